@@ -25,6 +25,8 @@ const (
 	FieldUserID = "user_id"
 	// FieldKey holds the string denoting the key field in the database.
 	FieldKey = "key"
+	// FieldKeyPrefix holds the string denoting the key_prefix field in the database.
+	FieldKeyPrefix = "key_prefix"
 	// FieldName holds the string denoting the name field in the database.
 	FieldName = "name"
 	// FieldGroupID holds the string denoting the group_id field in the database.
@@ -61,6 +63,16 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldPreviousKey holds the string denoting the previous_key field in the database.
+	FieldPreviousKey = "previous_key"
+	// FieldPreviousKeyPrefix holds the string denoting the previous_key_prefix field in the database.
+	FieldPreviousKeyPrefix = "previous_key_prefix"
+	// FieldPreviousKeyExpiresAt holds the string denoting the previous_key_expires_at field in the database.
+	FieldPreviousKeyExpiresAt = "previous_key_expires_at"
+	// FieldRotatedAt holds the string denoting the rotated_at field in the database.
+	FieldRotatedAt = "rotated_at"
+	// FieldPolicy holds the string denoting the policy field in the database.
+	FieldPolicy = "policy"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -100,6 +112,7 @@ var Columns = []string{
 	FieldDeletedAt,
 	FieldUserID,
 	FieldKey,
+	FieldKeyPrefix,
 	FieldName,
 	FieldGroupID,
 	FieldStatus,
@@ -118,6 +131,11 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldPreviousKey,
+	FieldPreviousKeyPrefix,
+	FieldPreviousKeyExpiresAt,
+	FieldRotatedAt,
+	FieldPolicy,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -146,6 +164,10 @@ var (
 	UpdateDefaultUpdatedAt func() time.Time
 	// KeyValidator is a validator for the "key" field. It is called by the builders before save.
 	KeyValidator func(string) error
+	// DefaultKeyPrefix holds the default value on creation for the "key_prefix" field.
+	DefaultKeyPrefix string
+	// KeyPrefixValidator is a validator for the "key_prefix" field. It is called by the builders before save.
+	KeyPrefixValidator func(string) error
 	// NameValidator is a validator for the "name" field. It is called by the builders before save.
 	NameValidator func(string) error
 	// DefaultStatus holds the default value on creation for the "status" field.
@@ -168,6 +190,12 @@ var (
 	DefaultUsage1d float64
 	// DefaultUsage7d holds the default value on creation for the "usage_7d" field.
 	DefaultUsage7d float64
+	// PreviousKeyValidator is a validator for the "previous_key" field. It is called by the builders before save.
+	PreviousKeyValidator func(string) error
+	// DefaultPreviousKeyPrefix holds the default value on creation for the "previous_key_prefix" field.
+	DefaultPreviousKeyPrefix string
+	// PreviousKeyPrefixValidator is a validator for the "previous_key_prefix" field. It is called by the builders before save.
+	PreviousKeyPrefixValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the APIKey queries.
@@ -203,6 +231,11 @@ func ByKey(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldKey, opts...).ToFunc()
 }
 
+// ByKeyPrefix orders the results by the key_prefix field.
+func ByKeyPrefix(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldKeyPrefix, opts...).ToFunc()
+}
+
 // ByName orders the results by the name field.
 func ByName(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldName, opts...).ToFunc()
@@ -283,6 +316,26 @@ func ByWindow7dStart(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldWindow7dStart, opts...).ToFunc()
 }
 
+// ByPreviousKey orders the results by the previous_key field.
+func ByPreviousKey(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPreviousKey, opts...).ToFunc()
+}
+
+// ByPreviousKeyPrefix orders the results by the previous_key_prefix field.
+func ByPreviousKeyPrefix(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPreviousKeyPrefix, opts...).ToFunc()
+}
+
+// ByPreviousKeyExpiresAt orders the results by the previous_key_expires_at field.
+func ByPreviousKeyExpiresAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPreviousKeyExpiresAt, opts...).ToFunc()
+}
+
+// ByRotatedAt orders the results by the rotated_at field.
+func ByRotatedAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRotatedAt, opts...).ToFunc()
+}
+
 // ByUserField orders the results by user field.
 func ByUserField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
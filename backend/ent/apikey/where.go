@@ -80,6 +80,11 @@ func Key(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldKey, v))
 }
 
+// KeyPrefix applies equality check predicate on the "key_prefix" field. It's identical to KeyPrefixEQ.
+func KeyPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldKeyPrefix, v))
+}
+
 // Name applies equality check predicate on the "name" field. It's identical to NameEQ.
 func Name(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldName, v))
@@ -160,6 +165,26 @@ func Window7dStart(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldWindow7dStart, v))
 }
 
+// PreviousKey applies equality check predicate on the "previous_key" field. It's identical to PreviousKeyEQ.
+func PreviousKey(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyPrefix applies equality check predicate on the "previous_key_prefix" field. It's identical to PreviousKeyPrefixEQ.
+func PreviousKeyPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyExpiresAt applies equality check predicate on the "previous_key_expires_at" field. It's identical to PreviousKeyExpiresAtEQ.
+func PreviousKeyExpiresAt(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyExpiresAt, v))
+}
+
+// RotatedAt applies equality check predicate on the "rotated_at" field. It's identical to RotatedAtEQ.
+func RotatedAt(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRotatedAt, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldCreatedAt, v))
@@ -375,6 +400,71 @@ func KeyContainsFold(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldContainsFold(FieldKey, v))
 }
 
+// KeyPrefixEQ applies the EQ predicate on the "key_prefix" field.
+func KeyPrefixEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldKeyPrefix, v))
+}
+
+// KeyPrefixNEQ applies the NEQ predicate on the "key_prefix" field.
+func KeyPrefixNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldKeyPrefix, v))
+}
+
+// KeyPrefixIn applies the In predicate on the "key_prefix" field.
+func KeyPrefixIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldKeyPrefix, vs...))
+}
+
+// KeyPrefixNotIn applies the NotIn predicate on the "key_prefix" field.
+func KeyPrefixNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldKeyPrefix, vs...))
+}
+
+// KeyPrefixGT applies the GT predicate on the "key_prefix" field.
+func KeyPrefixGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldKeyPrefix, v))
+}
+
+// KeyPrefixGTE applies the GTE predicate on the "key_prefix" field.
+func KeyPrefixGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldKeyPrefix, v))
+}
+
+// KeyPrefixLT applies the LT predicate on the "key_prefix" field.
+func KeyPrefixLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldKeyPrefix, v))
+}
+
+// KeyPrefixLTE applies the LTE predicate on the "key_prefix" field.
+func KeyPrefixLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldKeyPrefix, v))
+}
+
+// KeyPrefixContains applies the Contains predicate on the "key_prefix" field.
+func KeyPrefixContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldKeyPrefix, v))
+}
+
+// KeyPrefixHasPrefix applies the HasPrefix predicate on the "key_prefix" field.
+func KeyPrefixHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldKeyPrefix, v))
+}
+
+// KeyPrefixHasSuffix applies the HasSuffix predicate on the "key_prefix" field.
+func KeyPrefixHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldKeyPrefix, v))
+}
+
+// KeyPrefixEqualFold applies the EqualFold predicate on the "key_prefix" field.
+func KeyPrefixEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldKeyPrefix, v))
+}
+
+// KeyPrefixContainsFold applies the ContainsFold predicate on the "key_prefix" field.
+func KeyPrefixContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldKeyPrefix, v))
+}
+
 // NameEQ applies the EQ predicate on the "name" field.
 func NameEQ(v string) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldName, v))
@@ -1125,6 +1215,256 @@ func Window7dStartNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldWindow7dStart))
 }
 
+// PreviousKeyEQ applies the EQ predicate on the "previous_key" field.
+func PreviousKeyEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyNEQ applies the NEQ predicate on the "previous_key" field.
+func PreviousKeyNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPreviousKey, v))
+}
+
+// PreviousKeyIn applies the In predicate on the "previous_key" field.
+func PreviousKeyIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPreviousKey, vs...))
+}
+
+// PreviousKeyNotIn applies the NotIn predicate on the "previous_key" field.
+func PreviousKeyNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPreviousKey, vs...))
+}
+
+// PreviousKeyGT applies the GT predicate on the "previous_key" field.
+func PreviousKeyGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPreviousKey, v))
+}
+
+// PreviousKeyGTE applies the GTE predicate on the "previous_key" field.
+func PreviousKeyGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPreviousKey, v))
+}
+
+// PreviousKeyLT applies the LT predicate on the "previous_key" field.
+func PreviousKeyLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPreviousKey, v))
+}
+
+// PreviousKeyLTE applies the LTE predicate on the "previous_key" field.
+func PreviousKeyLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPreviousKey, v))
+}
+
+// PreviousKeyContains applies the Contains predicate on the "previous_key" field.
+func PreviousKeyContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldPreviousKey, v))
+}
+
+// PreviousKeyHasPrefix applies the HasPrefix predicate on the "previous_key" field.
+func PreviousKeyHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldPreviousKey, v))
+}
+
+// PreviousKeyHasSuffix applies the HasSuffix predicate on the "previous_key" field.
+func PreviousKeyHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldPreviousKey, v))
+}
+
+// PreviousKeyIsNil applies the IsNil predicate on the "previous_key" field.
+func PreviousKeyIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldPreviousKey))
+}
+
+// PreviousKeyNotNil applies the NotNil predicate on the "previous_key" field.
+func PreviousKeyNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldPreviousKey))
+}
+
+// PreviousKeyEqualFold applies the EqualFold predicate on the "previous_key" field.
+func PreviousKeyEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldPreviousKey, v))
+}
+
+// PreviousKeyContainsFold applies the ContainsFold predicate on the "previous_key" field.
+func PreviousKeyContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldPreviousKey, v))
+}
+
+// PreviousKeyPrefixEQ applies the EQ predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixNEQ applies the NEQ predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixIn applies the In predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPreviousKeyPrefix, vs...))
+}
+
+// PreviousKeyPrefixNotIn applies the NotIn predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPreviousKeyPrefix, vs...))
+}
+
+// PreviousKeyPrefixGT applies the GT predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixGTE applies the GTE predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixLT applies the LT predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixLTE applies the LTE predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixContains applies the Contains predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixHasPrefix applies the HasPrefix predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixHasSuffix applies the HasSuffix predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixEqualFold applies the EqualFold predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyPrefixContainsFold applies the ContainsFold predicate on the "previous_key_prefix" field.
+func PreviousKeyPrefixContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldPreviousKeyPrefix, v))
+}
+
+// PreviousKeyExpiresAtEQ applies the EQ predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtNEQ applies the NEQ predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtIn applies the In predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPreviousKeyExpiresAt, vs...))
+}
+
+// PreviousKeyExpiresAtNotIn applies the NotIn predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPreviousKeyExpiresAt, vs...))
+}
+
+// PreviousKeyExpiresAtGT applies the GT predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtGTE applies the GTE predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtLT applies the LT predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtLTE applies the LTE predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPreviousKeyExpiresAt, v))
+}
+
+// PreviousKeyExpiresAtIsNil applies the IsNil predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldPreviousKeyExpiresAt))
+}
+
+// PreviousKeyExpiresAtNotNil applies the NotNil predicate on the "previous_key_expires_at" field.
+func PreviousKeyExpiresAtNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldPreviousKeyExpiresAt))
+}
+
+// RotatedAtEQ applies the EQ predicate on the "rotated_at" field.
+func RotatedAtEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRotatedAt, v))
+}
+
+// RotatedAtNEQ applies the NEQ predicate on the "rotated_at" field.
+func RotatedAtNEQ(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRotatedAt, v))
+}
+
+// RotatedAtIn applies the In predicate on the "rotated_at" field.
+func RotatedAtIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldRotatedAt, vs...))
+}
+
+// RotatedAtNotIn applies the NotIn predicate on the "rotated_at" field.
+func RotatedAtNotIn(vs ...time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldRotatedAt, vs...))
+}
+
+// RotatedAtGT applies the GT predicate on the "rotated_at" field.
+func RotatedAtGT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldRotatedAt, v))
+}
+
+// RotatedAtGTE applies the GTE predicate on the "rotated_at" field.
+func RotatedAtGTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldRotatedAt, v))
+}
+
+// RotatedAtLT applies the LT predicate on the "rotated_at" field.
+func RotatedAtLT(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldRotatedAt, v))
+}
+
+// RotatedAtLTE applies the LTE predicate on the "rotated_at" field.
+func RotatedAtLTE(v time.Time) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldRotatedAt, v))
+}
+
+// RotatedAtIsNil applies the IsNil predicate on the "rotated_at" field.
+func RotatedAtIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldRotatedAt))
+}
+
+// RotatedAtNotNil applies the NotNil predicate on the "rotated_at" field.
+func RotatedAtNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldRotatedAt))
+}
+
+// PolicyIsNil applies the IsNil predicate on the "policy" field.
+func PolicyIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldPolicy))
+}
+
+// PolicyNotNil applies the NotNil predicate on the "policy" field.
+func PolicyNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldPolicy))
+}
+
 // HasUser applies the HasEdge predicate on the "user" edge.
 func HasUser() predicate.APIKey {
 	return predicate.APIKey(func(s *sql.Selector) {
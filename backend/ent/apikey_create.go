@@ -15,6 +15,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // APIKeyCreate is the builder for creating a APIKey entity.
@@ -79,6 +80,20 @@ func (_c *APIKeyCreate) SetKey(v string) *APIKeyCreate {
 	return _c
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (_c *APIKeyCreate) SetKeyPrefix(v string) *APIKeyCreate {
+	_c.mutation.SetKeyPrefix(v)
+	return _c
+}
+
+// SetNillableKeyPrefix sets the "key_prefix" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableKeyPrefix(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetKeyPrefix(*v)
+	}
+	return _c
+}
+
 // SetName sets the "name" field.
 func (_c *APIKeyCreate) SetName(v string) *APIKeyCreate {
 	_c.mutation.SetName(v)
@@ -307,6 +322,76 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (_c *APIKeyCreate) SetPreviousKey(v string) *APIKeyCreate {
+	_c.mutation.SetPreviousKey(v)
+	return _c
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePreviousKey(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetPreviousKey(*v)
+	}
+	return _c
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (_c *APIKeyCreate) SetPreviousKeyPrefix(v string) *APIKeyCreate {
+	_c.mutation.SetPreviousKeyPrefix(v)
+	return _c
+}
+
+// SetNillablePreviousKeyPrefix sets the "previous_key_prefix" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePreviousKeyPrefix(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetPreviousKeyPrefix(*v)
+	}
+	return _c
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_c *APIKeyCreate) SetPreviousKeyExpiresAt(v time.Time) *APIKeyCreate {
+	_c.mutation.SetPreviousKeyExpiresAt(v)
+	return _c
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetPreviousKeyExpiresAt(*v)
+	}
+	return _c
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (_c *APIKeyCreate) SetRotatedAt(v time.Time) *APIKeyCreate {
+	_c.mutation.SetRotatedAt(v)
+	return _c
+}
+
+// SetNillableRotatedAt sets the "rotated_at" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableRotatedAt(v *time.Time) *APIKeyCreate {
+	if v != nil {
+		_c.SetRotatedAt(*v)
+	}
+	return _c
+}
+
+// SetPolicy sets the "policy" field.
+func (_c *APIKeyCreate) SetPolicy(v domain.APIKeyPolicy) *APIKeyCreate {
+	_c.mutation.SetPolicy(v)
+	return _c
+}
+
+// SetNillablePolicy sets the "policy" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePolicy(v *domain.APIKeyPolicy) *APIKeyCreate {
+	if v != nil {
+		_c.SetPolicy(*v)
+	}
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -383,6 +468,10 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUpdatedAt()
 		_c.mutation.SetUpdatedAt(v)
 	}
+	if _, ok := _c.mutation.KeyPrefix(); !ok {
+		v := apikey.DefaultKeyPrefix
+		_c.mutation.SetKeyPrefix(v)
+	}
 	if _, ok := _c.mutation.Status(); !ok {
 		v := apikey.DefaultStatus
 		_c.mutation.SetStatus(v)
@@ -419,6 +508,10 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.PreviousKeyPrefix(); !ok {
+		v := apikey.DefaultPreviousKeyPrefix
+		_c.mutation.SetPreviousKeyPrefix(v)
+	}
 	return nil
 }
 
@@ -441,6 +534,14 @@ func (_c *APIKeyCreate) check() error {
 			return &ValidationError{Name: "key", err: fmt.Errorf(`ent: validator failed for field "APIKey.key": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.KeyPrefix(); !ok {
+		return &ValidationError{Name: "key_prefix", err: errors.New(`ent: missing required field "APIKey.key_prefix"`)}
+	}
+	if v, ok := _c.mutation.KeyPrefix(); ok {
+		if err := apikey.KeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.key_prefix": %w`, err)}
+		}
+	}
 	if _, ok := _c.mutation.Name(); !ok {
 		return &ValidationError{Name: "name", err: errors.New(`ent: missing required field "APIKey.name"`)}
 	}
@@ -481,6 +582,19 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if v, ok := _c.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.PreviousKeyPrefix(); !ok {
+		return &ValidationError{Name: "previous_key_prefix", err: errors.New(`ent: missing required field "APIKey.previous_key_prefix"`)}
+	}
+	if v, ok := _c.mutation.PreviousKeyPrefix(); ok {
+		if err := apikey.PreviousKeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key_prefix": %w`, err)}
+		}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -527,6 +641,10 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldKey, field.TypeString, value)
 		_node.Key = value
 	}
+	if value, ok := _c.mutation.KeyPrefix(); ok {
+		_spec.SetField(apikey.FieldKeyPrefix, field.TypeString, value)
+		_node.KeyPrefix = value
+	}
 	if value, ok := _c.mutation.Name(); ok {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 		_node.Name = value
@@ -595,6 +713,26 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+		_node.PreviousKey = &value
+	}
+	if value, ok := _c.mutation.PreviousKeyPrefix(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyPrefix, field.TypeString, value)
+		_node.PreviousKeyPrefix = value
+	}
+	if value, ok := _c.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+		_node.PreviousKeyExpiresAt = &value
+	}
+	if value, ok := _c.mutation.RotatedAt(); ok {
+		_spec.SetField(apikey.FieldRotatedAt, field.TypeTime, value)
+		_node.RotatedAt = &value
+	}
+	if value, ok := _c.mutation.Policy(); ok {
+		_spec.SetField(apikey.FieldPolicy, field.TypeJSON, value)
+		_node.Policy = value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -751,6 +889,18 @@ func (u *APIKeyUpsert) UpdateKey() *APIKeyUpsert {
 	return u
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (u *APIKeyUpsert) SetKeyPrefix(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldKeyPrefix, v)
+	return u
+}
+
+// UpdateKeyPrefix sets the "key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateKeyPrefix() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldKeyPrefix)
+	return u
+}
+
 // SetName sets the "name" field.
 func (u *APIKeyUpsert) SetName(v string) *APIKeyUpsert {
 	u.Set(apikey.FieldName, v)
@@ -1063,6 +1213,90 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsert) SetPreviousKey(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldPreviousKey, v)
+	return u
+}
+
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePreviousKey() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPreviousKey)
+	return u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsert) ClearPreviousKey() *APIKeyUpsert {
+	u.SetNull(apikey.FieldPreviousKey)
+	return u
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (u *APIKeyUpsert) SetPreviousKeyPrefix(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldPreviousKeyPrefix, v)
+	return u
+}
+
+// UpdatePreviousKeyPrefix sets the "previous_key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePreviousKeyPrefix() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPreviousKeyPrefix)
+	return u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsert) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldPreviousKeyExpiresAt, v)
+	return u
+}
+
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePreviousKeyExpiresAt() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPreviousKeyExpiresAt)
+	return u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsert) ClearPreviousKeyExpiresAt() *APIKeyUpsert {
+	u.SetNull(apikey.FieldPreviousKeyExpiresAt)
+	return u
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (u *APIKeyUpsert) SetRotatedAt(v time.Time) *APIKeyUpsert {
+	u.Set(apikey.FieldRotatedAt, v)
+	return u
+}
+
+// UpdateRotatedAt sets the "rotated_at" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateRotatedAt() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldRotatedAt)
+	return u
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (u *APIKeyUpsert) ClearRotatedAt() *APIKeyUpsert {
+	u.SetNull(apikey.FieldRotatedAt)
+	return u
+}
+
+// SetPolicy sets the "policy" field.
+func (u *APIKeyUpsert) SetPolicy(v domain.APIKeyPolicy) *APIKeyUpsert {
+	u.Set(apikey.FieldPolicy, v)
+	return u
+}
+
+// UpdatePolicy sets the "policy" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePolicy() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPolicy)
+	return u
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (u *APIKeyUpsert) ClearPolicy() *APIKeyUpsert {
+	u.SetNull(apikey.FieldPolicy)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1171,6 +1405,20 @@ func (u *APIKeyUpsertOne) UpdateKey() *APIKeyUpsertOne {
 	})
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (u *APIKeyUpsertOne) SetKeyPrefix(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetKeyPrefix(v)
+	})
+}
+
+// UpdateKeyPrefix sets the "key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateKeyPrefix() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateKeyPrefix()
+	})
+}
+
 // SetName sets the "name" field.
 func (u *APIKeyUpsertOne) SetName(v string) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -1535,6 +1783,104 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsertOne) SetPreviousKey(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKey(v)
+	})
+}
+
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePreviousKey() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKey()
+	})
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsertOne) ClearPreviousKey() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKey()
+	})
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (u *APIKeyUpsertOne) SetPreviousKeyPrefix(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKeyPrefix(v)
+	})
+}
+
+// UpdatePreviousKeyPrefix sets the "previous_key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePreviousKeyPrefix() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKeyPrefix()
+	})
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsertOne) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKeyExpiresAt(v)
+	})
+}
+
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePreviousKeyExpiresAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKeyExpiresAt()
+	})
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsertOne) ClearPreviousKeyExpiresAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKeyExpiresAt()
+	})
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (u *APIKeyUpsertOne) SetRotatedAt(v time.Time) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRotatedAt(v)
+	})
+}
+
+// UpdateRotatedAt sets the "rotated_at" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRotatedAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRotatedAt()
+	})
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (u *APIKeyUpsertOne) ClearRotatedAt() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearRotatedAt()
+	})
+}
+
+// SetPolicy sets the "policy" field.
+func (u *APIKeyUpsertOne) SetPolicy(v domain.APIKeyPolicy) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPolicy(v)
+	})
+}
+
+// UpdatePolicy sets the "policy" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePolicy() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePolicy()
+	})
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (u *APIKeyUpsertOne) ClearPolicy() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1809,6 +2155,20 @@ func (u *APIKeyUpsertBulk) UpdateKey() *APIKeyUpsertBulk {
 	})
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (u *APIKeyUpsertBulk) SetKeyPrefix(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetKeyPrefix(v)
+	})
+}
+
+// UpdateKeyPrefix sets the "key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateKeyPrefix() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateKeyPrefix()
+	})
+}
+
 // SetName sets the "name" field.
 func (u *APIKeyUpsertBulk) SetName(v string) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -2173,6 +2533,104 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (u *APIKeyUpsertBulk) SetPreviousKey(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKey(v)
+	})
+}
+
+// UpdatePreviousKey sets the "previous_key" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePreviousKey() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKey()
+	})
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (u *APIKeyUpsertBulk) ClearPreviousKey() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKey()
+	})
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (u *APIKeyUpsertBulk) SetPreviousKeyPrefix(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKeyPrefix(v)
+	})
+}
+
+// UpdatePreviousKeyPrefix sets the "previous_key_prefix" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePreviousKeyPrefix() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKeyPrefix()
+	})
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (u *APIKeyUpsertBulk) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPreviousKeyExpiresAt(v)
+	})
+}
+
+// UpdatePreviousKeyExpiresAt sets the "previous_key_expires_at" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePreviousKeyExpiresAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePreviousKeyExpiresAt()
+	})
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (u *APIKeyUpsertBulk) ClearPreviousKeyExpiresAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPreviousKeyExpiresAt()
+	})
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (u *APIKeyUpsertBulk) SetRotatedAt(v time.Time) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRotatedAt(v)
+	})
+}
+
+// UpdateRotatedAt sets the "rotated_at" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateRotatedAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRotatedAt()
+	})
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (u *APIKeyUpsertBulk) ClearRotatedAt() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearRotatedAt()
+	})
+}
+
+// SetPolicy sets the "policy" field.
+func (u *APIKeyUpsertBulk) SetPolicy(v domain.APIKeyPolicy) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPolicy(v)
+	})
+}
+
+// UpdatePolicy sets the "policy" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePolicy() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePolicy()
+	})
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (u *APIKeyUpsertBulk) ClearPolicy() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -17,6 +17,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/predicate"
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // APIKeyUpdate is the builder for updating APIKey entities.
@@ -86,6 +87,20 @@ func (_u *APIKeyUpdate) SetNillableKey(v *string) *APIKeyUpdate {
 	return _u
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (_u *APIKeyUpdate) SetKeyPrefix(v string) *APIKeyUpdate {
+	_u.mutation.SetKeyPrefix(v)
+	return _u
+}
+
+// SetNillableKeyPrefix sets the "key_prefix" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableKeyPrefix(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetKeyPrefix(*v)
+	}
+	return _u
+}
+
 // SetName sets the "name" field.
 func (_u *APIKeyUpdate) SetName(v string) *APIKeyUpdate {
 	_u.mutation.SetName(v)
@@ -438,6 +453,100 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (_u *APIKeyUpdate) SetPreviousKey(v string) *APIKeyUpdate {
+	_u.mutation.SetPreviousKey(v)
+	return _u
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePreviousKey(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPreviousKey(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (_u *APIKeyUpdate) ClearPreviousKey() *APIKeyUpdate {
+	_u.mutation.ClearPreviousKey()
+	return _u
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (_u *APIKeyUpdate) SetPreviousKeyPrefix(v string) *APIKeyUpdate {
+	_u.mutation.SetPreviousKeyPrefix(v)
+	return _u
+}
+
+// SetNillablePreviousKeyPrefix sets the "previous_key_prefix" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePreviousKeyPrefix(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPreviousKeyPrefix(*v)
+	}
+	return _u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_u *APIKeyUpdate) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetPreviousKeyExpiresAt(v)
+	return _u
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPreviousKeyExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (_u *APIKeyUpdate) ClearPreviousKeyExpiresAt() *APIKeyUpdate {
+	_u.mutation.ClearPreviousKeyExpiresAt()
+	return _u
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (_u *APIKeyUpdate) SetRotatedAt(v time.Time) *APIKeyUpdate {
+	_u.mutation.SetRotatedAt(v)
+	return _u
+}
+
+// SetNillableRotatedAt sets the "rotated_at" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableRotatedAt(v *time.Time) *APIKeyUpdate {
+	if v != nil {
+		_u.SetRotatedAt(*v)
+	}
+	return _u
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (_u *APIKeyUpdate) ClearRotatedAt() *APIKeyUpdate {
+	_u.mutation.ClearRotatedAt()
+	return _u
+}
+
+// SetPolicy sets the "policy" field.
+func (_u *APIKeyUpdate) SetPolicy(v domain.APIKeyPolicy) *APIKeyUpdate {
+	_u.mutation.SetPolicy(v)
+	return _u
+}
+
+// SetNillablePolicy sets the "policy" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePolicy(v *domain.APIKeyPolicy) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPolicy(*v)
+	}
+	return _u
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (_u *APIKeyUpdate) ClearPolicy() *APIKeyUpdate {
+	_u.mutation.ClearPolicy()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -550,6 +659,11 @@ func (_u *APIKeyUpdate) check() error {
 			return &ValidationError{Name: "key", err: fmt.Errorf(`ent: validator failed for field "APIKey.key": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.KeyPrefix(); ok {
+		if err := apikey.KeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.key_prefix": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Name(); ok {
 		if err := apikey.NameValidator(v); err != nil {
 			return &ValidationError{Name: "name", err: fmt.Errorf(`ent: validator failed for field "APIKey.name": %w`, err)}
@@ -560,6 +674,16 @@ func (_u *APIKeyUpdate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PreviousKeyPrefix(); ok {
+		if err := apikey.PreviousKeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key_prefix": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -590,6 +714,9 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.Key(); ok {
 		_spec.SetField(apikey.FieldKey, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.KeyPrefix(); ok {
+		_spec.SetField(apikey.FieldKeyPrefix, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.Name(); ok {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 	}
@@ -696,6 +823,33 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+	}
+	if _u.mutation.PreviousKeyCleared() {
+		_spec.ClearField(apikey.FieldPreviousKey, field.TypeString)
+	}
+	if value, ok := _u.mutation.PreviousKeyPrefix(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyPrefix, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.PreviousKeyExpiresAtCleared() {
+		_spec.ClearField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.RotatedAt(); ok {
+		_spec.SetField(apikey.FieldRotatedAt, field.TypeTime, value)
+	}
+	if _u.mutation.RotatedAtCleared() {
+		_spec.ClearField(apikey.FieldRotatedAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.Policy(); ok {
+		_spec.SetField(apikey.FieldPolicy, field.TypeJSON, value)
+	}
+	if _u.mutation.PolicyCleared() {
+		_spec.ClearField(apikey.FieldPolicy, field.TypeJSON)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -873,6 +1027,20 @@ func (_u *APIKeyUpdateOne) SetNillableKey(v *string) *APIKeyUpdateOne {
 	return _u
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (_u *APIKeyUpdateOne) SetKeyPrefix(v string) *APIKeyUpdateOne {
+	_u.mutation.SetKeyPrefix(v)
+	return _u
+}
+
+// SetNillableKeyPrefix sets the "key_prefix" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableKeyPrefix(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetKeyPrefix(*v)
+	}
+	return _u
+}
+
 // SetName sets the "name" field.
 func (_u *APIKeyUpdateOne) SetName(v string) *APIKeyUpdateOne {
 	_u.mutation.SetName(v)
@@ -1225,6 +1393,100 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (_u *APIKeyUpdateOne) SetPreviousKey(v string) *APIKeyUpdateOne {
+	_u.mutation.SetPreviousKey(v)
+	return _u
+}
+
+// SetNillablePreviousKey sets the "previous_key" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePreviousKey(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPreviousKey(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (_u *APIKeyUpdateOne) ClearPreviousKey() *APIKeyUpdateOne {
+	_u.mutation.ClearPreviousKey()
+	return _u
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (_u *APIKeyUpdateOne) SetPreviousKeyPrefix(v string) *APIKeyUpdateOne {
+	_u.mutation.SetPreviousKeyPrefix(v)
+	return _u
+}
+
+// SetNillablePreviousKeyPrefix sets the "previous_key_prefix" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePreviousKeyPrefix(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPreviousKeyPrefix(*v)
+	}
+	return _u
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (_u *APIKeyUpdateOne) SetPreviousKeyExpiresAt(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetPreviousKeyExpiresAt(v)
+	return _u
+}
+
+// SetNillablePreviousKeyExpiresAt sets the "previous_key_expires_at" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePreviousKeyExpiresAt(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPreviousKeyExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (_u *APIKeyUpdateOne) ClearPreviousKeyExpiresAt() *APIKeyUpdateOne {
+	_u.mutation.ClearPreviousKeyExpiresAt()
+	return _u
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (_u *APIKeyUpdateOne) SetRotatedAt(v time.Time) *APIKeyUpdateOne {
+	_u.mutation.SetRotatedAt(v)
+	return _u
+}
+
+// SetNillableRotatedAt sets the "rotated_at" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableRotatedAt(v *time.Time) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetRotatedAt(*v)
+	}
+	return _u
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (_u *APIKeyUpdateOne) ClearRotatedAt() *APIKeyUpdateOne {
+	_u.mutation.ClearRotatedAt()
+	return _u
+}
+
+// SetPolicy sets the "policy" field.
+func (_u *APIKeyUpdateOne) SetPolicy(v domain.APIKeyPolicy) *APIKeyUpdateOne {
+	_u.mutation.SetPolicy(v)
+	return _u
+}
+
+// SetNillablePolicy sets the "policy" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePolicy(v *domain.APIKeyPolicy) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPolicy(*v)
+	}
+	return _u
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (_u *APIKeyUpdateOne) ClearPolicy() *APIKeyUpdateOne {
+	_u.mutation.ClearPolicy()
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1350,6 +1612,11 @@ func (_u *APIKeyUpdateOne) check() error {
 			return &ValidationError{Name: "key", err: fmt.Errorf(`ent: validator failed for field "APIKey.key": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.KeyPrefix(); ok {
+		if err := apikey.KeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.key_prefix": %w`, err)}
+		}
+	}
 	if v, ok := _u.mutation.Name(); ok {
 		if err := apikey.NameValidator(v); err != nil {
 			return &ValidationError{Name: "name", err: fmt.Errorf(`ent: validator failed for field "APIKey.name": %w`, err)}
@@ -1360,6 +1627,16 @@ func (_u *APIKeyUpdateOne) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.PreviousKey(); ok {
+		if err := apikey.PreviousKeyValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PreviousKeyPrefix(); ok {
+		if err := apikey.PreviousKeyPrefixValidator(v); err != nil {
+			return &ValidationError{Name: "previous_key_prefix", err: fmt.Errorf(`ent: validator failed for field "APIKey.previous_key_prefix": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -1407,6 +1684,9 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if value, ok := _u.mutation.Key(); ok {
 		_spec.SetField(apikey.FieldKey, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.KeyPrefix(); ok {
+		_spec.SetField(apikey.FieldKeyPrefix, field.TypeString, value)
+	}
 	if value, ok := _u.mutation.Name(); ok {
 		_spec.SetField(apikey.FieldName, field.TypeString, value)
 	}
@@ -1513,6 +1793,33 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.PreviousKey(); ok {
+		_spec.SetField(apikey.FieldPreviousKey, field.TypeString, value)
+	}
+	if _u.mutation.PreviousKeyCleared() {
+		_spec.ClearField(apikey.FieldPreviousKey, field.TypeString)
+	}
+	if value, ok := _u.mutation.PreviousKeyPrefix(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyPrefix, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.PreviousKeyExpiresAt(); ok {
+		_spec.SetField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.PreviousKeyExpiresAtCleared() {
+		_spec.ClearField(apikey.FieldPreviousKeyExpiresAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.RotatedAt(); ok {
+		_spec.SetField(apikey.FieldRotatedAt, field.TypeTime, value)
+	}
+	if _u.mutation.RotatedAtCleared() {
+		_spec.ClearField(apikey.FieldRotatedAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.Policy(); ok {
+		_spec.SetField(apikey.FieldPolicy, field.TypeJSON, value)
+	}
+	if _u.mutation.PolicyCleared() {
+		_spec.ClearField(apikey.FieldPolicy, field.TypeJSON)
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
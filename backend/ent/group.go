@@ -11,6 +11,7 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
 	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // Group is the model entity for the Group schema.
@@ -82,6 +83,30 @@ type Group struct {
 	AllowMessagesDispatch bool `json:"allow_messages_dispatch,omitempty"`
 	// 默认映射模型 ID，当账号级映射找不到时使用此值
 	DefaultMappedModel string `json:"default_mapped_model,omitempty"`
+	// 每个 API Key 每小时允许的不同来源 IP 数，0 表示不限制
+	MaxIpsPerKeyPerHour int `json:"max_ips_per_key_per_hour,omitempty"`
+	// 分组内所有请求合计每分钟请求数上限（滑动窗口），0 表示不限制
+	RpmLimit int `json:"rpm_limit,omitempty"`
+	// 分组内所有请求合计每分钟 token 数上限（滑动窗口），0 表示不限制
+	TpmLimit int `json:"tpm_limit,omitempty"`
+	// 账号调度策略：priority_lru/weighted_round_robin/least_loaded/lowest_latency/cheapest/power_of_two，空表示平台默认
+	SchedulingStrategy string `json:"scheduling_strategy,omitempty"`
+	// 账号满载排队时的优先级（0-9，越大越优先）
+	QueuePriority int `json:"queue_priority,omitempty"`
+	// 模型降级链：源模型匹配且命中触发条件时依次尝试降级模型
+	ModelFallbackChains []domain.ModelFallbackChain `json:"model_fallback_chains,omitempty"`
+	// 本平台账号耗尽时是否转移到分组内 anthropic/antigravity 对端平台账号
+	CrossPlatformFailover bool `json:"cross_platform_failover,omitempty"`
+	// 影子流量镜像：按采样比例异步复制请求到影子账号/分组，仅记录对比指标，不计费
+	ShadowMirror *domain.ShadowMirrorConfig `json:"shadow_mirror,omitempty"`
+	// 网关响应缓存：确定性请求按规范化请求体精确匹配回放，命中按比例计费
+	ResponseCache *domain.ResponseCacheConfig `json:"response_cache,omitempty"`
+	// 是否为未设置 cache_control 的 Anthropic 请求自动在稳定前缀上注入缓存断点
+	AutoCacheBreakpoints bool `json:"auto_cache_breakpoints,omitempty"`
+	// 是否在服务端短暂缓冲流式响应，允许客户端断线后凭 Last-Event-ID 续传
+	StreamResume bool `json:"stream_resume,omitempty"`
+	// 上下文策略：转发前估算输入 token，超限时拒绝、截断最早轮次或摘要
+	ContextPolicy *domain.ContextPolicyConfig `json:"context_policy,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -188,15 +213,15 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldModelRouting, group.FieldSupportedModelScopes:
+		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldModelFallbackChains, group.FieldShadowMirror, group.FieldResponseCache, group.FieldContextPolicy:
 			values[i] = new([]byte)
-		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch:
+		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldCrossPlatformFailover, group.FieldAutoCacheBreakpoints, group.FieldStreamResume:
 			values[i] = new(sql.NullBool)
 		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k, group.FieldSoraImagePrice360, group.FieldSoraImagePrice540, group.FieldSoraVideoPricePerRequest, group.FieldSoraVideoPricePerRequestHd:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldSoraStorageQuotaBytes, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldSoraStorageQuotaBytes, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldMaxIpsPerKeyPerHour, group.FieldRpmLimit, group.FieldTpmLimit, group.FieldQueuePriority:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldSchedulingStrategy:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -431,6 +456,86 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.DefaultMappedModel = value.String
 			}
+		case group.FieldMaxIpsPerKeyPerHour:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_ips_per_key_per_hour", values[i])
+			} else if value.Valid {
+				_m.MaxIpsPerKeyPerHour = int(value.Int64)
+			}
+		case group.FieldRpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field rpm_limit", values[i])
+			} else if value.Valid {
+				_m.RpmLimit = int(value.Int64)
+			}
+		case group.FieldTpmLimit:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field tpm_limit", values[i])
+			} else if value.Valid {
+				_m.TpmLimit = int(value.Int64)
+			}
+		case group.FieldSchedulingStrategy:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field scheduling_strategy", values[i])
+			} else if value.Valid {
+				_m.SchedulingStrategy = value.String
+			}
+		case group.FieldQueuePriority:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field queue_priority", values[i])
+			} else if value.Valid {
+				_m.QueuePriority = int(value.Int64)
+			}
+		case group.FieldModelFallbackChains:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field model_fallback_chains", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ModelFallbackChains); err != nil {
+					return fmt.Errorf("unmarshal field model_fallback_chains: %w", err)
+				}
+			}
+		case group.FieldCrossPlatformFailover:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field cross_platform_failover", values[i])
+			} else if value.Valid {
+				_m.CrossPlatformFailover = value.Bool
+			}
+		case group.FieldShadowMirror:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field shadow_mirror", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ShadowMirror); err != nil {
+					return fmt.Errorf("unmarshal field shadow_mirror: %w", err)
+				}
+			}
+		case group.FieldResponseCache:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field response_cache", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ResponseCache); err != nil {
+					return fmt.Errorf("unmarshal field response_cache: %w", err)
+				}
+			}
+		case group.FieldAutoCacheBreakpoints:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field auto_cache_breakpoints", values[i])
+			} else if value.Valid {
+				_m.AutoCacheBreakpoints = value.Bool
+			}
+		case group.FieldStreamResume:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field stream_resume", values[i])
+			} else if value.Valid {
+				_m.StreamResume = value.Bool
+			}
+		case group.FieldContextPolicy:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field context_policy", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ContextPolicy); err != nil {
+					return fmt.Errorf("unmarshal field context_policy: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -630,6 +735,42 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("default_mapped_model=")
 	builder.WriteString(_m.DefaultMappedModel)
+	builder.WriteString(", ")
+	builder.WriteString("max_ips_per_key_per_hour=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxIpsPerKeyPerHour))
+	builder.WriteString(", ")
+	builder.WriteString("rpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("tpm_limit=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("scheduling_strategy=")
+	builder.WriteString(_m.SchedulingStrategy)
+	builder.WriteString(", ")
+	builder.WriteString("queue_priority=")
+	builder.WriteString(fmt.Sprintf("%v", _m.QueuePriority))
+	builder.WriteString(", ")
+	builder.WriteString("model_fallback_chains=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ModelFallbackChains))
+	builder.WriteString(", ")
+	builder.WriteString("cross_platform_failover=")
+	builder.WriteString(fmt.Sprintf("%v", _m.CrossPlatformFailover))
+	builder.WriteString(", ")
+	builder.WriteString("shadow_mirror=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ShadowMirror))
+	builder.WriteString(", ")
+	builder.WriteString("response_cache=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseCache))
+	builder.WriteString(", ")
+	builder.WriteString("auto_cache_breakpoints=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AutoCacheBreakpoints))
+	builder.WriteString(", ")
+	builder.WriteString("stream_resume=")
+	builder.WriteString(fmt.Sprintf("%v", _m.StreamResume))
+	builder.WriteString(", ")
+	builder.WriteString("context_policy=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ContextPolicy))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -79,6 +79,30 @@ const (
 	FieldAllowMessagesDispatch = "allow_messages_dispatch"
 	// FieldDefaultMappedModel holds the string denoting the default_mapped_model field in the database.
 	FieldDefaultMappedModel = "default_mapped_model"
+	// FieldMaxIpsPerKeyPerHour holds the string denoting the max_ips_per_key_per_hour field in the database.
+	FieldMaxIpsPerKeyPerHour = "max_ips_per_key_per_hour"
+	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
+	FieldRpmLimit = "rpm_limit"
+	// FieldTpmLimit holds the string denoting the tpm_limit field in the database.
+	FieldTpmLimit = "tpm_limit"
+	// FieldSchedulingStrategy holds the string denoting the scheduling_strategy field in the database.
+	FieldSchedulingStrategy = "scheduling_strategy"
+	// FieldQueuePriority holds the string denoting the queue_priority field in the database.
+	FieldQueuePriority = "queue_priority"
+	// FieldModelFallbackChains holds the string denoting the model_fallback_chains field in the database.
+	FieldModelFallbackChains = "model_fallback_chains"
+	// FieldCrossPlatformFailover holds the string denoting the cross_platform_failover field in the database.
+	FieldCrossPlatformFailover = "cross_platform_failover"
+	// FieldShadowMirror holds the string denoting the shadow_mirror field in the database.
+	FieldShadowMirror = "shadow_mirror"
+	// FieldResponseCache holds the string denoting the response_cache field in the database.
+	FieldResponseCache = "response_cache"
+	// FieldAutoCacheBreakpoints holds the string denoting the auto_cache_breakpoints field in the database.
+	FieldAutoCacheBreakpoints = "auto_cache_breakpoints"
+	// FieldStreamResume holds the string denoting the stream_resume field in the database.
+	FieldStreamResume = "stream_resume"
+	// FieldContextPolicy holds the string denoting the context_policy field in the database.
+	FieldContextPolicy = "context_policy"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -186,6 +210,18 @@ var Columns = []string{
 	FieldSortOrder,
 	FieldAllowMessagesDispatch,
 	FieldDefaultMappedModel,
+	FieldMaxIpsPerKeyPerHour,
+	FieldRpmLimit,
+	FieldTpmLimit,
+	FieldSchedulingStrategy,
+	FieldQueuePriority,
+	FieldModelFallbackChains,
+	FieldCrossPlatformFailover,
+	FieldShadowMirror,
+	FieldResponseCache,
+	FieldAutoCacheBreakpoints,
+	FieldStreamResume,
+	FieldContextPolicy,
 }
 
 var (
@@ -259,6 +295,24 @@ var (
 	DefaultDefaultMappedModel string
 	// DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	DefaultMappedModelValidator func(string) error
+	// DefaultMaxIpsPerKeyPerHour holds the default value on creation for the "max_ips_per_key_per_hour" field.
+	DefaultMaxIpsPerKeyPerHour int
+	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
+	DefaultRpmLimit int
+	// DefaultTpmLimit holds the default value on creation for the "tpm_limit" field.
+	DefaultTpmLimit int
+	// DefaultSchedulingStrategy holds the default value on creation for the "scheduling_strategy" field.
+	DefaultSchedulingStrategy string
+	// SchedulingStrategyValidator is a validator for the "scheduling_strategy" field. It is called by the builders before save.
+	SchedulingStrategyValidator func(string) error
+	// DefaultQueuePriority holds the default value on creation for the "queue_priority" field.
+	DefaultQueuePriority int
+	// DefaultCrossPlatformFailover holds the default value on creation for the "cross_platform_failover" field.
+	DefaultCrossPlatformFailover bool
+	// DefaultAutoCacheBreakpoints holds the default value on creation for the "auto_cache_breakpoints" field.
+	DefaultAutoCacheBreakpoints bool
+	// DefaultStreamResume holds the default value on creation for the "stream_resume" field.
+	DefaultStreamResume bool
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -419,6 +473,46 @@ func ByDefaultMappedModel(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldDefaultMappedModel, opts...).ToFunc()
 }
 
+// ByMaxIpsPerKeyPerHour orders the results by the max_ips_per_key_per_hour field.
+func ByMaxIpsPerKeyPerHour(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxIpsPerKeyPerHour, opts...).ToFunc()
+}
+
+// ByRpmLimit orders the results by the rpm_limit field.
+func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
+}
+
+// ByTpmLimit orders the results by the tpm_limit field.
+func ByTpmLimit(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTpmLimit, opts...).ToFunc()
+}
+
+// BySchedulingStrategy orders the results by the scheduling_strategy field.
+func BySchedulingStrategy(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSchedulingStrategy, opts...).ToFunc()
+}
+
+// ByQueuePriority orders the results by the queue_priority field.
+func ByQueuePriority(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldQueuePriority, opts...).ToFunc()
+}
+
+// ByCrossPlatformFailover orders the results by the cross_platform_failover field.
+func ByCrossPlatformFailover(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCrossPlatformFailover, opts...).ToFunc()
+}
+
+// ByAutoCacheBreakpoints orders the results by the auto_cache_breakpoints field.
+func ByAutoCacheBreakpoints(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldAutoCacheBreakpoints, opts...).ToFunc()
+}
+
+// ByStreamResume orders the results by the stream_resume field.
+func ByStreamResume(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldStreamResume, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -205,6 +205,46 @@ func DefaultMappedModel(v string) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldDefaultMappedModel, v))
 }
 
+// MaxIpsPerKeyPerHour applies equality check predicate on the "max_ips_per_key_per_hour" field. It's identical to MaxIpsPerKeyPerHourEQ.
+func MaxIpsPerKeyPerHour(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// RpmLimit applies equality check predicate on the "rpm_limit" field. It's identical to RpmLimitEQ.
+func RpmLimit(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// TpmLimit applies equality check predicate on the "tpm_limit" field. It's identical to TpmLimitEQ.
+func TpmLimit(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// SchedulingStrategy applies equality check predicate on the "scheduling_strategy" field. It's identical to SchedulingStrategyEQ.
+func SchedulingStrategy(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSchedulingStrategy, v))
+}
+
+// QueuePriority applies equality check predicate on the "queue_priority" field. It's identical to QueuePriorityEQ.
+func QueuePriority(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldQueuePriority, v))
+}
+
+// CrossPlatformFailover applies equality check predicate on the "cross_platform_failover" field. It's identical to CrossPlatformFailoverEQ.
+func CrossPlatformFailover(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldCrossPlatformFailover, v))
+}
+
+// AutoCacheBreakpoints applies equality check predicate on the "auto_cache_breakpoints" field. It's identical to AutoCacheBreakpointsEQ.
+func AutoCacheBreakpoints(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAutoCacheBreakpoints, v))
+}
+
+// StreamResume applies equality check predicate on the "stream_resume" field. It's identical to StreamResumeEQ.
+func StreamResume(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldStreamResume, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -1555,6 +1595,301 @@ func DefaultMappedModelContainsFold(v string) predicate.Group {
 	return predicate.Group(sql.FieldContainsFold(FieldDefaultMappedModel, v))
 }
 
+// MaxIpsPerKeyPerHourEQ applies the EQ predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// MaxIpsPerKeyPerHourNEQ applies the NEQ predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// MaxIpsPerKeyPerHourIn applies the In predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldMaxIpsPerKeyPerHour, vs...))
+}
+
+// MaxIpsPerKeyPerHourNotIn applies the NotIn predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldMaxIpsPerKeyPerHour, vs...))
+}
+
+// MaxIpsPerKeyPerHourGT applies the GT predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// MaxIpsPerKeyPerHourGTE applies the GTE predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// MaxIpsPerKeyPerHourLT applies the LT predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// MaxIpsPerKeyPerHourLTE applies the LTE predicate on the "max_ips_per_key_per_hour" field.
+func MaxIpsPerKeyPerHourLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldMaxIpsPerKeyPerHour, v))
+}
+
+// RpmLimitEQ applies the EQ predicate on the "rpm_limit" field.
+func RpmLimitEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitNEQ applies the NEQ predicate on the "rpm_limit" field.
+func RpmLimitNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldRpmLimit, v))
+}
+
+// RpmLimitIn applies the In predicate on the "rpm_limit" field.
+func RpmLimitIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitNotIn applies the NotIn predicate on the "rpm_limit" field.
+func RpmLimitNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldRpmLimit, vs...))
+}
+
+// RpmLimitGT applies the GT predicate on the "rpm_limit" field.
+func RpmLimitGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldRpmLimit, v))
+}
+
+// RpmLimitGTE applies the GTE predicate on the "rpm_limit" field.
+func RpmLimitGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldRpmLimit, v))
+}
+
+// RpmLimitLT applies the LT predicate on the "rpm_limit" field.
+func RpmLimitLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldRpmLimit, v))
+}
+
+// RpmLimitLTE applies the LTE predicate on the "rpm_limit" field.
+func RpmLimitLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldRpmLimit, v))
+}
+
+// TpmLimitEQ applies the EQ predicate on the "tpm_limit" field.
+func TpmLimitEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitNEQ applies the NEQ predicate on the "tpm_limit" field.
+func TpmLimitNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldTpmLimit, v))
+}
+
+// TpmLimitIn applies the In predicate on the "tpm_limit" field.
+func TpmLimitIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitNotIn applies the NotIn predicate on the "tpm_limit" field.
+func TpmLimitNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldTpmLimit, vs...))
+}
+
+// TpmLimitGT applies the GT predicate on the "tpm_limit" field.
+func TpmLimitGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldTpmLimit, v))
+}
+
+// TpmLimitGTE applies the GTE predicate on the "tpm_limit" field.
+func TpmLimitGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldTpmLimit, v))
+}
+
+// TpmLimitLT applies the LT predicate on the "tpm_limit" field.
+func TpmLimitLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldTpmLimit, v))
+}
+
+// TpmLimitLTE applies the LTE predicate on the "tpm_limit" field.
+func TpmLimitLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldTpmLimit, v))
+}
+
+// SchedulingStrategyEQ applies the EQ predicate on the "scheduling_strategy" field.
+func SchedulingStrategyEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyNEQ applies the NEQ predicate on the "scheduling_strategy" field.
+func SchedulingStrategyNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyIn applies the In predicate on the "scheduling_strategy" field.
+func SchedulingStrategyIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSchedulingStrategy, vs...))
+}
+
+// SchedulingStrategyNotIn applies the NotIn predicate on the "scheduling_strategy" field.
+func SchedulingStrategyNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSchedulingStrategy, vs...))
+}
+
+// SchedulingStrategyGT applies the GT predicate on the "scheduling_strategy" field.
+func SchedulingStrategyGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyGTE applies the GTE predicate on the "scheduling_strategy" field.
+func SchedulingStrategyGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyLT applies the LT predicate on the "scheduling_strategy" field.
+func SchedulingStrategyLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyLTE applies the LTE predicate on the "scheduling_strategy" field.
+func SchedulingStrategyLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyContains applies the Contains predicate on the "scheduling_strategy" field.
+func SchedulingStrategyContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyHasPrefix applies the HasPrefix predicate on the "scheduling_strategy" field.
+func SchedulingStrategyHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyHasSuffix applies the HasSuffix predicate on the "scheduling_strategy" field.
+func SchedulingStrategyHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyEqualFold applies the EqualFold predicate on the "scheduling_strategy" field.
+func SchedulingStrategyEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSchedulingStrategy, v))
+}
+
+// SchedulingStrategyContainsFold applies the ContainsFold predicate on the "scheduling_strategy" field.
+func SchedulingStrategyContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSchedulingStrategy, v))
+}
+
+// QueuePriorityEQ applies the EQ predicate on the "queue_priority" field.
+func QueuePriorityEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldQueuePriority, v))
+}
+
+// QueuePriorityNEQ applies the NEQ predicate on the "queue_priority" field.
+func QueuePriorityNEQ(v int) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldQueuePriority, v))
+}
+
+// QueuePriorityIn applies the In predicate on the "queue_priority" field.
+func QueuePriorityIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldQueuePriority, vs...))
+}
+
+// QueuePriorityNotIn applies the NotIn predicate on the "queue_priority" field.
+func QueuePriorityNotIn(vs ...int) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldQueuePriority, vs...))
+}
+
+// QueuePriorityGT applies the GT predicate on the "queue_priority" field.
+func QueuePriorityGT(v int) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldQueuePriority, v))
+}
+
+// QueuePriorityGTE applies the GTE predicate on the "queue_priority" field.
+func QueuePriorityGTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldQueuePriority, v))
+}
+
+// QueuePriorityLT applies the LT predicate on the "queue_priority" field.
+func QueuePriorityLT(v int) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldQueuePriority, v))
+}
+
+// QueuePriorityLTE applies the LTE predicate on the "queue_priority" field.
+func QueuePriorityLTE(v int) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldQueuePriority, v))
+}
+
+// ModelFallbackChainsIsNil applies the IsNil predicate on the "model_fallback_chains" field.
+func ModelFallbackChainsIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldModelFallbackChains))
+}
+
+// ModelFallbackChainsNotNil applies the NotNil predicate on the "model_fallback_chains" field.
+func ModelFallbackChainsNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldModelFallbackChains))
+}
+
+// CrossPlatformFailoverEQ applies the EQ predicate on the "cross_platform_failover" field.
+func CrossPlatformFailoverEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldCrossPlatformFailover, v))
+}
+
+// CrossPlatformFailoverNEQ applies the NEQ predicate on the "cross_platform_failover" field.
+func CrossPlatformFailoverNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldCrossPlatformFailover, v))
+}
+
+// ShadowMirrorIsNil applies the IsNil predicate on the "shadow_mirror" field.
+func ShadowMirrorIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldShadowMirror))
+}
+
+// ShadowMirrorNotNil applies the NotNil predicate on the "shadow_mirror" field.
+func ShadowMirrorNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldShadowMirror))
+}
+
+// ResponseCacheIsNil applies the IsNil predicate on the "response_cache" field.
+func ResponseCacheIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldResponseCache))
+}
+
+// ResponseCacheNotNil applies the NotNil predicate on the "response_cache" field.
+func ResponseCacheNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldResponseCache))
+}
+
+// AutoCacheBreakpointsEQ applies the EQ predicate on the "auto_cache_breakpoints" field.
+func AutoCacheBreakpointsEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldAutoCacheBreakpoints, v))
+}
+
+// AutoCacheBreakpointsNEQ applies the NEQ predicate on the "auto_cache_breakpoints" field.
+func AutoCacheBreakpointsNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldAutoCacheBreakpoints, v))
+}
+
+// StreamResumeEQ applies the EQ predicate on the "stream_resume" field.
+func StreamResumeEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldStreamResume, v))
+}
+
+// StreamResumeNEQ applies the NEQ predicate on the "stream_resume" field.
+func StreamResumeNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldStreamResume, v))
+}
+
+// ContextPolicyIsNil applies the IsNil predicate on the "context_policy" field.
+func ContextPolicyIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldContextPolicy))
+}
+
+// ContextPolicyNotNil applies the NotNil predicate on the "context_policy" field.
+func ContextPolicyNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldContextPolicy))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
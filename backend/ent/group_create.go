@@ -18,6 +18,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
 	"github.com/Wei-Shaw/sub2api/ent/usersubscription"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // GroupCreate is the builder for creating a Group entity.
@@ -452,6 +453,142 @@ func (_c *GroupCreate) SetNillableDefaultMappedModel(v *string) *GroupCreate {
 	return _c
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (_c *GroupCreate) SetMaxIpsPerKeyPerHour(v int) *GroupCreate {
+	_c.mutation.SetMaxIpsPerKeyPerHour(v)
+	return _c
+}
+
+// SetNillableMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableMaxIpsPerKeyPerHour(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetMaxIpsPerKeyPerHour(*v)
+	}
+	return _c
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (_c *GroupCreate) SetRpmLimit(v int) *GroupCreate {
+	_c.mutation.SetRpmLimit(v)
+	return _c
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableRpmLimit(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetRpmLimit(*v)
+	}
+	return _c
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_c *GroupCreate) SetTpmLimit(v int) *GroupCreate {
+	_c.mutation.SetTpmLimit(v)
+	return _c
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableTpmLimit(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetTpmLimit(*v)
+	}
+	return _c
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_c *GroupCreate) SetSchedulingStrategy(v string) *GroupCreate {
+	_c.mutation.SetSchedulingStrategy(v)
+	return _c
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSchedulingStrategy(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSchedulingStrategy(*v)
+	}
+	return _c
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (_c *GroupCreate) SetQueuePriority(v int) *GroupCreate {
+	_c.mutation.SetQueuePriority(v)
+	return _c
+}
+
+// SetNillableQueuePriority sets the "queue_priority" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableQueuePriority(v *int) *GroupCreate {
+	if v != nil {
+		_c.SetQueuePriority(*v)
+	}
+	return _c
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (_c *GroupCreate) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupCreate {
+	_c.mutation.SetModelFallbackChains(v)
+	return _c
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (_c *GroupCreate) SetCrossPlatformFailover(v bool) *GroupCreate {
+	_c.mutation.SetCrossPlatformFailover(v)
+	return _c
+}
+
+// SetNillableCrossPlatformFailover sets the "cross_platform_failover" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableCrossPlatformFailover(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetCrossPlatformFailover(*v)
+	}
+	return _c
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (_c *GroupCreate) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupCreate {
+	_c.mutation.SetShadowMirror(v)
+	return _c
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (_c *GroupCreate) SetResponseCache(v *domain.ResponseCacheConfig) *GroupCreate {
+	_c.mutation.SetResponseCache(v)
+	return _c
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (_c *GroupCreate) SetAutoCacheBreakpoints(v bool) *GroupCreate {
+	_c.mutation.SetAutoCacheBreakpoints(v)
+	return _c
+}
+
+// SetNillableAutoCacheBreakpoints sets the "auto_cache_breakpoints" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAutoCacheBreakpoints(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetAutoCacheBreakpoints(*v)
+	}
+	return _c
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (_c *GroupCreate) SetStreamResume(v bool) *GroupCreate {
+	_c.mutation.SetStreamResume(v)
+	return _c
+}
+
+// SetNillableStreamResume sets the "stream_resume" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableStreamResume(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetStreamResume(*v)
+	}
+	return _c
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (_c *GroupCreate) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupCreate {
+	_c.mutation.SetContextPolicy(v)
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -649,6 +786,38 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultDefaultMappedModel
 		_c.mutation.SetDefaultMappedModel(v)
 	}
+	if _, ok := _c.mutation.MaxIpsPerKeyPerHour(); !ok {
+		v := group.DefaultMaxIpsPerKeyPerHour
+		_c.mutation.SetMaxIpsPerKeyPerHour(v)
+	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		v := group.DefaultRpmLimit
+		_c.mutation.SetRpmLimit(v)
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		v := group.DefaultTpmLimit
+		_c.mutation.SetTpmLimit(v)
+	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		v := group.DefaultSchedulingStrategy
+		_c.mutation.SetSchedulingStrategy(v)
+	}
+	if _, ok := _c.mutation.QueuePriority(); !ok {
+		v := group.DefaultQueuePriority
+		_c.mutation.SetQueuePriority(v)
+	}
+	if _, ok := _c.mutation.CrossPlatformFailover(); !ok {
+		v := group.DefaultCrossPlatformFailover
+		_c.mutation.SetCrossPlatformFailover(v)
+	}
+	if _, ok := _c.mutation.AutoCacheBreakpoints(); !ok {
+		v := group.DefaultAutoCacheBreakpoints
+		_c.mutation.SetAutoCacheBreakpoints(v)
+	}
+	if _, ok := _c.mutation.StreamResume(); !ok {
+		v := group.DefaultStreamResume
+		_c.mutation.SetStreamResume(v)
+	}
 	return nil
 }
 
@@ -730,6 +899,35 @@ func (_c *GroupCreate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.MaxIpsPerKeyPerHour(); !ok {
+		return &ValidationError{Name: "max_ips_per_key_per_hour", err: errors.New(`ent: missing required field "Group.max_ips_per_key_per_hour"`)}
+	}
+	if _, ok := _c.mutation.RpmLimit(); !ok {
+		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "Group.rpm_limit"`)}
+	}
+	if _, ok := _c.mutation.TpmLimit(); !ok {
+		return &ValidationError{Name: "tpm_limit", err: errors.New(`ent: missing required field "Group.tpm_limit"`)}
+	}
+	if _, ok := _c.mutation.SchedulingStrategy(); !ok {
+		return &ValidationError{Name: "scheduling_strategy", err: errors.New(`ent: missing required field "Group.scheduling_strategy"`)}
+	}
+	if v, ok := _c.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.QueuePriority(); !ok {
+		return &ValidationError{Name: "queue_priority", err: errors.New(`ent: missing required field "Group.queue_priority"`)}
+	}
+	if _, ok := _c.mutation.CrossPlatformFailover(); !ok {
+		return &ValidationError{Name: "cross_platform_failover", err: errors.New(`ent: missing required field "Group.cross_platform_failover"`)}
+	}
+	if _, ok := _c.mutation.AutoCacheBreakpoints(); !ok {
+		return &ValidationError{Name: "auto_cache_breakpoints", err: errors.New(`ent: missing required field "Group.auto_cache_breakpoints"`)}
+	}
+	if _, ok := _c.mutation.StreamResume(); !ok {
+		return &ValidationError{Name: "stream_resume", err: errors.New(`ent: missing required field "Group.stream_resume"`)}
+	}
 	return nil
 }
 
@@ -885,6 +1083,54 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 		_node.DefaultMappedModel = value
 	}
+	if value, ok := _c.mutation.MaxIpsPerKeyPerHour(); ok {
+		_spec.SetField(group.FieldMaxIpsPerKeyPerHour, field.TypeInt, value)
+		_node.MaxIpsPerKeyPerHour = value
+	}
+	if value, ok := _c.mutation.RpmLimit(); ok {
+		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
+		_node.RpmLimit = value
+	}
+	if value, ok := _c.mutation.TpmLimit(); ok {
+		_spec.SetField(group.FieldTpmLimit, field.TypeInt, value)
+		_node.TpmLimit = value
+	}
+	if value, ok := _c.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+		_node.SchedulingStrategy = value
+	}
+	if value, ok := _c.mutation.QueuePriority(); ok {
+		_spec.SetField(group.FieldQueuePriority, field.TypeInt, value)
+		_node.QueuePriority = value
+	}
+	if value, ok := _c.mutation.ModelFallbackChains(); ok {
+		_spec.SetField(group.FieldModelFallbackChains, field.TypeJSON, value)
+		_node.ModelFallbackChains = value
+	}
+	if value, ok := _c.mutation.CrossPlatformFailover(); ok {
+		_spec.SetField(group.FieldCrossPlatformFailover, field.TypeBool, value)
+		_node.CrossPlatformFailover = value
+	}
+	if value, ok := _c.mutation.ShadowMirror(); ok {
+		_spec.SetField(group.FieldShadowMirror, field.TypeJSON, value)
+		_node.ShadowMirror = value
+	}
+	if value, ok := _c.mutation.ResponseCache(); ok {
+		_spec.SetField(group.FieldResponseCache, field.TypeJSON, value)
+		_node.ResponseCache = value
+	}
+	if value, ok := _c.mutation.AutoCacheBreakpoints(); ok {
+		_spec.SetField(group.FieldAutoCacheBreakpoints, field.TypeBool, value)
+		_node.AutoCacheBreakpoints = value
+	}
+	if value, ok := _c.mutation.StreamResume(); ok {
+		_spec.SetField(group.FieldStreamResume, field.TypeBool, value)
+		_node.StreamResume = value
+	}
+	if value, ok := _c.mutation.ContextPolicy(); ok {
+		_spec.SetField(group.FieldContextPolicy, field.TypeJSON, value)
+		_node.ContextPolicy = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1599,6 +1845,198 @@ func (u *GroupUpsert) UpdateDefaultMappedModel() *GroupUpsert {
 	return u
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsert) SetMaxIpsPerKeyPerHour(v int) *GroupUpsert {
+	u.Set(group.FieldMaxIpsPerKeyPerHour, v)
+	return u
+}
+
+// UpdateMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateMaxIpsPerKeyPerHour() *GroupUpsert {
+	u.SetExcluded(group.FieldMaxIpsPerKeyPerHour)
+	return u
+}
+
+// AddMaxIpsPerKeyPerHour adds v to the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsert) AddMaxIpsPerKeyPerHour(v int) *GroupUpsert {
+	u.Add(group.FieldMaxIpsPerKeyPerHour, v)
+	return u
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *GroupUpsert) SetRpmLimit(v int) *GroupUpsert {
+	u.Set(group.FieldRpmLimit, v)
+	return u
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateRpmLimit() *GroupUpsert {
+	u.SetExcluded(group.FieldRpmLimit)
+	return u
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *GroupUpsert) AddRpmLimit(v int) *GroupUpsert {
+	u.Add(group.FieldRpmLimit, v)
+	return u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *GroupUpsert) SetTpmLimit(v int) *GroupUpsert {
+	u.Set(group.FieldTpmLimit, v)
+	return u
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateTpmLimit() *GroupUpsert {
+	u.SetExcluded(group.FieldTpmLimit)
+	return u
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *GroupUpsert) AddTpmLimit(v int) *GroupUpsert {
+	u.Add(group.FieldTpmLimit, v)
+	return u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsert) SetSchedulingStrategy(v string) *GroupUpsert {
+	u.Set(group.FieldSchedulingStrategy, v)
+	return u
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSchedulingStrategy() *GroupUpsert {
+	u.SetExcluded(group.FieldSchedulingStrategy)
+	return u
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (u *GroupUpsert) SetQueuePriority(v int) *GroupUpsert {
+	u.Set(group.FieldQueuePriority, v)
+	return u
+}
+
+// UpdateQueuePriority sets the "queue_priority" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateQueuePriority() *GroupUpsert {
+	u.SetExcluded(group.FieldQueuePriority)
+	return u
+}
+
+// AddQueuePriority adds v to the "queue_priority" field.
+func (u *GroupUpsert) AddQueuePriority(v int) *GroupUpsert {
+	u.Add(group.FieldQueuePriority, v)
+	return u
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (u *GroupUpsert) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpsert {
+	u.Set(group.FieldModelFallbackChains, v)
+	return u
+}
+
+// UpdateModelFallbackChains sets the "model_fallback_chains" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateModelFallbackChains() *GroupUpsert {
+	u.SetExcluded(group.FieldModelFallbackChains)
+	return u
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (u *GroupUpsert) ClearModelFallbackChains() *GroupUpsert {
+	u.SetNull(group.FieldModelFallbackChains)
+	return u
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (u *GroupUpsert) SetCrossPlatformFailover(v bool) *GroupUpsert {
+	u.Set(group.FieldCrossPlatformFailover, v)
+	return u
+}
+
+// UpdateCrossPlatformFailover sets the "cross_platform_failover" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateCrossPlatformFailover() *GroupUpsert {
+	u.SetExcluded(group.FieldCrossPlatformFailover)
+	return u
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (u *GroupUpsert) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupUpsert {
+	u.Set(group.FieldShadowMirror, v)
+	return u
+}
+
+// UpdateShadowMirror sets the "shadow_mirror" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateShadowMirror() *GroupUpsert {
+	u.SetExcluded(group.FieldShadowMirror)
+	return u
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (u *GroupUpsert) ClearShadowMirror() *GroupUpsert {
+	u.SetNull(group.FieldShadowMirror)
+	return u
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (u *GroupUpsert) SetResponseCache(v *domain.ResponseCacheConfig) *GroupUpsert {
+	u.Set(group.FieldResponseCache, v)
+	return u
+}
+
+// UpdateResponseCache sets the "response_cache" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseCache() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseCache)
+	return u
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (u *GroupUpsert) ClearResponseCache() *GroupUpsert {
+	u.SetNull(group.FieldResponseCache)
+	return u
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (u *GroupUpsert) SetAutoCacheBreakpoints(v bool) *GroupUpsert {
+	u.Set(group.FieldAutoCacheBreakpoints, v)
+	return u
+}
+
+// UpdateAutoCacheBreakpoints sets the "auto_cache_breakpoints" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAutoCacheBreakpoints() *GroupUpsert {
+	u.SetExcluded(group.FieldAutoCacheBreakpoints)
+	return u
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (u *GroupUpsert) SetStreamResume(v bool) *GroupUpsert {
+	u.Set(group.FieldStreamResume, v)
+	return u
+}
+
+// UpdateStreamResume sets the "stream_resume" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateStreamResume() *GroupUpsert {
+	u.SetExcluded(group.FieldStreamResume)
+	return u
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (u *GroupUpsert) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupUpsert {
+	u.Set(group.FieldContextPolicy, v)
+	return u
+}
+
+// UpdateContextPolicy sets the "context_policy" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateContextPolicy() *GroupUpsert {
+	u.SetExcluded(group.FieldContextPolicy)
+	return u
+}
+
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (u *GroupUpsert) ClearContextPolicy() *GroupUpsert {
+	u.SetNull(group.FieldContextPolicy)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2295,6 +2733,230 @@ func (u *GroupUpsertOne) UpdateDefaultMappedModel() *GroupUpsertOne {
 	})
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsertOne) SetMaxIpsPerKeyPerHour(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxIpsPerKeyPerHour(v)
+	})
+}
+
+// AddMaxIpsPerKeyPerHour adds v to the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsertOne) AddMaxIpsPerKeyPerHour(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxIpsPerKeyPerHour(v)
+	})
+}
+
+// UpdateMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateMaxIpsPerKeyPerHour() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxIpsPerKeyPerHour()
+	})
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *GroupUpsertOne) SetRpmLimit(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *GroupUpsertOne) AddRpmLimit(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateRpmLimit() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *GroupUpsertOne) SetTpmLimit(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *GroupUpsertOne) AddTpmLimit(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateTpmLimit() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertOne) SetSchedulingStrategy(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSchedulingStrategy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (u *GroupUpsertOne) SetQueuePriority(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetQueuePriority(v)
+	})
+}
+
+// AddQueuePriority adds v to the "queue_priority" field.
+func (u *GroupUpsertOne) AddQueuePriority(v int) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddQueuePriority(v)
+	})
+}
+
+// UpdateQueuePriority sets the "queue_priority" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateQueuePriority() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateQueuePriority()
+	})
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (u *GroupUpsertOne) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelFallbackChains(v)
+	})
+}
+
+// UpdateModelFallbackChains sets the "model_fallback_chains" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateModelFallbackChains() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelFallbackChains()
+	})
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (u *GroupUpsertOne) ClearModelFallbackChains() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelFallbackChains()
+	})
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (u *GroupUpsertOne) SetCrossPlatformFailover(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetCrossPlatformFailover(v)
+	})
+}
+
+// UpdateCrossPlatformFailover sets the "cross_platform_failover" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateCrossPlatformFailover() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateCrossPlatformFailover()
+	})
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (u *GroupUpsertOne) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetShadowMirror(v)
+	})
+}
+
+// UpdateShadowMirror sets the "shadow_mirror" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateShadowMirror() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateShadowMirror()
+	})
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (u *GroupUpsertOne) ClearShadowMirror() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearShadowMirror()
+	})
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (u *GroupUpsertOne) SetResponseCache(v *domain.ResponseCacheConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCache(v)
+	})
+}
+
+// UpdateResponseCache sets the "response_cache" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseCache() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCache()
+	})
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (u *GroupUpsertOne) ClearResponseCache() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearResponseCache()
+	})
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (u *GroupUpsertOne) SetAutoCacheBreakpoints(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAutoCacheBreakpoints(v)
+	})
+}
+
+// UpdateAutoCacheBreakpoints sets the "auto_cache_breakpoints" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAutoCacheBreakpoints() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAutoCacheBreakpoints()
+	})
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (u *GroupUpsertOne) SetStreamResume(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetStreamResume(v)
+	})
+}
+
+// UpdateStreamResume sets the "stream_resume" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateStreamResume() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateStreamResume()
+	})
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (u *GroupUpsertOne) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextPolicy(v)
+	})
+}
+
+// UpdateContextPolicy sets the "context_policy" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateContextPolicy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextPolicy()
+	})
+}
+
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (u *GroupUpsertOne) ClearContextPolicy() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearContextPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -3157,6 +3819,230 @@ func (u *GroupUpsertBulk) UpdateDefaultMappedModel() *GroupUpsertBulk {
 	})
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsertBulk) SetMaxIpsPerKeyPerHour(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxIpsPerKeyPerHour(v)
+	})
+}
+
+// AddMaxIpsPerKeyPerHour adds v to the "max_ips_per_key_per_hour" field.
+func (u *GroupUpsertBulk) AddMaxIpsPerKeyPerHour(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxIpsPerKeyPerHour(v)
+	})
+}
+
+// UpdateMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateMaxIpsPerKeyPerHour() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxIpsPerKeyPerHour()
+	})
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (u *GroupUpsertBulk) SetRpmLimit(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetRpmLimit(v)
+	})
+}
+
+// AddRpmLimit adds v to the "rpm_limit" field.
+func (u *GroupUpsertBulk) AddRpmLimit(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddRpmLimit(v)
+	})
+}
+
+// UpdateRpmLimit sets the "rpm_limit" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateRpmLimit() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateRpmLimit()
+	})
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (u *GroupUpsertBulk) SetTpmLimit(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetTpmLimit(v)
+	})
+}
+
+// AddTpmLimit adds v to the "tpm_limit" field.
+func (u *GroupUpsertBulk) AddTpmLimit(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddTpmLimit(v)
+	})
+}
+
+// UpdateTpmLimit sets the "tpm_limit" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateTpmLimit() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateTpmLimit()
+	})
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (u *GroupUpsertBulk) SetSchedulingStrategy(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSchedulingStrategy(v)
+	})
+}
+
+// UpdateSchedulingStrategy sets the "scheduling_strategy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSchedulingStrategy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSchedulingStrategy()
+	})
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (u *GroupUpsertBulk) SetQueuePriority(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetQueuePriority(v)
+	})
+}
+
+// AddQueuePriority adds v to the "queue_priority" field.
+func (u *GroupUpsertBulk) AddQueuePriority(v int) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddQueuePriority(v)
+	})
+}
+
+// UpdateQueuePriority sets the "queue_priority" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateQueuePriority() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateQueuePriority()
+	})
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (u *GroupUpsertBulk) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModelFallbackChains(v)
+	})
+}
+
+// UpdateModelFallbackChains sets the "model_fallback_chains" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateModelFallbackChains() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModelFallbackChains()
+	})
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (u *GroupUpsertBulk) ClearModelFallbackChains() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearModelFallbackChains()
+	})
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (u *GroupUpsertBulk) SetCrossPlatformFailover(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetCrossPlatformFailover(v)
+	})
+}
+
+// UpdateCrossPlatformFailover sets the "cross_platform_failover" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateCrossPlatformFailover() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateCrossPlatformFailover()
+	})
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (u *GroupUpsertBulk) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetShadowMirror(v)
+	})
+}
+
+// UpdateShadowMirror sets the "shadow_mirror" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateShadowMirror() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateShadowMirror()
+	})
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (u *GroupUpsertBulk) ClearShadowMirror() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearShadowMirror()
+	})
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (u *GroupUpsertBulk) SetResponseCache(v *domain.ResponseCacheConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseCache(v)
+	})
+}
+
+// UpdateResponseCache sets the "response_cache" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseCache() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseCache()
+	})
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (u *GroupUpsertBulk) ClearResponseCache() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearResponseCache()
+	})
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (u *GroupUpsertBulk) SetAutoCacheBreakpoints(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAutoCacheBreakpoints(v)
+	})
+}
+
+// UpdateAutoCacheBreakpoints sets the "auto_cache_breakpoints" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAutoCacheBreakpoints() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAutoCacheBreakpoints()
+	})
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (u *GroupUpsertBulk) SetStreamResume(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetStreamResume(v)
+	})
+}
+
+// UpdateStreamResume sets the "stream_resume" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateStreamResume() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateStreamResume()
+	})
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (u *GroupUpsertBulk) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetContextPolicy(v)
+	})
+}
+
+// UpdateContextPolicy sets the "context_policy" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateContextPolicy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateContextPolicy()
+	})
+}
+
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (u *GroupUpsertBulk) ClearContextPolicy() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearContextPolicy()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -20,6 +20,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
 	"github.com/Wei-Shaw/sub2api/ent/usersubscription"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // GroupUpdate is the builder for updating Group entities.
@@ -653,6 +654,200 @@ func (_u *GroupUpdate) SetNillableDefaultMappedModel(v *string) *GroupUpdate {
 	return _u
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (_u *GroupUpdate) SetMaxIpsPerKeyPerHour(v int) *GroupUpdate {
+	_u.mutation.ResetMaxIpsPerKeyPerHour()
+	_u.mutation.SetMaxIpsPerKeyPerHour(v)
+	return _u
+}
+
+// SetNillableMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableMaxIpsPerKeyPerHour(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetMaxIpsPerKeyPerHour(*v)
+	}
+	return _u
+}
+
+// AddMaxIpsPerKeyPerHour adds value to the "max_ips_per_key_per_hour" field.
+func (_u *GroupUpdate) AddMaxIpsPerKeyPerHour(v int) *GroupUpdate {
+	_u.mutation.AddMaxIpsPerKeyPerHour(v)
+	return _u
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *GroupUpdate) SetRpmLimit(v int) *GroupUpdate {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableRpmLimit(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *GroupUpdate) AddRpmLimit(v int) *GroupUpdate {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *GroupUpdate) SetTpmLimit(v int) *GroupUpdate {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableTpmLimit(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *GroupUpdate) AddTpmLimit(v int) *GroupUpdate {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdate) SetSchedulingStrategy(v string) *GroupUpdate {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSchedulingStrategy(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (_u *GroupUpdate) SetQueuePriority(v int) *GroupUpdate {
+	_u.mutation.ResetQueuePriority()
+	_u.mutation.SetQueuePriority(v)
+	return _u
+}
+
+// SetNillableQueuePriority sets the "queue_priority" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableQueuePriority(v *int) *GroupUpdate {
+	if v != nil {
+		_u.SetQueuePriority(*v)
+	}
+	return _u
+}
+
+// AddQueuePriority adds value to the "queue_priority" field.
+func (_u *GroupUpdate) AddQueuePriority(v int) *GroupUpdate {
+	_u.mutation.AddQueuePriority(v)
+	return _u
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (_u *GroupUpdate) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpdate {
+	_u.mutation.SetModelFallbackChains(v)
+	return _u
+}
+
+// AppendModelFallbackChains appends value to the "model_fallback_chains" field.
+func (_u *GroupUpdate) AppendModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpdate {
+	_u.mutation.AppendModelFallbackChains(v)
+	return _u
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (_u *GroupUpdate) ClearModelFallbackChains() *GroupUpdate {
+	_u.mutation.ClearModelFallbackChains()
+	return _u
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (_u *GroupUpdate) SetCrossPlatformFailover(v bool) *GroupUpdate {
+	_u.mutation.SetCrossPlatformFailover(v)
+	return _u
+}
+
+// SetNillableCrossPlatformFailover sets the "cross_platform_failover" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableCrossPlatformFailover(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetCrossPlatformFailover(*v)
+	}
+	return _u
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (_u *GroupUpdate) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupUpdate {
+	_u.mutation.SetShadowMirror(v)
+	return _u
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (_u *GroupUpdate) ClearShadowMirror() *GroupUpdate {
+	_u.mutation.ClearShadowMirror()
+	return _u
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (_u *GroupUpdate) SetResponseCache(v *domain.ResponseCacheConfig) *GroupUpdate {
+	_u.mutation.SetResponseCache(v)
+	return _u
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (_u *GroupUpdate) ClearResponseCache() *GroupUpdate {
+	_u.mutation.ClearResponseCache()
+	return _u
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (_u *GroupUpdate) SetAutoCacheBreakpoints(v bool) *GroupUpdate {
+	_u.mutation.SetAutoCacheBreakpoints(v)
+	return _u
+}
+
+// SetNillableAutoCacheBreakpoints sets the "auto_cache_breakpoints" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAutoCacheBreakpoints(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetAutoCacheBreakpoints(*v)
+	}
+	return _u
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (_u *GroupUpdate) SetStreamResume(v bool) *GroupUpdate {
+	_u.mutation.SetStreamResume(v)
+	return _u
+}
+
+// SetNillableStreamResume sets the "stream_resume" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableStreamResume(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetStreamResume(*v)
+	}
+	return _u
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (_u *GroupUpdate) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupUpdate {
+	_u.mutation.SetContextPolicy(v)
+	return _u
+}
+
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (_u *GroupUpdate) ClearContextPolicy() *GroupUpdate {
+	_u.mutation.ClearContextPolicy()
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -943,6 +1138,11 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1149,6 +1349,71 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.MaxIpsPerKeyPerHour(); ok {
+		_spec.SetField(group.FieldMaxIpsPerKeyPerHour, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMaxIpsPerKeyPerHour(); ok {
+		_spec.AddField(group.FieldMaxIpsPerKeyPerHour, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(group.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(group.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.QueuePriority(); ok {
+		_spec.SetField(group.FieldQueuePriority, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedQueuePriority(); ok {
+		_spec.AddField(group.FieldQueuePriority, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ModelFallbackChains(); ok {
+		_spec.SetField(group.FieldModelFallbackChains, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelFallbackChains(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldModelFallbackChains, value)
+		})
+	}
+	if _u.mutation.ModelFallbackChainsCleared() {
+		_spec.ClearField(group.FieldModelFallbackChains, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.CrossPlatformFailover(); ok {
+		_spec.SetField(group.FieldCrossPlatformFailover, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ShadowMirror(); ok {
+		_spec.SetField(group.FieldShadowMirror, field.TypeJSON, value)
+	}
+	if _u.mutation.ShadowMirrorCleared() {
+		_spec.ClearField(group.FieldShadowMirror, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ResponseCache(); ok {
+		_spec.SetField(group.FieldResponseCache, field.TypeJSON, value)
+	}
+	if _u.mutation.ResponseCacheCleared() {
+		_spec.ClearField(group.FieldResponseCache, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AutoCacheBreakpoints(); ok {
+		_spec.SetField(group.FieldAutoCacheBreakpoints, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.StreamResume(); ok {
+		_spec.SetField(group.FieldStreamResume, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ContextPolicy(); ok {
+		_spec.SetField(group.FieldContextPolicy, field.TypeJSON, value)
+	}
+	if _u.mutation.ContextPolicyCleared() {
+		_spec.ClearField(group.FieldContextPolicy, field.TypeJSON)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -2081,6 +2346,200 @@ func (_u *GroupUpdateOne) SetNillableDefaultMappedModel(v *string) *GroupUpdateO
 	return _u
 }
 
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (_u *GroupUpdateOne) SetMaxIpsPerKeyPerHour(v int) *GroupUpdateOne {
+	_u.mutation.ResetMaxIpsPerKeyPerHour()
+	_u.mutation.SetMaxIpsPerKeyPerHour(v)
+	return _u
+}
+
+// SetNillableMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableMaxIpsPerKeyPerHour(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetMaxIpsPerKeyPerHour(*v)
+	}
+	return _u
+}
+
+// AddMaxIpsPerKeyPerHour adds value to the "max_ips_per_key_per_hour" field.
+func (_u *GroupUpdateOne) AddMaxIpsPerKeyPerHour(v int) *GroupUpdateOne {
+	_u.mutation.AddMaxIpsPerKeyPerHour(v)
+	return _u
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (_u *GroupUpdateOne) SetRpmLimit(v int) *GroupUpdateOne {
+	_u.mutation.ResetRpmLimit()
+	_u.mutation.SetRpmLimit(v)
+	return _u
+}
+
+// SetNillableRpmLimit sets the "rpm_limit" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableRpmLimit(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetRpmLimit(*v)
+	}
+	return _u
+}
+
+// AddRpmLimit adds value to the "rpm_limit" field.
+func (_u *GroupUpdateOne) AddRpmLimit(v int) *GroupUpdateOne {
+	_u.mutation.AddRpmLimit(v)
+	return _u
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (_u *GroupUpdateOne) SetTpmLimit(v int) *GroupUpdateOne {
+	_u.mutation.ResetTpmLimit()
+	_u.mutation.SetTpmLimit(v)
+	return _u
+}
+
+// SetNillableTpmLimit sets the "tpm_limit" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableTpmLimit(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetTpmLimit(*v)
+	}
+	return _u
+}
+
+// AddTpmLimit adds value to the "tpm_limit" field.
+func (_u *GroupUpdateOne) AddTpmLimit(v int) *GroupUpdateOne {
+	_u.mutation.AddTpmLimit(v)
+	return _u
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (_u *GroupUpdateOne) SetSchedulingStrategy(v string) *GroupUpdateOne {
+	_u.mutation.SetSchedulingStrategy(v)
+	return _u
+}
+
+// SetNillableSchedulingStrategy sets the "scheduling_strategy" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSchedulingStrategy(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSchedulingStrategy(*v)
+	}
+	return _u
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (_u *GroupUpdateOne) SetQueuePriority(v int) *GroupUpdateOne {
+	_u.mutation.ResetQueuePriority()
+	_u.mutation.SetQueuePriority(v)
+	return _u
+}
+
+// SetNillableQueuePriority sets the "queue_priority" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableQueuePriority(v *int) *GroupUpdateOne {
+	if v != nil {
+		_u.SetQueuePriority(*v)
+	}
+	return _u
+}
+
+// AddQueuePriority adds value to the "queue_priority" field.
+func (_u *GroupUpdateOne) AddQueuePriority(v int) *GroupUpdateOne {
+	_u.mutation.AddQueuePriority(v)
+	return _u
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (_u *GroupUpdateOne) SetModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpdateOne {
+	_u.mutation.SetModelFallbackChains(v)
+	return _u
+}
+
+// AppendModelFallbackChains appends value to the "model_fallback_chains" field.
+func (_u *GroupUpdateOne) AppendModelFallbackChains(v []domain.ModelFallbackChain) *GroupUpdateOne {
+	_u.mutation.AppendModelFallbackChains(v)
+	return _u
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (_u *GroupUpdateOne) ClearModelFallbackChains() *GroupUpdateOne {
+	_u.mutation.ClearModelFallbackChains()
+	return _u
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (_u *GroupUpdateOne) SetCrossPlatformFailover(v bool) *GroupUpdateOne {
+	_u.mutation.SetCrossPlatformFailover(v)
+	return _u
+}
+
+// SetNillableCrossPlatformFailover sets the "cross_platform_failover" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableCrossPlatformFailover(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetCrossPlatformFailover(*v)
+	}
+	return _u
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (_u *GroupUpdateOne) SetShadowMirror(v *domain.ShadowMirrorConfig) *GroupUpdateOne {
+	_u.mutation.SetShadowMirror(v)
+	return _u
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (_u *GroupUpdateOne) ClearShadowMirror() *GroupUpdateOne {
+	_u.mutation.ClearShadowMirror()
+	return _u
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (_u *GroupUpdateOne) SetResponseCache(v *domain.ResponseCacheConfig) *GroupUpdateOne {
+	_u.mutation.SetResponseCache(v)
+	return _u
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (_u *GroupUpdateOne) ClearResponseCache() *GroupUpdateOne {
+	_u.mutation.ClearResponseCache()
+	return _u
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (_u *GroupUpdateOne) SetAutoCacheBreakpoints(v bool) *GroupUpdateOne {
+	_u.mutation.SetAutoCacheBreakpoints(v)
+	return _u
+}
+
+// SetNillableAutoCacheBreakpoints sets the "auto_cache_breakpoints" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAutoCacheBreakpoints(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAutoCacheBreakpoints(*v)
+	}
+	return _u
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (_u *GroupUpdateOne) SetStreamResume(v bool) *GroupUpdateOne {
+	_u.mutation.SetStreamResume(v)
+	return _u
+}
+
+// SetNillableStreamResume sets the "stream_resume" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableStreamResume(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetStreamResume(*v)
+	}
+	return _u
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (_u *GroupUpdateOne) SetContextPolicy(v *domain.ContextPolicyConfig) *GroupUpdateOne {
+	_u.mutation.SetContextPolicy(v)
+	return _u
+}
+
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (_u *GroupUpdateOne) ClearContextPolicy() *GroupUpdateOne {
+	_u.mutation.ClearContextPolicy()
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2384,6 +2843,11 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.SchedulingStrategy(); ok {
+		if err := group.SchedulingStrategyValidator(v); err != nil {
+			return &ValidationError{Name: "scheduling_strategy", err: fmt.Errorf(`ent: validator failed for field "Group.scheduling_strategy": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2607,6 +3071,71 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.DefaultMappedModel(); ok {
 		_spec.SetField(group.FieldDefaultMappedModel, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.MaxIpsPerKeyPerHour(); ok {
+		_spec.SetField(group.FieldMaxIpsPerKeyPerHour, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedMaxIpsPerKeyPerHour(); ok {
+		_spec.AddField(group.FieldMaxIpsPerKeyPerHour, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.RpmLimit(); ok {
+		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedRpmLimit(); ok {
+		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TpmLimit(); ok {
+		_spec.SetField(group.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTpmLimit(); ok {
+		_spec.AddField(group.FieldTpmLimit, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.SchedulingStrategy(); ok {
+		_spec.SetField(group.FieldSchedulingStrategy, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.QueuePriority(); ok {
+		_spec.SetField(group.FieldQueuePriority, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedQueuePriority(); ok {
+		_spec.AddField(group.FieldQueuePriority, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.ModelFallbackChains(); ok {
+		_spec.SetField(group.FieldModelFallbackChains, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedModelFallbackChains(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, group.FieldModelFallbackChains, value)
+		})
+	}
+	if _u.mutation.ModelFallbackChainsCleared() {
+		_spec.ClearField(group.FieldModelFallbackChains, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.CrossPlatformFailover(); ok {
+		_spec.SetField(group.FieldCrossPlatformFailover, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ShadowMirror(); ok {
+		_spec.SetField(group.FieldShadowMirror, field.TypeJSON, value)
+	}
+	if _u.mutation.ShadowMirrorCleared() {
+		_spec.ClearField(group.FieldShadowMirror, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.ResponseCache(); ok {
+		_spec.SetField(group.FieldResponseCache, field.TypeJSON, value)
+	}
+	if _u.mutation.ResponseCacheCleared() {
+		_spec.ClearField(group.FieldResponseCache, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.AutoCacheBreakpoints(); ok {
+		_spec.SetField(group.FieldAutoCacheBreakpoints, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.StreamResume(); ok {
+		_spec.SetField(group.FieldStreamResume, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ContextPolicy(); ok {
+		_spec.SetField(group.FieldContextPolicy, field.TypeJSON, value)
+	}
+	if _u.mutation.ContextPolicyCleared() {
+		_spec.ClearField(group.FieldContextPolicy, field.TypeJSON)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
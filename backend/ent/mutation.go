@@ -72,51 +72,57 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                      Op
+	typ                     string
+	id                      *int64
+	created_at              *time.Time
+	updated_at              *time.Time
+	deleted_at              *time.Time
+	key                     *string
+	key_prefix              *string
+	name                    *string
+	status                  *string
+	last_used_at            *time.Time
+	ip_whitelist            *[]string
+	appendip_whitelist      []string
+	ip_blacklist            *[]string
+	appendip_blacklist      []string
+	quota                   *float64
+	addquota                *float64
+	quota_used              *float64
+	addquota_used           *float64
+	expires_at              *time.Time
+	rate_limit_5h           *float64
+	addrate_limit_5h        *float64
+	rate_limit_1d           *float64
+	addrate_limit_1d        *float64
+	rate_limit_7d           *float64
+	addrate_limit_7d        *float64
+	usage_5h                *float64
+	addusage_5h             *float64
+	usage_1d                *float64
+	addusage_1d             *float64
+	usage_7d                *float64
+	addusage_7d             *float64
+	window_5h_start         *time.Time
+	window_1d_start         *time.Time
+	window_7d_start         *time.Time
+	previous_key            *string
+	previous_key_prefix     *string
+	previous_key_expires_at *time.Time
+	rotated_at              *time.Time
+	policy                  *domain.APIKeyPolicy
+	clearedFields           map[string]struct{}
+	user                    *int64
+	cleareduser             bool
+	group                   *int64
+	clearedgroup            bool
+	usage_logs              map[int64]struct{}
+	removedusage_logs       map[int64]struct{}
+	clearedusage_logs       bool
+	done                    bool
+	oldValue                func(context.Context) (*APIKey, error)
+	predicates              []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -410,6 +416,42 @@ func (m *APIKeyMutation) ResetKey() {
 	m.key = nil
 }
 
+// SetKeyPrefix sets the "key_prefix" field.
+func (m *APIKeyMutation) SetKeyPrefix(s string) {
+	m.key_prefix = &s
+}
+
+// KeyPrefix returns the value of the "key_prefix" field in the mutation.
+func (m *APIKeyMutation) KeyPrefix() (r string, exists bool) {
+	v := m.key_prefix
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldKeyPrefix returns the old "key_prefix" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldKeyPrefix(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldKeyPrefix is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldKeyPrefix requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldKeyPrefix: %w", err)
+	}
+	return oldValue.KeyPrefix, nil
+}
+
+// ResetKeyPrefix resets all changes to the "key_prefix" field.
+func (m *APIKeyMutation) ResetKeyPrefix() {
+	m.key_prefix = nil
+}
+
 // SetName sets the "name" field.
 func (m *APIKeyMutation) SetName(s string) {
 	m.name = &s
@@ -1354,6 +1396,238 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetPreviousKey sets the "previous_key" field.
+func (m *APIKeyMutation) SetPreviousKey(s string) {
+	m.previous_key = &s
+}
+
+// PreviousKey returns the value of the "previous_key" field in the mutation.
+func (m *APIKeyMutation) PreviousKey() (r string, exists bool) {
+	v := m.previous_key
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPreviousKey returns the old "previous_key" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldPreviousKey(ctx context.Context) (v *string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPreviousKey is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPreviousKey requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPreviousKey: %w", err)
+	}
+	return oldValue.PreviousKey, nil
+}
+
+// ClearPreviousKey clears the value of the "previous_key" field.
+func (m *APIKeyMutation) ClearPreviousKey() {
+	m.previous_key = nil
+	m.clearedFields[apikey.FieldPreviousKey] = struct{}{}
+}
+
+// PreviousKeyCleared returns if the "previous_key" field was cleared in this mutation.
+func (m *APIKeyMutation) PreviousKeyCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldPreviousKey]
+	return ok
+}
+
+// ResetPreviousKey resets all changes to the "previous_key" field.
+func (m *APIKeyMutation) ResetPreviousKey() {
+	m.previous_key = nil
+	delete(m.clearedFields, apikey.FieldPreviousKey)
+}
+
+// SetPreviousKeyPrefix sets the "previous_key_prefix" field.
+func (m *APIKeyMutation) SetPreviousKeyPrefix(s string) {
+	m.previous_key_prefix = &s
+}
+
+// PreviousKeyPrefix returns the value of the "previous_key_prefix" field in the mutation.
+func (m *APIKeyMutation) PreviousKeyPrefix() (r string, exists bool) {
+	v := m.previous_key_prefix
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPreviousKeyPrefix returns the old "previous_key_prefix" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldPreviousKeyPrefix(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPreviousKeyPrefix is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPreviousKeyPrefix requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPreviousKeyPrefix: %w", err)
+	}
+	return oldValue.PreviousKeyPrefix, nil
+}
+
+// ResetPreviousKeyPrefix resets all changes to the "previous_key_prefix" field.
+func (m *APIKeyMutation) ResetPreviousKeyPrefix() {
+	m.previous_key_prefix = nil
+}
+
+// SetPreviousKeyExpiresAt sets the "previous_key_expires_at" field.
+func (m *APIKeyMutation) SetPreviousKeyExpiresAt(t time.Time) {
+	m.previous_key_expires_at = &t
+}
+
+// PreviousKeyExpiresAt returns the value of the "previous_key_expires_at" field in the mutation.
+func (m *APIKeyMutation) PreviousKeyExpiresAt() (r time.Time, exists bool) {
+	v := m.previous_key_expires_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPreviousKeyExpiresAt returns the old "previous_key_expires_at" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldPreviousKeyExpiresAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPreviousKeyExpiresAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPreviousKeyExpiresAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPreviousKeyExpiresAt: %w", err)
+	}
+	return oldValue.PreviousKeyExpiresAt, nil
+}
+
+// ClearPreviousKeyExpiresAt clears the value of the "previous_key_expires_at" field.
+func (m *APIKeyMutation) ClearPreviousKeyExpiresAt() {
+	m.previous_key_expires_at = nil
+	m.clearedFields[apikey.FieldPreviousKeyExpiresAt] = struct{}{}
+}
+
+// PreviousKeyExpiresAtCleared returns if the "previous_key_expires_at" field was cleared in this mutation.
+func (m *APIKeyMutation) PreviousKeyExpiresAtCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldPreviousKeyExpiresAt]
+	return ok
+}
+
+// ResetPreviousKeyExpiresAt resets all changes to the "previous_key_expires_at" field.
+func (m *APIKeyMutation) ResetPreviousKeyExpiresAt() {
+	m.previous_key_expires_at = nil
+	delete(m.clearedFields, apikey.FieldPreviousKeyExpiresAt)
+}
+
+// SetRotatedAt sets the "rotated_at" field.
+func (m *APIKeyMutation) SetRotatedAt(t time.Time) {
+	m.rotated_at = &t
+}
+
+// RotatedAt returns the value of the "rotated_at" field in the mutation.
+func (m *APIKeyMutation) RotatedAt() (r time.Time, exists bool) {
+	v := m.rotated_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRotatedAt returns the old "rotated_at" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldRotatedAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRotatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRotatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRotatedAt: %w", err)
+	}
+	return oldValue.RotatedAt, nil
+}
+
+// ClearRotatedAt clears the value of the "rotated_at" field.
+func (m *APIKeyMutation) ClearRotatedAt() {
+	m.rotated_at = nil
+	m.clearedFields[apikey.FieldRotatedAt] = struct{}{}
+}
+
+// RotatedAtCleared returns if the "rotated_at" field was cleared in this mutation.
+func (m *APIKeyMutation) RotatedAtCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldRotatedAt]
+	return ok
+}
+
+// ResetRotatedAt resets all changes to the "rotated_at" field.
+func (m *APIKeyMutation) ResetRotatedAt() {
+	m.rotated_at = nil
+	delete(m.clearedFields, apikey.FieldRotatedAt)
+}
+
+// SetPolicy sets the "policy" field.
+func (m *APIKeyMutation) SetPolicy(dkp domain.APIKeyPolicy) {
+	m.policy = &dkp
+}
+
+// Policy returns the value of the "policy" field in the mutation.
+func (m *APIKeyMutation) Policy() (r domain.APIKeyPolicy, exists bool) {
+	v := m.policy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPolicy returns the old "policy" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldPolicy(ctx context.Context) (v domain.APIKeyPolicy, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPolicy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPolicy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPolicy: %w", err)
+	}
+	return oldValue.Policy, nil
+}
+
+// ClearPolicy clears the value of the "policy" field.
+func (m *APIKeyMutation) ClearPolicy() {
+	m.policy = nil
+	m.clearedFields[apikey.FieldPolicy] = struct{}{}
+}
+
+// PolicyCleared returns if the "policy" field was cleared in this mutation.
+func (m *APIKeyMutation) PolicyCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldPolicy]
+	return ok
+}
+
+// ResetPolicy resets all changes to the "policy" field.
+func (m *APIKeyMutation) ResetPolicy() {
+	m.policy = nil
+	delete(m.clearedFields, apikey.FieldPolicy)
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1496,7 +1770,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 29)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1512,6 +1786,9 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.key != nil {
 		fields = append(fields, apikey.FieldKey)
 	}
+	if m.key_prefix != nil {
+		fields = append(fields, apikey.FieldKeyPrefix)
+	}
 	if m.name != nil {
 		fields = append(fields, apikey.FieldName)
 	}
@@ -1566,6 +1843,21 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.previous_key != nil {
+		fields = append(fields, apikey.FieldPreviousKey)
+	}
+	if m.previous_key_prefix != nil {
+		fields = append(fields, apikey.FieldPreviousKeyPrefix)
+	}
+	if m.previous_key_expires_at != nil {
+		fields = append(fields, apikey.FieldPreviousKeyExpiresAt)
+	}
+	if m.rotated_at != nil {
+		fields = append(fields, apikey.FieldRotatedAt)
+	}
+	if m.policy != nil {
+		fields = append(fields, apikey.FieldPolicy)
+	}
 	return fields
 }
 
@@ -1584,6 +1876,8 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.UserID()
 	case apikey.FieldKey:
 		return m.Key()
+	case apikey.FieldKeyPrefix:
+		return m.KeyPrefix()
 	case apikey.FieldName:
 		return m.Name()
 	case apikey.FieldGroupID:
@@ -1620,6 +1914,16 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldPreviousKey:
+		return m.PreviousKey()
+	case apikey.FieldPreviousKeyPrefix:
+		return m.PreviousKeyPrefix()
+	case apikey.FieldPreviousKeyExpiresAt:
+		return m.PreviousKeyExpiresAt()
+	case apikey.FieldRotatedAt:
+		return m.RotatedAt()
+	case apikey.FieldPolicy:
+		return m.Policy()
 	}
 	return nil, false
 }
@@ -1639,6 +1943,8 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldUserID(ctx)
 	case apikey.FieldKey:
 		return m.OldKey(ctx)
+	case apikey.FieldKeyPrefix:
+		return m.OldKeyPrefix(ctx)
 	case apikey.FieldName:
 		return m.OldName(ctx)
 	case apikey.FieldGroupID:
@@ -1675,6 +1981,16 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldPreviousKey:
+		return m.OldPreviousKey(ctx)
+	case apikey.FieldPreviousKeyPrefix:
+		return m.OldPreviousKeyPrefix(ctx)
+	case apikey.FieldPreviousKeyExpiresAt:
+		return m.OldPreviousKeyExpiresAt(ctx)
+	case apikey.FieldRotatedAt:
+		return m.OldRotatedAt(ctx)
+	case apikey.FieldPolicy:
+		return m.OldPolicy(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1719,6 +2035,13 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetKey(v)
 		return nil
+	case apikey.FieldKeyPrefix:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetKeyPrefix(v)
+		return nil
 	case apikey.FieldName:
 		v, ok := value.(string)
 		if !ok {
@@ -1845,6 +2168,41 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldPreviousKey:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPreviousKey(v)
+		return nil
+	case apikey.FieldPreviousKeyPrefix:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPreviousKeyPrefix(v)
+		return nil
+	case apikey.FieldPreviousKeyExpiresAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPreviousKeyExpiresAt(v)
+		return nil
+	case apikey.FieldRotatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRotatedAt(v)
+		return nil
+	case apikey.FieldPolicy:
+		v, ok := value.(domain.APIKeyPolicy)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPolicy(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -2001,6 +2359,18 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldWindow7dStart) {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.FieldCleared(apikey.FieldPreviousKey) {
+		fields = append(fields, apikey.FieldPreviousKey)
+	}
+	if m.FieldCleared(apikey.FieldPreviousKeyExpiresAt) {
+		fields = append(fields, apikey.FieldPreviousKeyExpiresAt)
+	}
+	if m.FieldCleared(apikey.FieldRotatedAt) {
+		fields = append(fields, apikey.FieldRotatedAt)
+	}
+	if m.FieldCleared(apikey.FieldPolicy) {
+		fields = append(fields, apikey.FieldPolicy)
+	}
 	return fields
 }
 
@@ -2042,6 +2412,18 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ClearWindow7dStart()
 		return nil
+	case apikey.FieldPreviousKey:
+		m.ClearPreviousKey()
+		return nil
+	case apikey.FieldPreviousKeyExpiresAt:
+		m.ClearPreviousKeyExpiresAt()
+		return nil
+	case apikey.FieldRotatedAt:
+		m.ClearRotatedAt()
+		return nil
+	case apikey.FieldPolicy:
+		m.ClearPolicy()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey nullable field %s", name)
 }
@@ -2065,6 +2447,9 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldKey:
 		m.ResetKey()
 		return nil
+	case apikey.FieldKeyPrefix:
+		m.ResetKeyPrefix()
+		return nil
 	case apikey.FieldName:
 		m.ResetName()
 		return nil
@@ -2119,6 +2504,21 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldPreviousKey:
+		m.ResetPreviousKey()
+		return nil
+	case apikey.FieldPreviousKeyPrefix:
+		m.ResetPreviousKeyPrefix()
+		return nil
+	case apikey.FieldPreviousKeyExpiresAt:
+		m.ResetPreviousKeyExpiresAt()
+		return nil
+	case apikey.FieldRotatedAt:
+		m.ResetRotatedAt()
+		return nil
+	case apikey.FieldPolicy:
+		m.ResetPolicy()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -8252,6 +8652,23 @@ type GroupMutation struct {
 	addsort_order                           *int
 	allow_messages_dispatch                 *bool
 	default_mapped_model                    *string
+	max_ips_per_key_per_hour                *int
+	addmax_ips_per_key_per_hour             *int
+	rpm_limit                               *int
+	addrpm_limit                            *int
+	tpm_limit                               *int
+	addtpm_limit                            *int
+	scheduling_strategy                     *string
+	queue_priority                          *int
+	addqueue_priority                       *int
+	model_fallback_chains                   *[]domain.ModelFallbackChain
+	appendmodel_fallback_chains             []domain.ModelFallbackChain
+	cross_platform_failover                 *bool
+	shadow_mirror                           **domain.ShadowMirrorConfig
+	response_cache                          **domain.ResponseCacheConfig
+	auto_cache_breakpoints                  *bool
+	stream_resume                           *bool
+	context_policy                          **domain.ContextPolicyConfig
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -10054,18 +10471,598 @@ func (m *GroupMutation) OldDefaultMappedModel(ctx context.Context) (v string, er
 		return v, errors.New("OldDefaultMappedModel is only allowed on UpdateOne operations")
 	}
 	if m.id == nil || m.oldValue == nil {
-		return v, errors.New("OldDefaultMappedModel requires an ID field in the mutation")
+		return v, errors.New("OldDefaultMappedModel requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDefaultMappedModel: %w", err)
+	}
+	return oldValue.DefaultMappedModel, nil
+}
+
+// ResetDefaultMappedModel resets all changes to the "default_mapped_model" field.
+func (m *GroupMutation) ResetDefaultMappedModel() {
+	m.default_mapped_model = nil
+}
+
+// SetMaxIpsPerKeyPerHour sets the "max_ips_per_key_per_hour" field.
+func (m *GroupMutation) SetMaxIpsPerKeyPerHour(i int) {
+	m.max_ips_per_key_per_hour = &i
+	m.addmax_ips_per_key_per_hour = nil
+}
+
+// MaxIpsPerKeyPerHour returns the value of the "max_ips_per_key_per_hour" field in the mutation.
+func (m *GroupMutation) MaxIpsPerKeyPerHour() (r int, exists bool) {
+	v := m.max_ips_per_key_per_hour
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxIpsPerKeyPerHour returns the old "max_ips_per_key_per_hour" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldMaxIpsPerKeyPerHour(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxIpsPerKeyPerHour is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxIpsPerKeyPerHour requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxIpsPerKeyPerHour: %w", err)
+	}
+	return oldValue.MaxIpsPerKeyPerHour, nil
+}
+
+// AddMaxIpsPerKeyPerHour adds i to the "max_ips_per_key_per_hour" field.
+func (m *GroupMutation) AddMaxIpsPerKeyPerHour(i int) {
+	if m.addmax_ips_per_key_per_hour != nil {
+		*m.addmax_ips_per_key_per_hour += i
+	} else {
+		m.addmax_ips_per_key_per_hour = &i
+	}
+}
+
+// AddedMaxIpsPerKeyPerHour returns the value that was added to the "max_ips_per_key_per_hour" field in this mutation.
+func (m *GroupMutation) AddedMaxIpsPerKeyPerHour() (r int, exists bool) {
+	v := m.addmax_ips_per_key_per_hour
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxIpsPerKeyPerHour resets all changes to the "max_ips_per_key_per_hour" field.
+func (m *GroupMutation) ResetMaxIpsPerKeyPerHour() {
+	m.max_ips_per_key_per_hour = nil
+	m.addmax_ips_per_key_per_hour = nil
+}
+
+// SetRpmLimit sets the "rpm_limit" field.
+func (m *GroupMutation) SetRpmLimit(i int) {
+	m.rpm_limit = &i
+	m.addrpm_limit = nil
+}
+
+// RpmLimit returns the value of the "rpm_limit" field in the mutation.
+func (m *GroupMutation) RpmLimit() (r int, exists bool) {
+	v := m.rpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRpmLimit returns the old "rpm_limit" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldRpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRpmLimit: %w", err)
+	}
+	return oldValue.RpmLimit, nil
+}
+
+// AddRpmLimit adds i to the "rpm_limit" field.
+func (m *GroupMutation) AddRpmLimit(i int) {
+	if m.addrpm_limit != nil {
+		*m.addrpm_limit += i
+	} else {
+		m.addrpm_limit = &i
+	}
+}
+
+// AddedRpmLimit returns the value that was added to the "rpm_limit" field in this mutation.
+func (m *GroupMutation) AddedRpmLimit() (r int, exists bool) {
+	v := m.addrpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRpmLimit resets all changes to the "rpm_limit" field.
+func (m *GroupMutation) ResetRpmLimit() {
+	m.rpm_limit = nil
+	m.addrpm_limit = nil
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (m *GroupMutation) SetTpmLimit(i int) {
+	m.tpm_limit = &i
+	m.addtpm_limit = nil
+}
+
+// TpmLimit returns the value of the "tpm_limit" field in the mutation.
+func (m *GroupMutation) TpmLimit() (r int, exists bool) {
+	v := m.tpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTpmLimit returns the old "tpm_limit" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldTpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTpmLimit: %w", err)
+	}
+	return oldValue.TpmLimit, nil
+}
+
+// AddTpmLimit adds i to the "tpm_limit" field.
+func (m *GroupMutation) AddTpmLimit(i int) {
+	if m.addtpm_limit != nil {
+		*m.addtpm_limit += i
+	} else {
+		m.addtpm_limit = &i
+	}
+}
+
+// AddedTpmLimit returns the value that was added to the "tpm_limit" field in this mutation.
+func (m *GroupMutation) AddedTpmLimit() (r int, exists bool) {
+	v := m.addtpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTpmLimit resets all changes to the "tpm_limit" field.
+func (m *GroupMutation) ResetTpmLimit() {
+	m.tpm_limit = nil
+	m.addtpm_limit = nil
+}
+
+// SetSchedulingStrategy sets the "scheduling_strategy" field.
+func (m *GroupMutation) SetSchedulingStrategy(s string) {
+	m.scheduling_strategy = &s
+}
+
+// SchedulingStrategy returns the value of the "scheduling_strategy" field in the mutation.
+func (m *GroupMutation) SchedulingStrategy() (r string, exists bool) {
+	v := m.scheduling_strategy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSchedulingStrategy returns the old "scheduling_strategy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSchedulingStrategy(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSchedulingStrategy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSchedulingStrategy requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSchedulingStrategy: %w", err)
+	}
+	return oldValue.SchedulingStrategy, nil
+}
+
+// ResetSchedulingStrategy resets all changes to the "scheduling_strategy" field.
+func (m *GroupMutation) ResetSchedulingStrategy() {
+	m.scheduling_strategy = nil
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (m *GroupMutation) SetQueuePriority(i int) {
+	m.queue_priority = &i
+	m.addqueue_priority = nil
+}
+
+// QueuePriority returns the value of the "queue_priority" field in the mutation.
+func (m *GroupMutation) QueuePriority() (r int, exists bool) {
+	v := m.queue_priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldQueuePriority returns the old "queue_priority" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldQueuePriority(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldQueuePriority is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldQueuePriority requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldQueuePriority: %w", err)
+	}
+	return oldValue.QueuePriority, nil
+}
+
+// AddQueuePriority adds i to the "queue_priority" field.
+func (m *GroupMutation) AddQueuePriority(i int) {
+	if m.addqueue_priority != nil {
+		*m.addqueue_priority += i
+	} else {
+		m.addqueue_priority = &i
+	}
+}
+
+// AddedQueuePriority returns the value that was added to the "queue_priority" field in this mutation.
+func (m *GroupMutation) AddedQueuePriority() (r int, exists bool) {
+	v := m.addqueue_priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetQueuePriority resets all changes to the "queue_priority" field.
+func (m *GroupMutation) ResetQueuePriority() {
+	m.queue_priority = nil
+	m.addqueue_priority = nil
+}
+
+// SetModelFallbackChains sets the "model_fallback_chains" field.
+func (m *GroupMutation) SetModelFallbackChains(dfc []domain.ModelFallbackChain) {
+	m.model_fallback_chains = &dfc
+	m.appendmodel_fallback_chains = nil
+}
+
+// ModelFallbackChains returns the value of the "model_fallback_chains" field in the mutation.
+func (m *GroupMutation) ModelFallbackChains() (r []domain.ModelFallbackChain, exists bool) {
+	v := m.model_fallback_chains
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModelFallbackChains returns the old "model_fallback_chains" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldModelFallbackChains(ctx context.Context) (v []domain.ModelFallbackChain, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModelFallbackChains is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModelFallbackChains requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModelFallbackChains: %w", err)
+	}
+	return oldValue.ModelFallbackChains, nil
+}
+
+// AppendModelFallbackChains adds dfc to the "model_fallback_chains" field.
+func (m *GroupMutation) AppendModelFallbackChains(dfc []domain.ModelFallbackChain) {
+	m.appendmodel_fallback_chains = append(m.appendmodel_fallback_chains, dfc...)
+}
+
+// AppendedModelFallbackChains returns the list of values that were appended to the "model_fallback_chains" field in this mutation.
+func (m *GroupMutation) AppendedModelFallbackChains() ([]domain.ModelFallbackChain, bool) {
+	if len(m.appendmodel_fallback_chains) == 0 {
+		return nil, false
+	}
+	return m.appendmodel_fallback_chains, true
+}
+
+// ClearModelFallbackChains clears the value of the "model_fallback_chains" field.
+func (m *GroupMutation) ClearModelFallbackChains() {
+	m.model_fallback_chains = nil
+	m.appendmodel_fallback_chains = nil
+	m.clearedFields[group.FieldModelFallbackChains] = struct{}{}
+}
+
+// ModelFallbackChainsCleared returns if the "model_fallback_chains" field was cleared in this mutation.
+func (m *GroupMutation) ModelFallbackChainsCleared() bool {
+	_, ok := m.clearedFields[group.FieldModelFallbackChains]
+	return ok
+}
+
+// ResetModelFallbackChains resets all changes to the "model_fallback_chains" field.
+func (m *GroupMutation) ResetModelFallbackChains() {
+	m.model_fallback_chains = nil
+	m.appendmodel_fallback_chains = nil
+	delete(m.clearedFields, group.FieldModelFallbackChains)
+}
+
+// SetCrossPlatformFailover sets the "cross_platform_failover" field.
+func (m *GroupMutation) SetCrossPlatformFailover(b bool) {
+	m.cross_platform_failover = &b
+}
+
+// CrossPlatformFailover returns the value of the "cross_platform_failover" field in the mutation.
+func (m *GroupMutation) CrossPlatformFailover() (r bool, exists bool) {
+	v := m.cross_platform_failover
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCrossPlatformFailover returns the old "cross_platform_failover" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldCrossPlatformFailover(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCrossPlatformFailover is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCrossPlatformFailover requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCrossPlatformFailover: %w", err)
+	}
+	return oldValue.CrossPlatformFailover, nil
+}
+
+// ResetCrossPlatformFailover resets all changes to the "cross_platform_failover" field.
+func (m *GroupMutation) ResetCrossPlatformFailover() {
+	m.cross_platform_failover = nil
+}
+
+// SetShadowMirror sets the "shadow_mirror" field.
+func (m *GroupMutation) SetShadowMirror(dmc *domain.ShadowMirrorConfig) {
+	m.shadow_mirror = &dmc
+}
+
+// ShadowMirror returns the value of the "shadow_mirror" field in the mutation.
+func (m *GroupMutation) ShadowMirror() (r *domain.ShadowMirrorConfig, exists bool) {
+	v := m.shadow_mirror
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldShadowMirror returns the old "shadow_mirror" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldShadowMirror(ctx context.Context) (v *domain.ShadowMirrorConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldShadowMirror is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldShadowMirror requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldShadowMirror: %w", err)
+	}
+	return oldValue.ShadowMirror, nil
+}
+
+// ClearShadowMirror clears the value of the "shadow_mirror" field.
+func (m *GroupMutation) ClearShadowMirror() {
+	m.shadow_mirror = nil
+	m.clearedFields[group.FieldShadowMirror] = struct{}{}
+}
+
+// ShadowMirrorCleared returns if the "shadow_mirror" field was cleared in this mutation.
+func (m *GroupMutation) ShadowMirrorCleared() bool {
+	_, ok := m.clearedFields[group.FieldShadowMirror]
+	return ok
+}
+
+// ResetShadowMirror resets all changes to the "shadow_mirror" field.
+func (m *GroupMutation) ResetShadowMirror() {
+	m.shadow_mirror = nil
+	delete(m.clearedFields, group.FieldShadowMirror)
+}
+
+// SetResponseCache sets the "response_cache" field.
+func (m *GroupMutation) SetResponseCache(dcc *domain.ResponseCacheConfig) {
+	m.response_cache = &dcc
+}
+
+// ResponseCache returns the value of the "response_cache" field in the mutation.
+func (m *GroupMutation) ResponseCache() (r *domain.ResponseCacheConfig, exists bool) {
+	v := m.response_cache
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseCache returns the old "response_cache" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseCache(ctx context.Context) (v *domain.ResponseCacheConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseCache is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseCache requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseCache: %w", err)
+	}
+	return oldValue.ResponseCache, nil
+}
+
+// ClearResponseCache clears the value of the "response_cache" field.
+func (m *GroupMutation) ClearResponseCache() {
+	m.response_cache = nil
+	m.clearedFields[group.FieldResponseCache] = struct{}{}
+}
+
+// ResponseCacheCleared returns if the "response_cache" field was cleared in this mutation.
+func (m *GroupMutation) ResponseCacheCleared() bool {
+	_, ok := m.clearedFields[group.FieldResponseCache]
+	return ok
+}
+
+// ResetResponseCache resets all changes to the "response_cache" field.
+func (m *GroupMutation) ResetResponseCache() {
+	m.response_cache = nil
+	delete(m.clearedFields, group.FieldResponseCache)
+}
+
+// SetAutoCacheBreakpoints sets the "auto_cache_breakpoints" field.
+func (m *GroupMutation) SetAutoCacheBreakpoints(b bool) {
+	m.auto_cache_breakpoints = &b
+}
+
+// AutoCacheBreakpoints returns the value of the "auto_cache_breakpoints" field in the mutation.
+func (m *GroupMutation) AutoCacheBreakpoints() (r bool, exists bool) {
+	v := m.auto_cache_breakpoints
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAutoCacheBreakpoints returns the old "auto_cache_breakpoints" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAutoCacheBreakpoints(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAutoCacheBreakpoints is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAutoCacheBreakpoints requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAutoCacheBreakpoints: %w", err)
+	}
+	return oldValue.AutoCacheBreakpoints, nil
+}
+
+// ResetAutoCacheBreakpoints resets all changes to the "auto_cache_breakpoints" field.
+func (m *GroupMutation) ResetAutoCacheBreakpoints() {
+	m.auto_cache_breakpoints = nil
+}
+
+// SetStreamResume sets the "stream_resume" field.
+func (m *GroupMutation) SetStreamResume(b bool) {
+	m.stream_resume = &b
+}
+
+// StreamResume returns the value of the "stream_resume" field in the mutation.
+func (m *GroupMutation) StreamResume() (r bool, exists bool) {
+	v := m.stream_resume
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStreamResume returns the old "stream_resume" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldStreamResume(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStreamResume is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStreamResume requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStreamResume: %w", err)
+	}
+	return oldValue.StreamResume, nil
+}
+
+// ResetStreamResume resets all changes to the "stream_resume" field.
+func (m *GroupMutation) ResetStreamResume() {
+	m.stream_resume = nil
+}
+
+// SetContextPolicy sets the "context_policy" field.
+func (m *GroupMutation) SetContextPolicy(dpc *domain.ContextPolicyConfig) {
+	m.context_policy = &dpc
+}
+
+// ContextPolicy returns the value of the "context_policy" field in the mutation.
+func (m *GroupMutation) ContextPolicy() (r *domain.ContextPolicyConfig, exists bool) {
+	v := m.context_policy
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContextPolicy returns the old "context_policy" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldContextPolicy(ctx context.Context) (v *domain.ContextPolicyConfig, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContextPolicy is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContextPolicy requires an ID field in the mutation")
 	}
 	oldValue, err := m.oldValue(ctx)
 	if err != nil {
-		return v, fmt.Errorf("querying old value for OldDefaultMappedModel: %w", err)
+		return v, fmt.Errorf("querying old value for OldContextPolicy: %w", err)
 	}
-	return oldValue.DefaultMappedModel, nil
+	return oldValue.ContextPolicy, nil
 }
 
-// ResetDefaultMappedModel resets all changes to the "default_mapped_model" field.
-func (m *GroupMutation) ResetDefaultMappedModel() {
-	m.default_mapped_model = nil
+// ClearContextPolicy clears the value of the "context_policy" field.
+func (m *GroupMutation) ClearContextPolicy() {
+	m.context_policy = nil
+	m.clearedFields[group.FieldContextPolicy] = struct{}{}
+}
+
+// ContextPolicyCleared returns if the "context_policy" field was cleared in this mutation.
+func (m *GroupMutation) ContextPolicyCleared() bool {
+	_, ok := m.clearedFields[group.FieldContextPolicy]
+	return ok
+}
+
+// ResetContextPolicy resets all changes to the "context_policy" field.
+func (m *GroupMutation) ResetContextPolicy() {
+	m.context_policy = nil
+	delete(m.clearedFields, group.FieldContextPolicy)
 }
 
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
@@ -10426,7 +11423,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 32)
+	fields := make([]string, 0, 44)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -10523,6 +11520,42 @@ func (m *GroupMutation) Fields() []string {
 	if m.default_mapped_model != nil {
 		fields = append(fields, group.FieldDefaultMappedModel)
 	}
+	if m.max_ips_per_key_per_hour != nil {
+		fields = append(fields, group.FieldMaxIpsPerKeyPerHour)
+	}
+	if m.rpm_limit != nil {
+		fields = append(fields, group.FieldRpmLimit)
+	}
+	if m.tpm_limit != nil {
+		fields = append(fields, group.FieldTpmLimit)
+	}
+	if m.scheduling_strategy != nil {
+		fields = append(fields, group.FieldSchedulingStrategy)
+	}
+	if m.queue_priority != nil {
+		fields = append(fields, group.FieldQueuePriority)
+	}
+	if m.model_fallback_chains != nil {
+		fields = append(fields, group.FieldModelFallbackChains)
+	}
+	if m.cross_platform_failover != nil {
+		fields = append(fields, group.FieldCrossPlatformFailover)
+	}
+	if m.shadow_mirror != nil {
+		fields = append(fields, group.FieldShadowMirror)
+	}
+	if m.response_cache != nil {
+		fields = append(fields, group.FieldResponseCache)
+	}
+	if m.auto_cache_breakpoints != nil {
+		fields = append(fields, group.FieldAutoCacheBreakpoints)
+	}
+	if m.stream_resume != nil {
+		fields = append(fields, group.FieldStreamResume)
+	}
+	if m.context_policy != nil {
+		fields = append(fields, group.FieldContextPolicy)
+	}
 	return fields
 }
 
@@ -10595,6 +11628,30 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.AllowMessagesDispatch()
 	case group.FieldDefaultMappedModel:
 		return m.DefaultMappedModel()
+	case group.FieldMaxIpsPerKeyPerHour:
+		return m.MaxIpsPerKeyPerHour()
+	case group.FieldRpmLimit:
+		return m.RpmLimit()
+	case group.FieldTpmLimit:
+		return m.TpmLimit()
+	case group.FieldSchedulingStrategy:
+		return m.SchedulingStrategy()
+	case group.FieldQueuePriority:
+		return m.QueuePriority()
+	case group.FieldModelFallbackChains:
+		return m.ModelFallbackChains()
+	case group.FieldCrossPlatformFailover:
+		return m.CrossPlatformFailover()
+	case group.FieldShadowMirror:
+		return m.ShadowMirror()
+	case group.FieldResponseCache:
+		return m.ResponseCache()
+	case group.FieldAutoCacheBreakpoints:
+		return m.AutoCacheBreakpoints()
+	case group.FieldStreamResume:
+		return m.StreamResume()
+	case group.FieldContextPolicy:
+		return m.ContextPolicy()
 	}
 	return nil, false
 }
@@ -10668,6 +11725,30 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldAllowMessagesDispatch(ctx)
 	case group.FieldDefaultMappedModel:
 		return m.OldDefaultMappedModel(ctx)
+	case group.FieldMaxIpsPerKeyPerHour:
+		return m.OldMaxIpsPerKeyPerHour(ctx)
+	case group.FieldRpmLimit:
+		return m.OldRpmLimit(ctx)
+	case group.FieldTpmLimit:
+		return m.OldTpmLimit(ctx)
+	case group.FieldSchedulingStrategy:
+		return m.OldSchedulingStrategy(ctx)
+	case group.FieldQueuePriority:
+		return m.OldQueuePriority(ctx)
+	case group.FieldModelFallbackChains:
+		return m.OldModelFallbackChains(ctx)
+	case group.FieldCrossPlatformFailover:
+		return m.OldCrossPlatformFailover(ctx)
+	case group.FieldShadowMirror:
+		return m.OldShadowMirror(ctx)
+	case group.FieldResponseCache:
+		return m.OldResponseCache(ctx)
+	case group.FieldAutoCacheBreakpoints:
+		return m.OldAutoCacheBreakpoints(ctx)
+	case group.FieldStreamResume:
+		return m.OldStreamResume(ctx)
+	case group.FieldContextPolicy:
+		return m.OldContextPolicy(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -10901,6 +11982,90 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetDefaultMappedModel(v)
 		return nil
+	case group.FieldMaxIpsPerKeyPerHour:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxIpsPerKeyPerHour(v)
+		return nil
+	case group.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRpmLimit(v)
+		return nil
+	case group.FieldTpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTpmLimit(v)
+		return nil
+	case group.FieldSchedulingStrategy:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSchedulingStrategy(v)
+		return nil
+	case group.FieldQueuePriority:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetQueuePriority(v)
+		return nil
+	case group.FieldModelFallbackChains:
+		v, ok := value.([]domain.ModelFallbackChain)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModelFallbackChains(v)
+		return nil
+	case group.FieldCrossPlatformFailover:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCrossPlatformFailover(v)
+		return nil
+	case group.FieldShadowMirror:
+		v, ok := value.(*domain.ShadowMirrorConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetShadowMirror(v)
+		return nil
+	case group.FieldResponseCache:
+		v, ok := value.(*domain.ResponseCacheConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseCache(v)
+		return nil
+	case group.FieldAutoCacheBreakpoints:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAutoCacheBreakpoints(v)
+		return nil
+	case group.FieldStreamResume:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStreamResume(v)
+		return nil
+	case group.FieldContextPolicy:
+		v, ok := value.(*domain.ContextPolicyConfig)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContextPolicy(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -10957,6 +12122,18 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addsort_order != nil {
 		fields = append(fields, group.FieldSortOrder)
 	}
+	if m.addmax_ips_per_key_per_hour != nil {
+		fields = append(fields, group.FieldMaxIpsPerKeyPerHour)
+	}
+	if m.addrpm_limit != nil {
+		fields = append(fields, group.FieldRpmLimit)
+	}
+	if m.addtpm_limit != nil {
+		fields = append(fields, group.FieldTpmLimit)
+	}
+	if m.addqueue_priority != nil {
+		fields = append(fields, group.FieldQueuePriority)
+	}
 	return fields
 }
 
@@ -10997,6 +12174,14 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedFallbackGroupIDOnInvalidRequest()
 	case group.FieldSortOrder:
 		return m.AddedSortOrder()
+	case group.FieldMaxIpsPerKeyPerHour:
+		return m.AddedMaxIpsPerKeyPerHour()
+	case group.FieldRpmLimit:
+		return m.AddedRpmLimit()
+	case group.FieldTpmLimit:
+		return m.AddedTpmLimit()
+	case group.FieldQueuePriority:
+		return m.AddedQueuePriority()
 	}
 	return nil, false
 }
@@ -11118,6 +12303,34 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddSortOrder(v)
 		return nil
+	case group.FieldMaxIpsPerKeyPerHour:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxIpsPerKeyPerHour(v)
+		return nil
+	case group.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRpmLimit(v)
+		return nil
+	case group.FieldTpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTpmLimit(v)
+		return nil
+	case group.FieldQueuePriority:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddQueuePriority(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -11171,6 +12384,18 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldModelRouting) {
 		fields = append(fields, group.FieldModelRouting)
 	}
+	if m.FieldCleared(group.FieldModelFallbackChains) {
+		fields = append(fields, group.FieldModelFallbackChains)
+	}
+	if m.FieldCleared(group.FieldShadowMirror) {
+		fields = append(fields, group.FieldShadowMirror)
+	}
+	if m.FieldCleared(group.FieldResponseCache) {
+		fields = append(fields, group.FieldResponseCache)
+	}
+	if m.FieldCleared(group.FieldContextPolicy) {
+		fields = append(fields, group.FieldContextPolicy)
+	}
 	return fields
 }
 
@@ -11230,6 +12455,18 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldModelRouting:
 		m.ClearModelRouting()
 		return nil
+	case group.FieldModelFallbackChains:
+		m.ClearModelFallbackChains()
+		return nil
+	case group.FieldShadowMirror:
+		m.ClearShadowMirror()
+		return nil
+	case group.FieldResponseCache:
+		m.ClearResponseCache()
+		return nil
+	case group.FieldContextPolicy:
+		m.ClearContextPolicy()
+		return nil
 	}
 	return fmt.Errorf("unknown Group nullable field %s", name)
 }
@@ -11334,6 +12571,42 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldDefaultMappedModel:
 		m.ResetDefaultMappedModel()
 		return nil
+	case group.FieldMaxIpsPerKeyPerHour:
+		m.ResetMaxIpsPerKeyPerHour()
+		return nil
+	case group.FieldRpmLimit:
+		m.ResetRpmLimit()
+		return nil
+	case group.FieldTpmLimit:
+		m.ResetTpmLimit()
+		return nil
+	case group.FieldSchedulingStrategy:
+		m.ResetSchedulingStrategy()
+		return nil
+	case group.FieldQueuePriority:
+		m.ResetQueuePriority()
+		return nil
+	case group.FieldModelFallbackChains:
+		m.ResetModelFallbackChains()
+		return nil
+	case group.FieldCrossPlatformFailover:
+		m.ResetCrossPlatformFailover()
+		return nil
+	case group.FieldShadowMirror:
+		m.ResetShadowMirror()
+		return nil
+	case group.FieldResponseCache:
+		m.ResetResponseCache()
+		return nil
+	case group.FieldAutoCacheBreakpoints:
+		m.ResetAutoCacheBreakpoints()
+		return nil
+	case group.FieldStreamResume:
+		m.ResetStreamResume()
+		return nil
+	case group.FieldContextPolicy:
+		m.ResetContextPolicy()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -17382,8 +18655,8 @@ func (m *UsageCleanupTaskMutation) ResetStatus() {
 }
 
 // SetFilters sets the "filters" field.
-func (m *UsageCleanupTaskMutation) SetFilters(jm json.RawMessage) {
-	m.filters = &jm
+func (m *UsageCleanupTaskMutation) SetFilters(j json.RawMessage) {
+	m.filters = &j
 	m.appendfilters = nil
 }
 
@@ -17413,9 +18686,9 @@ func (m *UsageCleanupTaskMutation) OldFilters(ctx context.Context) (v json.RawMe
 	return oldValue.Filters, nil
 }
 
-// AppendFilters adds jm to the "filters" field.
-func (m *UsageCleanupTaskMutation) AppendFilters(jm json.RawMessage) {
-	m.appendfilters = append(m.appendfilters, jm...)
+// AppendFilters adds j to the "filters" field.
+func (m *UsageCleanupTaskMutation) AppendFilters(j json.RawMessage) {
+	m.appendfilters = append(m.appendfilters, j...)
 }
 
 // AppendedFilters returns the list of values that were appended to the "filters" field in this mutation.
@@ -21269,6 +22542,12 @@ type UserMutation struct {
 	addsora_storage_quota_bytes   *int64
 	sora_storage_used_bytes       *int64
 	addsora_storage_used_bytes    *int64
+	rpm_limit                     *int
+	addrpm_limit                  *int
+	tpm_limit                     *int
+	addtpm_limit                  *int
+	queue_priority                *int
+	addqueue_priority             *int
 	clearedFields                 map[string]struct{}
 	api_keys                      map[int64]struct{}
 	removedapi_keys               map[int64]struct{}
@@ -22095,6 +23374,174 @@ func (m *UserMutation) ResetSoraStorageUsedBytes() {
 	m.addsora_storage_used_bytes = nil
 }
 
+// SetRpmLimit sets the "rpm_limit" field.
+func (m *UserMutation) SetRpmLimit(i int) {
+	m.rpm_limit = &i
+	m.addrpm_limit = nil
+}
+
+// RpmLimit returns the value of the "rpm_limit" field in the mutation.
+func (m *UserMutation) RpmLimit() (r int, exists bool) {
+	v := m.rpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRpmLimit returns the old "rpm_limit" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldRpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRpmLimit: %w", err)
+	}
+	return oldValue.RpmLimit, nil
+}
+
+// AddRpmLimit adds i to the "rpm_limit" field.
+func (m *UserMutation) AddRpmLimit(i int) {
+	if m.addrpm_limit != nil {
+		*m.addrpm_limit += i
+	} else {
+		m.addrpm_limit = &i
+	}
+}
+
+// AddedRpmLimit returns the value that was added to the "rpm_limit" field in this mutation.
+func (m *UserMutation) AddedRpmLimit() (r int, exists bool) {
+	v := m.addrpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetRpmLimit resets all changes to the "rpm_limit" field.
+func (m *UserMutation) ResetRpmLimit() {
+	m.rpm_limit = nil
+	m.addrpm_limit = nil
+}
+
+// SetTpmLimit sets the "tpm_limit" field.
+func (m *UserMutation) SetTpmLimit(i int) {
+	m.tpm_limit = &i
+	m.addtpm_limit = nil
+}
+
+// TpmLimit returns the value of the "tpm_limit" field in the mutation.
+func (m *UserMutation) TpmLimit() (r int, exists bool) {
+	v := m.tpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTpmLimit returns the old "tpm_limit" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldTpmLimit(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTpmLimit is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTpmLimit requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTpmLimit: %w", err)
+	}
+	return oldValue.TpmLimit, nil
+}
+
+// AddTpmLimit adds i to the "tpm_limit" field.
+func (m *UserMutation) AddTpmLimit(i int) {
+	if m.addtpm_limit != nil {
+		*m.addtpm_limit += i
+	} else {
+		m.addtpm_limit = &i
+	}
+}
+
+// AddedTpmLimit returns the value that was added to the "tpm_limit" field in this mutation.
+func (m *UserMutation) AddedTpmLimit() (r int, exists bool) {
+	v := m.addtpm_limit
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTpmLimit resets all changes to the "tpm_limit" field.
+func (m *UserMutation) ResetTpmLimit() {
+	m.tpm_limit = nil
+	m.addtpm_limit = nil
+}
+
+// SetQueuePriority sets the "queue_priority" field.
+func (m *UserMutation) SetQueuePriority(i int) {
+	m.queue_priority = &i
+	m.addqueue_priority = nil
+}
+
+// QueuePriority returns the value of the "queue_priority" field in the mutation.
+func (m *UserMutation) QueuePriority() (r int, exists bool) {
+	v := m.queue_priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldQueuePriority returns the old "queue_priority" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldQueuePriority(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldQueuePriority is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldQueuePriority requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldQueuePriority: %w", err)
+	}
+	return oldValue.QueuePriority, nil
+}
+
+// AddQueuePriority adds i to the "queue_priority" field.
+func (m *UserMutation) AddQueuePriority(i int) {
+	if m.addqueue_priority != nil {
+		*m.addqueue_priority += i
+	} else {
+		m.addqueue_priority = &i
+	}
+}
+
+// AddedQueuePriority returns the value that was added to the "queue_priority" field in this mutation.
+func (m *UserMutation) AddedQueuePriority() (r int, exists bool) {
+	v := m.addqueue_priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetQueuePriority resets all changes to the "queue_priority" field.
+func (m *UserMutation) ResetQueuePriority() {
+	m.queue_priority = nil
+	m.addqueue_priority = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *UserMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -22615,7 +24062,7 @@ func (m *UserMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *UserMutation) Fields() []string {
-	fields := make([]string, 0, 16)
+	fields := make([]string, 0, 19)
 	if m.created_at != nil {
 		fields = append(fields, user.FieldCreatedAt)
 	}
@@ -22664,6 +24111,15 @@ func (m *UserMutation) Fields() []string {
 	if m.sora_storage_used_bytes != nil {
 		fields = append(fields, user.FieldSoraStorageUsedBytes)
 	}
+	if m.rpm_limit != nil {
+		fields = append(fields, user.FieldRpmLimit)
+	}
+	if m.tpm_limit != nil {
+		fields = append(fields, user.FieldTpmLimit)
+	}
+	if m.queue_priority != nil {
+		fields = append(fields, user.FieldQueuePriority)
+	}
 	return fields
 }
 
@@ -22704,6 +24160,12 @@ func (m *UserMutation) Field(name string) (ent.Value, bool) {
 		return m.SoraStorageQuotaBytes()
 	case user.FieldSoraStorageUsedBytes:
 		return m.SoraStorageUsedBytes()
+	case user.FieldRpmLimit:
+		return m.RpmLimit()
+	case user.FieldTpmLimit:
+		return m.TpmLimit()
+	case user.FieldQueuePriority:
+		return m.QueuePriority()
 	}
 	return nil, false
 }
@@ -22745,6 +24207,12 @@ func (m *UserMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldSoraStorageQuotaBytes(ctx)
 	case user.FieldSoraStorageUsedBytes:
 		return m.OldSoraStorageUsedBytes(ctx)
+	case user.FieldRpmLimit:
+		return m.OldRpmLimit(ctx)
+	case user.FieldTpmLimit:
+		return m.OldTpmLimit(ctx)
+	case user.FieldQueuePriority:
+		return m.OldQueuePriority(ctx)
 	}
 	return nil, fmt.Errorf("unknown User field %s", name)
 }
@@ -22866,6 +24334,27 @@ func (m *UserMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetSoraStorageUsedBytes(v)
 		return nil
+	case user.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRpmLimit(v)
+		return nil
+	case user.FieldTpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTpmLimit(v)
+		return nil
+	case user.FieldQueuePriority:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetQueuePriority(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -22886,6 +24375,15 @@ func (m *UserMutation) AddedFields() []string {
 	if m.addsora_storage_used_bytes != nil {
 		fields = append(fields, user.FieldSoraStorageUsedBytes)
 	}
+	if m.addrpm_limit != nil {
+		fields = append(fields, user.FieldRpmLimit)
+	}
+	if m.addtpm_limit != nil {
+		fields = append(fields, user.FieldTpmLimit)
+	}
+	if m.addqueue_priority != nil {
+		fields = append(fields, user.FieldQueuePriority)
+	}
 	return fields
 }
 
@@ -22902,6 +24400,12 @@ func (m *UserMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSoraStorageQuotaBytes()
 	case user.FieldSoraStorageUsedBytes:
 		return m.AddedSoraStorageUsedBytes()
+	case user.FieldRpmLimit:
+		return m.AddedRpmLimit()
+	case user.FieldTpmLimit:
+		return m.AddedTpmLimit()
+	case user.FieldQueuePriority:
+		return m.AddedQueuePriority()
 	}
 	return nil, false
 }
@@ -22939,6 +24443,27 @@ func (m *UserMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddSoraStorageUsedBytes(v)
 		return nil
+	case user.FieldRpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddRpmLimit(v)
+		return nil
+	case user.FieldTpmLimit:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTpmLimit(v)
+		return nil
+	case user.FieldQueuePriority:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddQueuePriority(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User numeric field %s", name)
 }
@@ -23035,6 +24560,15 @@ func (m *UserMutation) ResetField(name string) error {
 	case user.FieldSoraStorageUsedBytes:
 		m.ResetSoraStorageUsedBytes()
 		return nil
+	case user.FieldRpmLimit:
+		m.ResetRpmLimit()
+		return nil
+	case user.FieldTpmLimit:
+		m.ResetTpmLimit()
+		return nil
+	case user.FieldQueuePriority:
+		m.ResetQueuePriority()
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -70,8 +70,14 @@ func init() {
 			return nil
 		}
 	}()
+	// apikeyDescKeyPrefix is the schema descriptor for key_prefix field.
+	apikeyDescKeyPrefix := apikeyFields[2].Descriptor()
+	// apikey.DefaultKeyPrefix holds the default value on creation for the key_prefix field.
+	apikey.DefaultKeyPrefix = apikeyDescKeyPrefix.Default.(string)
+	// apikey.KeyPrefixValidator is a validator for the "key_prefix" field. It is called by the builders before save.
+	apikey.KeyPrefixValidator = apikeyDescKeyPrefix.Validators[0].(func(string) error)
 	// apikeyDescName is the schema descriptor for name field.
-	apikeyDescName := apikeyFields[2].Descriptor()
+	apikeyDescName := apikeyFields[3].Descriptor()
 	// apikey.NameValidator is a validator for the "name" field. It is called by the builders before save.
 	apikey.NameValidator = func() func(string) error {
 		validators := apikeyDescName.Validators
@@ -89,43 +95,53 @@ func init() {
 		}
 	}()
 	// apikeyDescStatus is the schema descriptor for status field.
-	apikeyDescStatus := apikeyFields[4].Descriptor()
+	apikeyDescStatus := apikeyFields[5].Descriptor()
 	// apikey.DefaultStatus holds the default value on creation for the status field.
 	apikey.DefaultStatus = apikeyDescStatus.Default.(string)
 	// apikey.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	apikey.StatusValidator = apikeyDescStatus.Validators[0].(func(string) error)
 	// apikeyDescQuota is the schema descriptor for quota field.
-	apikeyDescQuota := apikeyFields[8].Descriptor()
+	apikeyDescQuota := apikeyFields[9].Descriptor()
 	// apikey.DefaultQuota holds the default value on creation for the quota field.
 	apikey.DefaultQuota = apikeyDescQuota.Default.(float64)
 	// apikeyDescQuotaUsed is the schema descriptor for quota_used field.
-	apikeyDescQuotaUsed := apikeyFields[9].Descriptor()
+	apikeyDescQuotaUsed := apikeyFields[10].Descriptor()
 	// apikey.DefaultQuotaUsed holds the default value on creation for the quota_used field.
 	apikey.DefaultQuotaUsed = apikeyDescQuotaUsed.Default.(float64)
 	// apikeyDescRateLimit5h is the schema descriptor for rate_limit_5h field.
-	apikeyDescRateLimit5h := apikeyFields[11].Descriptor()
+	apikeyDescRateLimit5h := apikeyFields[12].Descriptor()
 	// apikey.DefaultRateLimit5h holds the default value on creation for the rate_limit_5h field.
 	apikey.DefaultRateLimit5h = apikeyDescRateLimit5h.Default.(float64)
 	// apikeyDescRateLimit1d is the schema descriptor for rate_limit_1d field.
-	apikeyDescRateLimit1d := apikeyFields[12].Descriptor()
+	apikeyDescRateLimit1d := apikeyFields[13].Descriptor()
 	// apikey.DefaultRateLimit1d holds the default value on creation for the rate_limit_1d field.
 	apikey.DefaultRateLimit1d = apikeyDescRateLimit1d.Default.(float64)
 	// apikeyDescRateLimit7d is the schema descriptor for rate_limit_7d field.
-	apikeyDescRateLimit7d := apikeyFields[13].Descriptor()
+	apikeyDescRateLimit7d := apikeyFields[14].Descriptor()
 	// apikey.DefaultRateLimit7d holds the default value on creation for the rate_limit_7d field.
 	apikey.DefaultRateLimit7d = apikeyDescRateLimit7d.Default.(float64)
 	// apikeyDescUsage5h is the schema descriptor for usage_5h field.
-	apikeyDescUsage5h := apikeyFields[14].Descriptor()
+	apikeyDescUsage5h := apikeyFields[15].Descriptor()
 	// apikey.DefaultUsage5h holds the default value on creation for the usage_5h field.
 	apikey.DefaultUsage5h = apikeyDescUsage5h.Default.(float64)
 	// apikeyDescUsage1d is the schema descriptor for usage_1d field.
-	apikeyDescUsage1d := apikeyFields[15].Descriptor()
+	apikeyDescUsage1d := apikeyFields[16].Descriptor()
 	// apikey.DefaultUsage1d holds the default value on creation for the usage_1d field.
 	apikey.DefaultUsage1d = apikeyDescUsage1d.Default.(float64)
 	// apikeyDescUsage7d is the schema descriptor for usage_7d field.
-	apikeyDescUsage7d := apikeyFields[16].Descriptor()
+	apikeyDescUsage7d := apikeyFields[17].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
+	// apikeyDescPreviousKey is the schema descriptor for previous_key field.
+	apikeyDescPreviousKey := apikeyFields[21].Descriptor()
+	// apikey.PreviousKeyValidator is a validator for the "previous_key" field. It is called by the builders before save.
+	apikey.PreviousKeyValidator = apikeyDescPreviousKey.Validators[0].(func(string) error)
+	// apikeyDescPreviousKeyPrefix is the schema descriptor for previous_key_prefix field.
+	apikeyDescPreviousKeyPrefix := apikeyFields[22].Descriptor()
+	// apikey.DefaultPreviousKeyPrefix holds the default value on creation for the previous_key_prefix field.
+	apikey.DefaultPreviousKeyPrefix = apikeyDescPreviousKeyPrefix.Default.(string)
+	// apikey.PreviousKeyPrefixValidator is a validator for the "previous_key_prefix" field. It is called by the builders before save.
+	apikey.PreviousKeyPrefixValidator = apikeyDescPreviousKeyPrefix.Validators[0].(func(string) error)
 	accountMixin := schema.Account{}.Mixin()
 	accountMixinHooks1 := accountMixin[1].Hooks()
 	account.Hooks[0] = accountMixinHooks1[0]
@@ -463,6 +479,40 @@ func init() {
 	group.DefaultDefaultMappedModel = groupDescDefaultMappedModel.Default.(string)
 	// group.DefaultMappedModelValidator is a validator for the "default_mapped_model" field. It is called by the builders before save.
 	group.DefaultMappedModelValidator = groupDescDefaultMappedModel.Validators[0].(func(string) error)
+	// groupDescMaxIpsPerKeyPerHour is the schema descriptor for max_ips_per_key_per_hour field.
+	groupDescMaxIpsPerKeyPerHour := groupFields[29].Descriptor()
+	// group.DefaultMaxIpsPerKeyPerHour holds the default value on creation for the max_ips_per_key_per_hour field.
+	group.DefaultMaxIpsPerKeyPerHour = groupDescMaxIpsPerKeyPerHour.Default.(int)
+	// groupDescRpmLimit is the schema descriptor for rpm_limit field.
+	groupDescRpmLimit := groupFields[30].Descriptor()
+	// group.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
+	group.DefaultRpmLimit = groupDescRpmLimit.Default.(int)
+	// groupDescTpmLimit is the schema descriptor for tpm_limit field.
+	groupDescTpmLimit := groupFields[31].Descriptor()
+	// group.DefaultTpmLimit holds the default value on creation for the tpm_limit field.
+	group.DefaultTpmLimit = groupDescTpmLimit.Default.(int)
+	// groupDescSchedulingStrategy is the schema descriptor for scheduling_strategy field.
+	groupDescSchedulingStrategy := groupFields[32].Descriptor()
+	// group.DefaultSchedulingStrategy holds the default value on creation for the scheduling_strategy field.
+	group.DefaultSchedulingStrategy = groupDescSchedulingStrategy.Default.(string)
+	// group.SchedulingStrategyValidator is a validator for the "scheduling_strategy" field. It is called by the builders before save.
+	group.SchedulingStrategyValidator = groupDescSchedulingStrategy.Validators[0].(func(string) error)
+	// groupDescQueuePriority is the schema descriptor for queue_priority field.
+	groupDescQueuePriority := groupFields[33].Descriptor()
+	// group.DefaultQueuePriority holds the default value on creation for the queue_priority field.
+	group.DefaultQueuePriority = groupDescQueuePriority.Default.(int)
+	// groupDescCrossPlatformFailover is the schema descriptor for cross_platform_failover field.
+	groupDescCrossPlatformFailover := groupFields[35].Descriptor()
+	// group.DefaultCrossPlatformFailover holds the default value on creation for the cross_platform_failover field.
+	group.DefaultCrossPlatformFailover = groupDescCrossPlatformFailover.Default.(bool)
+	// groupDescAutoCacheBreakpoints is the schema descriptor for auto_cache_breakpoints field.
+	groupDescAutoCacheBreakpoints := groupFields[38].Descriptor()
+	// group.DefaultAutoCacheBreakpoints holds the default value on creation for the auto_cache_breakpoints field.
+	group.DefaultAutoCacheBreakpoints = groupDescAutoCacheBreakpoints.Default.(bool)
+	// groupDescStreamResume is the schema descriptor for stream_resume field.
+	groupDescStreamResume := groupFields[39].Descriptor()
+	// group.DefaultStreamResume holds the default value on creation for the stream_resume field.
+	group.DefaultStreamResume = groupDescStreamResume.Default.(bool)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -1006,6 +1056,18 @@ func init() {
 	userDescSoraStorageUsedBytes := userFields[12].Descriptor()
 	// user.DefaultSoraStorageUsedBytes holds the default value on creation for the sora_storage_used_bytes field.
 	user.DefaultSoraStorageUsedBytes = userDescSoraStorageUsedBytes.Default.(int64)
+	// userDescRpmLimit is the schema descriptor for rpm_limit field.
+	userDescRpmLimit := userFields[13].Descriptor()
+	// user.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
+	user.DefaultRpmLimit = userDescRpmLimit.Default.(int)
+	// userDescTpmLimit is the schema descriptor for tpm_limit field.
+	userDescTpmLimit := userFields[14].Descriptor()
+	// user.DefaultTpmLimit holds the default value on creation for the tpm_limit field.
+	user.DefaultTpmLimit = userDescTpmLimit.Default.(int)
+	// userDescQueuePriority is the schema descriptor for queue_priority field.
+	userDescQueuePriority := userFields[15].Descriptor()
+	// user.DefaultQueuePriority holds the default value on creation for the queue_priority field.
+	user.DefaultQueuePriority = userDescQueuePriority.Default.(int)
 	userallowedgroupFields := schema.UserAllowedGroup{}.Fields()
 	_ = userallowedgroupFields
 	// userallowedgroupDescCreatedAt is the schema descriptor for created_at field.
@@ -34,10 +34,15 @@ func (APIKey) Mixin() []ent.Mixin {
 func (APIKey) Fields() []ent.Field {
 	return []ent.Field{
 		field.Int64("user_id"),
+		// key 存储 API Key 的带密钥摘要（HMAC-SHA256），明文仅在创建时返回一次
 		field.String("key").
 			MaxLen(128).
 			NotEmpty().
 			Unique(),
+		field.String("key_prefix").
+			MaxLen(32).
+			Default("").
+			Comment("Display prefix of the plaintext key"),
 		field.String("name").
 			MaxLen(100).
 			NotEmpty(),
@@ -115,6 +120,33 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		// ========== Rotation fields ==========
+		// 轮换后旧 Key 在宽限期内仍可认证，到期后由后台任务清除
+		field.String("previous_key").
+			MaxLen(128).
+			Optional().
+			Nillable().
+			Comment("Digest of the previous key, valid until previous_key_expires_at"),
+		field.String("previous_key_prefix").
+			MaxLen(32).
+			Default("").
+			Comment("Display prefix of the previous key"),
+		field.Time("previous_key_expires_at").
+			Optional().
+			Nillable().
+			Comment("Grace period end of the previous key"),
+		field.Time("rotated_at").
+			Optional().
+			Nillable().
+			Comment("Last rotation time"),
+
+		// ========== Policy fields ==========
+		// 模型/端点/平台白名单与单次请求、每分钟用量上限
+		field.JSON("policy", domain.APIKeyPolicy{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("Access policy: model/endpoint/platform allow lists and request caps"),
 	}
 }
 
@@ -144,5 +176,7 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		index.Fields("previous_key"),
+		index.Fields("previous_key_expires_at"),
 	}
 }
@@ -157,6 +157,69 @@ func (Group) Fields() []ent.Field {
 			MaxLen(100).
 			Default("").
 			Comment("默认映射模型 ID，当账号级映射找不到时使用此值"),
+
+		// Key 共享防护 (added by migration 073)
+		field.Int("max_ips_per_key_per_hour").
+			Default(0).
+			Comment("每个 API Key 每小时允许的不同来源 IP 数，0 表示不限制"),
+
+		// 请求速率限制 (added by migration 081)
+		field.Int("rpm_limit").
+			Default(0).
+			Comment("分组内所有请求合计每分钟请求数上限（滑动窗口），0 表示不限制"),
+		field.Int("tpm_limit").
+			Default(0).
+			Comment("分组内所有请求合计每分钟 token 数上限（滑动窗口），0 表示不限制"),
+
+		// 账号调度策略 (added by migration 083)
+		field.String("scheduling_strategy").
+			MaxLen(32).
+			Default("").
+			Comment("账号调度策略：priority_lru/weighted_round_robin/least_loaded/lowest_latency/cheapest/power_of_two，空表示平台默认"),
+
+		// 排队优先级 (added by migration 084)
+		field.Int("queue_priority").
+			Default(0).
+			Comment("账号满载排队时的优先级（0-9，越大越优先）"),
+
+		// 模型降级链 (added by migration 085)
+		field.JSON("model_fallback_chains", []domain.ModelFallbackChain{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("模型降级链：源模型匹配且命中触发条件时依次尝试降级模型"),
+
+		// 跨平台故障转移 (added by migration 086)
+		field.Bool("cross_platform_failover").
+			Default(false).
+			Comment("本平台账号耗尽时是否转移到分组内 anthropic/antigravity 对端平台账号"),
+
+		// 影子流量镜像 (added by migration 087)
+		field.JSON("shadow_mirror", &domain.ShadowMirrorConfig{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("影子流量镜像：按采样比例异步复制请求到影子账号/分组，仅记录对比指标，不计费"),
+
+		// 网关响应缓存 (added by migration 089)
+		field.JSON("response_cache", &domain.ResponseCacheConfig{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("网关响应缓存：确定性请求按规范化请求体精确匹配回放，命中按比例计费"),
+
+		// 自动 prompt 缓存断点 (added by migration 090)
+		field.Bool("auto_cache_breakpoints").
+			Default(false).
+			Comment("是否为未设置 cache_control 的 Anthropic 请求自动在稳定前缀上注入缓存断点"),
+
+		// 流式断线续传 (added by migration 091)
+		field.Bool("stream_resume").
+			Default(false).
+			Comment("是否在服务端短暂缓冲流式响应，允许客户端断线后凭 Last-Event-ID 续传"),
+
+		// 上下文策略 (added by migration 092)
+		field.JSON("context_policy", &domain.ContextPolicyConfig{}).
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("上下文策略：转发前估算输入 token，超限时拒绝、截断最早轮次或摘要"),
 	}
 }
 
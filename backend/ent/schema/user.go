@@ -78,6 +78,19 @@ func (User) Fields() []ent.Field {
 			Default(0),
 		field.Int64("sora_storage_used_bytes").
 			Default(0),
+
+		// 请求速率限制 (added by migration 081)
+		field.Int("rpm_limit").
+			Default(0).
+			Comment("用户所有 API Key 合计每分钟请求数上限（滑动窗口），0 表示不限制"),
+		field.Int("tpm_limit").
+			Default(0).
+			Comment("用户所有 API Key 合计每分钟 token 数上限（滑动窗口），0 表示不限制"),
+
+		// 排队优先级 (added by migration 084)
+		field.Int("queue_priority").
+			Default(0).
+			Comment("账号满载排队时的优先级（0-9，越大越优先）"),
 	}
 }
 
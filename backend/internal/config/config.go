@@ -64,6 +64,7 @@ type Config struct {
 	Ops                     OpsConfig                     `mapstructure:"ops"`
 	JWT                     JWTConfig                     `mapstructure:"jwt"`
 	Totp                    TotpConfig                    `mapstructure:"totp"`
+	WebAuthn                WebAuthnConfig                `mapstructure:"webauthn"`
 	LinuxDo                 LinuxDoConnectConfig          `mapstructure:"linuxdo_connect"`
 	Default                 DefaultConfig                 `mapstructure:"default"`
 	RateLimit               RateLimitConfig               `mapstructure:"rate_limit"`
@@ -75,6 +76,11 @@ type Config struct {
 	Dashboard               DashboardCacheConfig          `mapstructure:"dashboard_cache"`
 	DashboardAgg            DashboardAggregationConfig    `mapstructure:"dashboard_aggregation"`
 	UsageCleanup            UsageCleanupConfig            `mapstructure:"usage_cleanup"`
+	UsageArchive            UsageArchiveConfig            `mapstructure:"usage_archive"`
+	Partitioning            PartitioningConfig            `mapstructure:"partitioning"`
+	CostAnomaly             CostAnomalyConfig             `mapstructure:"cost_anomaly"`
+	KeySharing              KeySharingConfig              `mapstructure:"key_sharing"`
+	APIKeyRotation          APIKeyRotationConfig          `mapstructure:"api_key_rotation"`
 	Concurrency             ConcurrencyConfig             `mapstructure:"concurrency"`
 	TokenRefresh            TokenRefreshConfig            `mapstructure:"token_refresh"`
 	Sora                    SoraConfig                    `mapstructure:"sora"`
@@ -95,6 +101,7 @@ type LogConfig struct {
 	Output          LogOutputConfig   `mapstructure:"output"`
 	Rotation        LogRotationConfig `mapstructure:"rotation"`
 	Sampling        LogSamplingConfig `mapstructure:"sampling"`
+	Sinks           LogSinksConfig    `mapstructure:"sinks"`
 }
 
 type LogOutputConfig struct {
@@ -117,6 +124,131 @@ type LogSamplingConfig struct {
 	Thereafter int  `mapstructure:"thereafter"`
 }
 
+// LogSinksConfig 外部日志/事件投递（syslog、Loki、HTTP NDJSON）
+type LogSinksConfig struct {
+	// QueueSize 每个 sink 的内存队列容量，队列满时丢弃并计数
+	QueueSize int `mapstructure:"queue_size"`
+	// BatchSize 单次投递的最大事件数
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushIntervalMs 未攒满批次时的最长等待时间（毫秒）
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	// TimeoutSeconds 单次投递超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+
+	Syslog LogSyslogSinkConfig `mapstructure:"syslog"`
+	Loki   LogLokiSinkConfig   `mapstructure:"loki"`
+	HTTP   LogHTTPSinkConfig   `mapstructure:"http"`
+}
+
+// LogSinkFilterConfig sink 通用过滤条件
+type LogSinkFilterConfig struct {
+	// MinLevel 普通日志的最低级别：debug/info/warn/error
+	MinLevel string `mapstructure:"min_level"`
+	// AccessEvents 是否投递请求访问事件（不含请求/响应体）
+	AccessEvents bool `mapstructure:"access_events"`
+}
+
+// LogSyslogSinkConfig RFC5424 syslog 投递
+type LogSyslogSinkConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Network udp/tcp/tls；tcp/tls 使用 RFC6587 octet-counting 分帧
+	Network string `mapstructure:"network"`
+	Address string `mapstructure:"address"`
+	// Facility syslog facility 名称，如 local0、user、daemon
+	Facility string `mapstructure:"facility"`
+	// AppName RFC5424 APP-NAME，留空时使用 log.service_name
+	AppName string `mapstructure:"app_name"`
+	// Hostname RFC5424 HOSTNAME，留空时使用系统主机名
+	Hostname string `mapstructure:"hostname"`
+	// InsecureSkipVerify 仅 tls 有效，跳过证书校验（仅用于测试环境）
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+
+	LogSinkFilterConfig `mapstructure:",squash"`
+}
+
+// LogLokiSinkConfig Grafana Loki push API 投递
+type LogLokiSinkConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// URL Loki 地址，如 http://loki:3100（自动补全 /loki/api/v1/push）
+	URL      string `mapstructure:"url"`
+	TenantID string `mapstructure:"tenant_id"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Labels 附加的静态标签；service/env/level/component 标签始终存在
+	Labels map[string]string `mapstructure:"labels"`
+
+	LogSinkFilterConfig `mapstructure:",squash"`
+}
+
+// LogHTTPSinkConfig 通用 HTTP 批量 NDJSON 投递
+type LogHTTPSinkConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	URL         string            `mapstructure:"url"`
+	BearerToken string            `mapstructure:"bearer_token"`
+	Headers     map[string]string `mapstructure:"headers"`
+	// Gzip 是否以 gzip 压缩请求体
+	Gzip bool `mapstructure:"gzip"`
+
+	LogSinkFilterConfig `mapstructure:",squash"`
+}
+
+func (c LogSinksConfig) validate() error {
+	if !c.Syslog.Enabled && !c.Loki.Enabled && !c.HTTP.Enabled {
+		return nil
+	}
+	if c.QueueSize <= 0 {
+		return fmt.Errorf("log.sinks.queue_size must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("log.sinks.batch_size must be positive")
+	}
+	if c.FlushIntervalMs <= 0 {
+		return fmt.Errorf("log.sinks.flush_interval_ms must be positive")
+	}
+	if c.TimeoutSeconds <= 0 {
+		return fmt.Errorf("log.sinks.timeout_seconds must be positive")
+	}
+	if c.Syslog.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.Syslog.Network)) {
+		case "udp", "tcp", "tls":
+		default:
+			return fmt.Errorf("log.sinks.syslog.network must be one of: udp/tcp/tls")
+		}
+		if strings.TrimSpace(c.Syslog.Address) == "" {
+			return fmt.Errorf("log.sinks.syslog.address is required when syslog sink is enabled")
+		}
+		if err := validateLogSinkLevel("log.sinks.syslog.min_level", c.Syslog.MinLevel); err != nil {
+			return err
+		}
+	}
+	if c.Loki.Enabled {
+		if err := ValidateAbsoluteHTTPURL(c.Loki.URL); err != nil {
+			return fmt.Errorf("log.sinks.loki.url invalid: %w", err)
+		}
+		if err := validateLogSinkLevel("log.sinks.loki.min_level", c.Loki.MinLevel); err != nil {
+			return err
+		}
+	}
+	if c.HTTP.Enabled {
+		if err := ValidateAbsoluteHTTPURL(c.HTTP.URL); err != nil {
+			return fmt.Errorf("log.sinks.http.url invalid: %w", err)
+		}
+		if err := validateLogSinkLevel("log.sinks.http.min_level", c.HTTP.MinLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLogSinkLevel(key, level string) error {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "debug", "info", "warn", "error":
+		return nil
+	default:
+		return fmt.Errorf("%s must be one of: debug/info/warn/error", key)
+	}
+}
+
 type GeminiConfig struct {
 	OAuth GeminiOAuthConfig `mapstructure:"oauth"`
 	Quota GeminiQuotaConfig `mapstructure:"quota"`
@@ -249,6 +381,8 @@ type SecurityConfig struct {
 	CSP             CSPConfig            `mapstructure:"csp"`
 	ProxyFallback   ProxyFallbackConfig  `mapstructure:"proxy_fallback"`
 	ProxyProbe      ProxyProbeConfig     `mapstructure:"proxy_probe"`
+	// APIKeyHashSecret 用于计算 API Key 的 HMAC 摘要；留空时自动生成并持久化到数据库
+	APIKeyHashSecret string `mapstructure:"api_key_hash_secret"`
 }
 
 type URLAllowlistConfig struct {
@@ -467,6 +601,20 @@ type GatewayConfig struct {
 	// UserMessageQueue: 用户消息串行队列配置
 	// 对 role:"user" 的真实用户消息实施账号级串行化 + RPM 自适应延迟
 	UserMessageQueue UserMessageQueueConfig `mapstructure:"user_message_queue"`
+
+	// StreamResume: 流式断线续传缓冲配置（是否启用由分组控制）
+	StreamResume GatewayStreamResumeConfig `mapstructure:"stream_resume"`
+}
+
+// GatewayStreamResumeConfig 流式断线续传缓冲配置
+// 每个实例在内存中缓冲开启续传分组的 SSE 事件，客户端断线后可在窗口期内凭 Last-Event-ID 续传
+type GatewayStreamResumeConfig struct {
+	// BufferTTLSeconds: 流结束后缓冲保留时间（秒）
+	BufferTTLSeconds int `mapstructure:"buffer_ttl_seconds"`
+	// MaxStreamBytes: 单个流的缓冲上限（字节），超出后停止缓冲该流
+	MaxStreamBytes int `mapstructure:"max_stream_bytes"`
+	// MaxTotalBytes: 单实例缓冲总上限（字节），超出时优先淘汰最早结束的流
+	MaxTotalBytes int64 `mapstructure:"max_total_bytes"`
 }
 
 // UserMessageQueueConfig 用户消息串行队列配置
@@ -858,6 +1006,16 @@ type TotpConfig struct {
 	EncryptionKeyConfigured bool `mapstructure:"-"`
 }
 
+// WebAuthnConfig WebAuthn / Passkey 依赖方（Relying Party）配置
+type WebAuthnConfig struct {
+	// RPID 依赖方 ID（通常为站点域名，不含协议与端口）；为空时从 server.frontend_url 推导
+	RPID string `mapstructure:"rp_id"`
+	// RPDisplayName 认证器中展示的站点名称
+	RPDisplayName string `mapstructure:"rp_display_name"`
+	// RPOrigins 允许发起 WebAuthn 仪式的来源（如 https://example.com）；为空时使用 server.frontend_url
+	RPOrigins []string `mapstructure:"rp_origins"`
+}
+
 type TurnstileConfig struct {
 	Required bool `mapstructure:"required"`
 }
@@ -953,6 +1111,106 @@ type UsageCleanupConfig struct {
 	TaskTimeoutSeconds int `mapstructure:"task_timeout_seconds"`
 }
 
+// UsageArchiveConfig 使用记录归档配置（删除前导出到对象存储）
+type UsageArchiveConfig struct {
+	// Enabled: 是否在删除 usage_logs 前先归档
+	Enabled bool `mapstructure:"enabled"`
+	// S3ProfileID: 使用的 S3 配置 ID（系统设置中的 S3 多配置），留空表示当前激活配置
+	S3ProfileID string `mapstructure:"s3_profile_id"`
+	// Prefix: 归档对象 key 前缀
+	Prefix string `mapstructure:"prefix"`
+	// Granularity: 归档粒度（day/month）
+	Granularity string `mapstructure:"granularity"`
+	// ArchiveTimeoutSeconds: 单次归档批次的最大执行时长（秒）
+	ArchiveTimeoutSeconds int `mapstructure:"archive_timeout_seconds"`
+	// RehydrateBatchSize: 回灌时单批写入行数
+	RehydrateBatchSize int `mapstructure:"rehydrate_batch_size"`
+}
+
+// PartitioningConfig 按月分区表（usage_logs、ops_error_logs、ops_system_metrics）维护配置
+type PartitioningConfig struct {
+	// Enabled: 是否启用分区维护（预建未来分区）；仅对已分区的表生效。过期去重记录始终按维护间隔清理
+	Enabled bool `mapstructure:"enabled"`
+	// PremakeMonths: 提前创建的未来月份分区数量
+	PremakeMonths int `mapstructure:"premake_months"`
+	// MaintenanceIntervalMinutes: 分区维护间隔（分钟）
+	MaintenanceIntervalMinutes int `mapstructure:"maintenance_interval_minutes"`
+	// ConvertBatchSize: 在线转换分区表时单批复制行数
+	ConvertBatchSize int `mapstructure:"convert_batch_size"`
+	// DedupRetentionDays: usage_logs request_id 去重记录保留天数
+	DedupRetentionDays int `mapstructure:"dedup_retention_days"`
+}
+
+// CostAnomalyConfig 费用异常检测配置
+type CostAnomalyConfig struct {
+	// Enabled: 是否启用费用异常检测
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalMinutes: 检测间隔（分钟）
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// BaselineDays: 学习基线使用的历史天数（按小时聚合）
+	BaselineDays int `mapstructure:"baseline_days"`
+	// MinBaselineHours: 基线至少覆盖的小时数，不足时视为无基线
+	MinBaselineHours int `mapstructure:"min_baseline_hours"`
+	// ZScoreThreshold: 最近一小时超过基线均值多少个标准差视为异常（<=0 关闭该判据）
+	ZScoreThreshold float64 `mapstructure:"z_score_threshold"`
+	// Multiplier: 最近一小时超过基线均值多少倍视为异常（<=0 关闭该判据）
+	Multiplier float64 `mapstructure:"multiplier"`
+	// MinHourlyCost: 最近一小时费用低于该值时不判定异常（USD），避免小额波动误报
+	MinHourlyCost float64 `mapstructure:"min_hourly_cost"`
+	// MinHourlyRequests: 最近一小时请求数低于该值时不按请求数判定异常
+	MinHourlyRequests int64 `mapstructure:"min_hourly_requests"`
+	// NoBaselineHourlyCost: 无基线（新 Key/新用户）时的最近一小时费用上限（USD），0 表示不检测
+	NoBaselineHourlyCost float64 `mapstructure:"no_baseline_hourly_cost"`
+	// AutoSuspend: 是否自动停用异常的 API Key（状态置为 suspended，需管理员解除）
+	AutoSuspend bool `mapstructure:"auto_suspend"`
+	// NotifyUser: 是否邮件通知 Key 所属用户
+	NotifyUser bool `mapstructure:"notify_user"`
+	// CooldownMinutes: 同一对象两次告警的最小间隔（分钟）
+	CooldownMinutes int `mapstructure:"cooldown_minutes"`
+}
+
+// KeySharingConfig API Key 共享/转售检测配置
+type KeySharingConfig struct {
+	// Enabled: 是否启用后台检测（分组级 IP 上限不受此开关影响）
+	Enabled bool `mapstructure:"enabled"`
+	// IntervalMinutes: 检测间隔（分钟）
+	IntervalMinutes int `mapstructure:"interval_minutes"`
+	// Windows: 滑动窗口及各窗口的信号阈值，任一窗口得分达到阈值即进入审核队列
+	Windows []KeySharingWindowConfig `mapstructure:"windows"`
+	// MinRequests: 窗口内请求数低于该值的 Key 不参与评分
+	MinRequests int64 `mapstructure:"min_requests"`
+	// ScoreThreshold: 可疑分数阈值（0-100）
+	ScoreThreshold float64 `mapstructure:"score_threshold"`
+	// DismissCooldownHours: 审核忽略后多少小时内不再重复进入队列
+	DismissCooldownHours int `mapstructure:"dismiss_cooldown_hours"`
+	// EvidenceTopN: 证据中保留的 Top IP / User-Agent 数量
+	EvidenceTopN int `mapstructure:"evidence_top_n"`
+}
+
+// KeySharingWindowConfig 单个滑动窗口的阈值；各信号达到阈值时该项得满分，0 表示不使用该信号
+type KeySharingWindowConfig struct {
+	// Minutes: 窗口长度（分钟）
+	Minutes int `mapstructure:"minutes"`
+	// DistinctIPs: 不同来源 IP 数
+	DistinctIPs int `mapstructure:"distinct_ips"`
+	// DistinctNetworks: 不同网段数（IPv4 /16、IPv6 /32，作为 ASN/地域分布的近似）
+	DistinctNetworks int `mapstructure:"distinct_networks"`
+	// DistinctUserAgents: 不同客户端指纹（User-Agent）数
+	DistinctUserAgents int `mapstructure:"distinct_user_agents"`
+	// ConcurrentIPs: 同一分钟内同时活跃的不同 IP 数
+	ConcurrentIPs int `mapstructure:"concurrent_ips"`
+}
+
+// APIKeyRotationConfig API Key 轮换配置
+type APIKeyRotationConfig struct {
+	// DefaultGraceHours: 未指定时旧 Key 的宽限期（小时），0 表示轮换后旧 Key 立即失效
+	DefaultGraceHours int `mapstructure:"default_grace_hours"`
+	// MaxGraceHours: 请求可指定的最大宽限期（小时）
+	MaxGraceHours int `mapstructure:"max_grace_hours"`
+	// CleanupIntervalMinutes: 清除过期旧 Key 的间隔（分钟）
+	CleanupIntervalMinutes int `mapstructure:"cleanup_interval_minutes"`
+}
+
 func NormalizeRunMode(value string) string {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch normalized {
@@ -1071,6 +1329,18 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 		cfg.Totp.EncryptionKeyConfigured = true
 	}
 
+	// WebAuthn 依赖方未显式配置时从前端地址推导
+	cfg.WebAuthn.RPID = strings.TrimSpace(cfg.WebAuthn.RPID)
+	cfg.WebAuthn.RPOrigins = normalizeStringSlice(cfg.WebAuthn.RPOrigins)
+	if u, err := url.Parse(cfg.Server.FrontendURL); err == nil && u.Host != "" {
+		if cfg.WebAuthn.RPID == "" {
+			cfg.WebAuthn.RPID = u.Hostname()
+		}
+		if len(cfg.WebAuthn.RPOrigins) == 0 {
+			cfg.WebAuthn.RPOrigins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+
 	originalJWTSecret := cfg.JWT.Secret
 	if allowMissingJWTSecret && originalJWTSecret == "" {
 		// 启动阶段允许先无 JWT 密钥，后续在数据库初始化后补齐。
@@ -1143,6 +1413,32 @@ func setDefaults() {
 	viper.SetDefault("log.sampling.enabled", false)
 	viper.SetDefault("log.sampling.initial", 100)
 	viper.SetDefault("log.sampling.thereafter", 100)
+	viper.SetDefault("log.sinks.queue_size", 10000)
+	viper.SetDefault("log.sinks.batch_size", 200)
+	viper.SetDefault("log.sinks.flush_interval_ms", 1000)
+	viper.SetDefault("log.sinks.timeout_seconds", 10)
+	viper.SetDefault("log.sinks.syslog.enabled", false)
+	viper.SetDefault("log.sinks.syslog.network", "udp")
+	viper.SetDefault("log.sinks.syslog.address", "")
+	viper.SetDefault("log.sinks.syslog.facility", "local0")
+	viper.SetDefault("log.sinks.syslog.app_name", "")
+	viper.SetDefault("log.sinks.syslog.hostname", "")
+	viper.SetDefault("log.sinks.syslog.insecure_skip_verify", false)
+	viper.SetDefault("log.sinks.syslog.min_level", "info")
+	viper.SetDefault("log.sinks.syslog.access_events", false)
+	viper.SetDefault("log.sinks.loki.enabled", false)
+	viper.SetDefault("log.sinks.loki.url", "")
+	viper.SetDefault("log.sinks.loki.tenant_id", "")
+	viper.SetDefault("log.sinks.loki.username", "")
+	viper.SetDefault("log.sinks.loki.password", "")
+	viper.SetDefault("log.sinks.loki.min_level", "info")
+	viper.SetDefault("log.sinks.loki.access_events", false)
+	viper.SetDefault("log.sinks.http.enabled", false)
+	viper.SetDefault("log.sinks.http.url", "")
+	viper.SetDefault("log.sinks.http.bearer_token", "")
+	viper.SetDefault("log.sinks.http.gzip", false)
+	viper.SetDefault("log.sinks.http.min_level", "info")
+	viper.SetDefault("log.sinks.http.access_events", false)
 
 	// CORS
 	viper.SetDefault("cors.allowed_origins", []string{})
@@ -1249,6 +1545,11 @@ func setDefaults() {
 	// TOTP
 	viper.SetDefault("totp.encryption_key", "")
 
+	// WebAuthn / Passkey
+	viper.SetDefault("webauthn.rp_id", "")
+	viper.SetDefault("webauthn.rp_display_name", "Sub2API")
+	viper.SetDefault("webauthn.rp_origins", []string{})
+
 	// Default
 	// Admin credentials are created via the setup flow (web wizard / CLI / AUTO_SETUP).
 	// Do not ship fixed defaults here to avoid insecure "known credentials" in production.
@@ -1312,6 +1613,52 @@ func setDefaults() {
 	viper.SetDefault("usage_cleanup.worker_interval_seconds", 10)
 	viper.SetDefault("usage_cleanup.task_timeout_seconds", 1800)
 
+	// Usage archive
+	viper.SetDefault("usage_archive.enabled", false)
+	viper.SetDefault("usage_archive.s3_profile_id", "")
+	viper.SetDefault("usage_archive.prefix", "usage-archive")
+	viper.SetDefault("usage_archive.granularity", "month")
+	viper.SetDefault("usage_archive.archive_timeout_seconds", 3600)
+	viper.SetDefault("usage_archive.rehydrate_batch_size", 1000)
+
+	// Partitioning
+	viper.SetDefault("partitioning.enabled", true)
+	viper.SetDefault("partitioning.premake_months", 3)
+	viper.SetDefault("partitioning.maintenance_interval_minutes", 60)
+	viper.SetDefault("partitioning.convert_batch_size", 10000)
+	viper.SetDefault("partitioning.dedup_retention_days", 7)
+
+	// Cost anomaly detection
+	viper.SetDefault("cost_anomaly.enabled", false)
+	viper.SetDefault("cost_anomaly.interval_minutes", 10)
+	viper.SetDefault("cost_anomaly.baseline_days", 14)
+	viper.SetDefault("cost_anomaly.min_baseline_hours", 24)
+	viper.SetDefault("cost_anomaly.z_score_threshold", 4.0)
+	viper.SetDefault("cost_anomaly.multiplier", 5.0)
+	viper.SetDefault("cost_anomaly.min_hourly_cost", 5.0)
+	viper.SetDefault("cost_anomaly.min_hourly_requests", 200)
+	viper.SetDefault("cost_anomaly.no_baseline_hourly_cost", 50.0)
+	viper.SetDefault("cost_anomaly.auto_suspend", false)
+	viper.SetDefault("cost_anomaly.notify_user", true)
+	viper.SetDefault("cost_anomaly.cooldown_minutes", 60)
+
+	// API key sharing detection
+	viper.SetDefault("key_sharing.enabled", false)
+	viper.SetDefault("key_sharing.interval_minutes", 15)
+	viper.SetDefault("key_sharing.windows", []map[string]any{
+		{"minutes": 60, "distinct_ips": 10, "distinct_networks": 5, "distinct_user_agents": 4, "concurrent_ips": 4},
+		{"minutes": 1440, "distinct_ips": 40, "distinct_networks": 15, "distinct_user_agents": 8, "concurrent_ips": 6},
+	})
+	viper.SetDefault("key_sharing.min_requests", 20)
+	viper.SetDefault("key_sharing.score_threshold", 70.0)
+	viper.SetDefault("key_sharing.dismiss_cooldown_hours", 24)
+	viper.SetDefault("key_sharing.evidence_top_n", 10)
+
+	// API Key rotation
+	viper.SetDefault("api_key_rotation.default_grace_hours", 24)
+	viper.SetDefault("api_key_rotation.max_grace_hours", 720)
+	viper.SetDefault("api_key_rotation.cleanup_interval_minutes", 5)
+
 	// Idempotency
 	viper.SetDefault("idempotency.observe_only", true)
 	viper.SetDefault("idempotency.default_ttl_seconds", 86400)
@@ -1435,6 +1782,9 @@ func setDefaults() {
 	viper.SetDefault("gateway.usage_record.auto_scale_cooldown_seconds", 10)
 	viper.SetDefault("gateway.user_group_rate_cache_ttl_seconds", 30)
 	viper.SetDefault("gateway.models_list_cache_ttl_seconds", 15)
+	viper.SetDefault("gateway.stream_resume.buffer_ttl_seconds", 120)
+	viper.SetDefault("gateway.stream_resume.max_stream_bytes", 4*1024*1024)
+	viper.SetDefault("gateway.stream_resume.max_total_bytes", 256*1024*1024)
 	// TLS指纹伪装配置（默认关闭，需要账号级别单独启用）
 	// 用户消息串行队列默认值
 	viper.SetDefault("gateway.user_message_queue.enabled", false)
@@ -1559,6 +1909,9 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("log.sampling.thereafter must be non-negative")
 		}
 	}
+	if err := c.Log.Sinks.validate(); err != nil {
+		return err
+	}
 
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
@@ -1591,6 +1944,11 @@ func (c *Config) Validate() error {
 		}
 		warnIfInsecureURL("server.frontend_url", c.Server.FrontendURL)
 	}
+	for _, origin := range c.WebAuthn.RPOrigins {
+		if err := ValidateAbsoluteHTTPURL(origin); err != nil {
+			return fmt.Errorf("webauthn.rp_origins invalid: %w", err)
+		}
+	}
 	if c.JWT.ExpireHour <= 0 {
 		return fmt.Errorf("jwt.expire_hour must be positive")
 	}
@@ -1817,6 +2175,84 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("usage_cleanup.task_timeout_seconds must be non-negative")
 		}
 	}
+	if c.UsageArchive.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.UsageArchive.Granularity)) {
+		case "day", "month":
+		default:
+			return fmt.Errorf("usage_archive.granularity must be one of: day/month")
+		}
+		if c.UsageArchive.ArchiveTimeoutSeconds <= 0 {
+			return fmt.Errorf("usage_archive.archive_timeout_seconds must be positive")
+		}
+		if c.UsageArchive.RehydrateBatchSize <= 0 {
+			return fmt.Errorf("usage_archive.rehydrate_batch_size must be positive")
+		}
+	}
+	if c.Partitioning.Enabled {
+		if c.Partitioning.PremakeMonths < 1 || c.Partitioning.PremakeMonths > 24 {
+			return fmt.Errorf("partitioning.premake_months must be between 1-24")
+		}
+		if c.Partitioning.MaintenanceIntervalMinutes <= 0 {
+			return fmt.Errorf("partitioning.maintenance_interval_minutes must be positive")
+		}
+		if c.Partitioning.ConvertBatchSize <= 0 {
+			return fmt.Errorf("partitioning.convert_batch_size must be positive")
+		}
+		if c.Partitioning.DedupRetentionDays <= 0 {
+			return fmt.Errorf("partitioning.dedup_retention_days must be positive")
+		}
+	}
+	if c.CostAnomaly.Enabled {
+		if c.CostAnomaly.IntervalMinutes <= 0 {
+			return fmt.Errorf("cost_anomaly.interval_minutes must be positive")
+		}
+		if c.CostAnomaly.BaselineDays < 1 || c.CostAnomaly.BaselineDays > 90 {
+			return fmt.Errorf("cost_anomaly.baseline_days must be between 1-90")
+		}
+		if c.CostAnomaly.MinBaselineHours < 0 {
+			return fmt.Errorf("cost_anomaly.min_baseline_hours must be non-negative")
+		}
+		if c.CostAnomaly.ZScoreThreshold <= 0 && c.CostAnomaly.Multiplier <= 0 {
+			return fmt.Errorf("cost_anomaly.z_score_threshold or cost_anomaly.multiplier must be positive")
+		}
+		if c.CostAnomaly.MinHourlyCost < 0 || c.CostAnomaly.NoBaselineHourlyCost < 0 || c.CostAnomaly.MinHourlyRequests < 0 {
+			return fmt.Errorf("cost_anomaly thresholds must be non-negative")
+		}
+		if c.CostAnomaly.CooldownMinutes < 0 {
+			return fmt.Errorf("cost_anomaly.cooldown_minutes must be non-negative")
+		}
+	}
+	if c.KeySharing.Enabled {
+		if c.KeySharing.IntervalMinutes <= 0 {
+			return fmt.Errorf("key_sharing.interval_minutes must be positive")
+		}
+		if len(c.KeySharing.Windows) == 0 {
+			return fmt.Errorf("key_sharing.windows must not be empty")
+		}
+		for i, w := range c.KeySharing.Windows {
+			if w.Minutes <= 0 || w.Minutes > 7*24*60 {
+				return fmt.Errorf("key_sharing.windows[%d].minutes must be between 1-10080", i)
+			}
+			if w.DistinctIPs < 0 || w.DistinctNetworks < 0 || w.DistinctUserAgents < 0 || w.ConcurrentIPs < 0 {
+				return fmt.Errorf("key_sharing.windows[%d] thresholds must be non-negative", i)
+			}
+		}
+		if c.KeySharing.ScoreThreshold <= 0 || c.KeySharing.ScoreThreshold > 100 {
+			return fmt.Errorf("key_sharing.score_threshold must be between 0-100")
+		}
+		if c.KeySharing.MinRequests < 0 || c.KeySharing.DismissCooldownHours < 0 || c.KeySharing.EvidenceTopN < 0 {
+			return fmt.Errorf("key_sharing thresholds must be non-negative")
+		}
+	}
+	if c.APIKeyRotation.MaxGraceHours < 0 {
+		return fmt.Errorf("api_key_rotation.max_grace_hours must be non-negative")
+	}
+	if c.APIKeyRotation.DefaultGraceHours < 0 || c.APIKeyRotation.DefaultGraceHours > c.APIKeyRotation.MaxGraceHours {
+		return fmt.Errorf("api_key_rotation.default_grace_hours must be between 0 and max_grace_hours")
+	}
+	if c.APIKeyRotation.CleanupIntervalMinutes <= 0 {
+		return fmt.Errorf("api_key_rotation.cleanup_interval_minutes must be positive")
+	}
 	if c.Idempotency.DefaultTTLSeconds <= 0 {
 		return fmt.Errorf("idempotency.default_ttl_seconds must be positive")
 	}
@@ -2157,6 +2593,15 @@ func (c *Config) Validate() error {
 	if c.Gateway.ModelsListCacheTTLSeconds < 10 || c.Gateway.ModelsListCacheTTLSeconds > 30 {
 		return fmt.Errorf("gateway.models_list_cache_ttl_seconds must be between 10-30")
 	}
+	if c.Gateway.StreamResume.BufferTTLSeconds <= 0 {
+		return fmt.Errorf("gateway.stream_resume.buffer_ttl_seconds must be positive")
+	}
+	if c.Gateway.StreamResume.MaxStreamBytes <= 0 {
+		return fmt.Errorf("gateway.stream_resume.max_stream_bytes must be positive")
+	}
+	if c.Gateway.StreamResume.MaxTotalBytes < int64(c.Gateway.StreamResume.MaxStreamBytes) {
+		return fmt.Errorf("gateway.stream_resume.max_total_bytes must be at least max_stream_bytes")
+	}
 	if c.Gateway.Scheduling.StickySessionMaxWaiting <= 0 {
 		return fmt.Errorf("gateway.scheduling.sticky_session_max_waiting must be positive")
 	}
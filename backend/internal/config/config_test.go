@@ -76,6 +76,26 @@ func TestLoadDefaultSchedulingConfig(t *testing.T) {
 	}
 }
 
+func TestLoadDefaultKeySharingWindows(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	windows := cfg.KeySharing.Windows
+	if len(windows) != 2 {
+		t.Fatalf("len(KeySharing.Windows) = %d, want 2", len(windows))
+	}
+	if windows[0].Minutes != 60 || windows[0].DistinctIPs != 10 || windows[0].ConcurrentIPs != 4 {
+		t.Fatalf("KeySharing.Windows[0] = %+v, want 60m/10 ips/4 concurrent", windows[0])
+	}
+	if windows[1].Minutes != 1440 || windows[1].DistinctNetworks != 15 {
+		t.Fatalf("KeySharing.Windows[1] = %+v, want 1440m/15 networks", windows[1])
+	}
+}
+
 func TestLoadDefaultOpenAIWSConfig(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
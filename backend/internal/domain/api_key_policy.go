@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	apiKeyPolicyMaxPatterns      = 100
+	apiKeyPolicyMaxPatternLength = 200
+)
+
+// QueuePriorityMax 排队优先级上限（用户/分组/Key 共用 0-9 取值范围）
+const QueuePriorityMax = 9
+
+var ErrAPIKeyPolicyInvalid = infraerrors.BadRequest("API_KEY_POLICY_INVALID", "invalid api key policy")
+
+// APIKeyPolicy 单个 API Key 的访问策略，零值表示不做任何限制。
+//
+// 模型与端点规则支持 glob 通配：* 匹配任意字符序列，? 匹配单个字符。
+type APIKeyPolicy struct {
+	// AllowedModels 允许的模型，为空表示不限制
+	AllowedModels []string `json:"allowed_models,omitempty"`
+	// DeniedModels 禁止的模型，优先级高于 AllowedModels
+	DeniedModels []string `json:"denied_models,omitempty"`
+	// AllowedEndpoints 允许的请求路径（如 /v1/messages、/v1beta/models/*），为空表示不限制
+	AllowedEndpoints []string `json:"allowed_endpoints,omitempty"`
+	// AllowedPlatforms 允许的上游平台（anthropic/openai/gemini/antigravity/sora），为空表示不限制
+	AllowedPlatforms []string `json:"allowed_platforms,omitempty"`
+
+	// MaxInputTokens 单次请求的输入 token 上限（估算值），0 表示不限制
+	MaxInputTokens int `json:"max_input_tokens,omitempty"`
+	// MaxOutputTokens 单次请求的 max_tokens 上限，0 表示不限制
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// DenyThinking 禁止开启 thinking / reasoning
+	DenyThinking bool `json:"deny_thinking,omitempty"`
+	// DenyTools 禁止在请求中携带工具定义
+	DenyTools bool `json:"deny_tools,omitempty"`
+
+	// RPM 每分钟请求数上限，0 表示不限制
+	RPM int `json:"rpm,omitempty"`
+	// TPM 每分钟 token 数上限（输入+输出），0 表示不限制
+	TPM int `json:"tpm,omitempty"`
+
+	// QueuePriority 账号满载排队时的优先级（1-9），0 表示沿用用户/分组设置；只能下调不能上调
+	QueuePriority int `json:"queue_priority,omitempty"`
+
+	// ResponseCache 对确定性请求启用网关响应缓存（分组未配置时使用默认 TTL 与命中价格）
+	ResponseCache bool `json:"response_cache,omitempty"`
+}
+
+// IsEmpty 策略是否未配置任何限制
+func (p APIKeyPolicy) IsEmpty() bool {
+	return len(p.AllowedModels) == 0 &&
+		len(p.DeniedModels) == 0 &&
+		len(p.AllowedEndpoints) == 0 &&
+		len(p.AllowedPlatforms) == 0 &&
+		p.MaxInputTokens == 0 &&
+		p.MaxOutputTokens == 0 &&
+		!p.DenyThinking &&
+		!p.DenyTools &&
+		p.RPM == 0 &&
+		p.TPM == 0 &&
+		p.QueuePriority == 0 &&
+		!p.ResponseCache
+}
+
+// AllowsModel 判断模型是否被允许，拒绝列表优先
+func (p APIKeyPolicy) AllowsModel(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, pattern := range p.DeniedModels {
+		if globMatch(strings.ToLower(pattern), model) {
+			return false
+		}
+	}
+	if len(p.AllowedModels) == 0 {
+		return true
+	}
+	for _, pattern := range p.AllowedModels {
+		if globMatch(strings.ToLower(pattern), model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsEndpoint 判断请求路径是否被允许
+func (p APIKeyPolicy) AllowsEndpoint(path string) bool {
+	if len(p.AllowedEndpoints) == 0 {
+		return true
+	}
+	path = strings.TrimRight(path, "/")
+	for _, pattern := range p.AllowedEndpoints {
+		if globMatch(strings.TrimRight(pattern, "/"), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsPlatform 判断上游平台是否被允许，平台未知（空）时放行
+func (p APIKeyPolicy) AllowsPlatform(platform string) bool {
+	if len(p.AllowedPlatforms) == 0 || platform == "" {
+		return true
+	}
+	for _, allowed := range p.AllowedPlatforms {
+		if allowed == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeAndValidate 去除空白与重复项并校验取值范围
+func (p APIKeyPolicy) NormalizeAndValidate() (APIKeyPolicy, error) {
+	var err error
+	normalized := APIKeyPolicy{
+		MaxInputTokens:  p.MaxInputTokens,
+		MaxOutputTokens: p.MaxOutputTokens,
+		DenyThinking:    p.DenyThinking,
+		DenyTools:       p.DenyTools,
+		RPM:             p.RPM,
+		TPM:             p.TPM,
+		QueuePriority:   p.QueuePriority,
+		ResponseCache:   p.ResponseCache,
+	}
+
+	if normalized.AllowedModels, err = normalizePolicyPatterns("allowed_models", p.AllowedModels, true); err != nil {
+		return APIKeyPolicy{}, err
+	}
+	if normalized.DeniedModels, err = normalizePolicyPatterns("denied_models", p.DeniedModels, true); err != nil {
+		return APIKeyPolicy{}, err
+	}
+	if normalized.AllowedEndpoints, err = normalizePolicyPatterns("allowed_endpoints", p.AllowedEndpoints, false); err != nil {
+		return APIKeyPolicy{}, err
+	}
+	for _, endpoint := range normalized.AllowedEndpoints {
+		if !strings.HasPrefix(endpoint, "/") {
+			return APIKeyPolicy{}, fmt.Errorf("%w: allowed_endpoints entry %q must start with /", ErrAPIKeyPolicyInvalid, endpoint)
+		}
+	}
+	if normalized.AllowedPlatforms, err = normalizePolicyPatterns("allowed_platforms", p.AllowedPlatforms, true); err != nil {
+		return APIKeyPolicy{}, err
+	}
+	for _, platform := range normalized.AllowedPlatforms {
+		switch platform {
+		case PlatformAnthropic, PlatformOpenAI, PlatformGemini, PlatformAntigravity, PlatformSora:
+		default:
+			return APIKeyPolicy{}, fmt.Errorf("%w: unknown platform %q", ErrAPIKeyPolicyInvalid, platform)
+		}
+	}
+
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"max_input_tokens", p.MaxInputTokens},
+		{"max_output_tokens", p.MaxOutputTokens},
+		{"rpm", p.RPM},
+		{"tpm", p.TPM},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return APIKeyPolicy{}, fmt.Errorf("%w: %s must be >= 0", ErrAPIKeyPolicyInvalid, limit.name)
+		}
+	}
+	if p.QueuePriority < 0 || p.QueuePriority > QueuePriorityMax {
+		return APIKeyPolicy{}, fmt.Errorf("%w: queue_priority must be between 0 and %d", ErrAPIKeyPolicyInvalid, QueuePriorityMax)
+	}
+
+	return normalized, nil
+}
+
+func normalizePolicyPatterns(field string, patterns []string, lower bool) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	if len(patterns) > apiKeyPolicyMaxPatterns {
+		return nil, fmt.Errorf("%w: %s allows at most %d entries", ErrAPIKeyPolicyInvalid, field, apiKeyPolicyMaxPatterns)
+	}
+	out := make([]string, 0, len(patterns))
+	seen := make(map[string]struct{}, len(patterns))
+	for _, raw := range patterns {
+		pattern := strings.TrimSpace(raw)
+		if lower {
+			pattern = strings.ToLower(pattern)
+		}
+		if pattern == "" {
+			continue
+		}
+		if len(pattern) > apiKeyPolicyMaxPatternLength {
+			return nil, fmt.Errorf("%w: %s entry is too long", ErrAPIKeyPolicyInvalid, field)
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		out = append(out, pattern)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// globMatch 通配匹配：* 匹配任意字符序列（可跨 /），? 匹配单个字符
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	starPx, starSx := -1, 0
+	for sx < len(s) {
+		switch {
+		case px < len(pattern) && (pattern[px] == '?' || pattern[px] == s[sx]):
+			px++
+			sx++
+		case px < len(pattern) && pattern[px] == '*':
+			starPx, starSx = px, sx
+			px++
+		case starPx >= 0:
+			px = starPx + 1
+			starSx++
+			sx = starSx
+		default:
+			return false
+		}
+	}
+	for px < len(pattern) && pattern[px] == '*' {
+		px++
+	}
+	return px == len(pattern)
+}
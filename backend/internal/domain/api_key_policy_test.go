@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAPIKeyPolicy_AllowsModel(t *testing.T) {
+	t.Parallel()
+
+	policy := APIKeyPolicy{
+		AllowedModels: []string{"claude-*", "gpt-5?"},
+		DeniedModels:  []string{"claude-opus-*"},
+	}
+
+	cases := map[string]bool{
+		"claude-sonnet-4-5":   true,
+		"CLAUDE-SONNET-4-5":   true,
+		"claude-opus-4-1":     false,
+		"gpt-51":              true,
+		"gpt-5":               false,
+		"gemini-2.5-pro":      false,
+		"vendor/claude-haiku": false,
+	}
+	for model, want := range cases {
+		if got := policy.AllowsModel(model); got != want {
+			t.Errorf("AllowsModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+
+	denyOnly := APIKeyPolicy{DeniedModels: []string{"*image*"}}
+	if !denyOnly.AllowsModel("gemini-2.5-pro") {
+		t.Error("deny-only policy should allow unmatched models")
+	}
+	if denyOnly.AllowsModel("gemini-3.1-flash-image") {
+		t.Error("deny-only policy should reject matched models")
+	}
+}
+
+func TestAPIKeyPolicy_AllowsEndpointAndPlatform(t *testing.T) {
+	t.Parallel()
+
+	policy := APIKeyPolicy{
+		AllowedEndpoints: []string{"/v1/messages", "/v1beta/models/*"},
+		AllowedPlatforms: []string{PlatformAnthropic, PlatformGemini},
+	}
+
+	if !policy.AllowsEndpoint("/v1/messages") || !policy.AllowsEndpoint("/v1/messages/") {
+		t.Error("exact endpoint should be allowed")
+	}
+	if policy.AllowsEndpoint("/v1/messages/count_tokens") {
+		t.Error("sub path should not match exact endpoint")
+	}
+	if !policy.AllowsEndpoint("/v1beta/models/gemini-2.5-pro:generateContent") {
+		t.Error("wildcard endpoint should match")
+	}
+	if policy.AllowsEndpoint("/v1/responses") {
+		t.Error("unlisted endpoint should be rejected")
+	}
+
+	if !policy.AllowsPlatform(PlatformGemini) || policy.AllowsPlatform(PlatformOpenAI) {
+		t.Error("platform allow list mismatch")
+	}
+	if !policy.AllowsPlatform("") {
+		t.Error("unknown platform should be allowed")
+	}
+	if !(APIKeyPolicy{}).AllowsEndpoint("/anything") {
+		t.Error("empty policy should allow all endpoints")
+	}
+}
+
+func TestAPIKeyPolicy_NormalizeAndValidate(t *testing.T) {
+	t.Parallel()
+
+	normalized, err := APIKeyPolicy{
+		AllowedModels:    []string{" Claude-* ", "claude-*", ""},
+		AllowedEndpoints: []string{" /v1/messages "},
+		AllowedPlatforms: []string{"Anthropic"},
+		RPM:              60,
+	}.NormalizeAndValidate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(normalized.AllowedModels) != 1 || normalized.AllowedModels[0] != "claude-*" {
+		t.Fatalf("unexpected models: %v", normalized.AllowedModels)
+	}
+	if normalized.AllowedEndpoints[0] != "/v1/messages" || normalized.AllowedPlatforms[0] != PlatformAnthropic {
+		t.Fatalf("unexpected normalization: %+v", normalized)
+	}
+	if normalized.IsEmpty() {
+		t.Fatal("normalized policy should not be empty")
+	}
+
+	invalid := []APIKeyPolicy{
+		{AllowedPlatforms: []string{"unknown"}},
+		{AllowedEndpoints: []string{"v1/messages"}},
+		{MaxOutputTokens: -1},
+		{TPM: -5},
+		{QueuePriority: 10},
+	}
+	for _, policy := range invalid {
+		if _, err := policy.NormalizeAndValidate(); !errors.Is(err, ErrAPIKeyPolicyInvalid) {
+			t.Errorf("NormalizeAndValidate(%+v) error = %v, want ErrAPIKeyPolicyInvalid", policy, err)
+		}
+	}
+
+	empty, err := APIKeyPolicy{AllowedModels: []string{" "}}.NormalizeAndValidate()
+	if err != nil || !empty.IsEmpty() {
+		t.Fatalf("blank entries should normalize to empty policy, got %+v, %v", empty, err)
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 上下文超限时的处理方式
+const (
+	// ContextPolicyActionReject 直接拒绝，返回包含估算值的错误
+	ContextPolicyActionReject = "reject"
+	// ContextPolicyActionTruncate 丢弃最早的对话轮次
+	ContextPolicyActionTruncate = "truncate"
+	// ContextPolicyActionSummarize 用低价模型摘要最早的对话轮次
+	ContextPolicyActionSummarize = "summarize"
+)
+
+const (
+	DefaultContextPolicyMaxInputTokens   = 180000
+	MinContextPolicyMaxInputTokens       = 1024
+	MaxContextPolicyMaxInputTokens       = 2000000
+	DefaultContextPolicySummaryMaxTokens = 2048
+	MaxContextPolicySummaryMaxTokens     = 16384
+)
+
+var ErrContextPolicyInvalid = infraerrors.BadRequest("CONTEXT_POLICY_INVALID", "invalid context policy config")
+
+// ContextPolicyConfig 分组级上下文策略：转发前本地估算输入 token，超出上限时按 Action 处理，
+// 避免上游返回 "prompt is too long" 后反复切换账号。
+type ContextPolicyConfig struct {
+	Enabled bool `json:"enabled"`
+	// Action 超限处理方式：reject / truncate / summarize
+	Action string `json:"action"`
+	// MaxInputTokens 输入 token 上限（本地估算值，应低于模型上下文窗口留出余量）
+	MaxInputTokens int `json:"max_input_tokens"`
+	// SummaryModel 摘要使用的模型（summarize 必填），从本分组账号中调度
+	SummaryModel string `json:"summary_model,omitempty"`
+	// SummaryMaxTokens 摘要输出 token 上限，同时作为摘要在上下文中的预留空间
+	SummaryMaxTokens int `json:"summary_max_tokens,omitempty"`
+}
+
+// NormalizeContextPolicyConfig 校验上下文策略并填充默认值；未启用时归一为 nil
+func NormalizeContextPolicyConfig(cfg *ContextPolicyConfig) (*ContextPolicyConfig, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	out := *cfg
+	out.Action = strings.ToLower(strings.TrimSpace(out.Action))
+	out.SummaryModel = strings.TrimSpace(out.SummaryModel)
+	switch out.Action {
+	case "":
+		out.Action = ContextPolicyActionReject
+	case ContextPolicyActionReject, ContextPolicyActionTruncate, ContextPolicyActionSummarize:
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrContextPolicyInvalid, out.Action)
+	}
+	if out.MaxInputTokens == 0 {
+		out.MaxInputTokens = DefaultContextPolicyMaxInputTokens
+	}
+	if out.MaxInputTokens < MinContextPolicyMaxInputTokens || out.MaxInputTokens > MaxContextPolicyMaxInputTokens {
+		return nil, fmt.Errorf("%w: max_input_tokens must be in [%d, %d]", ErrContextPolicyInvalid, MinContextPolicyMaxInputTokens, MaxContextPolicyMaxInputTokens)
+	}
+	if out.Action != ContextPolicyActionSummarize {
+		out.SummaryModel = ""
+		out.SummaryMaxTokens = 0
+		return &out, nil
+	}
+	if out.SummaryModel == "" {
+		return nil, fmt.Errorf("%w: summary_model is required for action summarize", ErrContextPolicyInvalid)
+	}
+	if out.SummaryMaxTokens == 0 {
+		out.SummaryMaxTokens = DefaultContextPolicySummaryMaxTokens
+	}
+	if out.SummaryMaxTokens < 0 || out.SummaryMaxTokens > MaxContextPolicySummaryMaxTokens {
+		return nil, fmt.Errorf("%w: summary_max_tokens must be in [1, %d]", ErrContextPolicyInvalid, MaxContextPolicySummaryMaxTokens)
+	}
+	if out.SummaryMaxTokens >= out.MaxInputTokens/2 {
+		return nil, fmt.Errorf("%w: summary_max_tokens must be less than half of max_input_tokens", ErrContextPolicyInvalid)
+	}
+	return &out, nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeContextPolicyConfig(t *testing.T) {
+	t.Parallel()
+
+	if cfg, err := NormalizeContextPolicyConfig(&ContextPolicyConfig{Action: "truncate"}); err != nil || cfg != nil {
+		t.Fatalf("disabled config should normalize to nil, got %+v, %v", cfg, err)
+	}
+
+	cfg, err := NormalizeContextPolicyConfig(&ContextPolicyConfig{Enabled: true, SummaryModel: "claude-haiku-4-5"})
+	if err != nil || cfg == nil {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+	if cfg.Action != ContextPolicyActionReject || cfg.MaxInputTokens != DefaultContextPolicyMaxInputTokens || cfg.SummaryModel != "" {
+		t.Fatalf("defaults not applied: %+v", cfg)
+	}
+
+	cfg, err = NormalizeContextPolicyConfig(&ContextPolicyConfig{Enabled: true, Action: " Summarize ", SummaryModel: " claude-haiku-4-5 "})
+	if err != nil || cfg == nil {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+	if cfg.Action != ContextPolicyActionSummarize || cfg.SummaryModel != "claude-haiku-4-5" || cfg.SummaryMaxTokens != DefaultContextPolicySummaryMaxTokens {
+		t.Fatalf("summarize defaults not applied: %+v", cfg)
+	}
+
+	invalid := []ContextPolicyConfig{
+		{Enabled: true, Action: "compress"},
+		{Enabled: true, MaxInputTokens: MinContextPolicyMaxInputTokens - 1},
+		{Enabled: true, MaxInputTokens: MaxContextPolicyMaxInputTokens + 1},
+		{Enabled: true, Action: ContextPolicyActionSummarize},
+		{Enabled: true, Action: ContextPolicyActionSummarize, SummaryModel: "m", SummaryMaxTokens: MaxContextPolicySummaryMaxTokens + 1},
+		{Enabled: true, Action: ContextPolicyActionSummarize, SummaryModel: "m", MaxInputTokens: 4096, SummaryMaxTokens: 2048},
+	}
+	for _, c := range invalid {
+		c := c
+		if _, err := NormalizeContextPolicyConfig(&c); !errors.Is(err, ErrContextPolicyInvalid) {
+			t.Errorf("NormalizeContextPolicyConfig(%+v) error = %v, want ErrContextPolicyInvalid", c, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 实验状态
+const (
+	ExperimentStatusDraft   = "draft"
+	ExperimentStatusRunning = "running"
+	ExperimentStatusStopped = "stopped"
+)
+
+// 实验分流粘性维度
+const (
+	ExperimentStickyUser   = "user"
+	ExperimentStickyAPIKey = "api_key"
+)
+
+const (
+	experimentMaxVariants       = 10
+	experimentMaxPatchLen       = 8192
+	experimentVariantNameMaxLen = 64
+)
+
+var experimentVariantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var ErrExperimentInvalid = infraerrors.BadRequest("EXPERIMENT_INVALID", "invalid experiment")
+
+// ExperimentVariant 实验分组（变体）。所有覆盖项为空的变体即对照组。
+type ExperimentVariant struct {
+	Name string `json:"name"`
+	// Percent 流量占比（整数百分比，所有变体合计 100）
+	Percent int `json:"percent"`
+	// Model 替换请求模型，为空表示不修改
+	Model string `json:"model,omitempty"`
+	// GroupID 改由指定分组的账号处理（计费按该分组倍率），0 表示不修改
+	GroupID int64 `json:"group_id,omitempty"`
+	// SystemPromptPatch 追加到 system 末尾的文本，为空表示不修改
+	SystemPromptPatch string `json:"system_prompt_patch,omitempty"`
+}
+
+// IsControl 判断是否为对照组（不做任何改写）
+func (v ExperimentVariant) IsControl() bool {
+	return v.Model == "" && v.GroupID == 0 && v.SystemPromptPatch == ""
+}
+
+// Experiment A/B 模型实验：匹配 ModelPattern 的请求按粘性维度哈希分配到各变体。
+//
+// ModelPattern 支持 glob 通配（* / ?）；GroupIDs 为空表示不限 API Key 所属分组。
+type Experiment struct {
+	ID           int64               `json:"id"`
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Status       string              `json:"status"`
+	ModelPattern string              `json:"model_pattern"`
+	GroupIDs     []int64             `json:"group_ids"`
+	StickyBy     string              `json:"sticky_by"`
+	Variants     []ExperimentVariant `json:"variants"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// MatchesModel 判断请求模型是否命中实验
+func (e *Experiment) MatchesModel(model string) bool {
+	return globMatch(e.ModelPattern, strings.ToLower(strings.TrimSpace(model)))
+}
+
+// MatchesGroup 判断 API Key 所属分组是否在实验范围内
+func (e *Experiment) MatchesGroup(groupID *int64) bool {
+	if len(e.GroupIDs) == 0 {
+		return true
+	}
+	if groupID == nil {
+		return false
+	}
+	for _, id := range e.GroupIDs {
+		if id == *groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// OverridesGroup 判断是否有变体改写分组
+func (e *Experiment) OverridesGroup() bool {
+	for _, v := range e.Variants {
+		if v.GroupID > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Assign 按粘性键稳定地分配变体：同一用户/Key 在实验配置不变时始终落在同一变体
+func (e *Experiment) Assign(stickyKey int64) *ExperimentVariant {
+	if len(e.Variants) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strconv.FormatInt(e.ID, 10) + ":" + e.StickyBy + ":" + strconv.FormatInt(stickyKey, 10)))
+	bucket := int(h.Sum32() % 100)
+	cumulative := 0
+	for i := range e.Variants {
+		cumulative += e.Variants[i].Percent
+		if bucket < cumulative {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// NormalizeExperiment 去除空白并校验实验配置
+func NormalizeExperiment(e *Experiment) error {
+	if e == nil {
+		return fmt.Errorf("%w: experiment is required", ErrExperimentInvalid)
+	}
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrExperimentInvalid)
+	}
+	e.Description = strings.TrimSpace(e.Description)
+
+	e.Status = strings.ToLower(strings.TrimSpace(e.Status))
+	switch e.Status {
+	case "":
+		e.Status = ExperimentStatusDraft
+	case ExperimentStatusDraft, ExperimentStatusRunning, ExperimentStatusStopped:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrExperimentInvalid, e.Status)
+	}
+
+	e.ModelPattern = strings.ToLower(strings.TrimSpace(e.ModelPattern))
+	if e.ModelPattern == "" {
+		return fmt.Errorf("%w: model_pattern is required", ErrExperimentInvalid)
+	}
+
+	e.StickyBy = strings.ToLower(strings.TrimSpace(e.StickyBy))
+	switch e.StickyBy {
+	case "":
+		e.StickyBy = ExperimentStickyUser
+	case ExperimentStickyUser, ExperimentStickyAPIKey:
+	default:
+		return fmt.Errorf("%w: unknown sticky_by %q", ErrExperimentInvalid, e.StickyBy)
+	}
+
+	groupIDs := make([]int64, 0, len(e.GroupIDs))
+	seenGroups := make(map[int64]struct{}, len(e.GroupIDs))
+	for _, id := range e.GroupIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: invalid group id %d", ErrExperimentInvalid, id)
+		}
+		if _, ok := seenGroups[id]; ok {
+			continue
+		}
+		seenGroups[id] = struct{}{}
+		groupIDs = append(groupIDs, id)
+	}
+	e.GroupIDs = groupIDs
+
+	if len(e.Variants) < 2 {
+		return fmt.Errorf("%w: at least 2 variants are required", ErrExperimentInvalid)
+	}
+	if len(e.Variants) > experimentMaxVariants {
+		return fmt.Errorf("%w: at most %d variants allowed", ErrExperimentInvalid, experimentMaxVariants)
+	}
+	total := 0
+	seenNames := make(map[string]struct{}, len(e.Variants))
+	for i := range e.Variants {
+		v := &e.Variants[i]
+		v.Name = strings.ToLower(strings.TrimSpace(v.Name))
+		if v.Name == "" || len(v.Name) > experimentVariantNameMaxLen || !experimentVariantNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("%w: invalid variant name %q", ErrExperimentInvalid, v.Name)
+		}
+		if _, ok := seenNames[v.Name]; ok {
+			return fmt.Errorf("%w: duplicate variant name %q", ErrExperimentInvalid, v.Name)
+		}
+		seenNames[v.Name] = struct{}{}
+		if v.Percent < 0 || v.Percent > 100 {
+			return fmt.Errorf("%w: variant %q percent must be in [0, 100]", ErrExperimentInvalid, v.Name)
+		}
+		total += v.Percent
+		v.Model = strings.TrimSpace(v.Model)
+		if v.GroupID < 0 {
+			return fmt.Errorf("%w: variant %q has an invalid group id", ErrExperimentInvalid, v.Name)
+		}
+		v.SystemPromptPatch = strings.TrimSpace(v.SystemPromptPatch)
+		if len(v.SystemPromptPatch) > experimentMaxPatchLen {
+			return fmt.Errorf("%w: variant %q system prompt patch exceeds %d bytes", ErrExperimentInvalid, v.Name, experimentMaxPatchLen)
+		}
+	}
+	if total != 100 {
+		return fmt.Errorf("%w: variant percents must sum to 100, got %d", ErrExperimentInvalid, total)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func validExperiment() *Experiment {
+	return &Experiment{
+		Name:         " sonnet next ",
+		ModelPattern: " Claude-Sonnet-4* ",
+		GroupIDs:     []int64{3, 3, 5},
+		Variants: []ExperimentVariant{
+			{Name: " Control ", Percent: 50},
+			{Name: "treatment", Percent: 50, Model: " claude-sonnet-4-5 ", SystemPromptPatch: " be brief "},
+		},
+	}
+}
+
+func TestNormalizeExperiment(t *testing.T) {
+	t.Parallel()
+
+	exp := validExperiment()
+	if err := NormalizeExperiment(exp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp.Name != "sonnet next" || exp.ModelPattern != "claude-sonnet-4*" {
+		t.Fatalf("unexpected normalization: %+v", exp)
+	}
+	if exp.Status != ExperimentStatusDraft || exp.StickyBy != ExperimentStickyUser {
+		t.Fatalf("defaults not applied: status=%q sticky_by=%q", exp.Status, exp.StickyBy)
+	}
+	if len(exp.GroupIDs) != 2 {
+		t.Fatalf("duplicate group ids should be removed, got %v", exp.GroupIDs)
+	}
+	if exp.Variants[0].Name != "control" || !exp.Variants[0].IsControl() {
+		t.Fatalf("unexpected control variant: %+v", exp.Variants[0])
+	}
+	if exp.Variants[1].Model != "claude-sonnet-4-5" || exp.Variants[1].SystemPromptPatch != "be brief" {
+		t.Fatalf("unexpected treatment variant: %+v", exp.Variants[1])
+	}
+
+	invalid := []func(e *Experiment){
+		func(e *Experiment) { e.Name = " " },
+		func(e *Experiment) { e.ModelPattern = "" },
+		func(e *Experiment) { e.Status = "paused" },
+		func(e *Experiment) { e.StickyBy = "ip" },
+		func(e *Experiment) { e.GroupIDs = []int64{0} },
+		func(e *Experiment) { e.Variants = e.Variants[:1] },
+		func(e *Experiment) { e.Variants[1].Name = "Control" },
+		func(e *Experiment) { e.Variants[1].Name = "bad name" },
+		func(e *Experiment) { e.Variants[1].Percent = 40 },
+		func(e *Experiment) { e.Variants[0].Percent, e.Variants[1].Percent = -10, 110 },
+	}
+	for i, mutate := range invalid {
+		exp := validExperiment()
+		mutate(exp)
+		if err := NormalizeExperiment(exp); !errors.Is(err, ErrExperimentInvalid) {
+			t.Fatalf("case %d: expected ErrExperimentInvalid, got %v", i, err)
+		}
+	}
+}
+
+func TestExperimentMatches(t *testing.T) {
+	t.Parallel()
+
+	exp := validExperiment()
+	if err := NormalizeExperiment(exp); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exp.MatchesModel("claude-sonnet-4-20250514") || exp.MatchesModel("claude-opus-4") {
+		t.Fatal("model pattern matching is wrong")
+	}
+	in, out := int64(5), int64(7)
+	if !exp.MatchesGroup(&in) || exp.MatchesGroup(&out) || exp.MatchesGroup(nil) {
+		t.Fatal("group scope matching is wrong")
+	}
+	exp.GroupIDs = nil
+	if !exp.MatchesGroup(nil) {
+		t.Fatal("empty group scope should match all keys")
+	}
+	if exp.OverridesGroup() {
+		t.Fatal("no variant overrides group")
+	}
+}
+
+func TestExperimentAssignIsStickyAndWeighted(t *testing.T) {
+	t.Parallel()
+
+	exp := &Experiment{
+		ID:       42,
+		StickyBy: ExperimentStickyUser,
+		Variants: []ExperimentVariant{
+			{Name: "control", Percent: 80},
+			{Name: "treatment", Percent: 20},
+			{Name: "off", Percent: 0},
+		},
+	}
+	counts := map[string]int{}
+	const total = 20000
+	for key := int64(1); key <= total; key++ {
+		v := exp.Assign(key)
+		if v == nil {
+			t.Fatalf("key %d was not assigned", key)
+		}
+		if again := exp.Assign(key); again.Name != v.Name {
+			t.Fatalf("assignment for key %d is not sticky", key)
+		}
+		counts[v.Name]++
+	}
+	if counts["off"] != 0 {
+		t.Fatalf("0%% variant received traffic: %d", counts["off"])
+	}
+	share := float64(counts["treatment"]) / total
+	if math.Abs(share-0.2) > 0.02 {
+		t.Fatalf("treatment share %.3f deviates from 20%%", share)
+	}
+}
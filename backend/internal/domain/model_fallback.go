@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	modelFallbackMaxChains = 50
+	modelFallbackMaxSteps  = 10
+)
+
+// 模型降级触发条件
+const (
+	// ModelFallbackTriggerNoAccount 没有可调度账号
+	ModelFallbackTriggerNoAccount = "no_account"
+	// ModelFallbackTriggerRateLimited 上游 429
+	ModelFallbackTriggerRateLimited = "rate_limited"
+	// ModelFallbackTriggerOverloaded 上游 529 / 503 过载
+	ModelFallbackTriggerOverloaded = "overloaded"
+	// ModelFallbackTriggerContextTooLong 上下文超出模型上限
+	ModelFallbackTriggerContextTooLong = "context_too_long"
+)
+
+var ErrModelFallbackInvalid = infraerrors.BadRequest("MODEL_FALLBACK_INVALID", "invalid model fallback chain")
+
+// ModelFallbackStep 降级链中的一步
+type ModelFallbackStep struct {
+	// Model 降级目标模型
+	Model string `json:"model"`
+	// Platform 限定调度的账号平台（anthropic / antigravity），为空表示按分组平台调度
+	Platform string `json:"platform,omitempty"`
+}
+
+// ModelFallbackChain 分组级模型降级链：请求模型匹配 Source 且命中触发条件时，依次尝试 Fallbacks。
+//
+// Source 支持 glob 通配：* 匹配任意字符序列，? 匹配单个字符。
+// 降级链仅作用于 Claude Messages 接口（/v1/messages），因此只能配置在 anthropic / antigravity 分组上。
+type ModelFallbackChain struct {
+	Source    string              `json:"source"`
+	Fallbacks []ModelFallbackStep `json:"fallbacks"`
+	// Triggers 触发条件，为空表示全部条件均触发
+	Triggers []string `json:"triggers,omitempty"`
+}
+
+// Matches 判断请求模型是否匹配该降级链
+func (c ModelFallbackChain) Matches(model string) bool {
+	return globMatch(c.Source, strings.ToLower(strings.TrimSpace(model)))
+}
+
+// TriggeredBy 判断触发条件是否启用
+func (c ModelFallbackChain) TriggeredBy(trigger string) bool {
+	if len(c.Triggers) == 0 {
+		return true
+	}
+	for _, t := range c.Triggers {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// FindModelFallbackChain 返回第一个匹配请求模型的降级链
+func FindModelFallbackChain(chains []ModelFallbackChain, model string) *ModelFallbackChain {
+	for i := range chains {
+		if chains[i].Matches(model) {
+			return &chains[i]
+		}
+	}
+	return nil
+}
+
+// ModelFallbackSupportsGroupPlatform 判断分组平台是否支持配置降级链（仅 Messages 接口所在的平台）
+func ModelFallbackSupportsGroupPlatform(platform string) bool {
+	return platform == PlatformAnthropic || platform == PlatformAntigravity
+}
+
+// NormalizeModelFallbackChains 去除空白并校验降级链配置，空配置归一为 nil
+func NormalizeModelFallbackChains(chains []ModelFallbackChain) ([]ModelFallbackChain, error) {
+	if len(chains) == 0 {
+		return nil, nil
+	}
+	if len(chains) > modelFallbackMaxChains {
+		return nil, fmt.Errorf("%w: at most %d chains allowed", ErrModelFallbackInvalid, modelFallbackMaxChains)
+	}
+	out := make([]ModelFallbackChain, 0, len(chains))
+	for _, chain := range chains {
+		source := strings.ToLower(strings.TrimSpace(chain.Source))
+		if source == "" {
+			return nil, fmt.Errorf("%w: source is required", ErrModelFallbackInvalid)
+		}
+		if len(chain.Fallbacks) == 0 {
+			return nil, fmt.Errorf("%w: chain %q has no fallbacks", ErrModelFallbackInvalid, source)
+		}
+		if len(chain.Fallbacks) > modelFallbackMaxSteps {
+			return nil, fmt.Errorf("%w: chain %q allows at most %d fallbacks", ErrModelFallbackInvalid, source, modelFallbackMaxSteps)
+		}
+		normalized := ModelFallbackChain{Source: source}
+		for _, step := range chain.Fallbacks {
+			model := strings.TrimSpace(step.Model)
+			if model == "" {
+				return nil, fmt.Errorf("%w: chain %q has an empty fallback model", ErrModelFallbackInvalid, source)
+			}
+			platform := strings.ToLower(strings.TrimSpace(step.Platform))
+			switch platform {
+			case "", PlatformAnthropic, PlatformAntigravity:
+			default:
+				return nil, fmt.Errorf("%w: unknown platform %q", ErrModelFallbackInvalid, platform)
+			}
+			normalized.Fallbacks = append(normalized.Fallbacks, ModelFallbackStep{Model: model, Platform: platform})
+		}
+		seen := make(map[string]struct{}, len(chain.Triggers))
+		for _, raw := range chain.Triggers {
+			trigger := strings.ToLower(strings.TrimSpace(raw))
+			switch trigger {
+			case ModelFallbackTriggerNoAccount, ModelFallbackTriggerRateLimited, ModelFallbackTriggerOverloaded, ModelFallbackTriggerContextTooLong:
+			default:
+				return nil, fmt.Errorf("%w: unknown trigger %q", ErrModelFallbackInvalid, raw)
+			}
+			if _, ok := seen[trigger]; ok {
+				continue
+			}
+			seen[trigger] = struct{}{}
+			normalized.Triggers = append(normalized.Triggers, trigger)
+		}
+		out = append(out, normalized)
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeModelFallbackChains(t *testing.T) {
+	t.Parallel()
+
+	chains, err := NormalizeModelFallbackChains([]ModelFallbackChain{{
+		Source: " Claude-Opus-4* ",
+		Fallbacks: []ModelFallbackStep{
+			{Model: " claude-sonnet-4 "},
+			{Model: "gemini-2.5-pro", Platform: "Antigravity"},
+		},
+		Triggers: []string{"rate_limited", "Overloaded", "rate_limited"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chain := chains[0]
+	if chain.Source != "claude-opus-4*" || chain.Fallbacks[0].Model != "claude-sonnet-4" || chain.Fallbacks[1].Platform != PlatformAntigravity {
+		t.Fatalf("unexpected normalization: %+v", chain)
+	}
+	if len(chain.Triggers) != 2 {
+		t.Fatalf("duplicate triggers should be removed, got %v", chain.Triggers)
+	}
+
+	invalid := [][]ModelFallbackChain{
+		{{Source: "", Fallbacks: []ModelFallbackStep{{Model: "a"}}}},
+		{{Source: "a"}},
+		{{Source: "a", Fallbacks: []ModelFallbackStep{{Model: " "}}}},
+		{{Source: "a", Fallbacks: []ModelFallbackStep{{Model: "b", Platform: "unknown"}}}},
+		{{Source: "a", Fallbacks: []ModelFallbackStep{{Model: "b", Platform: PlatformOpenAI}}}},
+		{{Source: "a", Fallbacks: []ModelFallbackStep{{Model: "b"}}, Triggers: []string{"timeout"}}},
+	}
+	for _, c := range invalid {
+		if _, err := NormalizeModelFallbackChains(c); !errors.Is(err, ErrModelFallbackInvalid) {
+			t.Errorf("NormalizeModelFallbackChains(%+v) error = %v, want ErrModelFallbackInvalid", c, err)
+		}
+	}
+
+	empty, err := NormalizeModelFallbackChains(nil)
+	if err != nil || empty != nil {
+		t.Fatalf("empty chains should normalize to nil, got %+v, %v", empty, err)
+	}
+}
+
+func TestFindModelFallbackChain(t *testing.T) {
+	t.Parallel()
+
+	chains := []ModelFallbackChain{
+		{Source: "claude-opus-4*", Fallbacks: []ModelFallbackStep{{Model: "claude-sonnet-4"}}, Triggers: []string{ModelFallbackTriggerRateLimited}},
+		{Source: "*", Fallbacks: []ModelFallbackStep{{Model: "claude-haiku-4"}}},
+	}
+
+	chain := FindModelFallbackChain(chains, "Claude-Opus-4-1")
+	if chain == nil || chain.Fallbacks[0].Model != "claude-sonnet-4" {
+		t.Fatalf("expected opus chain, got %+v", chain)
+	}
+	if !chain.TriggeredBy(ModelFallbackTriggerRateLimited) || chain.TriggeredBy(ModelFallbackTriggerNoAccount) {
+		t.Fatal("trigger filter mismatch")
+	}
+	if !FindModelFallbackChain(chains, "claude-sonnet-4").TriggeredBy(ModelFallbackTriggerNoAccount) {
+		t.Fatal("chain without triggers should accept every trigger")
+	}
+	if FindModelFallbackChain(chains[:1], "gpt-5") != nil {
+		t.Fatal("unexpected match")
+	}
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 响应缓存共享范围
+const (
+	ResponseCacheScopeAPIKey = "api_key"
+	ResponseCacheScopeGroup  = "group"
+)
+
+const (
+	DefaultResponseCacheTTLSeconds    = 3600
+	MaxResponseCacheTTLSeconds        = 7 * 24 * 3600
+	DefaultResponseCacheMaxEntryBytes = 1 << 20
+	MaxResponseCacheMaxEntryBytes     = 8 << 20
+	DefaultResponseCacheMaxTotalBytes = 64 << 20
+	MaxResponseCacheMaxTotalBytes     = 1 << 30
+	DefaultResponseCacheHitPriceRatio = 0.1
+)
+
+var ErrResponseCacheInvalid = infraerrors.BadRequest("RESPONSE_CACHE_INVALID", "invalid response cache config")
+
+// ResponseCacheConfig 网关响应缓存配置（分组级；API Key 策略开启时使用默认值）。
+//
+// 仅缓存确定性请求（temperature 显式为 0），按规范化后的请求体精确匹配；
+// 命中时直接回放缓存的 JSON 或 SSE 响应，按原始费用 × HitPriceRatio 计费。
+type ResponseCacheConfig struct {
+	Enabled bool `json:"enabled"`
+	// TTLSeconds 缓存有效期（秒）
+	TTLSeconds int `json:"ttl_seconds"`
+	// MaxEntryBytes 单条响应的缓存上限，超出不缓存
+	MaxEntryBytes int `json:"max_entry_bytes"`
+	// MaxTotalBytes 单个共享范围（API Key 或分组）内缓存总量上限，超出时按写入时间淘汰最早的条目
+	MaxTotalBytes int `json:"max_total_bytes"`
+	// HitPriceRatio 命中计费比例 [0, 1]，相对未命中时的费用
+	HitPriceRatio float64 `json:"hit_price_ratio"`
+	// Scope 共享范围：api_key（默认，仅同一 Key 命中）或 group（同分组内所有 Key 共享）
+	Scope string `json:"scope"`
+}
+
+// DefaultResponseCacheConfig 仅由 API Key 策略开启缓存时使用的默认配置
+func DefaultResponseCacheConfig() *ResponseCacheConfig {
+	return &ResponseCacheConfig{
+		Enabled:       true,
+		TTLSeconds:    DefaultResponseCacheTTLSeconds,
+		MaxEntryBytes: DefaultResponseCacheMaxEntryBytes,
+		MaxTotalBytes: DefaultResponseCacheMaxTotalBytes,
+		HitPriceRatio: DefaultResponseCacheHitPriceRatio,
+		Scope:         ResponseCacheScopeAPIKey,
+	}
+}
+
+// NormalizeResponseCacheConfig 校验缓存配置并填充默认值；未启用时归一为 nil
+func NormalizeResponseCacheConfig(cfg *ResponseCacheConfig) (*ResponseCacheConfig, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	out := *cfg
+	if out.TTLSeconds == 0 {
+		out.TTLSeconds = DefaultResponseCacheTTLSeconds
+	}
+	if out.TTLSeconds < 0 || out.TTLSeconds > MaxResponseCacheTTLSeconds {
+		return nil, fmt.Errorf("%w: ttl_seconds must be in [1, %d]", ErrResponseCacheInvalid, MaxResponseCacheTTLSeconds)
+	}
+	if out.MaxEntryBytes == 0 {
+		out.MaxEntryBytes = DefaultResponseCacheMaxEntryBytes
+	}
+	if out.MaxEntryBytes < 0 || out.MaxEntryBytes > MaxResponseCacheMaxEntryBytes {
+		return nil, fmt.Errorf("%w: max_entry_bytes must be in [1, %d]", ErrResponseCacheInvalid, MaxResponseCacheMaxEntryBytes)
+	}
+	if out.MaxTotalBytes == 0 {
+		out.MaxTotalBytes = DefaultResponseCacheMaxTotalBytes
+	}
+	if out.MaxTotalBytes < out.MaxEntryBytes || out.MaxTotalBytes > MaxResponseCacheMaxTotalBytes {
+		return nil, fmt.Errorf("%w: max_total_bytes must be in [max_entry_bytes, %d]", ErrResponseCacheInvalid, MaxResponseCacheMaxTotalBytes)
+	}
+	if out.HitPriceRatio < 0 || out.HitPriceRatio > 1 {
+		return nil, fmt.Errorf("%w: hit_price_ratio must be in [0, 1]", ErrResponseCacheInvalid)
+	}
+	switch out.Scope {
+	case "":
+		out.Scope = ResponseCacheScopeAPIKey
+	case ResponseCacheScopeAPIKey, ResponseCacheScopeGroup:
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %q", ErrResponseCacheInvalid, out.Scope)
+	}
+	return &out, nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeResponseCacheConfig(t *testing.T) {
+	t.Parallel()
+
+	if cfg, err := NormalizeResponseCacheConfig(&ResponseCacheConfig{TTLSeconds: 60}); err != nil || cfg != nil {
+		t.Fatalf("disabled config should normalize to nil, got %+v, %v", cfg, err)
+	}
+
+	cfg, err := NormalizeResponseCacheConfig(&ResponseCacheConfig{Enabled: true, HitPriceRatio: 0.2})
+	if err != nil || cfg == nil {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+	if cfg.TTLSeconds != DefaultResponseCacheTTLSeconds || cfg.MaxEntryBytes != DefaultResponseCacheMaxEntryBytes || cfg.MaxTotalBytes != DefaultResponseCacheMaxTotalBytes || cfg.Scope != ResponseCacheScopeAPIKey {
+		t.Fatalf("defaults not applied: %+v", cfg)
+	}
+
+	invalid := []ResponseCacheConfig{
+		{Enabled: true, TTLSeconds: -1},
+		{Enabled: true, TTLSeconds: MaxResponseCacheTTLSeconds + 1},
+		{Enabled: true, MaxEntryBytes: MaxResponseCacheMaxEntryBytes + 1},
+		{Enabled: true, MaxEntryBytes: 2 << 20, MaxTotalBytes: 1 << 20},
+		{Enabled: true, MaxTotalBytes: MaxResponseCacheMaxTotalBytes + 1},
+		{Enabled: true, HitPriceRatio: 1.5},
+		{Enabled: true, Scope: "global"},
+	}
+	for _, c := range invalid {
+		c := c
+		if _, err := NormalizeResponseCacheConfig(&c); !errors.Is(err, ErrResponseCacheInvalid) {
+			t.Errorf("NormalizeResponseCacheConfig(%+v) error = %v, want ErrResponseCacheInvalid", c, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var ErrShadowMirrorInvalid = infraerrors.BadRequest("SHADOW_MIRROR_INVALID", "invalid shadow mirror config")
+
+// ShadowMirrorConfig 分组级影子流量镜像配置。
+//
+// 命中采样的请求在主请求完成后异步复制一份发往影子账号（或影子分组内调度出的账号），
+// 影子响应直接丢弃，只记录状态码、耗时、token 用量与输出相似度，不产生任何计费。
+type ShadowMirrorConfig struct {
+	Enabled bool `json:"enabled"`
+	// SampleRate 采样比例（0-1]
+	SampleRate float64 `json:"sample_rate"`
+	// AccountID 影子账号，与 GroupID 二选一
+	AccountID int64 `json:"account_id,omitempty"`
+	// GroupID 影子分组：在该分组内按负载调度账号
+	GroupID int64 `json:"group_id,omitempty"`
+}
+
+// NormalizeShadowMirrorConfig 校验镜像配置；未启用时归一为 nil
+func NormalizeShadowMirrorConfig(cfg *ShadowMirrorConfig) (*ShadowMirrorConfig, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		return nil, fmt.Errorf("%w: sample_rate must be in (0, 1]", ErrShadowMirrorInvalid)
+	}
+	if cfg.AccountID < 0 || cfg.GroupID < 0 {
+		return nil, fmt.Errorf("%w: invalid target id", ErrShadowMirrorInvalid)
+	}
+	if (cfg.AccountID > 0) == (cfg.GroupID > 0) {
+		return nil, fmt.Errorf("%w: exactly one of account_id and group_id is required", ErrShadowMirrorInvalid)
+	}
+	out := *cfg
+	return &out, nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeShadowMirrorConfig(t *testing.T) {
+	t.Parallel()
+
+	if cfg, err := NormalizeShadowMirrorConfig(&ShadowMirrorConfig{SampleRate: 0.5, AccountID: 1}); err != nil || cfg != nil {
+		t.Fatalf("disabled config should normalize to nil, got %+v, %v", cfg, err)
+	}
+
+	cfg, err := NormalizeShadowMirrorConfig(&ShadowMirrorConfig{Enabled: true, SampleRate: 0.1, GroupID: 3})
+	if err != nil || cfg == nil || cfg.GroupID != 3 {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+
+	invalid := []ShadowMirrorConfig{
+		{Enabled: true, SampleRate: 0, AccountID: 1},
+		{Enabled: true, SampleRate: 1.5, AccountID: 1},
+		{Enabled: true, SampleRate: 0.5},
+		{Enabled: true, SampleRate: 0.5, AccountID: 1, GroupID: 2},
+		{Enabled: true, SampleRate: 0.5, AccountID: -1},
+	}
+	for _, c := range invalid {
+		c := c
+		if _, err := NormalizeShadowMirrorConfig(&c); !errors.Is(err, ErrShadowMirrorInvalid) {
+			t.Errorf("NormalizeShadowMirrorConfig(%+v) error = %v, want ErrShadowMirrorInvalid", c, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyHandler manages named, scoped admin API keys
+type AdminKeyHandler struct {
+	adminKeyService *service.AdminKeyService
+}
+
+// NewAdminKeyHandler creates a new admin key handler
+func NewAdminKeyHandler(adminKeyService *service.AdminKeyService) *AdminKeyHandler {
+	return &AdminKeyHandler{adminKeyService: adminKeyService}
+}
+
+// AdminKeyRequest represents the request to create or update an admin API key
+type AdminKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	Scopes      []string   `json:"scopes" binding:"required"`
+	IPAllowlist []string   `json:"ip_allowlist"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// AdminKeySecretResponse is returned on create/rotate; the key is only shown once
+type AdminKeySecretResponse struct {
+	*service.AdminKey
+	Key string `json:"key"`
+}
+
+func (r *AdminKeyRequest) toInput() service.AdminKeyInput {
+	return service.AdminKeyInput{
+		Name:        r.Name,
+		Scopes:      r.Scopes,
+		IPAllowlist: r.IPAllowlist,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
+
+// List returns all admin API keys
+// GET /api/v1/admin/settings/admin-api-keys
+func (h *AdminKeyHandler) List(c *gin.Context) {
+	keys, err := h.adminKeyService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, keys)
+}
+
+// Create creates a new admin API key
+// POST /api/v1/admin/settings/admin-api-keys
+func (h *AdminKeyHandler) Create(c *gin.Context) {
+	var req AdminKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	key, plaintext, err := h.adminKeyService.Create(c.Request.Context(), req.toInput(), actorID(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, AdminKeySecretResponse{AdminKey: key, Key: plaintext})
+}
+
+// Update updates an admin API key's name, scopes, IP allowlist and expiry
+// PUT /api/v1/admin/settings/admin-api-keys/:id
+func (h *AdminKeyHandler) Update(c *gin.Context) {
+	id, ok := parseAdminKeyID(c)
+	if !ok {
+		return
+	}
+	var req AdminKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	key, err := h.adminKeyService.Update(c.Request.Context(), id, req.toInput())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, key)
+}
+
+// Rotate issues a new secret for an admin API key; the old secret stops working immediately
+// POST /api/v1/admin/settings/admin-api-keys/:id/rotate
+func (h *AdminKeyHandler) Rotate(c *gin.Context) {
+	id, ok := parseAdminKeyID(c)
+	if !ok {
+		return
+	}
+	key, plaintext, err := h.adminKeyService.Rotate(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, AdminKeySecretResponse{AdminKey: key, Key: plaintext})
+}
+
+// Revoke permanently disables an admin API key
+// POST /api/v1/admin/settings/admin-api-keys/:id/revoke
+func (h *AdminKeyHandler) Revoke(c *gin.Context) {
+	id, ok := parseAdminKeyID(c)
+	if !ok {
+		return
+	}
+	if err := h.adminKeyService.Revoke(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Admin API key revoked"})
+}
+
+func parseAdminKeyID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid admin API key ID")
+		return 0, false
+	}
+	return id, true
+}
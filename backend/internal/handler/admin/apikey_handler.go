@@ -1,6 +1,9 @@
 package admin
 
 import (
+	"context"
+	"errors"
+	"io"
 	"strconv"
 
 	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
@@ -12,13 +15,15 @@ import (
 
 // AdminAPIKeyHandler handles admin API key management
 type AdminAPIKeyHandler struct {
-	adminService service.AdminService
+	adminService  service.AdminService
+	apiKeyService *service.APIKeyService
 }
 
 // NewAdminAPIKeyHandler creates a new admin API key handler
-func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandler {
+func NewAdminAPIKeyHandler(adminService service.AdminService, apiKeyService *service.APIKeyService) *AdminAPIKeyHandler {
 	return &AdminAPIKeyHandler{
-		adminService: adminService,
+		adminService:  adminService,
+		apiKeyService: apiKeyService,
 	}
 }
 
@@ -61,3 +66,36 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 	response.Success(c, resp)
 }
+
+// AdminRotateAPIKeyRequest represents the request to rotate an API key
+type AdminRotateAPIKeyRequest struct {
+	GraceHours *int `json:"grace_hours"` // 旧 Key 宽限期（小时），不传使用默认值，0=立即失效
+}
+
+// Rotate handles rotating an API key on behalf of its owner
+// POST /api/v1/admin/api-keys/:id/rotate
+func (h *AdminAPIKeyHandler) Rotate(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+
+	var req AdminRotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	payload := struct {
+		KeyID int64 `json:"key_id"`
+		AdminRotateAPIKeyRequest
+	}{KeyID: keyID, AdminRotateAPIKeyRequest: req}
+	executeAdminIdempotentJSON(c, "admin.api_keys.rotate", payload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		key, err := h.apiKeyService.AdminRotate(ctx, keyID, service.RotateAPIKeyRequest{GraceHours: req.GraceHours})
+		if err != nil {
+			return nil, err
+		}
+		return dto.APIKeyFromService(key), nil
+	})
+}
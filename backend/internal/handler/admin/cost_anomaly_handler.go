@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CostAnomalyHandler handles admin cost anomaly baselines and suspended keys
+type CostAnomalyHandler struct {
+	anomalyService *service.CostAnomalyService
+	apiKeyService  *service.APIKeyService
+}
+
+// NewCostAnomalyHandler creates a new admin cost anomaly handler
+func NewCostAnomalyHandler(anomalyService *service.CostAnomalyService, apiKeyService *service.APIKeyService) *CostAnomalyHandler {
+	return &CostAnomalyHandler{
+		anomalyService: anomalyService,
+		apiKeyService:  apiKeyService,
+	}
+}
+
+// GetAPIKeyBaseline returns the learned hourly baseline of an API key
+// GET /api/v1/admin/api-keys/:id/cost-baseline
+func (h *CostAnomalyHandler) GetAPIKeyBaseline(c *gin.Context) {
+	h.getBaseline(c, service.CostAnomalySubjectAPIKey, "Invalid API key ID")
+}
+
+// GetUserBaseline returns the learned hourly baseline of a user
+// GET /api/v1/admin/users/:id/cost-baseline
+func (h *CostAnomalyHandler) GetUserBaseline(c *gin.Context) {
+	h.getBaseline(c, service.CostAnomalySubjectUser, "Invalid user ID")
+}
+
+func (h *CostAnomalyHandler) getBaseline(c *gin.Context, subject, invalidMsg string) {
+	if h.anomalyService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Cost anomaly service unavailable")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, invalidMsg)
+		return
+	}
+	view, err := h.anomalyService.GetBaseline(c.Request.Context(), subject, id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, view)
+}
+
+// Unsuspend restores an API key suspended by cost anomaly detection
+// POST /api/v1/admin/api-keys/:id/unsuspend
+func (h *CostAnomalyHandler) Unsuspend(c *gin.Context) {
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || keyID <= 0 {
+		response.BadRequest(c, "Invalid API key ID")
+		return
+	}
+	apiKey, err := h.apiKeyService.Unsuspend(c.Request.Context(), keyID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.APIKeyFromService(apiKey))
+}
@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExperimentHandler manages A/B model experiments
+type ExperimentHandler struct {
+	experimentService *service.ExperimentService
+}
+
+// NewExperimentHandler creates a new admin experiment handler
+func NewExperimentHandler(experimentService *service.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{experimentService: experimentService}
+}
+
+// ExperimentVariantRequest represents one experiment variant
+type ExperimentVariantRequest struct {
+	Name              string `json:"name" binding:"required"`
+	Percent           int    `json:"percent"`
+	Model             string `json:"model"`
+	GroupID           int64  `json:"group_id"`
+	SystemPromptPatch string `json:"system_prompt_patch"`
+}
+
+// SaveExperimentRequest represents create/update experiment request
+type SaveExperimentRequest struct {
+	Name         string                     `json:"name" binding:"required"`
+	Description  string                     `json:"description"`
+	Status       string                     `json:"status" binding:"omitempty,oneof=draft running stopped"`
+	ModelPattern string                     `json:"model_pattern" binding:"required"`
+	GroupIDs     []int64                    `json:"group_ids"`
+	StickyBy     string                     `json:"sticky_by" binding:"omitempty,oneof=user api_key"`
+	Variants     []ExperimentVariantRequest `json:"variants" binding:"required,min=2,dive"`
+}
+
+func (r *SaveExperimentRequest) toExperiment() *service.Experiment {
+	variants := make([]service.ExperimentVariant, 0, len(r.Variants))
+	for _, v := range r.Variants {
+		variants = append(variants, service.ExperimentVariant{
+			Name:              v.Name,
+			Percent:           v.Percent,
+			Model:             v.Model,
+			GroupID:           v.GroupID,
+			SystemPromptPatch: v.SystemPromptPatch,
+		})
+	}
+	return &service.Experiment{
+		Name:         r.Name,
+		Description:  r.Description,
+		Status:       r.Status,
+		ModelPattern: r.ModelPattern,
+		GroupIDs:     r.GroupIDs,
+		StickyBy:     r.StickyBy,
+		Variants:     variants,
+	}
+}
+
+func parseExperimentID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid experiment ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// List returns all experiments
+// GET /api/v1/admin/experiments
+func (h *ExperimentHandler) List(c *gin.Context) {
+	experiments, err := h.experimentService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, experiments)
+}
+
+// GetByID returns an experiment
+// GET /api/v1/admin/experiments/:id
+func (h *ExperimentHandler) GetByID(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+	experiment, err := h.experimentService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, experiment)
+}
+
+// Create creates an experiment
+// POST /api/v1/admin/experiments
+func (h *ExperimentHandler) Create(c *gin.Context) {
+	var req SaveExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	experiment, err := h.experimentService.Create(c.Request.Context(), req.toExperiment())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, experiment)
+}
+
+// Update replaces an experiment's configuration
+// PUT /api/v1/admin/experiments/:id
+func (h *ExperimentHandler) Update(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+	var req SaveExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	experiment, err := h.experimentService.Update(c.Request.Context(), id, req.toExperiment())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, experiment)
+}
+
+// Delete deletes an experiment (usage history keeps its tags)
+// DELETE /api/v1/admin/experiments/:id
+func (h *ExperimentHandler) Delete(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+	if err := h.experimentService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Experiment deleted successfully"})
+}
+
+// GetResults compares errors, latency, tokens and cost per variant
+// GET /api/v1/admin/experiments/:id/results?start_date=&end_date=&timezone=
+func (h *ExperimentHandler) GetResults(c *gin.Context) {
+	id, ok := parseExperimentID(c)
+	if !ok {
+		return
+	}
+	startTime, endTime := parseTimeRange(c)
+	results, err := h.experimentService.GetResults(c.Request.Context(), id, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, results)
+}
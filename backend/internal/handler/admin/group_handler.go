@@ -56,6 +56,29 @@ type CreateGroupRequest struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    string `json:"default_mapped_model"`
+	// 每个 API Key 每小时允许的不同来源 IP 数（0 不限制）
+	MaxIPsPerKeyPerHour int `json:"max_ips_per_key_per_hour" binding:"omitempty,min=0"`
+	// 分组内所有请求合计的每分钟请求数 / token 数上限（0 不限制）
+	RPMLimit int `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit int `json:"tpm_limit" binding:"omitempty,min=0"`
+	// 账号满载排队优先级（0-9，越大越优先）
+	QueuePriority int `json:"queue_priority" binding:"omitempty,min=0,max=9"`
+	// 账号调度策略（空为平台默认）
+	SchedulingStrategy string `json:"scheduling_strategy"`
+	// 模型降级链（无可用账号/429/529/上下文超长时按顺序降级）
+	ModelFallbackChains []service.ModelFallbackChain `json:"model_fallback_chains"`
+	// 跨平台故障转移（anthropic 与 antigravity 账号互为后备）
+	CrossPlatformFailover bool `json:"cross_platform_failover"`
+	// 影子流量镜像（采样复制请求到影子账号/分组，仅记录对比指标）
+	ShadowMirror *service.ShadowMirrorConfig `json:"shadow_mirror"`
+	// 网关响应缓存（确定性请求精确匹配回放，命中按比例计费）
+	ResponseCache *service.ResponseCacheConfig `json:"response_cache"`
+	// 自动 prompt 缓存断点（tools / system / 历史轮次）
+	AutoCacheBreakpoints bool `json:"auto_cache_breakpoints"`
+	// 流式断线续传（服务端短暂缓冲 SSE 事件）
+	StreamResume bool `json:"stream_resume"`
+	// 上下文策略（输入超限时拒绝、截断最早轮次或摘要）
+	ContextPolicy *service.ContextPolicyConfig `json:"context_policy"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -94,6 +117,29 @@ type UpdateGroupRequest struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch *bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    *string `json:"default_mapped_model"`
+	// 每个 API Key 每小时允许的不同来源 IP 数（0 不限制）
+	MaxIPsPerKeyPerHour *int `json:"max_ips_per_key_per_hour" binding:"omitempty,min=0"`
+	// 分组内所有请求合计的每分钟请求数 / token 数上限（0 不限制）
+	RPMLimit *int `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit *int `json:"tpm_limit" binding:"omitempty,min=0"`
+	// 账号满载排队优先级（0-9，越大越优先）
+	QueuePriority *int `json:"queue_priority" binding:"omitempty,min=0,max=9"`
+	// 账号调度策略（空字符串恢复平台默认）
+	SchedulingStrategy *string `json:"scheduling_strategy"`
+	// 模型降级链（nil 不修改，空数组清除）
+	ModelFallbackChains []service.ModelFallbackChain `json:"model_fallback_chains"`
+	// 跨平台故障转移（anthropic 与 antigravity 账号互为后备）
+	CrossPlatformFailover *bool `json:"cross_platform_failover"`
+	// 影子流量镜像（nil 不修改，enabled=false 关闭）
+	ShadowMirror *service.ShadowMirrorConfig `json:"shadow_mirror"`
+	// 网关响应缓存（nil 不修改，enabled=false 关闭）
+	ResponseCache *service.ResponseCacheConfig `json:"response_cache"`
+	// 自动 prompt 缓存断点（tools / system / 历史轮次）
+	AutoCacheBreakpoints *bool `json:"auto_cache_breakpoints"`
+	// 流式断线续传（服务端短暂缓冲 SSE 事件）
+	StreamResume *bool `json:"stream_resume"`
+	// 上下文策略（nil 不修改，enabled=false 关闭）
+	ContextPolicy *service.ContextPolicyConfig `json:"context_policy"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -211,6 +257,18 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		SoraStorageQuotaBytes:           req.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		MaxIPsPerKeyPerHour:             req.MaxIPsPerKeyPerHour,
+		RPMLimit:                        req.RPMLimit,
+		TPMLimit:                        req.TPMLimit,
+		QueuePriority:                   req.QueuePriority,
+		SchedulingStrategy:              req.SchedulingStrategy,
+		ModelFallbackChains:             req.ModelFallbackChains,
+		CrossPlatformFailover:           req.CrossPlatformFailover,
+		ShadowMirror:                    req.ShadowMirror,
+		ResponseCache:                   req.ResponseCache,
+		AutoCacheBreakpoints:            req.AutoCacheBreakpoints,
+		StreamResume:                    req.StreamResume,
+		ContextPolicy:                   req.ContextPolicy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -264,6 +322,18 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		SoraStorageQuotaBytes:           req.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           req.AllowMessagesDispatch,
 		DefaultMappedModel:              req.DefaultMappedModel,
+		MaxIPsPerKeyPerHour:             req.MaxIPsPerKeyPerHour,
+		RPMLimit:                        req.RPMLimit,
+		TPMLimit:                        req.TPMLimit,
+		QueuePriority:                   req.QueuePriority,
+		SchedulingStrategy:              req.SchedulingStrategy,
+		ModelFallbackChains:             req.ModelFallbackChains,
+		CrossPlatformFailover:           req.CrossPlatformFailover,
+		ShadowMirror:                    req.ShadowMirror,
+		ResponseCache:                   req.ResponseCache,
+		AutoCacheBreakpoints:            req.AutoCacheBreakpoints,
+		StreamResume:                    req.StreamResume,
+		ContextPolicy:                   req.ContextPolicy,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonationHandler lets support staff view the product as a user
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+}
+
+// NewImpersonationHandler creates a new admin impersonation handler
+func NewImpersonationHandler(impersonationService *service.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService}
+}
+
+// StartImpersonationRequest represents the request to impersonate a user
+type StartImpersonationRequest struct {
+	Reason          string `json:"reason" binding:"required,max=500"`
+	WriteAccess     bool   `json:"write_access"`
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1,max=120"`
+}
+
+// Start mints a short-lived impersonation token for a user
+// POST /api/v1/admin/users/:id/impersonate
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	userID, ok := parseSessionUserID(c)
+	if !ok {
+		return
+	}
+	var req StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	result, err := h.impersonationService.Start(c.Request.Context(), actorID(c), userID, service.ImpersonationInput{
+		Reason:          req.Reason,
+		WriteAccess:     req.WriteAccess,
+		DurationMinutes: req.DurationMinutes,
+		ClientIP:        ip.GetClientIP(c),
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// ListLogs returns the impersonation audit log
+// GET /api/v1/admin/users/impersonation-logs?user_id=&admin_id=&impersonation_id=
+func (h *ImpersonationHandler) ListLogs(c *gin.Context) {
+	filter := service.ImpersonationAuditFilter{ImpersonationID: c.Query("impersonation_id")}
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid user_id")
+			return
+		}
+		filter.TargetUserID = id
+	}
+	if v := c.Query("admin_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			response.BadRequest(c, "Invalid admin_id")
+			return
+		}
+		filter.AdminID = id
+	}
+
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	logs, result, err := h.impersonationService.ListAuditLogs(c.Request.Context(), filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, logs, result.Total, page, pageSize)
+}
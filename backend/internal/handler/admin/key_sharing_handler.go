@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeySharingHandler handles the admin review queue for shared/resold API keys
+type KeySharingHandler struct {
+	sharingService *service.KeySharingService
+}
+
+// NewKeySharingHandler creates a new admin key sharing handler
+func NewKeySharingHandler(sharingService *service.KeySharingService) *KeySharingHandler {
+	return &KeySharingHandler{sharingService: sharingService}
+}
+
+// ResolveKeySharingReviewRequest represents the request to resolve a review
+type ResolveKeySharingReviewRequest struct {
+	Action  string `json:"action" binding:"required,oneof=confirm dismiss"`
+	Suspend bool   `json:"suspend"`
+	Note    string `json:"note" binding:"max=1000"`
+}
+
+// List returns the key sharing review queue
+// GET /api/v1/admin/key-sharing/reviews
+func (h *KeySharingHandler) List(c *gin.Context) {
+	if h.sharingService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Key sharing service unavailable")
+		return
+	}
+	status := strings.TrimSpace(c.Query("status"))
+	switch status {
+	case "", service.KeySharingReviewPending, service.KeySharingReviewConfirmed, service.KeySharingReviewDismissed:
+	default:
+		response.BadRequest(c, "Invalid status")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	reviews, result, err := h.sharingService.ListReviews(c.Request.Context(), status, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, reviews, result.Total, page, pageSize)
+}
+
+// GetByID returns a single review with its evidence
+// GET /api/v1/admin/key-sharing/reviews/:id
+func (h *KeySharingHandler) GetByID(c *gin.Context) {
+	if h.sharingService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Key sharing service unavailable")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid review ID")
+		return
+	}
+	review, err := h.sharingService.GetReview(c.Request.Context(), id)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, review)
+}
+
+// Resolve confirms or dismisses a pending review
+// POST /api/v1/admin/key-sharing/reviews/:id/resolve
+func (h *KeySharingHandler) Resolve(c *gin.Context) {
+	if h.sharingService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Key sharing service unavailable")
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid review ID")
+		return
+	}
+	var req ResolveKeySharingReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	var adminID int64
+	if subject, ok := middleware2.GetAuthSubjectFromContext(c); ok {
+		adminID = subject.UserID
+	}
+	review, err := h.sharingService.ResolveReview(c.Request.Context(), id, service.KeySharingResolveInput{
+		Action:  req.Action,
+		Suspend: req.Suspend,
+		Note:    req.Note,
+	}, adminID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, review)
+}
@@ -156,6 +156,64 @@ func (h *OpsHandler) GetAccountAvailability(c *gin.Context) {
 	response.Success(c, payload)
 }
 
+// GetCrossPlatformFailoverStats returns how often groups failed over between anthropic and antigravity accounts.
+// GET /api/v1/admin/ops/cross-platform-failover
+func (h *OpsHandler) GetCrossPlatformFailoverStats(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	stats, err := h.opsService.GetCrossPlatformFailoverStats(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"items":     stats,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetStreamResumeStats returns buffer usage and resume hits of stream resumption on this instance.
+// GET /api/v1/admin/ops/stream-resume
+func (h *OpsHandler) GetStreamResumeStats(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	stats, err := h.opsService.GetStreamResumeStats(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"stats":     stats,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
+// GetAccountSchedulerMetrics returns account scheduler metrics of the gateways on this instance.
+// GET /api/v1/admin/ops/account-scheduler
+func (h *OpsHandler) GetAccountSchedulerMetrics(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	metrics, err := h.opsService.GetAccountSchedulerMetrics(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{
+		"gateway":   metrics.Gateway,
+		"openai":    metrics.OpenAI,
+		"timestamp": time.Now().UTC(),
+	})
+}
+
 func parseOpsRealtimeWindow(v string) (time.Duration, string, bool) {
 	switch strings.ToLower(strings.TrimSpace(v)) {
 	case "", "1min", "1m":
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartitionHandler handles admin time partition management
+type PartitionHandler struct {
+	partitionService *service.TimePartitionService
+}
+
+// NewPartitionHandler creates a new admin partition handler
+func NewPartitionHandler(partitionService *service.TimePartitionService) *PartitionHandler {
+	return &PartitionHandler{partitionService: partitionService}
+}
+
+// List returns partition status of all managed tables
+// GET /api/v1/admin/system/partitions
+func (h *PartitionHandler) List(c *gin.Context) {
+	if h.partitionService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Partition service unavailable")
+		return
+	}
+	statuses, err := h.partitionService.ListStatus(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, statuses)
+}
+
+// Convert starts an online conversion of a table to monthly partitions
+// POST /api/v1/admin/system/partitions/:table/convert
+func (h *PartitionHandler) Convert(c *gin.Context) {
+	if h.partitionService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Partition service unavailable")
+		return
+	}
+	conversion, err := h.partitionService.Convert(c.Request.Context(), c.Param("table"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, conversion)
+}
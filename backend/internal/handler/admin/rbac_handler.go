@@ -0,0 +1,195 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RBACHandler handles admin roles, permissions and role assignments
+type RBACHandler struct {
+	rbacService *service.AdminRBACService
+}
+
+// NewRBACHandler creates a new admin RBAC handler
+func NewRBACHandler(rbacService *service.AdminRBACService) *RBACHandler {
+	return &RBACHandler{rbacService: rbacService}
+}
+
+// AdminRoleRequest represents the request to create or update a custom role
+type AdminRoleRequest struct {
+	Key         string   `json:"key"`
+	Name        string   `json:"name" binding:"max=100"`
+	Description string   `json:"description" binding:"max=500"`
+	Permissions []string `json:"permissions"`
+}
+
+// AssignAdminRoleRequest represents the request to assign a role to a user
+type AssignAdminRoleRequest struct {
+	RoleKey string `json:"role_key" binding:"required"`
+}
+
+// GetMyAccess returns the effective admin permissions of the current caller
+// GET /api/v1/admin/rbac/me
+func (h *RBACHandler) GetMyAccess(c *gin.Context) {
+	access, ok := middleware2.GetAdminAccessFromContext(c)
+	if !ok {
+		response.Forbidden(c, "Admin access required")
+		return
+	}
+	response.Success(c, access)
+}
+
+// ListPermissions returns the permission catalog
+// GET /api/v1/admin/rbac/permissions
+func (h *RBACHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, service.AdminPermissionCatalog())
+}
+
+// ListRoles returns built-in and custom roles
+// GET /api/v1/admin/rbac/roles
+func (h *RBACHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, roles)
+}
+
+// CreateRole creates a custom role
+// POST /api/v1/admin/rbac/roles
+func (h *RBACHandler) CreateRole(c *gin.Context) {
+	var req AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	role, err := h.rbacService.CreateRole(c.Request.Context(), service.AdminRoleInput{
+		Key:         req.Key,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// UpdateRole updates a custom role
+// PUT /api/v1/admin/rbac/roles/:key
+func (h *RBACHandler) UpdateRole(c *gin.Context) {
+	var req AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	role, err := h.rbacService.UpdateRole(c.Request.Context(), c.Param("key"), service.AdminRoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, role)
+}
+
+// DeleteRole deletes a custom role that is no longer assigned
+// DELETE /api/v1/admin/rbac/roles/:key
+func (h *RBACHandler) DeleteRole(c *gin.Context) {
+	if err := h.rbacService.DeleteRole(c.Request.Context(), c.Param("key")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Role deleted successfully"})
+}
+
+// ListAssignments returns all role assignments
+// GET /api/v1/admin/rbac/assignments
+func (h *RBACHandler) ListAssignments(c *gin.Context) {
+	assignments, err := h.rbacService.ListAssignments(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, assignments)
+}
+
+// AssignRole assigns a role to a user, replacing any previous assignment
+// PUT /api/v1/admin/rbac/assignments/:user_id
+func (h *RBACHandler) AssignRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+	var req AssignAdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if err := h.rbacService.AssignRole(c.Request.Context(), actorID(c), userID, req.RoleKey); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"user_id": userID, "role_key": req.RoleKey})
+}
+
+// UnassignRole removes a user's role assignment
+// DELETE /api/v1/admin/rbac/assignments/:user_id
+func (h *RBACHandler) UnassignRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+	if err := h.rbacService.UnassignRole(c.Request.Context(), actorID(c), userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Role assignment removed"})
+}
+
+// GuardStaffTarget is a route middleware for /users/:id mutations: changing or
+// deleting another staff member's account (e.g. resetting their password) would
+// bypass role boundaries, so it additionally requires roles:manage.
+func (h *RBACHandler) GuardStaffTarget(c *gin.Context) {
+	access, ok := middleware2.GetAdminAccessFromContext(c)
+	if ok && access.Has(service.AdminPermRolesManage) {
+		c.Next()
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		c.Abort()
+		return
+	}
+	staff, err := h.rbacService.IsStaff(c.Request.Context(), userID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		c.Abort()
+		return
+	}
+	if staff {
+		response.Forbidden(c, "Missing admin permission: "+service.AdminPermRolesManage)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func actorID(c *gin.Context) int64 {
+	if subject, ok := middleware2.GetAuthSubjectFromContext(c); ok {
+		return subject.UserID
+	}
+	return 0
+}
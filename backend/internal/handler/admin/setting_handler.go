@@ -82,7 +82,10 @@ func (h *SettingHandler) GetSettings(c *gin.Context) {
 		PasswordResetEnabled:                 settings.PasswordResetEnabled,
 		InvitationCodeEnabled:                settings.InvitationCodeEnabled,
 		TotpEnabled:                          settings.TotpEnabled,
+		PasskeyEnabled:                       settings.PasskeyEnabled,
+		AdminRequire2FA:                      settings.AdminRequire2FA,
 		TotpEncryptionKeyConfigured:          h.settingService.IsTotpEncryptionKeyConfigured(),
+		WebAuthnConfigured:                   h.settingService.IsWebAuthnConfigured(),
 		SMTPHost:                             settings.SMTPHost,
 		SMTPPort:                             settings.SMTPPort,
 		SMTPUsername:                         settings.SMTPUsername,
@@ -137,7 +140,9 @@ type UpdateSettingsRequest struct {
 	PromoCodeEnabled                 bool     `json:"promo_code_enabled"`
 	PasswordResetEnabled             bool     `json:"password_reset_enabled"`
 	InvitationCodeEnabled            bool     `json:"invitation_code_enabled"`
-	TotpEnabled                      bool     `json:"totp_enabled"` // TOTP 双因素认证
+	TotpEnabled                      bool     `json:"totp_enabled"`      // TOTP 双因素认证
+	PasskeyEnabled                   bool     `json:"passkey_enabled"`   // WebAuthn Passkey 登录与 2FA
+	AdminRequire2FA                  bool     `json:"admin_require_2fa"` // 管理员必须启用第二因素
 
 	// 邮件服务设置
 	SMTPHost     string `json:"smtp_host"`
@@ -265,6 +270,17 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		}
 	}
 
+	// Passkey 参数验证：必须先在配置文件中配置 WebAuthn 依赖方
+	if req.PasskeyEnabled && !previousSettings.PasskeyEnabled && !h.settingService.IsWebAuthnConfigured() {
+		response.BadRequest(c, "Cannot enable passkeys: configure webauthn.rp_id and webauthn.rp_origins (or server.frontend_url) first.")
+		return
+	}
+	// 管理员第二因素策略至少需要一种可用的第二因素，否则管理员将无法满足策略
+	if req.AdminRequire2FA && !req.TotpEnabled && !req.PasskeyEnabled {
+		response.BadRequest(c, "Requiring admin 2FA needs TOTP or passkeys to be enabled")
+		return
+	}
+
 	// LinuxDo Connect 参数验证
 	if req.LinuxDoConnectEnabled {
 		req.LinuxDoConnectClientID = strings.TrimSpace(req.LinuxDoConnectClientID)
@@ -435,6 +451,8 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		PasswordResetEnabled:             req.PasswordResetEnabled,
 		InvitationCodeEnabled:            req.InvitationCodeEnabled,
 		TotpEnabled:                      req.TotpEnabled,
+		PasskeyEnabled:                   req.PasskeyEnabled,
+		AdminRequire2FA:                  req.AdminRequire2FA,
 		SMTPHost:                         req.SMTPHost,
 		SMTPPort:                         req.SMTPPort,
 		SMTPUsername:                     req.SMTPUsername,
@@ -528,7 +546,10 @@ func (h *SettingHandler) UpdateSettings(c *gin.Context) {
 		PasswordResetEnabled:                 updatedSettings.PasswordResetEnabled,
 		InvitationCodeEnabled:                updatedSettings.InvitationCodeEnabled,
 		TotpEnabled:                          updatedSettings.TotpEnabled,
+		PasskeyEnabled:                       updatedSettings.PasskeyEnabled,
+		AdminRequire2FA:                      updatedSettings.AdminRequire2FA,
 		TotpEncryptionKeyConfigured:          h.settingService.IsTotpEncryptionKeyConfigured(),
+		WebAuthnConfigured:                   h.settingService.IsWebAuthnConfigured(),
 		SMTPHost:                             updatedSettings.SMTPHost,
 		SMTPPort:                             updatedSettings.SMTPPort,
 		SMTPUsername:                         updatedSettings.SMTPUsername,
@@ -611,6 +632,12 @@ func diffSettings(before *service.SystemSettings, after *service.SystemSettings,
 	if before.TotpEnabled != after.TotpEnabled {
 		changed = append(changed, "totp_enabled")
 	}
+	if before.PasskeyEnabled != after.PasskeyEnabled {
+		changed = append(changed, "passkey_enabled")
+	}
+	if before.AdminRequire2FA != after.AdminRequire2FA {
+		changed = append(changed, "admin_require_2fa")
+	}
 	if before.SMTPHost != after.SMTPHost {
 		changed = append(changed, "smtp_host")
 	}
@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpsertOIDCProviderRequest 创建/更新 OIDC 提供方请求（client_secret 为空时保留原值）
+type UpsertOIDCProviderRequest struct {
+	Name                string                 `json:"name" binding:"required,max=64"`
+	Enabled             bool                   `json:"enabled"`
+	DiscoveryURL        string                 `json:"discovery_url"`
+	Issuer              string                 `json:"issuer"`
+	AuthorizeURL        string                 `json:"authorize_url"`
+	TokenURL            string                 `json:"token_url"`
+	UserInfoURL         string                 `json:"userinfo_url"`
+	JWKSURL             string                 `json:"jwks_url"`
+	ClientID            string                 `json:"client_id" binding:"required"`
+	ClientSecret        string                 `json:"client_secret"`
+	TokenAuthMethod     string                 `json:"token_auth_method" binding:"omitempty,oneof=client_secret_post client_secret_basic none"`
+	Scopes              string                 `json:"scopes"`
+	RedirectURL         string                 `json:"redirect_url" binding:"required"`
+	FrontendRedirectURL string                 `json:"frontend_redirect_url"`
+	SubjectClaim        string                 `json:"subject_claim"`
+	EmailClaim          string                 `json:"email_claim"`
+	EmailVerifiedClaim  string                 `json:"email_verified_claim"`
+	UsernameClaim       string                 `json:"username_claim"`
+	GroupsClaim         string                 `json:"groups_claim"`
+	TrustEmail          bool                   `json:"trust_email"`
+	AssumeEmailVerified bool                   `json:"assume_email_verified"`
+	GroupMappings       []dto.OIDCGroupMapping `json:"group_mappings"`
+}
+
+// ListOIDCProviders 获取 OIDC 登录提供方列表
+// GET /api/v1/admin/settings/oidc-providers
+func (h *SettingHandler) ListOIDCProviders(c *gin.Context) {
+	providers, err := h.settingService.ListOIDCProviders(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	items := make([]dto.OIDCProvider, 0, len(providers))
+	for i := range providers {
+		items = append(items, toOIDCProviderDTO(&providers[i]))
+	}
+	response.Success(c, items)
+}
+
+// UpsertOIDCProvider 创建或更新 OIDC 登录提供方
+// PUT /api/v1/admin/settings/oidc-providers/:key
+func (h *SettingHandler) UpsertOIDCProvider(c *gin.Context) {
+	var req UpsertOIDCProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	mappings := make([]service.OIDCGroupMapping, 0, len(req.GroupMappings))
+	for _, m := range req.GroupMappings {
+		subs := make([]service.DefaultSubscriptionSetting, 0, len(m.Subscriptions))
+		for _, sub := range m.Subscriptions {
+			if sub.GroupID <= 0 || sub.ValidityDays <= 0 {
+				continue
+			}
+			if sub.ValidityDays > service.MaxValidityDays {
+				sub.ValidityDays = service.MaxValidityDays
+			}
+			subs = append(subs, service.DefaultSubscriptionSetting{GroupID: sub.GroupID, ValidityDays: sub.ValidityDays})
+		}
+		mappings = append(mappings, service.OIDCGroupMapping{
+			IdPGroup:        m.IdPGroup,
+			AllowedGroupIDs: m.AllowedGroupIDs,
+			Subscriptions:   subs,
+		})
+	}
+
+	saved, err := h.settingService.UpsertOIDCProvider(c.Request.Context(), &service.OIDCProvider{
+		Key:                 c.Param("key"),
+		Name:                req.Name,
+		Enabled:             req.Enabled,
+		DiscoveryURL:        req.DiscoveryURL,
+		Issuer:              req.Issuer,
+		AuthorizeURL:        req.AuthorizeURL,
+		TokenURL:            req.TokenURL,
+		UserInfoURL:         req.UserInfoURL,
+		JWKSURL:             req.JWKSURL,
+		ClientID:            req.ClientID,
+		ClientSecret:        req.ClientSecret,
+		TokenAuthMethod:     req.TokenAuthMethod,
+		Scopes:              req.Scopes,
+		RedirectURL:         req.RedirectURL,
+		FrontendRedirectURL: req.FrontendRedirectURL,
+		SubjectClaim:        req.SubjectClaim,
+		EmailClaim:          req.EmailClaim,
+		EmailVerifiedClaim:  req.EmailVerifiedClaim,
+		UsernameClaim:       req.UsernameClaim,
+		GroupsClaim:         req.GroupsClaim,
+		TrustEmail:          req.TrustEmail,
+		AssumeEmailVerified: req.AssumeEmailVerified,
+		GroupMappings:       mappings,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, toOIDCProviderDTO(saved))
+}
+
+// DeleteOIDCProvider 删除 OIDC 登录提供方
+// DELETE /api/v1/admin/settings/oidc-providers/:key
+func (h *SettingHandler) DeleteOIDCProvider(c *gin.Context) {
+	if err := h.settingService.DeleteOIDCProvider(c.Request.Context(), c.Param("key")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "OIDC provider deleted successfully"})
+}
+
+func toOIDCProviderDTO(p *service.OIDCProvider) dto.OIDCProvider {
+	mappings := make([]dto.OIDCGroupMapping, 0, len(p.GroupMappings))
+	for _, m := range p.GroupMappings {
+		subs := make([]dto.DefaultSubscriptionSetting, 0, len(m.Subscriptions))
+		for _, sub := range m.Subscriptions {
+			subs = append(subs, dto.DefaultSubscriptionSetting{GroupID: sub.GroupID, ValidityDays: sub.ValidityDays})
+		}
+		allowed := m.AllowedGroupIDs
+		if allowed == nil {
+			allowed = []int64{}
+		}
+		mappings = append(mappings, dto.OIDCGroupMapping{
+			IdPGroup:        m.IdPGroup,
+			AllowedGroupIDs: allowed,
+			Subscriptions:   subs,
+		})
+	}
+	return dto.OIDCProvider{
+		Key:                    p.Key,
+		Name:                   p.Name,
+		Enabled:                p.Enabled,
+		DiscoveryURL:           p.DiscoveryURL,
+		Issuer:                 p.Issuer,
+		AuthorizeURL:           p.AuthorizeURL,
+		TokenURL:               p.TokenURL,
+		UserInfoURL:            p.UserInfoURL,
+		JWKSURL:                p.JWKSURL,
+		ClientID:               p.ClientID,
+		ClientSecretConfigured: p.ClientSecretConfigured,
+		TokenAuthMethod:        p.TokenAuthMethod,
+		Scopes:                 p.Scopes,
+		RedirectURL:            p.RedirectURL,
+		FrontendRedirectURL:    p.FrontendRedirectURL,
+		SubjectClaim:           p.SubjectClaim,
+		EmailClaim:             p.EmailClaim,
+		EmailVerifiedClaim:     p.EmailVerifiedClaim,
+		UsernameClaim:          p.UsernameClaim,
+		GroupsClaim:            p.GroupsClaim,
+		TrustEmail:             p.TrustEmail,
+		AssumeEmailVerified:    p.AssumeEmailVerified,
+		GroupMappings:          mappings,
+		UpdatedAt:              p.UpdatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShadowMirrorHandler exposes per-group shadow mirroring comparison results
+type ShadowMirrorHandler struct {
+	shadowMirrorService *service.ShadowMirrorService
+}
+
+// NewShadowMirrorHandler creates a new admin shadow mirror handler
+func NewShadowMirrorHandler(shadowMirrorService *service.ShadowMirrorService) *ShadowMirrorHandler {
+	return &ShadowMirrorHandler{shadowMirrorService: shadowMirrorService}
+}
+
+func parseShadowMirrorFilter(c *gin.Context) (service.ShadowMirrorFilter, bool) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || groupID <= 0 {
+		response.BadRequest(c, "Invalid group ID")
+		return service.ShadowMirrorFilter{}, false
+	}
+	startTime, endTime := parseTimeRange(c)
+	return service.ShadowMirrorFilter{GroupID: groupID, StartTime: &startTime, EndTime: &endTime}, true
+}
+
+// ListResults returns shadow mirroring comparison records of a group
+// GET /api/v1/admin/groups/:id/shadow-mirror/results?start_date=&end_date=
+func (h *ShadowMirrorHandler) ListResults(c *gin.Context) {
+	filter, ok := parseShadowMirrorFilter(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	results, result, err := h.shadowMirrorService.ListResults(c.Request.Context(), filter, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, results, result.Total, page, pageSize)
+}
+
+// GetSummary returns aggregated primary vs shadow metrics of a group
+// GET /api/v1/admin/groups/:id/shadow-mirror/summary?start_date=&end_date=
+func (h *ShadowMirrorHandler) GetSummary(c *gin.Context) {
+	filter, ok := parseShadowMirrorFilter(c)
+	if !ok {
+		return
+	}
+	summary, err := h.shadowMirrorService.GetSummary(c.Request.Context(), filter)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, summary)
+}
@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageArchiveHandler handles admin usage archive requests
+type UsageArchiveHandler struct {
+	archiveService *service.UsageArchiveService
+}
+
+// NewUsageArchiveHandler creates a new admin usage archive handler
+func NewUsageArchiveHandler(archiveService *service.UsageArchiveService) *UsageArchiveHandler {
+	return &UsageArchiveHandler{archiveService: archiveService}
+}
+
+// List handles listing usage log archives
+// GET /api/v1/admin/usage/archives
+func (h *UsageArchiveHandler) List(c *gin.Context) {
+	if h.archiveService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Usage archive service unavailable")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	archives, result, err := h.archiveService.ListArchives(c.Request.Context(), params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.UsageLogArchive, 0, len(archives))
+	for i := range archives {
+		out = append(out, *dto.UsageLogArchiveFromService(&archives[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// Rehydrate handles loading an archive back into the database for ad-hoc queries
+// POST /api/v1/admin/usage/archives/:id/rehydrate
+func (h *UsageArchiveHandler) Rehydrate(c *gin.Context) {
+	if h.archiveService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Usage archive service unavailable")
+		return
+	}
+	archiveID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || archiveID <= 0 {
+		response.BadRequest(c, "Invalid archive ID")
+		return
+	}
+	archive, err := h.archiveService.Rehydrate(c.Request.Context(), archiveID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UsageLogArchiveFromService(archive))
+}
+
+// EvictRehydration handles removing rehydrated rows of an archive
+// DELETE /api/v1/admin/usage/archives/:id/rehydrate
+func (h *UsageArchiveHandler) EvictRehydration(c *gin.Context) {
+	if h.archiveService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Usage archive service unavailable")
+		return
+	}
+	archiveID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || archiveID <= 0 {
+		response.BadRequest(c, "Invalid archive ID")
+		return
+	}
+	if err := h.archiveService.EvictRehydration(c.Request.Context(), archiveID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": archiveID})
+}
+
+// ListLogs handles querying rehydrated usage logs
+// GET /api/v1/admin/usage/archives/logs
+func (h *UsageArchiveHandler) ListLogs(c *gin.Context) {
+	if h.archiveService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Usage archive service unavailable")
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+
+	var filters service.UsageArchiveLogFilters
+	for _, item := range []struct {
+		name   string
+		target **int64
+	}{
+		{"user_id", &filters.UserID},
+		{"api_key_id", &filters.APIKeyID},
+		{"archive_id", &filters.ArchiveID},
+	} {
+		raw := c.Query(item.name)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid "+item.name)
+			return
+		}
+		*item.target = &id
+	}
+
+	startTime, endTime, ok := parseUsageArchiveDateRange(c, false)
+	if !ok {
+		return
+	}
+	filters.StartTime = startTime
+	filters.EndTime = endTime
+
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	rows, result, err := h.archiveService.ListRehydratedLogs(c.Request.Context(), filters, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Paginated(c, rows, result.Total, page, pageSize)
+}
+
+// Statement handles generating a user statement spanning live and rehydrated usage
+// GET /api/v1/admin/usage/archives/statement
+func (h *UsageArchiveHandler) Statement(c *gin.Context) {
+	if h.archiveService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Usage archive service unavailable")
+		return
+	}
+	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user_id")
+		return
+	}
+	startTime, endTime, ok := parseUsageArchiveDateRange(c, true)
+	if !ok {
+		return
+	}
+	statement, err := h.archiveService.GetUserStatement(c.Request.Context(), userID, startTime, endTime)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, statement)
+}
+
+// parseUsageArchiveDateRange 解析 start_date/end_date（YYYY-MM-DD，按用户时区），返回左闭右开区间
+func parseUsageArchiveDateRange(c *gin.Context, required bool) (time.Time, time.Time, bool) {
+	userTZ := c.Query("timezone")
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+	if required && (startDateStr == "" || endDateStr == "") {
+		response.BadRequest(c, "start_date and end_date are required")
+		return time.Time{}, time.Time{}, false
+	}
+
+	var startTime, endTime time.Time
+	if startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		startTime = t
+	}
+	if endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return time.Time{}, time.Time{}, false
+		}
+		endTime = t.AddDate(0, 0, 1)
+	}
+	return startTime, endTime, true
+}
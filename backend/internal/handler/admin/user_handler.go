@@ -42,6 +42,9 @@ type CreateUserRequest struct {
 	Concurrency           int     `json:"concurrency"`
 	AllowedGroups         []int64 `json:"allowed_groups"`
 	SoraStorageQuotaBytes int64   `json:"sora_storage_quota_bytes"`
+	RPMLimit              int     `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit              int     `json:"tpm_limit" binding:"omitempty,min=0"`
+	QueuePriority         int     `json:"queue_priority" binding:"omitempty,min=0,max=9"`
 }
 
 // UpdateUserRequest represents admin update user request
@@ -59,6 +62,9 @@ type UpdateUserRequest struct {
 	// map[groupID]*rate，nil 表示删除该分组的专属倍率
 	GroupRates            map[int64]*float64 `json:"group_rates"`
 	SoraStorageQuotaBytes *int64             `json:"sora_storage_quota_bytes"`
+	RPMLimit              *int               `json:"rpm_limit" binding:"omitempty,min=0"`
+	TPMLimit              *int               `json:"tpm_limit" binding:"omitempty,min=0"`
+	QueuePriority         *int               `json:"queue_priority" binding:"omitempty,min=0,max=9"`
 }
 
 // UpdateBalanceRequest represents balance update request
@@ -188,6 +194,9 @@ func (h *UserHandler) Create(c *gin.Context) {
 		Concurrency:           req.Concurrency,
 		AllowedGroups:         req.AllowedGroups,
 		SoraStorageQuotaBytes: req.SoraStorageQuotaBytes,
+		RPMLimit:              req.RPMLimit,
+		TPMLimit:              req.TPMLimit,
+		QueuePriority:         req.QueuePriority,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
@@ -224,6 +233,9 @@ func (h *UserHandler) Update(c *gin.Context) {
 		AllowedGroups:         req.AllowedGroups,
 		GroupRates:            req.GroupRates,
 		SoraStorageQuotaBytes: req.SoraStorageQuotaBytes,
+		RPMLimit:              req.RPMLimit,
+		TPMLimit:              req.TPMLimit,
+		QueuePriority:         req.QueuePriority,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
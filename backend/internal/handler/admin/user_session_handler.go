@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserSessionHandler manages login sessions of any user
+type UserSessionHandler struct {
+	authService *service.AuthService
+}
+
+// NewUserSessionHandler creates a new admin user session handler
+func NewUserSessionHandler(authService *service.AuthService) *UserSessionHandler {
+	return &UserSessionHandler{authService: authService}
+}
+
+func parseSessionUserID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return 0, false
+	}
+	return userID, true
+}
+
+// List returns the active login sessions of a user
+// GET /api/v1/admin/users/:id/sessions
+func (h *UserSessionHandler) List(c *gin.Context) {
+	userID, ok := parseSessionUserID(c)
+	if !ok {
+		return
+	}
+	sessions, err := h.authService.ListUserSessions(c.Request.Context(), userID, "")
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserSessionsFromService(sessions))
+}
+
+// Revoke revokes a single login session of a user
+// DELETE /api/v1/admin/users/:id/sessions/:session_id
+func (h *UserSessionHandler) Revoke(c *gin.Context) {
+	userID, ok := parseSessionUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.authService.RevokeUserSession(c.Request.Context(), userID, c.Param("session_id")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAll revokes all login sessions of a user
+// POST /api/v1/admin/users/:id/sessions/revoke-all
+func (h *UserSessionHandler) RevokeAll(c *gin.Context) {
+	userID, ok := parseSessionUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.authService.RevokeAllUserSessions(c.Request.Context(), userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "All sessions revoked"})
+}
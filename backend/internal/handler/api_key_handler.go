@@ -3,6 +3,8 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -42,6 +44,14 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h *float64 `json:"rate_limit_5h"`
 	RateLimit1d *float64 `json:"rate_limit_1d"`
 	RateLimit7d *float64 `json:"rate_limit_7d"`
+
+	// 访问策略（模型/端点/平台白名单与请求上限）
+	Policy *service.APIKeyPolicy `json:"policy"`
+}
+
+// RotateAPIKeyRequest represents the rotate API key request payload
+type RotateAPIKeyRequest struct {
+	GraceHours *int `json:"grace_hours"` // 旧 Key 宽限期（小时），不传使用默认值，0=立即失效
 }
 
 // UpdateAPIKeyRequest represents the update API key request payload
@@ -60,6 +70,9 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // 重置限速用量
+
+	// 访问策略（不传不修改，空对象清除策略）
+	Policy *service.APIKeyPolicy `json:"policy"`
 }
 
 // List handles listing user's API keys with pagination
@@ -155,6 +168,7 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 		IPWhitelist:   req.IPWhitelist,
 		IPBlacklist:   req.IPBlacklist,
 		ExpiresInDays: req.ExpiresInDays,
+		Policy:        req.Policy,
 	}
 	if req.Quota != nil {
 		svcReq.Quota = *req.Quota
@@ -208,6 +222,7 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 		RateLimit1d:         req.RateLimit1d,
 		RateLimit7d:         req.RateLimit7d,
 		ResetRateLimitUsage: req.ResetRateLimitUsage,
+		Policy:              req.Policy,
 	}
 	if req.Name != "" {
 		svcReq.Name = &req.Name
@@ -241,6 +256,40 @@ func (h *APIKeyHandler) Update(c *gin.Context) {
 	response.Success(c, dto.APIKeyFromService(key))
 }
 
+// Rotate handles rotating an API key (new secret, old one stays valid during the grace period)
+// POST /api/v1/api-keys/:id/rotate
+func (h *APIKeyHandler) Rotate(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid key ID")
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	payload := struct {
+		KeyID int64 `json:"key_id"`
+		RotateAPIKeyRequest
+	}{KeyID: keyID, RotateAPIKeyRequest: req}
+	executeUserIdempotentJSON(c, "user.api_keys.rotate", payload, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
+		key, err := h.apiKeyService.Rotate(ctx, keyID, subject.UserID, service.RotateAPIKeyRequest{GraceHours: req.GraceHours})
+		if err != nil {
+			return nil, err
+		}
+		return dto.APIKeyFromService(key), nil
+	})
+}
+
 // Delete handles deleting an API key
 // DELETE /api/v1/api-keys/:id
 func (h *APIKeyHandler) Delete(c *gin.Context) {
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
@@ -16,25 +21,29 @@ import (
 
 // AuthHandler handles authentication-related requests
 type AuthHandler struct {
-	cfg           *config.Config
-	authService   *service.AuthService
-	userService   *service.UserService
-	settingSvc    *service.SettingService
-	promoService  *service.PromoService
-	redeemService *service.RedeemService
-	totpService   *service.TotpService
+	cfg            *config.Config
+	authService    *service.AuthService
+	userService    *service.UserService
+	settingSvc     *service.SettingService
+	promoService   *service.PromoService
+	redeemService  *service.RedeemService
+	totpService    *service.TotpService
+	passkeyService *service.PasskeyService
+	impersonation  *service.ImpersonationService
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(cfg *config.Config, authService *service.AuthService, userService *service.UserService, settingService *service.SettingService, promoService *service.PromoService, redeemService *service.RedeemService, totpService *service.TotpService) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, authService *service.AuthService, userService *service.UserService, settingService *service.SettingService, promoService *service.PromoService, redeemService *service.RedeemService, totpService *service.TotpService, passkeyService *service.PasskeyService, impersonationService *service.ImpersonationService) *AuthHandler {
 	return &AuthHandler{
-		cfg:           cfg,
-		authService:   authService,
-		userService:   userService,
-		settingSvc:    settingService,
-		promoService:  promoService,
-		redeemService: redeemService,
-		totpService:   totpService,
+		cfg:            cfg,
+		authService:    authService,
+		userService:    userService,
+		settingSvc:     settingService,
+		promoService:   promoService,
+		redeemService:  redeemService,
+		totpService:    totpService,
+		passkeyService: passkeyService,
+		impersonation:  impersonationService,
 	}
 }
 
@@ -79,7 +88,7 @@ type AuthResponse struct {
 // respondWithTokenPair 生成 Token 对并返回认证响应
 // 如果 Token 对生成失败，回退到只返回 Access Token（向后兼容）
 func (h *AuthHandler) respondWithTokenPair(c *gin.Context, user *service.User) {
-	tokenPair, err := h.authService.GenerateTokenPair(c.Request.Context(), user, "")
+	tokenPair, err := h.authService.GenerateTokenPair(sessionClientContext(c), user, "")
 	if err != nil {
 		slog.Error("failed to generate token pair", "error", err, "user_id", user.ID)
 		// 回退到只返回Access Token
@@ -177,20 +186,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 	_ = token // token 由 authService.Login 返回但此处由 respondWithTokenPair 重新生成
 
-	// Check if TOTP 2FA is enabled for this user
-	if h.totpService != nil && h.settingSvc.IsTotpEnabled(c.Request.Context()) && user.TotpEnabled {
-		// Create a temporary login session for 2FA
-		tempToken, err := h.totpService.CreateLoginSession(c.Request.Context(), user.ID, user.Email)
-		if err != nil {
-			response.InternalError(c, "Failed to create 2FA session")
-			return
-		}
-
-		response.Success(c, TotpLoginResponse{
-			Requires2FA:     true,
-			TempToken:       tempToken,
-			UserEmailMasked: service.MaskEmail(user.Email),
-		})
+	// Check if a second factor (TOTP or passkey) is enabled for this user
+	challenge, err := h.secondFactorChallenge(c.Request.Context(), user)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if challenge != nil {
+		response.Success(c, challenge)
 		return
 	}
 
@@ -199,15 +202,78 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // TotpLoginResponse represents the response when 2FA is required
 type TotpLoginResponse struct {
-	Requires2FA     bool   `json:"requires_2fa"`
-	TempToken       string `json:"temp_token,omitempty"`
-	UserEmailMasked string `json:"user_email_masked,omitempty"`
+	Requires2FA      bool   `json:"requires_2fa"`
+	TempToken        string `json:"temp_token,omitempty"`
+	UserEmailMasked  string `json:"user_email_masked,omitempty"`
+	TotpAvailable    bool   `json:"totp_available"`
+	PasskeyAvailable bool   `json:"passkey_available"`
+}
+
+// secondFactorChallenge 用户启用了第二因素（TOTP 或 Passkey）时创建临时登录会话并返回 2FA 挑战，未启用时返回 nil。
+// 密码登录与第三方登录共用，任何登录方式都不能绕过第二因素。
+func (h *AuthHandler) secondFactorChallenge(ctx context.Context, user *service.User) (*TotpLoginResponse, error) {
+	if h.totpService == nil {
+		return nil, nil
+	}
+	totpAvailable := h.settingSvc.IsTotpEnabled(ctx) && user.TotpEnabled
+	passkeyAvailable := false
+	if h.passkeyService != nil {
+		var err error
+		passkeyAvailable, err = h.passkeyService.HasUsablePasskey(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !totpAvailable && !passkeyAvailable {
+		return nil, nil
+	}
+	// Create a temporary login session for 2FA
+	tempToken, err := h.totpService.CreateLoginSession(ctx, user.ID, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("create 2fa session: %w", err)
+	}
+	return &TotpLoginResponse{
+		Requires2FA:      true,
+		TempToken:        tempToken,
+		UserEmailMasked:  service.MaskEmail(user.Email),
+		TotpAvailable:    totpAvailable,
+		PasskeyAvailable: passkeyAvailable,
+	}, nil
+}
+
+// completeOAuthLogin 第三方登录回调的最后一步，结果写入重定向 fragment：
+// 用户启用了第二因素时回传临时会话，由前端调用 /auth/login/2fa 完成登录；否则直接签发 Token 对。
+func (h *AuthHandler) completeOAuthLogin(c *gin.Context, user *service.User, fragment url.Values) error {
+	challenge, err := h.secondFactorChallenge(c.Request.Context(), user)
+	if err != nil {
+		return err
+	}
+	if challenge != nil {
+		fragment.Set("requires_2fa", "true")
+		fragment.Set("temp_token", challenge.TempToken)
+		fragment.Set("user_email_masked", challenge.UserEmailMasked)
+		fragment.Set("totp_available", strconv.FormatBool(challenge.TotpAvailable))
+		fragment.Set("passkey_available", strconv.FormatBool(challenge.PasskeyAvailable))
+		return nil
+	}
+	tokenPair, err := h.authService.GenerateTokenPair(sessionClientContext(c), user, "")
+	if err != nil {
+		return err
+	}
+	fragment.Set("access_token", tokenPair.AccessToken)
+	fragment.Set("refresh_token", tokenPair.RefreshToken)
+	fragment.Set("expires_in", fmt.Sprintf("%d", tokenPair.ExpiresIn))
+	fragment.Set("token_type", "Bearer")
+	return nil
 }
 
 // Login2FARequest represents the 2FA login request
+// Either totp_code or passkey_ceremony_token + passkey_credential must be provided.
 type Login2FARequest struct {
-	TempToken string `json:"temp_token" binding:"required"`
-	TotpCode  string `json:"totp_code" binding:"required,len=6"`
+	TempToken            string          `json:"temp_token" binding:"required"`
+	TotpCode             string          `json:"totp_code" binding:"omitempty,len=6"`
+	PasskeyCeremonyToken string          `json:"passkey_ceremony_token"`
+	PasskeyCredential    json.RawMessage `json:"passkey_credential"`
 }
 
 // Login2FA completes the login with 2FA verification
@@ -241,12 +307,22 @@ func (h *AuthHandler) Login2FA(c *gin.Context) {
 		"user_id", session.UserID,
 		"email", session.Email)
 
-	// Verify the TOTP code
-	if err := h.totpService.VerifyCode(c.Request.Context(), session.UserID, req.TotpCode); err != nil {
+	// Verify the TOTP code or the passkey assertion
+	var verifyErr error
+	switch {
+	case req.TotpCode != "":
+		verifyErr = h.totpService.VerifyCode(c.Request.Context(), session.UserID, req.TotpCode)
+	case len(req.PasskeyCredential) > 0 && h.passkeyService != nil:
+		verifyErr = h.passkeyService.VerifySecondFactor(c.Request.Context(), session.UserID, req.PasskeyCeremonyToken, req.PasskeyCredential)
+	default:
+		response.BadRequest(c, "totp_code or passkey_credential is required")
+		return
+	}
+	if verifyErr != nil {
 		slog.Debug("login_2fa_verify_failed",
 			"user_id", session.UserID,
-			"error", err)
-		response.ErrorFrom(c, err)
+			"error", verifyErr)
+		response.ErrorFrom(c, verifyErr)
 		return
 	}
 
@@ -281,6 +357,8 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	type UserResponse struct {
 		*dto.User
 		RunMode string `json:"run_mode"`
+		// Impersonation 非空表示当前为管理员模拟登录，前端据此显示横幅
+		Impersonation *service.ImpersonationSession `json:"impersonation,omitempty"`
 	}
 
 	runMode := config.RunModeStandard
@@ -288,7 +366,11 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 		runMode = h.cfg.RunMode
 	}
 
-	response.Success(c, UserResponse{User: dto.UserFromService(user), RunMode: runMode})
+	response.Success(c, UserResponse{
+		User:          dto.UserFromService(user),
+		RunMode:       runMode,
+		Impersonation: middleware2.GetImpersonationFromContext(c),
+	})
 }
 
 // ValidatePromoCodeRequest 验证优惠码请求
@@ -522,7 +604,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokenPair, err := h.authService.RefreshTokenPair(c.Request.Context(), req.RefreshToken)
+	tokenPair, err := h.authService.RefreshTokenPair(sessionClientContext(c), req.RefreshToken)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
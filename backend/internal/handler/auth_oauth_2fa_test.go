@@ -0,0 +1,57 @@
+//go:build unit
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type loginSessionTotpCacheStub struct {
+	service.TotpCache
+	sessions map[string]*service.TotpLoginSession
+}
+
+func (s *loginSessionTotpCacheStub) SetLoginSession(_ context.Context, tempToken string, session *service.TotpLoginSession, _ time.Duration) error {
+	s.sessions[tempToken] = session
+	return nil
+}
+
+func TestCompleteOAuthLogin_EnrolledUserMustPassSecondFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/linuxdo/callback", nil)
+
+	settingService := service.NewSettingService(newStubSettingRepoForHandler(map[string]string{
+		service.SettingKeyTotpEnabled: "true",
+	}), &config.Config{})
+	cache := &loginSessionTotpCacheStub{sessions: map[string]*service.TotpLoginSession{}}
+	h := &AuthHandler{
+		settingSvc:  settingService,
+		totpService: service.NewTotpService(nil, nil, cache, settingService, nil, nil),
+	}
+	user := &service.User{ID: 42, Email: "admin@example.com", Role: service.RoleAdmin, TotpEnabled: true}
+
+	fragment := url.Values{}
+	require.NoError(t, h.completeOAuthLogin(c, user, fragment))
+	require.Equal(t, "true", fragment.Get("requires_2fa"))
+	require.Equal(t, "true", fragment.Get("totp_available"))
+	require.Empty(t, fragment.Get("access_token"), "启用第二因素的用户不直接签发 Token")
+
+	session := cache.sessions[fragment.Get("temp_token")]
+	require.NotNil(t, session)
+	require.Equal(t, int64(42), session.UserID)
+
+	challenge, err := h.secondFactorChallenge(c.Request.Context(), &service.User{ID: 43, Email: "user@example.com"})
+	require.NoError(t, err)
+	require.Nil(t, challenge, "未启用第二因素时无需 2FA")
+}
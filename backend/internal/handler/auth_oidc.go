@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcCookiePath        = "/api/v1/auth/oauth/oidc"
+	oidcStateCookieName   = "oidc_oauth_state"
+	oidcCookieMaxAgeSec   = 10 * 60 // 10 minutes
+	oidcDefaultRedirectTo = "/dashboard"
+	oidcDefaultLinkTo     = "/profile"
+	oidcDefaultFrontendCB = "/auth/oidc/callback"
+)
+
+// OIDCHandler handles generic OIDC/OAuth2 single sign-on and identity linking
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	// authHandler 登录完成后签发 Token 或要求第二因素（与密码登录一致）
+	authHandler *AuthHandler
+}
+
+// NewOIDCHandler creates a new OIDCHandler
+func NewOIDCHandler(oidcService *service.OIDCService, authHandler *AuthHandler) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService, authHandler: authHandler}
+}
+
+// OIDCLinkRequest represents the request to start linking an external identity
+type OIDCLinkRequest struct {
+	Redirect string `json:"redirect"`
+}
+
+// Start 启动 OIDC 登录流程。
+// GET /api/v1/auth/oauth/oidc/:provider/start?redirect=/dashboard
+func (h *OIDCHandler) Start(c *gin.Context) {
+	redirectTo := sanitizeFrontendRedirectPath(c.Query("redirect"))
+	if redirectTo == "" {
+		redirectTo = oidcDefaultRedirectTo
+	}
+	authURL, state, err := h.oidcService.BeginAuth(c.Request.Context(), c.Param("provider"), redirectTo, 0)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	setOIDCStateCookie(c, state)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Link 已登录用户发起外部身份绑定，返回授权地址由前端跳转。
+// POST /api/v1/user/oidc/:provider/link
+func (h *OIDCHandler) Link(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	var req OIDCLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	redirectTo := sanitizeFrontendRedirectPath(req.Redirect)
+	if redirectTo == "" {
+		redirectTo = oidcDefaultLinkTo
+	}
+	authURL, state, err := h.oidcService.BeginAuth(c.Request.Context(), c.Param("provider"), redirectTo, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	setOIDCStateCookie(c, state)
+	response.Success(c, gin.H{"authorize_url": authURL})
+}
+
+// Callback 处理 IdP 回调：登录/注册或完成绑定，然后重定向到前端。
+// GET /api/v1/auth/oauth/oidc/:provider/callback?code=...&state=...
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	providerKey := c.Param("provider")
+	frontendCallback := oidcDefaultFrontendCB
+	if provider, err := h.oidcService.GetProvider(c.Request.Context(), providerKey); err == nil && provider.FrontendRedirectURL != "" {
+		frontendCallback = provider.FrontendRedirectURL
+	}
+
+	// state cookie 一次性使用；须在重定向写出响应头之前清除
+	clearOIDCStateCookie(c)
+
+	if providerErr := strings.TrimSpace(c.Query("error")); providerErr != "" {
+		redirectOAuthError(c, frontendCallback, "provider_error", providerErr, c.Query("error_description"))
+		return
+	}
+	code := strings.TrimSpace(c.Query("code"))
+	state := strings.TrimSpace(c.Query("state"))
+	if code == "" || state == "" {
+		redirectOAuthError(c, frontendCallback, "missing_params", "missing code/state", "")
+		return
+	}
+	// state 须与发起授权的浏览器一致，防止登录 CSRF
+	expectedState, err := readCookieDecoded(c, oidcStateCookieName)
+	if err != nil || expectedState == "" || state != expectedState {
+		redirectOAuthError(c, frontendCallback, "invalid_state", "invalid oauth state", "")
+		return
+	}
+
+	result, err := h.oidcService.CompleteAuth(sessionClientContext(c), providerKey, state, code)
+	if err != nil {
+		redirectOAuthError(c, frontendCallback, "login_failed", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+
+	redirectTo := sanitizeFrontendRedirectPath(result.RedirectTo)
+	if redirectTo == "" {
+		redirectTo = oidcDefaultRedirectTo
+	}
+	fragment := url.Values{}
+	fragment.Set("provider", result.Provider)
+	fragment.Set("redirect", redirectTo)
+	if result.Linked {
+		fragment.Set("linked", "true")
+		redirectWithFragment(c, frontendCallback, fragment)
+		return
+	}
+	if err := h.authHandler.completeOAuthLogin(c, result.User, fragment); err != nil {
+		redirectOAuthError(c, frontendCallback, "login_failed", infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+	redirectWithFragment(c, frontendCallback, fragment)
+}
+
+// ListIdentities 列出当前用户已绑定的外部身份
+// GET /api/v1/user/identities
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	identities, err := h.oidcService.ListIdentities(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, identities)
+}
+
+// Unlink 解除当前用户与指定提供方的绑定
+// DELETE /api/v1/user/identities/:provider
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if err := h.oidcService.Unlink(c.Request.Context(), subject.UserID, c.Param("provider")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Identity unlinked successfully"})
+}
+
+func setOIDCStateCookie(c *gin.Context, state string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    encodeCookieValue(state),
+		Path:     oidcCookiePath,
+		MaxAge:   oidcCookieMaxAgeSec,
+		HttpOnly: true,
+		Secure:   isRequestHTTPS(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearOIDCStateCookie(c *gin.Context) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isRequestHTTPS(c),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
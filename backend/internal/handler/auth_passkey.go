@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasskeyLoginOptions 发起无密码 Passkey 登录，返回 navigator.credentials.get() 选项。
+// POST /api/v1/auth/passkey/login/options
+func (h *AuthHandler) PasskeyLoginOptions(c *gin.Context) {
+	result, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// PasskeyLoginRequest represents the passwordless passkey login request
+type PasskeyLoginRequest struct {
+	CeremonyToken string          `json:"ceremony_token" binding:"required"`
+	Credential    json.RawMessage `json:"credential" binding:"required"`
+}
+
+// PasskeyLogin 校验 Passkey 断言并签发令牌（要求用户验证，无需再走 2FA）。
+// POST /api/v1/auth/passkey/login
+func (h *AuthHandler) PasskeyLogin(c *gin.Context) {
+	var req PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	user, err := h.passkeyService.FinishLogin(c.Request.Context(), req.CeremonyToken, req.Credential)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	h.respondWithTokenPair(c, user)
+}
+
+// Login2FAPasskeyOptionsRequest represents the request for 2FA passkey options
+type Login2FAPasskeyOptionsRequest struct {
+	TempToken string `json:"temp_token" binding:"required"`
+}
+
+// Login2FAPasskeyOptions 在密码登录后的 2FA 步骤中发起 Passkey 认证。
+// 断言结果随 /auth/login/2fa 的 passkey_credential 字段提交。
+// POST /api/v1/auth/login/2fa/passkey/options
+func (h *AuthHandler) Login2FAPasskeyOptions(c *gin.Context) {
+	var req Login2FAPasskeyOptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	session, err := h.totpService.GetLoginSession(c.Request.Context(), req.TempToken)
+	if err != nil || session == nil {
+		response.BadRequest(c, "Invalid or expired 2FA session")
+		return
+	}
+
+	result, err := h.passkeyService.BeginSecondFactor(c.Request.Context(), session.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
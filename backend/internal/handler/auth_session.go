@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionClientContext 附带客户端 IP 与 User-Agent，签发 Token 时据此记录会话设备
+func sessionClientContext(c *gin.Context) context.Context {
+	return service.WithSessionClientInfo(c.Request.Context(), ip.GetClientIP(c), c.Request.UserAgent())
+}
+
+// ListSessions 列出当前用户的登录会话
+// GET /api/v1/user/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.authService.ListUserSessions(c.Request.Context(), subject.UserID, middleware2.GetSessionIDFromContext(c))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserSessionsFromService(sessions))
+}
+
+// RevokeSession 撤销当前用户的单个登录会话
+// DELETE /api/v1/user/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.authService.RevokeUserSession(c.Request.Context(), subject.UserID, c.Param("id")); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Session revoked"})
+}
+
+// EndImpersonation 结束当前的管理员模拟登录，模拟 Token 随即失效
+// POST /api/v1/auth/impersonation/end
+func (h *AuthHandler) EndImpersonation(c *gin.Context) {
+	if err := h.impersonation.End(c.Request.Context(), middleware2.GetImpersonationFromContext(c), ip.GetClientIP(c)); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Impersonation ended"})
+}
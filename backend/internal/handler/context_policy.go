@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// applyContextPolicy 按分组上下文策略处理输入超限的请求。
+// 需要拒绝时写入 400 错误并返回 false；截断或摘要后改写 body 与 parsedReq，返回处理结果供使用日志标记。
+// 摘要请求产生的用量在此单独记录。
+func (h *GatewayHandler) applyContextPolicy(c *gin.Context, apiKey *service.APIKey, subscription *service.UserSubscription, body *[]byte, parsedReq **service.ParsedRequest, reqLog *zap.Logger) (*service.ContextPolicyOutcome, bool) {
+	outcome, err := h.contextPolicyService.Apply(c.Request.Context(), apiKey.Group, *body)
+	h.recordContextSummaryUsage(c, apiKey, subscription, outcome)
+
+	var tooLong *service.ContextTooLongError
+	if errors.As(err, &tooLong) {
+		reqLog.Info("gateway.context_policy_rejected",
+			zap.Int("estimated_tokens", tooLong.EstimatedTokens),
+			zap.Int("max_tokens", tooLong.MaxTokens),
+		)
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", tooLong.Error())
+		return nil, false
+	}
+	if err != nil || outcome == nil || outcome.Action == "" {
+		return nil, true
+	}
+
+	reparsed, err := service.ParseGatewayRequest(outcome.Body, domain.PlatformAnthropic)
+	if err != nil {
+		reqLog.Warn("gateway.context_policy_rewrite_failed", zap.String("action", outcome.Action), zap.Error(err))
+		return nil, true
+	}
+	*body = outcome.Body
+	*parsedReq = reparsed
+	c.Header(service.ContextPolicyHeader, outcome.Action)
+	reqLog.Info("gateway.context_policy_applied",
+		zap.String("action", outcome.Action),
+		zap.Int("estimated_tokens", outcome.EstimatedTokens),
+		zap.Int("final_tokens", outcome.FinalTokens),
+		zap.Int("dropped_messages", outcome.DroppedMessages),
+	)
+	return outcome, true
+}
+
+// recordContextSummaryUsage 记录摘要请求的用量（context_action = summary_request）
+func (h *GatewayHandler) recordContextSummaryUsage(c *gin.Context, apiKey *service.APIKey, subscription *service.UserSubscription, outcome *service.ContextPolicyOutcome) {
+	summaryOutcome := outcome.SummaryRequestOutcome()
+	if summaryOutcome == nil {
+		return
+	}
+	result := outcome.SummaryResult
+	account := outcome.SummaryAccount
+	userAgent := c.GetHeader("User-Agent")
+	clientIP := ip.GetClientIP(c)
+
+	h.submitUsageRecordTask(func(ctx context.Context) {
+		if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
+			Result:        result,
+			APIKey:        apiKey,
+			User:          apiKey.User,
+			Account:       account,
+			Subscription:  subscription,
+			UserAgent:     userAgent,
+			IPAddress:     clientIP,
+			APIKeyService: h.apiKeyService,
+			ContextPolicy: summaryOutcome,
+		}); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.gateway.context_policy"),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.String("model", result.Model),
+				zap.Int64("account_id", account.ID),
+			).Error("gateway.record_usage_failed", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+func contextPolicyHandlerBody() []byte {
+	text := strings.Repeat("word ", 3000)
+	return []byte(`{"model":"claude-sonnet-4-5","messages":[
+		{"role":"user","content":"` + text + `"},
+		{"role":"assistant","content":"a1"},
+		{"role":"user","content":"q2"}
+	]}`)
+}
+
+func TestApplyContextPolicy_RejectWritesInvalidRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &GatewayHandler{contextPolicyService: service.NewContextPolicyService(nil, nil)}
+	apiKey := &service.APIKey{ID: 3, Group: &service.Group{ID: 1, ContextPolicy: &domain.ContextPolicyConfig{
+		Enabled: true, Action: domain.ContextPolicyActionReject, MaxInputTokens: 2048,
+	}}}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	body := contextPolicyHandlerBody()
+	parsed, err := service.ParseGatewayRequest(body, domain.PlatformAnthropic)
+	require.NoError(t, err)
+
+	outcome, ok := h.applyContextPolicy(c, apiKey, nil, &body, &parsed, zap.NewNop())
+	require.False(t, ok)
+	require.Nil(t, outcome)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "invalid_request_error", gjson.Get(w.Body.String(), "error.type").String())
+	require.Contains(t, gjson.Get(w.Body.String(), "error.message").String(), "prompt is too long")
+}
+
+func TestApplyContextPolicy_TruncateRewritesRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &GatewayHandler{contextPolicyService: service.NewContextPolicyService(nil, nil)}
+	apiKey := &service.APIKey{ID: 3, Group: &service.Group{ID: 1, ContextPolicy: &domain.ContextPolicyConfig{
+		Enabled: true, Action: domain.ContextPolicyActionTruncate, MaxInputTokens: 2048,
+	}}}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	body := contextPolicyHandlerBody()
+	parsed, err := service.ParseGatewayRequest(body, domain.PlatformAnthropic)
+	require.NoError(t, err)
+
+	outcome, ok := h.applyContextPolicy(c, apiKey, nil, &body, &parsed, zap.NewNop())
+	require.True(t, ok)
+	require.NotNil(t, outcome)
+	require.Equal(t, 2, outcome.DroppedMessages)
+	require.Equal(t, service.UsageContextActionTruncated, w.Header().Get(service.ContextPolicyHeader))
+	require.Len(t, gjson.GetBytes(body, "messages").Array(), 1)
+	require.Len(t, parsed.Messages, 1)
+}
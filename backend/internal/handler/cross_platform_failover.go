@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// crossPlatformFailoverState 单个请求的跨平台故障转移状态，同一请求模型最多转移一次。
+type crossPlatformFailoverState struct {
+	groupID int64
+	from    string
+	to      string
+	// previousForcePlatform 转移前的强制平台（空表示未强制），用于模型降级时恢复
+	previousForcePlatform string
+	active                bool
+	used                  bool
+}
+
+// tryCrossPlatformFailover 本平台账号耗尽时，按分组策略将后续调度限定到对端平台
+// （anthropic ↔ antigravity）。粘性会话在对端选中新账号时由调度层重新绑定。
+func (h *GatewayHandler) tryCrossPlatformFailover(c *gin.Context, apiKey *service.APIKey, state *crossPlatformFailoverState, reqLog *zap.Logger) bool {
+	if state.used || apiKey == nil || apiKey.Group == nil || !apiKey.Group.CrossPlatformFailover {
+		return false
+	}
+	forced, _ := c.Request.Context().Value(ctxkey.ForcePlatform).(string)
+	from := apiKey.Group.Platform
+	if forced != "" {
+		from = forced
+	}
+	to := service.CrossPlatformFailoverPeer(from)
+	if to == "" {
+		return false
+	}
+
+	*state = crossPlatformFailoverState{
+		groupID:               apiKey.Group.ID,
+		from:                  from,
+		to:                    to,
+		previousForcePlatform: forced,
+		active:                true,
+		used:                  true,
+	}
+	ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, to)
+	c.Request = c.Request.WithContext(ctx)
+	h.gatewayService.RecordCrossPlatformFailover(state.groupID, from, to)
+	reqLog.Warn("gateway.cross_platform_failover",
+		zap.Int64("group_id", state.groupID),
+		zap.String("from_platform", from),
+		zap.String("to_platform", to),
+	)
+	return true
+}
+
+// restore 恢复转移前的调度平台（模型降级切换模型后重新从本平台开始调度）
+func (s *crossPlatformFailoverState) restore(c *gin.Context) {
+	if !s.active {
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, s.previousForcePlatform)
+	c.Request = c.Request.WithContext(ctx)
+	s.active = false
+	s.used = false
+}
+
+// recordSuccess 转移后请求成功时计入统计
+func (h *GatewayHandler) recordCrossPlatformFailoverSuccess(state *crossPlatformFailoverState) {
+	if !state.active {
+		return
+	}
+	h.gatewayService.RecordCrossPlatformFailoverSuccess(state.groupID, state.from, state.to)
+}
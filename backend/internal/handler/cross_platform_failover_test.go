@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newCrossPlatformTestContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	return c
+}
+
+func TestTryCrossPlatformFailover_SwitchesOnceToPeerPlatform(t *testing.T) {
+	c := newCrossPlatformTestContext()
+	h := &GatewayHandler{}
+	apiKey := &service.APIKey{Group: &service.Group{ID: 7, Platform: service.PlatformAnthropic, CrossPlatformFailover: true}}
+	var state crossPlatformFailoverState
+
+	require.True(t, h.tryCrossPlatformFailover(c, apiKey, &state, zap.NewNop()))
+	require.Equal(t, service.PlatformAntigravity, c.Request.Context().Value(ctxkey.ForcePlatform))
+	require.False(t, h.tryCrossPlatformFailover(c, apiKey, &state, zap.NewNop()), "同一模型只转移一次")
+
+	state.restore(c)
+	require.Equal(t, "", c.Request.Context().Value(ctxkey.ForcePlatform))
+	require.True(t, h.tryCrossPlatformFailover(c, apiKey, &state, zap.NewNop()), "模型降级后可再次转移")
+}
+
+func TestTryCrossPlatformFailover_DisabledOrUnsupported(t *testing.T) {
+	c := newCrossPlatformTestContext()
+	h := &GatewayHandler{}
+	var state crossPlatformFailoverState
+
+	disabled := &service.APIKey{Group: &service.Group{Platform: service.PlatformAnthropic}}
+	require.False(t, h.tryCrossPlatformFailover(c, disabled, &state, zap.NewNop()))
+
+	openai := &service.APIKey{Group: &service.Group{Platform: service.PlatformOpenAI, CrossPlatformFailover: true}}
+	require.False(t, h.tryCrossPlatformFailover(c, openai, &state, zap.NewNop()))
+	require.Nil(t, c.Request.Context().Value(ctxkey.ForcePlatform))
+}
@@ -64,17 +64,41 @@ func UserFromServiceAdmin(u *service.User) *AdminUser {
 		GroupRates:            u.GroupRates,
 		SoraStorageQuotaBytes: u.SoraStorageQuotaBytes,
 		SoraStorageUsedBytes:  u.SoraStorageUsedBytes,
+		RPMLimit:              u.RPMLimit,
+		TPMLimit:              u.TPMLimit,
+		QueuePriority:         u.QueuePriority,
 	}
 }
 
+func UserSessionsFromService(sessions []*service.UserSession) []UserSession {
+	out := make([]UserSession, 0, len(sessions))
+	for _, s := range sessions {
+		if s == nil {
+			continue
+		}
+		out = append(out, UserSession{
+			ID:         s.ID,
+			Device:     s.Device,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.Current,
+		})
+	}
+	return out
+}
+
 func APIKeyFromService(k *service.APIKey) *APIKey {
 	if k == nil {
 		return nil
 	}
-	return &APIKey{
+	out := &APIKey{
 		ID:            k.ID,
 		UserID:        k.UserID,
 		Key:           k.Key,
+		KeyPrefix:     k.KeyPrefix,
 		Name:          k.Name,
 		GroupID:       k.GroupID,
 		Status:        k.Status,
@@ -95,9 +119,17 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		Window5hStart: k.Window5hStart,
 		Window1dStart: k.Window1dStart,
 		Window7dStart: k.Window7dStart,
+		RotatedAt:     k.RotatedAt,
+		Policy:        k.Policy,
 		User:          UserFromServiceShallow(k.User),
 		Group:         GroupFromServiceShallow(k.Group),
 	}
+	// 已过宽限期但尚未被后台任务清除的旧 Key 不再展示
+	if k.PreviousKeyExpiresAt != nil && k.PreviousKeyExpiresAt.After(time.Now()) {
+		out.PreviousKeyPrefix = k.PreviousKeyPrefix
+		out.PreviousKeyExpiresAt = k.PreviousKeyExpiresAt
+	}
+	return out
 }
 
 func GroupFromServiceShallow(g *service.Group) *Group {
@@ -122,14 +154,26 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		return nil
 	}
 	out := &AdminGroup{
-		Group:                groupFromServiceBase(g),
-		ModelRouting:         g.ModelRouting,
-		ModelRoutingEnabled:  g.ModelRoutingEnabled,
-		MCPXMLInject:         g.MCPXMLInject,
-		DefaultMappedModel:   g.DefaultMappedModel,
-		SupportedModelScopes: g.SupportedModelScopes,
-		AccountCount:         g.AccountCount,
-		SortOrder:            g.SortOrder,
+		Group:                 groupFromServiceBase(g),
+		ModelRouting:          g.ModelRouting,
+		ModelRoutingEnabled:   g.ModelRoutingEnabled,
+		MCPXMLInject:          g.MCPXMLInject,
+		DefaultMappedModel:    g.DefaultMappedModel,
+		MaxIPsPerKeyPerHour:   g.MaxIPsPerKeyPerHour,
+		RPMLimit:              g.RPMLimit,
+		TPMLimit:              g.TPMLimit,
+		QueuePriority:         g.QueuePriority,
+		SchedulingStrategy:    g.SchedulingStrategy,
+		ModelFallbackChains:   g.ModelFallbackChains,
+		CrossPlatformFailover: g.CrossPlatformFailover,
+		ShadowMirror:          g.ShadowMirror,
+		ResponseCache:         g.ResponseCache,
+		AutoCacheBreakpoints:  g.AutoCacheBreakpoints,
+		StreamResume:          g.StreamResume,
+		ContextPolicy:         g.ContextPolicy,
+		SupportedModelScopes:  g.SupportedModelScopes,
+		AccountCount:          g.AccountCount,
+		SortOrder:             g.SortOrder,
 	}
 	if len(g.AccountGroups) > 0 {
 		out.AccountGroups = make([]AccountGroup, 0, len(g.AccountGroups))
@@ -573,6 +617,45 @@ func UsageCleanupTaskFromService(task *service.UsageCleanupTask) *UsageCleanupTa
 	}
 }
 
+func UsageLogArchiveFromService(archive *service.UsageLogArchive) *UsageLogArchive {
+	if archive == nil {
+		return nil
+	}
+	return &UsageLogArchive{
+		ID:          archive.ID,
+		Source:      archive.Source,
+		TaskID:      archive.TaskID,
+		Granularity: archive.Granularity,
+		RangeStart:  archive.RangeStart,
+		RangeEnd:    archive.RangeEnd,
+		Filters: UsageCleanupFilters{
+			StartTime:   archive.Filters.StartTime,
+			EndTime:     archive.Filters.EndTime,
+			UserID:      archive.Filters.UserID,
+			APIKeyID:    archive.Filters.APIKeyID,
+			AccountID:   archive.Filters.AccountID,
+			GroupID:     archive.Filters.GroupID,
+			Model:       archive.Filters.Model,
+			RequestType: requestTypeStringPtr(archive.Filters.RequestType),
+			Stream:      archive.Filters.Stream,
+			BillingType: archive.Filters.BillingType,
+		},
+		ProfileID:       archive.ProfileID,
+		Bucket:          archive.Bucket,
+		ObjectKey:       archive.ObjectKey,
+		Format:          archive.Format,
+		RowCount:        archive.RowCount,
+		SizeBytes:       archive.SizeBytes,
+		SHA256:          archive.SHA256,
+		RehydrateStatus: archive.RehydrateStatus,
+		RehydratedRows:  archive.RehydratedRows,
+		RehydrateError:  archive.RehydrateError,
+		RehydratedAt:    archive.RehydratedAt,
+		CreatedAt:       archive.CreatedAt,
+		UpdatedAt:       archive.UpdatedAt,
+	}
+}
+
 func requestTypeStringPtr(requestType *int16) *string {
 	if requestType == nil {
 		return nil
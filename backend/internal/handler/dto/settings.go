@@ -25,6 +25,9 @@ type SystemSettings struct {
 	InvitationCodeEnabled            bool     `json:"invitation_code_enabled"`
 	TotpEnabled                      bool     `json:"totp_enabled"`                   // TOTP 双因素认证
 	TotpEncryptionKeyConfigured      bool     `json:"totp_encryption_key_configured"` // TOTP 加密密钥是否已配置
+	PasskeyEnabled                   bool     `json:"passkey_enabled"`                // WebAuthn Passkey 登录与 2FA
+	WebAuthnConfigured               bool     `json:"webauthn_configured"`            // WebAuthn 依赖方是否已配置
+	AdminRequire2FA                  bool     `json:"admin_require_2fa"`              // 管理员必须启用第二因素
 
 	SMTPHost               string `json:"smtp_host"`
 	SMTPPort               int    `json:"smtp_port"`
@@ -89,29 +92,37 @@ type DefaultSubscriptionSetting struct {
 }
 
 type PublicSettings struct {
-	RegistrationEnabled              bool             `json:"registration_enabled"`
-	EmailVerifyEnabled               bool             `json:"email_verify_enabled"`
-	RegistrationEmailSuffixWhitelist []string         `json:"registration_email_suffix_whitelist"`
-	PromoCodeEnabled                 bool             `json:"promo_code_enabled"`
-	PasswordResetEnabled             bool             `json:"password_reset_enabled"`
-	InvitationCodeEnabled            bool             `json:"invitation_code_enabled"`
-	TotpEnabled                      bool             `json:"totp_enabled"` // TOTP 双因素认证
-	TurnstileEnabled                 bool             `json:"turnstile_enabled"`
-	TurnstileSiteKey                 string           `json:"turnstile_site_key"`
-	SiteName                         string           `json:"site_name"`
-	SiteLogo                         string           `json:"site_logo"`
-	SiteSubtitle                     string           `json:"site_subtitle"`
-	APIBaseURL                       string           `json:"api_base_url"`
-	ContactInfo                      string           `json:"contact_info"`
-	DocURL                           string           `json:"doc_url"`
-	HomeContent                      string           `json:"home_content"`
-	HideCcsImportButton              bool             `json:"hide_ccs_import_button"`
-	PurchaseSubscriptionEnabled      bool             `json:"purchase_subscription_enabled"`
-	PurchaseSubscriptionURL          string           `json:"purchase_subscription_url"`
-	CustomMenuItems                  []CustomMenuItem `json:"custom_menu_items"`
-	LinuxDoOAuthEnabled              bool             `json:"linuxdo_oauth_enabled"`
-	SoraClientEnabled                bool             `json:"sora_client_enabled"`
-	Version                          string           `json:"version"`
+	RegistrationEnabled              bool                 `json:"registration_enabled"`
+	EmailVerifyEnabled               bool                 `json:"email_verify_enabled"`
+	RegistrationEmailSuffixWhitelist []string             `json:"registration_email_suffix_whitelist"`
+	PromoCodeEnabled                 bool                 `json:"promo_code_enabled"`
+	PasswordResetEnabled             bool                 `json:"password_reset_enabled"`
+	InvitationCodeEnabled            bool                 `json:"invitation_code_enabled"`
+	TotpEnabled                      bool                 `json:"totp_enabled"` // TOTP 双因素认证
+	PasskeyEnabled                   bool                 `json:"passkey_enabled"`
+	TurnstileEnabled                 bool                 `json:"turnstile_enabled"`
+	TurnstileSiteKey                 string               `json:"turnstile_site_key"`
+	SiteName                         string               `json:"site_name"`
+	SiteLogo                         string               `json:"site_logo"`
+	SiteSubtitle                     string               `json:"site_subtitle"`
+	APIBaseURL                       string               `json:"api_base_url"`
+	ContactInfo                      string               `json:"contact_info"`
+	DocURL                           string               `json:"doc_url"`
+	HomeContent                      string               `json:"home_content"`
+	HideCcsImportButton              bool                 `json:"hide_ccs_import_button"`
+	PurchaseSubscriptionEnabled      bool                 `json:"purchase_subscription_enabled"`
+	PurchaseSubscriptionURL          string               `json:"purchase_subscription_url"`
+	CustomMenuItems                  []CustomMenuItem     `json:"custom_menu_items"`
+	LinuxDoOAuthEnabled              bool                 `json:"linuxdo_oauth_enabled"`
+	OIDCProviders                    []OIDCPublicProvider `json:"oidc_providers"`
+	SoraClientEnabled                bool                 `json:"sora_client_enabled"`
+	Version                          string               `json:"version"`
+}
+
+// OIDCPublicProvider 登录页展示的 OIDC 提供方
+type OIDCPublicProvider struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
 }
 
 // SoraS3Settings Sora S3 存储配置 DTO（响应用，不含敏感字段）
@@ -193,3 +204,38 @@ func ParseUserVisibleMenuItems(raw string) []CustomMenuItem {
 	}
 	return filtered
 }
+
+// OIDCGroupMapping IdP 分组映射 DTO
+type OIDCGroupMapping struct {
+	IdPGroup        string                       `json:"idp_group"`
+	AllowedGroupIDs []int64                      `json:"allowed_group_ids"`
+	Subscriptions   []DefaultSubscriptionSetting `json:"subscriptions"`
+}
+
+// OIDCProvider OIDC 登录提供方 DTO（响应用，不含 client secret）
+type OIDCProvider struct {
+	Key                    string             `json:"key"`
+	Name                   string             `json:"name"`
+	Enabled                bool               `json:"enabled"`
+	DiscoveryURL           string             `json:"discovery_url"`
+	Issuer                 string             `json:"issuer"`
+	AuthorizeURL           string             `json:"authorize_url"`
+	TokenURL               string             `json:"token_url"`
+	UserInfoURL            string             `json:"userinfo_url"`
+	JWKSURL                string             `json:"jwks_url"`
+	ClientID               string             `json:"client_id"`
+	ClientSecretConfigured bool               `json:"client_secret_configured"`
+	TokenAuthMethod        string             `json:"token_auth_method"`
+	Scopes                 string             `json:"scopes"`
+	RedirectURL            string             `json:"redirect_url"`
+	FrontendRedirectURL    string             `json:"frontend_redirect_url"`
+	SubjectClaim           string             `json:"subject_claim"`
+	EmailClaim             string             `json:"email_claim"`
+	EmailVerifiedClaim     string             `json:"email_verified_claim"`
+	UsernameClaim          string             `json:"username_claim"`
+	GroupsClaim            string             `json:"groups_claim"`
+	TrustEmail             bool               `json:"trust_email"`
+	AssumeEmailVerified    bool               `json:"assume_email_verified"`
+	GroupMappings          []OIDCGroupMapping `json:"group_mappings"`
+	UpdatedAt              string             `json:"updated_at"`
+}
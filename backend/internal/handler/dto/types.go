@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
 
 type User struct {
 	ID            int64     `json:"id"`
@@ -18,6 +22,18 @@ type User struct {
 	Subscriptions []UserSubscription `json:"subscriptions,omitempty"`
 }
 
+// UserSession 用户登录会话（一个 Refresh Token 家族）
+type UserSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
 // AdminUser 是管理员接口使用的 user DTO（包含敏感/内部字段）。
 // 注意：普通用户接口不得返回 notes 等管理员备注信息。
 type AdminUser struct {
@@ -29,12 +45,18 @@ type AdminUser struct {
 	GroupRates            map[int64]float64 `json:"group_rates,omitempty"`
 	SoraStorageQuotaBytes int64             `json:"sora_storage_quota_bytes"`
 	SoraStorageUsedBytes  int64             `json:"sora_storage_used_bytes"`
+	// 请求速率限制（用户所有 Key 合计，0 不限制）
+	RPMLimit int `json:"rpm_limit"`
+	TPMLimit int `json:"tpm_limit"`
+	// 账号满载排队优先级（0-9）
+	QueuePriority int `json:"queue_priority"`
 }
 
 type APIKey struct {
 	ID          int64      `json:"id"`
 	UserID      int64      `json:"user_id"`
-	Key         string     `json:"key"`
+	Key         string     `json:"key"` // 明文仅在创建时返回，其余接口只返回 key_prefix
+	KeyPrefix   string     `json:"key_prefix"`
 	Name        string     `json:"name"`
 	GroupID     *int64     `json:"group_id"`
 	Status      string     `json:"status"`
@@ -58,6 +80,14 @@ type APIKey struct {
 	Window1dStart *time.Time `json:"window_1d_start"`
 	Window7dStart *time.Time `json:"window_7d_start"`
 
+	// Rotation fields: 宽限期内仍可使用的旧 Key（仅返回前缀）
+	PreviousKeyPrefix    string     `json:"previous_key_prefix"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at"`
+	RotatedAt            *time.Time `json:"rotated_at"`
+
+	// Policy 访问策略（模型/端点/平台与请求上限），未配置时为 null
+	Policy *service.APIKeyPolicy `json:"policy"`
+
 	User  *User  `json:"user,omitempty"`
 	Group *Group `json:"group,omitempty"`
 }
@@ -118,6 +148,34 @@ type AdminGroup struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	DefaultMappedModel string `json:"default_mapped_model"`
 
+	// Key 共享防护：每个 API Key 每小时允许的不同来源 IP 数（0 不限制）
+	MaxIPsPerKeyPerHour int `json:"max_ips_per_key_per_hour"`
+
+	// 请求速率限制：分组内所有请求合计的 RPM/TPM（0 不限制）
+	RPMLimit int `json:"rpm_limit"`
+	TPMLimit int `json:"tpm_limit"`
+
+	// 账号满载排队优先级（0-9，越大越优先）
+	QueuePriority int `json:"queue_priority"`
+
+	// 账号调度策略（空为平台默认）
+	SchedulingStrategy string `json:"scheduling_strategy"`
+
+	// 模型降级链
+	ModelFallbackChains []service.ModelFallbackChain `json:"model_fallback_chains"`
+	// 跨平台故障转移
+	CrossPlatformFailover bool `json:"cross_platform_failover"`
+	// 影子流量镜像
+	ShadowMirror *service.ShadowMirrorConfig `json:"shadow_mirror"`
+	// 网关响应缓存
+	ResponseCache *service.ResponseCacheConfig `json:"response_cache"`
+	// 自动 prompt 缓存断点
+	AutoCacheBreakpoints bool `json:"auto_cache_breakpoints"`
+	// 流式断线续传
+	StreamResume bool `json:"stream_resume"`
+	// 上下文策略
+	ContextPolicy *service.ContextPolicyConfig `json:"context_policy"`
+
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes []string       `json:"supported_model_scopes"`
 	AccountGroups        []AccountGroup `json:"account_groups,omitempty"`
@@ -410,6 +468,29 @@ type UsageCleanupTask struct {
 	UpdatedAt    time.Time           `json:"updated_at"`
 }
 
+type UsageLogArchive struct {
+	ID              int64               `json:"id"`
+	Source          string              `json:"source"`
+	TaskID          *int64              `json:"task_id,omitempty"`
+	Granularity     string              `json:"granularity"`
+	RangeStart      time.Time           `json:"range_start"`
+	RangeEnd        time.Time           `json:"range_end"`
+	Filters         UsageCleanupFilters `json:"filters"`
+	ProfileID       string              `json:"profile_id"`
+	Bucket          string              `json:"bucket"`
+	ObjectKey       string              `json:"object_key"`
+	Format          string              `json:"format"`
+	RowCount        int64               `json:"row_count"`
+	SizeBytes       int64               `json:"size_bytes"`
+	SHA256          string              `json:"sha256"`
+	RehydrateStatus string              `json:"rehydrate_status"`
+	RehydratedRows  int64               `json:"rehydrated_rows"`
+	RehydrateError  *string             `json:"rehydrate_error,omitempty"`
+	RehydratedAt    *time.Time          `json:"rehydrated_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
 // AccountSummary is a minimal account info for usage log display.
 // It intentionally excludes sensitive fields like Credentials, Proxy, etc.
 type AccountSummary struct {
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// applyExperiment 为请求分配 A/B 实验变体，并按变体改写分组、模型与 system。
+// 返回（可能改写分组后的）API Key 与命中的实验；未命中或变体分组不可用时原样返回、不打标签，
+// 避免把未按变体处理的请求计入实验结果。
+func (h *GatewayHandler) applyExperiment(c *gin.Context, apiKey *service.APIKey, body *[]byte, parsedReq **service.ParsedRequest, reqLog *zap.Logger) (*service.APIKey, *service.ExperimentAssignment) {
+	assignment := h.experimentService.Assign(c.Request.Context(), apiKey, (*parsedReq).Model)
+	if assignment == nil {
+		return apiKey, nil
+	}
+	variant := assignment.Variant
+
+	newBody, newParsed := *body, *parsedReq
+	if variant.Model != "" || variant.SystemPromptPatch != "" {
+		rewritten := h.gatewayService.ApplyExperimentVariant(*body, (*parsedReq).System, variant)
+		reparsed, err := service.ParseGatewayRequest(rewritten, domain.PlatformAnthropic)
+		if err != nil {
+			reqLog.Warn("gateway.experiment_rewrite_failed",
+				zap.Int64("experiment_id", assignment.ExperimentID),
+				zap.String("variant", variant.Name),
+				zap.Error(err),
+			)
+			return apiKey, nil
+		}
+		newBody, newParsed = rewritten, reparsed
+	}
+
+	newAPIKey := apiKey
+	if variant.GroupID > 0 {
+		group, err := h.gatewayService.ResolveGroupByID(c.Request.Context(), variant.GroupID)
+		if err != nil {
+			reqLog.Warn("gateway.experiment_group_resolve_failed",
+				zap.Int64("experiment_id", assignment.ExperimentID),
+				zap.String("variant", variant.Name),
+				zap.Int64("group_id", variant.GroupID),
+				zap.Error(err),
+			)
+			return apiKey, nil
+		}
+		newAPIKey = cloneAPIKeyWithGroup(apiKey, group)
+	}
+
+	// 变体改写后的模型与平台仍需符合 Key 访问策略，不符合时跳过该变体
+	if err := experimentVariantPolicyError(c, apiKey, newAPIKey, variant, newBody, newParsed.Model); err != nil {
+		reqLog.Info("gateway.experiment_variant_policy_denied",
+			zap.Int64("experiment_id", assignment.ExperimentID),
+			zap.String("variant", variant.Name),
+			zap.Error(err),
+		)
+		return apiKey, nil
+	}
+
+	if variant.GroupID > 0 {
+		apiKey = newAPIKey
+		// 变体分组按"直接请求该分组"调度：清除强制平台
+		ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, "")
+		c.Request = c.Request.WithContext(ctx)
+	}
+
+	*body = newBody
+	*parsedReq = newParsed
+	c.Set(service.OpsExperimentKey, assignment)
+	reqLog.Debug("gateway.experiment_assigned",
+		zap.Int64("experiment_id", assignment.ExperimentID),
+		zap.String("variant", variant.Name),
+		zap.String("model", (*parsedReq).Model),
+	)
+	return apiKey, assignment
+}
+
+// experimentVariantPolicyError 按 Key 访问策略校验变体改写后的模型与平台，避免实验绕过策略限制
+func experimentVariantPolicyError(c *gin.Context, original, rewritten *service.APIKey, variant service.ExperimentVariant, body []byte, model string) error {
+	if original == nil || original.Policy == nil {
+		return nil
+	}
+	platform := ""
+	if variant.GroupID == 0 {
+		platform, _ = c.Request.Context().Value(ctxkey.ForcePlatform).(string)
+	}
+	if platform == "" && rewritten.Group != nil {
+		platform = rewritten.Group.Platform
+	}
+	if platform != "" && !original.Policy.AllowsPlatform(platform) {
+		return service.ErrAPIKeyPlatformNotAllowed
+	}
+	return service.CheckAPIKeyPolicyLimits(original.Policy, service.NewAPIKeyPolicyRequest(body, model))
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentVariantPolicyError(t *testing.T) {
+	c := newCrossPlatformTestContext()
+	apiKey := &service.APIKey{
+		Group: &service.Group{ID: 7, Platform: service.PlatformAnthropic},
+		Policy: &service.APIKeyPolicy{
+			AllowedModels:    []string{"claude-*"},
+			AllowedPlatforms: []string{service.PlatformAnthropic},
+		},
+	}
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":16,"messages":[]}`)
+
+	require.NoError(t, experimentVariantPolicyError(c, apiKey, apiKey, service.ExperimentVariant{Model: "claude-sonnet-4"}, body, "claude-sonnet-4"))
+
+	// 变体模型不在 Key 允许范围内
+	err := experimentVariantPolicyError(c, apiKey, apiKey, service.ExperimentVariant{Model: "gpt-5"}, body, "gpt-5")
+	require.Error(t, err)
+
+	// 变体分组的平台不在 Key 允许范围内
+	antigravity := cloneAPIKeyWithGroup(apiKey, &service.Group{ID: 8, Platform: service.PlatformAntigravity})
+	err = experimentVariantPolicyError(c, apiKey, antigravity, service.ExperimentVariant{GroupID: 8}, body, "claude-sonnet-4")
+	require.ErrorIs(t, err, service.ErrAPIKeyPlatformNotAllowed)
+
+	// 沿用原分组时以强制平台为准
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, service.PlatformAntigravity))
+	err = experimentVariantPolicyError(c, apiKey, apiKey, service.ExperimentVariant{Model: "claude-sonnet-4"}, body, "claude-sonnet-4")
+	require.ErrorIs(t, err, service.ErrAPIKeyPlatformNotAllowed)
+
+	// 无策略的 Key 不受限制
+	require.NoError(t, experimentVariantPolicyError(c, &service.APIKey{}, &service.APIKey{}, service.ExperimentVariant{Model: "gpt-5"}, body, "gpt-5"))
+}
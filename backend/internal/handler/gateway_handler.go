@@ -51,6 +51,11 @@ type GatewayHandler struct {
 	maxAccountSwitchesGemini  int
 	cfg                       *config.Config
 	settingService            *service.SettingService
+	shadowMirrorService       *service.ShadowMirrorService
+	experimentService         *service.ExperimentService
+	responseCacheService      *service.ResponseCacheService
+	streamResumeService       *service.StreamResumeService
+	contextPolicyService      *service.ContextPolicyService
 }
 
 // NewGatewayHandler creates a new GatewayHandler
@@ -68,6 +73,11 @@ func NewGatewayHandler(
 	userMsgQueueService *service.UserMessageQueueService,
 	cfg *config.Config,
 	settingService *service.SettingService,
+	shadowMirrorService *service.ShadowMirrorService,
+	experimentService *service.ExperimentService,
+	responseCacheService *service.ResponseCacheService,
+	streamResumeService *service.StreamResumeService,
+	contextPolicyService *service.ContextPolicyService,
 ) *GatewayHandler {
 	pingInterval := time.Duration(0)
 	maxAccountSwitches := 10
@@ -104,6 +114,11 @@ func NewGatewayHandler(
 		maxAccountSwitchesGemini:  maxAccountSwitchesGemini,
 		cfg:                       cfg,
 		settingService:            settingService,
+		shadowMirrorService:       shadowMirrorService,
+		experimentService:         experimentService,
+		responseCacheService:      responseCacheService,
+		streamResumeService:       streamResumeService,
+		contextPolicyService:      contextPolicyService,
 	}
 }
 
@@ -131,6 +146,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	)
 	defer h.maybeLogCompatibilityFallbackMetrics(reqLog)
 
+	// 流式断线续传：携带本网关签发的 Last-Event-ID 时回放缓冲事件，不请求上游
+	if h.tryResumeStream(c, apiKey) {
+		return
+	}
+
 	// 读取请求体
 	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
 	if err != nil {
@@ -185,6 +205,23 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// Key 访问策略：模型、thinking/工具与单次请求输入/输出 token 上限（RPM/TPM 由鉴权中间件的限流处理）
+	if apiKey.Policy != nil && h.apiKeyService != nil {
+		if err := h.apiKeyService.CheckPolicyRequest(c.Request.Context(), apiKey, service.NewAPIKeyPolicyRequest(body, reqModel)); err != nil {
+			status, errType, message := apiKeyPolicyErrorDetails(err)
+			h.errorResponse(c, status, errType, message)
+			return
+		}
+	}
+
+	// A/B 模型实验：按粘性维度分配变体，在调度前改写分组、模型与 system
+	var experiment *service.ExperimentAssignment
+	if apiKey, experiment = h.applyExperiment(c, apiKey, &body, &parsedReq, reqLog); experiment != nil {
+		reqModel = parsedReq.Model
+		setOpsRequestContext(c, reqModel, reqStream, body)
+		reqLog = reqLog.With(zap.Int64("experiment_id", experiment.ExperimentID), zap.String("experiment_variant", experiment.Variant.Name))
+	}
+
 	// Track if we've started streaming (for error handling)
 	streamStarted := false
 
@@ -244,6 +281,25 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 网关响应缓存：确定性请求命中时直接回放，未命中时截留响应待成功后写入
+	responseCache, cacheHit := h.lookupResponseCache(c, apiKey, subscription, experiment, body, reqStream)
+	if cacheHit {
+		return
+	}
+
+	// 上下文策略：本地估算输入 token，超限时拒绝、截断最早轮次或摘要
+	contextOutcome, ok := h.applyContextPolicy(c, apiKey, subscription, &body, &parsedReq, reqLog)
+	if !ok {
+		return
+	}
+	if contextOutcome != nil {
+		setOpsRequestContext(c, reqModel, reqStream, body)
+	}
+
+	// 流式断线续传：缓冲本次 SSE 事件供客户端重连续传
+	streamResume := h.beginStreamResume(c, apiKey, reqStream)
+	defer streamResume.Finish()
+
 	// 计算粘性会话hash
 	parsedReq.SessionContext = &service.SessionContext{
 		ClientIP:  ip.GetClientIP(c),
@@ -251,6 +307,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		APIKeyID:  apiKey.ID,
 	}
 	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	// 自动缓存断点：每个客户端请求只识别一次对话轮次，换号重试沿用同一结果
+	h.gatewayService.ResolveAutoCacheConversation(c.Request.Context(), apiKey.Group, parsedReq)
 
 	// 获取平台：优先使用强制平台（/antigravity 路由，中间件已设置 request.Context），否则使用分组平台
 	platform := ""
@@ -291,7 +349,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		}
 
 		for {
-			selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), apiKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, "") // Gemini 不使用会话限制
+			selection, scheduleDecision, err := h.gatewayService.SelectAccountWithScheduleDecision(c.Request.Context(), apiKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, "") // Gemini 不使用会话限制
 			if err != nil {
 				if len(fs.FailedAccountIDs) == 0 {
 					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error(), streamStarted)
@@ -316,6 +374,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			account := selection.Account
 			setOpsSelectedAccount(c, account.ID, account.Platform)
+			logAccountScheduleDecision(reqLog, scheduleDecision)
 
 			// 检查请求拦截（预热请求、SUGGESTION MODE等）
 			if account.IsInterceptWarmupEnabled() {
@@ -400,11 +459,13 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				accountReleaseFunc()
 			}
 			if err != nil {
+				h.gatewayService.ReportAccountScheduleResult(account.ID, false, nil)
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
 					action := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
 					switch action {
 					case FailoverContinue:
+						h.gatewayService.RecordAccountSwitch()
 						continue
 					case FailoverExhausted:
 						h.handleFailoverExhausted(c, fs.LastFailoverErr, service.PlatformGemini, streamStarted)
@@ -422,6 +483,9 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				return
 			}
 
+			h.gatewayService.ReportAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+			h.storeResponseCache(c, responseCache, account, result)
+
 			// RPM 计数递增（Forward 成功后）
 			// 注意：TOCTOU 竞态是已知且可接受的设计权衡，与 WindowCost 一致的 soft-limit 模式。
 			// 在高并发下可能短暂超出 RPM 限制，但不会导致请求失败。
@@ -447,6 +511,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					IPAddress:         clientIP,
 					ForceCacheBilling: fs.ForceCacheBilling,
 					APIKeyService:     h.apiKeyService,
+					Experiment:        experiment,
+					ContextPolicy:     contextOutcome,
 				}); err != nil {
 					logger.L().With(
 						zap.String("component", "handler.gateway.messages"),
@@ -477,19 +543,40 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		c.Request = c.Request.WithContext(ctx)
 	}
 
+	// 分组模型降级链：按原始请求模型匹配，触发条件命中时依次切换到降级模型
+	modelFallback := service.NewModelFallbackPlan(apiKey.Group, reqModel)
+	if modelFallback != nil {
+		modelFallback.BaseForcePlatform, _ = c.Request.Context().Value(ctxkey.ForcePlatform).(string)
+	}
+	var crossPlatform crossPlatformFailoverState
+	carryCacheBilling := false
+	// 影子流量镜像：按采样率异步复制原始请求到影子账号，仅记录对比指标
+	shadowMirror := h.startShadowMirror(c, apiKey.Group, body, reqModel, reqStream)
+
 	for {
 		fs := NewFailoverState(h.maxAccountSwitches, hasBoundSession)
+		fs.ForceCacheBilling = carryCacheBilling
 		retryWithFallback := false
 
 		for {
 			// 选择支持该模型的账号
-			selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), currentAPIKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, parsedReq.MetadataUserID)
+			selection, scheduleDecision, err := h.gatewayService.SelectAccountWithScheduleDecision(c.Request.Context(), currentAPIKey.GroupID, sessionKey, reqModel, fs.FailedAccountIDs, parsedReq.MetadataUserID)
 			if err != nil {
 				if len(fs.FailedAccountIDs) == 0 {
+					if h.tryCrossPlatformFailover(c, currentAPIKey, &crossPlatform, reqLog) ||
+						h.applyModelFallback(c, currentAPIKey, modelFallback, service.ModelFallbackTriggerNoAccount, &body, parsedReq, &reqModel, &crossPlatform, reqLog) {
+						retryWithFallback = true
+						break
+					}
 					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error(), streamStarted)
 					return
 				}
 				action := fs.HandleSelectionExhausted(c.Request.Context())
+				if action == FailoverExhausted && (h.tryCrossPlatformFailover(c, currentAPIKey, &crossPlatform, reqLog) ||
+					h.applyModelFallback(c, currentAPIKey, modelFallback, modelFallbackTriggerForFailover(fs.LastFailoverErr), &body, parsedReq, &reqModel, &crossPlatform, reqLog)) {
+					retryWithFallback = true
+					break
+				}
 				switch action {
 				case FailoverContinue:
 					ctx := service.WithSingleAccountRetry(c.Request.Context(), true, h.metadataBridgeEnabled())
@@ -508,6 +595,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			account := selection.Account
 			setOpsSelectedAccount(c, account.ID, account.Platform)
+			logAccountScheduleDecision(reqLog, scheduleDecision)
 
 			// 检查请求拦截（预热请求、SUGGESTION MODE等）
 			if account.IsInterceptWarmupEnabled() {
@@ -529,6 +617,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			accountReleaseFunc := selection.ReleaseFunc
 			if !selection.Acquired {
 				if selection.WaitPlan == nil {
+					if h.tryCrossPlatformFailover(c, currentAPIKey, &crossPlatform, reqLog) ||
+						h.applyModelFallback(c, currentAPIKey, modelFallback, service.ModelFallbackTriggerNoAccount, &body, parsedReq, &reqModel, &crossPlatform, reqLog) {
+						retryWithFallback = true
+						break
+					}
 					h.handleStreamingAwareError(c, http.StatusServiceUnavailable, "api_error", "No available accounts", streamStarted)
 					return
 				}
@@ -638,6 +731,13 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
 				result, err = h.antigravityGatewayService.Forward(requestCtx, c, account, body, hasBoundSession)
 			} else {
+				if streamResume != nil {
+					// 上游不随客户端断开而取消，读取完毕的事件留待续传
+					requestCtx = context.WithoutCancel(requestCtx)
+				}
+				if modelFallback.CanFallback(service.ModelFallbackTriggerContextTooLong) {
+					requestCtx = service.WithModelFallbackOnPromptTooLong(requestCtx)
+				}
 				result, err = h.gatewayService.Forward(requestCtx, c, account, parsedReq)
 			}
 
@@ -654,6 +754,10 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			if err != nil {
 				var promptTooLongErr *service.PromptTooLongError
 				if errors.As(err, &promptTooLongErr) {
+					if h.applyModelFallback(c, currentAPIKey, modelFallback, service.ModelFallbackTriggerContextTooLong, &body, parsedReq, &reqModel, &crossPlatform, reqLog) {
+						retryWithFallback = true
+						break
+					}
 					reqLog.Warn("gateway.prompt_too_long_from_antigravity",
 						zap.Any("current_group_id", currentAPIKey.GroupID),
 						zap.Any("fallback_group_id", fallbackGroupID),
@@ -695,18 +799,28 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					_ = h.antigravityGatewayService.WriteMappedClaudeError(c, account, promptTooLongErr.StatusCode, promptTooLongErr.RequestID, promptTooLongErr.Body)
 					return
 				}
+				h.gatewayService.ReportAccountScheduleResult(account.ID, false, nil)
 				var failoverErr *service.UpstreamFailoverError
 				if errors.As(err, &failoverErr) {
 					action := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
 					switch action {
 					case FailoverContinue:
+						h.gatewayService.RecordAccountSwitch()
 						continue
 					case FailoverExhausted:
+						if h.tryCrossPlatformFailover(c, currentAPIKey, &crossPlatform, reqLog) ||
+							h.applyModelFallback(c, currentAPIKey, modelFallback, modelFallbackTriggerForFailover(fs.LastFailoverErr), &body, parsedReq, &reqModel, &crossPlatform, reqLog) {
+							retryWithFallback = true
+							break
+						}
 						h.handleFailoverExhausted(c, fs.LastFailoverErr, account.Platform, streamStarted)
 						return
 					case FailoverCanceled:
 						return
 					}
+					if retryWithFallback {
+						break
+					}
 				}
 				wroteFallback := h.ensureForwardErrorResponse(c, streamStarted)
 				reqLog.Error("gateway.forward_failed",
@@ -717,6 +831,11 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				return
 			}
 
+			h.gatewayService.ReportAccountScheduleResult(account.ID, true, result.FirstTokenMs)
+			h.recordCrossPlatformFailoverSuccess(&crossPlatform)
+			h.submitShadowMirror(c, shadowMirror, currentAPIKey, account, result)
+			h.storeResponseCache(c, responseCache, account, result)
+
 			// RPM 计数递增（Forward 成功后）
 			// 注意：TOCTOU 竞态是已知且可接受的设计权衡，与 WindowCost 一致的 soft-limit 模式。
 			// 在高并发下可能短暂超出 RPM 限制，但不会导致请求失败。
@@ -742,6 +861,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					IPAddress:         clientIP,
 					ForceCacheBilling: fs.ForceCacheBilling,
 					APIKeyService:     h.apiKeyService,
+					Experiment:        experiment,
+					ContextPolicy:     contextOutcome,
 				}); err != nil {
 					logger.L().With(
 						zap.String("component", "handler.gateway.messages"),
@@ -758,6 +879,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		if !retryWithFallback {
 			return
 		}
+		// 跨平台转移视同粘性会话切换账号，保留缓存计费判定
+		carryCacheBilling = crossPlatform.active && fs.ForceCacheBilling
 	}
 }
 
@@ -1138,6 +1261,76 @@ func (h *GatewayHandler) handleConcurrencyError(c *gin.Context, err error, slotT
 		fmt.Sprintf("Concurrency limit exceeded for %s, please retry later", slotType), streamStarted)
 }
 
+// applyModelFallback 按分组降级链切换到下一个降级模型：重写请求体与模型，
+// 设置降级响应头，并按步骤重设调度平台。Key 访问策略不允许的步骤直接跳过；无可用降级步骤时返回 false。
+func (h *GatewayHandler) applyModelFallback(c *gin.Context, apiKey *service.APIKey, plan *service.ModelFallbackPlan, trigger string, body *[]byte, parsedReq *service.ParsedRequest, reqModel *string, crossPlatform *crossPlatformFailoverState, reqLog *zap.Logger) bool {
+	var step service.ModelFallbackStep
+	for {
+		next, ok := plan.Next(trigger)
+		if !ok {
+			return false
+		}
+		if err := modelFallbackStepPolicyError(apiKey, plan, next, *body); err != nil {
+			reqLog.Info("gateway.model_fallback_step_skipped",
+				zap.String("fallback_model", next.Model),
+				zap.String("fallback_platform", next.Platform),
+				zap.Error(err),
+			)
+			continue
+		}
+		step = next
+		break
+	}
+	reqLog.Info("gateway.model_fallback",
+		zap.String("original_model", plan.OriginalModel),
+		zap.String("from_model", *reqModel),
+		zap.String("fallback_model", step.Model),
+		zap.String("fallback_platform", step.Platform),
+		zap.String("trigger", trigger),
+	)
+	*body = h.gatewayService.ReplaceModelInBody(*body, step.Model)
+	*reqModel = step.Model
+	parsedReq.Body = *body
+	parsedReq.Model = step.Model
+	// 降级模型重新从降级前的平台开始调度（仍可再次跨平台转移）；步骤指定平台时限定到该平台
+	crossPlatform.restore(c)
+	forcePlatform := plan.BaseForcePlatform
+	if step.Platform != "" {
+		forcePlatform = step.Platform
+	}
+	ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, forcePlatform)
+	c.Request = c.Request.WithContext(ctx)
+	c.Header(service.ModelFallbackFromHeader, plan.OriginalModel)
+	c.Header(service.ModelFallbackReasonHeader, trigger)
+	return true
+}
+
+// modelFallbackStepPolicyError 按 Key 访问策略校验降级步骤的模型与平台，避免降级绕过策略限制
+func modelFallbackStepPolicyError(apiKey *service.APIKey, plan *service.ModelFallbackPlan, step service.ModelFallbackStep, body []byte) error {
+	if apiKey == nil || apiKey.Policy == nil {
+		return nil
+	}
+	platform := step.Platform
+	if platform == "" {
+		platform = plan.BaseForcePlatform
+	}
+	if platform == "" && apiKey.Group != nil {
+		platform = apiKey.Group.Platform
+	}
+	if platform != "" && !apiKey.Policy.AllowsPlatform(platform) {
+		return service.ErrAPIKeyPlatformNotAllowed
+	}
+	return service.CheckAPIKeyPolicyLimits(apiKey.Policy, service.NewAPIKeyPolicyRequest(body, step.Model))
+}
+
+// modelFallbackTriggerForFailover 根据最后一次上游失败推导降级触发条件
+func modelFallbackTriggerForFailover(failoverErr *service.UpstreamFailoverError) string {
+	if failoverErr == nil {
+		return service.ModelFallbackTriggerNoAccount
+	}
+	return service.ModelFallbackTriggerForStatus(failoverErr.StatusCode)
+}
+
 func (h *GatewayHandler) handleFailoverExhausted(c *gin.Context, failoverErr *service.UpstreamFailoverError, platform string, streamStarted bool) {
 	statusCode := failoverErr.StatusCode
 	responseBody := failoverErr.ResponseBody
@@ -1328,6 +1521,13 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 		return
 	}
 
+	// Key 访问策略：计数请求不消耗 token，仅校验模型
+	if err := service.CheckAPIKeyPolicyLimits(apiKey.Policy, service.APIKeyPolicyRequest{Model: parsedReq.Model}); err != nil {
+		status, errType, message := apiKeyPolicyErrorDetails(err)
+		h.errorResponse(c, status, errType, message)
+		return
+	}
+
 	setOpsRequestContext(c, parsedReq.Model, parsedReq.Stream, body)
 
 	// 获取订阅信息（可能为nil）
@@ -1676,3 +1876,31 @@ func (h *GatewayHandler) getUserMsgQueueMode(account *service.Account, parsed *s
 	}
 	return mode
 }
+
+// apiKeyPolicyErrorDetails 将 Key 访问策略错误映射为网关错误响应
+func apiKeyPolicyErrorDetails(err error) (status int, errType, message string) {
+	message = pkgerrors.Message(err)
+	switch pkgerrors.Code(err) {
+	case http.StatusTooManyRequests:
+		return http.StatusTooManyRequests, "rate_limit_error", message
+	case http.StatusForbidden:
+		return http.StatusForbidden, "permission_error", message
+	default:
+		return http.StatusBadRequest, "invalid_request_error", message
+	}
+}
+
+// logAccountScheduleDecision 以与 OpenAI 网关一致的字段输出调度决策。
+func logAccountScheduleDecision(reqLog *zap.Logger, decision service.AccountScheduleDecision) {
+	if reqLog == nil {
+		return
+	}
+	reqLog.Debug("gateway.account_schedule_decision",
+		zap.String("layer", decision.Layer),
+		zap.String("strategy", decision.Strategy),
+		zap.Bool("sticky_session_hit", decision.StickySessionHit),
+		zap.Int("candidate_count", decision.CandidateCount),
+		zap.Int64("latency_ms", decision.LatencyMs),
+		zap.Float64("load_skew", decision.LoadSkew),
+	)
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -292,10 +293,28 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
+	// 账号槽位走优先级等待队列：只有队首才去抢占槽位，槽位释放时队首会被立即唤醒
+	var waiter *service.AccountSlotWaiter
+	var notifyCh <-chan struct{}
+	if slotType == "account" && maxConcurrency > 0 {
+		var userID int64
+		priority := 0
+		if apiKey, ok := middleware2.GetAPIKeyFromContext(c); ok && apiKey != nil {
+			userID = apiKey.UserID
+			priority = service.ResolveQueuePriority(apiKey)
+		}
+		waiter = h.concurrencyService.EnterAccountWaitQueue(id, userID, priority)
+		defer waiter.Leave()
+		notifyCh = waiter.Notify()
+	}
+
 	acquireSlot := func() (*service.AcquireResult, error) {
 		if slotType == "user" {
 			return h.concurrencyService.AcquireUserSlot(ctx, id, maxConcurrency)
 		}
+		if waiter != nil {
+			return waiter.TryAcquire(ctx, maxConcurrency)
+		}
 		return h.concurrencyService.AcquireAccountSlot(ctx, id, maxConcurrency)
 	}
 
@@ -355,6 +374,16 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 			}
 			flusher.Flush()
 
+		case <-notifyCh:
+			// 槽位刚释放或已轮到本请求：立即重试，不打断退避节奏
+			result, err := acquireSlot()
+			if err != nil {
+				return nil, err
+			}
+			if result.Acquired {
+				return result.ReleaseFunc, nil
+			}
+
 		case <-timer.C:
 			// Try to acquire slot
 			result, err := acquireSlot()
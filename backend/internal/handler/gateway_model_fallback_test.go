@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestApplyModelFallback_SkipsStepsRejectedByKeyPolicy(t *testing.T) {
+	c := newCrossPlatformTestContext()
+	h := &GatewayHandler{gatewayService: &service.GatewayService{}}
+	apiKey := &service.APIKey{
+		Group: &service.Group{ID: 7, Platform: service.PlatformAnthropic, ModelFallbackChains: []service.ModelFallbackChain{{
+			Source: "claude-opus-4*",
+			Fallbacks: []service.ModelFallbackStep{
+				{Model: "claude-sonnet-4"},
+				{Model: "gemini-2.5-pro", Platform: service.PlatformAntigravity},
+				{Model: "claude-haiku-4"},
+			},
+		}}},
+		Policy: &service.APIKeyPolicy{
+			DeniedModels:     []string{"claude-sonnet-4"},
+			AllowedPlatforms: []string{service.PlatformAnthropic},
+		},
+	}
+	plan := service.NewModelFallbackPlan(apiKey.Group, "claude-opus-4-1")
+	body := []byte(`{"model":"claude-opus-4-1","max_tokens":16,"messages":[]}`)
+	parsedReq := &service.ParsedRequest{Model: "claude-opus-4-1", Body: body}
+	reqModel := "claude-opus-4-1"
+	var crossPlatform crossPlatformFailoverState
+
+	ok := h.applyModelFallback(c, apiKey, plan, service.ModelFallbackTriggerRateLimited, &body, parsedReq, &reqModel, &crossPlatform, zap.NewNop())
+	require.True(t, ok)
+	require.Equal(t, "claude-haiku-4", reqModel, "被策略拒绝的模型与平台均被跳过")
+	require.Equal(t, "claude-haiku-4", parsedReq.Model)
+	require.Contains(t, string(body), `"claude-haiku-4"`)
+
+	require.False(t, h.applyModelFallback(c, apiKey, plan, service.ModelFallbackTriggerRateLimited, &body, parsedReq, &reqModel, &crossPlatform, zap.NewNop()))
+}
+
+func TestApplyModelFallback_ResetsForcePlatformEachStep(t *testing.T) {
+	c := newCrossPlatformTestContext()
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, service.PlatformAnthropic))
+	h := &GatewayHandler{gatewayService: &service.GatewayService{}}
+	apiKey := &service.APIKey{Group: &service.Group{ID: 7, Platform: service.PlatformAnthropic, ModelFallbackChains: []service.ModelFallbackChain{{
+		Source: "claude-opus-4*",
+		Fallbacks: []service.ModelFallbackStep{
+			{Model: "gemini-2.5-pro", Platform: service.PlatformAntigravity},
+			{Model: "claude-sonnet-4"},
+		},
+	}}}}
+	plan := service.NewModelFallbackPlan(apiKey.Group, "claude-opus-4-1")
+	plan.BaseForcePlatform = service.PlatformAnthropic
+	body := []byte(`{"model":"claude-opus-4-1","messages":[]}`)
+	parsedReq := &service.ParsedRequest{Model: "claude-opus-4-1", Body: body}
+	reqModel := "claude-opus-4-1"
+	var crossPlatform crossPlatformFailoverState
+
+	require.True(t, h.applyModelFallback(c, apiKey, plan, service.ModelFallbackTriggerOverloaded, &body, parsedReq, &reqModel, &crossPlatform, zap.NewNop()))
+	require.Equal(t, service.PlatformAntigravity, c.Request.Context().Value(ctxkey.ForcePlatform))
+
+	require.True(t, h.applyModelFallback(c, apiKey, plan, service.ModelFallbackTriggerOverloaded, &body, parsedReq, &reqModel, &crossPlatform, zap.NewNop()))
+	require.Equal(t, service.PlatformAnthropic, c.Request.Context().Value(ctxkey.ForcePlatform), "未指定平台的步骤恢复降级前的平台")
+}
@@ -183,6 +183,19 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 
 	setOpsRequestContext(c, modelName, stream, body)
 
+	// Key 访问策略：countTokens 不消耗 token，仅校验模型
+	if apiKey.Policy != nil && h.apiKeyService != nil {
+		policyReq := service.APIKeyPolicyRequest{Model: modelName}
+		if action != "countTokens" {
+			policyReq = service.NewAPIKeyPolicyRequest(body, modelName)
+		}
+		if err := h.apiKeyService.CheckPolicyRequest(c.Request.Context(), apiKey, policyReq); err != nil {
+			status, _, message := apiKeyPolicyErrorDetails(err)
+			googleError(c, status, message)
+			return
+		}
+	}
+
 	// Get subscription (may be nil)
 	subscription, _ := middleware.GetSubscriptionFromContext(c)
 
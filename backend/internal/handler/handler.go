@@ -24,10 +24,20 @@ type AdminHandlers struct {
 	System           *admin.SystemHandler
 	Subscription     *admin.SubscriptionHandler
 	Usage            *admin.UsageHandler
+	UsageArchive     *admin.UsageArchiveHandler
+	Partition        *admin.PartitionHandler
+	CostAnomaly      *admin.CostAnomalyHandler
+	KeySharing       *admin.KeySharingHandler
 	UserAttribute    *admin.UserAttributeHandler
 	ErrorPassthrough *admin.ErrorPassthroughHandler
 	APIKey           *admin.AdminAPIKeyHandler
 	ScheduledTest    *admin.ScheduledTestHandler
+	RBAC             *admin.RBACHandler
+	AdminKey         *admin.AdminKeyHandler
+	UserSession      *admin.UserSessionHandler
+	Impersonation    *admin.ImpersonationHandler
+	ShadowMirror     *admin.ShadowMirrorHandler
+	Experiment       *admin.ExperimentHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -46,6 +56,8 @@ type Handlers struct {
 	SoraClient    *SoraClientHandler
 	Setting       *SettingHandler
 	Totp          *TotpHandler
+	OIDC          *OIDCHandler
+	Passkey       *PasskeyHandler
 }
 
 // BuildInfo contains build-time information
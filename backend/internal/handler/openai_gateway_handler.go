@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	pkgerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
@@ -173,6 +174,13 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 
 	setOpsRequestContext(c, reqModel, reqStream, body)
 
+	// Key 访问策略：模型、reasoning/工具与单次请求输入/输出 token 上限（RPM/TPM 由鉴权中间件的限流处理）
+	if err := h.apiKeyService.CheckPolicyRequest(c.Request.Context(), apiKey, service.NewAPIKeyPolicyRequest(body, reqModel)); err != nil {
+		status, errType, message := apiKeyPolicyErrorDetails(err)
+		h.errorResponse(c, status, errType, message)
+		return
+	}
+
 	// 提前校验 function_call_output 是否具备可关联上下文，避免上游 400。
 	if !h.validateFunctionCallOutputRequest(c, body, reqLog) {
 		return
@@ -251,6 +259,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		}
 		reqLog.Debug("openai.account_schedule_decision",
 			zap.String("layer", scheduleDecision.Layer),
+			zap.String("strategy", scheduleDecision.Strategy),
 			zap.Bool("sticky_previous_hit", scheduleDecision.StickyPreviousHit),
 			zap.Bool("sticky_session_hit", scheduleDecision.StickySessionHit),
 			zap.Int("candidate_count", scheduleDecision.CandidateCount),
@@ -510,6 +519,13 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 
 	setOpsRequestContext(c, reqModel, reqStream, body)
 
+	// Key 访问策略：模型、thinking/工具与单次请求输入/输出 token 上限（RPM/TPM 由鉴权中间件的限流处理）
+	if err := h.apiKeyService.CheckPolicyRequest(c.Request.Context(), apiKey, service.NewAPIKeyPolicyRequest(body, reqModel)); err != nil {
+		status, errType, message := apiKeyPolicyErrorDetails(err)
+		h.anthropicErrorResponse(c, status, errType, message)
+		return
+	}
+
 	// 绑定错误透传服务，允许 service 层在非 failover 错误场景复用规则。
 	if h.errorPassthroughService != nil {
 		service.BindErrorPassthroughService(c, h.errorPassthroughService)
@@ -1026,6 +1042,11 @@ func (h *OpenAIGatewayHandler) ResponsesWebSocket(c *gin.Context) {
 	)
 	setOpsRequestContext(c, reqModel, true, firstMessage)
 
+	if err := h.apiKeyService.CheckPolicyRequest(ctx, apiKey, service.NewAPIKeyPolicyRequest(firstMessage, reqModel)); err != nil {
+		closeOpenAIClientWS(wsConn, coderws.StatusPolicyViolation, pkgerrors.Message(err))
+		return
+	}
+
 	var currentUserRelease func()
 	var currentAccountRelease func()
 	releaseTurnSlots := func() {
@@ -288,6 +288,25 @@ func attachOpsRequestBodyToEntry(c *gin.Context, entry *service.OpsInsertErrorLo
 	opsErrorLogSanitized.Add(1)
 }
 
+// applyOpsExperimentFromContext 为错误日志打上请求命中的 A/B 实验与变体
+func applyOpsExperimentFromContext(c *gin.Context, entry *service.OpsInsertErrorLogInput) {
+	if c == nil || entry == nil {
+		return
+	}
+	v, ok := c.Get(service.OpsExperimentKey)
+	if !ok {
+		return
+	}
+	assignment, ok := v.(*service.ExperimentAssignment)
+	if !ok || assignment == nil {
+		return
+	}
+	id := assignment.ExperimentID
+	variant := assignment.Variant.Name
+	entry.ExperimentID = &id
+	entry.ExperimentVariant = &variant
+}
+
 func setOpsSelectedAccount(c *gin.Context, accountID int64, platform ...string) {
 	if c == nil || accountID <= 0 {
 		return
@@ -666,6 +685,17 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 
 		phase := classifyOpsPhase(normalizedType, parsed.Message, parsed.Code)
 		isBusinessLimited := classifyOpsIsBusinessLimited(normalizedType, phase, parsed.Code, status, parsed.Message)
+		severity := classifyOpsSeverity(normalizedType, status)
+
+		// Key/用户/分组 RPM/TPM 本地限流：归为客户端请求阶段的业务限制，不计入上游错误
+		if v, ok := c.Get(service.OpsRequestRateLimitKey); ok {
+			if scope, _ := v.(string); scope != "" {
+				normalizedType = "rate_limit_error"
+				phase = "request"
+				isBusinessLimited = true
+				severity = "P3"
+			}
+		}
 
 		errorOwner := classifyOpsErrorOwner(phase, parsed.Message)
 		errorSource := classifyOpsErrorSource(phase, parsed.Message)
@@ -688,7 +718,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 
 			ErrorPhase:        phase,
 			ErrorType:         normalizedType,
-			Severity:          classifyOpsSeverity(normalizedType, status),
+			Severity:          severity,
 			StatusCode:        status,
 			IsBusinessLimited: isBusinessLimited,
 			IsCountTokens:     isCountTokensRequest(c),
@@ -705,6 +735,7 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			CreatedAt:   time.Now(),
 		}
 		applyOpsLatencyFieldsFromContext(c, entry)
+		applyOpsExperimentFromContext(c, entry)
 
 		// Capture upstream error context set by gateway services (if present).
 		// This does NOT affect the client response; it enriches Ops troubleshooting data.
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// responseCacheCapture 未命中请求的缓存上下文：缓存键与截留的响应
+type responseCacheCapture struct {
+	lookup *service.ResponseCacheLookup
+	writer *captureResponseWriter
+	stream bool
+}
+
+// lookupResponseCache 查询网关响应缓存。命中时回放缓存响应、提交命中计费并返回 hit=true；
+// 未命中时包装 ResponseWriter 截留本次响应，请求成功后由 storeResponseCache 写入缓存。
+func (h *GatewayHandler) lookupResponseCache(c *gin.Context, apiKey *service.APIKey, subscription *service.UserSubscription, experiment *service.ExperimentAssignment, body []byte, stream bool) (capture *responseCacheCapture, hit bool) {
+	lookup := h.responseCacheService.Prepare(apiKey, body, c.GetHeader("anthropic-beta"))
+	if lookup == nil {
+		return nil, false
+	}
+	if strings.EqualFold(strings.TrimSpace(c.GetHeader(service.ResponseCacheHeader)), service.ResponseCacheBypass) {
+		c.Header(service.ResponseCacheHeader, service.ResponseCacheStatusBypass)
+		return nil, false
+	}
+
+	start := time.Now()
+	if entry := h.responseCacheService.Get(c.Request.Context(), lookup); entry != nil && entry.Stream == stream {
+		replayResponseCache(c, entry)
+		h.recordResponseCacheHit(c, lookup, entry, apiKey, subscription, experiment, time.Since(start))
+		return nil, true
+	}
+
+	c.Header(service.ResponseCacheHeader, service.ResponseCacheStatusMiss)
+	writer := newCaptureResponseWriter(c.Writer, lookup.Config.MaxEntryBytes)
+	c.Writer = writer
+	return &responseCacheCapture{lookup: lookup, writer: writer, stream: stream}, false
+}
+
+// replayResponseCache 原样回放缓存的 JSON 或 SSE 响应
+func replayResponseCache(c *gin.Context, entry *service.ResponseCacheEntry) {
+	contentType := entry.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+		if entry.Stream {
+			contentType = "text/event-stream"
+		}
+	}
+	c.Header(service.ResponseCacheHeader, service.ResponseCacheStatusHit)
+	if entry.Stream {
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+	}
+	c.Data(http.StatusOK, contentType, entry.Body)
+}
+
+// recordResponseCacheHit 按缓存命中计费类型记录使用量（不计入上游账号用量）
+func (h *GatewayHandler) recordResponseCacheHit(c *gin.Context, lookup *service.ResponseCacheLookup, entry *service.ResponseCacheEntry, apiKey *service.APIKey, subscription *service.UserSubscription, experiment *service.ExperimentAssignment, duration time.Duration) {
+	result := &service.ForwardResult{
+		RequestID: "resp-cache-" + uuid.NewString(),
+		Usage:     entry.Usage,
+		Model:     entry.Model,
+		Stream:    entry.Stream,
+		Duration:  duration,
+	}
+	account := &service.Account{ID: entry.AccountID}
+	hit := &service.ResponseCacheHit{PriceRatio: lookup.Config.HitPriceRatio}
+	userAgent := c.GetHeader("User-Agent")
+	clientIP := ip.GetClientIP(c)
+
+	h.submitUsageRecordTask(func(ctx context.Context) {
+		if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
+			Result:           result,
+			APIKey:           apiKey,
+			User:             apiKey.User,
+			Account:          account,
+			Subscription:     subscription,
+			UserAgent:        userAgent,
+			IPAddress:        clientIP,
+			APIKeyService:    h.apiKeyService,
+			Experiment:       experiment,
+			ResponseCacheHit: hit,
+		}); err != nil {
+			logger.L().With(
+				zap.String("component", "handler.gateway.response_cache"),
+				zap.Int64("api_key_id", apiKey.ID),
+				zap.String("model", entry.Model),
+			).Error("gateway.record_usage_failed", zap.Error(err))
+		}
+	})
+}
+
+// storeResponseCache 请求成功且响应完整截留时写入缓存；须在提交使用量记录之前调用
+func (h *GatewayHandler) storeResponseCache(c *gin.Context, capture *responseCacheCapture, account *service.Account, result *service.ForwardResult) {
+	if capture == nil || result == nil || result.ClientDisconnect {
+		return
+	}
+	if capture.writer.truncated || capture.writer.Status() != http.StatusOK || result.Usage.OutputTokens == 0 {
+		return
+	}
+	h.responseCacheService.Store(c.Request.Context(), capture.lookup, &service.ResponseCacheEntry{
+		Body:        bytes.Clone(capture.writer.buf.Bytes()),
+		ContentType: capture.writer.Header().Get("Content-Type"),
+		Stream:      capture.stream,
+		Model:       result.Model,
+		Usage:       result.Usage,
+		AccountID:   account.ID,
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureResponseWriter 在写回客户端的同时截留响应内容，最多 limit 字节，超出部分标记为截断
+type captureResponseWriter struct {
+	gin.ResponseWriter
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func newCaptureResponseWriter(w gin.ResponseWriter, limit int) *captureResponseWriter {
+	return &captureResponseWriter{ResponseWriter: w, limit: limit}
+}
+
+func (w *captureResponseWriter) capture(n int, write func(n int)) {
+	remaining := w.limit - w.buf.Len()
+	if n > remaining {
+		w.truncated = true
+	}
+	if remaining > 0 {
+		write(min(n, remaining))
+	}
+}
+
+func (w *captureResponseWriter) Write(b []byte) (int, error) {
+	w.capture(len(b), func(n int) { _, _ = w.buf.Write(b[:n]) })
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureResponseWriter) WriteString(s string) (int, error) {
+	w.capture(len(s), func(n int) { _, _ = w.buf.WriteString(s[:n]) })
+	return w.ResponseWriter.WriteString(s)
+}
@@ -39,6 +39,7 @@ func (h *SettingHandler) GetPublicSettings(c *gin.Context) {
 		PasswordResetEnabled:             settings.PasswordResetEnabled,
 		InvitationCodeEnabled:            settings.InvitationCodeEnabled,
 		TotpEnabled:                      settings.TotpEnabled,
+		PasskeyEnabled:                   settings.PasskeyEnabled,
 		TurnstileEnabled:                 settings.TurnstileEnabled,
 		TurnstileSiteKey:                 settings.TurnstileSiteKey,
 		SiteName:                         settings.SiteName,
@@ -53,7 +54,16 @@ func (h *SettingHandler) GetPublicSettings(c *gin.Context) {
 		PurchaseSubscriptionURL:          settings.PurchaseSubscriptionURL,
 		CustomMenuItems:                  dto.ParseUserVisibleMenuItems(settings.CustomMenuItems),
 		LinuxDoOAuthEnabled:              settings.LinuxDoOAuthEnabled,
+		OIDCProviders:                    oidcPublicProvidersToDTO(settings.OIDCProviders),
 		SoraClientEnabled:                settings.SoraClientEnabled,
 		Version:                          h.version,
 	})
 }
+
+func oidcPublicProvidersToDTO(providers []service.OIDCPublicProvider) []dto.OIDCPublicProvider {
+	out := make([]dto.OIDCPublicProvider, 0, len(providers))
+	for _, p := range providers {
+		out = append(out, dto.OIDCPublicProvider{Key: p.Key, Name: p.Name})
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shadowMirrorCaptureLimit 主响应捕获上限，仅用于与影子响应计算相似度
+const shadowMirrorCaptureLimit = 256 * 1024
+
+// shadowMirrorCapture 被采样请求的镜像上下文：原始请求体/模型与主响应捕获
+type shadowMirrorCapture struct {
+	config  service.ShadowMirrorConfig
+	groupID int64
+	body    []byte
+	model   string
+	stream  bool
+	start   time.Time
+	writer  *captureResponseWriter
+}
+
+// startShadowMirror 按分组采样率决定是否镜像当前请求；命中时包装 ResponseWriter 截留主响应
+func (h *GatewayHandler) startShadowMirror(c *gin.Context, group *service.Group, body []byte, model string, stream bool) *shadowMirrorCapture {
+	if !h.shadowMirrorService.ShouldMirror(group) {
+		return nil
+	}
+	writer := newCaptureResponseWriter(c.Writer, shadowMirrorCaptureLimit)
+	c.Writer = writer
+	return &shadowMirrorCapture{
+		config:  *group.ShadowMirror,
+		groupID: group.ID,
+		body:    bytes.Clone(body),
+		model:   model,
+		stream:  stream,
+		start:   time.Now(),
+		writer:  writer,
+	}
+}
+
+// submitShadowMirror 主请求成功后提交影子请求（影子请求不计费，不影响主响应）
+func (h *GatewayHandler) submitShadowMirror(c *gin.Context, capture *shadowMirrorCapture, apiKey *service.APIKey, account *service.Account, result *service.ForwardResult) {
+	if capture == nil {
+		return
+	}
+	h.shadowMirrorService.Submit(&service.ShadowMirrorRequest{
+		Config:           capture.config,
+		GroupID:          capture.groupID,
+		APIKeyID:         apiKey.ID,
+		Body:             capture.body,
+		Model:            capture.model,
+		Stream:           capture.stream,
+		Headers:          c.Request.Header.Clone(),
+		PrimaryAccountID: account.ID,
+		PrimaryStatus:    capture.writer.Status(),
+		PrimaryLatency:   time.Since(capture.start),
+		PrimaryResult:    result,
+		PrimaryOutput:    bytes.Clone(capture.writer.buf.Bytes()),
+	})
+}
@@ -985,8 +985,8 @@ func (r *stubAPIKeyRepoForHandler) GetByID(_ context.Context, id int64) (*servic
 	return nil, fmt.Errorf("api key not found: %d", id)
 }
 func (r *stubAPIKeyRepoForHandler) Create(context.Context, *service.APIKey) error { return nil }
-func (r *stubAPIKeyRepoForHandler) GetKeyAndOwnerID(_ context.Context, _ int64) (string, int64, error) {
-	return "", 0, nil
+func (r *stubAPIKeyRepoForHandler) GetKeyAndOwnerID(_ context.Context, _ int64) ([]string, int64, error) {
+	return nil, 0, nil
 }
 func (r *stubAPIKeyRepoForHandler) GetByKey(context.Context, string) (*service.APIKey, error) {
 	return nil, nil
@@ -1026,6 +1026,12 @@ func (r *stubAPIKeyRepoForHandler) ListKeysByUserID(context.Context, int64) ([]s
 func (r *stubAPIKeyRepoForHandler) ListKeysByGroupID(context.Context, int64) ([]string, error) {
 	return nil, nil
 }
+func (r *stubAPIKeyRepoForHandler) Rotate(context.Context, service.APIKeyRotation) error {
+	return nil
+}
+func (r *stubAPIKeyRepoForHandler) ClearExpiredPreviousKeys(context.Context, time.Time) ([]string, error) {
+	return nil, nil
+}
 func (r *stubAPIKeyRepoForHandler) IncrementQuotaUsed(_ context.Context, _ int64, _ float64) (float64, error) {
 	return 0, nil
 }
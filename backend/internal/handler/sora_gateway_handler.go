@@ -171,6 +171,13 @@ func (h *SoraGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// Key 访问策略：Sora 按次计费，仅校验模型
+	if err := service.CheckAPIKeyPolicyLimits(apiKey.Policy, service.APIKeyPolicyRequest{Model: reqModel}); err != nil {
+		status, errType, message := apiKeyPolicyErrorDetails(err)
+		h.errorResponse(c, status, errType, message)
+		return
+	}
+
 	streamStarted := false
 	subscription, _ := middleware2.GetSubscriptionFromContext(c)
 
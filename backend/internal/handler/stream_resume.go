@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// beginStreamResume 分组开启续传的流式请求创建事件记录器并注入请求上下文，以请求 ID 作为续传 ID
+func (h *GatewayHandler) beginStreamResume(c *gin.Context, apiKey *service.APIKey, stream bool) *service.StreamResumeRecorder {
+	if !stream || apiKey == nil || apiKey.Group == nil || !apiKey.Group.StreamResume {
+		return nil
+	}
+	requestID, _ := c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+	recorder := h.streamResumeService.Begin(requestID, apiKey.ID)
+	if recorder == nil {
+		return nil
+	}
+	c.Request = c.Request.WithContext(service.WithStreamResumeRecorder(c.Request.Context(), recorder))
+	return recorder
+}
+
+// tryResumeStream 请求携带的 Last-Event-ID 对应缓冲中仍存在的流时回放缓冲事件并返回 true；
+// 其它格式或已不存在的流按普通请求处理，避免普通生成请求因客户端残留的事件 ID 被拒绝
+func (h *GatewayHandler) tryResumeStream(c *gin.Context, apiKey *service.APIKey) bool {
+	if apiKey.Group == nil || !apiKey.Group.StreamResume {
+		return false
+	}
+	lastEventID := strings.TrimSpace(c.GetHeader(service.StreamResumeLastEventIDHeader))
+	if _, _, ok := service.ParseStreamResumeEventID(lastEventID); !ok {
+		return false
+	}
+	cursor := h.streamResumeService.Resume(apiKey.ID, lastEventID)
+	if cursor == nil {
+		return false
+	}
+	h.serveStreamResume(c, cursor)
+	return true
+}
+
+// ResumeStream handles resuming a buffered stream after a dropped connection
+// GET /v1/messages/resume
+func (h *GatewayHandler) ResumeStream(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+	lastEventID := strings.TrimSpace(c.GetHeader(service.StreamResumeLastEventIDHeader))
+	if lastEventID == "" {
+		lastEventID = strings.TrimSpace(c.Query("last_event_id"))
+	}
+	if lastEventID == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Last-Event-ID header or last_event_id query is required")
+		return
+	}
+	cursor := h.streamResumeService.Resume(apiKey.ID, lastEventID)
+	if cursor == nil {
+		h.errorResponse(c, http.StatusNotFound, "not_found_error", "Stream is no longer available for resumption")
+		return
+	}
+	h.serveStreamResume(c, cursor)
+}
+
+// serveStreamResume 回放游标之后的缓冲事件；原始流仍在进行时持续跟随直到结束
+func (h *GatewayHandler) serveStreamResume(c *gin.Context, cursor *service.StreamResumeCursor) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header(service.StreamResumeIDHeader, cursor.ID())
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	for {
+		events, wait, done := cursor.Next()
+		for _, event := range events {
+			if _, err := io.WriteString(c.Writer, event); err != nil {
+				return
+			}
+		}
+		if len(events) > 0 {
+			c.Writer.Flush()
+			continue
+		}
+		if done {
+			c.Writer.Flush()
+			return
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryResumeStream_ReplaysBufferedEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewStreamResumeService(nil)
+	h := &GatewayHandler{streamResumeService: svc}
+	apiKey := &service.APIKey{ID: 3, Group: &service.Group{ID: 1, StreamResume: true}}
+
+	recorder := svc.Begin("req-1", apiKey.ID)
+	recorder.Append("event: message_start\ndata: {}\n\n")
+	recorder.Append("event: message_stop\ndata: {}\n\n")
+	recorder.Finish()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set("Last-Event-ID", "req-1:1")
+	require.True(t, h.tryResumeStream(c, apiKey))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "req-1", w.Header().Get(service.StreamResumeIDHeader))
+	require.Equal(t, "id: req-1:2\nevent: message_stop\ndata: {}\n\n", w.Body.String())
+
+	// 过期或不存在的流按普通请求处理，不拦截生成请求
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set("Last-Event-ID", "req-9:1")
+	require.False(t, h.tryResumeStream(c, apiKey))
+	require.False(t, c.Writer.Written())
+
+	// 专用续传端点对不存在的流返回 404
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/messages/resume", nil)
+	c.Request.Header.Set("Last-Event-ID", "req-9:1")
+	c.Set(string(middleware.ContextKeyAPIKey), apiKey)
+	h.ResumeStream(c)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// 未开启续传的分组或非本网关格式的事件 ID 按普通请求处理
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	c.Request.Header.Set("Last-Event-ID", "opaque")
+	require.False(t, h.tryResumeStream(c, apiKey))
+	c.Request.Header.Set("Last-Event-ID", "req-1:1")
+	require.False(t, h.tryResumeStream(c, &service.APIKey{ID: 3, Group: &service.Group{ID: 1}}))
+}
+
+func TestBeginStreamResume_OnlyForOptedInStreamingRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &GatewayHandler{streamResumeService: service.NewStreamResumeService(nil)}
+	apiKey := &service.APIKey{ID: 3, Group: &service.Group{ID: 1, StreamResume: true}}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	require.Nil(t, h.beginStreamResume(c, apiKey, false))
+
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.ClientRequestID, "req-1"))
+	recorder := h.beginStreamResume(c, apiKey, true)
+	require.NotNil(t, recorder)
+	require.Equal(t, "req-1", recorder.ID())
+	require.Same(t, recorder, service.StreamResumeRecorderFromContext(c.Request.Context()))
+
+	require.Nil(t, h.beginStreamResume(c, &service.APIKey{ID: 3, Group: &service.Group{ID: 1}}, true))
+}
@@ -27,10 +27,20 @@ func ProvideAdminHandlers(
 	systemHandler *admin.SystemHandler,
 	subscriptionHandler *admin.SubscriptionHandler,
 	usageHandler *admin.UsageHandler,
+	usageArchiveHandler *admin.UsageArchiveHandler,
+	partitionHandler *admin.PartitionHandler,
+	costAnomalyHandler *admin.CostAnomalyHandler,
+	keySharingHandler *admin.KeySharingHandler,
 	userAttributeHandler *admin.UserAttributeHandler,
 	errorPassthroughHandler *admin.ErrorPassthroughHandler,
 	apiKeyHandler *admin.AdminAPIKeyHandler,
 	scheduledTestHandler *admin.ScheduledTestHandler,
+	rbacHandler *admin.RBACHandler,
+	adminKeyHandler *admin.AdminKeyHandler,
+	userSessionHandler *admin.UserSessionHandler,
+	impersonationHandler *admin.ImpersonationHandler,
+	shadowMirrorHandler *admin.ShadowMirrorHandler,
+	experimentHandler *admin.ExperimentHandler,
 ) *AdminHandlers {
 	return &AdminHandlers{
 		Dashboard:        dashboardHandler,
@@ -51,10 +61,20 @@ func ProvideAdminHandlers(
 		System:           systemHandler,
 		Subscription:     subscriptionHandler,
 		Usage:            usageHandler,
+		UsageArchive:     usageArchiveHandler,
+		Partition:        partitionHandler,
+		CostAnomaly:      costAnomalyHandler,
+		KeySharing:       keySharingHandler,
 		UserAttribute:    userAttributeHandler,
 		ErrorPassthrough: errorPassthroughHandler,
 		APIKey:           apiKeyHandler,
 		ScheduledTest:    scheduledTestHandler,
+		RBAC:             rbacHandler,
+		AdminKey:         adminKeyHandler,
+		UserSession:      userSessionHandler,
+		Impersonation:    impersonationHandler,
+		ShadowMirror:     shadowMirrorHandler,
+		Experiment:       experimentHandler,
 	}
 }
 
@@ -84,6 +104,8 @@ func ProvideHandlers(
 	soraClientHandler *SoraClientHandler,
 	settingHandler *SettingHandler,
 	totpHandler *TotpHandler,
+	oidcHandler *OIDCHandler,
+	passkeyHandler *PasskeyHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -102,6 +124,8 @@ func ProvideHandlers(
 		SoraClient:    soraClientHandler,
 		Setting:       settingHandler,
 		Totp:          totpHandler,
+		OIDC:          oidcHandler,
+		Passkey:       passkeyHandler,
 	}
 }
 
@@ -119,6 +143,8 @@ var ProviderSet = wire.NewSet(
 	NewOpenAIGatewayHandler,
 	NewSoraGatewayHandler,
 	NewTotpHandler,
+	NewOIDCHandler,
+	NewPasskeyHandler,
 	ProvideSettingHandler,
 
 	// Admin handlers
@@ -140,6 +166,16 @@ var ProviderSet = wire.NewSet(
 	ProvideSystemHandler,
 	admin.NewSubscriptionHandler,
 	admin.NewUsageHandler,
+	admin.NewUsageArchiveHandler,
+	admin.NewPartitionHandler,
+	admin.NewCostAnomalyHandler,
+	admin.NewKeySharingHandler,
+	admin.NewRBACHandler,
+	admin.NewAdminKeyHandler,
+	admin.NewUserSessionHandler,
+	admin.NewImpersonationHandler,
+	admin.NewShadowMirrorHandler,
+	admin.NewExperimentHandler,
 	admin.NewUserAttributeHandler,
 	admin.NewErrorPassthroughHandler,
 	admin.NewAdminAPIKeyHandler,
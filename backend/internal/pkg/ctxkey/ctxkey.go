@@ -37,6 +37,9 @@ const (
 	// Group 认证后的分组信息，由 API Key 认证中间件设置
 	Group Key = "ctx_group"
 
+	// QueuePriority 请求的账号排队优先级（0-9），由 API Key 认证中间件设置
+	QueuePriority Key = "ctx_queue_priority"
+
 	// IsMaxTokensOneHaikuRequest 标识当前请求是否为 max_tokens=1 + haiku 模型的探测请求
 	// 用于 ClaudeCodeOnly 验证绕过（绕过 system prompt 检查，但仍需验证 User-Agent）
 	IsMaxTokensOneHaikuRequest Key = "ctx_is_max_tokens_one_haiku"
@@ -55,4 +58,14 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// SessionClient 登录/刷新 Token 时的客户端信息（IP、User-Agent），用于记录用户会话设备
+	SessionClient Key = "ctx_session_client"
+
+	// StreamResumeRecorder 流式断线续传的事件缓冲记录器，由 handler 在分组开启续传时设置
+	StreamResumeRecorder Key = "ctx_stream_resume_recorder"
+
+	// ModelFallbackOnPromptTooLong 分组降级链可由 context_too_long 触发时由 handler 设置，
+	// 上游 prompt too long 的 400 以 *PromptTooLongError 返回而不直接写回客户端
+	ModelFallbackOnPromptTooLong Key = "ctx_model_fallback_on_prompt_too_long"
 )
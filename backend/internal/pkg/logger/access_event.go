@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// AccessEventComponent 访问事件使用的组件名，外部 sink 据此区分访问事件与普通日志
+	AccessEventComponent = "access"
+	// AccessEventSchema 访问事件结构版本；字段只增不改，破坏性变更需升级版本号
+	AccessEventSchema = "sub2api.access.v1"
+)
+
+var accessEventsEnabled atomic.Bool
+
+// AccessEvent 单个 HTTP 请求的访问事件（不含请求/响应体）
+type AccessEvent struct {
+	Time            time.Time
+	RequestID       string
+	ClientRequestID string
+	Method          string
+	Path            string
+	Route           string
+	Protocol        string
+	StatusCode      int
+	LatencyMs       int64
+	ClientIP        string
+	UserAgent       string
+	RequestBytes    int64
+	ResponseBytes   int64
+	UserID          int64
+	APIKeyID        int64
+	AdminAPIKeyID   int64
+	GroupID         int64
+	AccountID       int64
+	Platform        string
+	Model           string
+}
+
+// SetAccessEventsEnabled 开启/关闭访问事件输出（由配置了 access_events 的 sink 启用）
+func SetAccessEventsEnabled(enabled bool) {
+	accessEventsEnabled.Store(enabled)
+}
+
+// AccessEventsEnabled 是否需要输出访问事件
+func AccessEventsEnabled() bool {
+	return accessEventsEnabled.Load()
+}
+
+// Fields 按稳定结构展开为字段；未知的 ID 输出为 0，字符串输出为空串，保证字段集合固定。
+func (e *AccessEvent) Fields() map[string]any {
+	return map[string]any{
+		"schema":            AccessEventSchema,
+		"component":         AccessEventComponent,
+		"request_id":        e.RequestID,
+		"client_request_id": e.ClientRequestID,
+		"method":            e.Method,
+		"path":              e.Path,
+		"route":             e.Route,
+		"protocol":          e.Protocol,
+		"status_code":       e.StatusCode,
+		"latency_ms":        e.LatencyMs,
+		"client_ip":         e.ClientIP,
+		"user_agent":        e.UserAgent,
+		"request_bytes":     e.RequestBytes,
+		"response_bytes":    e.ResponseBytes,
+		"user_id":           e.UserID,
+		"api_key_id":        e.APIKeyID,
+		"admin_api_key_id":  e.AdminAPIKeyID,
+		"group_id":          e.GroupID,
+		"account_id":        e.AccountID,
+		"platform":          e.Platform,
+		"model":             e.Model,
+	}
+}
+
+// WriteAccessEvent 将访问事件直接写入 sink；未启用访问事件时忽略。
+func WriteAccessEvent(event *AccessEvent) {
+	if event == nil || !AccessEventsEnabled() {
+		return
+	}
+	sink := loadSink()
+	if sink == nil {
+		return
+	}
+	ts := event.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	sink.WriteLogEvent(&LogEvent{
+		Time:       ts,
+		Level:      "info",
+		Component:  AccessEventComponent,
+		Message:    "http access",
+		LoggerName: AccessEventComponent,
+		Fields:     event.Fields(),
+	})
+}
+
+// IsAccessEvent 判断日志事件是否为访问事件
+func IsAccessEvent(event *LogEvent) bool {
+	return event != nil && event.Component == AccessEventComponent
+}
@@ -186,6 +186,8 @@ type BatchAPIKeyUsageStats struct {
 	APIKeyID        int64   `json:"api_key_id"`
 	TodayActualCost float64 `json:"today_actual_cost"`
 	TotalActualCost float64 `json:"total_actual_cost"`
+	// PromptCacheSavings 统计区间内自动缓存断点带来的节省金额
+	PromptCacheSavings float64 `json:"prompt_cache_savings"`
 }
 
 // AccountUsageHistory represents daily usage history for an account
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const adminKeySelectColumns = `
+	id, name, key_prefix, key_hash, scopes, ip_allowlist, expires_at,
+	last_used_at, last_used_ip, revoked_at, created_by, created_at, updated_at
+`
+
+type adminKeyRepository struct {
+	sql sqlExecutor
+}
+
+// NewAdminKeyRepository 创建管理员 API Key 仓储
+func NewAdminKeyRepository(sqlDB *sql.DB) service.AdminKeyRepository {
+	return &adminKeyRepository{sql: sqlDB}
+}
+
+func (r *adminKeyRepository) List(ctx context.Context) ([]service.AdminKey, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+adminKeySelectColumns+" FROM admin_api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AdminKey, 0)
+	for rows.Next() {
+		key, err := scanAdminKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *key)
+	}
+	return out, rows.Err()
+}
+
+func (r *adminKeyRepository) GetByID(ctx context.Context, id int64) (*service.AdminKey, error) {
+	return r.getOne(ctx, "SELECT "+adminKeySelectColumns+" FROM admin_api_keys WHERE id = $1", id)
+}
+
+func (r *adminKeyRepository) GetByHash(ctx context.Context, keyHash string) (*service.AdminKey, error) {
+	return r.getOne(ctx, "SELECT "+adminKeySelectColumns+" FROM admin_api_keys WHERE key_hash = $1", keyHash)
+}
+
+func (r *adminKeyRepository) getOne(ctx context.Context, query string, args ...any) (*service.AdminKey, error) {
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	key, err := scanAdminKey(rows)
+	if err != nil {
+		return nil, err
+	}
+	return key, rows.Err()
+}
+
+func (r *adminKeyRepository) Create(ctx context.Context, key *service.AdminKey) error {
+	scopes, allowlist, err := marshalAdminKeyLists(key)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO admin_api_keys (name, key_prefix, key_hash, scopes, ip_allowlist, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{key.Name, key.KeyPrefix, key.KeyHash, scopes, allowlist, key.ExpiresAt, key.CreatedBy},
+		&key.ID, &key.CreatedAt, &key.UpdatedAt,
+	)
+}
+
+func (r *adminKeyRepository) Update(ctx context.Context, key *service.AdminKey) error {
+	scopes, allowlist, err := marshalAdminKeyLists(key)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE admin_api_keys
+		SET name = $2, scopes = $3, ip_allowlist = $4, expires_at = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{key.ID, key.Name, scopes, allowlist, key.ExpiresAt},
+		&key.UpdatedAt,
+	)
+}
+
+func (r *adminKeyRepository) UpdateSecret(ctx context.Context, id int64, keyHash, keyPrefix string) error {
+	_, err := r.sql.ExecContext(ctx,
+		"UPDATE admin_api_keys SET key_hash = $2, key_prefix = $3, updated_at = NOW() WHERE id = $1",
+		id, keyHash, keyPrefix,
+	)
+	return err
+}
+
+func (r *adminKeyRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.sql.ExecContext(ctx,
+		"UPDATE admin_api_keys SET revoked_at = $2, updated_at = NOW() WHERE id = $1 AND revoked_at IS NULL",
+		id, at,
+	)
+	return err
+}
+
+func (r *adminKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time, clientIP string) error {
+	_, err := r.sql.ExecContext(ctx,
+		"UPDATE admin_api_keys SET last_used_at = $2, last_used_ip = $3 WHERE id = $1",
+		id, at, clientIP,
+	)
+	return err
+}
+
+func marshalAdminKeyLists(key *service.AdminKey) ([]byte, []byte, error) {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal admin key scopes: %w", err)
+	}
+	allowlist := key.IPAllowlist
+	if allowlist == nil {
+		allowlist = []string{}
+	}
+	allowlistJSON, err := json.Marshal(allowlist)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal admin key ip allowlist: %w", err)
+	}
+	return scopes, allowlistJSON, nil
+}
+
+func scanAdminKey(rows *sql.Rows) (*service.AdminKey, error) {
+	var (
+		key        service.AdminKey
+		scopes     []byte
+		allowlist  []byte
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+		createdBy  sql.NullInt64
+	)
+	if err := rows.Scan(
+		&key.ID, &key.Name, &key.KeyPrefix, &key.KeyHash, &scopes, &allowlist, &expiresAt,
+		&lastUsedAt, &key.LastUsedIP, &revokedAt, &createdBy, &key.CreatedAt, &key.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, fmt.Errorf("unmarshal admin key scopes: %w", err)
+	}
+	if err := json.Unmarshal(allowlist, &key.IPAllowlist); err != nil {
+		return nil, fmt.Errorf("unmarshal admin key ip allowlist: %w", err)
+	}
+	if key.IPAllowlist == nil {
+		key.IPAllowlist = []string{}
+	}
+	key.ExpiresAt = nullTimePtr(expiresAt)
+	key.LastUsedAt = nullTimePtr(lastUsedAt)
+	key.RevokedAt = nullTimePtr(revokedAt)
+	if createdBy.Valid {
+		v := createdBy.Int64
+		key.CreatedBy = &v
+	}
+	return &key, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
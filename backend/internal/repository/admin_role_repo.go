@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type adminRoleRepository struct {
+	sql sqlExecutor
+}
+
+// NewAdminRoleRepository 创建管理角色仓储
+func NewAdminRoleRepository(sqlDB *sql.DB) service.AdminRoleRepository {
+	return &adminRoleRepository{sql: sqlDB}
+}
+
+const adminRoleSelectColumns = `id, key, name, description, permissions, created_at, updated_at`
+
+func (r *adminRoleRepository) ListRoles(ctx context.Context) ([]service.AdminRole, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+adminRoleSelectColumns+" FROM admin_roles ORDER BY key")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AdminRole, 0)
+	for rows.Next() {
+		role, err := scanAdminRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *role)
+	}
+	return out, rows.Err()
+}
+
+func (r *adminRoleRepository) GetRole(ctx context.Context, key string) (*service.AdminRole, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+adminRoleSelectColumns+" FROM admin_roles WHERE key = $1", key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	role, err := scanAdminRole(rows)
+	if err != nil {
+		return nil, err
+	}
+	return role, rows.Err()
+}
+
+func (r *adminRoleRepository) CreateRole(ctx context.Context, role *service.AdminRole) error {
+	payload, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return fmt.Errorf("marshal admin role permissions: %w", err)
+	}
+	query := `
+		INSERT INTO admin_roles (key, name, description, permissions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err = scanSingleRow(ctx, r.sql, query,
+		[]any{role.Key, role.Name, role.Description, payload},
+		&role.ID, &role.CreatedAt, &role.UpdatedAt,
+	)
+	if isUniqueConstraintViolation(err) {
+		return service.ErrAdminRoleExists
+	}
+	return err
+}
+
+func (r *adminRoleRepository) UpdateRole(ctx context.Context, role *service.AdminRole) error {
+	payload, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return fmt.Errorf("marshal admin role permissions: %w", err)
+	}
+	query := `
+		UPDATE admin_roles
+		SET name = $2, description = $3, permissions = $4, updated_at = NOW()
+		WHERE key = $1
+		RETURNING updated_at
+	`
+	err = scanSingleRow(ctx, r.sql, query,
+		[]any{role.Key, role.Name, role.Description, payload},
+		&role.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrAdminRoleNotFound
+	}
+	return err
+}
+
+func (r *adminRoleRepository) DeleteRole(ctx context.Context, key string) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, "DELETE FROM admin_roles WHERE key = $1", key)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *adminRoleRepository) CountAssignments(ctx context.Context, roleKey string) (int, error) {
+	var count int
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM admin_role_assignments WHERE role_key = $1", []any{roleKey}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *adminRoleRepository) GetAssignment(ctx context.Context, userID int64) (string, error) {
+	var roleKey string
+	err := scanSingleRow(ctx, r.sql, "SELECT role_key FROM admin_role_assignments WHERE user_id = $1", []any{userID}, &roleKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return roleKey, nil
+}
+
+func (r *adminRoleRepository) ListAssignments(ctx context.Context) ([]service.AdminRoleAssignment, error) {
+	query := `
+		SELECT a.user_id, u.email, u.username, a.role_key, a.assigned_by, a.updated_at
+		FROM admin_role_assignments a
+		JOIN users u ON u.id = a.user_id
+		WHERE u.deleted_at IS NULL
+		ORDER BY a.user_id
+	`
+	rows, err := r.sql.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AdminRoleAssignment, 0)
+	for rows.Next() {
+		var (
+			item       service.AdminRoleAssignment
+			assignedBy sql.NullInt64
+		)
+		if err := rows.Scan(&item.UserID, &item.Email, &item.Username, &item.RoleKey, &assignedBy, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if assignedBy.Valid {
+			v := assignedBy.Int64
+			item.AssignedBy = &v
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (r *adminRoleRepository) SetAssignment(ctx context.Context, userID int64, roleKey string, assignedBy int64) error {
+	var actor any
+	if assignedBy > 0 {
+		actor = assignedBy
+	}
+	_, err := r.sql.ExecContext(ctx, `
+		INSERT INTO admin_role_assignments (user_id, role_key, assigned_by, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET role_key = EXCLUDED.role_key, assigned_by = EXCLUDED.assigned_by, updated_at = NOW()
+	`, userID, roleKey, actor)
+	return err
+}
+
+func (r *adminRoleRepository) DeleteAssignment(ctx context.Context, userID int64) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, "DELETE FROM admin_role_assignments WHERE user_id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanAdminRole(rows *sql.Rows) (*service.AdminRole, error) {
+	var (
+		role    service.AdminRole
+		payload []byte
+	)
+	if err := rows.Scan(&role.ID, &role.Key, &role.Name, &role.Description, &payload, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &role.Permissions); err != nil {
+		return nil, fmt.Errorf("unmarshal admin role permissions: %w", err)
+	}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+	return &role, nil
+}
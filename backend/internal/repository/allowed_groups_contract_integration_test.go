@@ -112,7 +112,7 @@ func TestGroupRepository_DeleteCascade_RemovesAllowedGroupsAndClearsApiKeys(t *t
 
 	key := &service.APIKey{
 		UserID:  u.ID,
-		Key:     uniqueTestValue(t, "sk-test-delete-cascade"),
+		KeyHash: uniqueTestValue(t, "sk-test-delete-cascade"),
 		Name:    "test key",
 		GroupID: &targetGroup.ID,
 		Status:  service.StatusActive,
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const apiKeyHashBackfillBatchSize = 500
+
+// backfillAPIKeyHashes 将旧版明文存储的 API Key（含已软删除的记录）转换为 HMAC 摘要与展示前缀。
+// 摘要依赖 security_secrets 中的服务端密钥，无法在 SQL 迁移中完成，因此在启动阶段执行；
+// 按 id 分批处理，条件更新保证多实例同时启动时的幂等性。
+func backfillAPIKeyHashes(ctx context.Context, db *sql.DB, secret string) error {
+	if db == nil {
+		return fmt.Errorf("nil sql db")
+	}
+	pattern := service.APIKeyHashPrefix + "%"
+	var lastID int64
+	converted := 0
+	for {
+		rows, err := db.QueryContext(ctx, `
+			SELECT id, key FROM api_keys
+			WHERE id > $1 AND key NOT LIKE $2
+			ORDER BY id
+			LIMIT $3`, lastID, pattern, apiKeyHashBackfillBatchSize)
+		if err != nil {
+			return fmt.Errorf("query plaintext api keys: %w", err)
+		}
+		type legacyKey struct {
+			id  int64
+			key string
+		}
+		batch := make([]legacyKey, 0, apiKeyHashBackfillBatchSize)
+		for rows.Next() {
+			var item legacyKey
+			if err := rows.Scan(&item.id, &item.key); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan plaintext api key: %w", err)
+			}
+			batch = append(batch, item)
+		}
+		if err := rows.Close(); err != nil {
+			return fmt.Errorf("close plaintext api key rows: %w", err)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate plaintext api keys: %w", err)
+		}
+
+		for _, item := range batch {
+			lastID = item.id
+			if _, err := db.ExecContext(ctx, `
+				UPDATE api_keys SET key = $1, key_prefix = $2
+				WHERE id = $3 AND key = $4`,
+				service.HashAPIKey(secret, item.key), service.APIKeyDisplayPrefix(item.key), item.id, item.key); err != nil {
+				return fmt.Errorf("hash api key %d: %w", item.id, err)
+			}
+			converted++
+		}
+		if len(batch) < apiKeyHashBackfillBatchSize {
+			break
+		}
+	}
+	if converted > 0 {
+		log.Printf("[Bootstrap] Hashed %d plaintext API keys", converted)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillAPIKeyHashes(t *testing.T) {
+	ctx := context.Background()
+	client := testEntClient(t)
+	const secret = "backfill-test-secret-0123456789abcdef"
+
+	u := createEntUser(t, ctx, client, uniqueSoftDeleteValue(t, "backfill-user")+"@example.com")
+	plaintext := uniqueSoftDeleteValue(t, "sk-legacy-plaintext")
+	legacy, err := client.APIKey.Create().
+		SetUserID(u.ID).
+		SetKey(plaintext).
+		SetName("legacy").
+		Save(ctx)
+	require.NoError(t, err)
+
+	hashed := service.HashAPIKey(secret, uniqueSoftDeleteValue(t, "sk-already-hashed"))
+	current, err := client.APIKey.Create().
+		SetUserID(u.ID).
+		SetKey(hashed).
+		SetKeyPrefix("sk-alrea").
+		SetName("current").
+		Save(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, backfillAPIKeyHashes(ctx, integrationDB, secret))
+	// 幂等：再次执行不会重复摘要
+	require.NoError(t, backfillAPIKeyHashes(ctx, integrationDB, secret))
+
+	repo := NewAPIKeyRepository(client, integrationDB)
+	got, err := repo.GetByKeyForAuth(ctx, service.HashAPIKey(secret, plaintext))
+	require.NoError(t, err)
+	require.Equal(t, legacy.ID, got.ID)
+
+	stored, err := repo.GetByID(ctx, legacy.ID)
+	require.NoError(t, err)
+	require.True(t, service.IsHashedAPIKey(stored.KeyHash))
+	require.NotContains(t, stored.KeyHash, plaintext)
+	require.Equal(t, service.APIKeyDisplayPrefix(plaintext), stored.KeyPrefix)
+
+	untouched, err := repo.GetByID(ctx, current.ID)
+	require.NoError(t, err)
+	require.Equal(t, hashed, untouched.KeyHash)
+	require.Equal(t, "sk-alrea", untouched.KeyPrefix)
+}
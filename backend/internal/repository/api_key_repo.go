@@ -8,6 +8,7 @@ import (
 	dbent "github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/ent/apikey"
 	"github.com/Wei-Shaw/sub2api/ent/group"
+	"github.com/Wei-Shaw/sub2api/ent/predicate"
 	"github.com/Wei-Shaw/sub2api/ent/schema/mixins"
 	"github.com/Wei-Shaw/sub2api/ent/user"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -36,7 +37,8 @@ func (r *apiKeyRepository) activeQuery() *dbent.APIKeyQuery {
 func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) error {
 	builder := r.client.APIKey.Create().
 		SetUserID(key.UserID).
-		SetKey(key.Key).
+		SetKey(key.KeyHash).
+		SetKeyPrefix(key.KeyPrefix).
 		SetName(key.Name).
 		SetStatus(key.Status).
 		SetNillableGroupID(key.GroupID).
@@ -54,6 +56,9 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if key.Policy != nil && !key.Policy.IsEmpty() {
+		builder.SetPolicy(*key.Policy)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -80,28 +85,32 @@ func (r *apiKeyRepository) GetByID(ctx context.Context, id int64) (*service.APIK
 	return apiKeyEntityToService(m), nil
 }
 
-// GetKeyAndOwnerID 根据 API Key ID 获取其 key 与所有者（用户）ID。
+// GetKeyAndOwnerID 根据 API Key ID 获取其 key 摘要（含宽限期内的旧 Key 摘要）与所有者（用户）ID。
 // 相比 GetByID，此方法性能更优，因为：
 //   - 使用 Select() 只查询必要字段，减少数据传输量
 //   - 不加载完整的 API Key 实体及其关联数据（User、Group 等）
 //   - 适用于删除等只需 key 与用户 ID 的场景
-func (r *apiKeyRepository) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
+func (r *apiKeyRepository) GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error) {
 	m, err := r.activeQuery().
 		Where(apikey.IDEQ(id)).
-		Select(apikey.FieldKey, apikey.FieldUserID).
+		Select(apikey.FieldKey, apikey.FieldPreviousKey, apikey.FieldUserID).
 		Only(ctx)
 	if err != nil {
 		if dbent.IsNotFound(err) {
-			return "", 0, service.ErrAPIKeyNotFound
+			return nil, 0, service.ErrAPIKeyNotFound
 		}
-		return "", 0, err
+		return nil, 0, err
+	}
+	keys := []string{m.Key}
+	if m.PreviousKey != nil && *m.PreviousKey != "" {
+		keys = append(keys, *m.PreviousKey)
 	}
-	return m.Key, m.UserID, nil
+	return keys, m.UserID, nil
 }
 
-func (r *apiKeyRepository) GetByKey(ctx context.Context, key string) (*service.APIKey, error) {
+func (r *apiKeyRepository) GetByKey(ctx context.Context, keyHash string) (*service.APIKey, error) {
 	m, err := r.activeQuery().
-		Where(apikey.KeyEQ(key)).
+		Where(apikey.KeyEQ(keyHash)).
 		WithUser().
 		WithGroup().
 		Only(ctx)
@@ -114,11 +123,18 @@ func (r *apiKeyRepository) GetByKey(ctx context.Context, key string) (*service.A
 	return apiKeyEntityToService(m), nil
 }
 
-func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*service.APIKey, error) {
+// GetByKeyForAuth 同时匹配当前 Key 与宽限期内的旧 Key（previous_key）
+func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, keyHash string) (*service.APIKey, error) {
 	m, err := r.activeQuery().
-		Where(apikey.KeyEQ(key)).
+		Where(apikey.Or(
+			apikey.KeyEQ(keyHash),
+			apikey.And(apikey.PreviousKeyEQ(keyHash), apikey.PreviousKeyExpiresAtGT(time.Now())),
+		)).
 		Select(
 			apikey.FieldID,
+			apikey.FieldKey,
+			apikey.FieldPreviousKey,
+			apikey.FieldPreviousKeyExpiresAt,
 			apikey.FieldUserID,
 			apikey.FieldGroupID,
 			apikey.FieldStatus,
@@ -130,6 +146,7 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldRateLimit5h,
 			apikey.FieldRateLimit1d,
 			apikey.FieldRateLimit7d,
+			apikey.FieldPolicy,
 		).
 		WithUser(func(q *dbent.UserQuery) {
 			q.Select(
@@ -138,6 +155,9 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				user.FieldRole,
 				user.FieldBalance,
 				user.FieldConcurrency,
+				user.FieldRpmLimit,
+				user.FieldTpmLimit,
+				user.FieldQueuePriority,
 			)
 		}).
 		WithGroup(func(q *dbent.GroupQuery) {
@@ -167,6 +187,18 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldSupportedModelScopes,
 				group.FieldAllowMessagesDispatch,
 				group.FieldDefaultMappedModel,
+				group.FieldMaxIpsPerKeyPerHour,
+				group.FieldRpmLimit,
+				group.FieldTpmLimit,
+				group.FieldQueuePriority,
+				group.FieldSchedulingStrategy,
+				group.FieldModelFallbackChains,
+				group.FieldCrossPlatformFailover,
+				group.FieldShadowMirror,
+				group.FieldResponseCache,
+				group.FieldAutoCacheBreakpoints,
+				group.FieldStreamResume,
+				group.FieldContextPolicy,
 			)
 		}).
 		Only(ctx)
@@ -242,6 +274,13 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		builder.ClearIPBlacklist()
 	}
 
+	// 访问策略
+	if key.Policy != nil && !key.Policy.IsEmpty() {
+		builder.SetPolicy(*key.Policy)
+	} else {
+		builder.ClearPolicy()
+	}
+
 	affected, err := builder.Save(ctx)
 	if err != nil {
 		return err
@@ -290,7 +329,7 @@ func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID int64, param
 	if filters.Search != "" {
 		q = q.Where(apikey.Or(
 			apikey.NameContainsFold(filters.Search),
-			apikey.KeyContainsFold(filters.Search),
+			apiKeyPrefixSearch(filters.Search),
 		))
 	}
 	if filters.Status != "" {
@@ -346,8 +385,9 @@ func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID int64) (int
 	return int64(count), err
 }
 
-func (r *apiKeyRepository) ExistsByKey(ctx context.Context, key string) (bool, error) {
-	count, err := r.activeQuery().Where(apikey.KeyEQ(key)).Count(ctx)
+// ExistsByKey 同时检查宽限期内的旧 Key，避免自定义 Key 与其冲突导致认证命中多条记录
+func (r *apiKeyRepository) ExistsByKey(ctx context.Context, keyHash string) (bool, error) {
+	count, err := r.activeQuery().Where(apikey.Or(apikey.KeyEQ(keyHash), apikey.PreviousKeyEQ(keyHash))).Count(ctx)
 	return count > 0, err
 }
 
@@ -400,6 +440,16 @@ func (r *apiKeyRepository) SearchAPIKeys(ctx context.Context, userID int64, keyw
 	return outKeys, nil
 }
 
+// apiKeyPrefixSearch 按展示前缀搜索 Key：key 字段只保存摘要，无法按明文模糊匹配。
+// 输入完整 Key（或比前缀更长的片段）时按其前缀匹配。
+func apiKeyPrefixSearch(search string) predicate.APIKey {
+	preds := []predicate.APIKey{apikey.KeyPrefixContainsFold(search)}
+	if prefix := service.APIKeyDisplayPrefix(search); prefix != search {
+		preds = append(preds, apikey.KeyPrefixEQ(prefix))
+	}
+	return apikey.Or(preds...)
+}
+
 // ClearGroupIDByGroupID 将指定分组的所有 API Key 的 group_id 设为 nil
 func (r *apiKeyRepository) ClearGroupIDByGroupID(ctx context.Context, groupID int64) (int64, error) {
 	n, err := r.client.APIKey.Update().
@@ -416,25 +466,108 @@ func (r *apiKeyRepository) CountByGroupID(ctx context.Context, groupID int64) (i
 }
 
 func (r *apiKeyRepository) ListKeysByUserID(ctx context.Context, userID int64) ([]string, error) {
-	keys, err := r.activeQuery().
-		Where(apikey.UserIDEQ(userID)).
-		Select(apikey.FieldKey).
-		Strings(ctx)
+	return r.listAuthKeyHashes(ctx, apikey.UserIDEQ(userID))
+}
+
+func (r *apiKeyRepository) ListKeysByGroupID(ctx context.Context, groupID int64) ([]string, error) {
+	return r.listAuthKeyHashes(ctx, apikey.GroupIDEQ(groupID))
+}
+
+// listAuthKeyHashes 返回匹配记录的全部可认证摘要（当前 Key 与尚未清除的旧 Key），用于认证缓存失效
+func (r *apiKeyRepository) listAuthKeyHashes(ctx context.Context, pred predicate.APIKey) ([]string, error) {
+	rows, err := r.activeQuery().
+		Where(pred).
+		Select(apikey.FieldKey, apikey.FieldPreviousKey).
+		All(ctx)
 	if err != nil {
 		return nil, err
 	}
+	keys := make([]string, 0, len(rows))
+	for _, m := range rows {
+		keys = append(keys, m.Key)
+		if m.PreviousKey != nil && *m.PreviousKey != "" {
+			keys = append(keys, *m.PreviousKey)
+		}
+	}
 	return keys, nil
 }
 
-func (r *apiKeyRepository) ListKeysByGroupID(ctx context.Context, groupID int64) ([]string, error) {
-	keys, err := r.activeQuery().
-		Where(apikey.GroupIDEQ(groupID)).
-		Select(apikey.FieldKey).
-		Strings(ctx)
+// Rotate 以条件更新替换 Key 摘要：仅当记录未删除且当前摘要仍为 CurrentKeyHash 时生效，
+// 避免并发轮换时后一次覆盖前一次而导致新 Key 丢失。
+func (r *apiKeyRepository) Rotate(ctx context.Context, rotation service.APIKeyRotation) error {
+	client := clientFromContext(ctx, r.client)
+	builder := client.APIKey.Update().
+		Where(
+			apikey.IDEQ(rotation.ID),
+			apikey.DeletedAtIsNil(),
+			apikey.KeyEQ(rotation.CurrentKeyHash),
+		).
+		SetKey(rotation.NewKeyHash).
+		SetKeyPrefix(rotation.NewKeyPrefix).
+		SetRotatedAt(rotation.RotatedAt).
+		SetUpdatedAt(rotation.RotatedAt)
+	if rotation.PreviousExpiresAt != nil {
+		builder.
+			SetPreviousKey(rotation.CurrentKeyHash).
+			SetPreviousKeyPrefix(rotation.CurrentKeyPrefix).
+			SetPreviousKeyExpiresAt(*rotation.PreviousExpiresAt)
+	} else {
+		builder.
+			ClearPreviousKey().
+			SetPreviousKeyPrefix("").
+			ClearPreviousKeyExpiresAt()
+	}
+
+	affected, err := builder.Save(ctx)
+	if err != nil {
+		return translatePersistenceError(err, nil, service.ErrAPIKeyExists)
+	}
+	if affected == 0 {
+		exists, err := r.activeQuery().Where(apikey.IDEQ(rotation.ID)).Exist(ctx)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return service.ErrAPIKeyRotationConflict
+		}
+		return service.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ClearExpiredPreviousKeys 清除宽限期已过的旧 Key（含已软删除记录），返回被清除的摘要
+// RETURNING 只能返回更新后的值，因此通过 CTE 锁定并带出清除前的摘要。
+func (r *apiKeyRepository) ClearExpiredPreviousKeys(ctx context.Context, now time.Time) (cleared []string, err error) {
+	rows, err := r.sql.QueryContext(ctx, `
+		WITH expired AS (
+			SELECT id, previous_key FROM api_keys
+			WHERE previous_key_expires_at IS NOT NULL AND previous_key_expires_at <= $1
+			FOR UPDATE
+		)
+		UPDATE api_keys AS k
+		SET previous_key = NULL, previous_key_prefix = '', previous_key_expires_at = NULL
+		FROM expired
+		WHERE k.id = expired.id
+		RETURNING COALESCE(expired.previous_key, '')`, now)
 	if err != nil {
 		return nil, err
 	}
-	return keys, nil
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for rows.Next() {
+		var keyHash string
+		if err := rows.Scan(&keyHash); err != nil {
+			return nil, err
+		}
+		if keyHash != "" {
+			cleared = append(cleared, keyHash)
+		}
+	}
+	return cleared, rows.Err()
 }
 
 // IncrementQuotaUsed 使用 Ent 原子递增 quota_used 字段并返回新值
@@ -532,7 +665,8 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 	out := &service.APIKey{
 		ID:            m.ID,
 		UserID:        m.UserID,
-		Key:           m.Key,
+		KeyHash:       m.Key,
+		KeyPrefix:     m.KeyPrefix,
 		Name:          m.Name,
 		Status:        m.Status,
 		IPWhitelist:   m.IPWhitelist,
@@ -553,6 +687,17 @@ func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 		Window5hStart: m.Window5hStart,
 		Window1dStart: m.Window1dStart,
 		Window7dStart: m.Window7dStart,
+
+		PreviousKeyPrefix:    m.PreviousKeyPrefix,
+		PreviousKeyExpiresAt: m.PreviousKeyExpiresAt,
+		RotatedAt:            m.RotatedAt,
+	}
+	if m.PreviousKey != nil {
+		out.PreviousKeyHash = *m.PreviousKey
+	}
+	if !m.Policy.IsEmpty() {
+		policy := m.Policy
+		out.Policy = &policy
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -579,6 +724,9 @@ func userEntityToService(u *dbent.User) *service.User {
 		Status:                u.Status,
 		SoraStorageQuotaBytes: u.SoraStorageQuotaBytes,
 		SoraStorageUsedBytes:  u.SoraStorageUsedBytes,
+		RPMLimit:              u.RpmLimit,
+		TPMLimit:              u.TpmLimit,
+		QueuePriority:         u.QueuePriority,
 		TotpSecretEncrypted:   u.TotpSecretEncrypted,
 		TotpEnabled:           u.TotpEnabled,
 		TotpEnabledAt:         u.TotpEnabledAt,
@@ -623,6 +771,18 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		SortOrder:                       g.SortOrder,
 		AllowMessagesDispatch:           g.AllowMessagesDispatch,
 		DefaultMappedModel:              g.DefaultMappedModel,
+		MaxIPsPerKeyPerHour:             g.MaxIpsPerKeyPerHour,
+		RPMLimit:                        g.RpmLimit,
+		TPMLimit:                        g.TpmLimit,
+		QueuePriority:                   g.QueuePriority,
+		SchedulingStrategy:              g.SchedulingStrategy,
+		ModelFallbackChains:             g.ModelFallbackChains,
+		CrossPlatformFailover:           g.CrossPlatformFailover,
+		ShadowMirror:                    g.ShadowMirror,
+		ResponseCache:                   g.ResponseCache,
+		AutoCacheBreakpoints:            g.AutoCacheBreakpoints,
+		StreamResume:                    g.StreamResume,
+		ContextPolicy:                   g.ContextPolicy,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
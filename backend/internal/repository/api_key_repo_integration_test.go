@@ -39,10 +39,10 @@ func (s *APIKeyRepoSuite) TestCreate() {
 	user := s.mustCreateUser("create@test.com")
 
 	key := &service.APIKey{
-		UserID: user.ID,
-		Key:    "sk-create-test",
-		Name:   "Test Key",
-		Status: service.StatusActive,
+		UserID:  user.ID,
+		KeyHash: "sk-create-test",
+		Name:    "Test Key",
+		Status:  service.StatusActive,
 	}
 
 	err := s.repo.Create(s.ctx, key)
@@ -51,7 +51,7 @@ func (s *APIKeyRepoSuite) TestCreate() {
 
 	got, err := s.repo.GetByID(s.ctx, key.ID)
 	s.Require().NoError(err, "GetByID")
-	s.Require().Equal("sk-create-test", got.Key)
+	s.Require().Equal("sk-create-test", got.KeyHash)
 }
 
 func (s *APIKeyRepoSuite) TestGetByID_NotFound() {
@@ -65,14 +65,14 @@ func (s *APIKeyRepoSuite) TestGetByKey() {
 
 	key := &service.APIKey{
 		UserID:  user.ID,
-		Key:     "sk-getbykey",
+		KeyHash: "sk-getbykey",
 		Name:    "My Key",
 		GroupID: &group.ID,
 		Status:  service.StatusActive,
 	}
 	s.Require().NoError(s.repo.Create(s.ctx, key))
 
-	got, err := s.repo.GetByKey(s.ctx, key.Key)
+	got, err := s.repo.GetByKey(s.ctx, key.KeyHash)
 	s.Require().NoError(err, "GetByKey")
 	s.Require().Equal(key.ID, got.ID)
 	s.Require().NotNil(got.User, "expected User preload")
@@ -91,10 +91,10 @@ func (s *APIKeyRepoSuite) TestGetByKey_NotFound() {
 func (s *APIKeyRepoSuite) TestUpdate() {
 	user := s.mustCreateUser("update@test.com")
 	key := &service.APIKey{
-		UserID: user.ID,
-		Key:    "sk-update",
-		Name:   "Original",
-		Status: service.StatusActive,
+		UserID:  user.ID,
+		KeyHash: "sk-update",
+		Name:    "Original",
+		Status:  service.StatusActive,
 	}
 	s.Require().NoError(s.repo.Create(s.ctx, key))
 
@@ -105,7 +105,7 @@ func (s *APIKeyRepoSuite) TestUpdate() {
 
 	got, err := s.repo.GetByID(s.ctx, key.ID)
 	s.Require().NoError(err, "GetByID after update")
-	s.Require().Equal("sk-update", got.Key, "Update should not change key")
+	s.Require().Equal("sk-update", got.KeyHash, "Update should not change key")
 	s.Require().Equal(user.ID, got.UserID, "Update should not change user_id")
 	s.Require().Equal("Renamed", got.Name)
 	s.Require().Equal(service.StatusDisabled, got.Status)
@@ -116,7 +116,7 @@ func (s *APIKeyRepoSuite) TestUpdate_ClearGroupID() {
 	group := s.mustCreateGroup("g-clear")
 	key := &service.APIKey{
 		UserID:  user.ID,
-		Key:     "sk-clear-group",
+		KeyHash: "sk-clear-group",
 		Name:    "Group Key",
 		GroupID: &group.ID,
 		Status:  service.StatusActive,
@@ -137,10 +137,10 @@ func (s *APIKeyRepoSuite) TestUpdate_ClearGroupID() {
 func (s *APIKeyRepoSuite) TestDelete() {
 	user := s.mustCreateUser("delete@test.com")
 	key := &service.APIKey{
-		UserID: user.ID,
-		Key:    "sk-delete",
-		Name:   "Delete Me",
-		Status: service.StatusActive,
+		UserID:  user.ID,
+		KeyHash: "sk-delete",
+		Name:    "Delete Me",
+		Status:  service.StatusActive,
 	}
 	s.Require().NoError(s.repo.Create(s.ctx, key))
 
@@ -177,6 +177,27 @@ func (s *APIKeyRepoSuite) TestListByUserID_Pagination() {
 	s.Require().Equal(3, page.Pages)
 }
 
+func (s *APIKeyRepoSuite) TestListByUserID_SearchMatchesKeyPrefix() {
+	user := s.mustCreateUser("searchprefix@test.com")
+	for _, k := range []*service.APIKey{
+		{UserID: user.ID, KeyHash: service.HashAPIKey("s", "sk-alpha-1"), KeyPrefix: "sk-alpha", Name: "A", Status: service.StatusActive},
+		{UserID: user.ID, KeyHash: service.HashAPIKey("s", "sk-bravo-1"), KeyPrefix: "sk-bravo", Name: "B", Status: service.StatusActive},
+	} {
+		s.Require().NoError(s.repo.Create(s.ctx, k), "create api key")
+	}
+
+	for _, search := range []string{"alpha", "sk-alpha-1234567890"} {
+		keys, _, err := s.repo.ListByUserID(s.ctx, user.ID, pagination.PaginationParams{Page: 1, PageSize: 10}, service.APIKeyListFilters{Search: search})
+		s.Require().NoError(err, "ListByUserID")
+		s.Require().Len(keys, 1, search)
+		s.Require().Equal("A", keys[0].Name)
+	}
+
+	keys, _, err := s.repo.ListByUserID(s.ctx, user.ID, pagination.PaginationParams{Page: 1, PageSize: 10}, service.APIKeyListFilters{Search: "hmac"})
+	s.Require().NoError(err)
+	s.Require().Empty(keys, "摘要内容不参与搜索")
+}
+
 func (s *APIKeyRepoSuite) TestCountByUserID() {
 	user := s.mustCreateUser("count@test.com")
 	s.mustCreateApiKey(user.ID, "sk-count-1", "K1", nil)
@@ -230,6 +251,123 @@ func (s *APIKeyRepoSuite) TestExistsByKey() {
 	s.Require().False(notExists)
 }
 
+// --- Rotation ---
+
+func (s *APIKeyRepoSuite) TestRotate_PreviousKeyValidDuringGrace() {
+	user := s.mustCreateUser("rotate@test.com")
+	key := s.mustCreateApiKey(user.ID, "sk-rotate-old", "K", nil)
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	s.Require().NoError(s.repo.Rotate(s.ctx, service.APIKeyRotation{
+		ID:                key.ID,
+		CurrentKeyHash:    "sk-rotate-old",
+		CurrentKeyPrefix:  "sk-rotat",
+		NewKeyHash:        "sk-rotate-new",
+		NewKeyPrefix:      "sk-rotnw",
+		PreviousExpiresAt: &expiresAt,
+		RotatedAt:         now,
+	}), "Rotate")
+
+	for _, keyHash := range []string{"sk-rotate-new", "sk-rotate-old"} {
+		got, err := s.repo.GetByKeyForAuth(s.ctx, keyHash)
+		s.Require().NoError(err, "GetByKeyForAuth %s", keyHash)
+		s.Require().Equal(key.ID, got.ID)
+		s.Require().Equal("sk-rotate-new", got.KeyHash)
+		s.Require().Equal("sk-rotate-old", got.PreviousKeyHash)
+	}
+
+	stored, err := s.repo.GetByID(s.ctx, key.ID)
+	s.Require().NoError(err)
+	s.Require().Equal("sk-rotnw", stored.KeyPrefix)
+	s.Require().Equal("sk-rotat", stored.PreviousKeyPrefix)
+	s.Require().NotNil(stored.PreviousKeyExpiresAt)
+	s.Require().NotNil(stored.RotatedAt)
+
+	keys, err := s.repo.ListKeysByUserID(s.ctx, user.ID)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]string{"sk-rotate-new", "sk-rotate-old"}, keys)
+
+	owned, ownerID, err := s.repo.GetKeyAndOwnerID(s.ctx, key.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(user.ID, ownerID)
+	s.Require().ElementsMatch([]string{"sk-rotate-new", "sk-rotate-old"}, owned)
+
+	exists, err := s.repo.ExistsByKey(s.ctx, "sk-rotate-old")
+	s.Require().NoError(err)
+	s.Require().True(exists, "previous key must block reuse as a custom key")
+
+	// 当前摘要已变化，基于旧摘要的并发轮换应失败
+	err = s.repo.Rotate(s.ctx, service.APIKeyRotation{
+		ID:             key.ID,
+		CurrentKeyHash: "sk-rotate-old",
+		NewKeyHash:     "sk-rotate-race",
+		RotatedAt:      now,
+	})
+	s.Require().ErrorIs(err, service.ErrAPIKeyRotationConflict)
+}
+
+func (s *APIKeyRepoSuite) TestRotate_ExpiredPreviousKeyRejectedAndCleared() {
+	user := s.mustCreateUser("rotate-expired@test.com")
+	key := s.mustCreateApiKey(user.ID, "sk-expired-old", "K", nil)
+
+	now := time.Now()
+	expiresAt := now.Add(-time.Minute)
+	s.Require().NoError(s.repo.Rotate(s.ctx, service.APIKeyRotation{
+		ID:                key.ID,
+		CurrentKeyHash:    "sk-expired-old",
+		NewKeyHash:        "sk-expired-new",
+		PreviousExpiresAt: &expiresAt,
+		RotatedAt:         now,
+	}))
+
+	_, err := s.repo.GetByKeyForAuth(s.ctx, "sk-expired-old")
+	s.Require().ErrorIs(err, service.ErrAPIKeyNotFound)
+
+	cleared, err := s.repo.ClearExpiredPreviousKeys(s.ctx, now)
+	s.Require().NoError(err)
+	s.Require().Contains(cleared, "sk-expired-old")
+
+	stored, err := s.repo.GetByID(s.ctx, key.ID)
+	s.Require().NoError(err)
+	s.Require().Empty(stored.PreviousKeyHash)
+	s.Require().Empty(stored.PreviousKeyPrefix)
+	s.Require().Nil(stored.PreviousKeyExpiresAt)
+	s.Require().Equal("sk-expired-new", stored.KeyHash)
+}
+
+func (s *APIKeyRepoSuite) TestRotate_NotFound() {
+	err := s.repo.Rotate(s.ctx, service.APIKeyRotation{ID: 999999, CurrentKeyHash: "x", NewKeyHash: "y", RotatedAt: time.Now()})
+	s.Require().ErrorIs(err, service.ErrAPIKeyNotFound)
+}
+
+// --- Policy ---
+
+func (s *APIKeyRepoSuite) TestPolicy_RoundTripAndClear() {
+	user := s.mustCreateUser("policy@test.com")
+	key := s.mustCreateApiKey(user.ID, "sk-policy", "K", nil)
+
+	key.Policy = &service.APIKeyPolicy{
+		AllowedModels: []string{"claude-*"},
+		DenyTools:     true,
+		RPM:           30,
+	}
+	s.Require().NoError(s.repo.Update(s.ctx, key), "Update")
+
+	got, err := s.repo.GetByKeyForAuth(s.ctx, "sk-policy")
+	s.Require().NoError(err, "GetByKeyForAuth")
+	s.Require().NotNil(got.Policy)
+	s.Require().Equal([]string{"claude-*"}, got.Policy.AllowedModels)
+	s.Require().True(got.Policy.DenyTools)
+	s.Require().Equal(30, got.Policy.RPM)
+
+	key.Policy = nil
+	s.Require().NoError(s.repo.Update(s.ctx, key), "Update clear")
+	got, err = s.repo.GetByID(s.ctx, key.ID)
+	s.Require().NoError(err)
+	s.Require().Nil(got.Policy)
+}
+
 // --- SearchAPIKeys ---
 
 func (s *APIKeyRepoSuite) TestSearchAPIKeys() {
@@ -293,7 +431,7 @@ func (s *APIKeyRepoSuite) TestCRUD_Search_ClearGroupID() {
 	key := s.mustCreateApiKey(user.ID, "sk-test-1", "My Key", &group.ID)
 	key.GroupID = &group.ID
 
-	got, err := s.repo.GetByKey(s.ctx, key.Key)
+	got, err := s.repo.GetByKey(s.ctx, key.KeyHash)
 	s.Require().NoError(err, "GetByKey")
 	s.Require().Equal(key.ID, got.ID)
 	s.Require().NotNil(got.User)
@@ -308,7 +446,7 @@ func (s *APIKeyRepoSuite) TestCRUD_Search_ClearGroupID() {
 
 	got2, err := s.repo.GetByID(s.ctx, key.ID)
 	s.Require().NoError(err, "GetByID")
-	s.Require().Equal("sk-test-1", got2.Key, "Update should not change key")
+	s.Require().Equal("sk-test-1", got2.KeyHash, "Update should not change key")
 	s.Require().Equal(user.ID, got2.UserID, "Update should not change user_id")
 	s.Require().Equal("Renamed", got2.Name)
 	s.Require().Equal(service.StatusDisabled, got2.Status)
@@ -378,7 +516,7 @@ func (s *APIKeyRepoSuite) mustCreateApiKey(userID int64, key, name string, group
 
 	k := &service.APIKey{
 		UserID:  userID,
-		Key:     key,
+		KeyHash: key,
 		Name:    name,
 		GroupID: groupID,
 		Status:  service.StatusActive,
@@ -434,10 +572,10 @@ func TestIncrementQuotaUsed_Concurrent(t *testing.T) {
 	require.NoError(t, err, "create user")
 
 	k := &service.APIKey{
-		UserID: u.ID,
-		Key:    "sk-concurrent-" + time.Now().Format(time.RFC3339Nano),
-		Name:   "Concurrent",
-		Status: service.StatusActive,
+		UserID:  u.ID,
+		KeyHash: "sk-concurrent-" + time.Now().Format(time.RFC3339Nano),
+		Name:    "Concurrent",
+		Status:  service.StatusActive,
 	}
 	require.NoError(t, repo.Create(ctx, k), "create api key")
 	t.Cleanup(func() {
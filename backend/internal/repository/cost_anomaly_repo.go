@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type costAnomalyRepository struct {
+	sql sqlExecutor
+}
+
+// NewCostAnomalyRepository 创建费用异常检测仓储
+func NewCostAnomalyRepository(sqlDB *sql.DB) service.CostAnomalyRepository {
+	return &costAnomalyRepository{sql: sqlDB}
+}
+
+func (r *costAnomalyRepository) RefreshHourly(ctx context.Context, from, to time.Time) error {
+	start := from.UTC().Truncate(time.Hour)
+	end := to.UTC().Truncate(time.Hour).Add(time.Hour)
+	if !end.After(start) {
+		return nil
+	}
+	computedAt := time.Now().UTC()
+	query := `
+		INSERT INTO usage_api_key_hourly (bucket_start, api_key_id, user_id, request_count, actual_cost, computed_at)
+		SELECT
+			date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket_start,
+			api_key_id,
+			MAX(user_id) AS user_id,
+			COUNT(*) AS request_count,
+			COALESCE(SUM(actual_cost), 0) AS actual_cost,
+			$3::timestamptz
+		FROM usage_logs
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY 1, api_key_id
+		ON CONFLICT (bucket_start, api_key_id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			request_count = EXCLUDED.request_count,
+			actual_cost = EXCLUDED.actual_cost,
+			computed_at = EXCLUDED.computed_at
+	`
+	if _, err := r.sql.ExecContext(ctx, query, start, end, computedAt); err != nil {
+		return err
+	}
+	// 区间内本次未重算到的聚合（对应用量已被清理）一并删除
+	_, err := r.sql.ExecContext(ctx, `
+		DELETE FROM usage_api_key_hourly
+		WHERE bucket_start >= $1 AND bucket_start < $2 AND computed_at < $3
+	`, start, end, computedAt)
+	return err
+}
+
+func (r *costAnomalyRepository) PruneHourly(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.sql.ExecContext(ctx, `DELETE FROM usage_api_key_hourly WHERE bucket_start < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *costAnomalyRepository) ListBaselines(ctx context.Context, subject string, ids []int64, start, end time.Time) ([]service.CostAnomalyBaseline, error) {
+	var subjectColumn string
+	switch subject {
+	case service.CostAnomalySubjectAPIKey:
+		subjectColumn = "api_key_id"
+	case service.CostAnomalySubjectUser:
+		subjectColumn = "user_id"
+	default:
+		return nil, fmt.Errorf("unsupported cost anomaly subject: %s", subject)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// 先按 (对象, 小时) 汇总，再计算和与平方和；用户维度需合并多个 Key 的同一小时
+	query := fmt.Sprintf(`
+		WITH buckets AS (
+			SELECT
+				%[1]s AS subject_id,
+				MAX(user_id) AS user_id,
+				bucket_start,
+				SUM(actual_cost)::double precision AS cost,
+				SUM(request_count)::double precision AS requests
+			FROM usage_api_key_hourly
+			WHERE %[1]s = ANY($1) AND bucket_start >= $2 AND bucket_start < $3
+			GROUP BY %[1]s, bucket_start
+		)
+		SELECT
+			subject_id,
+			MAX(user_id),
+			MIN(bucket_start),
+			SUM(cost),
+			SUM(cost * cost),
+			SUM(requests),
+			SUM(requests * requests)
+		FROM buckets
+		GROUP BY subject_id
+	`, subjectColumn)
+
+	rows, err := r.sql.QueryContext(ctx, query, pq.Array(ids), start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.CostAnomalyBaseline, 0, len(ids))
+	for rows.Next() {
+		var b service.CostAnomalyBaseline
+		if err := rows.Scan(&b.SubjectID, &b.UserID, &b.FirstBucket, &b.SumCost, &b.SumCostSq, &b.SumRequests, &b.SumRequestsSq); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func (r *costAnomalyRepository) ListRecentUsage(ctx context.Context, since time.Time) ([]service.CostAnomalyUsage, error) {
+	query := `
+		SELECT api_key_id, MAX(user_id), COALESCE(SUM(actual_cost), 0)::double precision, COUNT(*)
+		FROM usage_logs
+		WHERE created_at >= $1
+		GROUP BY api_key_id
+	`
+	rows, err := r.sql.QueryContext(ctx, query, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.CostAnomalyUsage, 0)
+	for rows.Next() {
+		var u service.CostAnomalyUsage
+		if err := rows.Scan(&u.APIKeyID, &u.UserID, &u.Cost, &u.Requests); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+func (r *costAnomalyRepository) HasRecentAlert(ctx context.Context, subject string, subjectID int64, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM ops_alert_events
+			WHERE fired_at >= $1
+				AND dimensions->>'source' = 'cost_anomaly'
+				AND dimensions->>'subject' = $2
+				AND dimensions->>'subject_id' = $3
+		)
+	`
+	var exists bool
+	if err := scanSingleRow(ctx, r.sql, query, []any{since.UTC(), subject, strconv.FormatInt(subjectID, 10)}, &exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
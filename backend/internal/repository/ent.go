@@ -79,6 +79,12 @@ func InitEnt(cfg *config.Config) (*ent.Client, *sql.DB, error) {
 		return nil, nil, fmt.Errorf("validate config after secret bootstrap: %w", err)
 	}
 
+	// 启动阶段：将旧版明文存储的 API Key 回填为摘要，认证路径只按摘要查询。
+	if err := backfillAPIKeyHashes(migrationCtx, drv.DB(), cfg.Security.APIKeyHashSecret); err != nil {
+		_ = client.Close()
+		return nil, nil, err
+	}
+
 	// SIMPLE 模式：启动时补齐各平台默认分组。
 	// - anthropic/openai/gemini: 确保存在 <platform>-default
 	// - antigravity: 仅要求存在 >=2 个未软删除分组（用于 claude/gemini 混合调度场景）
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const experimentSelectColumns = `
+	id, name, description, status, model_pattern, group_ids, sticky_by, variants, created_at, updated_at
+`
+
+type experimentRepository struct {
+	sql sqlExecutor
+}
+
+// NewExperimentRepository 创建 A/B 实验仓储
+func NewExperimentRepository(sqlDB *sql.DB) service.ExperimentRepository {
+	return &experimentRepository{sql: sqlDB}
+}
+
+func (r *experimentRepository) List(ctx context.Context) ([]service.Experiment, error) {
+	return r.query(ctx, "SELECT "+experimentSelectColumns+" FROM experiments WHERE deleted_at IS NULL ORDER BY id DESC")
+}
+
+func (r *experimentRepository) ListRunning(ctx context.Context) ([]service.Experiment, error) {
+	return r.query(ctx, "SELECT "+experimentSelectColumns+" FROM experiments WHERE deleted_at IS NULL AND status = $1 ORDER BY id ASC", service.ExperimentStatusRunning)
+}
+
+func (r *experimentRepository) GetByID(ctx context.Context, id int64) (*service.Experiment, error) {
+	items, err := r.query(ctx, "SELECT "+experimentSelectColumns+" FROM experiments WHERE id = $1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, service.ErrExperimentNotFound
+	}
+	return &items[0], nil
+}
+
+func (r *experimentRepository) Create(ctx context.Context, e *service.Experiment) error {
+	groupIDs, variants, err := marshalExperimentJSON(e)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO experiments (name, description, status, model_pattern, group_ids, sticky_by, variants, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{e.Name, e.Description, e.Status, e.ModelPattern, groupIDs, e.StickyBy, variants},
+		&e.ID, &e.CreatedAt, &e.UpdatedAt,
+	)
+}
+
+func (r *experimentRepository) Update(ctx context.Context, e *service.Experiment) error {
+	groupIDs, variants, err := marshalExperimentJSON(e)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE experiments
+		SET name = $2, description = $3, status = $4, model_pattern = $5, group_ids = $6, sticky_by = $7, variants = $8, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING created_at, updated_at
+	`
+	err = scanSingleRow(ctx, r.sql, query,
+		[]any{e.ID, e.Name, e.Description, e.Status, e.ModelPattern, groupIDs, e.StickyBy, variants},
+		&e.CreatedAt, &e.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrExperimentNotFound
+	}
+	return err
+}
+
+// Delete 软删除：使用日志仍引用实验 ID，保留记录以便回看历史结果
+func (r *experimentRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.sql.ExecContext(ctx, "UPDATE experiments SET deleted_at = NOW(), status = $2 WHERE id = $1 AND deleted_at IS NULL", id, service.ExperimentStatusStopped)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return service.ErrExperimentNotFound
+	}
+	return nil
+}
+
+func (r *experimentRepository) VariantStats(ctx context.Context, experimentID int64, startTime, endTime time.Time) ([]service.ExperimentVariantStats, error) {
+	// 成功请求来自 usage_logs，失败请求来自 ops_error_logs（排除本地限流等业务限制）；
+	// returning_users 为窗口内有 2 天及以上请求的用户数，用于粗略观察留存。
+	query := `
+		WITH u AS (
+			SELECT
+				experiment_variant AS variant,
+				COUNT(*) AS requests,
+				COUNT(DISTINCT user_id) AS unique_users,
+				COALESCE(AVG(duration_ms), 0) AS avg_duration_ms,
+				COALESCE(AVG(first_token_ms), 0) AS avg_first_token_ms,
+				COALESCE(SUM(input_tokens), 0) AS input_tokens,
+				COALESCE(SUM(output_tokens), 0) AS output_tokens,
+				COALESCE(SUM(actual_cost), 0) AS total_cost
+			FROM usage_logs
+			WHERE experiment_id = $1 AND created_at >= $2 AND created_at < $3
+			GROUP BY experiment_variant
+		),
+		ret AS (
+			SELECT variant, COUNT(*) FILTER (WHERE active_days >= 2) AS returning_users
+			FROM (
+				SELECT experiment_variant AS variant, user_id, COUNT(DISTINCT date_trunc('day', created_at)) AS active_days
+				FROM usage_logs
+				WHERE experiment_id = $1 AND created_at >= $2 AND created_at < $3
+				GROUP BY experiment_variant, user_id
+			) per_user
+			GROUP BY variant
+		),
+		e AS (
+			SELECT experiment_variant AS variant, COUNT(*) AS errors
+			FROM ops_error_logs
+			WHERE experiment_id = $1 AND created_at >= $2 AND created_at < $3 AND is_business_limited = FALSE
+			GROUP BY experiment_variant
+		)
+		SELECT
+			COALESCE(u.variant, e.variant, ''),
+			COALESCE(u.requests, 0),
+			COALESCE(e.errors, 0),
+			COALESCE(u.unique_users, 0),
+			COALESCE(ret.returning_users, 0),
+			COALESCE(u.avg_duration_ms, 0),
+			COALESCE(u.avg_first_token_ms, 0),
+			COALESCE(u.input_tokens, 0),
+			COALESCE(u.output_tokens, 0),
+			COALESCE(u.total_cost, 0)
+		FROM u
+		FULL OUTER JOIN e ON e.variant = u.variant
+		LEFT JOIN ret ON ret.variant = u.variant
+		ORDER BY 1
+	`
+	rows, err := r.sql.QueryContext(ctx, query, experimentID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := make([]service.ExperimentVariantStats, 0)
+	for rows.Next() {
+		var st service.ExperimentVariantStats
+		if err := rows.Scan(
+			&st.Variant, &st.Requests, &st.Errors, &st.UniqueUsers, &st.ReturningUsers,
+			&st.AvgDurationMs, &st.AvgFirstTokenMs, &st.InputTokens, &st.OutputTokens, &st.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *experimentRepository) query(ctx context.Context, query string, args ...any) ([]service.Experiment, error) {
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]service.Experiment, 0)
+	for rows.Next() {
+		var (
+			e        service.Experiment
+			groupIDs []byte
+			variants []byte
+		)
+		if err := rows.Scan(
+			&e.ID, &e.Name, &e.Description, &e.Status, &e.ModelPattern, &groupIDs, &e.StickyBy, &variants, &e.CreatedAt, &e.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(groupIDs) > 0 {
+			if err := json.Unmarshal(groupIDs, &e.GroupIDs); err != nil {
+				return nil, err
+			}
+		}
+		if len(variants) > 0 {
+			if err := json.Unmarshal(variants, &e.Variants); err != nil {
+				return nil, err
+			}
+		}
+		if e.GroupIDs == nil {
+			e.GroupIDs = []int64{}
+		}
+		items = append(items, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func marshalExperimentJSON(e *service.Experiment) (groupIDs []byte, variants []byte, err error) {
+	ids := e.GroupIDs
+	if ids == nil {
+		ids = []int64{}
+	}
+	if groupIDs, err = json.Marshal(ids); err != nil {
+		return nil, nil, err
+	}
+	if variants, err = json.Marshal(e.Variants); err != nil {
+		return nil, nil, err
+	}
+	return groupIDs, variants, nil
+}
@@ -61,12 +61,32 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetMcpXMLInject(groupIn.MCPXMLInject).
 		SetSoraStorageQuotaBytes(groupIn.SoraStorageQuotaBytes).
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetCrossPlatformFailover(groupIn.CrossPlatformFailover).
+		SetAutoCacheBreakpoints(groupIn.AutoCacheBreakpoints).
+		SetStreamResume(groupIn.StreamResume).
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetMaxIpsPerKeyPerHour(groupIn.MaxIPsPerKeyPerHour).
+		SetRpmLimit(groupIn.RPMLimit).
+		SetTpmLimit(groupIn.TPMLimit).
+		SetQueuePriority(groupIn.QueuePriority).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy)
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
 		builder = builder.SetModelRouting(groupIn.ModelRouting)
 	}
+	if len(groupIn.ModelFallbackChains) > 0 {
+		builder = builder.SetModelFallbackChains(groupIn.ModelFallbackChains)
+	}
+	if groupIn.ShadowMirror != nil {
+		builder = builder.SetShadowMirror(groupIn.ShadowMirror)
+	}
+	if groupIn.ResponseCache != nil {
+		builder = builder.SetResponseCache(groupIn.ResponseCache)
+	}
+	if groupIn.ContextPolicy != nil {
+		builder = builder.SetContextPolicy(groupIn.ContextPolicy)
+	}
 
 	// 设置支持的模型系列（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
@@ -129,7 +149,15 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetMcpXMLInject(groupIn.MCPXMLInject).
 		SetSoraStorageQuotaBytes(groupIn.SoraStorageQuotaBytes).
 		SetAllowMessagesDispatch(groupIn.AllowMessagesDispatch).
-		SetDefaultMappedModel(groupIn.DefaultMappedModel)
+		SetCrossPlatformFailover(groupIn.CrossPlatformFailover).
+		SetAutoCacheBreakpoints(groupIn.AutoCacheBreakpoints).
+		SetStreamResume(groupIn.StreamResume).
+		SetDefaultMappedModel(groupIn.DefaultMappedModel).
+		SetMaxIpsPerKeyPerHour(groupIn.MaxIPsPerKeyPerHour).
+		SetRpmLimit(groupIn.RPMLimit).
+		SetTpmLimit(groupIn.TPMLimit).
+		SetQueuePriority(groupIn.QueuePriority).
+		SetSchedulingStrategy(groupIn.SchedulingStrategy)
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -183,6 +211,31 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		builder = builder.ClearModelRouting()
 	}
 
+	// 处理 ModelFallbackChains：空时清除，否则设置
+	if len(groupIn.ModelFallbackChains) > 0 {
+		builder = builder.SetModelFallbackChains(groupIn.ModelFallbackChains)
+	} else {
+		builder = builder.ClearModelFallbackChains()
+	}
+	// 处理 ShadowMirror：nil 时清除
+	if groupIn.ShadowMirror != nil {
+		builder = builder.SetShadowMirror(groupIn.ShadowMirror)
+	} else {
+		builder = builder.ClearShadowMirror()
+	}
+	// 处理 ResponseCache：nil 时清除
+	if groupIn.ResponseCache != nil {
+		builder = builder.SetResponseCache(groupIn.ResponseCache)
+	} else {
+		builder = builder.ClearResponseCache()
+	}
+	// 处理 ContextPolicy：nil 时清除
+	if groupIn.ContextPolicy != nil {
+		builder = builder.SetContextPolicy(groupIn.ContextPolicy)
+	} else {
+		builder = builder.ClearContextPolicy()
+	}
+
 	// 处理 SupportedModelScopes（始终设置，空数组表示不限制）
 	builder = builder.SetSupportedModelScopes(groupIn.SupportedModelScopes)
 
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const impersonationAuditSelectColumns = `
+	id, impersonation_id, admin_id, target_user_id, event, scope,
+	method, path, status_code, detail, ip, created_at
+`
+
+type impersonationAuditRepository struct {
+	sql sqlExecutor
+}
+
+// NewImpersonationAuditRepository 创建模拟登录审计日志仓储
+func NewImpersonationAuditRepository(sqlDB *sql.DB) service.ImpersonationAuditRepository {
+	return &impersonationAuditRepository{sql: sqlDB}
+}
+
+func (r *impersonationAuditRepository) Create(ctx context.Context, log *service.ImpersonationAuditLog) error {
+	query := `
+		INSERT INTO impersonation_audit_logs
+			(impersonation_id, admin_id, target_user_id, event, scope, method, path, status_code, detail, ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING id, created_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{log.ImpersonationID, log.AdminID, log.TargetUserID, log.Event, log.Scope, log.Method, log.Path, log.StatusCode, log.Detail, log.IP},
+		&log.ID, &log.CreatedAt,
+	)
+}
+
+func (r *impersonationAuditRepository) List(ctx context.Context, filter service.ImpersonationAuditFilter, params pagination.PaginationParams) ([]service.ImpersonationAuditLog, *pagination.PaginationResult, error) {
+	conds := make([]string, 0, 3)
+	args := []any{}
+	if filter.ImpersonationID != "" {
+		args = append(args, filter.ImpersonationID)
+		conds = append(conds, "impersonation_id = $"+itoa(len(args)))
+	}
+	if filter.AdminID > 0 {
+		args = append(args, filter.AdminID)
+		conds = append(conds, "admin_id = $"+itoa(len(args)))
+	}
+	if filter.TargetUserID > 0 {
+		args = append(args, filter.TargetUserID)
+		conds = append(conds, "target_user_id = $"+itoa(len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int64
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM impersonation_audit_logs "+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.ImpersonationAuditLog{}, paginationResultFromTotal(0, params), nil
+	}
+
+	limitPos := len(args) + 1
+	query := "SELECT " + impersonationAuditSelectColumns + " FROM impersonation_audit_logs " + where +
+		" ORDER BY created_at DESC, id DESC LIMIT $" + itoa(limitPos) + " OFFSET $" + itoa(limitPos+1)
+	rows, err := r.sql.QueryContext(ctx, query, append(args, params.Limit(), params.Offset())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	logs := make([]service.ImpersonationAuditLog, 0)
+	for rows.Next() {
+		var l service.ImpersonationAuditLog
+		if err := rows.Scan(
+			&l.ID, &l.ImpersonationID, &l.AdminID, &l.TargetUserID, &l.Event, &l.Scope,
+			&l.Method, &l.Path, &l.StatusCode, &l.Detail, &l.IP, &l.CreatedAt,
+		); err != nil {
+			return nil, nil, err
+		}
+		logs = append(logs, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return logs, paginationResultFromTotal(total, params), nil
+}
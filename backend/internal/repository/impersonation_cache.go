@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const impersonationKeyPrefix = "impersonation:"
+
+// impersonationKey generates the Redis key for an impersonation session.
+func impersonationKey(id string) string {
+	return impersonationKeyPrefix + id
+}
+
+type impersonationCache struct {
+	rdb *redis.Client
+}
+
+// NewImpersonationCache creates a new ImpersonationCache implementation.
+func NewImpersonationCache(rdb *redis.Client) service.ImpersonationCache {
+	return &impersonationCache{rdb: rdb}
+}
+
+func (c *impersonationCache) SaveImpersonation(ctx context.Context, session *service.ImpersonationSession, ttl time.Duration) error {
+	val, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("marshal impersonation session: %w", err)
+	}
+	return c.rdb.Set(ctx, impersonationKey(session.ID), val, ttl).Err()
+}
+
+func (c *impersonationCache) GetImpersonation(ctx context.Context, id string) (*service.ImpersonationSession, error) {
+	val, err := c.rdb.Get(ctx, impersonationKey(id)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var session service.ImpersonationSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, fmt.Errorf("unmarshal impersonation session: %w", err)
+	}
+	return &session, nil
+}
+
+func (c *impersonationCache) DeleteImpersonation(ctx context.Context, id string) error {
+	return c.rdb.Del(ctx, impersonationKey(id)).Err()
+}
@@ -0,0 +1,50 @@
+//go:build integration
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImpersonationCacheSuite struct {
+	IntegrationRedisSuite
+	cache service.ImpersonationCache
+}
+
+func (s *ImpersonationCacheSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.cache = NewImpersonationCache(s.rdb)
+}
+
+func (s *ImpersonationCacheSuite) TestSaveGetAndDelete() {
+	now := time.Now().UTC().Truncate(time.Second)
+	session := &service.ImpersonationSession{
+		ID: "imp-1", AdminID: 1, AdminEmail: "admin@example.com", TargetUserID: 7,
+		Scope: service.ImpersonationScopeRead, Reason: "ticket", StartedAt: now, ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(s.T(), s.cache.SaveImpersonation(s.ctx, session, time.Hour))
+
+	got, err := s.cache.GetImpersonation(s.ctx, "imp-1")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(7), got.TargetUserID)
+	require.Equal(s.T(), "admin@example.com", got.AdminEmail)
+	require.True(s.T(), got.ExpiresAt.Equal(session.ExpiresAt))
+
+	ttl, err := s.rdb.TTL(s.ctx, impersonationKey("imp-1")).Result()
+	require.NoError(s.T(), err)
+	require.Greater(s.T(), ttl, 59*time.Minute)
+
+	require.NoError(s.T(), s.cache.DeleteImpersonation(s.ctx, "imp-1"))
+	missing, err := s.cache.GetImpersonation(s.ctx, "imp-1")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), missing)
+}
+
+func TestImpersonationCacheSuite(t *testing.T) {
+	suite.Run(t, new(ImpersonationCacheSuite))
+}
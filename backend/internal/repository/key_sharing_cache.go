@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// Key 来源 IP 计数缓存
+//
+// 设计说明：
+// 每个 API Key 每小时一个 Set 记录出现过的来源 IP：
+// - Key: key_ips:{apiKeyID}:{hourUnix}
+// - Member: 客户端 IP
+// - TTL: 2 小时（覆盖当前小时 + 冗余）
+//
+// 使用 Lua 脚本保证"已存在放行 / 未满加入 / 已满拒绝"的原子性；脚本只访问单个 key，兼容 Redis Cluster。
+const (
+	keySharingIPKeyPrefix = "key_ips:"
+	keySharingIPKeyTTL    = 2 * time.Hour
+)
+
+var (
+	// trackKeyIPScript 记录来源 IP 并校验上限
+	// KEYS[1] = 小时 Set 键
+	// ARGV[1] = 客户端 IP
+	// ARGV[2] = 不同 IP 上限
+	// ARGV[3] = TTL（秒）
+	// 返回 1 放行，0 超限
+	trackKeyIPScript = redis.NewScript(`
+		local key = KEYS[1]
+		local ip = ARGV[1]
+		local limit = tonumber(ARGV[2])
+		local ttl = tonumber(ARGV[3])
+
+		if redis.call('SISMEMBER', key, ip) == 1 then
+			return 1
+		end
+		if redis.call('SCARD', key) >= limit then
+			return 0
+		end
+		redis.call('SADD', key, ip)
+		redis.call('EXPIRE', key, ttl)
+		return 1
+	`)
+)
+
+type keySharingCache struct {
+	rdb *redis.Client
+}
+
+// NewKeySharingCache 创建 Key 来源 IP 计数缓存
+func NewKeySharingCache(rdb *redis.Client) service.KeySharingCache {
+	return &keySharingCache{rdb: rdb}
+}
+
+func (c *keySharingCache) TrackKeyIP(ctx context.Context, apiKeyID int64, ip string, bucket time.Time, limit int) (bool, error) {
+	key := fmt.Sprintf("%s%d:%d", keySharingIPKeyPrefix, apiKeyID, bucket.Unix())
+	result, err := trackKeyIPScript.Run(ctx, c.rdb, []string{key}, ip, limit, int(keySharingIPKeyTTL.Seconds())).Int()
+	if err != nil {
+		return false, fmt.Errorf("track key ip: %w", err)
+	}
+	return result == 1, nil
+}
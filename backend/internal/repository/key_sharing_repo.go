@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// keySharingNetworkExpr 网段表达式：IPv4 取前两段（/16），IPv6 取前两组（/32），作为 ASN/地域分布的近似
+const keySharingNetworkExpr = `CASE
+	WHEN strpos(ip_address, ':') > 0 THEN split_part(ip_address, ':', 1) || ':' || split_part(ip_address, ':', 2)
+	ELSE split_part(ip_address, '.', 1) || '.' || split_part(ip_address, '.', 2)
+END`
+
+const keySharingReviewSelectColumns = `
+	id, api_key_id, user_id, score, evidence, status, note,
+	reviewed_by, reviewed_at, detected_at, created_at, updated_at
+`
+
+type keySharingRepository struct {
+	sql sqlExecutor
+}
+
+// NewKeySharingRepository 创建 Key 共享检测仓储
+func NewKeySharingRepository(sqlDB *sql.DB) service.KeySharingRepository {
+	return &keySharingRepository{sql: sqlDB}
+}
+
+func (r *keySharingRepository) ListSignals(ctx context.Context, since time.Time, minRequests int64) ([]service.KeySharingSignals, error) {
+	// 先筛出多 IP 的 Key，再计算网段/UA 数与每分钟并发 IP 峰值
+	query := `
+		WITH recent AS (
+			SELECT api_key_id, user_id, ip_address, user_agent, created_at
+			FROM usage_logs
+			WHERE created_at >= $1 AND ip_address IS NOT NULL AND ip_address <> ''
+		),
+		candidates AS (
+			SELECT
+				api_key_id,
+				MAX(user_id) AS user_id,
+				COUNT(*) AS requests,
+				COUNT(DISTINCT ip_address) AS distinct_ips,
+				COUNT(DISTINCT ` + keySharingNetworkExpr + `) AS distinct_networks,
+				COUNT(DISTINCT NULLIF(user_agent, '')) AS distinct_user_agents
+			FROM recent
+			GROUP BY api_key_id
+			HAVING COUNT(*) >= $2 AND COUNT(DISTINCT ip_address) > 1
+		),
+		concurrency AS (
+			SELECT api_key_id, MAX(ips) AS concurrent_ips
+			FROM (
+				SELECT recent.api_key_id, date_trunc('minute', recent.created_at) AS minute_bucket, COUNT(DISTINCT recent.ip_address) AS ips
+				FROM recent
+				JOIN candidates ON candidates.api_key_id = recent.api_key_id
+				GROUP BY recent.api_key_id, minute_bucket
+			) per_minute
+			GROUP BY api_key_id
+		)
+		SELECT c.api_key_id, c.user_id, c.requests, c.distinct_ips, c.distinct_networks, c.distinct_user_agents, COALESCE(cc.concurrent_ips, 0)
+		FROM candidates c
+		LEFT JOIN concurrency cc ON cc.api_key_id = c.api_key_id
+		ORDER BY c.api_key_id
+	`
+	rows, err := r.sql.QueryContext(ctx, query, since.UTC(), minRequests)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.KeySharingSignals, 0)
+	for rows.Next() {
+		var s service.KeySharingSignals
+		if err := rows.Scan(&s.APIKeyID, &s.UserID, &s.Requests, &s.DistinctIPs, &s.DistinctNetworks, &s.DistinctUserAgents, &s.ConcurrentIPs); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *keySharingRepository) ListTopSources(ctx context.Context, apiKeyID int64, since time.Time, limit int) ([]service.KeySharingSourceCount, []service.KeySharingSourceCount, error) {
+	ips, err := r.listTopValues(ctx, "ip_address", apiKeyID, since, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	userAgents, err := r.listTopValues(ctx, "user_agent", apiKeyID, since, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ips, userAgents, nil
+}
+
+// listTopValues column 仅由内部传入固定列名
+func (r *keySharingRepository) listTopValues(ctx context.Context, column string, apiKeyID int64, since time.Time, limit int) ([]service.KeySharingSourceCount, error) {
+	query := `
+		SELECT ` + column + `, COUNT(*) AS requests
+		FROM usage_logs
+		WHERE api_key_id = $1 AND created_at >= $2 AND ` + column + ` IS NOT NULL AND ` + column + ` <> ''
+		GROUP BY ` + column + `
+		ORDER BY requests DESC, ` + column + `
+		LIMIT $3
+	`
+	rows, err := r.sql.QueryContext(ctx, query, apiKeyID, since.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.KeySharingSourceCount, 0, limit)
+	for rows.Next() {
+		var item service.KeySharingSourceCount
+		if err := rows.Scan(&item.Value, &item.Requests); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (r *keySharingRepository) UpsertPendingReview(ctx context.Context, review *service.KeySharingReview) error {
+	evidence, err := json.Marshal(review.Evidence)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO api_key_sharing_reviews (api_key_id, user_id, score, evidence, status, detected_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'pending', $5, NOW(), NOW())
+		ON CONFLICT (api_key_id) WHERE status = 'pending' DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			score = EXCLUDED.score,
+			evidence = EXCLUDED.evidence,
+			detected_at = EXCLUDED.detected_at,
+			updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{review.APIKeyID, review.UserID, review.Score, evidence, review.DetectedAt.UTC()},
+		&review.ID, &review.CreatedAt, &review.UpdatedAt,
+	)
+}
+
+func (r *keySharingRepository) HasRecentDismissal(ctx context.Context, apiKeyID int64, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM api_key_sharing_reviews
+			WHERE api_key_id = $1 AND status = 'dismissed' AND reviewed_at >= $2
+		)
+	`
+	var exists bool
+	if err := scanSingleRow(ctx, r.sql, query, []any{apiKeyID, since.UTC()}, &exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *keySharingRepository) ListReviews(ctx context.Context, status string, params pagination.PaginationParams) ([]service.KeySharingReview, *pagination.PaginationResult, error) {
+	where := ""
+	args := []any{}
+	if status != "" {
+		where = "WHERE status = $1"
+		args = append(args, status)
+	}
+
+	var total int64
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM api_key_sharing_reviews "+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.KeySharingReview{}, paginationResultFromTotal(0, params), nil
+	}
+
+	limitPos := len(args) + 1
+	query := "SELECT " + keySharingReviewSelectColumns + " FROM api_key_sharing_reviews " + where +
+		" ORDER BY detected_at DESC, id DESC LIMIT $" + itoa(limitPos) + " OFFSET $" + itoa(limitPos+1)
+	rows, err := r.sql.QueryContext(ctx, query, append(args, params.Limit(), params.Offset())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	reviews := make([]service.KeySharingReview, 0)
+	for rows.Next() {
+		review, err := scanKeySharingReview(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return reviews, paginationResultFromTotal(total, params), nil
+}
+
+func (r *keySharingRepository) GetReview(ctx context.Context, id int64) (*service.KeySharingReview, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+keySharingReviewSelectColumns+" FROM api_key_sharing_reviews WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	review, err := scanKeySharingReview(rows)
+	if err != nil {
+		return nil, err
+	}
+	return review, rows.Err()
+}
+
+func (r *keySharingRepository) ResolveReview(ctx context.Context, id int64, status, note string, reviewedBy int64, reviewedAt time.Time) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, `
+		UPDATE api_key_sharing_reviews
+		SET status = $2, note = $3, reviewed_by = $4, reviewed_at = $5, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, note, reviewedBy, reviewedAt.UTC())
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanKeySharingReview(rows *sql.Rows) (*service.KeySharingReview, error) {
+	var (
+		review     service.KeySharingReview
+		evidence   []byte
+		reviewedBy sql.NullInt64
+		reviewedAt sql.NullTime
+	)
+	if err := rows.Scan(
+		&review.ID, &review.APIKeyID, &review.UserID, &review.Score, &evidence, &review.Status, &review.Note,
+		&reviewedBy, &reviewedAt, &review.DetectedAt, &review.CreatedAt, &review.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(evidence) > 0 {
+		if err := json.Unmarshal(evidence, &review.Evidence); err != nil {
+			return nil, fmt.Errorf("parse key sharing evidence: %w", err)
+		}
+	}
+	if reviewedBy.Valid {
+		v := reviewedBy.Int64
+		review.ReviewedBy = &v
+	}
+	if reviewedAt.Valid {
+		v := reviewedAt.Time
+		review.ReviewedAt = &v
+	}
+	return &review, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const oidcStateKeyPrefix = "oidc:state:"
+
+type oidcStateCache struct {
+	rdb *redis.Client
+}
+
+// NewOIDCStateCache 创建 OIDC 授权状态缓存
+func NewOIDCStateCache(rdb *redis.Client) service.OIDCStateStore {
+	return &oidcStateCache{rdb: rdb}
+}
+
+func (c *oidcStateCache) SaveState(ctx context.Context, state string, data *service.OIDCAuthState, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal oidc state: %w", err)
+	}
+	if err := c.rdb.Set(ctx, oidcStateKeyPrefix+state, payload, ttl).Err(); err != nil {
+		return fmt.Errorf("set oidc state: %w", err)
+	}
+	return nil
+}
+
+func (c *oidcStateCache) ConsumeState(ctx context.Context, state string) (*service.OIDCAuthState, error) {
+	// GETDEL 保证 state 只能被使用一次
+	payload, err := c.rdb.GetDel(ctx, oidcStateKeyPrefix+state).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get oidc state: %w", err)
+	}
+	var data service.OIDCAuthState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal oidc state: %w", err)
+	}
+	return &data, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const passkeyCeremonyKeyPrefix = "passkey:ceremony:"
+
+type passkeyCeremonyCache struct {
+	rdb *redis.Client
+}
+
+// NewPasskeyCeremonyCache 创建 WebAuthn 仪式状态缓存
+func NewPasskeyCeremonyCache(rdb *redis.Client) service.PasskeyCeremonyStore {
+	return &passkeyCeremonyCache{rdb: rdb}
+}
+
+func (c *passkeyCeremonyCache) SaveCeremony(ctx context.Context, token string, ceremony *service.PasskeyCeremony, ttl time.Duration) error {
+	payload, err := json.Marshal(ceremony)
+	if err != nil {
+		return fmt.Errorf("marshal passkey ceremony: %w", err)
+	}
+	if err := c.rdb.Set(ctx, passkeyCeremonyKeyPrefix+token, payload, ttl).Err(); err != nil {
+		return fmt.Errorf("set passkey ceremony: %w", err)
+	}
+	return nil
+}
+
+func (c *passkeyCeremonyCache) ConsumeCeremony(ctx context.Context, token string) (*service.PasskeyCeremony, error) {
+	// GETDEL 保证挑战只能被使用一次
+	payload, err := c.rdb.GetDel(ctx, passkeyCeremonyKeyPrefix+token).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get passkey ceremony: %w", err)
+	}
+	var ceremony service.PasskeyCeremony
+	if err := json.Unmarshal(payload, &ceremony); err != nil {
+		return nil, fmt.Errorf("unmarshal passkey ceremony: %w", err)
+	}
+	return &ceremony, nil
+}
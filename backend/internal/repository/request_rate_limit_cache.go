@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// 请求速率（RPM/TPM）滑动窗口缓存
+//
+// 设计说明：
+// 每个限流维度（api_key / user / group）使用一个 Redis Hash 保存秒级时间桶：
+// - Key: request_rate_limit:{scope}:{subjectID}
+// - Field: r:{unixSecond}（请求数）/ t:{unixSecond}（token 数）
+// - TTL: 窗口长度 + 60 秒冗余
+//
+// 检查时累加最近 60 个秒级桶得到滑动窗口内的用量，并顺带清理过期桶，Hash 字段数上限约 120。
+// 每次脚本调用只访问一个 key，兼容 Redis Cluster；使用 Redis TIME 避免多实例时钟偏差。
+const (
+	requestRateLimitKeyPrefix = "request_rate_limit:"
+	requestRateLimitKeyTTL    = service.RequestRateLimitWindowSeconds + 60
+)
+
+var (
+	// requestRateLimitAcquireScript 检查滑动窗口用量，未超限时计入本次请求
+	// KEYS[1] = request_rate_limit:{scope}:{subjectID}
+	// ARGV[1] = rpm 上限（0 不限制）
+	// ARGV[2] = tpm 上限（0 不限制）
+	// ARGV[3] = 窗口长度（秒）
+	// ARGV[4] = key TTL（秒）
+	// 返回: {allowed, requests, tokens, resetRequests, resetTokens, retryAfter, requestsExceeded, bucket}
+	requestRateLimitAcquireScript = redis.NewScript(`
+		local key = KEYS[1]
+		local rpm = tonumber(ARGV[1])
+		local tpm = tonumber(ARGV[2])
+		local window = tonumber(ARGV[3])
+		local ttl = tonumber(ARGV[4])
+
+		local now = tonumber(redis.call('TIME')[1])
+		local windowStart = now - window + 1
+
+		local requests, tokens = 0, 0
+		local reqBuckets, tokBuckets = {}, {}
+		local data = redis.call('HGETALL', key)
+		for i = 1, #data, 2 do
+			local field = data[i]
+			local sec = tonumber(string.sub(field, 3))
+			local val = tonumber(data[i + 1]) or 0
+			if sec == nil or sec < windowStart then
+				redis.call('HDEL', key, field)
+			elseif val > 0 then
+				if string.sub(field, 1, 1) == 'r' then
+					requests = requests + val
+					table.insert(reqBuckets, {sec, val})
+				else
+					tokens = tokens + val
+					table.insert(tokBuckets, {sec, val})
+				end
+			end
+		end
+
+		local byTime = function(a, b) return a[1] < b[1] end
+		table.sort(reqBuckets, byTime)
+		table.sort(tokBuckets, byTime)
+
+		-- 最早的桶移出窗口所需秒数
+		local function resetAfter(buckets)
+			if #buckets == 0 then
+				return 0
+			end
+			return buckets[1][1] + window - now
+		end
+
+		-- 用量回落到 limit 以下（可再容纳一次请求）所需秒数
+		local function retryAfter(buckets, total, limit)
+			for _, b in ipairs(buckets) do
+				total = total - b[2]
+				if total < limit then
+					return b[1] + window - now
+				end
+			end
+			return window
+		end
+
+		if rpm > 0 and requests >= rpm then
+			return {0, requests, tokens, resetAfter(reqBuckets), resetAfter(tokBuckets), retryAfter(reqBuckets, requests, rpm), 1, now}
+		end
+		if tpm > 0 and tokens >= tpm then
+			return {0, requests, tokens, resetAfter(reqBuckets), resetAfter(tokBuckets), retryAfter(tokBuckets, tokens, tpm), 0, now}
+		end
+
+		redis.call('HINCRBY', key, 'r:' .. now, 1)
+		redis.call('EXPIRE', key, ttl)
+		if #reqBuckets == 0 then
+			table.insert(reqBuckets, {now, 1})
+		end
+		return {1, requests + 1, tokens, resetAfter(reqBuckets), resetAfter(tokBuckets), 0, 0, now}
+	`)
+
+	// requestRateLimitAddTokensScript 将 token 用量计入当前秒级桶
+	// KEYS[1] = request_rate_limit:{scope}:{subjectID}
+	// ARGV[1] = tokens
+	// ARGV[2] = key TTL（秒）
+	requestRateLimitAddTokensScript = redis.NewScript(`
+		local now = redis.call('TIME')[1]
+		redis.call('HINCRBY', KEYS[1], 't:' .. now, tonumber(ARGV[1]))
+		redis.call('EXPIRE', KEYS[1], tonumber(ARGV[2]))
+		return 1
+	`)
+)
+
+type requestRateLimitCache struct {
+	rdb *redis.Client
+}
+
+// NewRequestRateLimitCache 创建 RPM/TPM 滑动窗口缓存
+func NewRequestRateLimitCache(rdb *redis.Client) service.RequestRateLimitCache {
+	return &requestRateLimitCache{rdb: rdb}
+}
+
+func requestRateLimitKey(rule service.RequestRateLimitRule) string {
+	return fmt.Sprintf("%s%s:%d", requestRateLimitKeyPrefix, rule.Scope, rule.SubjectID)
+}
+
+func (c *requestRateLimitCache) Acquire(ctx context.Context, rule service.RequestRateLimitRule) (*service.RequestRateLimitWindow, error) {
+	values, err := requestRateLimitAcquireScript.Run(ctx, c.rdb, []string{requestRateLimitKey(rule)},
+		rule.RPM, rule.TPM, service.RequestRateLimitWindowSeconds, requestRateLimitKeyTTL).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("request rate limit acquire: %w", err)
+	}
+	if len(values) < 8 {
+		return nil, fmt.Errorf("request rate limit acquire: script returned %d values", len(values))
+	}
+	return &service.RequestRateLimitWindow{
+		Allowed:          values[0] == 1,
+		Requests:         int(values[1]),
+		Tokens:           int(values[2]),
+		ResetRequests:    time.Duration(values[3]) * time.Second,
+		ResetTokens:      time.Duration(values[4]) * time.Second,
+		RetryAfter:       time.Duration(values[5]) * time.Second,
+		RequestsExceeded: values[6] == 1,
+		Bucket:           values[7],
+	}, nil
+}
+
+func (c *requestRateLimitCache) Release(ctx context.Context, rule service.RequestRateLimitRule, bucket int64) error {
+	if err := c.rdb.HIncrBy(ctx, requestRateLimitKey(rule), fmt.Sprintf("r:%d", bucket), -1).Err(); err != nil {
+		return fmt.Errorf("request rate limit release: %w", err)
+	}
+	return nil
+}
+
+func (c *requestRateLimitCache) AddTokens(ctx context.Context, rule service.RequestRateLimitRule, tokens int) error {
+	if tokens <= 0 {
+		return nil
+	}
+	if err := requestRateLimitAddTokensScript.Run(ctx, c.rdb, []string{requestRateLimitKey(rule)}, tokens, requestRateLimitKeyTTL).Err(); err != nil {
+		return fmt.Errorf("request rate limit add tokens: %w", err)
+	}
+	return nil
+}
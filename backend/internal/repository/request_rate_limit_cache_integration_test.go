@@ -0,0 +1,75 @@
+//go:build integration
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestRateLimitCacheSuite struct {
+	IntegrationRedisSuite
+	cache service.RequestRateLimitCache
+}
+
+func (s *RequestRateLimitCacheSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.cache = NewRequestRateLimitCache(s.rdb)
+}
+
+func (s *RequestRateLimitCacheSuite) TestAcquire_RPM() {
+	rule := service.RequestRateLimitRule{Scope: service.RequestRateLimitScopeAPIKey, SubjectID: 1, RPM: 2}
+
+	w, err := s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.True(s.T(), w.Allowed)
+	require.Equal(s.T(), 1, w.Requests)
+	require.Positive(s.T(), w.ResetRequests)
+
+	w, err = s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.True(s.T(), w.Allowed)
+	require.Equal(s.T(), 2, w.Requests)
+
+	w, err = s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.False(s.T(), w.Allowed)
+	require.True(s.T(), w.RequestsExceeded)
+	require.Positive(s.T(), w.RetryAfter)
+
+	// 回滚一次后可再次通过
+	require.NoError(s.T(), s.cache.Release(s.ctx, rule, w.Bucket))
+	w, err = s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.True(s.T(), w.Allowed)
+
+	ttl, err := s.rdb.TTL(s.ctx, requestRateLimitKey(rule)).Result()
+	require.NoError(s.T(), err)
+	require.Positive(s.T(), ttl)
+}
+
+func (s *RequestRateLimitCacheSuite) TestAcquire_TPM() {
+	rule := service.RequestRateLimitRule{Scope: service.RequestRateLimitScopeUser, SubjectID: 2, TPM: 100}
+
+	w, err := s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.True(s.T(), w.Allowed)
+	require.Equal(s.T(), 0, w.Tokens)
+
+	require.NoError(s.T(), s.cache.AddTokens(s.ctx, rule, 60))
+	require.NoError(s.T(), s.cache.AddTokens(s.ctx, rule, 40))
+
+	w, err = s.cache.Acquire(s.ctx, rule)
+	require.NoError(s.T(), err)
+	require.False(s.T(), w.Allowed)
+	require.False(s.T(), w.RequestsExceeded)
+	require.Equal(s.T(), 100, w.Tokens)
+	require.Positive(s.T(), w.RetryAfter)
+}
+
+func TestRequestRateLimitCacheSuite(t *testing.T) {
+	suite.Run(t, new(RequestRateLimitCacheSuite))
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// 网关响应缓存
+//
+// 设计说明：
+//   - 条目：response_cache:{key}，String，TTL 为分组配置的有效期
+//   - 范围索引：response_cache_index:{scope}（ZSet，member=条目 key，score=写入时间毫秒）
+//     与 response_cache_size:{scope}（Hash，field=条目 key，value=字节数），两者以 {scope} 作为 hash tag 落在同一 slot
+//
+// 写入条目后由脚本更新范围索引：清理已过期的索引项，累计范围总量，超过上限时按写入时间从早到晚淘汰，
+// 返回被淘汰的条目 key，由调用方逐个删除（条目与索引不在同一 slot，不在脚本中跨 key 删除，兼容 Redis Cluster）。
+const (
+	responseCacheKeyPrefix      = "response_cache:"
+	responseCacheIndexKeyPrefix = "response_cache_index:"
+	responseCacheSizeKeyPrefix  = "response_cache_size:"
+)
+
+// responseCacheKey generates the Redis key for a cached gateway response.
+func responseCacheKey(key string) string {
+	return responseCacheKeyPrefix + key
+}
+
+func responseCacheIndexKeys(scope string) []string {
+	tag := "{" + scope + "}"
+	return []string{responseCacheIndexKeyPrefix + tag, responseCacheSizeKeyPrefix + tag}
+}
+
+var (
+	// responseCacheTrackScript 记录条目大小并按范围总量上限淘汰最早的条目
+	// KEYS[1] = response_cache_index:{scope}
+	// KEYS[2] = response_cache_size:{scope}
+	// ARGV[1] = 条目 key
+	// ARGV[2] = 条目字节数
+	// ARGV[3] = TTL（秒）
+	// ARGV[4] = 范围总量上限（字节，0 不限制）
+	// 返回: 被淘汰的条目 key 列表
+	responseCacheTrackScript = redis.NewScript(`
+		local index = KEYS[1]
+		local sizes = KEYS[2]
+		local member = ARGV[1]
+		local ttl = tonumber(ARGV[3])
+		local maxTotal = tonumber(ARGV[4])
+		local t = redis.call('TIME')
+		local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+		local expired = redis.call('ZRANGEBYSCORE', index, '-inf', now - ttl * 1000)
+		for _, m in ipairs(expired) do
+			redis.call('ZREM', index, m)
+			redis.call('HDEL', sizes, m)
+		end
+
+		redis.call('ZADD', index, now, member)
+		redis.call('HSET', sizes, member, ARGV[2])
+
+		local total = 0
+		for _, v in ipairs(redis.call('HVALS', sizes)) do
+			total = total + (tonumber(v) or 0)
+		end
+
+		local evicted = {}
+		while maxTotal > 0 and total > maxTotal do
+			local oldest = redis.call('ZRANGE', index, 0, 0)
+			if #oldest == 0 or oldest[1] == member then
+				break
+			end
+			local m = oldest[1]
+			total = total - (tonumber(redis.call('HGET', sizes, m)) or 0)
+			redis.call('ZREM', index, m)
+			redis.call('HDEL', sizes, m)
+			table.insert(evicted, m)
+		end
+
+		redis.call('EXPIRE', index, ttl)
+		redis.call('EXPIRE', sizes, ttl)
+		return evicted
+	`)
+)
+
+type responseCacheStore struct {
+	rdb *redis.Client
+}
+
+// NewResponseCacheStore creates a Redis-backed gateway response cache.
+func NewResponseCacheStore(rdb *redis.Client) service.ResponseCacheStore {
+	return &responseCacheStore{rdb: rdb}
+}
+
+func (c *responseCacheStore) Get(ctx context.Context, key string) (*service.ResponseCacheEntry, error) {
+	val, err := c.rdb.Get(ctx, responseCacheKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entry service.ResponseCacheEntry
+	if err := json.Unmarshal(val, &entry); err != nil {
+		return nil, fmt.Errorf("unmarshal response cache entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (c *responseCacheStore) Set(ctx context.Context, scope, key string, entry *service.ResponseCacheEntry, ttl time.Duration, maxTotalBytes int) error {
+	val, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal response cache entry: %w", err)
+	}
+	if err := c.rdb.Set(ctx, responseCacheKey(key), val, ttl).Err(); err != nil {
+		return err
+	}
+
+	ttlSeconds := int64(ttl / time.Second)
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+	evicted, err := responseCacheTrackScript.Run(ctx, c.rdb, responseCacheIndexKeys(scope), key, len(val), ttlSeconds, maxTotalBytes).StringSlice()
+	if err != nil {
+		return fmt.Errorf("track response cache size: %w", err)
+	}
+	for _, member := range evicted {
+		if err := c.rdb.Del(ctx, responseCacheKey(member)).Err(); err != nil {
+			return fmt.Errorf("evict response cache entry: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResponseCacheStoreSuite struct {
+	IntegrationRedisSuite
+	store service.ResponseCacheStore
+}
+
+func (s *ResponseCacheStoreSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.store = NewResponseCacheStore(s.rdb)
+}
+
+func (s *ResponseCacheStoreSuite) TestSetAndGet() {
+	entry := &service.ResponseCacheEntry{Body: []byte(`{"ok":true}`), Model: "claude"}
+	require.NoError(s.T(), s.store.Set(s.ctx, "k1", "k1:a", entry, time.Minute, 1<<20))
+
+	got, err := s.store.Get(s.ctx, "k1:a")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "claude", got.Model)
+
+	ttl, err := s.rdb.TTL(s.ctx, responseCacheKey("k1:a")).Result()
+	require.NoError(s.T(), err)
+	s.AssertTTLWithin(ttl, time.Second, time.Minute)
+
+	missing, err := s.store.Get(s.ctx, "k1:missing")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), missing)
+}
+
+func (s *ResponseCacheStoreSuite) TestSetEvictsOldestWhenScopeExceedsTotal() {
+	body := []byte(strings.Repeat("x", 400))
+	for _, key := range []string{"g7:a", "g7:b", "g7:c"} {
+		require.NoError(s.T(), s.store.Set(s.ctx, "g7", key, &service.ResponseCacheEntry{Body: body}, time.Minute, 1200))
+		time.Sleep(5 * time.Millisecond)
+	}
+	// 其他范围不受影响
+	require.NoError(s.T(), s.store.Set(s.ctx, "g8", "g8:a", &service.ResponseCacheEntry{Body: body}, time.Minute, 1200))
+
+	evicted, err := s.store.Get(s.ctx, "g7:a")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), evicted, "最早写入的条目被淘汰")
+	for _, key := range []string{"g7:b", "g7:c", "g8:a"} {
+		got, err := s.store.Get(s.ctx, key)
+		require.NoError(s.T(), err)
+		require.NotNil(s.T(), got, key)
+	}
+
+	members, err := s.rdb.ZRange(s.ctx, responseCacheIndexKeys("g7")[0], 0, -1).Result()
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{"g7:b", "g7:c"}, members)
+}
+
+func TestResponseCacheStoreSuite(t *testing.T) {
+	suite.Run(t, new(ResponseCacheStoreSuite))
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const shadowMirrorSelectColumns = `
+	id, group_id, api_key_id, model, stream, primary_account_id, shadow_account_id,
+	primary_status, shadow_status, primary_latency_ms, shadow_latency_ms,
+	primary_input_tokens, primary_output_tokens, shadow_input_tokens, shadow_output_tokens,
+	similarity, error_message, created_at
+`
+
+type shadowMirrorRepository struct {
+	sql sqlExecutor
+}
+
+// NewShadowMirrorRepository 创建影子镜像对比记录仓储
+func NewShadowMirrorRepository(sqlDB *sql.DB) service.ShadowMirrorRepository {
+	return &shadowMirrorRepository{sql: sqlDB}
+}
+
+func (r *shadowMirrorRepository) Create(ctx context.Context, result *service.ShadowMirrorResult) error {
+	query := `
+		INSERT INTO shadow_mirror_results
+			(group_id, api_key_id, model, stream, primary_account_id, shadow_account_id,
+			 primary_status, shadow_status, primary_latency_ms, shadow_latency_ms,
+			 primary_input_tokens, primary_output_tokens, shadow_input_tokens, shadow_output_tokens,
+			 similarity, error_message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+		RETURNING id, created_at
+	`
+	return scanSingleRow(ctx, r.sql, query,
+		[]any{
+			result.GroupID, result.APIKeyID, result.Model, result.Stream, result.PrimaryAccountID, result.ShadowAccountID,
+			result.PrimaryStatus, result.ShadowStatus, result.PrimaryLatencyMs, result.ShadowLatencyMs,
+			result.PrimaryInputTokens, result.PrimaryOutputTokens, result.ShadowInputTokens, result.ShadowOutputTokens,
+			result.Similarity, result.ErrorMessage,
+		},
+		&result.ID, &result.CreatedAt,
+	)
+}
+
+func shadowMirrorWhere(filter service.ShadowMirrorFilter) (string, []any) {
+	conds := make([]string, 0, 3)
+	args := []any{}
+	if filter.GroupID > 0 {
+		args = append(args, filter.GroupID)
+		conds = append(conds, "group_id = $"+itoa(len(args)))
+	}
+	if filter.StartTime != nil {
+		args = append(args, *filter.StartTime)
+		conds = append(conds, "created_at >= $"+itoa(len(args)))
+	}
+	if filter.EndTime != nil {
+		args = append(args, *filter.EndTime)
+		conds = append(conds, "created_at < $"+itoa(len(args)))
+	}
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (r *shadowMirrorRepository) List(ctx context.Context, filter service.ShadowMirrorFilter, params pagination.PaginationParams) ([]service.ShadowMirrorResult, *pagination.PaginationResult, error) {
+	where, args := shadowMirrorWhere(filter)
+
+	var total int64
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM shadow_mirror_results "+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.ShadowMirrorResult{}, paginationResultFromTotal(0, params), nil
+	}
+
+	limitPos := len(args) + 1
+	query := "SELECT " + shadowMirrorSelectColumns + " FROM shadow_mirror_results " + where +
+		" ORDER BY created_at DESC, id DESC LIMIT $" + itoa(limitPos) + " OFFSET $" + itoa(limitPos+1)
+	rows, err := r.sql.QueryContext(ctx, query, append(args, params.Limit(), params.Offset())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := make([]service.ShadowMirrorResult, 0)
+	for rows.Next() {
+		var (
+			item            service.ShadowMirrorResult
+			shadowAccountID sql.NullInt64
+			similarity      sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&item.ID, &item.GroupID, &item.APIKeyID, &item.Model, &item.Stream, &item.PrimaryAccountID, &shadowAccountID,
+			&item.PrimaryStatus, &item.ShadowStatus, &item.PrimaryLatencyMs, &item.ShadowLatencyMs,
+			&item.PrimaryInputTokens, &item.PrimaryOutputTokens, &item.ShadowInputTokens, &item.ShadowOutputTokens,
+			&similarity, &item.ErrorMessage, &item.CreatedAt,
+		); err != nil {
+			return nil, nil, err
+		}
+		if shadowAccountID.Valid {
+			v := shadowAccountID.Int64
+			item.ShadowAccountID = &v
+		}
+		if similarity.Valid {
+			v := similarity.Float64
+			item.Similarity = &v
+		}
+		results = append(results, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return results, paginationResultFromTotal(total, params), nil
+}
+
+func (r *shadowMirrorRepository) Summary(ctx context.Context, filter service.ShadowMirrorFilter) (*service.ShadowMirrorSummary, error) {
+	where, args := shadowMirrorWhere(filter)
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE shadow_status > 0 AND shadow_status < 400),
+			COUNT(*) FILTER (WHERE shadow_status >= 400),
+			COUNT(*) FILTER (WHERE shadow_status = 0),
+			COALESCE(AVG(primary_latency_ms), 0),
+			COALESCE(AVG(shadow_latency_ms) FILTER (WHERE shadow_status > 0), 0),
+			COALESCE(AVG(primary_output_tokens), 0),
+			COALESCE(AVG(shadow_output_tokens) FILTER (WHERE shadow_status > 0 AND shadow_status < 400), 0),
+			AVG(similarity)
+		FROM shadow_mirror_results ` + where
+
+	summary := &service.ShadowMirrorSummary{}
+	var avgSimilarity sql.NullFloat64
+	if err := scanSingleRow(ctx, r.sql, query, args,
+		&summary.Total, &summary.ShadowSucceeded, &summary.ShadowFailed, &summary.Skipped,
+		&summary.AvgPrimaryLatencyMs, &summary.AvgShadowLatencyMs,
+		&summary.AvgPrimaryOutputTokens, &summary.AvgShadowOutputTokens,
+		&avgSimilarity,
+	); err != nil {
+		return nil, err
+	}
+	if avgSimilarity.Valid {
+		v := avgSimilarity.Float64
+		summary.AvgSimilarity = &v
+	}
+	return summary, nil
+}
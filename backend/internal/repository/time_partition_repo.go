@@ -0,0 +1,538 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+// 在线转换使用的对象命名：
+//
+//	{table}_partitioned  影子分区表，替换完成后改名为 {table}
+//	{table}_legacy       替换后的原表，确认无误后手动删除
+//	{table}_YYYYMM       月分区
+const (
+	partitionShadowSuffix    = "_partitioned"
+	partitionLegacySuffix    = "_legacy"
+	partitionTempIndexSuffix = "_p"
+	partitionSyncFuncSuffix  = "_partition_sync"
+)
+
+type timePartitionRepository struct {
+	db *sql.DB
+}
+
+func NewTimePartitionRepository(db *sql.DB) service.TimePartitionRepository {
+	return &timePartitionRepository{db: db}
+}
+
+type partitionSourceIndex struct {
+	name      string
+	def       string
+	isPrimary bool
+	isUnique  bool
+}
+
+func (r *timePartitionRepository) IsPartitioned(ctx context.Context, table string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM pg_partitioned_table pt
+			JOIN pg_class c ON c.oid = pt.partrelid
+			WHERE c.relname = $1
+		)
+	`
+	var partitioned bool
+	if err := scanSingleRow(ctx, r.db, query, []any{table}, &partitioned); err != nil {
+		return false, err
+	}
+	return partitioned, nil
+}
+
+func (r *timePartitionRepository) ListPartitions(ctx context.Context, table string) ([]service.TimePartition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.relname, GREATEST(c.reltuples, 0)::bigint
+		FROM pg_inherits
+		JOIN pg_class c ON c.oid = pg_inherits.inhrelid
+		JOIN pg_class p ON p.oid = pg_inherits.inhparent
+		WHERE p.relname = $1
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	partitions := make([]service.TimePartition, 0)
+	for rows.Next() {
+		var name string
+		var estimated int64
+		if err := rows.Scan(&name, &estimated); err != nil {
+			return nil, err
+		}
+		month, ok := parsePartitionMonth(table, name)
+		if !ok {
+			continue
+		}
+		partitions = append(partitions, service.TimePartition{
+			Name:          name,
+			RangeStart:    month,
+			RangeEnd:      month.AddDate(0, 1, 0),
+			EstimatedRows: estimated,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].RangeStart.Before(partitions[j].RangeStart) })
+	return partitions, nil
+}
+
+func (r *timePartitionRepository) CreateMonthlyPartition(ctx context.Context, table string, month time.Time) error {
+	return createMonthlyPartition(ctx, r.db, table, table, month)
+}
+
+func (r *timePartitionRepository) DropPartition(ctx context.Context, table string, name string) error {
+	if _, ok := parsePartitionMonth(table, name); !ok {
+		return fmt.Errorf("invalid partition name %q for table %s", name, table)
+	}
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", pq.QuoteIdentifier(name)))
+	return err
+}
+
+func (r *timePartitionRepository) OldestRowTime(ctx context.Context, table string) (*time.Time, error) {
+	var oldest sql.NullTime
+	query := fmt.Sprintf("SELECT MIN(created_at) FROM %s", pq.QuoteIdentifier(table))
+	if err := scanSingleRow(ctx, r.db, query, nil, &oldest); err != nil {
+		return nil, err
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	t := oldest.Time.UTC()
+	return &t, nil
+}
+
+func (r *timePartitionRepository) PruneUsageLogDedup(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	query := `
+		DELETE FROM usage_log_dedup
+		WHERE (request_id, api_key_id) IN (
+			SELECT request_id, api_key_id
+			FROM usage_log_dedup
+			WHERE created_at < $1
+			LIMIT $2
+		)
+	`
+	var total int64
+	for {
+		res, err := r.db.ExecContext(ctx, query, before.UTC(), batchSize)
+		if err != nil {
+			return total, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (r *timePartitionRepository) GetConversion(ctx context.Context, table string) (*service.TablePartitionConversion, error) {
+	query := `
+		SELECT table_name, status, last_copied_id, copied_rows, error_message, started_at, finished_at, updated_at
+		FROM table_partition_conversions
+		WHERE table_name = $1
+	`
+	var conversion service.TablePartitionConversion
+	var errMsg sql.NullString
+	var finishedAt sql.NullTime
+	err := scanSingleRow(ctx, r.db, query, []any{table},
+		&conversion.TableName,
+		&conversion.Status,
+		&conversion.LastCopiedID,
+		&conversion.CopiedRows,
+		&errMsg,
+		&conversion.StartedAt,
+		&finishedAt,
+		&conversion.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errMsg.Valid {
+		v := errMsg.String
+		conversion.ErrorMessage = &v
+	}
+	if finishedAt.Valid {
+		v := finishedAt.Time
+		conversion.FinishedAt = &v
+	}
+	return &conversion, nil
+}
+
+func (r *timePartitionRepository) ClaimConversion(ctx context.Context, table string) (bool, error) {
+	// 进程崩溃时状态会停留在 copying/swapping，超过 30 分钟无进度视为失效允许重新抢占
+	query := `
+		INSERT INTO table_partition_conversions (table_name, status)
+		VALUES ($1, $2)
+		ON CONFLICT (table_name) DO UPDATE
+		SET status = EXCLUDED.status,
+			last_copied_id = CASE WHEN table_partition_conversions.status = $4 THEN 0 ELSE table_partition_conversions.last_copied_id END,
+			copied_rows = CASE WHEN table_partition_conversions.status = $4 THEN 0 ELSE table_partition_conversions.copied_rows END,
+			error_message = NULL,
+			started_at = NOW(),
+			finished_at = NULL,
+			updated_at = NOW()
+		WHERE table_partition_conversions.status NOT IN ($2, $3)
+			OR table_partition_conversions.updated_at < NOW() - interval '30 minutes'
+		RETURNING table_name
+	`
+	var claimed string
+	err := scanSingleRow(ctx, r.db, query, []any{
+		table,
+		service.PartitionConversionCopying,
+		service.PartitionConversionSwapping,
+		service.PartitionConversionCompleted,
+	}, &claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *timePartitionRepository) UpdateConversion(ctx context.Context, table string, status string, lastCopiedID, copiedRows int64, errMsg *string) error {
+	query := `
+		UPDATE table_partition_conversions
+		SET status = $2,
+			last_copied_id = $3,
+			copied_rows = $4,
+			error_message = $5,
+			finished_at = CASE WHEN $2::text IN ($6::text, $7::text) THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE table_name = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, table, status, lastCopiedID, copiedRows, nullString(errMsg),
+		service.PartitionConversionCompleted, service.PartitionConversionFailed)
+	return err
+}
+
+func (r *timePartitionRepository) PrepareConversion(ctx context.Context, table string, from, to time.Time) (bool, error) {
+	shadow := table + partitionShadowSuffix
+
+	var exists bool
+	if err := scanSingleRow(ctx, r.db, "SELECT to_regclass($1) IS NOT NULL", []any{shadow}, &exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		createQuery := fmt.Sprintf(
+			"CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING STORAGE INCLUDING COMMENTS) PARTITION BY RANGE (created_at)",
+			pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(table),
+		)
+		if _, err := r.db.ExecContext(ctx, createQuery); err != nil {
+			return false, fmt.Errorf("create shadow table: %w", err)
+		}
+		pkQuery := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (id, created_at)",
+			pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(partitionTempName(table+"_pkey", partitionTempIndexSuffix)))
+		if _, err := r.db.ExecContext(ctx, pkQuery); err != nil {
+			return false, fmt.Errorf("add primary key: %w", err)
+		}
+	}
+
+	for month := truncateToMonthUTC(from); month.Before(to); month = month.AddDate(0, 1, 0) {
+		if err := createMonthlyPartition(ctx, r.db, shadow, table, month); err != nil {
+			return false, fmt.Errorf("create partition: %w", err)
+		}
+	}
+
+	// 普通索引照搬；分区表上的唯一索引必须包含分区键，这里降级为普通索引
+	//（usage_logs 的 request_id 幂等由 usage_log_dedup 承担）
+	indexes, err := r.listSourceIndexes(ctx, table)
+	if err != nil {
+		return false, err
+	}
+	for _, idx := range indexes {
+		if idx.isPrimary {
+			continue
+		}
+		query, err := rewriteIndexForShadow(idx, shadow)
+		if err != nil {
+			return false, err
+		}
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return false, fmt.Errorf("create index %s: %w", idx.name, err)
+		}
+	}
+
+	// 外键按原名复制（约束名仅需在表内唯一）
+	fkRows, err := r.db.QueryContext(ctx, `
+		SELECT conname, pg_get_constraintdef(oid)
+		FROM pg_constraint
+		WHERE conrelid = $1::regclass AND contype = 'f'
+			AND conname NOT IN (SELECT conname FROM pg_constraint WHERE conrelid = $2::regclass)
+	`, table, shadow)
+	if err != nil {
+		return false, err
+	}
+	type fkDef struct{ name, def string }
+	var fks []fkDef
+	for fkRows.Next() {
+		var fk fkDef
+		if err := fkRows.Scan(&fk.name, &fk.def); err != nil {
+			_ = fkRows.Close()
+			return false, err
+		}
+		fks = append(fks, fk)
+	}
+	if err := fkRows.Err(); err != nil {
+		_ = fkRows.Close()
+		return false, err
+	}
+	_ = fkRows.Close()
+	for _, fk := range fks {
+		query := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(fk.name), fk.def)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return false, fmt.Errorf("add foreign key %s: %w", fk.name, err)
+		}
+	}
+
+	// 复制期间原表上的 UPDATE/DELETE 通过触发器同步到影子表；INSERT 由分批复制与最终增量覆盖
+	fn := pq.QuoteIdentifier(table + partitionSyncFuncSuffix)
+	fnQuery := fmt.Sprintf(`
+		CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $fn$
+		BEGIN
+			DELETE FROM %s WHERE id = OLD.id AND created_at = OLD.created_at;
+			IF TG_OP = 'UPDATE' THEN
+				INSERT INTO %s SELECT NEW.* ON CONFLICT DO NOTHING;
+			END IF;
+			RETURN NULL;
+		END
+		$fn$
+	`, fn, pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(shadow))
+	if _, err := r.db.ExecContext(ctx, fnQuery); err != nil {
+		return false, fmt.Errorf("create sync function: %w", err)
+	}
+	trigger := pq.QuoteIdentifier("trg" + partitionSyncFuncSuffix)
+	if _, err := r.db.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger, pq.QuoteIdentifier(table))); err != nil {
+		return false, err
+	}
+	triggerQuery := fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s()",
+		trigger, pq.QuoteIdentifier(table), fn)
+	if _, err := r.db.ExecContext(ctx, triggerQuery); err != nil {
+		return false, fmt.Errorf("create sync trigger: %w", err)
+	}
+	return !exists, nil
+}
+
+func (r *timePartitionRepository) CopyConversionBatch(ctx context.Context, table string, afterID int64, limit int) (int64, int64, error) {
+	query := fmt.Sprintf(`
+		WITH batch AS (
+			SELECT * FROM %s WHERE id > $1 ORDER BY id LIMIT $2
+		), inserted AS (
+			INSERT INTO %s SELECT * FROM batch ON CONFLICT DO NOTHING
+		)
+		SELECT COALESCE(MAX(id), $1::bigint), COUNT(*) FROM batch
+	`, pq.QuoteIdentifier(table), pq.QuoteIdentifier(table+partitionShadowSuffix))
+	var lastID, scanned int64
+	if err := scanSingleRow(ctx, r.db, query, []any{afterID, limit}, &lastID, &scanned); err != nil {
+		return afterID, 0, err
+	}
+	return lastID, scanned, nil
+}
+
+func (r *timePartitionRepository) SwapConversion(ctx context.Context, table string, afterID int64) (copied int64, err error) {
+	shadow := table + partitionShadowSuffix
+	legacy := table + partitionLegacySuffix
+
+	indexes, err := r.listSourceIndexes(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "SET LOCAL lock_timeout = '10s'"); err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", pq.QuoteIdentifier(table))); err != nil {
+		return 0, fmt.Errorf("lock table: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE id > $1 ON CONFLICT DO NOTHING",
+		pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(table)), afterID)
+	if err != nil {
+		return 0, fmt.Errorf("copy delta: %w", err)
+	}
+	if copied, err = res.RowsAffected(); err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s",
+		pq.QuoteIdentifier("trg"+partitionSyncFuncSuffix), pq.QuoteIdentifier(table))); err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", pq.QuoteIdentifier(table+partitionSyncFuncSuffix))); err != nil {
+		return 0, err
+	}
+
+	// 分区表的主键包含 created_at，其他表指向原表 id 的外键无法保留
+	var incoming []string
+	rows, err := tx.QueryContext(ctx, `
+		SELECT format('ALTER TABLE %s DROP CONSTRAINT %I', conrelid::regclass, conname)
+		FROM pg_constraint
+		WHERE contype = 'f' AND confrelid = $1::regclass AND conrelid <> $1::regclass
+	`, table)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var stmt string
+		if err = rows.Scan(&stmt); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		incoming = append(incoming, stmt)
+	}
+	if err = rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+	for _, stmt := range incoming {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return 0, fmt.Errorf("drop incoming foreign key: %w", err)
+		}
+	}
+
+	var sequence sql.NullString
+	if err = scanSingleRow(ctx, tx, "SELECT pg_get_serial_sequence($1, 'id')", []any{table}, &sequence); err != nil {
+		return 0, err
+	}
+
+	for _, idx := range indexes {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER INDEX %s RENAME TO %s",
+			pq.QuoteIdentifier(idx.name), pq.QuoteIdentifier(partitionTempName(idx.name, partitionLegacySuffix)))); err != nil {
+			return 0, fmt.Errorf("rename legacy index %s: %w", idx.name, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", pq.QuoteIdentifier(table), pq.QuoteIdentifier(legacy))); err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", pq.QuoteIdentifier(shadow), pq.QuoteIdentifier(table))); err != nil {
+		return 0, err
+	}
+	for _, idx := range indexes {
+		tempName := partitionTempName(idx.name, partitionTempIndexSuffix)
+		if idx.isPrimary {
+			tempName = partitionTempName(table+"_pkey", partitionTempIndexSuffix)
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER INDEX IF EXISTS %s RENAME TO %s",
+			pq.QuoteIdentifier(tempName), pq.QuoteIdentifier(idx.name))); err != nil {
+			return 0, fmt.Errorf("rename index %s: %w", idx.name, err)
+		}
+	}
+	if sequence.Valid && sequence.String != "" {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.id", sequence.String, pq.QuoteIdentifier(table))); err != nil {
+			return 0, fmt.Errorf("transfer sequence ownership: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return copied, nil
+}
+
+func (r *timePartitionRepository) listSourceIndexes(ctx context.Context, table string) ([]partitionSourceIndex, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.relname, pg_get_indexdef(i.oid), ix.indisprimary, ix.indisunique
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		WHERE ix.indrelid = $1::regclass
+		ORDER BY i.relname
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var indexes []partitionSourceIndex
+	for rows.Next() {
+		var idx partitionSourceIndex
+		if err := rows.Scan(&idx.name, &idx.def, &idx.isPrimary, &idx.isUnique); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, rows.Err()
+}
+
+// rewriteIndexForShadow 将 pg_get_indexdef 输出改写为影子表上的同构索引（临时名称，替换时改回原名）
+func rewriteIndexForShadow(idx partitionSourceIndex, shadow string) (string, error) {
+	usingPos := strings.Index(idx.def, " USING ")
+	if usingPos < 0 {
+		return "", fmt.Errorf("unsupported index definition: %s", idx.def)
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s%s",
+		pq.QuoteIdentifier(partitionTempName(idx.name, partitionTempIndexSuffix)),
+		pq.QuoteIdentifier(shadow),
+		idx.def[usingPos:],
+	), nil
+}
+
+func createMonthlyPartition(ctx context.Context, exec sqlExecutor, parent, base string, month time.Time) error {
+	monthStart := truncateToMonthUTC(month)
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	name := fmt.Sprintf("%s_%s", base, monthStart.Format("200601"))
+	query := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+		pq.QuoteIdentifier(name),
+		pq.QuoteIdentifier(parent),
+		pq.QuoteLiteral(monthStart.Format("2006-01-02")),
+		pq.QuoteLiteral(nextMonth.Format("2006-01-02")),
+	)
+	_, err := exec.ExecContext(ctx, query)
+	return err
+}
+
+func parsePartitionMonth(table, name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, table+"_")
+	if !ok || len(suffix) != 6 {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("200601", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month.UTC(), true
+}
+
+// partitionTempName 追加后缀并保证不超过 PostgreSQL 63 字节的标识符上限
+func partitionTempName(name, suffix string) string {
+	const maxIdentifierLen = 63
+	if len(name)+len(suffix) > maxIdentifierLen {
+		name = name[:maxIdentifierLen-len(suffix)]
+	}
+	return name + suffix
+}
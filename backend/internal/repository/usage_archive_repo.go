@@ -0,0 +1,502 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type usageArchiveRepository struct {
+	sql sqlExecutor
+}
+
+func NewUsageArchiveRepository(sqlDB *sql.DB) service.UsageArchiveRepository {
+	return &usageArchiveRepository{sql: sqlDB}
+}
+
+const usageArchiveSelectColumns = `
+	id, source, task_id, granularity, range_start, range_end, filters,
+	profile_id, bucket, object_key, format, row_count, size_bytes, sha256,
+	min_log_id, max_log_id, rehydrate_status, rehydrated_rows, rehydrate_error,
+	rehydrated_at, created_at, updated_at
+`
+
+func (r *usageArchiveRepository) ExportUsageLogs(ctx context.Context, filters service.UsageCleanupFilters, w io.Writer) (*service.UsageArchiveExportStats, error) {
+	whereClause, args := buildUsageCleanupWhere(filters)
+	if whereClause == "" || filters.StartTime.IsZero() || filters.EndTime.IsZero() {
+		return nil, fmt.Errorf("archive filters missing time range")
+	}
+	query := fmt.Sprintf(`
+		SELECT ul.id, row_to_json(ul)::text
+		FROM usage_logs AS ul
+		WHERE %s
+		ORDER BY ul.id ASC
+	`, whereClause)
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats := &service.UsageArchiveExportStats{}
+	newline := []byte{'\n'}
+	for rows.Next() {
+		var id int64
+		var line []byte
+		if err := rows.Scan(&id, &line); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(line); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(newline); err != nil {
+			return nil, err
+		}
+		if stats.MinLogID == nil {
+			minID := id
+			stats.MinLogID = &minID
+		}
+		maxID := id
+		stats.MaxLogID = &maxID
+		stats.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *usageArchiveRepository) OldestUsageLogTime(ctx context.Context, before time.Time) (*time.Time, error) {
+	var oldest sql.NullTime
+	if err := scanSingleRow(ctx, r.sql, "SELECT MIN(created_at) FROM usage_logs WHERE created_at < $1", []any{before.UTC()}, &oldest); err != nil {
+		return nil, err
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	t := oldest.Time.UTC()
+	return &t, nil
+}
+
+func (r *usageArchiveRepository) FindArchive(ctx context.Context, source string, granularity string, taskID *int64, rangeStart time.Time) (*service.UsageLogArchive, error) {
+	query := "SELECT " + usageArchiveSelectColumns + `
+		FROM usage_log_archives
+		WHERE source = $1 AND granularity = $2 AND range_start = $3
+			AND task_id IS NOT DISTINCT FROM $4
+		LIMIT 1
+	`
+	rows, err := r.sql.QueryContext(ctx, query, source, granularity, rangeStart.UTC(), nullInt64(taskID))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	archive, err := scanUsageLogArchive(rows)
+	if err != nil {
+		return nil, err
+	}
+	return archive, rows.Err()
+}
+
+func (r *usageArchiveRepository) CreateArchive(ctx context.Context, archive *service.UsageLogArchive) error {
+	if archive == nil {
+		return nil
+	}
+	filtersJSON, err := json.Marshal(archive.Filters)
+	if err != nil {
+		return fmt.Errorf("marshal archive filters: %w", err)
+	}
+	query := `
+		INSERT INTO usage_log_archives (
+			source, task_id, granularity, range_start, range_end, filters,
+			profile_id, bucket, object_key, format, row_count, size_bytes, sha256,
+			min_log_id, max_log_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at, updated_at
+	`
+	return scanSingleRow(ctx, r.sql, query, []any{
+		archive.Source,
+		nullInt64(archive.TaskID),
+		archive.Granularity,
+		archive.RangeStart.UTC(),
+		archive.RangeEnd.UTC(),
+		filtersJSON,
+		archive.ProfileID,
+		archive.Bucket,
+		archive.ObjectKey,
+		archive.Format,
+		archive.RowCount,
+		archive.SizeBytes,
+		archive.SHA256,
+		nullInt64(archive.MinLogID),
+		nullInt64(archive.MaxLogID),
+	}, &archive.ID, &archive.CreatedAt, &archive.UpdatedAt)
+}
+
+func (r *usageArchiveRepository) GetArchive(ctx context.Context, id int64) (*service.UsageLogArchive, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+usageArchiveSelectColumns+" FROM usage_log_archives WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, service.ErrUsageArchiveNotFound
+	}
+	archive, err := scanUsageLogArchive(rows)
+	if err != nil {
+		return nil, err
+	}
+	return archive, rows.Err()
+}
+
+func (r *usageArchiveRepository) ListArchives(ctx context.Context, params pagination.PaginationParams) ([]service.UsageLogArchive, *pagination.PaginationResult, error) {
+	var total int64
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM usage_log_archives", nil, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.UsageLogArchive{}, paginationResultFromTotal(0, params), nil
+	}
+	query := "SELECT " + usageArchiveSelectColumns + `
+		FROM usage_log_archives
+		ORDER BY range_start DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.sql.QueryContext(ctx, query, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	archives := make([]service.UsageLogArchive, 0)
+	for rows.Next() {
+		archive, err := scanUsageLogArchive(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		archives = append(archives, *archive)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return archives, paginationResultFromTotal(total, params), nil
+}
+
+func (r *usageArchiveRepository) CountArchivesWithoutRehydration(ctx context.Context, start, end time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM usage_log_archives
+		WHERE row_count > 0
+			AND range_start < $2
+			AND range_end > $1
+			AND rehydrate_status <> $3
+	`
+	var count int
+	if err := scanSingleRow(ctx, r.sql, query, []any{start.UTC(), end.UTC(), service.UsageArchiveRehydrateReady}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *usageArchiveRepository) ClaimRehydration(ctx context.Context, id int64) (bool, error) {
+	// 回灌进程崩溃时状态会停留在 running，超过 3 小时视为失效允许重新抢占
+	query := `
+		UPDATE usage_log_archives
+		SET rehydrate_status = $2,
+			rehydrate_error = NULL,
+			updated_at = NOW()
+		WHERE id = $1
+			AND (rehydrate_status <> $2 OR updated_at < NOW() - interval '3 hours')
+		RETURNING id
+	`
+	var claimedID int64
+	err := scanSingleRow(ctx, r.sql, query, []any{id, service.UsageArchiveRehydrateRunning}, &claimedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *usageArchiveRepository) UpdateRehydration(ctx context.Context, id int64, status string, rowCount int64, errMsg *string) error {
+	query := `
+		UPDATE usage_log_archives
+		SET rehydrate_status = $2,
+			rehydrated_rows = $3,
+			rehydrate_error = $4,
+			rehydrated_at = CASE WHEN $2::text = $5::text THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.sql.ExecContext(ctx, query, id, status, rowCount, nullString(errMsg), service.UsageArchiveRehydrateReady)
+	return err
+}
+
+func (r *usageArchiveRepository) InsertRehydratedRows(ctx context.Context, archiveID int64, rows []json.RawMessage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	payload := make([]byte, 0, len(rows)*512)
+	payload = append(payload, '[')
+	payload = append(payload, bytes.Join(rawMessagesToBytes(rows), []byte{','})...)
+	payload = append(payload, ']')
+
+	query := `
+		INSERT INTO usage_log_archive_rows (
+			archive_id, usage_log_id, user_id, api_key_id, account_id, group_id, model,
+			input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens,
+			total_cost, actual_cost, created_at, data
+		)
+		SELECT
+			$1,
+			(x->>'id')::bigint,
+			(x->>'user_id')::bigint,
+			(x->>'api_key_id')::bigint,
+			(x->>'account_id')::bigint,
+			(x->>'group_id')::bigint,
+			COALESCE(x->>'model', ''),
+			COALESCE((x->>'input_tokens')::bigint, 0),
+			COALESCE((x->>'output_tokens')::bigint, 0),
+			COALESCE((x->>'cache_creation_tokens')::bigint, 0),
+			COALESCE((x->>'cache_read_tokens')::bigint, 0),
+			COALESCE((x->>'total_cost')::numeric, 0),
+			COALESCE((x->>'actual_cost')::numeric, 0),
+			(x->>'created_at')::timestamptz,
+			x
+		FROM jsonb_array_elements($2::jsonb) AS x
+	`
+	_, err := r.sql.ExecContext(ctx, query, archiveID, payload)
+	return err
+}
+
+func (r *usageArchiveRepository) DeleteRehydratedRows(ctx context.Context, archiveID int64) (int64, error) {
+	result, err := r.sql.ExecContext(ctx, "DELETE FROM usage_log_archive_rows WHERE archive_id = $1", archiveID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *usageArchiveRepository) ListRehydratedLogs(ctx context.Context, filters service.UsageArchiveLogFilters, params pagination.PaginationParams) ([]service.UsageArchiveLogRow, *pagination.PaginationResult, error) {
+	conditions := make([]string, 0, 5)
+	args := make([]any, 0, 7)
+	if filters.UserID != nil {
+		args = append(args, *filters.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filters.APIKeyID != nil {
+		args = append(args, *filters.APIKeyID)
+		conditions = append(conditions, fmt.Sprintf("api_key_id = $%d", len(args)))
+	}
+	if filters.ArchiveID != nil {
+		args = append(args, *filters.ArchiveID)
+		conditions = append(conditions, fmt.Sprintf("archive_id = $%d", len(args)))
+	}
+	if !filters.StartTime.IsZero() {
+		args = append(args, filters.StartTime.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filters.EndTime.IsZero() {
+		args = append(args, filters.EndTime.UTC())
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM usage_log_archive_rows "+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+	if total == 0 {
+		return []service.UsageArchiveLogRow{}, paginationResultFromTotal(0, params), nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT archive_id, usage_log_id, user_id, api_key_id, account_id, group_id, model,
+			total_cost, actual_cost, created_at, data
+		FROM usage_log_archive_rows
+		%s
+		ORDER BY created_at DESC, usage_log_id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	rows, err := r.sql.QueryContext(ctx, query, append(args, params.Limit(), params.Offset())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UsageArchiveLogRow, 0)
+	for rows.Next() {
+		var row service.UsageArchiveLogRow
+		var groupID sql.NullInt64
+		var data []byte
+		if err := rows.Scan(
+			&row.ArchiveID,
+			&row.UsageLogID,
+			&row.UserID,
+			&row.APIKeyID,
+			&row.AccountID,
+			&groupID,
+			&row.Model,
+			&row.TotalCost,
+			&row.ActualCost,
+			&row.CreatedAt,
+			&data,
+		); err != nil {
+			return nil, nil, err
+		}
+		if groupID.Valid {
+			v := groupID.Int64
+			row.GroupID = &v
+		}
+		row.Data = json.RawMessage(data)
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+func (r *usageArchiveRepository) GetUserStatement(ctx context.Context, userID int64, start, end time.Time) ([]service.UsageStatementLine, error) {
+	// 在线数据与回灌数据合并；同一条记录可能出现在多个归档中，按 usage_log_id 去重，
+	// 且仍存在于 usage_logs 的记录以在线数据为准。
+	query := `
+		WITH combined AS (
+			SELECT model, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, total_cost, actual_cost
+			FROM usage_logs
+			WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+			UNION ALL
+			SELECT model, input_tokens, output_tokens, cache_creation_tokens, cache_read_tokens, total_cost, actual_cost
+			FROM (
+				SELECT DISTINCT ON (r.usage_log_id) r.*
+				FROM usage_log_archive_rows r
+				WHERE r.user_id = $1 AND r.created_at >= $2 AND r.created_at < $3
+					AND NOT EXISTS (SELECT 1 FROM usage_logs u WHERE u.id = r.usage_log_id AND u.created_at = r.created_at)
+				ORDER BY r.usage_log_id, r.archive_id
+			) archived
+		)
+		SELECT
+			model,
+			COUNT(*),
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_creation_tokens), 0),
+			COALESCE(SUM(cache_read_tokens), 0),
+			COALESCE(SUM(total_cost), 0),
+			COALESCE(SUM(actual_cost), 0)
+		FROM combined
+		GROUP BY model
+		ORDER BY SUM(actual_cost) DESC, model ASC
+	`
+	rows, err := r.sql.QueryContext(ctx, query, userID, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	lines := make([]service.UsageStatementLine, 0)
+	for rows.Next() {
+		var line service.UsageStatementLine
+		if err := rows.Scan(
+			&line.Model,
+			&line.Requests,
+			&line.InputTokens,
+			&line.OutputTokens,
+			&line.CacheCreationTokens,
+			&line.CacheReadTokens,
+			&line.TotalCost,
+			&line.ActualCost,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func scanUsageLogArchive(rows *sql.Rows) (*service.UsageLogArchive, error) {
+	var archive service.UsageLogArchive
+	var taskID, minLogID, maxLogID sql.NullInt64
+	var filtersJSON []byte
+	var rehydrateError sql.NullString
+	var rehydratedAt sql.NullTime
+	if err := rows.Scan(
+		&archive.ID,
+		&archive.Source,
+		&taskID,
+		&archive.Granularity,
+		&archive.RangeStart,
+		&archive.RangeEnd,
+		&filtersJSON,
+		&archive.ProfileID,
+		&archive.Bucket,
+		&archive.ObjectKey,
+		&archive.Format,
+		&archive.RowCount,
+		&archive.SizeBytes,
+		&archive.SHA256,
+		&minLogID,
+		&maxLogID,
+		&archive.RehydrateStatus,
+		&archive.RehydratedRows,
+		&rehydrateError,
+		&rehydratedAt,
+		&archive.CreatedAt,
+		&archive.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(filtersJSON) > 0 {
+		if err := json.Unmarshal(filtersJSON, &archive.Filters); err != nil {
+			return nil, fmt.Errorf("parse archive filters: %w", err)
+		}
+	}
+	if taskID.Valid {
+		v := taskID.Int64
+		archive.TaskID = &v
+	}
+	if minLogID.Valid {
+		v := minLogID.Int64
+		archive.MinLogID = &v
+	}
+	if maxLogID.Valid {
+		v := maxLogID.Int64
+		archive.MaxLogID = &v
+	}
+	if rehydrateError.Valid {
+		v := rehydrateError.String
+		archive.RehydrateError = &v
+	}
+	if rehydratedAt.Valid {
+		v := rehydratedAt.Time
+		archive.RehydratedAt = &v
+	}
+	return &archive, nil
+}
+
+func rawMessagesToBytes(rows []json.RawMessage) [][]byte {
+	out := make([][]byte, len(rows))
+	for i := range rows {
+		out[i] = rows[i]
+	}
+	return out
+}
@@ -291,6 +291,7 @@ func (r *usageCleanupRepository) DeleteUsageLogsBatch(ctx context.Context, filte
 		return 0, fmt.Errorf("cleanup filters missing time range")
 	}
 	args = append(args, limit)
+	// 外层 DELETE 重复时间条件（$1/$2），使分区表在规划阶段即可裁剪分区
 	query := fmt.Sprintf(`
 		WITH target AS (
 			SELECT id
@@ -300,7 +301,8 @@ func (r *usageCleanupRepository) DeleteUsageLogsBatch(ctx context.Context, filte
 			LIMIT $%d
 		)
 		DELETE FROM usage_logs
-		WHERE id IN (SELECT id FROM target)
+		WHERE created_at >= $1 AND created_at <= $2
+			AND id IN (SELECT id FROM target)
 		RETURNING id
 	`, whereClause, len(args))
 
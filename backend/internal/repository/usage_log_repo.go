@@ -101,7 +101,17 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 	log.SyncRequestTypeAndLegacyFields()
 	requestType := int16(log.RequestType)
 
+	// 幂等：分区表无法建立不含分区键的 (request_id, api_key_id) 唯一索引，
+	// 因此先占用 usage_log_dedup 的去重键，占用成功才写入 usage_logs；
+	// 未分区时原唯一索引仍生效（ON CONFLICT DO NOTHING 兜底去重记录已过期的重试）。
 	query := `
+		WITH dedup AS (
+			INSERT INTO usage_log_dedup (request_id, api_key_id, created_at)
+			SELECT $4::text, $2::bigint, $35::timestamptz
+			WHERE $4::text IS NOT NULL
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
 		INSERT INTO usage_logs (
 			user_id,
 			api_key_id,
@@ -137,16 +147,26 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 			media_type,
 			reasoning_effort,
 			cache_ttl_overridden,
-			created_at
-		) VALUES (
-			$1, $2, $3, $4, $5,
-			$6, $7,
-			$8, $9, $10, $11,
-			$12, $13,
-			$14, $15, $16, $17, $18, $19,
-			$20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35
+			created_at,
+			experiment_id,
+			experiment_variant,
+			auto_cache_breakpoints,
+			prompt_cache_savings,
+			context_action,
+			context_dropped_messages
 		)
-		ON CONFLICT (request_id, api_key_id) DO NOTHING
+		SELECT
+			$1::bigint, $2::bigint, $3::bigint, $4::text, $5::text,
+			$6::bigint, $7::bigint,
+			$8::bigint, $9::bigint, $10::bigint, $11::bigint,
+			$12::bigint, $13::bigint,
+			$14::numeric, $15::numeric, $16::numeric, $17::numeric, $18::numeric, $19::numeric,
+			$20::numeric, $21::numeric, $22::smallint, $23::smallint, $24::boolean, $25::boolean,
+			$26::bigint, $27::bigint, $28::text, $29::text, $30::bigint, $31::text, $32::text, $33::text,
+			$34::boolean, $35::timestamptz, $36::bigint, $37::text,
+			$38::smallint, $39::numeric, $40::text, $41::integer
+		WHERE $4::text IS NULL OR EXISTS (SELECT 1 FROM dedup)
+		ON CONFLICT DO NOTHING
 		RETURNING id, created_at
 	`
 
@@ -159,6 +179,9 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 	imageSize := nullString(log.ImageSize)
 	mediaType := nullString(log.MediaType)
 	reasoningEffort := nullString(log.ReasoningEffort)
+	experimentID := nullInt64(log.ExperimentID)
+	experimentVariant := nullString(log.ExperimentVariant)
+	contextAction := nullString(log.ContextAction)
 
 	var requestIDArg any
 	if requestID != "" {
@@ -201,11 +224,22 @@ func (r *usageLogRepository) Create(ctx context.Context, log *service.UsageLog)
 		reasoningEffort,
 		log.CacheTTLOverridden,
 		createdAt,
+		experimentID,
+		experimentVariant,
+		log.AutoCacheBreakpoints,
+		log.PromptCacheSavings,
+		contextAction,
+		log.ContextDroppedMessages,
 	}
 	if err := scanSingleRow(ctx, sqlq, query, args, &log.ID, &log.CreatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) && requestID != "" {
 			selectQuery := "SELECT id, created_at FROM usage_logs WHERE request_id = $1 AND api_key_id = $2"
 			if err := scanSingleRow(ctx, sqlq, selectQuery, []any{requestID, log.APIKeyID}, &log.ID, &log.CreatedAt); err != nil {
+				// 去重键仍在但原记录已被清理：视为已处理，不再重复计费
+				if errors.Is(err, sql.ErrNoRows) {
+					log.RateMultiplier = rateMultiplier
+					return false, nil
+				}
 				return false, err
 			}
 			log.RateMultiplier = rateMultiplier
@@ -1616,7 +1650,8 @@ func (r *usageLogRepository) GetBatchAPIKeyUsageStats(ctx context.Context, apiKe
 		SELECT
 			api_key_id,
 			COALESCE(SUM(actual_cost) FILTER (WHERE created_at >= $2 AND created_at < $3), 0) as total_cost,
-			COALESCE(SUM(actual_cost) FILTER (WHERE created_at >= $4), 0) as today_cost
+			COALESCE(SUM(actual_cost) FILTER (WHERE created_at >= $4), 0) as today_cost,
+			COALESCE(SUM(prompt_cache_savings) FILTER (WHERE created_at >= $2 AND created_at < $3), 0) as prompt_cache_savings
 		FROM usage_logs
 		WHERE api_key_id = ANY($1)
 		  AND created_at >= LEAST($2, $4)
@@ -1631,13 +1666,15 @@ func (r *usageLogRepository) GetBatchAPIKeyUsageStats(ctx context.Context, apiKe
 		var apiKeyID int64
 		var total float64
 		var todayTotal float64
-		if err := rows.Scan(&apiKeyID, &total, &todayTotal); err != nil {
+		var cacheSavings float64
+		if err := rows.Scan(&apiKeyID, &total, &todayTotal, &cacheSavings); err != nil {
 			_ = rows.Close()
 			return nil, err
 		}
 		if stats, ok := result[apiKeyID]; ok {
 			stats.TotalActualCost = total
 			stats.TodayActualCost = todayTotal
+			stats.PromptCacheSavings = cacheSavings
 		}
 	}
 	if err := rows.Close(); err != nil {
@@ -74,6 +74,12 @@ func TestUsageLogRepositoryCreateSyncRequestTypeAndLegacyFields(t *testing.T) {
 			sqlmock.AnyArg(), // reasoning_effort
 			log.CacheTTLOverridden,
 			createdAt,
+			sqlmock.AnyArg(), // experiment_id
+			sqlmock.AnyArg(), // experiment_variant
+			log.AutoCacheBreakpoints,
+			log.PromptCacheSavings,
+			sqlmock.AnyArg(), // context_action
+			log.ContextDroppedMessages,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(99), createdAt))
 
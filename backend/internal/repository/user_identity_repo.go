@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const userIdentitySelectColumns = `
+	id, user_id, provider, subject, email, username, last_login_at, created_at, updated_at
+`
+
+type userIdentityRepository struct {
+	sql sqlExecutor
+}
+
+// NewUserIdentityRepository 创建外部身份绑定仓储
+func NewUserIdentityRepository(sqlDB *sql.DB) service.UserIdentityRepository {
+	return &userIdentityRepository{sql: sqlDB}
+}
+
+func (r *userIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*service.UserIdentity, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+userIdentitySelectColumns+" FROM user_identities WHERE provider = $1 AND subject = $2", provider, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	identity, err := scanUserIdentity(rows)
+	if err != nil {
+		return nil, err
+	}
+	return identity, rows.Err()
+}
+
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID int64) ([]service.UserIdentity, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+userIdentitySelectColumns+" FROM user_identities WHERE user_id = $1 ORDER BY provider", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UserIdentity, 0)
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *identity)
+	}
+	return out, rows.Err()
+}
+
+func (r *userIdentityRepository) Upsert(ctx context.Context, identity *service.UserIdentity) error {
+	// 仅当已有绑定属于同一用户时才刷新；属于其他用户时不返回行
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, username, last_login_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (provider, subject) DO UPDATE SET
+			email = EXCLUDED.email,
+			username = EXCLUDED.username,
+			last_login_at = COALESCE(EXCLUDED.last_login_at, user_identities.last_login_at),
+			updated_at = NOW()
+		WHERE user_identities.user_id = EXCLUDED.user_id
+		RETURNING id, created_at, updated_at
+	`
+	err := scanSingleRow(ctx, r.sql, query,
+		[]any{identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.Username, identity.LastLoginAt},
+		&identity.ID, &identity.CreatedAt, &identity.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrOIDCIdentityLinkedElsewhere
+	}
+	if isUniqueConstraintViolation(err) {
+		return service.ErrOIDCProviderAlreadyLinked
+	}
+	return err
+}
+
+func (r *userIdentityRepository) Delete(ctx context.Context, userID int64, provider string) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, "DELETE FROM user_identities WHERE user_id = $1 AND provider = $2", userID, provider)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanUserIdentity(rows *sql.Rows) (*service.UserIdentity, error) {
+	var (
+		identity    service.UserIdentity
+		lastLoginAt sql.NullTime
+	)
+	if err := rows.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.Username,
+		&lastLoginAt, &identity.CreatedAt, &identity.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastLoginAt.Valid {
+		v := lastLoginAt.Time
+		identity.LastLoginAt = &v
+	}
+	return &identity, nil
+}
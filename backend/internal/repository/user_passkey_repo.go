@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+const userPasskeySelectColumns = `
+	id, user_id, name, credential_id, credential, last_used_at, created_at
+`
+
+type userPasskeyRepository struct {
+	sql sqlExecutor
+}
+
+// NewUserPasskeyRepository 创建用户 Passkey 仓储
+func NewUserPasskeyRepository(sqlDB *sql.DB) service.UserPasskeyRepository {
+	return &userPasskeyRepository{sql: sqlDB}
+}
+
+func (r *userPasskeyRepository) ListByUser(ctx context.Context, userID int64) ([]service.UserPasskey, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+userPasskeySelectColumns+" FROM user_passkeys WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.UserPasskey, 0)
+	for rows.Next() {
+		passkey, err := scanUserPasskey(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *passkey)
+	}
+	return out, rows.Err()
+}
+
+func (r *userPasskeyRepository) GetByCredentialID(ctx context.Context, credentialID string) (*service.UserPasskey, error) {
+	rows, err := r.sql.QueryContext(ctx, "SELECT "+userPasskeySelectColumns+" FROM user_passkeys WHERE credential_id = $1", credentialID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	passkey, err := scanUserPasskey(rows)
+	if err != nil {
+		return nil, err
+	}
+	return passkey, rows.Err()
+}
+
+func (r *userPasskeyRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	if err := scanSingleRow(ctx, r.sql, "SELECT COUNT(*) FROM user_passkeys WHERE user_id = $1", []any{userID}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *userPasskeyRepository) Create(ctx context.Context, passkey *service.UserPasskey) error {
+	payload, err := json.Marshal(passkey.Credential)
+	if err != nil {
+		return fmt.Errorf("marshal passkey credential: %w", err)
+	}
+	query := `
+		INSERT INTO user_passkeys (user_id, name, credential_id, credential, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at
+	`
+	err = scanSingleRow(ctx, r.sql, query,
+		[]any{passkey.UserID, passkey.Name, passkey.CredentialID, payload},
+		&passkey.ID, &passkey.CreatedAt,
+	)
+	if isUniqueConstraintViolation(err) {
+		return service.ErrPasskeyAlreadyRegistered
+	}
+	return err
+}
+
+func (r *userPasskeyRepository) UpdateCredential(ctx context.Context, id int64, credential *webauthn.Credential, usedAt time.Time) error {
+	payload, err := json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("marshal passkey credential: %w", err)
+	}
+	_, err = r.sql.ExecContext(ctx,
+		"UPDATE user_passkeys SET credential = $2, last_used_at = $3, updated_at = NOW() WHERE id = $1",
+		id, payload, usedAt,
+	)
+	return err
+}
+
+func (r *userPasskeyRepository) Rename(ctx context.Context, userID, id int64, name string) (bool, error) {
+	res, err := r.sql.ExecContext(ctx,
+		"UPDATE user_passkeys SET name = $3, updated_at = NOW() WHERE id = $1 AND user_id = $2",
+		id, userID, name,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *userPasskeyRepository) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	res, err := r.sql.ExecContext(ctx, "DELETE FROM user_passkeys WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func scanUserPasskey(rows *sql.Rows) (*service.UserPasskey, error) {
+	var (
+		passkey    service.UserPasskey
+		payload    []byte
+		lastUsedAt sql.NullTime
+	)
+	if err := rows.Scan(
+		&passkey.ID, &passkey.UserID, &passkey.Name, &passkey.CredentialID, &payload, &lastUsedAt, &passkey.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &passkey.Credential); err != nil {
+		return nil, fmt.Errorf("unmarshal passkey credential: %w", err)
+	}
+	if lastUsedAt.Valid {
+		v := lastUsedAt.Time
+		passkey.LastUsedAt = &v
+	}
+	return &passkey, nil
+}
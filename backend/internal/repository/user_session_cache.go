@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	userSessionKeyPrefix      = "user_session:"
+	userSessionsKeyPrefix     = "user_sessions:"
+	userKnownDevicesKeyPrefix = "user_known_devices:"
+)
+
+// rememberDeviceScript 记录用户设备，仅当用户已有设备记录且该设备首次出现时返回 1
+// 首次记录（如注册或功能上线后的第一次登录）不视为新设备，避免误报
+// KEYS[1] = user_known_devices:{user_id}
+// ARGV[1] = device
+// ARGV[2] = TTL（秒）
+var rememberDeviceScript = redis.NewScript(`
+	local existed = redis.call('EXISTS', KEYS[1])
+	local added = redis.call('SADD', KEYS[1], ARGV[1])
+	redis.call('EXPIRE', KEYS[1], tonumber(ARGV[2]))
+	if existed == 1 and added == 1 then
+		return 1
+	end
+	return 0
+`)
+
+// userSessionKey generates the Redis key for a session.
+func userSessionKey(sessionID string) string {
+	return userSessionKeyPrefix + sessionID
+}
+
+// userSessionsKey generates the Redis key for user's session set.
+func userSessionsKey(userID int64) string {
+	return fmt.Sprintf("%s%d", userSessionsKeyPrefix, userID)
+}
+
+// userKnownDevicesKey generates the Redis key for user's known device set.
+func userKnownDevicesKey(userID int64) string {
+	return fmt.Sprintf("%s%d", userKnownDevicesKeyPrefix, userID)
+}
+
+type userSessionCache struct {
+	rdb *redis.Client
+}
+
+// NewUserSessionCache creates a new UserSessionCache implementation.
+func NewUserSessionCache(rdb *redis.Client) service.UserSessionCache {
+	return &userSessionCache{rdb: rdb}
+}
+
+func (c *userSessionCache) SaveSession(ctx context.Context, session *service.UserSession, ttl time.Duration) error {
+	val, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("marshal user session: %w", err)
+	}
+	setKey := userSessionsKey(session.UserID)
+	pipe := c.rdb.Pipeline()
+	pipe.Set(ctx, userSessionKey(session.ID), val, ttl)
+	pipe.SAdd(ctx, setKey, session.ID)
+	pipe.Expire(ctx, setKey, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *userSessionCache) GetSession(ctx context.Context, sessionID string) (*service.UserSession, error) {
+	val, err := c.rdb.Get(ctx, userSessionKey(sessionID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var session service.UserSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, fmt.Errorf("unmarshal user session: %w", err)
+	}
+	return &session, nil
+}
+
+func (c *userSessionCache) ListUserSessions(ctx context.Context, userID int64) ([]*service.UserSession, error) {
+	setKey := userSessionsKey(userID)
+	ids, err := c.rdb.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*service.UserSession{}, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, userSessionKey(id))
+	}
+	values, err := c.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*service.UserSession, 0, len(values))
+	var expired []any
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			// 会话已过期，清理索引
+			expired = append(expired, ids[i])
+			continue
+		}
+		var session service.UserSession
+		if err := json.Unmarshal([]byte(raw), &session); err != nil {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+	if len(expired) > 0 {
+		_ = c.rdb.SRem(ctx, setKey, expired...).Err()
+	}
+	return sessions, nil
+}
+
+func (c *userSessionCache) DeleteSession(ctx context.Context, userID int64, sessionID string) error {
+	pipe := c.rdb.Pipeline()
+	pipe.Del(ctx, userSessionKey(sessionID))
+	pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *userSessionCache) DeleteUserSessions(ctx context.Context, userID int64) error {
+	setKey := userSessionsKey(userID)
+	ids, err := c.rdb.SMembers(ctx, setKey).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("get user sessions: %w", err)
+	}
+
+	pipe := c.rdb.Pipeline()
+	for _, id := range ids {
+		pipe.Del(ctx, userSessionKey(id))
+	}
+	pipe.Del(ctx, setKey)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *userSessionCache) RememberDevice(ctx context.Context, userID int64, device string, ttl time.Duration) (bool, error) {
+	result, err := rememberDeviceScript.Run(ctx, c.rdb, []string{userKnownDevicesKey(userID)}, device, int64(ttl.Seconds())).Int64()
+	if err != nil {
+		return false, fmt.Errorf("remember device: %w", err)
+	}
+	return result == 1, nil
+}
@@ -0,0 +1,81 @@
+//go:build integration
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserSessionCacheSuite struct {
+	IntegrationRedisSuite
+	cache service.UserSessionCache
+}
+
+func (s *UserSessionCacheSuite) SetupTest() {
+	s.IntegrationRedisSuite.SetupTest()
+	s.cache = NewUserSessionCache(s.rdb)
+}
+
+func (s *UserSessionCacheSuite) TestSaveListAndDeleteSession() {
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, id := range []string{"fam-a", "fam-b"} {
+		require.NoError(s.T(), s.cache.SaveSession(s.ctx, &service.UserSession{
+			ID: id, UserID: 7, Device: "Chrome on macOS", IP: "203.0.113.7", CreatedAt: now, LastSeenAt: now,
+		}, time.Hour))
+	}
+
+	got, err := s.cache.GetSession(s.ctx, "fam-a")
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), int64(7), got.UserID)
+	require.Equal(s.T(), "Chrome on macOS", got.Device)
+
+	sessions, err := s.cache.ListUserSessions(s.ctx, 7)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), sessions, 2)
+
+	require.NoError(s.T(), s.cache.DeleteSession(s.ctx, 7, "fam-a"))
+	missing, err := s.cache.GetSession(s.ctx, "fam-a")
+	require.NoError(s.T(), err)
+	require.Nil(s.T(), missing)
+
+	require.NoError(s.T(), s.cache.DeleteUserSessions(s.ctx, 7))
+	sessions, err = s.cache.ListUserSessions(s.ctx, 7)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), sessions)
+}
+
+func (s *UserSessionCacheSuite) TestListUserSessions_PrunesExpired() {
+	require.NoError(s.T(), s.cache.SaveSession(s.ctx, &service.UserSession{ID: "fam-x", UserID: 8}, time.Hour))
+	require.NoError(s.T(), s.rdb.Del(s.ctx, userSessionKey("fam-x")).Err())
+
+	sessions, err := s.cache.ListUserSessions(s.ctx, 8)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), sessions)
+
+	members, err := s.rdb.SMembers(s.ctx, userSessionsKey(8)).Result()
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), members)
+}
+
+func (s *UserSessionCacheSuite) TestRememberDevice() {
+	isNew, err := s.cache.RememberDevice(s.ctx, 9, "chrome on macos", time.Hour)
+	require.NoError(s.T(), err)
+	require.False(s.T(), isNew, "first recorded device is not reported as new")
+
+	isNew, err = s.cache.RememberDevice(s.ctx, 9, "chrome on macos", time.Hour)
+	require.NoError(s.T(), err)
+	require.False(s.T(), isNew)
+
+	isNew, err = s.cache.RememberDevice(s.ctx, 9, "firefox on linux", time.Hour)
+	require.NoError(s.T(), err)
+	require.True(s.T(), isNew)
+}
+
+func TestUserSessionCacheSuite(t *testing.T) {
+	suite.Run(t, new(UserSessionCacheSuite))
+}
@@ -64,6 +64,11 @@ var ProviderSet = wire.NewSet(
 	NewUsageLogRepository,
 	NewIdempotencyRepository,
 	NewUsageCleanupRepository,
+	NewUsageArchiveRepository,
+	NewTimePartitionRepository,
+	NewCostAnomalyRepository,
+	NewKeySharingRepository,
+	NewKeySharingCache,
 	NewDashboardAggregationRepository,
 	NewSettingRepository,
 	NewOpsRepository,
@@ -82,6 +87,7 @@ var ProviderSet = wire.NewSet(
 	ProvideConcurrencyCache,
 	ProvideSessionLimitCache,
 	NewRPMCache,
+	NewRequestRateLimitCache,
 	NewUserMsgQueueCache,
 	NewDashboardCache,
 	NewEmailCache,
@@ -93,7 +99,19 @@ var ProviderSet = wire.NewSet(
 	NewSchedulerOutboxRepository,
 	NewProxyLatencyCache,
 	NewTotpCache,
+	NewUserIdentityRepository,
+	NewOIDCStateCache,
+	NewUserPasskeyRepository,
+	NewPasskeyCeremonyCache,
+	NewAdminRoleRepository,
+	NewAdminKeyRepository,
 	NewRefreshTokenCache,
+	NewUserSessionCache,
+	NewImpersonationCache,
+	NewResponseCacheStore,
+	NewImpersonationAuditRepository,
+	NewShadowMirrorRepository,
+	NewExperimentRepository,
 	NewErrorPassthroughCache,
 
 	// Encryptors
@@ -78,6 +78,7 @@ func TestAPIContracts(t *testing.T) {
 					"id": 100,
 					"user_id": 1,
 					"key": "sk_custom_1234567890",
+					"key_prefix": "sk_custo",
 					"name": "Key One",
 					"group_id": null,
 					"status": "active",
@@ -95,6 +96,10 @@ func TestAPIContracts(t *testing.T) {
 					"window_5h_start": null,
 					"window_1d_start": null,
 					"window_7d_start": null,
+					"previous_key_prefix": "",
+					"previous_key_expires_at": null,
+					"rotated_at": null,
+					"policy": null,
 					"expires_at": null,
 					"created_at": "2025-01-02T03:04:05Z",
 					"updated_at": "2025-01-02T03:04:05Z"
@@ -108,7 +113,8 @@ func TestAPIContracts(t *testing.T) {
 				deps.apiKeyRepo.MustSeed(&service.APIKey{
 					ID:        100,
 					UserID:    1,
-					Key:       "sk_custom_1234567890",
+					KeyHash:   service.HashAPIKey("", "sk_custom_1234567890"),
+					KeyPrefix: "sk_custo",
 					Name:      "Key One",
 					Status:    service.StatusActive,
 					CreatedAt: deps.now,
@@ -126,7 +132,8 @@ func TestAPIContracts(t *testing.T) {
 						{
 							"id": 100,
 							"user_id": 1,
-							"key": "sk_custom_1234567890",
+							"key": "",
+							"key_prefix": "sk_custo",
 							"name": "Key One",
 							"group_id": null,
 							"status": "active",
@@ -144,6 +151,10 @@ func TestAPIContracts(t *testing.T) {
 							"window_5h_start": null,
 							"window_1d_start": null,
 							"window_7d_start": null,
+							"previous_key_prefix": "",
+							"previous_key_expires_at": null,
+							"rotated_at": null,
+							"policy": null,
 							"expires_at": null,
 							"created_at": "2025-01-02T03:04:05Z",
 							"updated_at": "2025-01-02T03:04:05Z"
@@ -495,6 +506,9 @@ func TestAPIContracts(t *testing.T) {
 					"password_reset_enabled": false,
 					"totp_enabled": false,
 					"totp_encryption_key_configured": false,
+					"passkey_enabled": false,
+					"webauthn_configured": false,
+					"admin_require_2fa": false,
 					"smtp_host": "smtp.example.com",
 					"smtp_port": 587,
 					"smtp_username": "user",
@@ -646,7 +660,7 @@ func newContractDeps(t *testing.T) *contractDeps {
 	settingService := service.NewSettingService(settingRepo, cfg)
 
 	adminService := service.NewAdminService(userRepo, groupRepo, &accountRepo, nil, proxyRepo, apiKeyRepo, redeemRepo, nil, nil, nil, nil, nil, nil, nil, nil)
-	authHandler := handler.NewAuthHandler(cfg, nil, userService, settingService, nil, redeemService, nil)
+	authHandler := handler.NewAuthHandler(cfg, nil, userService, settingService, nil, redeemService, nil, nil, nil)
 	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 	usageHandler := handler.NewUsageHandler(usageService, apiKeyService)
 	adminSettingHandler := adminhandler.NewSettingHandler(settingService, nil, nil, nil, nil)
@@ -1376,12 +1390,12 @@ func (r *stubApiKeyRepo) GetByID(ctx context.Context, id int64) (*service.APIKey
 	return &clone, nil
 }
 
-func (r *stubApiKeyRepo) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
+func (r *stubApiKeyRepo) GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error) {
 	key, ok := r.byID[id]
 	if !ok {
-		return "", 0, service.ErrAPIKeyNotFound
+		return nil, 0, service.ErrAPIKeyNotFound
 	}
-	return key.Key, key.UserID, nil
+	return key.AuthKeyHashes(), key.UserID, nil
 }
 
 func (r *stubApiKeyRepo) GetByKey(ctx context.Context, key string) (*service.APIKey, error) {
@@ -1519,6 +1533,14 @@ func (r *stubApiKeyRepo) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	return nil, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) Rotate(ctx context.Context, rotation service.APIKeyRotation) error {
+	return errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) ClearExpiredPreviousKeys(ctx context.Context, now time.Time) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	return 0, errors.New("not implemented")
 }
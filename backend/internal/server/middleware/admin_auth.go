@@ -6,9 +6,13 @@ import (
 	"errors"
 	"strings"
 
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // NewAdminAuthMiddleware 创建管理员认证中间件
@@ -16,18 +20,31 @@ func NewAdminAuthMiddleware(
 	authService *service.AuthService,
 	userService *service.UserService,
 	settingService *service.SettingService,
+	passkeyService *service.PasskeyService,
+	rbacService *service.AdminRBACService,
+	adminKeyService *service.AdminKeyService,
 ) AdminAuthMiddleware {
-	return AdminAuthMiddleware(adminAuth(authService, userService, settingService))
+	return AdminAuthMiddleware(adminAuth(authService, userService, settingService, passkeyService, rbacService, adminKeyService))
 }
 
 // adminAuth 管理员认证中间件实现
 // 支持两种认证方式（通过不同的 header 区分）：
 // 1. Admin API Key: x-api-key: <admin-api-key>
 // 2. JWT Token: Authorization: Bearer <jwt-token> (需要管理员角色)
+//
+// 启用“管理员必须启用第二因素”策略时，未绑定 TOTP/Passkey 的管理员 JWT 会被拒绝；
+// Admin API Key 不受该策略影响（便于自动化与恢复）。
+//
+// 认证通过后会把有效权限（*service.AdminAccess）写入上下文，由 RequireAdminPermission 按路由校验。
+// 全局 Admin API Key 拥有全部权限；命名 Admin API Key 按其 scope 计算；
+// JWT 用户的权限由其分配的管理角色决定。
 func adminAuth(
 	authService *service.AuthService,
 	userService *service.UserService,
 	settingService *service.SettingService,
+	passkeyService *service.PasskeyService,
+	rbacService *service.AdminRBACService,
+	adminKeyService *service.AdminKeyService,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// WebSocket upgrade requests cannot set Authorization headers in browsers.
@@ -36,7 +53,7 @@ func adminAuth(
 		//   Sec-WebSocket-Protocol: sub2api-admin, jwt.<token>
 		if isWebSocketUpgradeRequest(c) {
 			if token := extractJWTFromWebSocketSubprotocol(c); token != "" {
-				if !validateJWTForAdmin(c, token, authService, userService) {
+				if !validateJWTForAdmin(c, token, authService, userService, passkeyService, rbacService) {
 					return
 				}
 				c.Next()
@@ -47,7 +64,7 @@ func adminAuth(
 		// 检查 x-api-key header（Admin API Key 认证）
 		apiKey := c.GetHeader("x-api-key")
 		if apiKey != "" {
-			if !validateAdminAPIKey(c, apiKey, settingService, userService) {
+			if !validateAdminAPIKey(c, apiKey, settingService, userService, adminKeyService) {
 				return
 			}
 			c.Next()
@@ -64,7 +81,7 @@ func adminAuth(
 					AbortWithError(c, 401, "UNAUTHORIZED", "Authorization required")
 					return
 				}
-				if !validateJWTForAdmin(c, token, authService, userService) {
+				if !validateJWTForAdmin(c, token, authService, userService, passkeyService, rbacService) {
 					return
 				}
 				c.Next()
@@ -116,11 +133,13 @@ func extractJWTFromWebSocketSubprotocol(c *gin.Context) string {
 }
 
 // validateAdminAPIKey 验证管理员 API Key
+// 先匹配 settings 中的全局 Key（兼容旧集成，全部权限），再匹配命名 Key（按 scope 授权）。
 func validateAdminAPIKey(
 	c *gin.Context,
 	key string,
 	settingService *service.SettingService,
 	userService *service.UserService,
+	adminKeyService *service.AdminKeyService,
 ) bool {
 	storedKey, err := settingService.GetAdminAPIKey(c.Request.Context())
 	if err != nil {
@@ -128,8 +147,27 @@ func validateAdminAPIKey(
 		return false
 	}
 
+	var (
+		access   *service.AdminAccess
+		adminKey *service.AdminKey
+	)
+	if storedKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(storedKey)) == 1 {
+		access = service.SuperAdminAccess()
+	} else if adminKeyService != nil {
+		adminKey, err = adminKeyService.Authenticate(c.Request.Context(), key, ip.GetTrustedClientIP(c))
+		if err != nil {
+			var appErr *infraerrors.ApplicationError
+			if errors.As(err, &appErr) {
+				AbortWithError(c, int(appErr.Code), appErr.Reason, appErr.Message)
+				return false
+			}
+			AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+			return false
+		}
+		access = adminKey.AccessFor(c.Request.Method, adminRelativeRoute(c.FullPath()))
+	}
 	// 未配置或不匹配，统一返回相同错误（避免信息泄露）
-	if storedKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(storedKey)) != 1 {
+	if access == nil {
 		AbortWithError(c, 401, "INVALID_ADMIN_KEY", "Invalid admin API key")
 		return false
 	}
@@ -146,16 +184,37 @@ func validateAdminAPIKey(
 		Concurrency: admin.Concurrency,
 	})
 	c.Set(string(ContextKeyUserRole), admin.Role)
+	c.Set(string(ContextKeyAdminAccess), access)
 	c.Set("auth_method", "admin_api_key")
+	if adminKey != nil {
+		c.Set(string(ContextKeyAdminKey), adminKey)
+		// 请求级日志归属到具体的 Key
+		ctx := logger.IntoContext(c.Request.Context(), logger.FromContext(c.Request.Context()).With(
+			zap.Int64("admin_api_key_id", adminKey.ID),
+			zap.String("admin_api_key_name", adminKey.Name),
+		))
+		c.Request = c.Request.WithContext(ctx)
+	}
 	return true
 }
 
+// adminRelativeRoute 将路由模板转换为相对 /api/v1/admin 的形式（如 "users/:id"）
+func adminRelativeRoute(fullPath string) string {
+	const marker = "/admin/"
+	if idx := strings.Index(fullPath, marker); idx >= 0 {
+		return fullPath[idx+len(marker):]
+	}
+	return strings.TrimPrefix(fullPath, "/")
+}
+
 // validateJWTForAdmin 验证 JWT 并检查管理员权限
 func validateJWTForAdmin(
 	c *gin.Context,
 	token string,
 	authService *service.AuthService,
 	userService *service.UserService,
+	passkeyService *service.PasskeyService,
+	rbacService *service.AdminRBACService,
 ) bool {
 	// 验证 JWT token
 	claims, err := authService.ValidateToken(token)
@@ -168,6 +227,12 @@ func validateJWTForAdmin(
 		return false
 	}
 
+	// 模拟登录 Token 只代表被模拟的普通用户，不能访问管理后台
+	if claims.Impersonation != nil {
+		AbortWithError(c, 403, "IMPERSONATION_FORBIDDEN", "Impersonation tokens cannot access the admin API")
+		return false
+	}
+
 	// 从数据库获取用户
 	user, err := userService.GetByID(c.Request.Context(), claims.UserID)
 	if err != nil {
@@ -187,17 +252,46 @@ func validateJWTForAdmin(
 		return false
 	}
 
-	// 检查管理员权限
-	if !user.IsAdmin() {
+	// 会话已被撤销或登出时，其 Access Token 立即失效
+	if !authService.IsSessionActive(c.Request.Context(), user.ID, claims.SessionID) {
+		AbortWithError(c, 401, "SESSION_REVOKED", "Session has been revoked")
+		return false
+	}
+
+	// 检查管理后台访问权限（未启用 RBAC 时退化为单一管理员角色）
+	var access *service.AdminAccess
+	if rbacService != nil {
+		access, err = rbacService.ResolveAccess(c.Request.Context(), user)
+		if err != nil {
+			AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+			return false
+		}
+	} else if user.IsAdmin() {
+		access = service.SuperAdminAccess()
+	}
+	if access == nil {
 		AbortWithError(c, 403, "FORBIDDEN", "Admin access required")
 		return false
 	}
 
+	// 管理员第二因素策略
+	if passkeyService != nil {
+		if err := passkeyService.CheckStaffSecondFactor(c.Request.Context(), user); err != nil {
+			if errors.Is(err, service.ErrAdminSecondFactorRequired) {
+				AbortWithError(c, 403, "ADMIN_2FA_REQUIRED", "Admin accounts must enable two-factor authentication (TOTP or passkey)")
+				return false
+			}
+			AbortWithError(c, 500, "INTERNAL_ERROR", "Internal server error")
+			return false
+		}
+	}
+
 	c.Set(string(ContextKeyUser), AuthSubject{
 		UserID:      user.ID,
 		Concurrency: user.Concurrency,
 	})
 	c.Set(string(ContextKeyUserRole), user.Role)
+	c.Set(string(ContextKeyAdminAccess), access)
 	c.Set("auth_method", "jwt")
 
 	return true
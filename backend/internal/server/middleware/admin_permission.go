@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAdminAccessFromContext 获取管理后台有效权限（由 AdminAuth 中间件写入）
+func GetAdminAccessFromContext(c *gin.Context) (*service.AdminAccess, bool) {
+	value, exists := c.Get(string(ContextKeyAdminAccess))
+	if !exists {
+		return nil, false
+	}
+	access, ok := value.(*service.AdminAccess)
+	return access, ok && access != nil
+}
+
+func getAdminKeyFromContext(c *gin.Context) (*service.AdminKey, bool) {
+	value, exists := c.Get(string(ContextKeyAdminKey))
+	if !exists {
+		return nil, false
+	}
+	key, ok := value.(*service.AdminKey)
+	return key, ok && key != nil
+}
+
+// RequireAdminPermission 管理后台权限校验中间件，要求同时拥有全部给定权限。
+// 必须在 AdminAuth 中间件之后使用。
+func RequireAdminPermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, ok := GetAdminAccessFromContext(c)
+		if !ok {
+			AbortWithError(c, 403, "FORBIDDEN", "Admin access required")
+			return
+		}
+		for _, permission := range permissions {
+			if !access.Allows(permission) {
+				AbortWithError(c, 403, "ADMIN_PERMISSION_DENIED", "Missing admin permission: "+permission)
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdminPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(access *service.AdminAccess) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if access != nil {
+				c.Set(string(ContextKeyAdminAccess), access)
+			}
+			c.Next()
+		})
+		router.GET("/read", RequireAdminPermission(service.AdminPermUsersRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.POST("/balance", RequireAdminPermission(service.AdminPermUsersRead, service.AdminPermUsersBalance), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	do := func(router *gin.Engine, method, path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	t.Run("missing_access_rejected", func(t *testing.T) {
+		router := newRouter(nil)
+		require.Equal(t, http.StatusForbidden, do(router, http.MethodGet, "/read"))
+	})
+
+	t.Run("role_permissions_enforced", func(t *testing.T) {
+		router := newRouter(service.NewAdminAccess(&service.AdminRole{
+			Key:         service.AdminRoleSupport,
+			Permissions: []string{service.AdminPermUsersRead},
+		}))
+		require.Equal(t, http.StatusOK, do(router, http.MethodGet, "/read"))
+		require.Equal(t, http.StatusForbidden, do(router, http.MethodPost, "/balance"))
+	})
+
+	t.Run("superadmin_allowed", func(t *testing.T) {
+		router := newRouter(service.SuperAdminAccess())
+		require.Equal(t, http.StatusOK, do(router, http.MethodGet, "/read"))
+		require.Equal(t, http.StatusOK, do(router, http.MethodPost, "/balance"))
+	})
+}
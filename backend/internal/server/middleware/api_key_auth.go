@@ -7,6 +7,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
@@ -78,6 +79,12 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 
 		// ── 3. 基础鉴权（始终执行） ─────────────────────────────────
 
+		// 费用异常自动停用 → 需管理员解除
+		if apiKey.Status == service.StatusAPIKeySuspended {
+			AbortWithError(c, 403, "API_KEY_SUSPENDED", "API key is suspended due to abnormal usage")
+			return
+		}
+
 		// disabled / 未知状态 → 无条件拦截（expired 和 quota_exhausted 留给计费阶段）
 		if !apiKey.IsActive() &&
 			apiKey.Status != service.StatusAPIKeyExpired &&
@@ -109,9 +116,31 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			return
 		}
 
+		// 分组级每 Key 每小时来源 IP 上限（防共享/转售）
+		if apiKey.Group != nil && apiKey.Group.MaxIPsPerKeyPerHour > 0 {
+			if err := apiKeyService.CheckIPLimit(c.Request.Context(), apiKey, ip.GetTrustedClientIP(c)); err != nil {
+				AbortWithError(c, 429, "API_KEY_IP_LIMIT_EXCEEDED", "Too many distinct client IPs for this API key")
+				return
+			}
+		}
+
+		// Key 访问策略：端点/平台白名单（/v1/usage 仅查询用量，不受限制）
+		if apiKey.Policy != nil && c.Request.URL.Path != "/v1/usage" {
+			if err := apiKeyService.CheckPolicyAccess(c.Request.Context(), apiKey, c.Request.URL.Path, requestPlatform(c, apiKey)); err != nil {
+				AbortWithError(c, infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+				return
+			}
+		}
+
 		// ── 4. SimpleMode → early return ─────────────────────────────
 
 		if cfg.RunMode == config.RunModeSimple {
+			if c.Request.URL.Path != "/v1/usage" {
+				if err := enforceRequestRateLimit(c, apiKeyService, apiKey); err != nil {
+					AbortWithError(c, infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+					return
+				}
+			}
 			c.Set(string(ContextKeyAPIKey), apiKey)
 			c.Set(string(ContextKeyUser), AuthSubject{
 				UserID:      apiKey.User.ID,
@@ -119,6 +148,7 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setQueuePriorityContext(c, apiKey)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 			c.Next()
 			return
@@ -200,6 +230,12 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 					return
 				}
 			}
+
+			// Key/用户/分组 RPM/TPM 滑动窗口限流（计费校验通过后才计入请求数）
+			if err := enforceRequestRateLimit(c, apiKeyService, apiKey); err != nil {
+				AbortWithError(c, infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+				return
+			}
 		}
 
 		// ── 7. 设置上下文 → Next ─────────────────────────────────────
@@ -214,12 +250,29 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setQueuePriorityContext(c, apiKey)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 
 		c.Next()
 	}
 }
 
+// setQueuePriorityContext 记录请求的账号排队优先级，直接获取账号槽位时据此让位给更高优先级的排队者
+func setQueuePriorityContext(c *gin.Context, apiKey *service.APIKey) {
+	c.Request = c.Request.WithContext(service.WithQueuePriority(c.Request.Context(), service.ResolveQueuePriority(apiKey)))
+}
+
+// requestPlatform 返回本次请求的目标平台：强制平台路由优先，其次为分组平台
+func requestPlatform(c *gin.Context, apiKey *service.APIKey) string {
+	if platform, ok := GetForcePlatformFromContext(c); ok && platform != "" {
+		return platform
+	}
+	if apiKey.Group != nil {
+		return apiKey.Group.Platform
+	}
+	return ""
+}
+
 // GetAPIKeyFromContext 从上下文中获取API key
 func GetAPIKeyFromContext(c *gin.Context) (*service.APIKey, bool) {
 	value, exists := c.Get(string(ContextKeyAPIKey))
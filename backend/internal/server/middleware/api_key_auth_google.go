@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +44,10 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			return
 		}
 
+		if apiKey.Status == service.StatusAPIKeySuspended {
+			abortWithGoogleError(c, 403, "API key is suspended due to abnormal usage")
+			return
+		}
 		if !apiKey.IsActive() {
 			abortWithGoogleError(c, 401, "API key is disabled")
 			return
@@ -54,9 +60,25 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 401, "User account is not active")
 			return
 		}
+		if apiKey.Group != nil && apiKey.Group.MaxIPsPerKeyPerHour > 0 {
+			if err := apiKeyService.CheckIPLimit(c.Request.Context(), apiKey, ip.GetTrustedClientIP(c)); err != nil {
+				abortWithGoogleError(c, 429, "Too many distinct client IPs for this API key")
+				return
+			}
+		}
+		if apiKey.Policy != nil {
+			if err := apiKeyService.CheckPolicyAccess(c.Request.Context(), apiKey, c.Request.URL.Path, requestPlatform(c, apiKey)); err != nil {
+				abortWithGoogleError(c, infraerrors.Code(err), infraerrors.Message(err))
+				return
+			}
+		}
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
+			if err := enforceRequestRateLimit(c, apiKeyService, apiKey); err != nil {
+				abortWithGoogleError(c, infraerrors.Code(err), infraerrors.Message(err))
+				return
+			}
 			c.Set(string(ContextKeyAPIKey), apiKey)
 			c.Set(string(ContextKeyUser), AuthSubject{
 				UserID:      apiKey.User.ID,
@@ -64,6 +86,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setQueuePriorityContext(c, apiKey)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 			c.Next()
 			return
@@ -106,6 +129,12 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			}
 		}
 
+		// Key/用户/分组 RPM/TPM 滑动窗口限流（计费校验通过后才计入请求数）
+		if err := enforceRequestRateLimit(c, apiKeyService, apiKey); err != nil {
+			abortWithGoogleError(c, infraerrors.Code(err), infraerrors.Message(err))
+			return
+		}
+
 		c.Set(string(ContextKeyAPIKey), apiKey)
 		c.Set(string(ContextKeyUser), AuthSubject{
 			UserID:      apiKey.User.ID,
@@ -113,6 +142,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setQueuePriorityContext(c, apiKey)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 		c.Next()
 	}
@@ -5,6 +5,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -49,7 +50,7 @@ func TestSimpleModeBypassesQuotaCheck(t *testing.T) {
 
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -206,7 +207,7 @@ func TestAPIKeyAuthSetsGroupContext(t *testing.T) {
 
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -264,7 +265,7 @@ func TestAPIKeyAuthOverwritesInvalidContextGroup(t *testing.T) {
 
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -321,7 +322,7 @@ func TestAPIKeyAuthIPRestrictionDoesNotTrustSpoofedForwardHeaders(t *testing.T)
 
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -373,7 +374,7 @@ func TestAPIKeyAuthTouchesLastUsedOnSuccess(t *testing.T) {
 	var touchedAt time.Time
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -421,7 +422,7 @@ func TestAPIKeyAuthTouchLastUsedFailureDoesNotBlock(t *testing.T) {
 	touchCalls := 0
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -467,7 +468,7 @@ func TestAPIKeyAuthTouchesLastUsedInStandardMode(t *testing.T) {
 	touchCalls := 0
 	apiKeyRepo := &stubApiKeyRepo{
 		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
-			if key != apiKey.Key {
+			if key != service.HashAPIKey("", apiKey.Key) {
 				return nil, service.ErrAPIKeyNotFound
 			}
 			clone := *apiKey
@@ -492,6 +493,208 @@ func TestAPIKeyAuthTouchesLastUsedInStandardMode(t *testing.T) {
 	require.Equal(t, 1, touchCalls)
 }
 
+func TestAPIKeyAuthEnforcesGroupIPLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3}
+	group := &service.Group{ID: 42, Name: "g", Status: service.StatusActive, Hydrated: true, MaxIPsPerKeyPerHour: 1}
+	apiKey := &service.APIKey{ID: 100, UserID: user.ID, Key: "test-key", Status: service.StatusActive, User: user, Group: group}
+	apiKey.GroupID = &group.ID
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	limiter := &stubIPLimiter{allowed: map[string]bool{"1.1.1.1": true}}
+	apiKeyService.SetIPLimiter(limiter)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	router.GET("/t", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	send := func(remote string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.RemoteAddr = remote + ":12345"
+		req.Header.Set("x-api-key", apiKey.Key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("1.1.1.1").Code)
+	w := send("2.2.2.2")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_IP_LIMIT_EXCEEDED")
+	require.Equal(t, []string{"1.1.1.1", "2.2.2.2"}, limiter.seen)
+
+	// 未配置上限的分组不调用限制器
+	group.MaxIPsPerKeyPerHour = 0
+	require.Equal(t, http.StatusOK, send("3.3.3.3").Code)
+	require.Len(t, limiter.seen, 2)
+}
+
+func TestAPIKeyAuthEnforcesPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3}
+	group := &service.Group{ID: 42, Name: "g", Platform: service.PlatformAnthropic, Status: service.StatusActive, Hydrated: true}
+	apiKey := &service.APIKey{ID: 100, UserID: user.ID, Key: "test-key", Status: service.StatusActive, User: user, Group: group}
+	apiKey.GroupID = &group.ID
+	apiKey.Policy = &service.APIKeyPolicy{
+		AllowedEndpoints: []string{"/v1/messages", "/v1/usage"},
+		AllowedPlatforms: []string{service.PlatformAnthropic},
+		RPM:              2,
+	}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	apiKeyService.SetRequestRateLimiter(service.NewRequestRateLimitService(newStubRequestRateLimitCache()))
+	router := gin.New()
+	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, nil, cfg)))
+	router.POST("/v1/messages", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.POST("/v1/responses", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	send := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("x-api-key", apiKey.Key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/v1/responses")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_ENDPOINT_NOT_ALLOWED")
+
+	w = send("/v1/messages")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get("x-ratelimit-limit-requests"))
+	require.Equal(t, "1", w.Header().Get("x-ratelimit-remaining-requests"))
+	require.Equal(t, http.StatusOK, send("/v1/messages").Code)
+	w = send("/v1/messages")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_RPM_EXCEEDED")
+	require.Equal(t, "30", w.Header().Get("Retry-After"))
+	require.Equal(t, "0", w.Header().Get("x-ratelimit-remaining-requests"))
+
+	// 分组平台不在允许列表内
+	group.Platform = service.PlatformOpenAI
+	w = send("/v1/messages")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "API_KEY_PLATFORM_NOT_ALLOWED")
+}
+
+func TestAPIKeyAuthEnforcesUserRateLimitAndReleasesKeySlot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &service.User{ID: 7, Role: service.RoleUser, Status: service.StatusActive, Balance: 10, Concurrency: 3, RPMLimit: 1}
+	apiKey := &service.APIKey{ID: 100, UserID: user.ID, Key: "test-key", Status: service.StatusActive, User: user}
+	apiKey.Policy = &service.APIKeyPolicy{RPM: 5, TPM: 1000}
+
+	apiKeyRepo := &stubApiKeyRepo{
+		getByKey: func(ctx context.Context, key string) (*service.APIKey, error) {
+			clone := *apiKey
+			return &clone, nil
+		},
+	}
+	cfg := &config.Config{RunMode: config.RunModeSimple}
+	cache := newStubRequestRateLimitCache()
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, nil, nil, nil, nil, nil, cfg)
+	apiKeyService.SetRequestRateLimiter(service.NewRequestRateLimitService(cache))
+	router := newAuthTestRouter(apiKeyService, nil, cfg)
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/t", nil)
+		req.Header.Set("x-api-key", apiKey.Key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send()
+	require.Equal(t, http.StatusOK, w.Code)
+	// 用户级剩余额度更少，响应头以用户维度为准
+	require.Equal(t, "1", w.Header().Get("x-ratelimit-limit-requests"))
+	require.Equal(t, "0", w.Header().Get("x-ratelimit-remaining-requests"))
+	require.Equal(t, "1000", w.Header().Get("x-ratelimit-limit-tokens"))
+
+	w = send()
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Contains(t, w.Body.String(), "USER_RPM_EXCEEDED")
+	// 被用户维度拒绝的请求不占用 Key 维度额度
+	require.Equal(t, 1, cache.requests["api_key:100"])
+}
+
+// stubRequestRateLimitCache 内存计数器（不模拟窗口滑动）
+type stubRequestRateLimitCache struct {
+	requests map[string]int
+	tokens   map[string]int
+}
+
+func newStubRequestRateLimitCache() *stubRequestRateLimitCache {
+	return &stubRequestRateLimitCache{requests: map[string]int{}, tokens: map[string]int{}}
+}
+
+func (c *stubRequestRateLimitCache) key(rule service.RequestRateLimitRule) string {
+	return fmt.Sprintf("%s:%d", rule.Scope, rule.SubjectID)
+}
+
+func (c *stubRequestRateLimitCache) Acquire(ctx context.Context, rule service.RequestRateLimitRule) (*service.RequestRateLimitWindow, error) {
+	k := c.key(rule)
+	window := &service.RequestRateLimitWindow{Requests: c.requests[k], Tokens: c.tokens[k], ResetRequests: 30 * time.Second}
+	switch {
+	case rule.RPM > 0 && c.requests[k] >= rule.RPM:
+		window.RequestsExceeded = true
+		window.RetryAfter = 30 * time.Second
+	case rule.TPM > 0 && c.tokens[k] >= rule.TPM:
+		window.RetryAfter = 30 * time.Second
+	default:
+		c.requests[k]++
+		window.Allowed = true
+		window.Requests = c.requests[k]
+	}
+	return window, nil
+}
+
+func (c *stubRequestRateLimitCache) Release(ctx context.Context, rule service.RequestRateLimitRule, bucket int64) error {
+	c.requests[c.key(rule)]--
+	return nil
+}
+
+func (c *stubRequestRateLimitCache) AddTokens(ctx context.Context, rule service.RequestRateLimitRule, tokens int) error {
+	c.tokens[c.key(rule)] += tokens
+	return nil
+}
+
+type stubIPLimiter struct {
+	allowed map[string]bool
+	seen    []string
+}
+
+func (l *stubIPLimiter) CheckIPLimit(ctx context.Context, apiKey *service.APIKey, clientIP string) error {
+	l.seen = append(l.seen, clientIP)
+	if l.allowed[clientIP] {
+		return nil
+	}
+	return service.ErrAPIKeyIPLimitExceeded
+}
+
 func newAuthTestRouter(apiKeyService *service.APIKeyService, subscriptionService *service.SubscriptionService, cfg *config.Config) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.HandlerFunc(NewAPIKeyAuthMiddleware(apiKeyService, subscriptionService, cfg)))
@@ -514,8 +717,8 @@ func (r *stubApiKeyRepo) GetByID(ctx context.Context, id int64) (*service.APIKey
 	return nil, errors.New("not implemented")
 }
 
-func (r *stubApiKeyRepo) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
-	return "", 0, errors.New("not implemented")
+func (r *stubApiKeyRepo) GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error) {
+	return nil, 0, errors.New("not implemented")
 }
 
 func (r *stubApiKeyRepo) GetByKey(ctx context.Context, key string) (*service.APIKey, error) {
@@ -577,6 +780,14 @@ func (r *stubApiKeyRepo) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	return nil, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) Rotate(ctx context.Context, rotation service.APIKeyRotation) error {
+	return errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) ClearExpiredPreviousKeys(ctx context.Context, now time.Time) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	return 0, errors.New("not implemented")
 }
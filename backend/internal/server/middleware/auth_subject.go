@@ -1,6 +1,10 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
 
 // AuthSubject is the minimal authenticated identity stored in gin context.
 // Decision: {UserID int64, Concurrency int}
@@ -26,3 +30,18 @@ func GetUserRoleFromContext(c *gin.Context) (string, bool) {
 	role, ok := value.(string)
 	return role, ok
 }
+
+// GetSessionIDFromContext returns the login session of the current JWT, if any.
+func GetSessionIDFromContext(c *gin.Context) string {
+	value, _ := c.Get(string(ContextKeySessionID))
+	sessionID, _ := value.(string)
+	return sessionID
+}
+
+// GetImpersonationFromContext returns the impersonation session when the current JWT
+// was minted by an admin impersonating the user.
+func GetImpersonationFromContext(c *gin.Context) *service.ImpersonationSession {
+	value, _ := c.Get(string(ContextKeyImpersonation))
+	session, _ := value.(*service.ImpersonationSession)
+	return session
+}
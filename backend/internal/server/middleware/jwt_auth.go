@@ -4,18 +4,20 @@ import (
 	"errors"
 	"strings"
 
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 // NewJWTAuthMiddleware 创建 JWT 认证中间件
-func NewJWTAuthMiddleware(authService *service.AuthService, userService *service.UserService) JWTAuthMiddleware {
-	return JWTAuthMiddleware(jwtAuth(authService, userService))
+func NewJWTAuthMiddleware(authService *service.AuthService, userService *service.UserService, impersonationService *service.ImpersonationService) JWTAuthMiddleware {
+	return JWTAuthMiddleware(jwtAuth(authService, userService, impersonationService))
 }
 
 // jwtAuth JWT认证中间件实现
-func jwtAuth(authService *service.AuthService, userService *service.UserService) gin.HandlerFunc {
+func jwtAuth(authService *service.AuthService, userService *service.UserService, impersonationService *service.ImpersonationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从Authorization header中提取token
 		authHeader := c.GetHeader("Authorization")
@@ -68,14 +70,56 @@ func jwtAuth(authService *service.AuthService, userService *service.UserService)
 			return
 		}
 
+		// 会话已被撤销或登出时，其 Access Token 立即失效
+		if !authService.IsSessionActive(c.Request.Context(), user.ID, claims.SessionID) {
+			AbortWithError(c, 401, "SESSION_REVOKED", "Session has been revoked")
+			return
+		}
+
 		c.Set(string(ContextKeyUser), AuthSubject{
 			UserID:      user.ID,
 			Concurrency: user.Concurrency,
 		})
 		c.Set(string(ContextKeyUserRole), user.Role)
+		if claims.SessionID != "" {
+			c.Set(string(ContextKeySessionID), claims.SessionID)
+		}
+
+		if claims.Impersonation != nil {
+			impersonateRequest(c, impersonationService, claims.Impersonation, user.ID)
+			return
+		}
 
 		c.Next()
 	}
 }
 
+// impersonateRequest 处理管理员模拟 Token：校验会话仍有效、拦截只读范围外与敏感的操作，
+// 并在请求结束后写入审计日志（含被拦截的请求）
+func impersonateRequest(c *gin.Context, impersonationService *service.ImpersonationService, claim *service.ImpersonationClaim, userID int64) {
+	if impersonationService == nil {
+		AbortWithError(c, 401, "INVALID_TOKEN", "Invalid token")
+		return
+	}
+	session, err := impersonationService.Validate(c.Request.Context(), claim, userID)
+	if err != nil {
+		AbortWithError(c, infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+		return
+	}
+	c.Set(string(ContextKeyImpersonation), session)
+
+	method, path := c.Request.Method, c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	if err := service.CheckImpersonationAccess(session, method, path); err != nil {
+		AbortWithError(c, infraerrors.Code(err), infraerrors.Reason(err), infraerrors.Message(err))
+		impersonationService.RecordRequest(c.Request.Context(), session, method, path, c.Writer.Status(), infraerrors.Reason(err), ip.GetClientIP(c))
+		return
+	}
+
+	c.Next()
+	impersonationService.RecordRequest(c.Request.Context(), session, method, path, c.Writer.Status(), "", ip.GetClientIP(c))
+}
+
 // Deprecated: prefer GetAuthSubjectFromContext in auth_subject.go.
@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -42,7 +44,7 @@ func newJWTTestEnv(users map[int64]*service.User) (*gin.Engine, *service.AuthSer
 	userRepo := &stubJWTUserRepo{users: users}
 	authSvc := service.NewAuthService(userRepo, nil, nil, cfg, nil, nil, nil, nil, nil, nil)
 	userSvc := service.NewUserService(userRepo, nil, nil)
-	mw := NewJWTAuthMiddleware(authSvc, userSvc)
+	mw := NewJWTAuthMiddleware(authSvc, userSvc, nil)
 
 	r := gin.New()
 	r.Use(gin.HandlerFunc(mw))
@@ -254,3 +256,119 @@ func TestJWTAuth_TokenVersionMismatch(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
 	require.Equal(t, "TOKEN_REVOKED", body.Code)
 }
+
+type stubImpersonationCache struct {
+	sessions map[string]*service.ImpersonationSession
+}
+
+func (c *stubImpersonationCache) SaveImpersonation(_ context.Context, session *service.ImpersonationSession, _ time.Duration) error {
+	c.sessions[session.ID] = session
+	return nil
+}
+
+func (c *stubImpersonationCache) GetImpersonation(_ context.Context, id string) (*service.ImpersonationSession, error) {
+	return c.sessions[id], nil
+}
+
+func (c *stubImpersonationCache) DeleteImpersonation(_ context.Context, id string) error {
+	delete(c.sessions, id)
+	return nil
+}
+
+type stubImpersonationAuditRepo struct {
+	logs []service.ImpersonationAuditLog
+}
+
+func (r *stubImpersonationAuditRepo) Create(_ context.Context, log *service.ImpersonationAuditLog) error {
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *stubImpersonationAuditRepo) List(context.Context, service.ImpersonationAuditFilter, pagination.PaginationParams) ([]service.ImpersonationAuditLog, *pagination.PaginationResult, error) {
+	return r.logs, &pagination.PaginationResult{}, nil
+}
+
+func TestJWTAuth_ImpersonationToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin := &service.User{ID: 1, Email: "admin@example.com", Role: service.RoleAdmin, Status: service.StatusActive}
+	user := &service.User{ID: 2, Email: "user@example.com", Role: service.RoleUser, Status: service.StatusActive}
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "test-jwt-secret-32bytes-long!!!"
+	userRepo := &stubJWTUserRepo{users: map[int64]*service.User{1: admin, 2: user}}
+	authSvc := service.NewAuthService(userRepo, nil, nil, cfg, nil, nil, nil, nil, nil, nil)
+	cache := &stubImpersonationCache{sessions: map[string]*service.ImpersonationSession{}}
+	audit := &stubImpersonationAuditRepo{}
+	impSvc := service.NewImpersonationService(authSvc, userRepo, cache, audit)
+
+	r := gin.New()
+	r.Use(gin.HandlerFunc(NewJWTAuthMiddleware(authSvc, service.NewUserService(userRepo, nil, nil), impSvc)))
+	ok := func(c *gin.Context) {
+		session := GetImpersonationFromContext(c)
+		c.JSON(http.StatusOK, gin.H{"impersonation_id": session.ID})
+	}
+	r.GET("/api/v1/keys", ok)
+	r.PUT("/api/v1/keys/:id", ok)
+	r.PUT("/api/v1/user/password", ok)
+
+	started, err := impSvc.Start(context.Background(), admin.ID, user.ID, service.ImpersonationInput{Reason: "ticket"})
+	require.NoError(t, err)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+started.AccessToken)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/api/v1/keys")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), started.Session.ID)
+
+	// 只读模拟禁止写操作，敏感操作始终被拦截
+	var body ErrorResponse
+	w = do(http.MethodPut, "/api/v1/keys/5")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "IMPERSONATION_READ_ONLY", body.Code)
+
+	w = do(http.MethodPut, "/api/v1/user/password")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "IMPERSONATION_ACTION_BLOCKED", body.Code)
+
+	// 每个请求都写入审计日志
+	require.Len(t, audit.logs, 4)
+	require.Equal(t, service.ImpersonationEventAction, audit.logs[1].Event)
+	require.Equal(t, "/api/v1/keys", audit.logs[1].Path)
+	require.Equal(t, http.StatusOK, audit.logs[1].StatusCode)
+	require.Equal(t, service.ImpersonationEventBlocked, audit.logs[2].Event)
+	require.Equal(t, "/api/v1/keys/:id", audit.logs[2].Path)
+	require.Equal(t, http.StatusForbidden, audit.logs[2].StatusCode)
+	require.Equal(t, "IMPERSONATION_ACTION_BLOCKED", audit.logs[3].Detail)
+
+	// 结束后 Token 立即失效
+	require.NoError(t, impSvc.End(context.Background(), started.Session, ""))
+	w = do(http.MethodGet, "/api/v1/keys")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, "IMPERSONATION_ENDED", body.Code)
+}
+
+func TestJWTAuth_ImpersonationTokenRejectedWithoutService(t *testing.T) {
+	admin := &service.User{ID: 1, Email: "admin@example.com", Role: service.RoleAdmin, Status: service.StatusActive}
+	user := &service.User{ID: 2, Email: "user@example.com", Role: service.RoleUser, Status: service.StatusActive}
+	router, authSvc := newJWTTestEnv(map[int64]*service.User{1: admin, 2: user})
+
+	impSvc := service.NewImpersonationService(authSvc, &stubJWTUserRepo{users: map[int64]*service.User{1: admin, 2: user}},
+		&stubImpersonationCache{sessions: map[string]*service.ImpersonationSession{}}, &stubImpersonationAuditRepo{})
+	started, err := impSvc.Start(context.Background(), admin.ID, user.ID, service.ImpersonationInput{Reason: "ticket"})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+started.AccessToken)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
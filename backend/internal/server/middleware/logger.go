@@ -62,5 +62,51 @@ func Logger() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			l.Warn("http request contains gin errors", zap.String("errors", c.Errors.String()))
 		}
+
+		if logger.AccessEventsEnabled() {
+			logger.WriteAccessEvent(buildAccessEvent(c, endTime, latency))
+		}
+	}
+}
+
+// buildAccessEvent 组装访问事件（不含请求/响应体）
+func buildAccessEvent(c *gin.Context, endTime time.Time, latency time.Duration) *logger.AccessEvent {
+	ctx := c.Request.Context()
+	event := &logger.AccessEvent{
+		Time:          endTime,
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		Route:         c.FullPath(),
+		Protocol:      c.Request.Proto,
+		StatusCode:    c.Writer.Status(),
+		LatencyMs:     latency.Milliseconds(),
+		ClientIP:      c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		RequestBytes:  c.Request.ContentLength,
+		ResponseBytes: int64(c.Writer.Size()),
+	}
+	if event.RequestBytes < 0 {
+		event.RequestBytes = 0
+	}
+	if event.ResponseBytes < 0 {
+		event.ResponseBytes = 0
+	}
+	event.RequestID, _ = ctx.Value(ctxkey.RequestID).(string)
+	event.ClientRequestID, _ = ctx.Value(ctxkey.ClientRequestID).(string)
+	event.AccountID, _ = ctx.Value(ctxkey.AccountID).(int64)
+	event.Platform, _ = ctx.Value(ctxkey.Platform).(string)
+	event.Model, _ = ctx.Value(ctxkey.Model).(string)
+	if subject, ok := GetAuthSubjectFromContext(c); ok {
+		event.UserID = subject.UserID
+	}
+	if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+		event.APIKeyID = apiKey.ID
+		if apiKey.GroupID != nil {
+			event.GroupID = *apiKey.GroupID
+		}
+	}
+	if adminKey, ok := getAdminKeyFromContext(c); ok {
+		event.AdminAPIKeyID = adminKey.ID
 	}
+	return event
 }
@@ -18,6 +18,14 @@ const (
 	ContextKeyUser ContextKey = "user"
 	// ContextKeyUserRole 当前用户角色（string）
 	ContextKeyUserRole ContextKey = "user_role"
+	// ContextKeySessionID 当前 JWT 所属的登录会话 ID（Refresh Token 家族 ID）
+	ContextKeySessionID ContextKey = "session_id"
+	// ContextKeyImpersonation 管理员模拟登录会话（*service.ImpersonationSession）
+	ContextKeyImpersonation ContextKey = "impersonation"
+	// ContextKeyAdminAccess 管理后台有效权限（*service.AdminAccess）
+	ContextKeyAdminAccess ContextKey = "admin_access"
+	// ContextKeyAdminKey 通过命名管理员 API Key 认证时的 Key（*service.AdminKey）
+	ContextKeyAdminKey ContextKey = "admin_key"
 	// ContextKeyAPIKey API密钥上下文键
 	ContextKeyAPIKey ContextKey = "api_key"
 	// ContextKeySubscription 订阅上下文键
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// enforceRequestRateLimit 校验 Key/用户/分组的 RPM/TPM 滑动窗口限制，并写入 x-ratelimit-* 响应头。
+// 超限时写入 retry-after 并返回 429 错误，由调用方按协议格式输出。
+func enforceRequestRateLimit(c *gin.Context, apiKeyService *service.APIKeyService, apiKey *service.APIKey) error {
+	status, err := apiKeyService.AcquireRequestRateLimit(c.Request.Context(), apiKey)
+	writeRequestRateLimitHeaders(c, status)
+	if err != nil && status != nil {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(status.RetryAfter)))
+		// 超限请求同样关联 Key/用户/分组，便于运维监控归因
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Set(service.OpsRequestRateLimitKey, string(status.ExceededScope)+":"+status.ExceededMetric)
+	}
+	return err
+}
+
+// writeRequestRateLimitHeaders 按 OpenAI 约定输出剩余额度，仅在配置了对应限制时输出
+func writeRequestRateLimitHeaders(c *gin.Context, status *service.RequestRateLimitStatus) {
+	if status == nil {
+		return
+	}
+	if status.HasRequests {
+		c.Header("x-ratelimit-limit-requests", strconv.Itoa(status.LimitRequests))
+		c.Header("x-ratelimit-remaining-requests", strconv.Itoa(status.RemainingRequests))
+		c.Header("x-ratelimit-reset-requests", formatRateLimitReset(status.ResetRequests))
+	}
+	if status.HasTokens {
+		c.Header("x-ratelimit-limit-tokens", strconv.Itoa(status.LimitTokens))
+		c.Header("x-ratelimit-remaining-tokens", strconv.Itoa(status.RemainingTokens))
+		c.Header("x-ratelimit-reset-tokens", formatRateLimitReset(status.ResetTokens))
+	}
+}
+
+// formatRateLimitReset 输出如 "12s"、"1m0s" 的时长
+func formatRateLimitReset(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return d.Round(time.Second).String()
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
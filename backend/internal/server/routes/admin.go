@@ -4,10 +4,17 @@ package routes
 import (
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// perm 路由级管理权限校验（权限点定义见 service/admin_rbac_service.go）。
+// 约定：路由组挂载读权限，写操作在单条路由上追加写权限。
+func perm(permissions ...string) gin.HandlerFunc {
+	return middleware.RequireAdminPermission(permissions...)
+}
+
 // RegisterAdminRoutes 注册管理员路由
 func RegisterAdminRoutes(
 	v1 *gin.RouterGroup,
@@ -79,60 +86,85 @@ func RegisterAdminRoutes(
 		// API Key 管理
 		registerAdminAPIKeyRoutes(admin, h)
 
+		// Key 共享/转售审核
+		registerKeySharingRoutes(admin, h)
+
 		// 定时测试计划
 		registerScheduledTestRoutes(admin, h)
+
+		// A/B 模型实验
+		registerExperimentRoutes(admin, h)
+
+		// 管理角色与权限
+		registerRBACRoutes(admin, h)
 	}
 }
 
 func registerAdminAPIKeyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	apiKeys := admin.Group("/api-keys")
 	{
-		apiKeys.PUT("/:id", h.Admin.APIKey.UpdateGroup)
+		apiKeys.PUT("/:id", perm(service.AdminPermUsersWrite), h.Admin.APIKey.UpdateGroup)
+		apiKeys.GET("/:id/cost-baseline", perm(service.AdminPermUsersRead), h.Admin.CostAnomaly.GetAPIKeyBaseline)
+		apiKeys.POST("/:id/unsuspend", perm(service.AdminPermUsersWrite), h.Admin.CostAnomaly.Unsuspend)
+		apiKeys.POST("/:id/rotate", perm(service.AdminPermUsersWrite), h.Admin.APIKey.Rotate)
+	}
+}
+
+func registerKeySharingRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	keySharing := admin.Group("/key-sharing", perm(service.AdminPermUsersRead))
+	{
+		keySharing.GET("/reviews", h.Admin.KeySharing.List)
+		keySharing.GET("/reviews/:id", h.Admin.KeySharing.GetByID)
+		keySharing.POST("/reviews/:id/resolve", perm(service.AdminPermUsersWrite), h.Admin.KeySharing.Resolve)
 	}
 }
 
 func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	ops := admin.Group("/ops")
+	ops := admin.Group("/ops", perm(service.AdminPermOpsRead))
+	opsWrite := perm(service.AdminPermOpsWrite)
 	{
 		// Realtime ops signals
 		ops.GET("/concurrency", h.Admin.Ops.GetConcurrencyStats)
 		ops.GET("/user-concurrency", h.Admin.Ops.GetUserConcurrencyStats)
 		ops.GET("/account-availability", h.Admin.Ops.GetAccountAvailability)
 		ops.GET("/realtime-traffic", h.Admin.Ops.GetRealtimeTrafficSummary)
+		ops.GET("/cross-platform-failover", h.Admin.Ops.GetCrossPlatformFailoverStats)
+		ops.GET("/stream-resume", h.Admin.Ops.GetStreamResumeStats)
+		ops.GET("/account-scheduler", h.Admin.Ops.GetAccountSchedulerMetrics)
 
 		// Alerts (rules + events)
 		ops.GET("/alert-rules", h.Admin.Ops.ListAlertRules)
-		ops.POST("/alert-rules", h.Admin.Ops.CreateAlertRule)
-		ops.PUT("/alert-rules/:id", h.Admin.Ops.UpdateAlertRule)
-		ops.DELETE("/alert-rules/:id", h.Admin.Ops.DeleteAlertRule)
+		ops.POST("/alert-rules", opsWrite, h.Admin.Ops.CreateAlertRule)
+		ops.PUT("/alert-rules/:id", opsWrite, h.Admin.Ops.UpdateAlertRule)
+		ops.DELETE("/alert-rules/:id", opsWrite, h.Admin.Ops.DeleteAlertRule)
 		ops.GET("/alert-events", h.Admin.Ops.ListAlertEvents)
 		ops.GET("/alert-events/:id", h.Admin.Ops.GetAlertEvent)
-		ops.PUT("/alert-events/:id/status", h.Admin.Ops.UpdateAlertEventStatus)
-		ops.POST("/alert-silences", h.Admin.Ops.CreateAlertSilence)
+		ops.PUT("/alert-events/:id/status", opsWrite, h.Admin.Ops.UpdateAlertEventStatus)
+		ops.POST("/alert-silences", opsWrite, h.Admin.Ops.CreateAlertSilence)
 
 		// Email notification config (DB-backed)
 		ops.GET("/email-notification/config", h.Admin.Ops.GetEmailNotificationConfig)
-		ops.PUT("/email-notification/config", h.Admin.Ops.UpdateEmailNotificationConfig)
+		ops.PUT("/email-notification/config", opsWrite, h.Admin.Ops.UpdateEmailNotificationConfig)
 
 		// Runtime settings (DB-backed)
 		runtime := ops.Group("/runtime")
 		{
 			runtime.GET("/alert", h.Admin.Ops.GetAlertRuntimeSettings)
-			runtime.PUT("/alert", h.Admin.Ops.UpdateAlertRuntimeSettings)
+			runtime.PUT("/alert", opsWrite, h.Admin.Ops.UpdateAlertRuntimeSettings)
 			runtime.GET("/logging", h.Admin.Ops.GetRuntimeLogConfig)
-			runtime.PUT("/logging", h.Admin.Ops.UpdateRuntimeLogConfig)
-			runtime.POST("/logging/reset", h.Admin.Ops.ResetRuntimeLogConfig)
+			runtime.PUT("/logging", opsWrite, h.Admin.Ops.UpdateRuntimeLogConfig)
+			runtime.POST("/logging/reset", opsWrite, h.Admin.Ops.ResetRuntimeLogConfig)
 		}
 
 		// Advanced settings (DB-backed)
 		ops.GET("/advanced-settings", h.Admin.Ops.GetAdvancedSettings)
-		ops.PUT("/advanced-settings", h.Admin.Ops.UpdateAdvancedSettings)
+		ops.PUT("/advanced-settings", opsWrite, h.Admin.Ops.UpdateAdvancedSettings)
 
 		// Settings group (DB-backed)
 		settings := ops.Group("/settings")
 		{
 			settings.GET("/metric-thresholds", h.Admin.Ops.GetMetricThresholds)
-			settings.PUT("/metric-thresholds", h.Admin.Ops.UpdateMetricThresholds)
+			settings.PUT("/metric-thresholds", opsWrite, h.Admin.Ops.UpdateMetricThresholds)
 		}
 
 		// WebSocket realtime (QPS/TPS)
@@ -145,29 +177,29 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/errors", h.Admin.Ops.GetErrorLogs)
 		ops.GET("/errors/:id", h.Admin.Ops.GetErrorLogByID)
 		ops.GET("/errors/:id/retries", h.Admin.Ops.ListRetryAttempts)
-		ops.POST("/errors/:id/retry", h.Admin.Ops.RetryErrorRequest)
-		ops.PUT("/errors/:id/resolve", h.Admin.Ops.UpdateErrorResolution)
+		ops.POST("/errors/:id/retry", opsWrite, h.Admin.Ops.RetryErrorRequest)
+		ops.PUT("/errors/:id/resolve", opsWrite, h.Admin.Ops.UpdateErrorResolution)
 
 		// Request errors (client-visible failures)
 		ops.GET("/request-errors", h.Admin.Ops.ListRequestErrors)
 		ops.GET("/request-errors/:id", h.Admin.Ops.GetRequestError)
 		ops.GET("/request-errors/:id/upstream-errors", h.Admin.Ops.ListRequestErrorUpstreamErrors)
-		ops.POST("/request-errors/:id/retry-client", h.Admin.Ops.RetryRequestErrorClient)
-		ops.POST("/request-errors/:id/upstream-errors/:idx/retry", h.Admin.Ops.RetryRequestErrorUpstreamEvent)
-		ops.PUT("/request-errors/:id/resolve", h.Admin.Ops.ResolveRequestError)
+		ops.POST("/request-errors/:id/retry-client", opsWrite, h.Admin.Ops.RetryRequestErrorClient)
+		ops.POST("/request-errors/:id/upstream-errors/:idx/retry", opsWrite, h.Admin.Ops.RetryRequestErrorUpstreamEvent)
+		ops.PUT("/request-errors/:id/resolve", opsWrite, h.Admin.Ops.ResolveRequestError)
 
 		// Upstream errors (independent upstream failures)
 		ops.GET("/upstream-errors", h.Admin.Ops.ListUpstreamErrors)
 		ops.GET("/upstream-errors/:id", h.Admin.Ops.GetUpstreamError)
-		ops.POST("/upstream-errors/:id/retry", h.Admin.Ops.RetryUpstreamError)
-		ops.PUT("/upstream-errors/:id/resolve", h.Admin.Ops.ResolveUpstreamError)
+		ops.POST("/upstream-errors/:id/retry", opsWrite, h.Admin.Ops.RetryUpstreamError)
+		ops.PUT("/upstream-errors/:id/resolve", opsWrite, h.Admin.Ops.ResolveUpstreamError)
 
 		// Request drilldown (success + error)
 		ops.GET("/requests", h.Admin.Ops.ListRequestDetails)
 
 		// Indexed system logs
 		ops.GET("/system-logs", h.Admin.Ops.ListSystemLogs)
-		ops.POST("/system-logs/cleanup", h.Admin.Ops.CleanupSystemLogs)
+		ops.POST("/system-logs/cleanup", opsWrite, h.Admin.Ops.CleanupSystemLogs)
 		ops.GET("/system-logs/health", h.Admin.Ops.GetSystemLogIngestionHealth)
 
 		// Dashboard (vNext - raw path for MVP)
@@ -182,7 +214,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 }
 
 func registerDashboardRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	dashboard := admin.Group("/dashboard")
+	dashboard := admin.Group("/dashboard", perm(service.AdminPermDashboardRead))
 	{
 		dashboard.GET("/snapshot-v2", h.Admin.Dashboard.GetSnapshotV2)
 		dashboard.GET("/stats", h.Admin.Dashboard.GetStats)
@@ -194,103 +226,121 @@ func registerDashboardRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		dashboard.GET("/users-trend", h.Admin.Dashboard.GetUserUsageTrend)
 		dashboard.POST("/users-usage", h.Admin.Dashboard.GetBatchUsersUsage)
 		dashboard.POST("/api-keys-usage", h.Admin.Dashboard.GetBatchAPIKeysUsage)
-		dashboard.POST("/aggregation/backfill", h.Admin.Dashboard.BackfillAggregation)
+		dashboard.POST("/aggregation/backfill", perm(service.AdminPermOpsWrite), h.Admin.Dashboard.BackfillAggregation)
 	}
 }
 
 func registerUserManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	users := admin.Group("/users")
+	users := admin.Group("/users", perm(service.AdminPermUsersRead))
+	usersWrite := perm(service.AdminPermUsersWrite)
 	{
 		users.GET("", h.Admin.User.List)
 		users.GET("/:id", h.Admin.User.GetByID)
-		users.POST("", h.Admin.User.Create)
-		users.PUT("/:id", h.Admin.User.Update)
-		users.DELETE("/:id", h.Admin.User.Delete)
-		users.POST("/:id/balance", h.Admin.User.UpdateBalance)
+		users.POST("", usersWrite, h.Admin.User.Create)
+		// 修改/删除后台成员账号（可重置其密码）需要角色管理权限，防止越权
+		users.PUT("/:id", usersWrite, h.Admin.RBAC.GuardStaffTarget, h.Admin.User.Update)
+		users.DELETE("/:id", usersWrite, h.Admin.RBAC.GuardStaffTarget, h.Admin.User.Delete)
+		users.POST("/:id/balance", perm(service.AdminPermUsersBalance), h.Admin.User.UpdateBalance)
 		users.GET("/:id/api-keys", h.Admin.User.GetUserAPIKeys)
 		users.GET("/:id/usage", h.Admin.User.GetUserUsage)
+		users.GET("/:id/cost-baseline", h.Admin.CostAnomaly.GetUserBaseline)
 		users.GET("/:id/balance-history", h.Admin.User.GetBalanceHistory)
 
+		// 登录会话（设备）管理
+		users.GET("/:id/sessions", h.Admin.UserSession.List)
+		users.DELETE("/:id/sessions/:session_id", usersWrite, h.Admin.RBAC.GuardStaffTarget, h.Admin.UserSession.Revoke)
+		users.POST("/:id/sessions/revoke-all", usersWrite, h.Admin.RBAC.GuardStaffTarget, h.Admin.UserSession.RevokeAll)
+
+		// 模拟登录（默认只读）与审计日志
+		users.POST("/:id/impersonate", perm(service.AdminPermUsersImpersonate), h.Admin.RBAC.GuardStaffTarget, h.Admin.Impersonation.Start)
+		users.GET("/impersonation-logs", perm(service.AdminPermUsersImpersonate), h.Admin.Impersonation.ListLogs)
+
 		// User attribute values
 		users.GET("/:id/attributes", h.Admin.UserAttribute.GetUserAttributes)
-		users.PUT("/:id/attributes", h.Admin.UserAttribute.UpdateUserAttributes)
+		users.PUT("/:id/attributes", usersWrite, h.Admin.UserAttribute.UpdateUserAttributes)
 	}
 }
 
 func registerGroupRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	groups := admin.Group("/groups")
+	groups := admin.Group("/groups", perm(service.AdminPermGroupsRead))
+	groupsWrite := perm(service.AdminPermGroupsWrite)
 	{
 		groups.GET("", h.Admin.Group.List)
 		groups.GET("/all", h.Admin.Group.GetAll)
-		groups.PUT("/sort-order", h.Admin.Group.UpdateSortOrder)
+		groups.PUT("/sort-order", groupsWrite, h.Admin.Group.UpdateSortOrder)
 		groups.GET("/:id", h.Admin.Group.GetByID)
-		groups.POST("", h.Admin.Group.Create)
-		groups.PUT("/:id", h.Admin.Group.Update)
-		groups.DELETE("/:id", h.Admin.Group.Delete)
+		groups.POST("", groupsWrite, h.Admin.Group.Create)
+		groups.PUT("/:id", groupsWrite, h.Admin.Group.Update)
+		groups.DELETE("/:id", groupsWrite, h.Admin.Group.Delete)
 		groups.GET("/:id/stats", h.Admin.Group.GetStats)
 		groups.GET("/:id/api-keys", h.Admin.Group.GetGroupAPIKeys)
+		groups.GET("/:id/shadow-mirror/results", h.Admin.ShadowMirror.ListResults)
+		groups.GET("/:id/shadow-mirror/summary", h.Admin.ShadowMirror.GetSummary)
 	}
 }
 
 func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	accounts := admin.Group("/accounts")
+	accounts := admin.Group("/accounts", perm(service.AdminPermAccountsRead))
+	accountsWrite := perm(service.AdminPermAccountsWrite)
 	{
 		accounts.GET("", h.Admin.Account.List)
 		accounts.GET("/:id", h.Admin.Account.GetByID)
-		accounts.POST("", h.Admin.Account.Create)
+		accounts.POST("", accountsWrite, h.Admin.Account.Create)
 		accounts.POST("/check-mixed-channel", h.Admin.Account.CheckMixedChannel)
-		accounts.POST("/sync/crs", h.Admin.Account.SyncFromCRS)
-		accounts.POST("/sync/crs/preview", h.Admin.Account.PreviewFromCRS)
-		accounts.PUT("/:id", h.Admin.Account.Update)
-		accounts.DELETE("/:id", h.Admin.Account.Delete)
-		accounts.POST("/:id/test", h.Admin.Account.Test)
-		accounts.POST("/:id/refresh", h.Admin.Account.Refresh)
-		accounts.POST("/:id/refresh-tier", h.Admin.Account.RefreshTier)
+		accounts.POST("/sync/crs", accountsWrite, h.Admin.Account.SyncFromCRS)
+		accounts.POST("/sync/crs/preview", accountsWrite, h.Admin.Account.PreviewFromCRS)
+		accounts.PUT("/:id", accountsWrite, h.Admin.Account.Update)
+		accounts.DELETE("/:id", accountsWrite, h.Admin.Account.Delete)
+		accounts.POST("/:id/test", accountsWrite, h.Admin.Account.Test)
+		accounts.POST("/:id/refresh", accountsWrite, h.Admin.Account.Refresh)
+		accounts.POST("/:id/refresh-tier", accountsWrite, h.Admin.Account.RefreshTier)
 		accounts.GET("/:id/stats", h.Admin.Account.GetStats)
-		accounts.POST("/:id/clear-error", h.Admin.Account.ClearError)
+		accounts.POST("/:id/clear-error", accountsWrite, h.Admin.Account.ClearError)
 		accounts.GET("/:id/usage", h.Admin.Account.GetUsage)
 		accounts.GET("/:id/today-stats", h.Admin.Account.GetTodayStats)
 		accounts.POST("/today-stats/batch", h.Admin.Account.GetBatchTodayStats)
-		accounts.POST("/:id/clear-rate-limit", h.Admin.Account.ClearRateLimit)
-		accounts.POST("/:id/reset-quota", h.Admin.Account.ResetQuota)
+		accounts.POST("/:id/clear-rate-limit", accountsWrite, h.Admin.Account.ClearRateLimit)
+		accounts.POST("/:id/reset-quota", accountsWrite, h.Admin.Account.ResetQuota)
 		accounts.GET("/:id/temp-unschedulable", h.Admin.Account.GetTempUnschedulable)
-		accounts.DELETE("/:id/temp-unschedulable", h.Admin.Account.ClearTempUnschedulable)
-		accounts.POST("/:id/schedulable", h.Admin.Account.SetSchedulable)
+		accounts.DELETE("/:id/temp-unschedulable", accountsWrite, h.Admin.Account.ClearTempUnschedulable)
+		accounts.POST("/:id/schedulable", accountsWrite, h.Admin.Account.SetSchedulable)
 		accounts.GET("/:id/models", h.Admin.Account.GetAvailableModels)
-		accounts.POST("/batch", h.Admin.Account.BatchCreate)
-		accounts.GET("/data", h.Admin.Account.ExportData)
-		accounts.POST("/data", h.Admin.Account.ImportData)
-		accounts.POST("/batch-update-credentials", h.Admin.Account.BatchUpdateCredentials)
-		accounts.POST("/batch-refresh-tier", h.Admin.Account.BatchRefreshTier)
-		accounts.POST("/bulk-update", h.Admin.Account.BulkUpdate)
+		accounts.POST("/batch", accountsWrite, h.Admin.Account.BatchCreate)
+		// 导出数据包含账号凭证，需要写权限
+		accounts.GET("/data", accountsWrite, h.Admin.Account.ExportData)
+		accounts.POST("/data", accountsWrite, h.Admin.Account.ImportData)
+		accounts.POST("/batch-update-credentials", accountsWrite, h.Admin.Account.BatchUpdateCredentials)
+		accounts.POST("/batch-refresh-tier", accountsWrite, h.Admin.Account.BatchRefreshTier)
+		accounts.POST("/bulk-update", accountsWrite, h.Admin.Account.BulkUpdate)
 
 		// Antigravity 默认模型映射
 		accounts.GET("/antigravity/default-model-mapping", h.Admin.Account.GetAntigravityDefaultModelMapping)
 
 		// Claude OAuth routes
-		accounts.POST("/generate-auth-url", h.Admin.OAuth.GenerateAuthURL)
-		accounts.POST("/generate-setup-token-url", h.Admin.OAuth.GenerateSetupTokenURL)
-		accounts.POST("/exchange-code", h.Admin.OAuth.ExchangeCode)
-		accounts.POST("/exchange-setup-token-code", h.Admin.OAuth.ExchangeSetupTokenCode)
-		accounts.POST("/cookie-auth", h.Admin.OAuth.CookieAuth)
-		accounts.POST("/setup-token-cookie-auth", h.Admin.OAuth.SetupTokenCookieAuth)
+		accounts.POST("/generate-auth-url", accountsWrite, h.Admin.OAuth.GenerateAuthURL)
+		accounts.POST("/generate-setup-token-url", accountsWrite, h.Admin.OAuth.GenerateSetupTokenURL)
+		accounts.POST("/exchange-code", accountsWrite, h.Admin.OAuth.ExchangeCode)
+		accounts.POST("/exchange-setup-token-code", accountsWrite, h.Admin.OAuth.ExchangeSetupTokenCode)
+		accounts.POST("/cookie-auth", accountsWrite, h.Admin.OAuth.CookieAuth)
+		accounts.POST("/setup-token-cookie-auth", accountsWrite, h.Admin.OAuth.SetupTokenCookieAuth)
 	}
 }
 
 func registerAnnouncementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	announcements := admin.Group("/announcements")
+	announcements := admin.Group("/announcements", perm(service.AdminPermAnnouncementsRead))
+	announcementsWrite := perm(service.AdminPermAnnouncementsWrite)
 	{
 		announcements.GET("", h.Admin.Announcement.List)
-		announcements.POST("", h.Admin.Announcement.Create)
+		announcements.POST("", announcementsWrite, h.Admin.Announcement.Create)
 		announcements.GET("/:id", h.Admin.Announcement.GetByID)
-		announcements.PUT("/:id", h.Admin.Announcement.Update)
-		announcements.DELETE("/:id", h.Admin.Announcement.Delete)
+		announcements.PUT("/:id", announcementsWrite, h.Admin.Announcement.Update)
+		announcements.DELETE("/:id", announcementsWrite, h.Admin.Announcement.Delete)
 		announcements.GET("/:id/read-status", h.Admin.Announcement.ListReadStatus)
 	}
 }
 
 func registerOpenAIOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	openai := admin.Group("/openai")
+	openai := admin.Group("/openai", perm(service.AdminPermAccountsWrite))
 	{
 		openai.POST("/generate-auth-url", h.Admin.OpenAIOAuth.GenerateAuthURL)
 		openai.POST("/exchange-code", h.Admin.OpenAIOAuth.ExchangeCode)
@@ -301,7 +351,7 @@ func registerOpenAIOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 }
 
 func registerSoraOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	sora := admin.Group("/sora")
+	sora := admin.Group("/sora", perm(service.AdminPermAccountsWrite))
 	{
 		sora.POST("/generate-auth-url", h.Admin.OpenAIOAuth.GenerateAuthURL)
 		sora.POST("/exchange-code", h.Admin.OpenAIOAuth.ExchangeCode)
@@ -314,7 +364,7 @@ func registerSoraOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 }
 
 func registerGeminiOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	gemini := admin.Group("/gemini")
+	gemini := admin.Group("/gemini", perm(service.AdminPermAccountsWrite))
 	{
 		gemini.POST("/oauth/auth-url", h.Admin.GeminiOAuth.GenerateAuthURL)
 		gemini.POST("/oauth/exchange-code", h.Admin.GeminiOAuth.ExchangeCode)
@@ -323,7 +373,7 @@ func registerGeminiOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 }
 
 func registerAntigravityOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	antigravity := admin.Group("/antigravity")
+	antigravity := admin.Group("/antigravity", perm(service.AdminPermAccountsWrite))
 	{
 		antigravity.POST("/oauth/auth-url", h.Admin.AntigravityOAuth.GenerateAuthURL)
 		antigravity.POST("/oauth/exchange-code", h.Admin.AntigravityOAuth.ExchangeCode)
@@ -332,83 +382,100 @@ func registerAntigravityOAuthRoutes(admin *gin.RouterGroup, h *handler.Handlers)
 }
 
 func registerProxyRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	proxies := admin.Group("/proxies")
+	proxies := admin.Group("/proxies", perm(service.AdminPermProxiesRead))
+	proxiesWrite := perm(service.AdminPermProxiesWrite)
 	{
 		proxies.GET("", h.Admin.Proxy.List)
 		proxies.GET("/all", h.Admin.Proxy.GetAll)
-		proxies.GET("/data", h.Admin.Proxy.ExportData)
-		proxies.POST("/data", h.Admin.Proxy.ImportData)
+		proxies.GET("/data", proxiesWrite, h.Admin.Proxy.ExportData)
+		proxies.POST("/data", proxiesWrite, h.Admin.Proxy.ImportData)
 		proxies.GET("/:id", h.Admin.Proxy.GetByID)
-		proxies.POST("", h.Admin.Proxy.Create)
-		proxies.PUT("/:id", h.Admin.Proxy.Update)
-		proxies.DELETE("/:id", h.Admin.Proxy.Delete)
-		proxies.POST("/:id/test", h.Admin.Proxy.Test)
-		proxies.POST("/:id/quality-check", h.Admin.Proxy.CheckQuality)
+		proxies.POST("", proxiesWrite, h.Admin.Proxy.Create)
+		proxies.PUT("/:id", proxiesWrite, h.Admin.Proxy.Update)
+		proxies.DELETE("/:id", proxiesWrite, h.Admin.Proxy.Delete)
+		proxies.POST("/:id/test", proxiesWrite, h.Admin.Proxy.Test)
+		proxies.POST("/:id/quality-check", proxiesWrite, h.Admin.Proxy.CheckQuality)
 		proxies.GET("/:id/stats", h.Admin.Proxy.GetStats)
 		proxies.GET("/:id/accounts", h.Admin.Proxy.GetProxyAccounts)
-		proxies.POST("/batch-delete", h.Admin.Proxy.BatchDelete)
-		proxies.POST("/batch", h.Admin.Proxy.BatchCreate)
+		proxies.POST("/batch-delete", proxiesWrite, h.Admin.Proxy.BatchDelete)
+		proxies.POST("/batch", proxiesWrite, h.Admin.Proxy.BatchCreate)
 	}
 }
 
 func registerRedeemCodeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	codes := admin.Group("/redeem-codes")
+	codes := admin.Group("/redeem-codes", perm(service.AdminPermRedeemRead))
+	redeemWrite := perm(service.AdminPermRedeemWrite)
 	{
 		codes.GET("", h.Admin.Redeem.List)
 		codes.GET("/stats", h.Admin.Redeem.GetStats)
 		codes.GET("/export", h.Admin.Redeem.Export)
 		codes.GET("/:id", h.Admin.Redeem.GetByID)
-		codes.POST("/create-and-redeem", h.Admin.Redeem.CreateAndRedeem)
-		codes.POST("/generate", h.Admin.Redeem.Generate)
-		codes.DELETE("/:id", h.Admin.Redeem.Delete)
-		codes.POST("/batch-delete", h.Admin.Redeem.BatchDelete)
-		codes.POST("/:id/expire", h.Admin.Redeem.Expire)
+		codes.POST("/create-and-redeem", redeemWrite, h.Admin.Redeem.CreateAndRedeem)
+		codes.POST("/generate", redeemWrite, h.Admin.Redeem.Generate)
+		codes.DELETE("/:id", redeemWrite, h.Admin.Redeem.Delete)
+		codes.POST("/batch-delete", redeemWrite, h.Admin.Redeem.BatchDelete)
+		codes.POST("/:id/expire", redeemWrite, h.Admin.Redeem.Expire)
 	}
 }
 
 func registerPromoCodeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	promoCodes := admin.Group("/promo-codes")
+	promoCodes := admin.Group("/promo-codes", perm(service.AdminPermPromoRead))
+	promoWrite := perm(service.AdminPermPromoWrite)
 	{
 		promoCodes.GET("", h.Admin.Promo.List)
 		promoCodes.GET("/:id", h.Admin.Promo.GetByID)
-		promoCodes.POST("", h.Admin.Promo.Create)
-		promoCodes.PUT("/:id", h.Admin.Promo.Update)
-		promoCodes.DELETE("/:id", h.Admin.Promo.Delete)
+		promoCodes.POST("", promoWrite, h.Admin.Promo.Create)
+		promoCodes.PUT("/:id", promoWrite, h.Admin.Promo.Update)
+		promoCodes.DELETE("/:id", promoWrite, h.Admin.Promo.Delete)
 		promoCodes.GET("/:id/usages", h.Admin.Promo.GetUsages)
 	}
 }
 
 func registerSettingsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	adminSettings := admin.Group("/settings")
+	adminSettings := admin.Group("/settings", perm(service.AdminPermSettingsRead))
+	settingsWrite := perm(service.AdminPermSettingsWrite)
 	{
 		adminSettings.GET("", h.Admin.Setting.GetSettings)
-		adminSettings.PUT("", h.Admin.Setting.UpdateSettings)
-		adminSettings.POST("/test-smtp", h.Admin.Setting.TestSMTPConnection)
-		adminSettings.POST("/send-test-email", h.Admin.Setting.SendTestEmail)
-		// Admin API Key 管理
+		adminSettings.PUT("", settingsWrite, h.Admin.Setting.UpdateSettings)
+		adminSettings.POST("/test-smtp", settingsWrite, h.Admin.Setting.TestSMTPConnection)
+		adminSettings.POST("/send-test-email", settingsWrite, h.Admin.Setting.SendTestEmail)
+		// Admin API Key 管理（该 Key 拥有全部权限，生成/删除需要角色管理权限）
 		adminSettings.GET("/admin-api-key", h.Admin.Setting.GetAdminAPIKey)
-		adminSettings.POST("/admin-api-key/regenerate", h.Admin.Setting.RegenerateAdminAPIKey)
-		adminSettings.DELETE("/admin-api-key", h.Admin.Setting.DeleteAdminAPIKey)
+		adminSettings.POST("/admin-api-key/regenerate", settingsWrite, perm(service.AdminPermRolesManage), h.Admin.Setting.RegenerateAdminAPIKey)
+		adminSettings.DELETE("/admin-api-key", settingsWrite, perm(service.AdminPermRolesManage), h.Admin.Setting.DeleteAdminAPIKey)
+		// 命名、限定范围的管理员 API Key
+		adminKeys := adminSettings.Group("/admin-api-keys", settingsWrite, perm(service.AdminPermRolesManage))
+		{
+			adminKeys.GET("", h.Admin.AdminKey.List)
+			adminKeys.POST("", h.Admin.AdminKey.Create)
+			adminKeys.PUT("/:id", h.Admin.AdminKey.Update)
+			adminKeys.POST("/:id/rotate", h.Admin.AdminKey.Rotate)
+			adminKeys.POST("/:id/revoke", h.Admin.AdminKey.Revoke)
+		}
 		// 流超时处理配置
 		adminSettings.GET("/stream-timeout", h.Admin.Setting.GetStreamTimeoutSettings)
-		adminSettings.PUT("/stream-timeout", h.Admin.Setting.UpdateStreamTimeoutSettings)
+		adminSettings.PUT("/stream-timeout", settingsWrite, h.Admin.Setting.UpdateStreamTimeoutSettings)
 		// 请求整流器配置
 		adminSettings.GET("/rectifier", h.Admin.Setting.GetRectifierSettings)
-		adminSettings.PUT("/rectifier", h.Admin.Setting.UpdateRectifierSettings)
+		adminSettings.PUT("/rectifier", settingsWrite, h.Admin.Setting.UpdateRectifierSettings)
 		// Sora S3 存储配置
 		adminSettings.GET("/sora-s3", h.Admin.Setting.GetSoraS3Settings)
-		adminSettings.PUT("/sora-s3", h.Admin.Setting.UpdateSoraS3Settings)
-		adminSettings.POST("/sora-s3/test", h.Admin.Setting.TestSoraS3Connection)
+		adminSettings.PUT("/sora-s3", settingsWrite, h.Admin.Setting.UpdateSoraS3Settings)
+		adminSettings.POST("/sora-s3/test", settingsWrite, h.Admin.Setting.TestSoraS3Connection)
 		adminSettings.GET("/sora-s3/profiles", h.Admin.Setting.ListSoraS3Profiles)
-		adminSettings.POST("/sora-s3/profiles", h.Admin.Setting.CreateSoraS3Profile)
-		adminSettings.PUT("/sora-s3/profiles/:profile_id", h.Admin.Setting.UpdateSoraS3Profile)
-		adminSettings.DELETE("/sora-s3/profiles/:profile_id", h.Admin.Setting.DeleteSoraS3Profile)
-		adminSettings.POST("/sora-s3/profiles/:profile_id/activate", h.Admin.Setting.SetActiveSoraS3Profile)
+		adminSettings.POST("/sora-s3/profiles", settingsWrite, h.Admin.Setting.CreateSoraS3Profile)
+		adminSettings.PUT("/sora-s3/profiles/:profile_id", settingsWrite, h.Admin.Setting.UpdateSoraS3Profile)
+		adminSettings.DELETE("/sora-s3/profiles/:profile_id", settingsWrite, h.Admin.Setting.DeleteSoraS3Profile)
+		adminSettings.POST("/sora-s3/profiles/:profile_id/activate", settingsWrite, h.Admin.Setting.SetActiveSoraS3Profile)
+		// OIDC 登录提供方
+		adminSettings.GET("/oidc-providers", h.Admin.Setting.ListOIDCProviders)
+		adminSettings.PUT("/oidc-providers/:key", settingsWrite, h.Admin.Setting.UpsertOIDCProvider)
+		adminSettings.DELETE("/oidc-providers/:key", settingsWrite, h.Admin.Setting.DeleteOIDCProvider)
 	}
 }
 
 func registerDataManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	dataManagement := admin.Group("/data-management")
+	dataManagement := admin.Group("/data-management", perm(service.AdminPermDataManage))
 	{
 		dataManagement.GET("/agent/health", h.Admin.DataManagement.GetAgentHealth)
 		dataManagement.GET("/config", h.Admin.DataManagement.GetConfig)
@@ -431,79 +498,125 @@ func registerDataManagementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 }
 
 func registerSystemRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	system := admin.Group("/system")
+	system := admin.Group("/system", perm(service.AdminPermSystemRead))
+	systemManage := perm(service.AdminPermSystemManage)
 	{
 		system.GET("/version", h.Admin.System.GetVersion)
 		system.GET("/check-updates", h.Admin.System.CheckUpdates)
-		system.POST("/update", h.Admin.System.PerformUpdate)
-		system.POST("/rollback", h.Admin.System.Rollback)
-		system.POST("/restart", h.Admin.System.RestartService)
+		system.POST("/update", systemManage, h.Admin.System.PerformUpdate)
+		system.POST("/rollback", systemManage, h.Admin.System.Rollback)
+		system.POST("/restart", systemManage, h.Admin.System.RestartService)
+		system.GET("/partitions", h.Admin.Partition.List)
+		system.POST("/partitions/:table/convert", systemManage, h.Admin.Partition.Convert)
 	}
 }
 
 func registerSubscriptionRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	subscriptions := admin.Group("/subscriptions")
+	subscriptions := admin.Group("/subscriptions", perm(service.AdminPermSubscriptionsRead))
+	subscriptionsWrite := perm(service.AdminPermSubscriptionsWrite)
 	{
 		subscriptions.GET("", h.Admin.Subscription.List)
 		subscriptions.GET("/:id", h.Admin.Subscription.GetByID)
 		subscriptions.GET("/:id/progress", h.Admin.Subscription.GetProgress)
-		subscriptions.POST("/assign", h.Admin.Subscription.Assign)
-		subscriptions.POST("/bulk-assign", h.Admin.Subscription.BulkAssign)
-		subscriptions.POST("/:id/extend", h.Admin.Subscription.Extend)
-		subscriptions.DELETE("/:id", h.Admin.Subscription.Revoke)
+		subscriptions.POST("/assign", subscriptionsWrite, h.Admin.Subscription.Assign)
+		subscriptions.POST("/bulk-assign", subscriptionsWrite, h.Admin.Subscription.BulkAssign)
+		subscriptions.POST("/:id/extend", subscriptionsWrite, h.Admin.Subscription.Extend)
+		subscriptions.DELETE("/:id", subscriptionsWrite, h.Admin.Subscription.Revoke)
 	}
 
 	// 分组下的订阅列表
-	admin.GET("/groups/:id/subscriptions", h.Admin.Subscription.ListByGroup)
+	admin.GET("/groups/:id/subscriptions", perm(service.AdminPermSubscriptionsRead), h.Admin.Subscription.ListByGroup)
 
 	// 用户下的订阅列表
-	admin.GET("/users/:id/subscriptions", h.Admin.Subscription.ListByUser)
+	admin.GET("/users/:id/subscriptions", perm(service.AdminPermSubscriptionsRead), h.Admin.Subscription.ListByUser)
 }
 
 func registerUsageRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	usage := admin.Group("/usage")
+	usage := admin.Group("/usage", perm(service.AdminPermUsageRead))
+	usageWrite := perm(service.AdminPermUsageWrite)
 	{
 		usage.GET("", h.Admin.Usage.List)
 		usage.GET("/stats", h.Admin.Usage.Stats)
 		usage.GET("/search-users", h.Admin.Usage.SearchUsers)
 		usage.GET("/search-api-keys", h.Admin.Usage.SearchAPIKeys)
 		usage.GET("/cleanup-tasks", h.Admin.Usage.ListCleanupTasks)
-		usage.POST("/cleanup-tasks", h.Admin.Usage.CreateCleanupTask)
-		usage.POST("/cleanup-tasks/:id/cancel", h.Admin.Usage.CancelCleanupTask)
+		usage.POST("/cleanup-tasks", usageWrite, h.Admin.Usage.CreateCleanupTask)
+		usage.POST("/cleanup-tasks/:id/cancel", usageWrite, h.Admin.Usage.CancelCleanupTask)
+		usage.GET("/archives", h.Admin.UsageArchive.List)
+		usage.GET("/archives/logs", h.Admin.UsageArchive.ListLogs)
+		usage.GET("/archives/statement", h.Admin.UsageArchive.Statement)
+		usage.POST("/archives/:id/rehydrate", usageWrite, h.Admin.UsageArchive.Rehydrate)
+		usage.DELETE("/archives/:id/rehydrate", usageWrite, h.Admin.UsageArchive.EvictRehydration)
 	}
 }
 
 func registerUserAttributeRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	attrs := admin.Group("/user-attributes")
+	attrs := admin.Group("/user-attributes", perm(service.AdminPermUsersRead))
+	attrsWrite := perm(service.AdminPermUsersWrite)
 	{
 		attrs.GET("", h.Admin.UserAttribute.ListDefinitions)
-		attrs.POST("", h.Admin.UserAttribute.CreateDefinition)
+		attrs.POST("", attrsWrite, h.Admin.UserAttribute.CreateDefinition)
 		attrs.POST("/batch", h.Admin.UserAttribute.GetBatchUserAttributes)
-		attrs.PUT("/reorder", h.Admin.UserAttribute.ReorderDefinitions)
-		attrs.PUT("/:id", h.Admin.UserAttribute.UpdateDefinition)
-		attrs.DELETE("/:id", h.Admin.UserAttribute.DeleteDefinition)
+		attrs.PUT("/reorder", attrsWrite, h.Admin.UserAttribute.ReorderDefinitions)
+		attrs.PUT("/:id", attrsWrite, h.Admin.UserAttribute.UpdateDefinition)
+		attrs.DELETE("/:id", attrsWrite, h.Admin.UserAttribute.DeleteDefinition)
 	}
 }
 
 func registerScheduledTestRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	plans := admin.Group("/scheduled-test-plans")
+	plans := admin.Group("/scheduled-test-plans", perm(service.AdminPermAccountsRead))
+	plansWrite := perm(service.AdminPermAccountsWrite)
 	{
-		plans.POST("", h.Admin.ScheduledTest.Create)
-		plans.PUT("/:id", h.Admin.ScheduledTest.Update)
-		plans.DELETE("/:id", h.Admin.ScheduledTest.Delete)
+		plans.POST("", plansWrite, h.Admin.ScheduledTest.Create)
+		plans.PUT("/:id", plansWrite, h.Admin.ScheduledTest.Update)
+		plans.DELETE("/:id", plansWrite, h.Admin.ScheduledTest.Delete)
 		plans.GET("/:id/results", h.Admin.ScheduledTest.ListResults)
 	}
 	// Nested under accounts
-	admin.GET("/accounts/:id/scheduled-test-plans", h.Admin.ScheduledTest.ListByAccount)
+	admin.GET("/accounts/:id/scheduled-test-plans", perm(service.AdminPermAccountsRead), h.Admin.ScheduledTest.ListByAccount)
 }
 
 func registerErrorPassthroughRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
-	rules := admin.Group("/error-passthrough-rules")
+	rules := admin.Group("/error-passthrough-rules", perm(service.AdminPermOpsRead))
+	rulesWrite := perm(service.AdminPermOpsWrite)
 	{
 		rules.GET("", h.Admin.ErrorPassthrough.List)
 		rules.GET("/:id", h.Admin.ErrorPassthrough.GetByID)
-		rules.POST("", h.Admin.ErrorPassthrough.Create)
-		rules.PUT("/:id", h.Admin.ErrorPassthrough.Update)
-		rules.DELETE("/:id", h.Admin.ErrorPassthrough.Delete)
+		rules.POST("", rulesWrite, h.Admin.ErrorPassthrough.Create)
+		rules.PUT("/:id", rulesWrite, h.Admin.ErrorPassthrough.Update)
+		rules.DELETE("/:id", rulesWrite, h.Admin.ErrorPassthrough.Delete)
+	}
+}
+
+func registerExperimentRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	experiments := admin.Group("/experiments", perm(service.AdminPermGroupsRead))
+	experimentsWrite := perm(service.AdminPermGroupsWrite)
+	{
+		experiments.GET("", h.Admin.Experiment.List)
+		experiments.GET("/:id", h.Admin.Experiment.GetByID)
+		experiments.GET("/:id/results", h.Admin.Experiment.GetResults)
+		experiments.POST("", experimentsWrite, h.Admin.Experiment.Create)
+		experiments.PUT("/:id", experimentsWrite, h.Admin.Experiment.Update)
+		experiments.DELETE("/:id", experimentsWrite, h.Admin.Experiment.Delete)
+	}
+}
+
+func registerRBACRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	rbac := admin.Group("/rbac")
+	{
+		// 当前登录者的有效权限（供前端隐藏无权限操作），任何后台成员均可访问
+		rbac.GET("/me", h.Admin.RBAC.GetMyAccess)
+		rbac.GET("/permissions", h.Admin.RBAC.ListPermissions)
+
+		manage := rbac.Group("", perm(service.AdminPermRolesManage))
+		{
+			manage.GET("/roles", h.Admin.RBAC.ListRoles)
+			manage.POST("/roles", h.Admin.RBAC.CreateRole)
+			manage.PUT("/roles/:key", h.Admin.RBAC.UpdateRole)
+			manage.DELETE("/roles/:key", h.Admin.RBAC.DeleteRole)
+			manage.GET("/assignments", h.Admin.RBAC.ListAssignments)
+			manage.PUT("/assignments/:user_id", h.Admin.RBAC.AssignRole)
+			manage.DELETE("/assignments/:user_id", h.Admin.RBAC.UnassignRole)
+		}
 	}
 }
@@ -34,6 +34,16 @@ func RegisterAuthRoutes(
 		auth.POST("/login/2fa", rateLimiter.LimitWithOptions("auth-login-2fa", 20, time.Minute, middleware.RateLimitOptions{
 			FailureMode: middleware.RateLimitFailClose,
 		}), h.Auth.Login2FA)
+		auth.POST("/login/2fa/passkey/options", rateLimiter.LimitWithOptions("auth-login-2fa-passkey", 20, time.Minute, middleware.RateLimitOptions{
+			FailureMode: middleware.RateLimitFailClose,
+		}), h.Auth.Login2FAPasskeyOptions)
+		// Passkey 无密码登录
+		auth.POST("/passkey/login/options", rateLimiter.LimitWithOptions("auth-passkey-options", 20, time.Minute, middleware.RateLimitOptions{
+			FailureMode: middleware.RateLimitFailClose,
+		}), h.Auth.PasskeyLoginOptions)
+		auth.POST("/passkey/login", rateLimiter.LimitWithOptions("auth-passkey-login", 20, time.Minute, middleware.RateLimitOptions{
+			FailureMode: middleware.RateLimitFailClose,
+		}), h.Auth.PasskeyLogin)
 		auth.POST("/send-verify-code", rateLimiter.LimitWithOptions("auth-send-verify-code", 5, time.Minute, middleware.RateLimitOptions{
 			FailureMode: middleware.RateLimitFailClose,
 		}), h.Auth.SendVerifyCode)
@@ -61,6 +71,8 @@ func RegisterAuthRoutes(
 		}), h.Auth.ResetPassword)
 		auth.GET("/oauth/linuxdo/start", h.Auth.LinuxDoOAuthStart)
 		auth.GET("/oauth/linuxdo/callback", h.Auth.LinuxDoOAuthCallback)
+		auth.GET("/oauth/oidc/:provider/start", h.OIDC.Start)
+		auth.GET("/oauth/oidc/:provider/callback", h.OIDC.Callback)
 	}
 
 	// 公开设置（无需认证）
@@ -76,5 +88,7 @@ func RegisterAuthRoutes(
 		authenticated.GET("/auth/me", h.Auth.GetCurrentUser)
 		// 撤销所有会话（需要认证）
 		authenticated.POST("/auth/revoke-all-sessions", h.Auth.RevokeAllSessions)
+		// 结束管理员模拟登录
+		authenticated.POST("/auth/impersonation/end", h.Auth.EndImpersonation)
 	}
 }
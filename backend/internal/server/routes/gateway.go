@@ -51,6 +51,8 @@ func RegisterGatewayRoutes(
 			}
 			h.Gateway.Messages(c)
 		})
+		// /v1/messages/resume: 流式断线续传（Last-Event-ID 请求头或 last_event_id 参数）
+		gateway.GET("/messages/resume", h.Gateway.ResumeStream)
 		// /v1/messages/count_tokens: OpenAI groups get 404
 		gateway.POST("/messages/count_tokens", func(c *gin.Context) {
 			if getGroupPlatform(c) == service.PlatformOpenAI {
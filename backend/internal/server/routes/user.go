@@ -33,6 +33,25 @@ func RegisterUserRoutes(
 				totp.POST("/enable", h.Totp.Enable)
 				totp.POST("/disable", h.Totp.Disable)
 			}
+
+			// WebAuthn Passkey 管理
+			passkeys := user.Group("/passkeys")
+			{
+				passkeys.GET("", h.Passkey.List)
+				passkeys.POST("/register/begin", h.Passkey.BeginRegistration)
+				passkeys.POST("/register/finish", h.Passkey.FinishRegistration)
+				passkeys.PUT("/:id", h.Passkey.Rename)
+				passkeys.POST("/:id/delete", h.Passkey.Delete)
+			}
+
+			// 登录会话（设备）管理
+			user.GET("/sessions", h.Auth.ListSessions)
+			user.DELETE("/sessions/:id", h.Auth.RevokeSession)
+
+			// 外部身份（OIDC）绑定
+			user.GET("/identities", h.OIDC.ListIdentities)
+			user.DELETE("/identities/:provider", h.OIDC.Unlink)
+			user.POST("/oidc/:provider/link", h.OIDC.Link)
 		}
 
 		// API Key管理
@@ -43,6 +62,7 @@ func RegisterUserRoutes(
 			keys.POST("", h.APIKey.Create)
 			keys.PUT("/:id", h.APIKey.Update)
 			keys.DELETE("/:id", h.APIKey.Delete)
+			keys.POST("/:id/rotate", h.APIKey.Rotate)
 		}
 
 		// 用户可用分组（非管理员接口）
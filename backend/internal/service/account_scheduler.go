@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// 各网关（Anthropic / Gemini / OpenAI）共用的调度决策、指标与账号运行时统计。
+const (
+	accountScheduleLayerSessionSticky = "session_hash"
+	accountScheduleLayerModelRouting  = "model_routing"
+	accountScheduleLayerLoadBalance   = "load_balance"
+)
+
+// AccountScheduleDecision 记录一次账号调度的决策过程，供日志与指标使用。
+type AccountScheduleDecision struct {
+	Layer               string
+	Strategy            string
+	StickyPreviousHit   bool
+	StickySessionHit    bool
+	CandidateCount      int
+	TopK                int
+	LatencyMs           int64
+	LoadSkew            float64
+	SelectedAccountID   int64
+	SelectedAccountType string
+}
+
+// AccountSchedulerMetricsSnapshot 调度器累计指标快照。
+type AccountSchedulerMetricsSnapshot struct {
+	SelectTotal              int64   `json:"select_total"`
+	StickyPreviousHitTotal   int64   `json:"sticky_previous_hit_total"`
+	StickySessionHitTotal    int64   `json:"sticky_session_hit_total"`
+	LoadBalanceSelectTotal   int64   `json:"load_balance_select_total"`
+	AccountSwitchTotal       int64   `json:"account_switch_total"`
+	SchedulerLatencyMsTotal  int64   `json:"scheduler_latency_ms_total"`
+	SchedulerLatencyMsAvg    float64 `json:"scheduler_latency_ms_avg"`
+	StickyHitRatio           float64 `json:"sticky_hit_ratio"`
+	AccountSwitchRate        float64 `json:"account_switch_rate"`
+	LoadSkewAvg              float64 `json:"load_skew_avg"`
+	RuntimeStatsAccountCount int     `json:"runtime_stats_account_count"`
+}
+
+// OpsAccountSchedulerMetrics 各网关的调度指标快照（进程内累计）。
+// Gateway 覆盖 Anthropic / Gemini / Antigravity 分组，OpenAI 为独立调度器。
+type OpsAccountSchedulerMetrics struct {
+	Gateway AccountSchedulerMetricsSnapshot `json:"gateway"`
+	OpenAI  AccountSchedulerMetricsSnapshot `json:"openai"`
+}
+
+type accountSchedulerMetrics struct {
+	selectTotal            atomic.Int64
+	stickyPreviousHitTotal atomic.Int64
+	stickySessionHitTotal  atomic.Int64
+	loadBalanceSelectTotal atomic.Int64
+	accountSwitchTotal     atomic.Int64
+	latencyMsTotal         atomic.Int64
+	loadSkewMilliTotal     atomic.Int64
+}
+
+func (m *accountSchedulerMetrics) recordSelect(decision AccountScheduleDecision) {
+	if m == nil {
+		return
+	}
+	m.selectTotal.Add(1)
+	m.latencyMsTotal.Add(decision.LatencyMs)
+	m.loadSkewMilliTotal.Add(int64(math.Round(decision.LoadSkew * 1000)))
+	if decision.StickyPreviousHit {
+		m.stickyPreviousHitTotal.Add(1)
+	}
+	if decision.StickySessionHit {
+		m.stickySessionHitTotal.Add(1)
+	}
+	if decision.Layer == accountScheduleLayerLoadBalance {
+		m.loadBalanceSelectTotal.Add(1)
+	}
+}
+
+func (m *accountSchedulerMetrics) recordSwitch() {
+	if m == nil {
+		return
+	}
+	m.accountSwitchTotal.Add(1)
+}
+
+func (m *accountSchedulerMetrics) snapshot(runtimeStatsAccountCount int) AccountSchedulerMetricsSnapshot {
+	if m == nil {
+		return AccountSchedulerMetricsSnapshot{}
+	}
+
+	selectTotal := m.selectTotal.Load()
+	prevHit := m.stickyPreviousHitTotal.Load()
+	sessionHit := m.stickySessionHitTotal.Load()
+	switchTotal := m.accountSwitchTotal.Load()
+	latencyTotal := m.latencyMsTotal.Load()
+	loadSkewTotal := m.loadSkewMilliTotal.Load()
+
+	snapshot := AccountSchedulerMetricsSnapshot{
+		SelectTotal:              selectTotal,
+		StickyPreviousHitTotal:   prevHit,
+		StickySessionHitTotal:    sessionHit,
+		LoadBalanceSelectTotal:   m.loadBalanceSelectTotal.Load(),
+		AccountSwitchTotal:       switchTotal,
+		SchedulerLatencyMsTotal:  latencyTotal,
+		RuntimeStatsAccountCount: runtimeStatsAccountCount,
+	}
+	if selectTotal > 0 {
+		snapshot.SchedulerLatencyMsAvg = float64(latencyTotal) / float64(selectTotal)
+		snapshot.StickyHitRatio = float64(prevHit+sessionHit) / float64(selectTotal)
+		snapshot.AccountSwitchRate = float64(switchTotal) / float64(selectTotal)
+		snapshot.LoadSkewAvg = float64(loadSkewTotal) / 1000 / float64(selectTotal)
+	}
+	return snapshot
+}
+
+// GetAccountSchedulerMetrics 返回本实例各网关的账号调度指标。
+func (s *OpsService) GetAccountSchedulerMetrics(ctx context.Context) (*OpsAccountSchedulerMetrics, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	return &OpsAccountSchedulerMetrics{
+		Gateway: s.gatewayService.SnapshotAccountSchedulerMetrics(),
+		OpenAI:  s.openAIGatewayService.SnapshotOpenAIAccountSchedulerMetrics(),
+	}, nil
+}
+
+type accountRuntimeStats struct {
+	accounts     sync.Map
+	accountCount atomic.Int64
+}
+
+type accountRuntimeStat struct {
+	errorRateEWMABits atomic.Uint64
+	ttftEWMABits      atomic.Uint64
+}
+
+func newAccountRuntimeStats() *accountRuntimeStats {
+	return &accountRuntimeStats{}
+}
+
+func (s *accountRuntimeStats) loadOrCreate(accountID int64) *accountRuntimeStat {
+	if value, ok := s.accounts.Load(accountID); ok {
+		stat, _ := value.(*accountRuntimeStat)
+		if stat != nil {
+			return stat
+		}
+	}
+
+	stat := &accountRuntimeStat{}
+	stat.ttftEWMABits.Store(math.Float64bits(math.NaN()))
+	actual, loaded := s.accounts.LoadOrStore(accountID, stat)
+	if !loaded {
+		s.accountCount.Add(1)
+		return stat
+	}
+	existing, _ := actual.(*accountRuntimeStat)
+	if existing != nil {
+		return existing
+	}
+	return stat
+}
+
+func updateEWMAAtomic(target *atomic.Uint64, sample float64, alpha float64) {
+	for {
+		oldBits := target.Load()
+		oldValue := math.Float64frombits(oldBits)
+		newValue := alpha*sample + (1-alpha)*oldValue
+		if target.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+			return
+		}
+	}
+}
+
+func (s *accountRuntimeStats) report(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil || accountID <= 0 {
+		return
+	}
+	const alpha = 0.2
+	stat := s.loadOrCreate(accountID)
+
+	errorSample := 1.0
+	if success {
+		errorSample = 0.0
+	}
+	updateEWMAAtomic(&stat.errorRateEWMABits, errorSample, alpha)
+
+	if firstTokenMs != nil && *firstTokenMs > 0 {
+		ttft := float64(*firstTokenMs)
+		ttftBits := math.Float64bits(ttft)
+		for {
+			oldBits := stat.ttftEWMABits.Load()
+			oldValue := math.Float64frombits(oldBits)
+			if math.IsNaN(oldValue) {
+				if stat.ttftEWMABits.CompareAndSwap(oldBits, ttftBits) {
+					break
+				}
+				continue
+			}
+			newValue := alpha*ttft + (1-alpha)*oldValue
+			if stat.ttftEWMABits.CompareAndSwap(oldBits, math.Float64bits(newValue)) {
+				break
+			}
+		}
+	}
+}
+
+func (s *accountRuntimeStats) snapshot(accountID int64) (errorRate float64, ttft float64, hasTTFT bool) {
+	if s == nil || accountID <= 0 {
+		return 0, 0, false
+	}
+	value, ok := s.accounts.Load(accountID)
+	if !ok {
+		return 0, 0, false
+	}
+	stat, _ := value.(*accountRuntimeStat)
+	if stat == nil {
+		return 0, 0, false
+	}
+	errorRate = clamp01(math.Float64frombits(stat.errorRateEWMABits.Load()))
+	ttftValue := math.Float64frombits(stat.ttftEWMABits.Load())
+	if math.IsNaN(ttftValue) {
+		return errorRate, 0, false
+	}
+	return errorRate, ttftValue, true
+}
+
+func (s *accountRuntimeStats) size() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.accountCount.Load())
+}
+
+type accountSelectionRNG struct {
+	state uint64
+}
+
+func newAccountSelectionRNG(seed uint64) accountSelectionRNG {
+	if seed == 0 {
+		seed = 0x9e3779b97f4a7c15
+	}
+	return accountSelectionRNG{state: seed}
+}
+
+func (r *accountSelectionRNG) nextUint64() uint64 {
+	// xorshift64*
+	x := r.state
+	x ^= x >> 12
+	x ^= x << 25
+	x ^= x >> 27
+	r.state = x
+	return x * 2685821657736338717
+}
+
+func (r *accountSelectionRNG) nextFloat64() float64 {
+	// [0,1)
+	return float64(r.nextUint64()>>11) / (1 << 53)
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// AccountSchedulingStrategy 分组级账号调度策略。
+// 空字符串表示使用网关默认策略（Anthropic/Gemini 为优先级 + LRU，OpenAI 为综合评分 top-K）。
+type AccountSchedulingStrategy string
+
+const (
+	AccountSchedulingStrategyDefault            AccountSchedulingStrategy = ""
+	AccountSchedulingStrategyPriorityLRU        AccountSchedulingStrategy = "priority_lru"
+	AccountSchedulingStrategyWeightedRoundRobin AccountSchedulingStrategy = "weighted_round_robin"
+	AccountSchedulingStrategyLeastLoaded        AccountSchedulingStrategy = "least_loaded"
+	AccountSchedulingStrategyLowestLatency      AccountSchedulingStrategy = "lowest_latency"
+	AccountSchedulingStrategyCheapest           AccountSchedulingStrategy = "cheapest"
+	AccountSchedulingStrategyPowerOfTwo         AccountSchedulingStrategy = "power_of_two"
+)
+
+// openAIAccountSchedulingStrategyScore 仅用于决策上报：OpenAI 网关的默认综合评分策略，不可由分组显式选择。
+const openAIAccountSchedulingStrategyScore = "score_top_k"
+
+var validAccountSchedulingStrategies = map[AccountSchedulingStrategy]struct{}{
+	AccountSchedulingStrategyPriorityLRU:        {},
+	AccountSchedulingStrategyWeightedRoundRobin: {},
+	AccountSchedulingStrategyLeastLoaded:        {},
+	AccountSchedulingStrategyLowestLatency:      {},
+	AccountSchedulingStrategyCheapest:           {},
+	AccountSchedulingStrategyPowerOfTwo:         {},
+}
+
+// IsValidAccountSchedulingStrategy 判断策略名是否合法（空字符串视为平台默认，合法）。
+func IsValidAccountSchedulingStrategy(strategy string) bool {
+	if strategy == "" {
+		return true
+	}
+	_, ok := validAccountSchedulingStrategies[AccountSchedulingStrategy(strategy)]
+	return ok
+}
+
+func normalizeGroupSchedulingStrategy(raw string) (string, error) {
+	strategy := strings.ToLower(strings.TrimSpace(raw))
+	if !IsValidAccountSchedulingStrategy(strategy) {
+		return "", infraerrors.BadRequest("INVALID_SCHEDULING_STRATEGY", "unsupported scheduling strategy: "+raw)
+	}
+	return strategy, nil
+}
+
+// schedulingStrategyFromContext 从鉴权中间件注入的分组上下文读取调度策略，避免在调度热路径上查库。
+func schedulingStrategyFromContext(ctx context.Context, groupID *int64) AccountSchedulingStrategy {
+	if ctx == nil || groupID == nil {
+		return AccountSchedulingStrategyDefault
+	}
+	group, ok := ctx.Value(ctxkey.Group).(*Group)
+	if !ok || !IsGroupContextValid(group) || group.ID != *groupID {
+		return AccountSchedulingStrategyDefault
+	}
+	return AccountSchedulingStrategy(group.SchedulingStrategy)
+}
+
+// isAccountWithinQuota 检查 API Key 账号是否在配额限制内，各网关共用。
+// 仅适用于配置了 quota_limit 的 apikey 类型账号
+func isAccountWithinQuota(account *Account) bool {
+	if account == nil || account.Type != AccountTypeAPIKey {
+		return true
+	}
+	return !account.IsQuotaExceeded()
+}
+
+// AccountScheduleCandidate 已通过调度过滤的候选账号及其排序依据。
+type AccountScheduleCandidate struct {
+	Account   *Account
+	LoadInfo  *AccountLoadInfo
+	ErrorRate float64
+	// TTFTMs 首 token 耗时的 EWMA（毫秒），HasTTFT 为 false 时表示尚无样本
+	TTFTMs  float64
+	HasTTFT bool
+}
+
+func (c AccountScheduleCandidate) loadRate() int {
+	if c.LoadInfo == nil {
+		return 0
+	}
+	return c.LoadInfo.LoadRate
+}
+
+func (c AccountScheduleCandidate) waitingCount() int {
+	if c.LoadInfo == nil {
+		return 0
+	}
+	return c.LoadInfo.WaitingCount
+}
+
+// AccountSchedulingPolicy 对候选账号排序，调用方按返回顺序依次尝试获取并发槽位，
+// 全部失败时再按同一顺序生成等待计划。
+type AccountSchedulingPolicy interface {
+	Strategy() AccountSchedulingStrategy
+	Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate
+}
+
+// accountSchedulingPolicyRegistry 按分组 + 策略名缓存策略实例（加权轮询需要跨请求保留状态，且各分组互不影响）。
+// 零值可直接使用。
+type accountSchedulingPolicyRegistry struct {
+	policies sync.Map
+}
+
+type accountSchedulingPolicyKey struct {
+	groupID  int64
+	strategy AccountSchedulingStrategy
+}
+
+// resolve 返回分组的策略实例；平台默认策略返回 nil，由调用方走原有调度逻辑。
+func (r *accountSchedulingPolicyRegistry) resolve(groupID *int64, strategy AccountSchedulingStrategy) AccountSchedulingPolicy {
+	if r == nil || strategy == AccountSchedulingStrategyDefault {
+		return nil
+	}
+	key := accountSchedulingPolicyKey{groupID: derefGroupID(groupID), strategy: strategy}
+	if value, ok := r.policies.Load(key); ok {
+		return value.(AccountSchedulingPolicy)
+	}
+	policy := newAccountSchedulingPolicy(strategy)
+	if policy == nil {
+		return nil
+	}
+	actual, _ := r.policies.LoadOrStore(key, policy)
+	return actual.(AccountSchedulingPolicy)
+}
+
+func newAccountSchedulingPolicy(strategy AccountSchedulingStrategy) AccountSchedulingPolicy {
+	switch strategy {
+	case AccountSchedulingStrategyPriorityLRU:
+		return priorityLRUSchedulingPolicy{}
+	case AccountSchedulingStrategyWeightedRoundRobin:
+		return &weightedRoundRobinSchedulingPolicy{current: make(map[int64]*weightedRoundRobinWeight)}
+	case AccountSchedulingStrategyLeastLoaded:
+		return leastLoadedSchedulingPolicy{}
+	case AccountSchedulingStrategyLowestLatency:
+		return lowestLatencySchedulingPolicy{}
+	case AccountSchedulingStrategyCheapest:
+		return cheapestSchedulingPolicy{}
+	case AccountSchedulingStrategyPowerOfTwo:
+		return powerOfTwoSchedulingPolicy{}
+	default:
+		return nil
+	}
+}
+
+// buildAccountScheduleCandidates 组装候选列表，stats 为 nil 时不填充错误率/TTFT。
+func buildAccountScheduleCandidates(accounts []*Account, loadMap map[int64]*AccountLoadInfo, stats *accountRuntimeStats) []AccountScheduleCandidate {
+	candidates := make([]AccountScheduleCandidate, 0, len(accounts))
+	for _, account := range accounts {
+		loadInfo := loadMap[account.ID]
+		if loadInfo == nil {
+			loadInfo = &AccountLoadInfo{AccountID: account.ID}
+		}
+		errorRate, ttft, hasTTFT := stats.snapshot(account.ID)
+		candidates = append(candidates, AccountScheduleCandidate{
+			Account:   account,
+			LoadInfo:  loadInfo,
+			ErrorRate: errorRate,
+			TTFTMs:    ttft,
+			HasTTFT:   hasTTFT,
+		})
+	}
+	return candidates
+}
+
+// deriveAccountSelectionSeed 为会话请求生成稳定种子，无会话请求引入时间熵。
+func deriveAccountSelectionSeed(groupID *int64, sessionHash string, requestedModel string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(strings.TrimSpace(sessionHash)))
+	_, _ = hasher.Write([]byte{0})
+	_, _ = hasher.Write([]byte(strings.TrimSpace(requestedModel)))
+	_, _ = hasher.Write([]byte{0})
+	seed := hasher.Sum64() ^ uint64(derefGroupID(groupID))
+	if strings.TrimSpace(sessionHash) == "" {
+		seed ^= uint64(time.Now().UnixNano())
+	}
+	return seed
+}
+
+// sortAccountScheduleCandidates 先用种子打乱再稳定排序，使同分候选之间随机分布，
+// 避免并发请求读取同一快照时全部命中同一账号。
+func sortAccountScheduleCandidates(candidates []AccountScheduleCandidate, seed uint64, less func(a, b AccountScheduleCandidate) bool) []AccountScheduleCandidate {
+	ordered := append([]AccountScheduleCandidate(nil), candidates...)
+	rng := newAccountSelectionRNG(seed)
+	for i := len(ordered) - 1; i > 0; i-- {
+		j := int(rng.nextUint64() % uint64(i+1))
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return less(ordered[i], ordered[j])
+	})
+	return ordered
+}
+
+// priorityLRUSchedulingPolicy 优先级 → 负载率 → 最久未使用。
+type priorityLRUSchedulingPolicy struct{}
+
+func (priorityLRUSchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyPriorityLRU
+}
+
+func (priorityLRUSchedulingPolicy) Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate {
+	return sortAccountScheduleCandidates(candidates, seed, func(a, b AccountScheduleCandidate) bool {
+		if a.Account.Priority != b.Account.Priority {
+			return a.Account.Priority < b.Account.Priority
+		}
+		if a.loadRate() != b.loadRate() {
+			return a.loadRate() < b.loadRate()
+		}
+		switch {
+		case a.Account.LastUsedAt == nil && b.Account.LastUsedAt != nil:
+			return true
+		case a.Account.LastUsedAt != nil && b.Account.LastUsedAt == nil:
+			return false
+		case a.Account.LastUsedAt == nil && b.Account.LastUsedAt == nil:
+			return false
+		default:
+			return a.Account.LastUsedAt.Before(*b.Account.LastUsedAt)
+		}
+	})
+}
+
+// weightedRoundRobinStateTTL 账号连续多久未出现在候选中即清除其轮询权重。
+// 故障切换排除、短暂限流等只会让账号暂时缺席，不应重置其进度，因此按时间而非单次缺席淘汰。
+const weightedRoundRobinStateTTL = 10 * time.Minute
+
+// weightedRoundRobinSchedulingPolicy 平滑加权轮询（nginx 算法），权重为账号负载因子。
+// 只有排在首位的账号计入本轮选中，其余候选按当前权重降序作为获取槽位失败时的后备。
+// 实例按分组隔离（见 accountSchedulingPolicyRegistry），已移出分组或停用的账号超过 TTL 后被淘汰。
+type weightedRoundRobinSchedulingPolicy struct {
+	mu      sync.Mutex
+	current map[int64]*weightedRoundRobinWeight
+}
+
+type weightedRoundRobinWeight struct {
+	current  int64
+	lastSeen time.Time
+}
+
+func (*weightedRoundRobinSchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyWeightedRoundRobin
+}
+
+func (p *weightedRoundRobinSchedulingPolicy) Order(candidates []AccountScheduleCandidate, _ uint64) []AccountScheduleCandidate {
+	if len(candidates) <= 1 {
+		return append([]AccountScheduleCandidate(nil), candidates...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	weights := make([]*weightedRoundRobinWeight, len(candidates))
+	var total int64
+	bestIdx := 0
+	for i, candidate := range candidates {
+		weight := int64(candidate.Account.EffectiveLoadFactor())
+		total += weight
+		state := p.current[candidate.Account.ID]
+		if state == nil {
+			state = &weightedRoundRobinWeight{}
+			p.current[candidate.Account.ID] = state
+		}
+		state.current += weight
+		state.lastSeen = now
+		weights[i] = state
+		if state.current > weights[bestIdx].current {
+			bestIdx = i
+		}
+	}
+	weights[bestIdx].current -= total
+	p.evictStale(now)
+
+	ordered := make([]AccountScheduleCandidate, 0, len(candidates))
+	ordered = append(ordered, candidates[bestIdx])
+	rest := make([]AccountScheduleCandidate, 0, len(candidates)-1)
+	rest = append(rest, candidates[:bestIdx]...)
+	rest = append(rest, candidates[bestIdx+1:]...)
+	sort.SliceStable(rest, func(i, j int) bool {
+		return p.current[rest[i].Account.ID].current > p.current[rest[j].Account.ID].current
+	})
+	return append(ordered, rest...)
+}
+
+// evictStale 清除超过 TTL 未出现在候选中的账号，调用方需持有锁。
+func (p *weightedRoundRobinSchedulingPolicy) evictStale(now time.Time) {
+	for accountID, state := range p.current {
+		if now.Sub(state.lastSeen) > weightedRoundRobinStateTTL {
+			delete(p.current, accountID)
+		}
+	}
+}
+
+// leastLoadedSchedulingPolicy 负载率 → 排队数 → 优先级。
+type leastLoadedSchedulingPolicy struct{}
+
+func (leastLoadedSchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyLeastLoaded
+}
+
+func (leastLoadedSchedulingPolicy) Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate {
+	return sortAccountScheduleCandidates(candidates, seed, lessByLoad)
+}
+
+func lessByLoad(a, b AccountScheduleCandidate) bool {
+	if a.loadRate() != b.loadRate() {
+		return a.loadRate() < b.loadRate()
+	}
+	if a.waitingCount() != b.waitingCount() {
+		return a.waitingCount() < b.waitingCount()
+	}
+	return a.Account.Priority < b.Account.Priority
+}
+
+// lowestLatencySchedulingPolicy 首 token 耗时 EWMA 最低优先。
+// 尚无样本的账号排在最前，保证新账号能尽快获得测量数据。
+type lowestLatencySchedulingPolicy struct{}
+
+func (lowestLatencySchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyLowestLatency
+}
+
+func (lowestLatencySchedulingPolicy) Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate {
+	return sortAccountScheduleCandidates(candidates, seed, func(a, b AccountScheduleCandidate) bool {
+		if a.HasTTFT != b.HasTTFT {
+			return !a.HasTTFT
+		}
+		if a.HasTTFT && a.TTFTMs != b.TTFTMs {
+			return a.TTFTMs < b.TTFTMs
+		}
+		if a.ErrorRate != b.ErrorRate {
+			return a.ErrorRate < b.ErrorRate
+		}
+		return lessByLoad(a, b)
+	})
+}
+
+// cheapestSchedulingPolicy 账号计费倍率（rate_multiplier）最低优先，同倍率按优先级和负载。
+type cheapestSchedulingPolicy struct{}
+
+func (cheapestSchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyCheapest
+}
+
+func (cheapestSchedulingPolicy) Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate {
+	return sortAccountScheduleCandidates(candidates, seed, func(a, b AccountScheduleCandidate) bool {
+		aRate, bRate := a.Account.BillingRateMultiplier(), b.Account.BillingRateMultiplier()
+		if aRate != bRate {
+			return aRate < bRate
+		}
+		if a.Account.Priority != b.Account.Priority {
+			return a.Account.Priority < b.Account.Priority
+		}
+		return lessByLoad(a, b)
+	})
+}
+
+// powerOfTwoSchedulingPolicy 随机抽取两个候选，负载较低者优先；其余按负载排序作为后备。
+// 相比全局最小负载，可避免并发请求同时涌向同一个“最空闲”账号。
+type powerOfTwoSchedulingPolicy struct{}
+
+func (powerOfTwoSchedulingPolicy) Strategy() AccountSchedulingStrategy {
+	return AccountSchedulingStrategyPowerOfTwo
+}
+
+func (powerOfTwoSchedulingPolicy) Order(candidates []AccountScheduleCandidate, seed uint64) []AccountScheduleCandidate {
+	if len(candidates) <= 1 {
+		return append([]AccountScheduleCandidate(nil), candidates...)
+	}
+
+	rng := newAccountSelectionRNG(seed)
+	first := int(rng.nextUint64() % uint64(len(candidates)))
+	second := int(rng.nextUint64() % uint64(len(candidates)-1))
+	if second >= first {
+		second++
+	}
+	if lessByLoad(candidates[second], candidates[first]) {
+		first, second = second, first
+	}
+
+	ordered := make([]AccountScheduleCandidate, 0, len(candidates))
+	ordered = append(ordered, candidates[first], candidates[second])
+	rest := make([]AccountScheduleCandidate, 0, len(candidates)-2)
+	for i := range candidates {
+		if i != first && i != second {
+			rest = append(rest, candidates[i])
+		}
+	}
+	return append(ordered, sortAccountScheduleCandidates(rest, rng.nextUint64(), lessByLoad)...)
+}
@@ -0,0 +1,163 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduleCandidate(id int64, priority int, loadRate int) AccountScheduleCandidate {
+	return AccountScheduleCandidate{
+		Account:  &Account{ID: id, Priority: priority, Concurrency: 1},
+		LoadInfo: &AccountLoadInfo{AccountID: id, LoadRate: loadRate},
+	}
+}
+
+func scheduleCandidateIDs(candidates []AccountScheduleCandidate) []int64 {
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.Account.ID)
+	}
+	return ids
+}
+
+func TestNormalizeGroupSchedulingStrategy(t *testing.T) {
+	got, err := normalizeGroupSchedulingStrategy("  Least_Loaded ")
+	require.NoError(t, err)
+	require.Equal(t, "least_loaded", got)
+
+	got, err = normalizeGroupSchedulingStrategy("")
+	require.NoError(t, err)
+	require.Equal(t, "", got)
+
+	_, err = normalizeGroupSchedulingStrategy("random")
+	require.Error(t, err)
+}
+
+func TestSchedulingStrategyFromContext(t *testing.T) {
+	groupID := int64(7)
+	group := &Group{ID: groupID, Platform: PlatformAnthropic, Status: StatusActive, Hydrated: true, SchedulingStrategy: "cheapest"}
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+
+	require.Equal(t, AccountSchedulingStrategyCheapest, schedulingStrategyFromContext(ctx, &groupID))
+
+	otherID := int64(8)
+	require.Equal(t, AccountSchedulingStrategyDefault, schedulingStrategyFromContext(ctx, &otherID))
+	require.Equal(t, AccountSchedulingStrategyDefault, schedulingStrategyFromContext(context.Background(), &groupID))
+}
+
+func TestAccountSchedulingPolicyRegistry_DefaultReturnsNil(t *testing.T) {
+	var registry accountSchedulingPolicyRegistry
+	groupID, otherID := int64(7), int64(8)
+	require.Nil(t, registry.resolve(&groupID, AccountSchedulingStrategyDefault))
+	require.Nil(t, registry.resolve(&groupID, "unknown"))
+
+	policy := registry.resolve(&groupID, AccountSchedulingStrategyWeightedRoundRobin)
+	require.NotNil(t, policy)
+	require.Same(t, policy, registry.resolve(&groupID, AccountSchedulingStrategyWeightedRoundRobin))
+	require.NotSame(t, policy, registry.resolve(&otherID, AccountSchedulingStrategyWeightedRoundRobin), "加权轮询状态按分组隔离")
+}
+
+func TestPriorityLRUSchedulingPolicy_Order(t *testing.T) {
+	candidates := []AccountScheduleCandidate{
+		newScheduleCandidate(1, 2, 0),
+		newScheduleCandidate(2, 1, 50),
+		newScheduleCandidate(3, 1, 10),
+	}
+	ordered := priorityLRUSchedulingPolicy{}.Order(candidates, 42)
+	require.Equal(t, []int64{3, 2, 1}, scheduleCandidateIDs(ordered))
+}
+
+func TestLeastLoadedSchedulingPolicy_Order(t *testing.T) {
+	candidates := []AccountScheduleCandidate{
+		newScheduleCandidate(1, 1, 80),
+		newScheduleCandidate(2, 9, 5),
+		newScheduleCandidate(3, 1, 40),
+	}
+	ordered := leastLoadedSchedulingPolicy{}.Order(candidates, 42)
+	require.Equal(t, []int64{2, 3, 1}, scheduleCandidateIDs(ordered))
+}
+
+func TestLowestLatencySchedulingPolicy_UnsampledFirst(t *testing.T) {
+	fast := newScheduleCandidate(1, 1, 0)
+	fast.TTFTMs, fast.HasTTFT = 200, true
+	slow := newScheduleCandidate(2, 1, 0)
+	slow.TTFTMs, slow.HasTTFT = 900, true
+	fresh := newScheduleCandidate(3, 1, 0)
+
+	ordered := lowestLatencySchedulingPolicy{}.Order([]AccountScheduleCandidate{slow, fast, fresh}, 42)
+	require.Equal(t, []int64{3, 1, 2}, scheduleCandidateIDs(ordered))
+}
+
+func TestCheapestSchedulingPolicy_Order(t *testing.T) {
+	cheap, pricey := 0.5, 2.0
+	a := newScheduleCandidate(1, 1, 0)
+	a.Account.RateMultiplier = &pricey
+	b := newScheduleCandidate(2, 5, 90)
+	b.Account.RateMultiplier = &cheap
+	c := newScheduleCandidate(3, 1, 0) // 未配置倍率按 1.0
+
+	ordered := cheapestSchedulingPolicy{}.Order([]AccountScheduleCandidate{a, b, c}, 42)
+	require.Equal(t, []int64{2, 3, 1}, scheduleCandidateIDs(ordered))
+}
+
+func TestWeightedRoundRobinSchedulingPolicy_Distribution(t *testing.T) {
+	policy := &weightedRoundRobinSchedulingPolicy{current: make(map[int64]*weightedRoundRobinWeight)}
+	heavy := newScheduleCandidate(1, 1, 0)
+	heavy.Account.Concurrency = 3
+	light := newScheduleCandidate(2, 1, 0)
+	candidates := []AccountScheduleCandidate{heavy, light}
+
+	counts := map[int64]int{}
+	for i := 0; i < 8; i++ {
+		ordered := policy.Order(candidates, 0)
+		require.Len(t, ordered, 2)
+		counts[ordered[0].Account.ID]++
+	}
+	require.Equal(t, 6, counts[1])
+	require.Equal(t, 2, counts[2])
+}
+
+func TestWeightedRoundRobinSchedulingPolicy_EvictsStaleAccounts(t *testing.T) {
+	policy := &weightedRoundRobinSchedulingPolicy{current: make(map[int64]*weightedRoundRobinWeight)}
+	candidates := []AccountScheduleCandidate{
+		newScheduleCandidate(1, 1, 0),
+		newScheduleCandidate(2, 1, 0),
+		newScheduleCandidate(3, 1, 0),
+	}
+	policy.Order(candidates, 0)
+	require.Len(t, policy.current, 3)
+
+	// 账号 3 短暂缺席不清除，超过 TTL 未出现则淘汰
+	policy.Order(candidates[:2], 0)
+	require.Contains(t, policy.current, int64(3))
+	policy.current[3].lastSeen = time.Now().Add(-weightedRoundRobinStateTTL - time.Second)
+	policy.Order(candidates[:2], 0)
+	require.NotContains(t, policy.current, int64(3))
+	require.Len(t, policy.current, 2)
+}
+
+func TestPowerOfTwoSchedulingPolicy_PrefersLessLoadedOfPair(t *testing.T) {
+	candidates := []AccountScheduleCandidate{
+		newScheduleCandidate(1, 1, 90),
+		newScheduleCandidate(2, 1, 10),
+		newScheduleCandidate(3, 1, 50),
+	}
+	for seed := uint64(1); seed < 50; seed++ {
+		ordered := powerOfTwoSchedulingPolicy{}.Order(candidates, seed)
+		require.Len(t, ordered, 3)
+		require.LessOrEqual(t, ordered[0].LoadInfo.LoadRate, ordered[1].LoadInfo.LoadRate)
+		// 负载最高的账号不可能成为首选
+		require.NotEqual(t, int64(1), ordered[0].Account.ID)
+	}
+}
+
+func TestIsAccountWithinQuota(t *testing.T) {
+	require.True(t, isAccountWithinQuota(&Account{Type: AccountTypeOAuth}))
+	require.True(t, isAccountWithinQuota(&Account{Type: AccountTypeAPIKey}))
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	// AdminKeyScopeAll 授予全部权限的 scope
+	AdminKeyScopeAll = "*"
+
+	adminKeyMaxScopes        = 64
+	adminKeyDisplayPrefixLen = len(AdminAPIKeyPrefix) + 6
+	// 最近使用时间的写入节流间隔，避免每个请求都写库
+	adminKeyTouchInterval = time.Minute
+	adminKeyTouchTimeout  = 3 * time.Second
+)
+
+var (
+	ErrAdminKeyInvalid      = infraerrors.Unauthorized("INVALID_ADMIN_KEY", "Invalid admin API key")
+	ErrAdminKeyExpired      = infraerrors.Unauthorized("ADMIN_KEY_EXPIRED", "admin API key has expired")
+	ErrAdminKeyIPDenied     = infraerrors.Forbidden("ADMIN_KEY_IP_DENIED", "client IP is not allowed for this admin API key")
+	ErrAdminKeyNotFound     = infraerrors.NotFound("ADMIN_KEY_NOT_FOUND", "admin API key not found")
+	ErrAdminKeyRevoked      = infraerrors.Conflict("ADMIN_KEY_REVOKED", "admin API key has been revoked")
+	ErrAdminKeyNameRequired = infraerrors.BadRequest("ADMIN_KEY_NAME_REQUIRED", "admin API key name is required")
+	ErrAdminKeyScopeInvalid = infraerrors.BadRequest("ADMIN_KEY_SCOPE_INVALID", "invalid admin API key scope")
+	ErrAdminKeyScopeEmpty   = infraerrors.BadRequest("ADMIN_KEY_SCOPE_REQUIRED", "at least one scope is required")
+	ErrAdminKeyExpiryPast   = infraerrors.BadRequest("ADMIN_KEY_EXPIRY_INVALID", "expires_at must be in the future")
+)
+
+// adminKeyRouteScopePattern 路由 scope：可选 HTTP 方法 + 相对 /api/v1/admin 的路由模板，
+// 末尾 "/*" 表示前缀匹配。例如 "POST redeem-codes/create-and-redeem"、"users/:id"、"ops/*"。
+var adminKeyRouteScopePattern = regexp.MustCompile(`^(?:(GET|POST|PUT|PATCH|DELETE) )?(:?[a-z0-9_\-]+(?:/:?[a-z0-9_\-]+)*(?:/\*)?)$`)
+
+// AdminKey 命名的管理员 API Key（明文只在创建/轮换时返回一次，库中仅保存 SHA-256）
+type AdminKey struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	KeyPrefix   string     `json:"key_prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	IPAllowlist []string   `json:"ip_allowlist"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedBy   *int64     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsRevoked 是否已吊销
+func (k *AdminKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// IsExpired 是否已过期
+func (k *AdminKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// adminKeyProtectedRoutes 路由 scope 永远不覆盖的路由：Admin API Key 与角色管理只能通过权限 scope 授予
+var adminKeyProtectedRoutes = []string{"rbac", "settings/admin-api-key", "settings/admin-api-keys"}
+
+// AccessFor 计算该 Key 对指定路由的有效权限。
+// route 为相对 /api/v1/admin 的路由模板（如 "users/:id"）。
+// 命中路由 scope 时仅放行该路由的路由级权限校验（见 AdminAccess.Allows）；其余按权限 scope 计算。
+func (k *AdminKey) AccessFor(method, route string) *AdminAccess {
+	perms := make([]string, 0, len(k.Scopes))
+	routeGranted := false
+	protected := isAdminKeyProtectedRoute(route)
+	for _, scope := range k.Scopes {
+		switch {
+		case scope == AdminKeyScopeAll:
+			return adminKeyAccess(k, AllAdminPermissions())
+		case isAdminPermissionScope(scope):
+			perms = append(perms, scope)
+		case !protected && adminKeyRouteScopePattern.MatchString(scope) && matchRouteTemplate(scope, method, route):
+			routeGranted = true
+		}
+	}
+	access := adminKeyAccess(k, perms)
+	access.routeGranted = routeGranted
+	return access
+}
+
+func adminKeyAccess(k *AdminKey, perms []string) *AdminAccess {
+	return NewAdminAccess(&AdminRole{Key: "admin_key:" + k.KeyPrefix, Name: k.Name, Permissions: perms})
+}
+
+func isAdminPermissionScope(scope string) bool {
+	_, ok := adminPermissionSet[scope]
+	return ok
+}
+
+// isAdminKeyProtectedRoute 路由是否属于 adminKeyProtectedRoutes
+func isAdminKeyProtectedRoute(route string) bool {
+	route = strings.Trim(route, "/")
+	for _, protected := range adminKeyProtectedRoutes {
+		if route == protected || strings.HasPrefix(route, protected+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminKeyInput 创建/更新参数
+type AdminKeyInput struct {
+	Name        string
+	Scopes      []string
+	IPAllowlist []string
+	ExpiresAt   *time.Time
+}
+
+// AdminKeyRepository 管理员 API Key 存储
+type AdminKeyRepository interface {
+	List(ctx context.Context) ([]AdminKey, error)
+	GetByID(ctx context.Context, id int64) (*AdminKey, error)
+	// GetByHash 不存在时返回 (nil, nil)
+	GetByHash(ctx context.Context, keyHash string) (*AdminKey, error)
+	Create(ctx context.Context, key *AdminKey) error
+	Update(ctx context.Context, key *AdminKey) error
+	UpdateSecret(ctx context.Context, id int64, keyHash, keyPrefix string) error
+	Revoke(ctx context.Context, id int64, at time.Time) error
+	TouchLastUsed(ctx context.Context, id int64, at time.Time, clientIP string) error
+}
+
+// AdminKeyService 多个命名、限定范围的管理员 API Key：
+// 每个 Key 拥有独立的 scope、IP 白名单、过期时间与最近使用记录，轮换或吊销互不影响。
+type AdminKeyService struct {
+	repo AdminKeyRepository
+	now  func() time.Time
+}
+
+// NewAdminKeyService 创建管理员 API Key 服务
+func NewAdminKeyService(repo AdminKeyRepository) *AdminKeyService {
+	return &AdminKeyService{repo: repo, now: time.Now}
+}
+
+// Authenticate 校验明文 Key、有效期与来源 IP
+func (s *AdminKeyService) Authenticate(ctx context.Context, plaintext, clientIP string) (*AdminKey, error) {
+	if !strings.HasPrefix(plaintext, AdminAPIKeyPrefix) {
+		return nil, ErrAdminKeyInvalid
+	}
+	key, err := s.repo.GetByHash(ctx, hashAdminKey(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.IsRevoked() {
+		return nil, ErrAdminKeyInvalid
+	}
+	now := s.now()
+	if key.IsExpired(now) {
+		return nil, ErrAdminKeyExpired
+	}
+	if len(key.IPAllowlist) > 0 {
+		if allowed, _ := ip.CheckIPRestriction(clientIP, key.IPAllowlist, nil); !allowed {
+			return nil, ErrAdminKeyIPDenied
+		}
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= adminKeyTouchInterval || key.LastUsedIP != clientIP {
+		go s.touch(key.ID, now, clientIP)
+	}
+	return key, nil
+}
+
+func (s *AdminKeyService) touch(id int64, at time.Time, clientIP string) {
+	ctx, cancel := context.WithTimeout(context.Background(), adminKeyTouchTimeout)
+	defer cancel()
+	if err := s.repo.TouchLastUsed(ctx, id, at, clientIP); err != nil {
+		logger.LegacyPrintf("service.admin_key", "[AdminKey] update last used failed: id=%d err=%v", id, err)
+	}
+}
+
+// List 返回全部 Key（含已吊销）
+func (s *AdminKeyService) List(ctx context.Context) ([]AdminKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Create 创建 Key，返回记录与明文（明文仅此一次可见）
+func (s *AdminKeyService) Create(ctx context.Context, input AdminKeyInput, actorID int64) (*AdminKey, string, error) {
+	key := &AdminKey{}
+	if err := s.applyInput(key, input); err != nil {
+		return nil, "", err
+	}
+	plaintext, err := newAdminKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	key.KeyHash = hashAdminKey(plaintext)
+	key.KeyPrefix = adminKeyDisplayPrefix(plaintext)
+	if actorID > 0 {
+		key.CreatedBy = &actorID
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	logger.LegacyPrintf("service.admin_key", "[AdminKey] created: id=%d name=%q scopes=%v by=%d", key.ID, key.Name, key.Scopes, actorID)
+	return key, plaintext, nil
+}
+
+// Update 修改名称、scope、IP 白名单与过期时间
+func (s *AdminKeyService) Update(ctx context.Context, id int64, input AdminKeyInput) (*AdminKey, error) {
+	key, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return nil, ErrAdminKeyRevoked
+	}
+	if err := s.applyInput(key, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Rotate 生成新的明文并立即使旧值失效，其它 Key 不受影响
+func (s *AdminKeyService) Rotate(ctx context.Context, id int64) (*AdminKey, string, error) {
+	key, err := s.get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if key.IsRevoked() {
+		return nil, "", ErrAdminKeyRevoked
+	}
+	plaintext, err := newAdminKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	key.KeyHash = hashAdminKey(plaintext)
+	key.KeyPrefix = adminKeyDisplayPrefix(plaintext)
+	if err := s.repo.UpdateSecret(ctx, key.ID, key.KeyHash, key.KeyPrefix); err != nil {
+		return nil, "", err
+	}
+	logger.LegacyPrintf("service.admin_key", "[AdminKey] rotated: id=%d name=%q", key.ID, key.Name)
+	return key, plaintext, nil
+}
+
+// Revoke 吊销 Key（保留记录便于审计）
+func (s *AdminKeyService) Revoke(ctx context.Context, id int64) error {
+	key, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.IsRevoked() {
+		return nil
+	}
+	if err := s.repo.Revoke(ctx, id, s.now()); err != nil {
+		return err
+	}
+	logger.LegacyPrintf("service.admin_key", "[AdminKey] revoked: id=%d name=%q", key.ID, key.Name)
+	return nil
+}
+
+func (s *AdminKeyService) get(ctx context.Context, id int64) (*AdminKey, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAdminKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *AdminKeyService) applyInput(key *AdminKey, input AdminKeyInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return ErrAdminKeyNameRequired
+	}
+	if len([]rune(name)) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	scopes, err := normalizeAdminKeyScopes(input.Scopes)
+	if err != nil {
+		return err
+	}
+	allowlist := make([]string, 0, len(input.IPAllowlist))
+	for _, p := range input.IPAllowlist {
+		if p = strings.TrimSpace(p); p != "" {
+			allowlist = append(allowlist, p)
+		}
+	}
+	if invalid := ip.ValidateIPPatterns(allowlist); len(invalid) > 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidIPPattern, invalid)
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return ErrAdminKeyExpiryPast
+	}
+	key.Name = name
+	key.Scopes = scopes
+	key.IPAllowlist = allowlist
+	key.ExpiresAt = input.ExpiresAt
+	return nil
+}
+
+func normalizeAdminKeyScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(scopes))
+	for _, raw := range scopes {
+		scope := strings.TrimSpace(raw)
+		if scope == "" {
+			continue
+		}
+		if scope != AdminKeyScopeAll && !isAdminPermissionScope(scope) {
+			// 路由 scope：方法大写、路径去掉前导斜杠
+			method, path, hasMethod := strings.Cut(scope, " ")
+			if hasMethod {
+				scope = strings.ToUpper(method) + " " + strings.TrimLeft(strings.TrimSpace(path), "/")
+			} else {
+				scope = strings.TrimLeft(scope, "/")
+			}
+			if !adminKeyRouteScopePattern.MatchString(scope) {
+				return nil, ErrAdminKeyScopeInvalid.WithMetadata(map[string]string{"scope": raw})
+			}
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	if len(out) == 0 {
+		return nil, ErrAdminKeyScopeEmpty
+	}
+	if len(out) > adminKeyMaxScopes {
+		return nil, ErrAdminKeyScopeInvalid.WithMetadata(map[string]string{"reason": "too many scopes"})
+	}
+	return out, nil
+}
+
+func newAdminKeySecret() (string, error) {
+	// 32 字节随机数 = 64 位十六进制字符，与全局管理员 Key 格式一致
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("generate random bytes: %w", err)
+	}
+	return AdminAPIKeyPrefix + hex.EncodeToString(bytes), nil
+}
+
+func hashAdminKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func adminKeyDisplayPrefix(plaintext string) string {
+	if len(plaintext) <= adminKeyDisplayPrefixLen {
+		return plaintext
+	}
+	return plaintext[:adminKeyDisplayPrefixLen]
+}
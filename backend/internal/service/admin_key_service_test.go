@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminKeyRepoStub struct {
+	mu     sync.Mutex
+	keys   map[int64]*AdminKey
+	nextID int64
+}
+
+func newAdminKeyRepoStub() *adminKeyRepoStub {
+	return &adminKeyRepoStub{keys: map[int64]*AdminKey{}}
+}
+
+func (r *adminKeyRepoStub) List(ctx context.Context) ([]AdminKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]AdminKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		out = append(out, *k)
+	}
+	return out, nil
+}
+
+func (r *adminKeyRepoStub) GetByID(ctx context.Context, id int64) (*AdminKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if k, ok := r.keys[id]; ok {
+		clone := *k
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (r *adminKeyRepoStub) GetByHash(ctx context.Context, keyHash string) (*AdminKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.KeyHash == keyHash {
+			clone := *k
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *adminKeyRepoStub) Create(ctx context.Context, key *AdminKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	key.ID = r.nextID
+	clone := *key
+	r.keys[key.ID] = &clone
+	return nil
+}
+
+func (r *adminKeyRepoStub) Update(ctx context.Context, key *AdminKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clone := *key
+	r.keys[key.ID] = &clone
+	return nil
+}
+
+func (r *adminKeyRepoStub) UpdateSecret(ctx context.Context, id int64, keyHash, keyPrefix string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[id].KeyHash = keyHash
+	r.keys[id].KeyPrefix = keyPrefix
+	return nil
+}
+
+func (r *adminKeyRepoStub) Revoke(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[id].RevokedAt = &at
+	return nil
+}
+
+func (r *adminKeyRepoStub) TouchLastUsed(ctx context.Context, id int64, at time.Time, clientIP string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[id].LastUsedAt = &at
+	r.keys[id].LastUsedIP = clientIP
+	return nil
+}
+
+func TestAdminKeyScopes(t *testing.T) {
+	scopes, err := normalizeAdminKeyScopes([]string{
+		"users:read", "post /redeem-codes/create-and-redeem", "ops/*", "users:read", " ",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users:read", "POST redeem-codes/create-and-redeem", "ops/*"}, scopes)
+
+	_, err = normalizeAdminKeyScopes(nil)
+	require.ErrorIs(t, err, ErrAdminKeyScopeEmpty)
+	_, err = normalizeAdminKeyScopes([]string{"users:everything"})
+	require.ErrorIs(t, err, ErrAdminKeyScopeInvalid)
+	_, err = normalizeAdminKeyScopes([]string{"FETCH users"})
+	require.ErrorIs(t, err, ErrAdminKeyScopeInvalid)
+
+	key := &AdminKey{Name: "payment-bot", KeyPrefix: "admin-abc123", Scopes: scopes}
+
+	// 路由 scope 命中：放行该路由的路由级权限校验，但不计入权限列表
+	access := key.AccessFor("POST", "redeem-codes/create-and-redeem")
+	require.True(t, access.Allows(AdminPermRedeemWrite))
+	require.True(t, access.Allows(AdminPermRedeemRead))
+	require.False(t, access.Has(AdminPermRedeemWrite))
+	require.False(t, access.Allows(AdminPermRolesManage), "路由 scope 不授予角色管理权限")
+	require.Equal(t, []string{AdminPermUsersRead}, access.Permissions)
+	// 方法不匹配时仅剩权限 scope
+	access = key.AccessFor("GET", "redeem-codes/create-and-redeem")
+	require.False(t, access.Allows(AdminPermRedeemWrite))
+	require.True(t, access.Allows(AdminPermUsersRead))
+	// 前缀匹配
+	require.True(t, key.AccessFor("PUT", "ops/alert-rules/:id").Allows(AdminPermOpsWrite))
+	require.False(t, key.AccessFor("PUT", "opsx/rules").Allows(AdminPermOpsWrite))
+	// 未命中的路由只有 users:read
+	access = key.AccessFor("POST", "users/:id/balance")
+	require.False(t, access.Allows(AdminPermUsersBalance))
+	require.Equal(t, []string{AdminPermUsersRead}, access.Permissions)
+
+	// 路由 scope 不覆盖 Admin API Key 与角色管理
+	settings := &AdminKey{Scopes: []string{"settings/*", "rbac/*"}}
+	require.True(t, settings.AccessFor("PUT", "settings").Allows(AdminPermSettingsWrite))
+	require.True(t, settings.AccessFor("PUT", "settings/smtp").Allows(AdminPermSettingsWrite))
+	require.False(t, settings.AccessFor("POST", "settings/admin-api-keys").Allows(AdminPermSettingsWrite))
+	require.False(t, settings.AccessFor("POST", "settings/admin-api-key/regenerate").Allows(AdminPermSettingsWrite))
+	require.False(t, settings.AccessFor("POST", "rbac/roles").Allows(AdminPermUsersRead))
+
+	all := &AdminKey{Scopes: []string{AdminKeyScopeAll}}
+	require.True(t, all.AccessFor("POST", "system/restart").Has(AdminPermSystemManage))
+}
+
+func TestAdminKeyLifecycle(t *testing.T) {
+	repo := newAdminKeyRepoStub()
+	svc := NewAdminKeyService(repo)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	past := now.Add(-time.Hour)
+	_, _, err := svc.Create(ctx, AdminKeyInput{Name: "x", Scopes: []string{"users:read"}, ExpiresAt: &past}, 1)
+	require.ErrorIs(t, err, ErrAdminKeyExpiryPast)
+	_, _, err = svc.Create(ctx, AdminKeyInput{Name: "x", Scopes: []string{"users:read"}, IPAllowlist: []string{"not-an-ip"}}, 1)
+	require.ErrorIs(t, err, ErrInvalidIPPattern)
+
+	expires := now.Add(time.Hour)
+	bot, botSecret, err := svc.Create(ctx, AdminKeyInput{
+		Name:        "payment-bot",
+		Scopes:      []string{"POST redeem-codes/create-and-redeem"},
+		IPAllowlist: []string{"10.0.0.0/8"},
+		ExpiresAt:   &expires,
+	}, 1)
+	require.NoError(t, err)
+	require.Contains(t, botSecret, AdminAPIKeyPrefix)
+	require.Equal(t, botSecret[:len(bot.KeyPrefix)], bot.KeyPrefix)
+	require.NotContains(t, repo.keys[bot.ID].KeyHash, botSecret)
+
+	monitor, monitorSecret, err := svc.Create(ctx, AdminKeyInput{Name: "monitoring", Scopes: []string{"ops:read"}}, 1)
+	require.NoError(t, err)
+
+	got, err := svc.Authenticate(ctx, botSecret, "10.1.2.3")
+	require.NoError(t, err)
+	require.Equal(t, bot.ID, got.ID)
+
+	_, err = svc.Authenticate(ctx, botSecret, "192.168.1.1")
+	require.ErrorIs(t, err, ErrAdminKeyIPDenied)
+	_, err = svc.Authenticate(ctx, "admin-unknown", "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminKeyInvalid)
+	_, err = svc.Authenticate(ctx, "sk-whatever", "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminKeyInvalid)
+
+	// 轮换只影响当前 Key
+	_, newBotSecret, err := svc.Rotate(ctx, bot.ID)
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, botSecret, "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminKeyInvalid)
+	_, err = svc.Authenticate(ctx, newBotSecret, "10.1.2.3")
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, monitorSecret, "203.0.113.5")
+	require.NoError(t, err)
+
+	// 过期
+	now = now.Add(2 * time.Hour)
+	_, err = svc.Authenticate(ctx, newBotSecret, "10.1.2.3")
+	require.ErrorIs(t, err, ErrAdminKeyExpired)
+
+	// 吊销只影响当前 Key
+	require.NoError(t, svc.Revoke(ctx, monitor.ID))
+	_, err = svc.Authenticate(ctx, monitorSecret, "203.0.113.5")
+	require.ErrorIs(t, err, ErrAdminKeyInvalid)
+	_, _, err = svc.Rotate(ctx, monitor.ID)
+	require.ErrorIs(t, err, ErrAdminKeyRevoked)
+	_, err = svc.Update(ctx, monitor.ID, AdminKeyInput{Name: "m", Scopes: []string{"ops:read"}})
+	require.ErrorIs(t, err, ErrAdminKeyRevoked)
+	require.ErrorIs(t, svc.Revoke(ctx, 999), ErrAdminKeyNotFound)
+
+	// 最近使用记录为异步写入
+	require.Eventually(t, func() bool {
+		k, _ := repo.GetByID(ctx, monitor.ID)
+		return k.LastUsedAt != nil && k.LastUsedIP == "203.0.113.5"
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,529 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// 管理后台权限点（资源:动作）。
+// 路由与权限的映射见 server/routes/admin.go。
+const (
+	AdminPermDashboardRead      = "dashboard:read"
+	AdminPermUsersRead          = "users:read"
+	AdminPermUsersWrite         = "users:write"
+	AdminPermUsersBalance       = "users:balance"
+	AdminPermUsersImpersonate   = "users:impersonate"
+	AdminPermGroupsRead         = "groups:read"
+	AdminPermGroupsWrite        = "groups:write"
+	AdminPermAccountsRead       = "accounts:read"
+	AdminPermAccountsWrite      = "accounts:write"
+	AdminPermProxiesRead        = "proxies:read"
+	AdminPermProxiesWrite       = "proxies:write"
+	AdminPermAnnouncementsRead  = "announcements:read"
+	AdminPermAnnouncementsWrite = "announcements:write"
+	AdminPermRedeemRead         = "redeem:read"
+	AdminPermRedeemWrite        = "redeem:write"
+	AdminPermPromoRead          = "promo:read"
+	AdminPermPromoWrite         = "promo:write"
+	AdminPermSubscriptionsRead  = "subscriptions:read"
+	AdminPermSubscriptionsWrite = "subscriptions:write"
+	AdminPermUsageRead          = "usage:read"
+	AdminPermUsageWrite         = "usage:write"
+	AdminPermOpsRead            = "ops:read"
+	AdminPermOpsWrite           = "ops:write"
+	AdminPermSettingsRead       = "settings:read"
+	AdminPermSettingsWrite      = "settings:write"
+	AdminPermDataManage         = "data:manage"
+	AdminPermSystemRead         = "system:read"
+	AdminPermSystemManage       = "system:manage"
+	AdminPermRolesManage        = "roles:manage"
+)
+
+// 内置管理角色
+const (
+	AdminRoleViewer     = "viewer"
+	AdminRoleSupport    = "support"
+	AdminRoleFinance    = "finance"
+	AdminRoleOperator   = "operator"
+	AdminRoleSuperAdmin = "superadmin"
+)
+
+var (
+	ErrAdminRoleNotFound        = infraerrors.NotFound("ADMIN_ROLE_NOT_FOUND", "admin role not found")
+	ErrAdminRoleExists          = infraerrors.Conflict("ADMIN_ROLE_EXISTS", "admin role already exists")
+	ErrAdminRoleBuiltIn         = infraerrors.BadRequest("ADMIN_ROLE_BUILTIN", "built-in admin roles cannot be modified")
+	ErrAdminRoleInUse           = infraerrors.Conflict("ADMIN_ROLE_IN_USE", "admin role is still assigned to users")
+	ErrAdminRoleInvalidKey      = infraerrors.BadRequest("ADMIN_ROLE_INVALID_KEY", "role key must be 2-64 characters of lowercase letters, digits, '-' or '_'")
+	ErrAdminRoleInvalidPerm     = infraerrors.BadRequest("ADMIN_ROLE_INVALID_PERMISSION", "unknown admin permission")
+	ErrAdminRoleSelfAssignment  = infraerrors.BadRequest("ADMIN_ROLE_SELF_ASSIGNMENT", "you cannot change your own admin role")
+	ErrAdminRoleAssignmentEmpty = infraerrors.NotFound("ADMIN_ROLE_ASSIGNMENT_NOT_FOUND", "user has no admin role assignment")
+)
+
+var adminRoleKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,63}$`)
+
+// AdminPermissionInfo 权限点说明（供后台展示权限勾选列表）
+type AdminPermissionInfo struct {
+	Key         string `json:"key"`
+	Resource    string `json:"resource"`
+	Description string `json:"description"`
+}
+
+// adminPermissionCatalog 全部权限点，顺序即展示顺序
+var adminPermissionCatalog = []AdminPermissionInfo{
+	{AdminPermDashboardRead, "dashboard", "View dashboard statistics"},
+	{AdminPermUsersRead, "users", "View users, their API keys and usage"},
+	{AdminPermUsersWrite, "users", "Create, edit and delete users; manage user API keys and key sharing reviews"},
+	{AdminPermUsersBalance, "users", "Adjust user balances"},
+	{AdminPermUsersImpersonate, "users", "Impersonate users (read-only by default) and view the impersonation audit log"},
+	{AdminPermGroupsRead, "groups", "View groups"},
+	{AdminPermGroupsWrite, "groups", "Create, edit and delete groups"},
+	{AdminPermAccountsRead, "accounts", "View upstream accounts"},
+	{AdminPermAccountsWrite, "accounts", "Manage upstream accounts, OAuth flows and scheduled tests"},
+	{AdminPermProxiesRead, "proxies", "View proxies"},
+	{AdminPermProxiesWrite, "proxies", "Manage proxies"},
+	{AdminPermAnnouncementsRead, "announcements", "View announcements"},
+	{AdminPermAnnouncementsWrite, "announcements", "Publish and edit announcements"},
+	{AdminPermRedeemRead, "redeem", "View redeem codes"},
+	{AdminPermRedeemWrite, "redeem", "Generate, redeem and expire redeem codes"},
+	{AdminPermPromoRead, "promo", "View promo codes"},
+	{AdminPermPromoWrite, "promo", "Manage promo codes"},
+	{AdminPermSubscriptionsRead, "subscriptions", "View subscriptions"},
+	{AdminPermSubscriptionsWrite, "subscriptions", "Assign, extend and revoke subscriptions"},
+	{AdminPermUsageRead, "usage", "View usage records and archives"},
+	{AdminPermUsageWrite, "usage", "Run usage cleanup and archive rehydration"},
+	{AdminPermOpsRead, "ops", "View ops monitoring, alerts and logs"},
+	{AdminPermOpsWrite, "ops", "Manage alert rules, ops settings, retries and error passthrough rules"},
+	{AdminPermSettingsRead, "settings", "View system settings"},
+	{AdminPermSettingsWrite, "settings", "Change system settings, admin API key and login providers"},
+	{AdminPermDataManage, "data", "Manage backups and data sources"},
+	{AdminPermSystemRead, "system", "View version and partition status"},
+	{AdminPermSystemManage, "system", "Update, roll back and restart the service; convert partitions"},
+	{AdminPermRolesManage, "roles", "Manage admin roles and assignments"},
+}
+
+var adminPermissionSet = func() map[string]struct{} {
+	set := make(map[string]struct{}, len(adminPermissionCatalog))
+	for _, p := range adminPermissionCatalog {
+		set[p.Key] = struct{}{}
+	}
+	return set
+}()
+
+// AdminRole 管理角色（内置或自定义）
+type AdminRole struct {
+	ID          int64     `json:"id,omitempty"`
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+// AdminRoleAssignment 用户与管理角色的绑定
+type AdminRoleAssignment struct {
+	UserID     int64     `json:"user_id"`
+	Email      string    `json:"email"`
+	Username   string    `json:"username"`
+	RoleKey    string    `json:"role_key"`
+	AssignedBy *int64    `json:"assigned_by,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AdminRoleInput 自定义角色创建/更新参数
+type AdminRoleInput struct {
+	Key         string
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// AdminAccess 管理员的有效权限
+type AdminAccess struct {
+	RoleKey     string   `json:"role_key"`
+	RoleName    string   `json:"role_name"`
+	Permissions []string `json:"permissions"`
+
+	set map[string]struct{}
+	// routeGranted 命中 Admin API Key 的路由 scope：路由级权限校验要求的权限（roles:manage 除外）按需放行，
+	// 不计入 Permissions，handler 内的 Has 判断仍按权限 scope 计算
+	routeGranted bool
+}
+
+// Has 判断是否拥有指定权限
+func (a *AdminAccess) Has(permission string) bool {
+	if a == nil {
+		return false
+	}
+	_, ok := a.set[permission]
+	return ok
+}
+
+// Allows 路由级权限校验：拥有该权限，或命中路由 scope 且不是角色/Key 管理权限
+func (a *AdminAccess) Allows(permission string) bool {
+	if a.Has(permission) {
+		return true
+	}
+	return a != nil && a.routeGranted && permission != AdminPermRolesManage
+}
+
+// AdminRoleRepository 自定义角色与角色分配存储
+type AdminRoleRepository interface {
+	ListRoles(ctx context.Context) ([]AdminRole, error)
+	// GetRole 不存在时返回 (nil, nil)
+	GetRole(ctx context.Context, key string) (*AdminRole, error)
+	CreateRole(ctx context.Context, role *AdminRole) error
+	UpdateRole(ctx context.Context, role *AdminRole) error
+	DeleteRole(ctx context.Context, key string) (bool, error)
+	CountAssignments(ctx context.Context, roleKey string) (int, error)
+
+	// GetAssignment 返回用户绑定的角色 key，未绑定时返回空字符串
+	GetAssignment(ctx context.Context, userID int64) (string, error)
+	ListAssignments(ctx context.Context) ([]AdminRoleAssignment, error)
+	SetAssignment(ctx context.Context, userID int64, roleKey string, assignedBy int64) error
+	DeleteAssignment(ctx context.Context, userID int64) (bool, error)
+}
+
+// builtInAdminRoles 内置角色定义（不可修改/删除）
+var builtInAdminRoles = []AdminRole{
+	{
+		Key:         AdminRoleViewer,
+		Name:        "Viewer",
+		Description: "Read-only access to dashboards and business data",
+		Permissions: []string{
+			AdminPermDashboardRead, AdminPermUsersRead, AdminPermGroupsRead, AdminPermAccountsRead,
+			AdminPermProxiesRead, AdminPermAnnouncementsRead, AdminPermRedeemRead, AdminPermPromoRead,
+			AdminPermSubscriptionsRead, AdminPermUsageRead, AdminPermOpsRead, AdminPermSystemRead,
+		},
+	},
+	{
+		Key:         AdminRoleSupport,
+		Name:        "Support",
+		Description: "Handle user tickets: manage users, subscriptions and announcements, no balance edits",
+		Permissions: []string{
+			AdminPermDashboardRead, AdminPermUsersRead, AdminPermUsersWrite, AdminPermUsersImpersonate, AdminPermGroupsRead,
+			AdminPermAccountsRead, AdminPermAnnouncementsRead, AdminPermAnnouncementsWrite,
+			AdminPermRedeemRead, AdminPermPromoRead, AdminPermSubscriptionsRead, AdminPermSubscriptionsWrite,
+			AdminPermUsageRead, AdminPermOpsRead,
+		},
+	},
+	{
+		Key:         AdminRoleFinance,
+		Name:        "Finance",
+		Description: "Balances, redeem codes, promo codes and subscriptions",
+		Permissions: []string{
+			AdminPermDashboardRead, AdminPermUsersRead, AdminPermUsersBalance, AdminPermGroupsRead,
+			AdminPermRedeemRead, AdminPermRedeemWrite, AdminPermPromoRead, AdminPermPromoWrite,
+			AdminPermSubscriptionsRead, AdminPermSubscriptionsWrite, AdminPermUsageRead,
+		},
+	},
+	{
+		Key:         AdminRoleOperator,
+		Name:        "Operator",
+		Description: "Run the gateway: accounts, groups, proxies and ops monitoring, no system updates",
+		Permissions: []string{
+			AdminPermDashboardRead, AdminPermUsersRead, AdminPermGroupsRead, AdminPermGroupsWrite,
+			AdminPermAccountsRead, AdminPermAccountsWrite, AdminPermProxiesRead, AdminPermProxiesWrite,
+			AdminPermAnnouncementsRead, AdminPermAnnouncementsWrite, AdminPermSubscriptionsRead,
+			AdminPermUsageRead, AdminPermOpsRead, AdminPermOpsWrite, AdminPermSettingsRead, AdminPermSystemRead,
+		},
+	},
+	{
+		Key:         AdminRoleSuperAdmin,
+		Name:        "Super Admin",
+		Description: "Full access, including system updates and role management",
+		Permissions: AllAdminPermissions(),
+	},
+}
+
+// AllAdminPermissions 返回全部权限点 key
+func AllAdminPermissions() []string {
+	out := make([]string, 0, len(adminPermissionCatalog))
+	for _, p := range adminPermissionCatalog {
+		out = append(out, p.Key)
+	}
+	return out
+}
+
+// AdminPermissionCatalog 返回权限点说明列表
+func AdminPermissionCatalog() []AdminPermissionInfo {
+	out := make([]AdminPermissionInfo, len(adminPermissionCatalog))
+	copy(out, adminPermissionCatalog)
+	return out
+}
+
+func findBuiltInAdminRole(key string) *AdminRole {
+	for i := range builtInAdminRoles {
+		if builtInAdminRoles[i].Key == key {
+			role := builtInAdminRoles[i]
+			role.BuiltIn = true
+			role.Permissions = append([]string(nil), role.Permissions...)
+			return &role
+		}
+	}
+	return nil
+}
+
+// NewAdminAccess 根据角色构造有效权限
+func NewAdminAccess(role *AdminRole) *AdminAccess {
+	access := &AdminAccess{set: make(map[string]struct{})}
+	if role == nil {
+		access.Permissions = []string{}
+		return access
+	}
+	access.RoleKey = role.Key
+	access.RoleName = role.Name
+	for _, p := range role.Permissions {
+		if _, known := adminPermissionSet[p]; !known {
+			continue
+		}
+		access.set[p] = struct{}{}
+	}
+	// 保持与权限目录一致的顺序
+	access.Permissions = make([]string, 0, len(access.set))
+	for _, p := range adminPermissionCatalog {
+		if _, ok := access.set[p.Key]; ok {
+			access.Permissions = append(access.Permissions, p.Key)
+		}
+	}
+	return access
+}
+
+// SuperAdminAccess 返回拥有全部权限的访问上下文（Admin API Key 与未分配角色的管理员）
+func SuperAdminAccess() *AdminAccess {
+	return NewAdminAccess(findBuiltInAdminRole(AdminRoleSuperAdmin))
+}
+
+// AdminRBACService 管理后台角色与权限：
+// - role=admin 且未分配角色的用户视为 superadmin（兼容升级前的单一管理员角色）；
+// - 分配了角色的用户（无论 role 是否为 admin）按角色权限访问后台；
+// - 其余用户无后台访问权限。
+type AdminRBACService struct {
+	repo     AdminRoleRepository
+	userRepo UserRepository
+}
+
+// NewAdminRBACService 创建管理后台 RBAC 服务
+func NewAdminRBACService(repo AdminRoleRepository, userRepo UserRepository) *AdminRBACService {
+	return &AdminRBACService{repo: repo, userRepo: userRepo}
+}
+
+// ResolveAccess 计算用户的后台有效权限；无后台访问权限时返回 (nil, nil)
+func (s *AdminRBACService) ResolveAccess(ctx context.Context, user *User) (*AdminAccess, error) {
+	if user == nil {
+		return nil, nil
+	}
+	roleKey, err := s.repo.GetAssignment(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if roleKey == "" {
+		if user.IsAdmin() {
+			return SuperAdminAccess(), nil
+		}
+		return nil, nil
+	}
+	role, err := s.getRole(ctx, roleKey)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		// 角色已被删除（正常情况下删除前会校验），按无权限处理
+		logger.LegacyPrintf("service.admin_rbac", "[AdminRBAC] user %d assigned to missing role %q", user.ID, roleKey)
+		return nil, nil
+	}
+	return NewAdminAccess(role), nil
+}
+
+// IsStaff 判断用户是否拥有任何后台访问权限
+func (s *AdminRBACService) IsStaff(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	access, err := s.ResolveAccess(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return access != nil, nil
+}
+
+// ListRoles 返回内置与自定义角色
+func (s *AdminRBACService) ListRoles(ctx context.Context) ([]AdminRole, error) {
+	custom, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AdminRole, 0, len(builtInAdminRoles)+len(custom))
+	for _, r := range builtInAdminRoles {
+		out = append(out, *findBuiltInAdminRole(r.Key))
+	}
+	sort.SliceStable(custom, func(i, j int) bool { return custom[i].Key < custom[j].Key })
+	return append(out, custom...), nil
+}
+
+// CreateRole 创建自定义角色
+func (s *AdminRBACService) CreateRole(ctx context.Context, input AdminRoleInput) (*AdminRole, error) {
+	key := strings.ToLower(strings.TrimSpace(input.Key))
+	if !adminRoleKeyPattern.MatchString(key) {
+		return nil, ErrAdminRoleInvalidKey
+	}
+	if findBuiltInAdminRole(key) != nil {
+		return nil, ErrAdminRoleExists
+	}
+	perms, err := normalizeAdminPermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role := &AdminRole{
+		Key:         key,
+		Name:        adminRoleName(input.Name, key),
+		Description: strings.TrimSpace(input.Description),
+		Permissions: perms,
+	}
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole 更新自定义角色（key 不可变）
+func (s *AdminRBACService) UpdateRole(ctx context.Context, key string, input AdminRoleInput) (*AdminRole, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if findBuiltInAdminRole(key) != nil {
+		return nil, ErrAdminRoleBuiltIn
+	}
+	role, err := s.repo.GetRole(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrAdminRoleNotFound
+	}
+	perms, err := normalizeAdminPermissions(input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role.Name = adminRoleName(input.Name, key)
+	role.Description = strings.TrimSpace(input.Description)
+	role.Permissions = perms
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole 删除自定义角色；仍有用户绑定时拒绝
+func (s *AdminRBACService) DeleteRole(ctx context.Context, key string) error {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if findBuiltInAdminRole(key) != nil {
+		return ErrAdminRoleBuiltIn
+	}
+	count, err := s.repo.CountAssignments(ctx, key)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAdminRoleInUse.WithMetadata(map[string]string{"assigned_users": strconv.Itoa(count)})
+	}
+	deleted, err := s.repo.DeleteRole(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAdminRoleNotFound
+	}
+	return nil
+}
+
+// ListAssignments 返回全部角色分配
+func (s *AdminRBACService) ListAssignments(ctx context.Context) ([]AdminRoleAssignment, error) {
+	return s.repo.ListAssignments(ctx)
+}
+
+// AssignRole 为用户分配角色（覆盖原有分配）
+func (s *AdminRBACService) AssignRole(ctx context.Context, actorID, userID int64, roleKey string) error {
+	if actorID != 0 && actorID == userID {
+		return ErrAdminRoleSelfAssignment
+	}
+	roleKey = strings.ToLower(strings.TrimSpace(roleKey))
+	role, err := s.getRole(ctx, roleKey)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrAdminRoleNotFound
+	}
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.repo.SetAssignment(ctx, userID, roleKey, actorID); err != nil {
+		return err
+	}
+	logger.LegacyPrintf("service.admin_rbac", "[AdminRBAC] role assigned: user_id=%d role=%s by=%d", userID, roleKey, actorID)
+	return nil
+}
+
+// UnassignRole 移除用户的角色分配。
+// 注意：role=admin 的用户移除分配后恢复为 superadmin，普通用户则失去后台访问权限。
+func (s *AdminRBACService) UnassignRole(ctx context.Context, actorID, userID int64) error {
+	if actorID != 0 && actorID == userID {
+		return ErrAdminRoleSelfAssignment
+	}
+	deleted, err := s.repo.DeleteAssignment(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAdminRoleAssignmentEmpty
+	}
+	logger.LegacyPrintf("service.admin_rbac", "[AdminRBAC] role unassigned: user_id=%d by=%d", userID, actorID)
+	return nil
+}
+
+func (s *AdminRBACService) getRole(ctx context.Context, key string) (*AdminRole, error) {
+	if role := findBuiltInAdminRole(key); role != nil {
+		return role, nil
+	}
+	return s.repo.GetRole(ctx, key)
+}
+
+func normalizeAdminPermissions(perms []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, ok := adminPermissionSet[p]; !ok {
+			return nil, ErrAdminRoleInvalidPerm.WithMetadata(map[string]string{"permission": p})
+		}
+		seen[p] = struct{}{}
+	}
+	out := make([]string, 0, len(seen))
+	for _, p := range adminPermissionCatalog {
+		if _, ok := seen[p.Key]; ok {
+			out = append(out, p.Key)
+		}
+	}
+	return out, nil
+}
+
+func adminRoleName(name, key string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return key
+	}
+	if len([]rune(name)) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	return name
+}
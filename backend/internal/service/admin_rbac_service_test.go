@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type rbacUserRepoStub struct {
+	UserRepository
+	users map[int64]*User
+}
+
+func (r *rbacUserRepoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, ErrUserNotFound
+}
+
+type rbacRoleRepoStub struct {
+	roles       map[string]*AdminRole
+	assignments map[int64]string
+}
+
+func newRBACRoleRepoStub() *rbacRoleRepoStub {
+	return &rbacRoleRepoStub{roles: map[string]*AdminRole{}, assignments: map[int64]string{}}
+}
+
+func (r *rbacRoleRepoStub) ListRoles(ctx context.Context) ([]AdminRole, error) {
+	out := make([]AdminRole, 0, len(r.roles))
+	for _, role := range r.roles {
+		out = append(out, *role)
+	}
+	return out, nil
+}
+
+func (r *rbacRoleRepoStub) GetRole(ctx context.Context, key string) (*AdminRole, error) {
+	if role, ok := r.roles[key]; ok {
+		clone := *role
+		return &clone, nil
+	}
+	return nil, nil
+}
+
+func (r *rbacRoleRepoStub) CreateRole(ctx context.Context, role *AdminRole) error {
+	if _, ok := r.roles[role.Key]; ok {
+		return ErrAdminRoleExists
+	}
+	clone := *role
+	r.roles[role.Key] = &clone
+	return nil
+}
+
+func (r *rbacRoleRepoStub) UpdateRole(ctx context.Context, role *AdminRole) error {
+	clone := *role
+	r.roles[role.Key] = &clone
+	return nil
+}
+
+func (r *rbacRoleRepoStub) DeleteRole(ctx context.Context, key string) (bool, error) {
+	_, ok := r.roles[key]
+	delete(r.roles, key)
+	return ok, nil
+}
+
+func (r *rbacRoleRepoStub) CountAssignments(ctx context.Context, roleKey string) (int, error) {
+	count := 0
+	for _, key := range r.assignments {
+		if key == roleKey {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *rbacRoleRepoStub) GetAssignment(ctx context.Context, userID int64) (string, error) {
+	return r.assignments[userID], nil
+}
+
+func (r *rbacRoleRepoStub) ListAssignments(ctx context.Context) ([]AdminRoleAssignment, error) {
+	out := make([]AdminRoleAssignment, 0, len(r.assignments))
+	for userID, key := range r.assignments {
+		out = append(out, AdminRoleAssignment{UserID: userID, RoleKey: key})
+	}
+	return out, nil
+}
+
+func (r *rbacRoleRepoStub) SetAssignment(ctx context.Context, userID int64, roleKey string, assignedBy int64) error {
+	r.assignments[userID] = roleKey
+	return nil
+}
+
+func (r *rbacRoleRepoStub) DeleteAssignment(ctx context.Context, userID int64) (bool, error) {
+	_, ok := r.assignments[userID]
+	delete(r.assignments, userID)
+	return ok, nil
+}
+
+func newRBACTestService() (*AdminRBACService, *rbacRoleRepoStub, map[int64]*User) {
+	users := map[int64]*User{
+		1: {ID: 1, Role: RoleAdmin, Status: StatusActive},
+		2: {ID: 2, Role: RoleUser, Status: StatusActive},
+		3: {ID: 3, Role: RoleAdmin, Status: StatusActive},
+	}
+	repo := newRBACRoleRepoStub()
+	return NewAdminRBACService(repo, &rbacUserRepoStub{users: users}), repo, users
+}
+
+func TestAdminRBACResolveAccess(t *testing.T) {
+	svc, _, users := newRBACTestService()
+	ctx := context.Background()
+
+	// 未分配角色的管理员保持全部权限（兼容升级前行为）
+	access, err := svc.ResolveAccess(ctx, users[1])
+	require.NoError(t, err)
+	require.Equal(t, AdminRoleSuperAdmin, access.RoleKey)
+	require.ElementsMatch(t, AllAdminPermissions(), access.Permissions)
+
+	// 普通用户无后台权限
+	access, err = svc.ResolveAccess(ctx, users[2])
+	require.NoError(t, err)
+	require.Nil(t, access)
+
+	// 分配 support 角色后获得受限权限
+	require.NoError(t, svc.AssignRole(ctx, 1, 2, AdminRoleSupport))
+	access, err = svc.ResolveAccess(ctx, users[2])
+	require.NoError(t, err)
+	require.True(t, access.Has(AdminPermUsersWrite))
+	require.False(t, access.Has(AdminPermUsersBalance))
+	require.False(t, access.Has(AdminPermSystemManage))
+
+	// 管理员也可以被降级为只读
+	require.NoError(t, svc.AssignRole(ctx, 1, 3, AdminRoleViewer))
+	access, err = svc.ResolveAccess(ctx, users[3])
+	require.NoError(t, err)
+	require.True(t, access.Has(AdminPermDashboardRead))
+	require.False(t, access.Has(AdminPermUsersWrite))
+
+	// 移除分配后管理员恢复为 superadmin，普通用户失去访问权限
+	require.NoError(t, svc.UnassignRole(ctx, 1, 3))
+	access, err = svc.ResolveAccess(ctx, users[3])
+	require.NoError(t, err)
+	require.True(t, access.Has(AdminPermRolesManage))
+	require.NoError(t, svc.UnassignRole(ctx, 1, 2))
+	staff, err := svc.IsStaff(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, staff)
+}
+
+func TestAdminRBACAssignmentGuards(t *testing.T) {
+	svc, _, _ := newRBACTestService()
+	ctx := context.Background()
+
+	require.ErrorIs(t, svc.AssignRole(ctx, 1, 1, AdminRoleViewer), ErrAdminRoleSelfAssignment)
+	require.ErrorIs(t, svc.UnassignRole(ctx, 1, 1), ErrAdminRoleSelfAssignment)
+	require.ErrorIs(t, svc.AssignRole(ctx, 1, 2, "missing"), ErrAdminRoleNotFound)
+	require.ErrorIs(t, svc.AssignRole(ctx, 1, 99, AdminRoleViewer), ErrUserNotFound)
+	require.ErrorIs(t, svc.UnassignRole(ctx, 1, 2), ErrAdminRoleAssignmentEmpty)
+}
+
+func TestAdminRBACCustomRoles(t *testing.T) {
+	svc, _, users := newRBACTestService()
+	ctx := context.Background()
+
+	_, err := svc.CreateRole(ctx, AdminRoleInput{Key: AdminRoleFinance, Permissions: []string{AdminPermUsersRead}})
+	require.ErrorIs(t, err, ErrAdminRoleExists)
+	_, err = svc.CreateRole(ctx, AdminRoleInput{Key: "Bad Key!", Permissions: []string{AdminPermUsersRead}})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidKey)
+	_, err = svc.CreateRole(ctx, AdminRoleInput{Key: "auditor", Permissions: []string{"users:everything"}})
+	require.ErrorIs(t, err, ErrAdminRoleInvalidPerm)
+
+	role, err := svc.CreateRole(ctx, AdminRoleInput{
+		Key:         " Auditor ",
+		Permissions: []string{AdminPermUsageRead, AdminPermDashboardRead, AdminPermUsageRead},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "auditor", role.Key)
+	require.Equal(t, "auditor", role.Name)
+	// 去重并按权限目录排序
+	require.Equal(t, []string{AdminPermDashboardRead, AdminPermUsageRead}, role.Permissions)
+
+	roles, err := svc.ListRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, len(builtInAdminRoles)+1)
+	require.True(t, roles[0].BuiltIn)
+	require.False(t, roles[len(roles)-1].BuiltIn)
+
+	require.NoError(t, svc.AssignRole(ctx, 1, 2, "auditor"))
+	access, err := svc.ResolveAccess(ctx, users[2])
+	require.NoError(t, err)
+	require.Equal(t, "auditor", access.RoleKey)
+	require.False(t, access.Has(AdminPermUsersRead))
+
+	// 修改角色后立即生效
+	_, err = svc.UpdateRole(ctx, "auditor", AdminRoleInput{Name: "Auditor", Permissions: []string{AdminPermUsersRead}})
+	require.NoError(t, err)
+	access, err = svc.ResolveAccess(ctx, users[2])
+	require.NoError(t, err)
+	require.True(t, access.Has(AdminPermUsersRead))
+	require.False(t, access.Has(AdminPermUsageRead))
+
+	// 内置角色不可修改；使用中的角色不可删除
+	_, err = svc.UpdateRole(ctx, AdminRoleViewer, AdminRoleInput{})
+	require.ErrorIs(t, err, ErrAdminRoleBuiltIn)
+	require.ErrorIs(t, svc.DeleteRole(ctx, AdminRoleViewer), ErrAdminRoleBuiltIn)
+	require.ErrorIs(t, svc.DeleteRole(ctx, "auditor"), ErrAdminRoleInUse)
+	require.NoError(t, svc.UnassignRole(ctx, 1, 2))
+	require.NoError(t, svc.DeleteRole(ctx, "auditor"))
+	require.ErrorIs(t, svc.DeleteRole(ctx, "auditor"), ErrAdminRoleNotFound)
+}
+
+func TestBuiltInAdminRolesUseKnownPermissions(t *testing.T) {
+	for _, role := range builtInAdminRoles {
+		perms, err := normalizeAdminPermissions(role.Permissions)
+		require.NoError(t, err, role.Key)
+		require.Len(t, perms, len(role.Permissions), role.Key)
+	}
+}
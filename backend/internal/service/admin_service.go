@@ -97,6 +97,11 @@ type CreateUserInput struct {
 	Concurrency           int
 	AllowedGroups         []int64
 	SoraStorageQuotaBytes int64
+	// 请求速率限制（0 不限制）
+	RPMLimit int
+	TPMLimit int
+	// 账号满载排队优先级（0-9）
+	QueuePriority int
 }
 
 type UpdateUserInput struct {
@@ -112,6 +117,11 @@ type UpdateUserInput struct {
 	// map[groupID]*rate，nil 表示删除该分组的专属倍率
 	GroupRates            map[int64]*float64
 	SoraStorageQuotaBytes *int64
+	// 请求速率限制（0 不限制）
+	RPMLimit *int
+	TPMLimit *int
+	// 账号满载排队优先级（0-9）
+	QueuePriority *int
 }
 
 type CreateGroupInput struct {
@@ -148,6 +158,29 @@ type CreateGroupInput struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool
 	DefaultMappedModel    string
+	// 每个 API Key 每小时允许的不同来源 IP 数（0 不限制）
+	MaxIPsPerKeyPerHour int
+	// 分组内所有请求合计的 RPM/TPM 上限（0 不限制）
+	RPMLimit int
+	TPMLimit int
+	// 账号调度策略（空为平台默认）
+	SchedulingStrategy string
+	// 模型降级链
+	ModelFallbackChains []ModelFallbackChain
+	// 跨平台故障转移（仅 anthropic / antigravity 平台生效）
+	CrossPlatformFailover bool
+	// 影子流量镜像
+	ShadowMirror *ShadowMirrorConfig
+	// 网关响应缓存
+	ResponseCache *ResponseCacheConfig
+	// 自动 prompt 缓存断点（仅 Anthropic 请求生效）
+	AutoCacheBreakpoints bool
+	// 流式断线续传
+	StreamResume bool
+	// 上下文策略（输入超限时拒绝、截断最早轮次或摘要）
+	ContextPolicy *ContextPolicyConfig
+	// 账号满载排队优先级（0-9）
+	QueuePriority int
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -187,6 +220,29 @@ type UpdateGroupInput struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch *bool
 	DefaultMappedModel    *string
+	// 每个 API Key 每小时允许的不同来源 IP 数（0 不限制）
+	MaxIPsPerKeyPerHour *int
+	// 分组内所有请求合计的 RPM/TPM 上限（0 不限制）
+	RPMLimit *int
+	TPMLimit *int
+	// 账号调度策略（空字符串恢复平台默认）
+	SchedulingStrategy *string
+	// 模型降级链（nil 不修改，空数组清除）
+	ModelFallbackChains []ModelFallbackChain
+	// 跨平台故障转移（仅 anthropic / antigravity 平台生效）
+	CrossPlatformFailover *bool
+	// 影子流量镜像（nil 不修改，enabled=false 关闭）
+	ShadowMirror *ShadowMirrorConfig
+	// 网关响应缓存（nil 不修改，enabled=false 关闭）
+	ResponseCache *ResponseCacheConfig
+	// 自动 prompt 缓存断点（仅 Anthropic 请求生效）
+	AutoCacheBreakpoints *bool
+	// 流式断线续传
+	StreamResume *bool
+	// 上下文策略（nil 不修改，enabled=false 关闭）
+	ContextPolicy *ContextPolicyConfig
+	// 账号满载排队优先级（0-9）
+	QueuePriority *int
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -553,6 +609,9 @@ func (s *adminServiceImpl) CreateUser(ctx context.Context, input *CreateUserInpu
 		Status:                StatusActive,
 		AllowedGroups:         input.AllowedGroups,
 		SoraStorageQuotaBytes: input.SoraStorageQuotaBytes,
+		RPMLimit:              input.RPMLimit,
+		TPMLimit:              input.TPMLimit,
+		QueuePriority:         input.QueuePriority,
 	}
 	if err := user.SetPassword(input.Password); err != nil {
 		return nil, err
@@ -595,6 +654,9 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 	oldConcurrency := user.Concurrency
 	oldStatus := user.Status
 	oldRole := user.Role
+	oldRPMLimit := user.RPMLimit
+	oldTPMLimit := user.TPMLimit
+	oldQueuePriority := user.QueuePriority
 
 	if input.Email != "" {
 		user.Email = input.Email
@@ -628,6 +690,16 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 		user.SoraStorageQuotaBytes = *input.SoraStorageQuotaBytes
 	}
 
+	if input.RPMLimit != nil {
+		user.RPMLimit = *input.RPMLimit
+	}
+	if input.TPMLimit != nil {
+		user.TPMLimit = *input.TPMLimit
+	}
+	if input.QueuePriority != nil {
+		user.QueuePriority = *input.QueuePriority
+	}
+
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
@@ -640,7 +712,9 @@ func (s *adminServiceImpl) UpdateUser(ctx context.Context, id int64, input *Upda
 	}
 
 	if s.authCacheInvalidator != nil {
-		if user.Concurrency != oldConcurrency || user.Status != oldStatus || user.Role != oldRole {
+		if user.Concurrency != oldConcurrency || user.Status != oldStatus || user.Role != oldRole ||
+			user.RPMLimit != oldRPMLimit || user.TPMLimit != oldTPMLimit ||
+			user.QueuePriority != oldQueuePriority {
 			s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, user.ID)
 		}
 	}
@@ -858,6 +932,30 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		mcpXMLInject = *input.MCPXMLInject
 	}
 
+	schedulingStrategy, err := normalizeGroupSchedulingStrategy(input.SchedulingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	modelFallbackChains, err := NormalizeModelFallbackChains(input.ModelFallbackChains)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateGroupModelFallbackChains(platform, modelFallbackChains); err != nil {
+		return nil, err
+	}
+	shadowMirror, err := s.normalizeShadowMirror(ctx, 0, platform, input.ShadowMirror)
+	if err != nil {
+		return nil, err
+	}
+	responseCache, err := NormalizeResponseCacheConfig(input.ResponseCache)
+	if err != nil {
+		return nil, err
+	}
+	contextPolicy, err := NormalizeContextPolicyConfig(input.ContextPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	// 如果指定了复制账号的源分组，先获取账号 ID 列表
 	var accountIDsToCopy []int64
 	if len(input.CopyAccountsFromGroupIDs) > 0 {
@@ -917,6 +1015,18 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		SoraStorageQuotaBytes:           input.SoraStorageQuotaBytes,
 		AllowMessagesDispatch:           input.AllowMessagesDispatch,
 		DefaultMappedModel:              input.DefaultMappedModel,
+		MaxIPsPerKeyPerHour:             input.MaxIPsPerKeyPerHour,
+		RPMLimit:                        input.RPMLimit,
+		TPMLimit:                        input.TPMLimit,
+		QueuePriority:                   input.QueuePriority,
+		SchedulingStrategy:              schedulingStrategy,
+		ModelFallbackChains:             modelFallbackChains,
+		CrossPlatformFailover:           input.CrossPlatformFailover,
+		ShadowMirror:                    shadowMirror,
+		ResponseCache:                   responseCache,
+		AutoCacheBreakpoints:            input.AutoCacheBreakpoints,
+		StreamResume:                    input.StreamResume,
+		ContextPolicy:                   contextPolicy,
 	}
 	if err := s.groupRepo.Create(ctx, group); err != nil {
 		return nil, err
@@ -987,6 +1097,38 @@ func (s *adminServiceImpl) validateFallbackGroup(ctx context.Context, currentGro
 	}
 }
 
+// normalizeShadowMirror 校验影子镜像配置：仅 anthropic / antigravity 分组可用，目标账号或分组必须存在且平台兼容
+func (s *adminServiceImpl) normalizeShadowMirror(ctx context.Context, currentGroupID int64, platform string, cfg *ShadowMirrorConfig) (*ShadowMirrorConfig, error) {
+	normalized, err := NormalizeShadowMirrorConfig(cfg)
+	if err != nil || normalized == nil {
+		return normalized, err
+	}
+	if platform != PlatformAnthropic && platform != PlatformAntigravity {
+		return nil, fmt.Errorf("%w: shadow mirror only supported for anthropic or antigravity groups", ErrShadowMirrorInvalid)
+	}
+	if normalized.AccountID > 0 {
+		account, err := s.accountRepo.GetByID(ctx, normalized.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("shadow account not found: %w", err)
+		}
+		if account.Platform != PlatformAnthropic && account.Platform != PlatformAntigravity {
+			return nil, fmt.Errorf("%w: shadow account must be anthropic or antigravity platform", ErrShadowMirrorInvalid)
+		}
+		return normalized, nil
+	}
+	if currentGroupID > 0 && currentGroupID == normalized.GroupID {
+		return nil, fmt.Errorf("%w: cannot set self as shadow group", ErrShadowMirrorInvalid)
+	}
+	shadowGroup, err := s.groupRepo.GetByIDLite(ctx, normalized.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("shadow group not found: %w", err)
+	}
+	if shadowGroup.Platform != PlatformAnthropic && shadowGroup.Platform != PlatformAntigravity {
+		return nil, fmt.Errorf("%w: shadow group must be anthropic or antigravity platform", ErrShadowMirrorInvalid)
+	}
+	return normalized, nil
+}
+
 // validateFallbackGroupOnInvalidRequest 校验无效请求兜底分组的有效性
 // currentGroupID: 当前分组 ID（新建时为 0）
 // platform/subscriptionType: 当前分组的有效平台/订阅类型
@@ -1138,6 +1280,75 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 		group.DefaultMappedModel = *input.DefaultMappedModel
 	}
 
+	// Key 共享防护
+	if input.MaxIPsPerKeyPerHour != nil {
+		group.MaxIPsPerKeyPerHour = *input.MaxIPsPerKeyPerHour
+	}
+
+	// 请求速率限制
+	if input.RPMLimit != nil {
+		group.RPMLimit = *input.RPMLimit
+	}
+	if input.TPMLimit != nil {
+		group.TPMLimit = *input.TPMLimit
+	}
+
+	// 排队优先级
+	if input.QueuePriority != nil {
+		group.QueuePriority = *input.QueuePriority
+	}
+
+	// 账号调度策略
+	if input.SchedulingStrategy != nil {
+		strategy, err := normalizeGroupSchedulingStrategy(*input.SchedulingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		group.SchedulingStrategy = strategy
+	}
+
+	// 模型降级链
+	if input.ModelFallbackChains != nil {
+		chains, err := NormalizeModelFallbackChains(input.ModelFallbackChains)
+		if err != nil {
+			return nil, err
+		}
+		group.ModelFallbackChains = chains
+	}
+	if err := validateGroupModelFallbackChains(group.Platform, group.ModelFallbackChains); err != nil {
+		return nil, err
+	}
+	if input.CrossPlatformFailover != nil {
+		group.CrossPlatformFailover = *input.CrossPlatformFailover
+	}
+	if input.ShadowMirror != nil {
+		shadowMirror, err := s.normalizeShadowMirror(ctx, id, group.Platform, input.ShadowMirror)
+		if err != nil {
+			return nil, err
+		}
+		group.ShadowMirror = shadowMirror
+	}
+	if input.ResponseCache != nil {
+		responseCache, err := NormalizeResponseCacheConfig(input.ResponseCache)
+		if err != nil {
+			return nil, err
+		}
+		group.ResponseCache = responseCache
+	}
+	if input.AutoCacheBreakpoints != nil {
+		group.AutoCacheBreakpoints = *input.AutoCacheBreakpoints
+	}
+	if input.StreamResume != nil {
+		group.StreamResume = *input.StreamResume
+	}
+	if input.ContextPolicy != nil {
+		contextPolicy, err := NormalizeContextPolicyConfig(input.ContextPolicy)
+		if err != nil {
+			return nil, err
+		}
+		group.ContextPolicy = contextPolicy
+	}
+
 	if err := s.groupRepo.Update(ctx, group); err != nil {
 		return nil, err
 	}
@@ -1320,7 +1531,9 @@ func (s *adminServiceImpl) AdminUpdateAPIKeyGroupID(ctx context.Context, keyID i
 
 			// 失效认证缓存（在事务提交后执行）
 			if s.authCacheInvalidator != nil {
-				s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+				for _, keyHash := range apiKey.AuthKeyHashes() {
+					s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, keyHash)
+				}
 			}
 
 			result.APIKey = apiKey
@@ -1335,7 +1548,9 @@ func (s *adminServiceImpl) AdminUpdateAPIKeyGroupID(ctx context.Context, keyID i
 
 	// 失效认证缓存
 	if s.authCacheInvalidator != nil {
-		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+		for _, keyHash := range apiKey.AuthKeyHashes() {
+			s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, keyHash)
+		}
 	}
 
 	result.APIKey = apiKey
@@ -12,6 +12,8 @@ const (
 	StatusAPIKeyDisabled       = "disabled"
 	StatusAPIKeyQuotaExhausted = "quota_exhausted"
 	StatusAPIKeyExpired        = "expired"
+	// StatusAPIKeySuspended 因费用异常被自动停用，只能由管理员解除
+	StatusAPIKeySuspended = "suspended"
 )
 
 // Rate limit window durations
@@ -27,9 +29,13 @@ func IsWindowExpired(windowStart *time.Time, duration time.Duration) bool {
 }
 
 type APIKey struct {
-	ID          int64
-	UserID      int64
-	Key         string
+	ID     int64
+	UserID int64
+	// Key 明文，仅在创建时与认证路径中可用；从数据库读取的记录为空
+	Key string
+	// KeyHash 存储的 Key 摘要（见 HashAPIKey），同时作为认证缓存与失效消息的标识
+	KeyHash     string
+	KeyPrefix   string
 	Name        string
 	GroupID     *int64
 	Status      string
@@ -59,6 +65,28 @@ type APIKey struct {
 	Window5hStart *time.Time // Start of current 5h window
 	Window1dStart *time.Time // Start of current 1d window
 	Window7dStart *time.Time // Start of current 7d window
+
+	// Rotation fields
+	// 轮换后旧 Key 摘要在 PreviousKeyExpiresAt 之前仍可认证
+	PreviousKeyHash      string
+	PreviousKeyPrefix    string
+	PreviousKeyExpiresAt *time.Time
+	RotatedAt            *time.Time
+
+	// Policy 访问策略（模型/端点/平台与请求上限），nil 表示不限制
+	Policy *APIKeyPolicy
+}
+
+// AuthKeyHashes 返回当前可用于认证的全部 Key 摘要（含宽限期内的旧 Key），用于缓存失效
+func (k *APIKey) AuthKeyHashes() []string {
+	hashes := make([]string, 0, 2)
+	if k.KeyHash != "" {
+		hashes = append(hashes, k.KeyHash)
+	}
+	if k.PreviousKeyHash != "" {
+		hashes = append(hashes, k.PreviousKeyHash)
+	}
+	return hashes
 }
 
 func (k *APIKey) IsActive() bool {
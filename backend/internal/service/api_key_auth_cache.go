@@ -24,15 +24,24 @@ type APIKeyAuthSnapshot struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Policy 访问策略，请求时由中间件与网关 handler 校验
+	Policy *APIKeyPolicy `json:"policy,omitempty"`
+
+	// SecretExpiresAt 命中的是轮换前的旧 Key 时，记录其宽限期截止时间，过期后缓存条目不再可用
+	SecretExpiresAt *time.Time `json:"secret_expires_at,omitempty"`
 }
 
 // APIKeyAuthUserSnapshot 用户快照
 type APIKeyAuthUserSnapshot struct {
-	ID          int64   `json:"id"`
-	Status      string  `json:"status"`
-	Role        string  `json:"role"`
-	Balance     float64 `json:"balance"`
-	Concurrency int     `json:"concurrency"`
+	ID            int64   `json:"id"`
+	Status        string  `json:"status"`
+	Role          string  `json:"role"`
+	Balance       float64 `json:"balance"`
+	Concurrency   int     `json:"concurrency"`
+	RPMLimit      int     `json:"rpm_limit,omitempty"`
+	TPMLimit      int     `json:"tpm_limit,omitempty"`
+	QueuePriority int     `json:"queue_priority,omitempty"`
 }
 
 // APIKeyAuthGroupSnapshot 分组快照
@@ -69,6 +78,40 @@ type APIKeyAuthGroupSnapshot struct {
 	// OpenAI Messages 调度配置（仅 openai 平台使用）
 	AllowMessagesDispatch bool   `json:"allow_messages_dispatch"`
 	DefaultMappedModel    string `json:"default_mapped_model,omitempty"`
+
+	// Key 共享防护：每个 API Key 每小时允许的不同来源 IP 数
+	MaxIPsPerKeyPerHour int `json:"max_ips_per_key_per_hour,omitempty"`
+
+	// 请求速率限制：分组内所有请求合计的 RPM/TPM
+	RPMLimit int `json:"rpm_limit,omitempty"`
+	TPMLimit int `json:"tpm_limit,omitempty"`
+
+	// 账号满载排队优先级
+	QueuePriority int `json:"queue_priority,omitempty"`
+
+	// 账号调度策略（空为平台默认）
+	SchedulingStrategy string `json:"scheduling_strategy,omitempty"`
+
+	// 模型降级链
+	ModelFallbackChains []ModelFallbackChain `json:"model_fallback_chains,omitempty"`
+
+	// 跨平台故障转移
+	CrossPlatformFailover bool `json:"cross_platform_failover,omitempty"`
+
+	// 影子流量镜像
+	ShadowMirror *ShadowMirrorConfig `json:"shadow_mirror,omitempty"`
+
+	// 网关响应缓存
+	ResponseCache *ResponseCacheConfig `json:"response_cache,omitempty"`
+
+	// 自动 prompt 缓存断点
+	AutoCacheBreakpoints bool `json:"auto_cache_breakpoints,omitempty"`
+
+	// 流式断线续传
+	StreamResume bool `json:"stream_resume,omitempty"`
+
+	// 上下文策略
+	ContextPolicy *ContextPolicyConfig `json:"context_policy,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
@@ -101,8 +101,10 @@ func (s *APIKeyService) StartAuthCacheInvalidationSubscriber(ctx context.Context
 	}
 }
 
-func (s *APIKeyService) authCacheKey(key string) string {
-	sum := sha256.Sum256([]byte(key))
+// authCacheKey 由 Key 摘要派生缓存键，使按用户/分组批量失效时无需明文。
+// 格式仍为 64 位十六进制，与 Redis 中的缓存条目及跨实例失效消息保持兼容。
+func (s *APIKeyService) authCacheKey(keyHash string) string {
+	sum := sha256.Sum256([]byte(keyHash))
 	return hex.EncodeToString(sum[:])
 }
 
@@ -160,8 +162,8 @@ func (s *APIKeyService) deleteAuthCache(ctx context.Context, cacheKey string) {
 	_ = s.cache.PublishAuthCacheInvalidation(ctx, cacheKey)
 }
 
-func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey string) (*APIKeyAuthCacheEntry, error) {
-	apiKey, err := s.apiKeyRepo.GetByKeyForAuth(ctx, key)
+func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, keyHash, cacheKey string) (*APIKeyAuthCacheEntry, error) {
+	apiKey, err := s.apiKeyRepo.GetByKeyForAuth(ctx, keyHash)
 	if err != nil {
 		if errors.Is(err, ErrAPIKeyNotFound) {
 			entry := &APIKeyAuthCacheEntry{NotFound: true}
@@ -172,17 +174,19 @@ func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey st
 		}
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
-	apiKey.Key = key
 	snapshot := s.snapshotFromAPIKey(apiKey)
 	if snapshot == nil {
 		return nil, fmt.Errorf("get api key: %w", ErrAPIKeyNotFound)
 	}
+	if apiKey.KeyHash != keyHash && apiKey.PreviousKeyHash == keyHash {
+		snapshot.SecretExpiresAt = apiKey.PreviousKeyExpiresAt
+	}
 	entry := &APIKeyAuthCacheEntry{Snapshot: snapshot}
 	s.setAuthCacheEntry(ctx, cacheKey, entry, s.authCfg.l2TTL)
 	return entry, nil
 }
 
-func (s *APIKeyService) applyAuthCacheEntry(key string, entry *APIKeyAuthCacheEntry) (*APIKey, bool, error) {
+func (s *APIKeyService) applyAuthCacheEntry(key, keyHash string, entry *APIKeyAuthCacheEntry) (*APIKey, bool, error) {
 	if entry == nil {
 		return nil, false, nil
 	}
@@ -192,7 +196,13 @@ func (s *APIKeyService) applyAuthCacheEntry(key string, entry *APIKeyAuthCacheEn
 	if entry.Snapshot == nil {
 		return nil, false, nil
 	}
-	return s.snapshotToAPIKey(key, entry.Snapshot), true, nil
+	// 轮换宽限期已过的旧 Key：即使缓存尚未失效也视为不存在
+	if expiresAt := entry.Snapshot.SecretExpiresAt; expiresAt != nil && !time.Now().Before(*expiresAt) {
+		return nil, true, ErrAPIKeyNotFound
+	}
+	apiKey := s.snapshotToAPIKey(key, entry.Snapshot)
+	apiKey.KeyHash = keyHash
+	return apiKey, true, nil
 }
 
 func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
@@ -212,12 +222,16 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 		RateLimit5h: apiKey.RateLimit5h,
 		RateLimit1d: apiKey.RateLimit1d,
 		RateLimit7d: apiKey.RateLimit7d,
+		Policy:      apiKey.Policy,
 		User: APIKeyAuthUserSnapshot{
-			ID:          apiKey.User.ID,
-			Status:      apiKey.User.Status,
-			Role:        apiKey.User.Role,
-			Balance:     apiKey.User.Balance,
-			Concurrency: apiKey.User.Concurrency,
+			ID:            apiKey.User.ID,
+			Status:        apiKey.User.Status,
+			Role:          apiKey.User.Role,
+			Balance:       apiKey.User.Balance,
+			Concurrency:   apiKey.User.Concurrency,
+			RPMLimit:      apiKey.User.RPMLimit,
+			TPMLimit:      apiKey.User.TPMLimit,
+			QueuePriority: apiKey.User.QueuePriority,
 		},
 	}
 	if apiKey.Group != nil {
@@ -247,6 +261,18 @@ func (s *APIKeyService) snapshotFromAPIKey(apiKey *APIKey) *APIKeyAuthSnapshot {
 			SupportedModelScopes:            apiKey.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           apiKey.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              apiKey.Group.DefaultMappedModel,
+			MaxIPsPerKeyPerHour:             apiKey.Group.MaxIPsPerKeyPerHour,
+			RPMLimit:                        apiKey.Group.RPMLimit,
+			TPMLimit:                        apiKey.Group.TPMLimit,
+			QueuePriority:                   apiKey.Group.QueuePriority,
+			SchedulingStrategy:              apiKey.Group.SchedulingStrategy,
+			ModelFallbackChains:             apiKey.Group.ModelFallbackChains,
+			CrossPlatformFailover:           apiKey.Group.CrossPlatformFailover,
+			ShadowMirror:                    apiKey.Group.ShadowMirror,
+			ResponseCache:                   apiKey.Group.ResponseCache,
+			AutoCacheBreakpoints:            apiKey.Group.AutoCacheBreakpoints,
+			StreamResume:                    apiKey.Group.StreamResume,
+			ContextPolicy:                   apiKey.Group.ContextPolicy,
 		}
 	}
 	return snapshot
@@ -270,12 +296,16 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		RateLimit5h: snapshot.RateLimit5h,
 		RateLimit1d: snapshot.RateLimit1d,
 		RateLimit7d: snapshot.RateLimit7d,
+		Policy:      snapshot.Policy,
 		User: &User{
-			ID:          snapshot.User.ID,
-			Status:      snapshot.User.Status,
-			Role:        snapshot.User.Role,
-			Balance:     snapshot.User.Balance,
-			Concurrency: snapshot.User.Concurrency,
+			ID:            snapshot.User.ID,
+			Status:        snapshot.User.Status,
+			Role:          snapshot.User.Role,
+			Balance:       snapshot.User.Balance,
+			Concurrency:   snapshot.User.Concurrency,
+			RPMLimit:      snapshot.User.RPMLimit,
+			TPMLimit:      snapshot.User.TPMLimit,
+			QueuePriority: snapshot.User.QueuePriority,
 		},
 	}
 	if snapshot.Group != nil {
@@ -306,6 +336,18 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			SupportedModelScopes:            snapshot.Group.SupportedModelScopes,
 			AllowMessagesDispatch:           snapshot.Group.AllowMessagesDispatch,
 			DefaultMappedModel:              snapshot.Group.DefaultMappedModel,
+			MaxIPsPerKeyPerHour:             snapshot.Group.MaxIPsPerKeyPerHour,
+			RPMLimit:                        snapshot.Group.RPMLimit,
+			TPMLimit:                        snapshot.Group.TPMLimit,
+			QueuePriority:                   snapshot.Group.QueuePriority,
+			SchedulingStrategy:              snapshot.Group.SchedulingStrategy,
+			ModelFallbackChains:             snapshot.Group.ModelFallbackChains,
+			CrossPlatformFailover:           snapshot.Group.CrossPlatformFailover,
+			ShadowMirror:                    snapshot.Group.ShadowMirror,
+			ResponseCache:                   snapshot.Group.ResponseCache,
+			AutoCacheBreakpoints:            snapshot.Group.AutoCacheBreakpoints,
+			StreamResume:                    snapshot.Group.StreamResume,
+			ContextPolicy:                   snapshot.Group.ContextPolicy,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
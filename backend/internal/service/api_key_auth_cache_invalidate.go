@@ -2,15 +2,23 @@ package service
 
 import "context"
 
-// InvalidateAuthCacheByKey 清除指定 API Key 的认证缓存
-func (s *APIKeyService) InvalidateAuthCacheByKey(ctx context.Context, key string) {
-	if key == "" {
+// InvalidateAuthCacheByKey 清除指定 API Key 的认证缓存（参数为存储的 Key 摘要）
+func (s *APIKeyService) InvalidateAuthCacheByKey(ctx context.Context, keyHash string) {
+	if keyHash == "" {
 		return
 	}
-	cacheKey := s.authCacheKey(key)
+	cacheKey := s.authCacheKey(keyHash)
 	s.deleteAuthCache(ctx, cacheKey)
 }
 
+// invalidateAuthCacheForAPIKey 清除 API Key 当前 Key 与宽限期内旧 Key 的认证缓存
+func (s *APIKeyService) invalidateAuthCacheForAPIKey(ctx context.Context, apiKey *APIKey) {
+	if apiKey == nil {
+		return
+	}
+	s.deleteAuthCacheByKeys(ctx, apiKey.AuthKeyHashes())
+}
+
 // InvalidateAuthCacheByUserID 清除用户相关的 API Key 认证缓存
 func (s *APIKeyService) InvalidateAuthCacheByUserID(ctx context.Context, userID int64) {
 	if userID <= 0 {
@@ -35,14 +43,14 @@ func (s *APIKeyService) InvalidateAuthCacheByGroupID(ctx context.Context, groupI
 	s.deleteAuthCacheByKeys(ctx, keys)
 }
 
-func (s *APIKeyService) deleteAuthCacheByKeys(ctx context.Context, keys []string) {
-	if len(keys) == 0 {
+func (s *APIKeyService) deleteAuthCacheByKeys(ctx context.Context, keyHashes []string) {
+	if len(keyHashes) == 0 {
 		return
 	}
-	for _, key := range keys {
-		if key == "" {
+	for _, keyHash := range keyHashes {
+		if keyHash == "" {
 			continue
 		}
-		s.deleteAuthCache(ctx, s.authCacheKey(key))
+		s.deleteAuthCache(ctx, s.authCacheKey(keyHash))
 	}
 }
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyHashPrefix 标记 api_keys.key 中存储的是摘要而非旧版明文。
+// 自定义 Key 只允许字母、数字、下划线与连字符，因此不会与该前缀冲突。
+const APIKeyHashPrefix = "hmac:"
+
+// apiKeyDisplayPrefixLen 列表中展示的明文前缀长度
+const apiKeyDisplayPrefixLen = 8
+
+// HashAPIKey 计算 API Key 的存储摘要：HMAC-SHA256(secret, key)。
+// 使用服务端密钥而非普通哈希，数据库泄露时无法离线撞库。
+func HashAPIKey(secret, key string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(key))
+	return APIKeyHashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsHashedAPIKey 判断存储值是否已是摘要（旧数据可能仍为明文，等待启动回填）
+func IsHashedAPIKey(stored string) bool {
+	return strings.HasPrefix(stored, APIKeyHashPrefix)
+}
+
+// APIKeyDisplayPrefix 返回用于列表展示的 Key 前缀
+func APIKeyDisplayPrefix(key string) string {
+	if len(key) <= apiKeyDisplayPrefixLen {
+		return key
+	}
+	return key[:apiKeyDisplayPrefixLen]
+}
+
+// hashKey 使用配置中的服务端密钥计算 Key 摘要
+func (s *APIKeyService) hashKey(key string) string {
+	secret := ""
+	if s.cfg != nil {
+		secret = s.cfg.Security.APIKeyHashSecret
+	}
+	return HashAPIKey(secret, key)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// APIKeyPolicy 单个 API Key 的访问策略
+type APIKeyPolicy = domain.APIKeyPolicy
+
+var ErrAPIKeyPolicyInvalid = domain.ErrAPIKeyPolicyInvalid
+
+var (
+	ErrAPIKeyEndpointNotAllowed   = infraerrors.Forbidden("API_KEY_ENDPOINT_NOT_ALLOWED", "this endpoint is not allowed for this API key")
+	ErrAPIKeyPlatformNotAllowed   = infraerrors.Forbidden("API_KEY_PLATFORM_NOT_ALLOWED", "this platform is not allowed for this API key")
+	ErrAPIKeyModelNotAllowed      = infraerrors.Forbidden("API_KEY_MODEL_NOT_ALLOWED", "this model is not allowed for this API key")
+	ErrAPIKeyThinkingNotAllowed   = infraerrors.Forbidden("API_KEY_THINKING_NOT_ALLOWED", "thinking is not allowed for this API key")
+	ErrAPIKeyToolsNotAllowed      = infraerrors.Forbidden("API_KEY_TOOLS_NOT_ALLOWED", "tools are not allowed for this API key")
+	ErrAPIKeyInputTokensExceeded  = infraerrors.BadRequest("API_KEY_INPUT_TOKENS_EXCEEDED", "input tokens exceed the limit of this API key")
+	ErrAPIKeyOutputTokensExceeded = infraerrors.BadRequest("API_KEY_OUTPUT_TOKENS_EXCEEDED", "max_tokens exceeds the limit of this API key")
+	ErrAPIKeyRPMExceeded          = infraerrors.TooManyRequests("API_KEY_RPM_EXCEEDED", "requests per minute limit of this API key exceeded")
+	ErrAPIKeyTPMExceeded          = infraerrors.TooManyRequests("API_KEY_TPM_EXCEEDED", "tokens per minute limit of this API key exceeded")
+)
+
+// APIKeyPolicyRequest 网关请求中与 Key 策略相关的特征
+type APIKeyPolicyRequest struct {
+	Model string
+	// InputTokens 估算的输入 token 数，0 表示按 body 延迟估算
+	InputTokens int
+	// MaxOutputTokens 请求的 max_tokens / max_output_tokens，0 表示未指定
+	MaxOutputTokens int
+	Thinking        bool
+	Tools           bool
+
+	// body 原始请求体，仅在策略配置了 MaxInputTokens 时用于估算输入 token
+	body []byte
+}
+
+// NewAPIKeyPolicyRequest 从请求体提取策略相关特征，兼容 Anthropic / OpenAI / Gemini 请求格式。
+// model 由调用方传入（Gemini 原生接口的模型来自路径）。
+func NewAPIKeyPolicyRequest(body []byte, model string) APIKeyPolicyRequest {
+	req := APIKeyPolicyRequest{Model: model, body: body}
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return req
+	}
+	root := gjson.ParseBytes(body)
+
+	for _, path := range []string{"max_tokens", "max_output_tokens", "max_completion_tokens", "generationConfig.maxOutputTokens"} {
+		if v := root.Get(path); v.Exists() {
+			req.MaxOutputTokens = int(v.Int())
+			break
+		}
+	}
+
+	switch {
+	case root.Get("thinking.type").String() == "enabled" || root.Get("thinking.type").String() == "adaptive":
+		req.Thinking = true
+	case reasoningEnabled(root.Get("reasoning.effort")) || reasoningEnabled(root.Get("reasoning_effort")):
+		req.Thinking = true
+	default:
+		thinkingConfig := root.Get("generationConfig.thinkingConfig")
+		if thinkingConfig.Exists() {
+			budget := thinkingConfig.Get("thinkingBudget")
+			req.Thinking = (budget.Exists() && budget.Int() != 0) ||
+				thinkingConfig.Get("includeThoughts").Bool() ||
+				thinkingConfig.Get("thinkingLevel").String() != ""
+		}
+	}
+
+	req.Tools = len(root.Get("tools").Array()) > 0 || len(root.Get("functions").Array()) > 0
+	return req
+}
+
+func reasoningEnabled(effort gjson.Result) bool {
+	if !effort.Exists() {
+		return false
+	}
+	value := strings.ToLower(strings.TrimSpace(effort.String()))
+	return value != "" && value != "none"
+}
+
+// estimatePolicyInputTokens 粗略估算请求中的文本 token 数（忽略图片等二进制内容）
+func estimatePolicyInputTokens(body []byte) int {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return 0
+	}
+	var sb strings.Builder
+	collectPolicyText(gjson.ParseBytes(body), "", &sb)
+	return estimateTokensForText(sb.String())
+}
+
+func collectPolicyText(value gjson.Result, key string, sb *strings.Builder) {
+	switch {
+	case value.IsObject():
+		value.ForEach(func(k, v gjson.Result) bool {
+			collectPolicyText(v, k.String(), sb)
+			return true
+		})
+	case value.IsArray():
+		value.ForEach(func(_, v gjson.Result) bool {
+			collectPolicyText(v, key, sb)
+			return true
+		})
+	case value.Type == gjson.String:
+		switch key {
+		case "text", "content", "input", "system", "instructions", "prompt", "arguments":
+			sb.WriteString(value.String())
+			sb.WriteByte('\n')
+		}
+	}
+}
+
+// normalizeAPIKeyPolicy 校验策略，空策略归一为 nil
+func normalizeAPIKeyPolicy(policy *APIKeyPolicy) (*APIKeyPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	normalized, err := policy.NormalizeAndValidate()
+	if err != nil {
+		return nil, err
+	}
+	if normalized.IsEmpty() {
+		return nil, nil
+	}
+	return &normalized, nil
+}
+
+// CheckPolicyAccess 在认证阶段校验端点与平台（RPM/TPM 由 RequestRateLimitService 统一校验）
+func (s *APIKeyService) CheckPolicyAccess(ctx context.Context, apiKey *APIKey, path, platform string) error {
+	if apiKey == nil || apiKey.Policy == nil {
+		return nil
+	}
+	policy := apiKey.Policy
+	if !policy.AllowsEndpoint(path) {
+		return policyError(ErrAPIKeyEndpointNotAllowed, "endpoint %s is not allowed for this API key", path)
+	}
+	if !policy.AllowsPlatform(platform) {
+		return policyError(ErrAPIKeyPlatformNotAllowed, "platform %s is not allowed for this API key", platform)
+	}
+	return nil
+}
+
+// CheckPolicyRequest 在网关 handler 解析请求体后校验模型、token 上限与 thinking/工具
+func (s *APIKeyService) CheckPolicyRequest(ctx context.Context, apiKey *APIKey, req APIKeyPolicyRequest) error {
+	if apiKey == nil || apiKey.Policy == nil {
+		return nil
+	}
+	return CheckAPIKeyPolicyLimits(apiKey.Policy, req)
+}
+
+// CheckAPIKeyPolicyLimits 校验策略中与计数器无关的部分：模型、thinking/工具与单次请求 token 上限
+func CheckAPIKeyPolicyLimits(policy *APIKeyPolicy, req APIKeyPolicyRequest) error {
+	if policy == nil {
+		return nil
+	}
+	if req.Model != "" && !policy.AllowsModel(req.Model) {
+		return policyError(ErrAPIKeyModelNotAllowed, "model %s is not allowed for this API key", req.Model)
+	}
+	if policy.DenyThinking && req.Thinking {
+		return ErrAPIKeyThinkingNotAllowed
+	}
+	if policy.DenyTools && req.Tools {
+		return ErrAPIKeyToolsNotAllowed
+	}
+	if policy.MaxOutputTokens > 0 && req.MaxOutputTokens > policy.MaxOutputTokens {
+		return policyError(ErrAPIKeyOutputTokensExceeded, "max_tokens %d exceeds the limit of this API key (%d)", req.MaxOutputTokens, policy.MaxOutputTokens)
+	}
+	if policy.MaxInputTokens > 0 {
+		inputTokens := req.InputTokens
+		if inputTokens == 0 {
+			inputTokens = estimatePolicyInputTokens(req.body)
+		}
+		if inputTokens > policy.MaxInputTokens {
+			return policyError(ErrAPIKeyInputTokensExceeded, "input tokens (~%d) exceed the limit of this API key (%d)", inputTokens, policy.MaxInputTokens)
+		}
+	}
+	return nil
+}
+
+// policyError 保留错误码与 reason（errors.Is 仍可匹配），替换为包含具体取值的提示
+func policyError(base *infraerrors.ApplicationError, format string, args ...any) error {
+	return infraerrors.New(int(base.Code), base.Reason, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,66 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKeyPolicyRequest_ExtractsAcrossProtocols(t *testing.T) {
+	anthropic := NewAPIKeyPolicyRequest([]byte(`{"model":"claude-sonnet-4-5","max_tokens":2048,"thinking":{"type":"enabled","budget_tokens":1024},"tools":[{"name":"get_weather"}]}`), "claude-sonnet-4-5")
+	require.Equal(t, 2048, anthropic.MaxOutputTokens)
+	require.True(t, anthropic.Thinking)
+	require.True(t, anthropic.Tools)
+
+	openai := NewAPIKeyPolicyRequest([]byte(`{"model":"gpt-5","max_output_tokens":512,"reasoning":{"effort":"none"},"tools":[]}`), "gpt-5")
+	require.Equal(t, 512, openai.MaxOutputTokens)
+	require.False(t, openai.Thinking)
+	require.False(t, openai.Tools)
+
+	gemini := NewAPIKeyPolicyRequest([]byte(`{"contents":[],"generationConfig":{"maxOutputTokens":128,"thinkingConfig":{"thinkingBudget":-1}}}`), "gemini-2.5-pro")
+	require.Equal(t, "gemini-2.5-pro", gemini.Model)
+	require.Equal(t, 128, gemini.MaxOutputTokens)
+	require.True(t, gemini.Thinking)
+}
+
+func TestAPIKeyService_CheckPolicyRequest(t *testing.T) {
+	svc := &APIKeyService{}
+	apiKey := &APIKey{ID: 1, Policy: &APIKeyPolicy{
+		AllowedModels:   []string{"claude-*"},
+		DeniedModels:    []string{"claude-opus-*"},
+		MaxOutputTokens: 1000,
+		MaxInputTokens:  50,
+		DenyThinking:    true,
+		DenyTools:       true,
+	}}
+	ctx := context.Background()
+
+	require.NoError(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "claude-sonnet-4-5", MaxOutputTokens: 1000}))
+	require.ErrorIs(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "claude-opus-4-1"}), ErrAPIKeyModelNotAllowed)
+	require.ErrorIs(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "gpt-5"}), ErrAPIKeyModelNotAllowed)
+	require.ErrorIs(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "claude-haiku-4-5", Thinking: true}), ErrAPIKeyThinkingNotAllowed)
+	require.ErrorIs(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "claude-haiku-4-5", Tools: true}), ErrAPIKeyToolsNotAllowed)
+	require.ErrorIs(t, svc.CheckPolicyRequest(ctx, apiKey, APIKeyPolicyRequest{Model: "claude-haiku-4-5", MaxOutputTokens: 1001}), ErrAPIKeyOutputTokensExceeded)
+
+	longPrompt := `{"model":"claude-haiku-4-5","messages":[{"role":"user","content":"` + strings.Repeat("hello ", 100) + `"}]}`
+	err := svc.CheckPolicyRequest(ctx, apiKey, NewAPIKeyPolicyRequest([]byte(longPrompt), "claude-haiku-4-5"))
+	require.ErrorIs(t, err, ErrAPIKeyInputTokensExceeded)
+
+	// 未配置策略的 Key 不做任何检查
+	require.NoError(t, svc.CheckPolicyRequest(ctx, &APIKey{ID: 2}, APIKeyPolicyRequest{Model: "anything", Tools: true}))
+}
+
+func TestAPIKeyService_CreateRejectsInvalidPolicy(t *testing.T) {
+	repo := &authRepoStub{}
+	svc := &APIKeyService{apiKeyRepo: repo, userRepo: &userRepoStub{user: &User{ID: 1}}}
+
+	_, err := svc.Create(context.Background(), 1, CreateAPIKeyRequest{
+		Name:   "k",
+		Policy: &APIKeyPolicy{AllowedPlatforms: []string{"unknown"}},
+	})
+	require.ErrorIs(t, err, ErrAPIKeyPolicyInvalid)
+}
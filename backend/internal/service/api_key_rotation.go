@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrAPIKeyRotationGraceInvalid = infraerrors.BadRequest("API_KEY_ROTATION_GRACE_INVALID", "grace_hours is out of the allowed range")
+	ErrAPIKeyRotationConflict     = infraerrors.Conflict("API_KEY_ROTATION_CONFLICT", "api key was rotated concurrently, please retry")
+)
+
+// 未加载配置时（如单元测试）使用的宽限期默认值，与 config 默认值保持一致
+const (
+	defaultAPIKeyRotationGraceHours    = 24
+	defaultAPIKeyRotationMaxGraceHours = 720
+)
+
+// RotateAPIKeyRequest 轮换 API Key 请求
+type RotateAPIKeyRequest struct {
+	// GraceHours 旧 Key 的宽限期（小时）；nil 使用配置默认值，0 表示旧 Key 立即失效
+	GraceHours *int `json:"grace_hours"`
+}
+
+// APIKeyRotation 一次轮换写入的数据。
+// ID、分组、额度、限流窗口与用量历史均保持不变，仅替换 Key 摘要。
+type APIKeyRotation struct {
+	ID               int64
+	CurrentKeyHash   string
+	CurrentKeyPrefix string
+	NewKeyHash       string
+	NewKeyPrefix     string
+	// PreviousExpiresAt 旧 Key 宽限期截止时间，nil 表示不保留旧 Key
+	PreviousExpiresAt *time.Time
+	RotatedAt         time.Time
+}
+
+// Rotate 轮换用户自己的 API Key，返回的 APIKey.Key 为新 Key 明文（仅此一次）
+func (s *APIKeyService) Rotate(ctx context.Context, id int64, userID int64, req RotateAPIKeyRequest) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	if apiKey.UserID != userID {
+		return nil, ErrInsufficientPerms
+	}
+	return s.rotate(ctx, apiKey, req)
+}
+
+// AdminRotate 管理员轮换任意 API Key
+func (s *APIKeyService) AdminRotate(ctx context.Context, id int64, req RotateAPIKeyRequest) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	return s.rotate(ctx, apiKey, req)
+}
+
+func (s *APIKeyService) rotate(ctx context.Context, apiKey *APIKey, req RotateAPIKeyRequest) (*APIKey, error) {
+	grace, err := s.rotationGrace(req.GraceHours)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	now := time.Now()
+	rotation := APIKeyRotation{
+		ID:               apiKey.ID,
+		CurrentKeyHash:   apiKey.KeyHash,
+		CurrentKeyPrefix: apiKey.KeyPrefix,
+		NewKeyHash:       s.hashKey(key),
+		NewKeyPrefix:     APIKeyDisplayPrefix(key),
+		RotatedAt:        now,
+	}
+	if grace > 0 {
+		expiresAt := now.Add(grace)
+		rotation.PreviousExpiresAt = &expiresAt
+	}
+	if err := s.apiKeyRepo.Rotate(ctx, rotation); err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+
+	// 旧 Key 的缓存快照没有宽限期信息，被替换的更早一代旧 Key 也需立即失效
+	s.invalidateAuthCacheForAPIKey(ctx, apiKey)
+	s.InvalidateAuthCacheByKey(ctx, rotation.NewKeyHash)
+
+	apiKey.Key = key
+	apiKey.KeyHash = rotation.NewKeyHash
+	apiKey.KeyPrefix = rotation.NewKeyPrefix
+	apiKey.RotatedAt = &now
+	apiKey.UpdatedAt = now
+	if rotation.PreviousExpiresAt != nil {
+		apiKey.PreviousKeyHash = rotation.CurrentKeyHash
+		apiKey.PreviousKeyPrefix = rotation.CurrentKeyPrefix
+		apiKey.PreviousKeyExpiresAt = rotation.PreviousExpiresAt
+	} else {
+		apiKey.PreviousKeyHash = ""
+		apiKey.PreviousKeyPrefix = ""
+		apiKey.PreviousKeyExpiresAt = nil
+	}
+	s.compileAPIKeyIPRules(apiKey)
+	return apiKey, nil
+}
+
+// rotationGrace 解析旧 Key 宽限期，nil 时使用配置默认值
+func (s *APIKeyService) rotationGrace(graceHours *int) (time.Duration, error) {
+	defaultHours, maxHours := defaultAPIKeyRotationGraceHours, defaultAPIKeyRotationMaxGraceHours
+	if s.cfg != nil {
+		defaultHours, maxHours = s.cfg.APIKeyRotation.DefaultGraceHours, s.cfg.APIKeyRotation.MaxGraceHours
+	}
+	hours := defaultHours
+	if graceHours != nil {
+		hours = *graceHours
+	}
+	if hours < 0 || hours > maxHours {
+		return 0, ErrAPIKeyRotationGraceInvalid
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
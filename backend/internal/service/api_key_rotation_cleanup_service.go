@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// APIKeyRotationCleanupService periodically revokes previous API key secrets whose grace period has ended.
+type APIKeyRotationCleanupService struct {
+	apiKeyRepo  APIKeyRepository
+	invalidator APIKeyAuthCacheInvalidator
+	interval    time.Duration
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+}
+
+func NewAPIKeyRotationCleanupService(apiKeyRepo APIKeyRepository, invalidator APIKeyAuthCacheInvalidator, interval time.Duration) *APIKeyRotationCleanupService {
+	return &APIKeyRotationCleanupService{
+		apiKeyRepo:  apiKeyRepo,
+		invalidator: invalidator,
+		interval:    interval,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+func (s *APIKeyRotationCleanupService) Start() {
+	if s == nil || s.apiKeyRepo == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *APIKeyRotationCleanupService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *APIKeyRotationCleanupService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cleared, err := s.apiKeyRepo.ClearExpiredPreviousKeys(ctx, time.Now())
+	if err != nil {
+		log.Printf("[APIKeyRotation] Clear expired previous keys failed: %v", err)
+		return
+	}
+	// 认证缓存快照自带宽限期截止时间，这里失效只是尽快释放缓存条目
+	if s.invalidator != nil {
+		for _, keyHash := range cleared {
+			s.invalidator.InvalidateAuthCacheByKey(ctx, keyHash)
+		}
+	}
+	if len(cleared) > 0 {
+		log.Printf("[APIKeyRotation] Revoked %d expired previous keys", len(cleared))
+	}
+}
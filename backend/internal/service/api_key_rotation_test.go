@@ -0,0 +1,160 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type rotationRepoStub struct {
+	authRepoStub
+	apiKey    *APIKey
+	rotations []APIKeyRotation
+	rotateErr error
+}
+
+func (s *rotationRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error) {
+	if s.apiKey == nil || s.apiKey.ID != id {
+		return nil, ErrAPIKeyNotFound
+	}
+	clone := *s.apiKey
+	return &clone, nil
+}
+
+func (s *rotationRepoStub) Rotate(ctx context.Context, rotation APIKeyRotation) error {
+	if s.rotateErr != nil {
+		return s.rotateErr
+	}
+	s.rotations = append(s.rotations, rotation)
+	return nil
+}
+
+func newRotationTestService(repo APIKeyRepository, cache APIKeyCache) *APIKeyService {
+	cfg := &config.Config{
+		Security:   config.SecurityConfig{APIKeyHashSecret: "rotation-test-secret-0123456789abcdef"},
+		APIKeyAuth: config.APIKeyAuthCacheConfig{L2TTLSeconds: 60},
+		APIKeyRotation: config.APIKeyRotationConfig{
+			DefaultGraceHours: 24,
+			MaxGraceHours:     72,
+		},
+	}
+	return NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+}
+
+func TestAPIKeyService_Rotate_KeepsPreviousKeyDuringGrace(t *testing.T) {
+	repo := &rotationRepoStub{apiKey: &APIKey{
+		ID:                11,
+		UserID:            5,
+		KeyHash:           "hmac:current",
+		KeyPrefix:         "sk-curre",
+		PreviousKeyHash:   "hmac:older",
+		PreviousKeyPrefix: "sk-older",
+		Quota:             10,
+		QuotaUsed:         3,
+	}}
+	cache := &authCacheStub{}
+	svc := newRotationTestService(repo, cache)
+
+	before := time.Now()
+	rotated, err := svc.Rotate(context.Background(), 11, 5, RotateAPIKeyRequest{})
+	require.NoError(t, err)
+	require.Len(t, repo.rotations, 1)
+
+	rotation := repo.rotations[0]
+	require.Equal(t, int64(11), rotation.ID)
+	require.Equal(t, "hmac:current", rotation.CurrentKeyHash)
+	require.Equal(t, svc.hashKey(rotated.Key), rotation.NewKeyHash)
+	require.Equal(t, APIKeyDisplayPrefix(rotated.Key), rotation.NewKeyPrefix)
+	require.NotNil(t, rotation.PreviousExpiresAt)
+	require.WithinDuration(t, before.Add(24*time.Hour), *rotation.PreviousExpiresAt, time.Minute)
+
+	require.Equal(t, int64(11), rotated.ID)
+	require.Equal(t, 3.0, rotated.QuotaUsed)
+	require.Equal(t, rotation.NewKeyHash, rotated.KeyHash)
+	require.Equal(t, "hmac:current", rotated.PreviousKeyHash)
+	require.Equal(t, "sk-curre", rotated.PreviousKeyPrefix)
+	require.NotNil(t, rotated.RotatedAt)
+
+	// 当前 Key、被替换的更早旧 Key 与新 Key 的缓存均需失效
+	require.ElementsMatch(t, []string{
+		svc.authCacheKey("hmac:current"),
+		svc.authCacheKey("hmac:older"),
+		svc.authCacheKey(rotation.NewKeyHash),
+	}, cache.deleteAuthKeys)
+}
+
+func TestAPIKeyService_Rotate_ZeroGraceRevokesImmediately(t *testing.T) {
+	repo := &rotationRepoStub{apiKey: &APIKey{ID: 12, UserID: 5, KeyHash: "hmac:current"}}
+	svc := newRotationTestService(repo, &authCacheStub{})
+
+	zero := 0
+	rotated, err := svc.AdminRotate(context.Background(), 12, RotateAPIKeyRequest{GraceHours: &zero})
+	require.NoError(t, err)
+	require.Len(t, repo.rotations, 1)
+	require.Nil(t, repo.rotations[0].PreviousExpiresAt)
+	require.Empty(t, rotated.PreviousKeyHash)
+	require.Nil(t, rotated.PreviousKeyExpiresAt)
+}
+
+func TestAPIKeyService_Rotate_Rejections(t *testing.T) {
+	repo := &rotationRepoStub{apiKey: &APIKey{ID: 13, UserID: 5, KeyHash: "hmac:current"}}
+	svc := newRotationTestService(repo, &authCacheStub{})
+
+	_, err := svc.Rotate(context.Background(), 13, 6, RotateAPIKeyRequest{})
+	require.ErrorIs(t, err, ErrInsufficientPerms)
+
+	tooLong := 73
+	_, err = svc.Rotate(context.Background(), 13, 5, RotateAPIKeyRequest{GraceHours: &tooLong})
+	require.ErrorIs(t, err, ErrAPIKeyRotationGraceInvalid)
+
+	negative := -1
+	_, err = svc.Rotate(context.Background(), 13, 5, RotateAPIKeyRequest{GraceHours: &negative})
+	require.ErrorIs(t, err, ErrAPIKeyRotationGraceInvalid)
+
+	repo.rotateErr = ErrAPIKeyRotationConflict
+	_, err = svc.Rotate(context.Background(), 13, 5, RotateAPIKeyRequest{})
+	require.ErrorIs(t, err, ErrAPIKeyRotationConflict)
+	require.Empty(t, repo.rotations)
+}
+
+func TestAPIKeyService_GetByKey_PreviousKeyExpiresFromCache(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	cache := &authCacheStub{}
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, keyHash string) (*APIKey, error) {
+			return &APIKey{
+				ID:                   14,
+				UserID:               5,
+				Status:               StatusActive,
+				KeyHash:              "hmac:new",
+				PreviousKeyHash:      keyHash,
+				PreviousKeyExpiresAt: &expiresAt,
+				User:                 &User{ID: 5, Status: StatusActive, Role: RoleUser},
+			}, nil
+		},
+	}
+	svc := newRotationTestService(repo, cache)
+
+	apiKey, err := svc.GetByKey(context.Background(), "sk-old-secret")
+	require.NoError(t, err)
+	require.Equal(t, int64(14), apiKey.ID)
+	require.Len(t, cache.setAuthKeys, 1)
+
+	// 旧 Key 的缓存快照带有宽限期截止时间
+	stored, err := svc.loadAuthCacheEntry(context.Background(), svc.hashKey("sk-old-secret"), cache.setAuthKeys[0])
+	require.NoError(t, err)
+	require.NotNil(t, stored.Snapshot.SecretExpiresAt)
+	require.True(t, stored.Snapshot.SecretExpiresAt.Equal(expiresAt))
+
+	// 宽限期结束后，即使缓存条目仍在也拒绝认证
+	past := time.Now().Add(-time.Second)
+	stored.Snapshot.SecretExpiresAt = &past
+	_, used, err := svc.applyAuthCacheEntry("sk-old-secret", svc.hashKey("sk-old-secret"), stored)
+	require.True(t, used)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
@@ -26,6 +26,8 @@ var (
 	ErrAPIKeyInvalidChars = infraerrors.BadRequest("API_KEY_INVALID_CHARS", "api key can only contain letters, numbers, underscores, and hyphens")
 	ErrAPIKeyRateLimited  = infraerrors.TooManyRequests("API_KEY_RATE_LIMITED", "too many failed attempts, please try again later")
 	ErrInvalidIPPattern   = infraerrors.BadRequest("INVALID_IP_PATTERN", "invalid IP or CIDR pattern")
+	ErrAPIKeySuspended    = infraerrors.Forbidden("API_KEY_SUSPENDED", "api key is suspended due to abnormal usage, please contact the administrator")
+	ErrAPIKeyNotSuspended = infraerrors.Conflict("API_KEY_NOT_SUSPENDED", "api key is not suspended")
 	// ErrAPIKeyExpired        = infraerrors.Forbidden("API_KEY_EXPIRED", "api key has expired")
 	ErrAPIKeyExpired = infraerrors.Forbidden("API_KEY_EXPIRED", "api key 已过期")
 	// ErrAPIKeyQuotaExhausted = infraerrors.TooManyRequests("API_KEY_QUOTA_EXHAUSTED", "api key quota exhausted")
@@ -47,25 +49,33 @@ const (
 type APIKeyRepository interface {
 	Create(ctx context.Context, key *APIKey) error
 	GetByID(ctx context.Context, id int64) (*APIKey, error)
-	// GetKeyAndOwnerID 仅获取 API Key 的 key 与所有者 ID，用于删除等轻量场景
-	GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error)
-	GetByKey(ctx context.Context, key string) (*APIKey, error)
+	// GetKeyAndOwnerID 仅获取 API Key 的摘要（含宽限期内的旧 Key 摘要）与所有者 ID，用于删除等轻量场景
+	GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error)
+	// 以下按 key 查询/返回的方法均使用存储的 Key 摘要（见 HashAPIKey），而非明文
+	GetByKey(ctx context.Context, keyHash string) (*APIKey, error)
 	// GetByKeyForAuth 认证专用查询，返回最小字段集
-	GetByKeyForAuth(ctx context.Context, key string) (*APIKey, error)
+	GetByKeyForAuth(ctx context.Context, keyHash string) (*APIKey, error)
 	Update(ctx context.Context, key *APIKey) error
 	Delete(ctx context.Context, id int64) error
 
 	ListByUserID(ctx context.Context, userID int64, params pagination.PaginationParams, filters APIKeyListFilters) ([]APIKey, *pagination.PaginationResult, error)
 	VerifyOwnership(ctx context.Context, userID int64, apiKeyIDs []int64) ([]int64, error)
 	CountByUserID(ctx context.Context, userID int64) (int64, error)
-	ExistsByKey(ctx context.Context, key string) (bool, error)
+	ExistsByKey(ctx context.Context, keyHash string) (bool, error)
 	ListByGroupID(ctx context.Context, groupID int64, params pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error)
 	SearchAPIKeys(ctx context.Context, userID int64, keyword string, limit int) ([]APIKey, error)
 	ClearGroupIDByGroupID(ctx context.Context, groupID int64) (int64, error)
 	CountByGroupID(ctx context.Context, groupID int64) (int64, error)
+	// ListKeysByUserID / ListKeysByGroupID 返回的摘要包含宽限期内尚未清除的旧 Key
 	ListKeysByUserID(ctx context.Context, userID int64) ([]string, error)
 	ListKeysByGroupID(ctx context.Context, groupID int64) ([]string, error)
 
+	// Rotation methods
+	// Rotate 以条件更新替换 Key 摘要（仅当当前摘要仍为 CurrentKeyHash），旧摘要转入 previous_key
+	Rotate(ctx context.Context, rotation APIKeyRotation) error
+	// ClearExpiredPreviousKeys 清除宽限期已过的旧 Key，返回被清除的摘要用于缓存失效
+	ClearExpiredPreviousKeys(ctx context.Context, now time.Time) ([]string, error)
+
 	// Quota methods
 	IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error)
 	UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error
@@ -130,7 +140,7 @@ type APIKeyCache interface {
 
 // APIKeyAuthCacheInvalidator 提供认证缓存失效能力
 type APIKeyAuthCacheInvalidator interface {
-	InvalidateAuthCacheByKey(ctx context.Context, key string)
+	InvalidateAuthCacheByKey(ctx context.Context, keyHash string)
 	InvalidateAuthCacheByUserID(ctx context.Context, userID int64)
 	InvalidateAuthCacheByGroupID(ctx context.Context, groupID int64)
 }
@@ -151,6 +161,9 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// Policy 访问策略（nil 表示不限制）
+	Policy *APIKeyPolicy `json:"policy"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
@@ -172,6 +185,14 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// Policy 访问策略（nil = no change，空对象清除策略）
+	Policy *APIKeyPolicy `json:"policy"`
+}
+
+// APIKeyIPLimiter enforces the per-group cap on distinct client IPs per key.
+type APIKeyIPLimiter interface {
+	CheckIPLimit(ctx context.Context, apiKey *APIKey, clientIP string) error
 }
 
 // APIKeyService API Key服务
@@ -188,6 +209,8 @@ type APIKeyService struct {
 	userGroupRateRepo     UserGroupRateRepository
 	cache                 APIKeyCache
 	rateLimitCacheInvalid RateLimitCacheInvalidator // optional: invalidate Redis rate limit cache
+	ipLimiter             APIKeyIPLimiter           // optional: per-group distinct IP cap
+	rateLimiter           *RequestRateLimitService  // optional: key/user/group RPM/TPM sliding-window limits
 	cfg                   *config.Config
 	authCacheL1           *ristretto.Cache
 	authCfg               apiKeyAuthCacheConfig
@@ -225,6 +248,19 @@ func (s *APIKeyService) SetRateLimitCacheInvalidator(inv RateLimitCacheInvalidat
 	s.rateLimitCacheInvalid = inv
 }
 
+// SetIPLimiter sets the optional per-group distinct client IP limiter.
+func (s *APIKeyService) SetIPLimiter(limiter APIKeyIPLimiter) {
+	s.ipLimiter = limiter
+}
+
+// CheckIPLimit 校验分组级每 Key 每小时来源 IP 上限，未配置限制器时放行
+func (s *APIKeyService) CheckIPLimit(ctx context.Context, apiKey *APIKey, clientIP string) error {
+	if s.ipLimiter == nil {
+		return nil
+	}
+	return s.ipLimiter.CheckIPLimit(ctx, apiKey, clientIP)
+}
+
 func (s *APIKeyService) compileAPIKeyIPRules(apiKey *APIKey) {
 	if apiKey == nil {
 		return
@@ -335,6 +371,11 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	policy, err := normalizeAPIKeyPolicy(req.Policy)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -363,7 +404,7 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 
 		// 检查Key是否已存在
-		exists, err := s.apiKeyRepo.ExistsByKey(ctx, *req.CustomKey)
+		exists, err := s.apiKeyRepo.ExistsByKey(ctx, s.hashKey(*req.CustomKey))
 		if err != nil {
 			return nil, fmt.Errorf("check key exists: %w", err)
 		}
@@ -383,10 +424,12 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
-	// 创建API Key记录
+	// 创建API Key记录：仅持久化摘要与展示前缀，明文只随本次响应返回
 	apiKey := &APIKey{
 		UserID:      userID,
 		Key:         key,
+		KeyHash:     s.hashKey(key),
+		KeyPrefix:   APIKeyDisplayPrefix(key),
 		Name:        req.Name,
 		GroupID:     req.GroupID,
 		Status:      StatusActive,
@@ -397,6 +440,7 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		RateLimit5h: req.RateLimit5h,
 		RateLimit1d: req.RateLimit1d,
 		RateLimit7d: req.RateLimit7d,
+		Policy:      policy,
 	}
 
 	// Set expiration time if specified
@@ -409,7 +453,7 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		return nil, fmt.Errorf("create api key: %w", err)
 	}
 
-	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.invalidateAuthCacheForAPIKey(ctx, apiKey)
 	s.compileAPIKeyIPRules(apiKey)
 
 	return apiKey, nil
@@ -448,10 +492,11 @@ func (s *APIKeyService) GetByID(ctx context.Context, id int64) (*APIKey, error)
 
 // GetByKey 根据Key字符串获取API Key（用于认证）
 func (s *APIKeyService) GetByKey(ctx context.Context, key string) (*APIKey, error) {
-	cacheKey := s.authCacheKey(key)
+	keyHash := s.hashKey(key)
+	cacheKey := s.authCacheKey(keyHash)
 
 	if entry, ok := s.getAuthCacheEntry(ctx, cacheKey); ok {
-		if apiKey, used, err := s.applyAuthCacheEntry(key, entry); used {
+		if apiKey, used, err := s.applyAuthCacheEntry(key, keyHash, entry); used {
 			if err != nil {
 				return nil, fmt.Errorf("get api key: %w", err)
 			}
@@ -462,13 +507,13 @@ func (s *APIKeyService) GetByKey(ctx context.Context, key string) (*APIKey, erro
 
 	if s.authCfg.singleflight {
 		value, err, _ := s.authGroup.Do(cacheKey, func() (any, error) {
-			return s.loadAuthCacheEntry(ctx, key, cacheKey)
+			return s.loadAuthCacheEntry(ctx, keyHash, cacheKey)
 		})
 		if err != nil {
 			return nil, err
 		}
 		entry, _ := value.(*APIKeyAuthCacheEntry)
-		if apiKey, used, err := s.applyAuthCacheEntry(key, entry); used {
+		if apiKey, used, err := s.applyAuthCacheEntry(key, keyHash, entry); used {
 			if err != nil {
 				return nil, fmt.Errorf("get api key: %w", err)
 			}
@@ -476,11 +521,11 @@ func (s *APIKeyService) GetByKey(ctx context.Context, key string) (*APIKey, erro
 			return apiKey, nil
 		}
 	} else {
-		entry, err := s.loadAuthCacheEntry(ctx, key, cacheKey)
+		entry, err := s.loadAuthCacheEntry(ctx, keyHash, cacheKey)
 		if err != nil {
 			return nil, err
 		}
-		if apiKey, used, err := s.applyAuthCacheEntry(key, entry); used {
+		if apiKey, used, err := s.applyAuthCacheEntry(key, keyHash, entry); used {
 			if err != nil {
 				return nil, fmt.Errorf("get api key: %w", err)
 			}
@@ -489,11 +534,12 @@ func (s *APIKeyService) GetByKey(ctx context.Context, key string) (*APIKey, erro
 		}
 	}
 
-	apiKey, err := s.apiKeyRepo.GetByKeyForAuth(ctx, key)
+	apiKey, err := s.apiKeyRepo.GetByKeyForAuth(ctx, keyHash)
 	if err != nil {
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 	apiKey.Key = key
+	apiKey.KeyHash = keyHash
 	s.compileAPIKeyIPRules(apiKey)
 	return apiKey, nil
 }
@@ -524,6 +570,14 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		}
 	}
 
+	if req.Policy != nil {
+		policy, err := normalizeAPIKeyPolicy(req.Policy)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.Policy = policy
+	}
+
 	// 更新字段
 	if req.Name != nil {
 		apiKey.Name = *req.Name
@@ -549,6 +603,10 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	}
 
 	if req.Status != nil {
+		// 异常停用只能由管理员解除
+		if apiKey.Status == StatusAPIKeySuspended && *req.Status != StatusAPIKeySuspended {
+			return nil, ErrAPIKeySuspended
+		}
 		apiKey.Status = *req.Status
 		// 如果状态改变，清除Redis缓存
 		if s.cache != nil {
@@ -613,7 +671,7 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		return nil, fmt.Errorf("update api key: %w", err)
 	}
 
-	s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	s.invalidateAuthCacheForAPIKey(ctx, apiKey)
 	s.compileAPIKeyIPRules(apiKey)
 
 	// Invalidate Redis rate limit cache so reset takes effect immediately
@@ -626,7 +684,7 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 
 // Delete 删除API Key
 func (s *APIKeyService) Delete(ctx context.Context, id int64, userID int64) error {
-	key, ownerID, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, id)
+	keyHashes, ownerID, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("get api key: %w", err)
 	}
@@ -640,7 +698,7 @@ func (s *APIKeyService) Delete(ctx context.Context, id int64, userID int64) erro
 	if s.cache != nil {
 		_ = s.cache.DeleteCreateAttemptCount(ctx, userID)
 	}
-	s.InvalidateAuthCacheByKey(ctx, key)
+	s.deleteAuthCacheByKeys(ctx, keyHashes)
 
 	if err := s.apiKeyRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("delete api key: %w", err)
@@ -836,12 +894,46 @@ func (s *APIKeyService) UpdateQuotaUsed(ctx context.Context, apiKeyID int64, cos
 			return nil // Don't fail the request
 		}
 		// Invalidate cache so next request sees the new status
-		s.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+		s.invalidateAuthCacheForAPIKey(ctx, apiKey)
 	}
 
 	return nil
 }
 
+// Suspend 将 API Key 置为 suspended（费用异常自动停用），已停用时返回 false
+func (s *APIKeyService) Suspend(ctx context.Context, apiKeyID int64) (*APIKey, bool, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, apiKeyID)
+	if err != nil {
+		return nil, false, err
+	}
+	if apiKey.Status == StatusAPIKeySuspended {
+		return apiKey, false, nil
+	}
+	apiKey.Status = StatusAPIKeySuspended
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, false, fmt.Errorf("suspend api key: %w", err)
+	}
+	s.invalidateAuthCacheForAPIKey(ctx, apiKey)
+	return apiKey, true, nil
+}
+
+// Unsuspend 管理员解除异常停用，恢复为 active
+func (s *APIKeyService) Unsuspend(ctx context.Context, apiKeyID int64) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.Status != StatusAPIKeySuspended {
+		return nil, ErrAPIKeyNotSuspended
+	}
+	apiKey.Status = StatusAPIKeyActive
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("unsuspend api key: %w", err)
+	}
+	s.invalidateAuthCacheForAPIKey(ctx, apiKey)
+	return apiKey, nil
+}
+
 // GetRateLimitData returns rate limit usage and window state for an API key.
 func (s *APIKeyService) GetRateLimitData(ctx context.Context, id int64) (*APIKeyRateLimitData, error) {
 	return s.apiKeyRepo.GetRateLimitData(ctx, id)
@@ -30,7 +30,7 @@ func (s *authRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error) {
 	panic("unexpected GetByID call")
 }
 
-func (s *authRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
+func (s *authRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error) {
 	panic("unexpected GetKeyAndOwnerID call")
 }
 
@@ -99,6 +99,14 @@ func (s *authRepoStub) ListKeysByGroupID(ctx context.Context, groupID int64) ([]
 	return s.listKeysByGroupID(ctx, groupID)
 }
 
+func (s *authRepoStub) Rotate(ctx context.Context, rotation APIKeyRotation) error {
+	panic("unexpected Rotate call")
+}
+
+func (s *authRepoStub) ClearExpiredPreviousKeys(ctx context.Context, now time.Time) ([]string, error) {
+	panic("unexpected ClearExpiredPreviousKeys call")
+}
+
 func (s *authRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -312,7 +320,7 @@ func TestAPIKeyService_GetByKey_UsesL1Cache(t *testing.T) {
 	_, err := svc.GetByKey(context.Background(), "k-l1")
 	require.NoError(t, err)
 	svc.authCacheL1.Wait()
-	cacheKey := svc.authCacheKey("k-l1")
+	cacheKey := svc.authCacheKey(svc.hashKey("k-l1"))
 	_, ok := svc.authCacheL1.Get(cacheKey)
 	require.True(t, ok)
 	_, err = svc.GetByKey(context.Background(), "k-l1")
@@ -375,6 +383,43 @@ func TestAPIKeyService_InvalidateAuthCacheByKey(t *testing.T) {
 	require.Len(t, cache.deleteAuthKeys, 1)
 }
 
+func TestAPIKeyService_GetByKey_LooksUpByKeyedHash(t *testing.T) {
+	const secret = "api-key-hash-secret-0123456789abcdef"
+	var lookedUp string
+	cache := &authCacheStub{}
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, key string) (*APIKey, error) {
+			lookedUp = key
+			return &APIKey{
+				ID:     31,
+				UserID: 4,
+				Status: StatusActive,
+				User:   &User{ID: 4, Status: StatusActive, Role: RoleUser},
+			}, nil
+		},
+		listKeysByUserID: func(ctx context.Context, userID int64) ([]string, error) {
+			return []string{lookedUp}, nil
+		},
+	}
+	cfg := &config.Config{
+		Security:   config.SecurityConfig{APIKeyHashSecret: secret},
+		APIKeyAuth: config.APIKeyAuthCacheConfig{L2TTLSeconds: 60},
+	}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+
+	apiKey, err := svc.GetByKey(context.Background(), "sk-plaintext-secret")
+	require.NoError(t, err)
+	require.Equal(t, HashAPIKey(secret, "sk-plaintext-secret"), lookedUp)
+	require.NotEqual(t, HashAPIKey("other-secret", "sk-plaintext-secret"), lookedUp)
+	require.True(t, IsHashedAPIKey(lookedUp))
+	require.Equal(t, "sk-plaintext-secret", apiKey.Key)
+	require.Equal(t, lookedUp, apiKey.KeyHash)
+
+	// 按用户失效时只依赖存储的摘要，生成的缓存键须与认证路径一致
+	svc.InvalidateAuthCacheByUserID(context.Background(), 4)
+	require.Equal(t, cache.setAuthKeys, cache.deleteAuthKeys)
+}
+
 func TestAPIKeyService_GetByKey_CachesNegativeOnRepoMiss(t *testing.T) {
 	cache := &authCacheStub{}
 	repo := &authRepoStub{
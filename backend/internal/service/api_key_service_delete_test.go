@@ -50,14 +50,14 @@ func (s *apiKeyRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error)
 	panic("unexpected GetByID call")
 }
 
-func (s *apiKeyRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
+func (s *apiKeyRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) ([]string, int64, error) {
 	if s.getByIDErr != nil {
-		return "", 0, s.getByIDErr
+		return nil, 0, s.getByIDErr
 	}
 	if s.apiKey != nil {
-		return s.apiKey.Key, s.apiKey.UserID, nil
+		return s.apiKey.AuthKeyHashes(), s.apiKey.UserID, nil
 	}
-	return "", 0, ErrAPIKeyNotFound
+	return nil, 0, ErrAPIKeyNotFound
 }
 
 func (s *apiKeyRepoStub) GetByKey(ctx context.Context, key string) (*APIKey, error) {
@@ -121,6 +121,14 @@ func (s *apiKeyRepoStub) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	panic("unexpected ListKeysByGroupID call")
 }
 
+func (s *apiKeyRepoStub) Rotate(ctx context.Context, rotation APIKeyRotation) error {
+	panic("unexpected Rotate call")
+}
+
+func (s *apiKeyRepoStub) ClearExpiredPreviousKeys(ctx context.Context, now time.Time) ([]string, error) {
+	panic("unexpected ClearExpiredPreviousKeys call")
+}
+
 func (s *apiKeyRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -213,7 +221,7 @@ func (s *apiKeyCacheStub) SubscribeAuthCacheInvalidation(ctx context.Context, ha
 //   - 缓存不被清除
 func TestApiKeyService_Delete_OwnerMismatch(t *testing.T) {
 	repo := &apiKeyRepoStub{
-		apiKey: &APIKey{ID: 10, UserID: 1, Key: "k"},
+		apiKey: &APIKey{ID: 10, UserID: 1, KeyHash: "k"},
 	}
 	cache := &apiKeyCacheStub{}
 	svc := &APIKeyService{apiKeyRepo: repo, cache: cache}
@@ -234,7 +242,7 @@ func TestApiKeyService_Delete_OwnerMismatch(t *testing.T) {
 //   - 返回 nil 错误
 func TestApiKeyService_Delete_Success(t *testing.T) {
 	repo := &apiKeyRepoStub{
-		apiKey: &APIKey{ID: 42, UserID: 7, Key: "k"},
+		apiKey: &APIKey{ID: 42, UserID: 7, KeyHash: "k"},
 	}
 	cache := &apiKeyCacheStub{}
 	svc := &APIKeyService{apiKeyRepo: repo, cache: cache}
@@ -276,7 +284,7 @@ func TestApiKeyService_Delete_NotFound(t *testing.T) {
 //   - 返回包含 "delete api key" 的错误信息
 func TestApiKeyService_Delete_DeleteFails(t *testing.T) {
 	repo := &apiKeyRepoStub{
-		apiKey:    &APIKey{ID: 42, UserID: 3, Key: "k"},
+		apiKey:    &APIKey{ID: 42, UserID: 3, KeyHash: "k"},
 		deleteErr: errors.New("delete failed"),
 	}
 	cache := &apiKeyCacheStub{}
@@ -289,3 +297,17 @@ func TestApiKeyService_Delete_DeleteFails(t *testing.T) {
 	require.Equal(t, []int64{3}, cache.invalidated) // 验证缓存已被清除（即使删除失败）
 	require.Equal(t, []string{svc.authCacheKey("k")}, cache.deleteAuthKeys)
 }
+
+// TestApiKeyService_Delete_InvalidatesPreviousKey 测试删除轮换宽限期内的 API Key 时，
+// 当前 Key 与旧 Key 的认证缓存都会被清除。
+func TestApiKeyService_Delete_InvalidatesPreviousKey(t *testing.T) {
+	repo := &apiKeyRepoStub{
+		apiKey: &APIKey{ID: 43, UserID: 7, KeyHash: "k", PreviousKeyHash: "k-old"},
+	}
+	cache := &apiKeyCacheStub{}
+	svc := &APIKeyService{apiKeyRepo: repo, cache: cache}
+
+	err := svc.Delete(context.Background(), 43, 7)
+	require.NoError(t, err)
+	require.Equal(t, []string{svc.authCacheKey("k"), svc.authCacheKey("k-old")}, cache.deleteAuthKeys)
+}
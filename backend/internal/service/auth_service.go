@@ -53,6 +53,9 @@ type JWTClaims struct {
 	Email        string `json:"email"`
 	Role         string `json:"role"`
 	TokenVersion int64  `json:"token_version"` // Used to invalidate tokens on password change
+	SessionID    string `json:"sid,omitempty"` // Refresh Token 家族 ID，用于标识当前会话
+	// Impersonation 管理员模拟登录标记，非空时为模拟 Token（不可刷新、不可访问管理后台）
+	Impersonation *ImpersonationClaim `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -68,6 +71,7 @@ type AuthService struct {
 	emailQueueService  *EmailQueueService
 	promoService       *PromoService
 	defaultSubAssigner DefaultSubscriptionAssigner
+	sessionCache       UserSessionCache
 }
 
 type DefaultSubscriptionAssigner interface {
@@ -524,19 +528,33 @@ func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, email, username
 }
 
 // LoginOrRegisterOAuthWithTokenPair 用于第三方 OAuth/SSO 登录，返回完整的 TokenPair
-// 与 LoginOrRegisterOAuth 功能相同，但返回 TokenPair 而非单个 token
-func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, email, username string) (*TokenPair, *User, error) {
+// 与 LoginOrRegisterOAuth 功能相同，但返回 TokenPair 而非单个 token。
+// grants 为 IdP 分组映射得出的授权（可为 nil）：可用分组每次登录增量同步，订阅仅在首次注册时发放。
+func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, email, username string, grants *OAuthGrants) (*TokenPair, *User, error) {
 	// 检查 refreshTokenCache 是否可用
 	if s.refreshTokenCache == nil {
 		return nil, nil, errors.New("refresh token cache not configured")
 	}
+	user, err := s.LoginOrRegisterOAuthUser(ctx, email, username, grants)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokenPair, err := s.GenerateTokenPair(ctx, user, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate token pair: %w", err)
+	}
+	return tokenPair, user, nil
+}
 
+// LoginOrRegisterOAuthUser 第三方 OAuth/SSO 登录的用户解析部分（查找或注册、同步授权），不签发 Token；
+// 由调用方决定直接签发 Token 还是先要求第二因素。
+func (s *AuthService) LoginOrRegisterOAuthUser(ctx context.Context, email, username string, grants *OAuthGrants) (*User, error) {
 	email = strings.TrimSpace(email)
 	if email == "" || len(email) > 255 {
-		return nil, nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
+		return nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
 	}
 	if _, err := mail.ParseAddress(email); err != nil {
-		return nil, nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
+		return nil, infraerrors.BadRequest("INVALID_EMAIL", "invalid email")
 	}
 
 	username = strings.TrimSpace(username)
@@ -549,17 +567,17 @@ func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, ema
 		if errors.Is(err, ErrUserNotFound) {
 			// OAuth 首次登录视为注册
 			if s.settingService == nil || !s.settingService.IsRegistrationEnabled(ctx) {
-				return nil, nil, ErrRegDisabled
+				return nil, ErrRegDisabled
 			}
 
 			randomPassword, err := randomHexString(32)
 			if err != nil {
 				logger.LegacyPrintf("service.auth", "[Auth] Failed to generate random password for oauth signup: %v", err)
-				return nil, nil, ErrServiceUnavailable
+				return nil, ErrServiceUnavailable
 			}
 			hashedPassword, err := s.HashPassword(randomPassword)
 			if err != nil {
-				return nil, nil, fmt.Errorf("hash password: %w", err)
+				return nil, fmt.Errorf("hash password: %w", err)
 			}
 
 			defaultBalance := s.cfg.Default.UserBalance
@@ -584,24 +602,27 @@ func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, ema
 					user, err = s.userRepo.GetByEmail(ctx, email)
 					if err != nil {
 						logger.LegacyPrintf("service.auth", "[Auth] Database error getting user after conflict: %v", err)
-						return nil, nil, ErrServiceUnavailable
+						return nil, ErrServiceUnavailable
 					}
 				} else {
 					logger.LegacyPrintf("service.auth", "[Auth] Database error creating oauth user: %v", err)
-					return nil, nil, ErrServiceUnavailable
+					return nil, ErrServiceUnavailable
 				}
 			} else {
 				user = newUser
 				s.assignDefaultSubscriptions(ctx, user.ID)
+				if grants != nil {
+					s.assignSubscriptions(ctx, user.ID, grants.Subscriptions, "auto assigned by oidc group mapping")
+				}
 			}
 		} else {
 			logger.LegacyPrintf("service.auth", "[Auth] Database error during oauth login: %v", err)
-			return nil, nil, ErrServiceUnavailable
+			return nil, ErrServiceUnavailable
 		}
 	}
 
 	if !user.IsActive() {
-		return nil, nil, ErrUserNotActive
+		return nil, ErrUserNotActive
 	}
 
 	if user.Username == "" && username != "" {
@@ -611,6 +632,19 @@ func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, ema
 		}
 	}
 
+	s.grantAllowedGroups(ctx, user, grants)
+	return user, nil
+}
+
+// LoginOAuthUserWithTokenPair 已绑定外部身份的用户通过第三方登录，返回 TokenPair
+func (s *AuthService) LoginOAuthUserWithTokenPair(ctx context.Context, userID int64, grants *OAuthGrants) (*TokenPair, *User, error) {
+	if s.refreshTokenCache == nil {
+		return nil, nil, errors.New("refresh token cache not configured")
+	}
+	user, err := s.LoginOAuthUser(ctx, userID, grants)
+	if err != nil {
+		return nil, nil, err
+	}
 	tokenPair, err := s.GenerateTokenPair(ctx, user, "")
 	if err != nil {
 		return nil, nil, fmt.Errorf("generate token pair: %w", err)
@@ -618,17 +652,58 @@ func (s *AuthService) LoginOrRegisterOAuthWithTokenPair(ctx context.Context, ema
 	return tokenPair, user, nil
 }
 
+// LoginOAuthUser 已绑定外部身份的用户通过第三方登录：校验状态并同步授权，不签发 Token
+func (s *AuthService) LoginOAuthUser(ctx context.Context, userID int64, grants *OAuthGrants) (*User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		logger.LegacyPrintf("service.auth", "[Auth] Database error during oauth login: %v", err)
+		return nil, ErrServiceUnavailable
+	}
+	if !user.IsActive() {
+		return nil, ErrUserNotActive
+	}
+
+	s.grantAllowedGroups(ctx, user, grants)
+	return user, nil
+}
+
+// grantAllowedGroups 将映射得到的专属分组增量加入用户可用分组（不移除已有授权）
+func (s *AuthService) grantAllowedGroups(ctx context.Context, user *User, grants *OAuthGrants) {
+	if grants == nil || user == nil {
+		return
+	}
+	for _, groupID := range grants.AllowedGroupIDs {
+		if groupID <= 0 || user.CanBindGroup(groupID, true) {
+			continue
+		}
+		if err := s.userRepo.AddGroupToAllowedGroups(ctx, user.ID, groupID); err != nil {
+			logger.LegacyPrintf("service.auth", "[Auth] Failed to grant allowed group: user_id=%d group_id=%d err=%v", user.ID, groupID, err)
+			continue
+		}
+		user.AllowedGroups = append(user.AllowedGroups, groupID)
+	}
+}
+
 func (s *AuthService) assignDefaultSubscriptions(ctx context.Context, userID int64) {
-	if s.settingService == nil || s.defaultSubAssigner == nil || userID <= 0 {
+	if s.settingService == nil {
+		return
+	}
+	s.assignSubscriptions(ctx, userID, s.settingService.GetDefaultSubscriptions(ctx), "auto assigned by default user subscriptions setting")
+}
+
+func (s *AuthService) assignSubscriptions(ctx context.Context, userID int64, items []DefaultSubscriptionSetting, notes string) {
+	if s.defaultSubAssigner == nil || userID <= 0 {
 		return
 	}
-	items := s.settingService.GetDefaultSubscriptions(ctx)
 	for _, item := range items {
 		if _, _, err := s.defaultSubAssigner.AssignOrExtendSubscription(ctx, &AssignSubscriptionInput{
 			UserID:       userID,
 			GroupID:      item.GroupID,
 			ValidityDays: item.ValidityDays,
-			Notes:        "auto assigned by default user subscriptions setting",
+			Notes:        notes,
 		}); err != nil {
 			logger.LegacyPrintf("service.auth", "[Auth] Failed to assign default subscription: user_id=%d group_id=%d err=%v", userID, item.GroupID, err)
 		}
@@ -722,6 +797,11 @@ func isReservedEmail(email string) bool {
 // GenerateToken 生成JWT access token
 // 使用新的access_token_expire_minutes配置项（如果配置了），否则回退到expire_hour
 func (s *AuthService) GenerateToken(user *User) (string, error) {
+	return s.generateAccessToken(user, "")
+}
+
+// generateAccessToken 生成 Access Token，sessionID 非空时写入 sid 声明
+func (s *AuthService) generateAccessToken(user *User, sessionID string) (string, error) {
 	now := time.Now()
 	var expiresAt time.Time
 	if s.cfg.JWT.AccessTokenExpireMinutes > 0 {
@@ -736,6 +816,7 @@ func (s *AuthService) GenerateToken(user *User) (string, error) {
 		Email:        user.Email,
 		Role:         user.Role,
 		TokenVersion: user.TokenVersion,
+		SessionID:    sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -783,6 +864,10 @@ func (s *AuthService) RefreshToken(ctx context.Context, oldTokenString string) (
 	if err != nil && !errors.Is(err, ErrTokenExpired) {
 		return "", err
 	}
+	// 模拟 Token 到期即结束，不能换取普通 Token
+	if claims.Impersonation != nil {
+		return "", ErrInvalidToken
+	}
 
 	// 获取最新的用户信息
 	user, err := s.userRepo.GetByID(ctx, claims.UserID)
@@ -971,17 +1056,21 @@ type TokenPair struct {
 }
 
 // GenerateTokenPair 生成Access Token和Refresh Token对
-// familyID: 可选的Token家族ID，用于Token轮转时保持家族关系
+// familyID: 可选的Token家族ID，用于Token轮转时保持家族关系；为空时创建新会话
 func (s *AuthService) GenerateTokenPair(ctx context.Context, user *User, familyID string) (*TokenPair, error) {
 	// 检查 refreshTokenCache 是否可用
 	if s.refreshTokenCache == nil {
 		return nil, errors.New("refresh token cache not configured")
 	}
 
-	// 生成Access Token
-	accessToken, err := s.GenerateToken(user)
-	if err != nil {
-		return nil, fmt.Errorf("generate access token: %w", err)
+	// 如果没有提供familyID，生成新的（即新会话）
+	isNewSession := familyID == ""
+	if isNewSession {
+		familyBytes := make([]byte, 16)
+		if _, err := rand.Read(familyBytes); err != nil {
+			return nil, fmt.Errorf("generate family id: %w", err)
+		}
+		familyID = hex.EncodeToString(familyBytes)
 	}
 
 	// 生成Refresh Token
@@ -990,6 +1079,18 @@ func (s *AuthService) GenerateTokenPair(ctx context.Context, user *User, familyI
 		return nil, fmt.Errorf("generate refresh token: %w", err)
 	}
 
+	// 会话记录未能保存时 Access Token 不携带会话 ID，避免被 IsSessionActive 当作已撤销的会话拒绝
+	sessionID := ""
+	if s.trackSession(ctx, user, familyID, isNewSession, s.refreshTokenTTL()) {
+		sessionID = familyID
+	}
+
+	// 生成Access Token
+	accessToken, err := s.generateAccessToken(user, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("generate access token: %w", err)
+	}
+
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -1009,17 +1110,8 @@ func (s *AuthService) generateRefreshToken(ctx context.Context, user *User, fami
 	// 计算Token哈希（存储哈希而非原始Token）
 	tokenHash := hashToken(rawToken)
 
-	// 如果没有提供familyID，生成新的
-	if familyID == "" {
-		familyBytes := make([]byte, 16)
-		if _, err := rand.Read(familyBytes); err != nil {
-			return "", fmt.Errorf("generate family id: %w", err)
-		}
-		familyID = hex.EncodeToString(familyBytes)
-	}
-
 	now := time.Now()
-	ttl := time.Duration(s.cfg.JWT.RefreshTokenExpireDays) * 24 * time.Hour
+	ttl := s.refreshTokenTTL()
 
 	data := &RefreshTokenData{
 		UserID:       user.ID,
@@ -1049,6 +1141,11 @@ func (s *AuthService) generateRefreshToken(ctx context.Context, user *User, fami
 	return rawToken, nil
 }
 
+// refreshTokenTTL 返回Refresh Token有效期
+func (s *AuthService) refreshTokenTTL() time.Duration {
+	return time.Duration(s.cfg.JWT.RefreshTokenExpireDays) * 24 * time.Hour
+}
+
 // RefreshTokenPair 使用Refresh Token刷新Token对
 // 实现Token轮转：每次刷新都会生成新的Refresh Token，旧Token立即失效
 func (s *AuthService) RefreshTokenPair(ctx context.Context, refreshToken string) (*TokenPair, error) {
@@ -1089,6 +1186,7 @@ func (s *AuthService) RefreshTokenPair(ctx context.Context, refreshToken string)
 		if errors.Is(err, ErrUserNotFound) {
 			// 用户已删除，撤销整个Token家族
 			_ = s.refreshTokenCache.DeleteTokenFamily(ctx, data.FamilyID)
+			s.endSession(ctx, data)
 			return nil, ErrRefreshTokenInvalid
 		}
 		logger.LegacyPrintf("service.auth", "[Auth] Database error getting user for token refresh: %v", err)
@@ -1099,6 +1197,7 @@ func (s *AuthService) RefreshTokenPair(ctx context.Context, refreshToken string)
 	if !user.IsActive() {
 		// 用户被禁用，撤销整个Token家族
 		_ = s.refreshTokenCache.DeleteTokenFamily(ctx, data.FamilyID)
+		s.endSession(ctx, data)
 		return nil, ErrUserNotActive
 	}
 
@@ -1106,6 +1205,7 @@ func (s *AuthService) RefreshTokenPair(ctx context.Context, refreshToken string)
 	if data.TokenVersion != user.TokenVersion {
 		// TokenVersion不匹配，撤销整个Token家族
 		_ = s.refreshTokenCache.DeleteTokenFamily(ctx, data.FamilyID)
+		s.endSession(ctx, data)
 		return nil, ErrTokenRevoked
 	}
 
@@ -1119,7 +1219,8 @@ func (s *AuthService) RefreshTokenPair(ctx context.Context, refreshToken string)
 	return s.GenerateTokenPair(ctx, user, data.FamilyID)
 }
 
-// RevokeRefreshToken 撤销单个Refresh Token
+// RevokeRefreshToken 撤销Refresh Token所属的会话（登出）
+// 删除整个Token家族，使该会话在其他已轮转的Token上也无法继续刷新
 func (s *AuthService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
 	if s.refreshTokenCache == nil {
 		return nil // No-op if cache not configured
@@ -1129,7 +1230,15 @@ func (s *AuthService) RevokeRefreshToken(ctx context.Context, refreshToken strin
 	}
 
 	tokenHash := hashToken(refreshToken)
-	return s.refreshTokenCache.DeleteRefreshToken(ctx, tokenHash)
+	data, err := s.refreshTokenCache.GetRefreshToken(ctx, tokenHash)
+	if err != nil || data == nil || data.FamilyID == "" {
+		return s.refreshTokenCache.DeleteRefreshToken(ctx, tokenHash)
+	}
+	if err := s.refreshTokenCache.DeleteTokenFamily(ctx, data.FamilyID); err != nil {
+		return err
+	}
+	s.endSession(ctx, data)
+	return nil
 }
 
 // RevokeAllUserSessions 撤销用户的所有会话（所有Refresh Token）
@@ -1138,7 +1247,15 @@ func (s *AuthService) RevokeAllUserSessions(ctx context.Context, userID int64) e
 	if s.refreshTokenCache == nil {
 		return nil // No-op if cache not configured
 	}
-	return s.refreshTokenCache.DeleteUserRefreshTokens(ctx, userID)
+	if err := s.refreshTokenCache.DeleteUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	if s.sessionCache != nil {
+		if err := s.sessionCache.DeleteUserSessions(ctx, userID); err != nil {
+			logger.LegacyPrintf("service.auth", "[Auth] Failed to delete sessions for user %d: %v", userID, err)
+		}
+	}
+	return nil
 }
 
 // hashToken 计算Token的SHA256哈希
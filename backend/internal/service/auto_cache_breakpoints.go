@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// 自动 prompt 缓存断点：许多第三方客户端从不设置 cache_control，长 system 提示词与工具定义每轮都按全价输入计费。
+// 分组开启后，在稳定前缀上注入断点（总数不超过 4 个）：
+//   - tools：最后一个工具定义
+//   - system：最后一个 system 块
+//   - 当前完整前缀：最后一条消息，写入缓存供下一轮读取
+//   - 历史轮次：摘要链识别出的前序请求最后一条消息，确保下一轮命中已写入的缓存
+//
+// 消息断点仅在摘要链确认是多轮对话后注入，避免为单轮请求支付缓存写入溢价。
+const (
+	// autoCacheDigestPrefix 自动缓存断点的摘要会话命名空间（按 API Key 隔离）
+	autoCacheDigestPrefix = "auto_cache:k"
+
+	autoCacheTTL5m = "5m"
+	autoCacheTTL1h = "1h"
+)
+
+// autoCacheBreakpointTTL 断点 TTL 跟随账号的缓存 TTL 强制替换设置，未开启时使用默认 5m
+func autoCacheBreakpointTTL(account *Account) string {
+	if account != nil && account.IsCacheTTLOverrideEnabled() {
+		return account.GetCacheTTLOverrideTarget()
+	}
+	return autoCacheTTL5m
+}
+
+// applyAutoCacheBreakpoints 分组开启自动缓存断点时为请求体注入 cache_control，返回新请求体与注入数量。
+// 消息断点依据 parsed.AutoCacheSeenMessages 决定，本方法不读写摘要链，换号重试时结果一致
+func (s *GatewayService) applyAutoCacheBreakpoints(ctx context.Context, account *Account, parsed *ParsedRequest, body []byte) ([]byte, int) {
+	group, _ := ctx.Value(ctxkey.Group).(*Group)
+	if group == nil || !group.AutoCacheBreakpoints || parsed == nil {
+		return body, 0
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return body, 0
+	}
+	injected := injectAutoCacheBreakpoints(data, parsed.AutoCacheSeenMessages, autoCacheBreakpointTTL(account))
+	if injected == 0 {
+		return body, 0
+	}
+	result, err := json.Marshal(data)
+	if err != nil {
+		return body, 0
+	}
+	return result, injected
+}
+
+// ResolveAutoCacheConversation 分组开启自动缓存断点时，通过 Anthropic 摘要链识别同一对话的前序请求，
+// 将前序请求已发送的消息数写入 parsed.AutoCacheSeenMessages，并记录本次请求的摘要链。
+// 每个客户端请求只能调用一次：重复调用会匹配到本次请求自身的摘要链。
+func (s *GatewayService) ResolveAutoCacheConversation(ctx context.Context, group *Group, parsed *ParsedRequest) {
+	if group == nil || !group.AutoCacheBreakpoints || parsed == nil {
+		return
+	}
+	chain := BuildAnthropicDigestChain(parsed)
+	if chain == "" {
+		return
+	}
+	apiKeyID := int64(0)
+	if parsed.SessionContext != nil {
+		apiKeyID = parsed.SessionContext.APIKeyID
+	}
+	namespace := autoCacheDigestPrefix + strconv.FormatInt(apiKeyID, 10)
+	_, _, matchedChain, found := s.FindAnthropicSession(ctx, group.ID, namespace, chain)
+	_ = s.SaveAnthropicSession(ctx, group.ID, namespace, chain, "", 0, matchedChain)
+	if !found {
+		return
+	}
+	seen := strings.Count(matchedChain, "-") + 1
+	if strings.HasPrefix(matchedChain, "s:") {
+		seen--
+	}
+	parsed.AutoCacheSeenMessages = seen
+}
+
+// injectAutoCacheBreakpoints 在稳定前缀上注入断点，返回注入数量。
+// 客户端已自行设置的断点计入总数；已有断点 TTL 不一致时不注入，避免违反“长 TTL 断点必须在前”的约束。
+func injectAutoCacheBreakpoints(data map[string]any, seenMessages int, ttl string) int {
+	existingTTLs := collectCacheControlTTLs(data)
+	if len(existingTTLs) > 1 {
+		return 0
+	}
+	for existing := range existingTTLs {
+		ttl = existing
+	}
+	budget := maxCacheControlBlocks - countCacheControlBlocks(data) - countToolCacheControlBlocks(data)
+	injected := 0
+	mark := func(block map[string]any) bool {
+		if budget <= 0 || block == nil {
+			return false
+		}
+		block["cache_control"] = newAutoCacheControl(ttl)
+		budget--
+		injected++
+		return true
+	}
+
+	if tools, ok := data["tools"].([]any); ok && len(tools) > 0 && !blocksHaveCacheControl(tools) {
+		if last, ok := tools[len(tools)-1].(map[string]any); ok {
+			mark(last)
+		}
+	}
+
+	switch system := data["system"].(type) {
+	case string:
+		if strings.TrimSpace(system) != "" {
+			block := map[string]any{"type": "text", "text": system}
+			if mark(block) {
+				data["system"] = []any{block}
+			}
+		}
+	case []any:
+		if !blocksHaveCacheControl(system) {
+			mark(lastCacheableBlock(system))
+		}
+	}
+
+	// 客户端已自行管理消息断点，或新对话时不注入消息断点
+	messages, _ := data["messages"].([]any)
+	if len(messages) == 0 || seenMessages == 0 || messagesHaveCacheControl(messages) {
+		return injected
+	}
+	last := len(messages) - 1
+	if budget > 0 {
+		mark(cacheableMessageBlock(messages[last]))
+	}
+	if history := seenMessages - 1; budget > 0 && history >= 0 && history < last {
+		mark(cacheableMessageBlock(messages[history]))
+	}
+	return injected
+}
+
+// calculatePromptCacheSavings 计算自动缓存断点的节省：缓存 Token 全部按普通输入计费的费用减去实际费用
+func (s *GatewayService) calculatePromptCacheSavings(result *ForwardResult, multiplier float64, cost *CostBreakdown) float64 {
+	if cost == nil || s.billingService == nil {
+		return 0
+	}
+	usage := result.Usage
+	baseline, err := s.billingService.CalculateCost(result.Model, UsageTokens{
+		InputTokens:  usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens,
+		OutputTokens: usage.OutputTokens,
+	}, multiplier)
+	if err != nil {
+		return 0
+	}
+	return baseline.ActualCost - cost.ActualCost
+}
+
+func newAutoCacheControl(ttl string) map[string]any {
+	cc := map[string]any{"type": "ephemeral"}
+	if ttl == autoCacheTTL1h {
+		cc["ttl"] = autoCacheTTL1h
+	}
+	return cc
+}
+
+// collectCacheControlTTLs 收集请求中已有断点的 TTL（未指定视为 5m）
+func collectCacheControlTTLs(data map[string]any) map[string]struct{} {
+	ttls := make(map[string]struct{})
+	collect := func(blocks []any) {
+		for _, item := range blocks {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			cc, ok := m["cache_control"].(map[string]any)
+			if !ok {
+				continue
+			}
+			ttl, _ := cc["ttl"].(string)
+			if ttl == "" {
+				ttl = autoCacheTTL5m
+			}
+			ttls[ttl] = struct{}{}
+		}
+	}
+	if tools, ok := data["tools"].([]any); ok {
+		collect(tools)
+	}
+	if system, ok := data["system"].([]any); ok {
+		collect(system)
+	}
+	if messages, ok := data["messages"].([]any); ok {
+		for _, msg := range messages {
+			if msgMap, ok := msg.(map[string]any); ok {
+				if content, ok := msgMap["content"].([]any); ok {
+					collect(content)
+				}
+			}
+		}
+	}
+	return ttls
+}
+
+// countToolCacheControlBlocks 统计 tools 中的 cache_control（countCacheControlBlocks 不含 tools）
+func countToolCacheControlBlocks(data map[string]any) int {
+	tools, ok := data["tools"].([]any)
+	if !ok {
+		return 0
+	}
+	count := 0
+	for _, item := range tools {
+		if m, ok := item.(map[string]any); ok {
+			if _, has := m["cache_control"]; has {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func blocksHaveCacheControl(blocks []any) bool {
+	for _, item := range blocks {
+		if m, ok := item.(map[string]any); ok {
+			if _, has := m["cache_control"]; has {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func messagesHaveCacheControl(messages []any) bool {
+	for _, msg := range messages {
+		if msgMap, ok := msg.(map[string]any); ok {
+			if content, ok := msgMap["content"].([]any); ok && blocksHaveCacheControl(content) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lastCacheableBlock 返回最后一个可设置 cache_control 的块（跳过 thinking 与空文本）
+func lastCacheableBlock(blocks []any) map[string]any {
+	for i := len(blocks) - 1; i >= 0; i-- {
+		m, ok := blocks[i].(map[string]any)
+		if !ok {
+			continue
+		}
+		switch blockType, _ := m["type"].(string); blockType {
+		case "thinking", "redacted_thinking":
+			continue
+		case "text":
+			if text, _ := m["text"].(string); strings.TrimSpace(text) == "" {
+				continue
+			}
+		}
+		return m
+	}
+	return nil
+}
+
+// cacheableMessageBlock 返回消息中用于设置断点的块；字符串 content 转为单个 text 块
+func cacheableMessageBlock(msg any) map[string]any {
+	msgMap, ok := msg.(map[string]any)
+	if !ok {
+		return nil
+	}
+	switch content := msgMap["content"].(type) {
+	case string:
+		if strings.TrimSpace(content) == "" {
+			return nil
+		}
+		block := map[string]any{"type": "text", "text": content}
+		msgMap["content"] = []any{block}
+		return block
+	case []any:
+		return lastCacheableBlock(content)
+	}
+	return nil
+}
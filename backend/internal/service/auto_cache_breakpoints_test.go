@@ -0,0 +1,130 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func decodeAutoCacheBody(t *testing.T, body string) map[string]any {
+	t.Helper()
+	var data map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &data))
+	return data
+}
+
+func TestInjectAutoCacheBreakpoints_NewConversationCachesToolsAndSystem(t *testing.T) {
+	data := decodeAutoCacheBody(t, `{
+		"tools":[{"name":"a"},{"name":"b"}],
+		"system":"you are helpful",
+		"messages":[{"role":"user","content":"hi"}]
+	}`)
+
+	injected := injectAutoCacheBreakpoints(data, 0, autoCacheTTL5m)
+	require.Equal(t, 2, injected)
+
+	out, _ := json.Marshal(data)
+	require.True(t, gjson.GetBytes(out, "tools.1.cache_control").Exists())
+	require.False(t, gjson.GetBytes(out, "tools.0.cache_control").Exists())
+	require.Equal(t, "ephemeral", gjson.GetBytes(out, "system.0.cache_control.type").String())
+	require.False(t, gjson.GetBytes(out, "system.0.cache_control.ttl").Exists())
+	require.Equal(t, "hi", gjson.GetBytes(out, "messages.0.content").String(), "new conversation should not get message breakpoints")
+}
+
+func TestInjectAutoCacheBreakpoints_ContinuedConversationCachesHistory(t *testing.T) {
+	data := decodeAutoCacheBody(t, `{
+		"system":[{"type":"text","text":"sys"}],
+		"messages":[
+			{"role":"user","content":"q1"},
+			{"role":"assistant","content":[{"type":"thinking","thinking":"..."},{"type":"text","text":"a1"}]},
+			{"role":"user","content":"q2"},
+			{"role":"assistant","content":"a2"},
+			{"role":"user","content":[{"type":"text","text":"q3"}]}
+		]
+	}`)
+
+	injected := injectAutoCacheBreakpoints(data, 3, autoCacheTTL1h)
+	require.Equal(t, 3, injected)
+
+	out, _ := json.Marshal(data)
+	require.Equal(t, "1h", gjson.GetBytes(out, "system.0.cache_control.ttl").String())
+	require.Equal(t, "1h", gjson.GetBytes(out, "messages.4.content.0.cache_control.ttl").String())
+	require.Equal(t, "1h", gjson.GetBytes(out, "messages.2.content.0.cache_control.ttl").String())
+	require.False(t, gjson.GetBytes(out, "messages.1.content.0.cache_control").Exists())
+}
+
+func TestInjectAutoCacheBreakpoints_RespectsClientBreakpoints(t *testing.T) {
+	// 客户端已设置 3 个断点：只剩 1 个名额，TTL 跟随客户端
+	data := decodeAutoCacheBody(t, `{
+		"tools":[{"name":"a","cache_control":{"type":"ephemeral","ttl":"1h"}}],
+		"system":[{"type":"text","text":"s1","cache_control":{"type":"ephemeral","ttl":"1h"}},{"type":"text","text":"s2"}],
+		"messages":[
+			{"role":"user","content":[{"type":"text","text":"q1","cache_control":{"type":"ephemeral","ttl":"1h"}}]},
+			{"role":"assistant","content":"a1"},
+			{"role":"user","content":"q2"}
+		]
+	}`)
+	require.Equal(t, 0, injectAutoCacheBreakpoints(data, 1, autoCacheTTL5m), "client manages every section")
+
+	data = decodeAutoCacheBody(t, `{
+		"tools":[{"name":"a"}],
+		"system":[{"type":"text","text":"s1","cache_control":{"type":"ephemeral"}}],
+		"messages":[{"role":"user","content":[{"type":"text","text":"q1","cache_control":{"type":"ephemeral","ttl":"1h"}}]}]
+	}`)
+	require.Equal(t, 0, injectAutoCacheBreakpoints(data, 0, autoCacheTTL5m), "mixed client TTLs are left untouched")
+}
+
+func TestGatewayService_ApplyAutoCacheBreakpointsTracksConversation(t *testing.T) {
+	svc := &GatewayService{digestStore: NewDigestSessionStore()}
+	group := &Group{ID: 9, AutoCacheBreakpoints: true}
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+
+	turn1 := []byte(`{"model":"claude-sonnet-4-5","system":"sys","messages":[{"role":"user","content":"q1"}]}`)
+	parsed1, err := ParseGatewayRequest(turn1, "anthropic")
+	require.NoError(t, err)
+	parsed1.SessionContext = &SessionContext{APIKeyID: 3}
+	svc.ResolveAutoCacheConversation(ctx, group, parsed1)
+	require.Zero(t, parsed1.AutoCacheSeenMessages)
+	// 换号重试多次转发同一请求：不得把自身当作前序轮次注入消息断点
+	for attempt := 0; attempt < 2; attempt++ {
+		body, injected := svc.applyAutoCacheBreakpoints(ctx, &Account{}, parsed1, turn1)
+		require.Equal(t, 1, injected)
+		require.True(t, gjson.GetBytes(body, "system.0.cache_control").Exists())
+		require.False(t, gjson.GetBytes(body, "messages.0.content.0.cache_control").Exists())
+	}
+
+	turn2 := []byte(`{"model":"claude-sonnet-4-5","system":"sys","messages":[{"role":"user","content":"q1"},{"role":"assistant","content":"a1"},{"role":"user","content":"q2"}]}`)
+	parsed2, err := ParseGatewayRequest(turn2, "anthropic")
+	require.NoError(t, err)
+	parsed2.SessionContext = &SessionContext{APIKeyID: 3}
+	svc.ResolveAutoCacheConversation(ctx, group, parsed2)
+	require.Equal(t, 1, parsed2.AutoCacheSeenMessages)
+	for attempt := 0; attempt < 2; attempt++ {
+		body, injected := svc.applyAutoCacheBreakpoints(ctx, &Account{}, parsed2, turn2)
+		require.Equal(t, 3, injected)
+		require.True(t, gjson.GetBytes(body, "messages.0.content.0.cache_control").Exists())
+		require.True(t, gjson.GetBytes(body, "messages.2.content.0.cache_control").Exists())
+	}
+
+	// 分组未开启时原样返回
+	disabled := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: 9})
+	body, injected := svc.applyAutoCacheBreakpoints(disabled, &Account{}, parsed2, turn2)
+	require.Zero(t, injected)
+	require.Equal(t, turn2, body)
+}
+
+func TestAutoCacheBreakpointTTL_FollowsAccountOverride(t *testing.T) {
+	require.Equal(t, autoCacheTTL5m, autoCacheBreakpointTTL(&Account{}))
+	account := &Account{
+		Platform: PlatformAnthropic,
+		Type:     AccountTypeOAuth,
+		Extra:    map[string]any{"cache_ttl_override_enabled": true, "cache_ttl_override_target": "1h"},
+	}
+	require.Equal(t, autoCacheTTL1h, autoCacheBreakpointTTL(account))
+}
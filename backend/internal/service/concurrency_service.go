@@ -74,6 +74,8 @@ const (
 // ConcurrencyService manages concurrent request limiting for accounts and users
 type ConcurrencyService struct {
 	cache ConcurrencyCache
+	// waitQueues 账号满载时的本地优先级等待队列
+	waitQueues accountWaitQueueRegistry
 }
 
 // NewConcurrencyService creates a new ConcurrencyService
@@ -123,6 +125,15 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 		}, nil
 	}
 
+	// 本实例已有同级或更高优先级的排队者时，释放出的槽位留给队列，新请求需经等待队列获取
+	if s.waitQueues.hasWaitersAtOrAbove(accountID, QueuePriorityFromContext(ctx)) {
+		return &AcquireResult{Acquired: false}, nil
+	}
+	return s.acquireAccountSlot(ctx, accountID, maxConcurrency)
+}
+
+// acquireAccountSlot 直接向缓存申请槽位（不经过等待队列闸门）
+func (s *ConcurrencyService) acquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int) (*AcquireResult, error) {
 	// Generate unique request ID for this slot
 	requestID := generateRequestID()
 
@@ -140,6 +151,8 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 				if err := s.cache.ReleaseAccountSlot(bgCtx, accountID, requestID); err != nil {
 					logger.LegacyPrintf("service.concurrency", "Warning: failed to release account slot for %d (req=%s): %v", accountID, requestID, err)
 				}
+				// 唤醒本地队首立即抢占刚释放的槽位
+				s.waitQueues.signalHead(accountID)
 			},
 		}, nil
 	}
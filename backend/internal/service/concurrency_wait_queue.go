@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// QueuePriorityMax 排队优先级上限（0-9，越大越优先）
+const QueuePriorityMax = domain.QueuePriorityMax
+
+// queuePriorityAgingInterval 防饥饿：每等待该时长，排队优先级临时提升 1 级（不超过上限）
+const queuePriorityAgingInterval = 5 * time.Second
+
+// ResolveQueuePriority 计算请求的排队优先级。
+//
+// 用户级与分组级优先级取较大值；Key 策略中的优先级只能下调（用于后台批处理类 Key），
+// 避免用户通过自助修改 Key 策略插队。
+func ResolveQueuePriority(apiKey *APIKey) int {
+	if apiKey == nil {
+		return 0
+	}
+	priority := 0
+	if apiKey.User != nil {
+		priority = apiKey.User.QueuePriority
+	}
+	if apiKey.Group != nil && apiKey.Group.QueuePriority > priority {
+		priority = apiKey.Group.QueuePriority
+	}
+	if apiKey.Policy != nil && apiKey.Policy.QueuePriority > 0 && apiKey.Policy.QueuePriority < priority {
+		priority = apiKey.Policy.QueuePriority
+	}
+	return clampQueuePriority(priority)
+}
+
+// WithQueuePriority 在上下文中记录请求的排队优先级，供 AcquireAccountSlot 判断是否需要让位给排队者
+func WithQueuePriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, ctxkey.QueuePriority, clampQueuePriority(priority))
+}
+
+// QueuePriorityFromContext 读取请求的排队优先级；未设置时（如后台镜像请求）按最低优先级处理
+func QueuePriorityFromContext(ctx context.Context) int {
+	priority, _ := ctx.Value(ctxkey.QueuePriority).(int)
+	return priority
+}
+
+func clampQueuePriority(priority int) int {
+	if priority < 0 {
+		return 0
+	}
+	if priority > QueuePriorityMax {
+		return QueuePriorityMax
+	}
+	return priority
+}
+
+// accountWaitQueue 单个账号的本地等待队列
+type accountWaitQueue struct {
+	waiters map[uint64]*AccountSlotWaiter
+	// grants 本轮繁忙期内各用户已获得的槽位数，用于同优先级用户间的公平分配；队列清空后重置
+	grants map[int64]int
+}
+
+// accountWaitQueueRegistry 进程内的账号优先级等待队列，零值可用。
+//
+// 槽位计数仍由 ConcurrencyCache（Redis）维护，这里只决定本实例内哪个等待者有资格去抢占槽位：
+// 队首按「优先级（含等待老化）→ 同优先级内已获槽位较少的用户 → 先到先得」排序。
+//
+// AcquireAccountSlot 的直接获取在本实例存在同级或更高优先级排队者时让出槽位，避免低优先级新请求插队；
+// 队列不跨实例协调，其他实例的请求仍可能先于本实例队首拿到释放的槽位，因此优先级只在单实例内成立。
+type accountWaitQueueRegistry struct {
+	mu     sync.Mutex
+	queues map[int64]*accountWaitQueue
+	seq    uint64
+	now    func() time.Time // 测试注入，nil 时使用 time.Now
+}
+
+func (r *accountWaitQueueRegistry) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// AccountSlotWaiter 账号等待队列中的一个等待者，必须在退出等待时调用 Leave。
+type AccountSlotWaiter struct {
+	registry   *accountWaitQueueRegistry
+	service    *ConcurrencyService
+	accountID  int64
+	userID     int64
+	priority   int
+	seq        uint64
+	enqueuedAt time.Time
+	notify     chan struct{}
+	left       bool
+}
+
+func (r *accountWaitQueueRegistry) enter(svc *ConcurrencyService, accountID, userID int64, priority int) *AccountSlotWaiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.queues == nil {
+		r.queues = make(map[int64]*accountWaitQueue)
+	}
+	queue := r.queues[accountID]
+	if queue == nil {
+		queue = &accountWaitQueue{
+			waiters: make(map[uint64]*AccountSlotWaiter),
+			grants:  make(map[int64]int),
+		}
+		r.queues[accountID] = queue
+	}
+	r.seq++
+	waiter := &AccountSlotWaiter{
+		registry:   r,
+		service:    svc,
+		accountID:  accountID,
+		userID:     userID,
+		priority:   clampQueuePriority(priority),
+		seq:        r.seq,
+		enqueuedAt: r.currentTime(),
+		notify:     make(chan struct{}, 1),
+	}
+	queue.waiters[waiter.seq] = waiter
+	return waiter
+}
+
+// effectivePriority 叠加等待老化后的优先级
+func (r *accountWaitQueueRegistry) effectivePriority(w *AccountSlotWaiter, now time.Time) int {
+	boost := int(now.Sub(w.enqueuedAt) / queuePriorityAgingInterval)
+	return clampQueuePriority(w.priority + boost)
+}
+
+// headLocked 返回队首等待者，调用方需持有锁
+func (r *accountWaitQueueRegistry) headLocked(queue *accountWaitQueue) *AccountSlotWaiter {
+	now := r.currentTime()
+	var head *AccountSlotWaiter
+	headPriority := 0
+	for _, w := range queue.waiters {
+		p := r.effectivePriority(w, now)
+		if head == nil {
+			head, headPriority = w, p
+			continue
+		}
+		if p != headPriority {
+			if p > headPriority {
+				head, headPriority = w, p
+			}
+			continue
+		}
+		if gw, gh := queue.grants[w.userID], queue.grants[head.userID]; gw != gh {
+			if gw < gh {
+				head, headPriority = w, p
+			}
+			continue
+		}
+		if w.seq < head.seq {
+			head, headPriority = w, p
+		}
+	}
+	return head
+}
+
+func (r *accountWaitQueueRegistry) isHead(w *AccountSlotWaiter) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queue := r.queues[w.accountID]
+	if queue == nil || w.left {
+		return false
+	}
+	return r.headLocked(queue) == w
+}
+
+// hasWaitersAtOrAbove 账号是否存在有效优先级（含等待老化）不低于 priority 的本地等待者
+func (r *accountWaitQueueRegistry) hasWaitersAtOrAbove(accountID int64, priority int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queue := r.queues[accountID]
+	if queue == nil {
+		return false
+	}
+	now := r.currentTime()
+	for _, w := range queue.waiters {
+		if r.effectivePriority(w, now) >= priority {
+			return true
+		}
+	}
+	return false
+}
+
+// signalHead 唤醒账号当前队首，使其立即重试获取槽位
+func (r *accountWaitQueueRegistry) signalHead(accountID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signalHeadLocked(accountID)
+}
+
+func (r *accountWaitQueueRegistry) signalHeadLocked(accountID int64) {
+	queue := r.queues[accountID]
+	if queue == nil {
+		return
+	}
+	if head := r.headLocked(queue); head != nil {
+		select {
+		case head.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// remove 移出等待者；granted 表示已获得槽位，计入公平分配统计
+func (r *accountWaitQueueRegistry) remove(w *AccountSlotWaiter, granted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w.left {
+		return
+	}
+	w.left = true
+	queue := r.queues[w.accountID]
+	if queue == nil {
+		return
+	}
+	delete(queue.waiters, w.seq)
+	if len(queue.waiters) == 0 {
+		delete(r.queues, w.accountID)
+		return
+	}
+	if granted {
+		queue.grants[w.userID]++
+	}
+	// 队首可能已变化：唤醒新队首检查是否还有空闲槽位
+	r.signalHeadLocked(w.accountID)
+}
+
+// depthByPriority 返回各账号按基础优先级统计的等待数
+func (r *accountWaitQueueRegistry) depthByPriority() map[int64]map[int]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[int64]map[int]int, len(r.queues))
+	for accountID, queue := range r.queues {
+		depth := make(map[int]int)
+		for _, w := range queue.waiters {
+			depth[w.priority]++
+		}
+		out[accountID] = depth
+	}
+	return out
+}
+
+// Notify 槽位释放或队首变化时收到通知
+func (w *AccountSlotWaiter) Notify() <-chan struct{} {
+	return w.notify
+}
+
+// TryAcquire 仅当自己是队首时尝试获取账号槽位；获取成功后自动离开队列。
+func (w *AccountSlotWaiter) TryAcquire(ctx context.Context, maxConcurrency int) (*AcquireResult, error) {
+	if !w.registry.isHead(w) {
+		return &AcquireResult{Acquired: false}, nil
+	}
+	result, err := w.service.acquireAccountSlot(ctx, w.accountID, maxConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	if result.Acquired {
+		w.registry.remove(w, true)
+	}
+	return result, nil
+}
+
+// Leave 退出等待队列（超时、取消或已获取槽位后调用均安全）
+func (w *AccountSlotWaiter) Leave() {
+	w.registry.remove(w, false)
+}
+
+// EnterAccountWaitQueue 加入账号的优先级等待队列。
+// 队列为进程内结构：多实例部署时各实例独立排序，槽位上限仍由 Redis 全局保证。
+func (s *ConcurrencyService) EnterAccountWaitQueue(accountID, userID int64, priority int) *AccountSlotWaiter {
+	return s.waitQueues.enter(s, accountID, userID, priority)
+}
+
+// GetAccountWaitQueueDepths 返回本实例各账号按优先级统计的排队数
+func (s *ConcurrencyService) GetAccountWaitQueueDepths() map[int64]map[int]int {
+	if s == nil {
+		return map[int64]map[int]int{}
+	}
+	return s.waitQueues.depthByPriority()
+}
@@ -0,0 +1,166 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveQueuePriority(t *testing.T) {
+	require.Equal(t, 0, ResolveQueuePriority(nil))
+
+	apiKey := &APIKey{
+		User:  &User{QueuePriority: 3},
+		Group: &Group{QueuePriority: 6},
+	}
+	require.Equal(t, 6, ResolveQueuePriority(apiKey), "用户与分组取较大值")
+
+	apiKey.Policy = &APIKeyPolicy{QueuePriority: 2}
+	require.Equal(t, 2, ResolveQueuePriority(apiKey), "Key 策略可以下调")
+
+	apiKey.Policy = &APIKeyPolicy{QueuePriority: 9}
+	require.Equal(t, 6, ResolveQueuePriority(apiKey), "Key 策略不能上调")
+}
+
+func TestAccountWaitQueue_HeadOrdering(t *testing.T) {
+	svc := &ConcurrencyService{}
+	low := svc.EnterAccountWaitQueue(1, 10, 0)
+	high := svc.EnterAccountWaitQueue(1, 11, 5)
+	highLater := svc.EnterAccountWaitQueue(1, 12, 5)
+
+	require.True(t, svc.waitQueues.isHead(high))
+	require.False(t, svc.waitQueues.isHead(low))
+
+	high.Leave()
+	require.True(t, svc.waitQueues.isHead(highLater), "同优先级先到先得")
+	select {
+	case <-highLater.Notify():
+	default:
+		t.Fatal("队首离开后新队首应被唤醒")
+	}
+
+	highLater.Leave()
+	require.True(t, svc.waitQueues.isHead(low))
+	low.Leave()
+	require.Empty(t, svc.GetAccountWaitQueueDepths())
+}
+
+func TestAccountWaitQueue_FairShareAcrossUsers(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true}
+	svc := NewConcurrencyService(cache)
+
+	first := svc.EnterAccountWaitQueue(1, 100, 1)
+	second := svc.EnterAccountWaitQueue(1, 100, 1)
+	other := svc.EnterAccountWaitQueue(1, 200, 1)
+
+	result, err := first.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+
+	// 用户 100 已获得一个槽位，同优先级下用户 200 优先于其后到的请求
+	require.True(t, svc.waitQueues.isHead(other))
+	result, err = second.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+
+	other.Leave()
+	second.Leave()
+}
+
+func TestAccountWaitQueue_AgingPreventsStarvation(t *testing.T) {
+	now := time.Now()
+	svc := &ConcurrencyService{}
+	svc.waitQueues.now = func() time.Time { return now }
+
+	old := svc.EnterAccountWaitQueue(1, 10, 0)
+	now = now.Add(3 * queuePriorityAgingInterval)
+	fresh := svc.EnterAccountWaitQueue(1, 11, 2)
+
+	require.True(t, svc.waitQueues.isHead(old), "等待足够久的低优先级请求应被提升")
+
+	old.Leave()
+	fresh.Leave()
+}
+
+func TestAccountSlotWaiter_OnlyHeadAcquires(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true}
+	svc := NewConcurrencyService(cache)
+
+	low := svc.EnterAccountWaitQueue(1, 10, 0)
+	high := svc.EnterAccountWaitQueue(1, 11, 5)
+	defer low.Leave()
+	defer high.Leave()
+
+	result, err := low.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.False(t, result.Acquired, "非队首等待者不得抢占槽位")
+
+	result, err = svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 6), 1, 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired, "优先级高于全部排队者时可直接获取")
+
+	result, err = high.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+
+	result, err = low.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	require.Empty(t, svc.GetAccountWaitQueueDepths())
+}
+
+func TestAcquireAccountSlot_YieldsToQueuedHigherPriority(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true}
+	svc := NewConcurrencyService(cache)
+
+	high := svc.EnterAccountWaitQueue(1, 10, 5)
+	defer high.Leave()
+
+	// 晚到的低优先级请求不得抢走留给高优先级排队者的槽位
+	result, err := svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 1), 1, 1)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	// 未设置优先级的请求（如后台镜像）按最低优先级处理
+	result, err = svc.AcquireAccountSlot(context.Background(), 1, 1)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+	// 同级请求按先到先得排在队列之后
+	result, err = svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 5), 1, 1)
+	require.NoError(t, err)
+	require.False(t, result.Acquired)
+
+	// 更高优先级请求与其他账号不受影响
+	result, err = svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 6), 1, 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	result, err = svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 1), 2, 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+
+	result, err = high.TryAcquire(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired)
+	result, err = svc.AcquireAccountSlot(WithQueuePriority(context.Background(), 1), 1, 1)
+	require.NoError(t, err)
+	require.True(t, result.Acquired, "队列清空后恢复直接获取")
+}
+
+func TestGetAccountWaitQueueDepths(t *testing.T) {
+	svc := &ConcurrencyService{}
+	a := svc.EnterAccountWaitQueue(1, 10, 0)
+	b := svc.EnterAccountWaitQueue(1, 11, 3)
+	c := svc.EnterAccountWaitQueue(1, 12, 3)
+	d := svc.EnterAccountWaitQueue(2, 10, 12)
+
+	depths := svc.GetAccountWaitQueueDepths()
+	require.Equal(t, map[int]int{0: 1, 3: 2}, depths[1])
+	require.Equal(t, map[int]int{QueuePriorityMax: 1}, depths[2])
+
+	for _, w := range []*AccountSlotWaiter{a, b, c, d} {
+		w.Leave()
+	}
+}
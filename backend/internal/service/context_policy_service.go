@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+type ContextPolicyConfig = domain.ContextPolicyConfig
+
+const (
+	ContextPolicyActionReject    = domain.ContextPolicyActionReject
+	ContextPolicyActionTruncate  = domain.ContextPolicyActionTruncate
+	ContextPolicyActionSummarize = domain.ContextPolicyActionSummarize
+)
+
+var ErrContextPolicyInvalid = domain.ErrContextPolicyInvalid
+
+func NormalizeContextPolicyConfig(cfg *ContextPolicyConfig) (*ContextPolicyConfig, error) {
+	return domain.NormalizeContextPolicyConfig(cfg)
+}
+
+const (
+	// ContextPolicyHeader 响应头：请求历史被截断或摘要时返回实际处理方式
+	ContextPolicyHeader = "X-Context-Policy"
+
+	// 使用日志 context_action 取值
+	UsageContextActionTruncated      = "truncated"
+	UsageContextActionSummarized     = "summarized"
+	UsageContextActionSummaryRequest = "summary_request"
+
+	// contextPolicyMessageOverheadTokens 每条消息的结构开销估算
+	contextPolicyMessageOverheadTokens = 4
+	// contextPolicyMediaTokens 单个图片 / 文档来源的估算 token（无法本地解码，按常见尺寸上限计）
+	contextPolicyMediaTokens = 1600
+	// contextPolicySummaryWrapperTokens 摘要外层说明文字的预留 token
+	contextPolicySummaryWrapperTokens = 64
+
+	contextPolicySummaryTimeout      = 2 * time.Minute
+	contextPolicySummaryCaptureBytes = 512 * 1024
+)
+
+const contextSummarySystemPrompt = "You compress conversation history. Summarize the transcript so the conversation can continue without it. " +
+	"Preserve the user's goals, constraints, decisions, important facts, file names, identifiers, relevant tool results and any open tasks. " +
+	"Be concise, use plain text, and write in the language of the conversation."
+
+// ContextTooLongError 本地估算的输入 token 超出分组上限且无法截断
+type ContextTooLongError struct {
+	EstimatedTokens int
+	MaxTokens       int
+}
+
+func (e *ContextTooLongError) Error() string {
+	return fmt.Sprintf("prompt is too long: ~%d tokens > %d maximum (estimated by gateway)", e.EstimatedTokens, e.MaxTokens)
+}
+
+// ContextPolicyOutcome 上下文策略对单个请求的处理结果
+type ContextPolicyOutcome struct {
+	// Action 使用日志记录的处理方式：truncated / summarized
+	Action string
+	// EstimatedTokens 处理前的估算输入 token
+	EstimatedTokens int
+	// FinalTokens 处理后的估算输入 token
+	FinalTokens int
+	// DroppedMessages 被丢弃或摘要的消息数
+	DroppedMessages int
+	// Body 改写后的请求体
+	Body []byte
+
+	// 摘要请求本身的转发结果与账号（摘要失败回退截断时也可能存在），需单独记录使用量
+	SummaryResult  *ForwardResult
+	SummaryAccount *Account
+}
+
+// tagUsageLog 在使用日志上记录上下文处理方式
+func (o *ContextPolicyOutcome) tagUsageLog(log *UsageLog) {
+	if o == nil || log == nil || o.Action == "" {
+		return
+	}
+	action := o.Action
+	log.ContextAction = &action
+	log.ContextDroppedMessages = o.DroppedMessages
+}
+
+// SummaryRequestOutcome 摘要请求使用日志的标记；未发起摘要请求时返回 nil
+func (o *ContextPolicyOutcome) SummaryRequestOutcome() *ContextPolicyOutcome {
+	if o == nil || o.SummaryResult == nil || o.SummaryAccount == nil {
+		return nil
+	}
+	return &ContextPolicyOutcome{Action: UsageContextActionSummaryRequest, DroppedMessages: o.DroppedMessages}
+}
+
+// contextSummary 摘要请求结果
+type contextSummary struct {
+	text    string
+	result  *ForwardResult
+	account *Account
+}
+
+// ContextPolicyService 分组上下文策略：转发前本地估算 Claude Messages 请求的输入 token，
+// 超出上限时按配置拒绝、丢弃最早的对话轮次，或调用低价模型把最早的轮次压缩为摘要。
+//
+// 截断只在"不含 tool_result 的 user 消息"处切分，保证保留部分的 tool_use / tool_result 配对完整。
+type ContextPolicyService struct {
+	gatewayService            *GatewayService
+	antigravityGatewayService *AntigravityGatewayService
+
+	summarize func(ctx context.Context, groupID int64, cfg *ContextPolicyConfig, transcript string) (*contextSummary, error) // 测试注入
+}
+
+func NewContextPolicyService(gatewayService *GatewayService, antigravityGatewayService *AntigravityGatewayService) *ContextPolicyService {
+	svc := &ContextPolicyService{
+		gatewayService:            gatewayService,
+		antigravityGatewayService: antigravityGatewayService,
+	}
+	svc.summarize = svc.forwardSummary
+	return svc
+}
+
+// Apply 按分组策略处理请求体：未超限或未启用时返回 (nil, nil)；
+// 需要拒绝时返回 *ContextTooLongError
+func (s *ContextPolicyService) Apply(ctx context.Context, group *Group, body []byte) (*ContextPolicyOutcome, error) {
+	if s == nil || group == nil || group.ContextPolicy == nil || !group.ContextPolicy.Enabled {
+		return nil, nil
+	}
+	cfg := group.ContextPolicy
+	estimate := estimateContextPolicyRequest(body)
+	total := estimate.total()
+	if total <= cfg.MaxInputTokens {
+		return nil, nil
+	}
+	tooLong := &ContextTooLongError{EstimatedTokens: total, MaxTokens: cfg.MaxInputTokens}
+
+	var summaryOutcome *ContextPolicyOutcome
+	switch cfg.Action {
+	case ContextPolicyActionTruncate:
+	case ContextPolicyActionSummarize:
+		outcome, err := s.applySummary(ctx, group.ID, cfg, body, estimate)
+		if err == nil {
+			return outcome, nil
+		}
+		logger.FromContext(ctx).Warn("context_policy.summary_failed_fallback_truncate",
+			zap.Int64("group_id", group.ID),
+			zap.String("summary_model", cfg.SummaryModel),
+			zap.Error(err),
+		)
+		summaryOutcome = outcome
+	default:
+		return nil, tooLong
+	}
+
+	cut, ok := estimate.cutIndex(cfg.MaxInputTokens)
+	if !ok {
+		return summaryOutcome, tooLong
+	}
+	newBody, err := rebuildContextMessages(body, estimate.messages[cut:], "")
+	if err != nil {
+		return summaryOutcome, tooLong
+	}
+	outcome := &ContextPolicyOutcome{
+		Action:          UsageContextActionTruncated,
+		EstimatedTokens: total,
+		FinalTokens:     estimate.tailTotal(cut),
+		DroppedMessages: cut,
+		Body:            newBody,
+	}
+	if summaryOutcome != nil {
+		outcome.SummaryResult = summaryOutcome.SummaryResult
+		outcome.SummaryAccount = summaryOutcome.SummaryAccount
+	}
+	return outcome, nil
+}
+
+// applySummary 预留摘要空间后选择切分点，把切掉的消息交给摘要模型压缩并插入首条保留消息。
+// 失败时返回的 outcome 仅携带已产生的摘要请求用量（可能为 nil）。
+func (s *ContextPolicyService) applySummary(ctx context.Context, groupID int64, cfg *ContextPolicyConfig, body []byte, estimate *contextEstimate) (*ContextPolicyOutcome, error) {
+	budget := cfg.MaxInputTokens - cfg.SummaryMaxTokens - contextPolicySummaryWrapperTokens
+	cut, ok := estimate.cutIndex(budget)
+	if !ok {
+		return nil, fmt.Errorf("no cut point fits within %d tokens", budget)
+	}
+	transcript := renderContextTranscript(estimate.messages[:cut], cfg.MaxInputTokens-cfg.SummaryMaxTokens)
+
+	summary, err := s.summarize(ctx, groupID, cfg, transcript)
+	var usageOnly *ContextPolicyOutcome
+	if summary != nil && summary.result != nil && summary.account != nil {
+		usageOnly = &ContextPolicyOutcome{SummaryResult: summary.result, SummaryAccount: summary.account}
+	}
+	if err != nil {
+		return usageOnly, err
+	}
+	text := strings.TrimSpace(summary.text)
+	if text == "" {
+		return usageOnly, fmt.Errorf("summary model returned empty text")
+	}
+
+	wrapped := fmt.Sprintf("<conversation_summary>\nThe gateway replaced %d earlier messages with this summary to fit the context window:\n%s\n</conversation_summary>", cut, text)
+	newBody, err := rebuildContextMessages(body, estimate.messages[cut:], wrapped)
+	if err != nil {
+		return usageOnly, err
+	}
+	outcome := &ContextPolicyOutcome{
+		Action:          UsageContextActionSummarized,
+		EstimatedTokens: estimate.total(),
+		FinalTokens:     estimate.tailTotal(cut) + estimateTokensForText(wrapped),
+		DroppedMessages: cut,
+		Body:            newBody,
+	}
+	if usageOnly != nil {
+		outcome.SummaryResult = usageOnly.SummaryResult
+		outcome.SummaryAccount = usageOnly.SummaryAccount
+	}
+	return outcome, nil
+}
+
+// forwardSummary 从分组中调度账号，以非流式请求调用摘要模型
+func (s *ContextPolicyService) forwardSummary(ctx context.Context, groupID int64, cfg *ContextPolicyConfig, transcript string) (*contextSummary, error) {
+	summaryCtx, cancel := context.WithTimeout(context.Background(), contextPolicySummaryTimeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	reqBody, err := json.Marshal(map[string]any{
+		"model":      cfg.SummaryModel,
+		"max_tokens": cfg.SummaryMaxTokens,
+		"system":     contextSummarySystemPrompt,
+		"messages": []map[string]any{
+			{"role": "user", "content": transcript},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := s.gatewayService.SelectAccountWithLoadAwareness(summaryCtx, &groupID, "", cfg.SummaryModel, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("select summary account: %w", err)
+	}
+	if !selection.Acquired {
+		return nil, fmt.Errorf("group %d has no idle account for summary", groupID)
+	}
+	defer releaseOrNoop(selection.ReleaseFunc)()
+	account := selection.Account
+
+	resp, err := forwardDetachedClaudeRequest(summaryCtx, s.gatewayService, s.antigravityGatewayService, account, reqBody, nil, contextPolicySummaryCaptureBytes)
+	if resp == nil {
+		return nil, err
+	}
+	summary := &contextSummary{result: resp.result, account: account}
+	if err != nil {
+		return summary, err
+	}
+	if resp.status >= 400 {
+		return summary, fmt.Errorf("summary request failed with status %d", resp.status)
+	}
+	summary.text = ExtractClaudeResponseText(resp.body)
+	return summary, nil
+}
+
+// contextEstimate 请求体的分段 token 估算
+type contextEstimate struct {
+	// overhead messages 以外部分（system / tools 等）的估算
+	overhead int
+	messages []gjson.Result
+	// tokens 每条消息的估算
+	tokens []int
+}
+
+func estimateContextPolicyRequest(body []byte) *contextEstimate {
+	estimate := &contextEstimate{}
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return estimate
+	}
+	root := gjson.ParseBytes(body)
+	estimate.overhead = estimateContextValueTokens(root.Get("system"))
+	if tools := root.Get("tools"); tools.Exists() {
+		estimate.overhead += estimateTokensForText(tools.Raw)
+	}
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		estimate.messages = append(estimate.messages, msg)
+		estimate.tokens = append(estimate.tokens, contextPolicyMessageOverheadTokens+estimateContextValueTokens(msg.Get("content")))
+		return true
+	})
+	return estimate
+}
+
+func (e *contextEstimate) total() int {
+	return e.tailTotal(0)
+}
+
+// tailTotal 保留 messages[from:] 时的估算总量
+func (e *contextEstimate) tailTotal(from int) int {
+	total := e.overhead
+	for _, t := range e.tokens[from:] {
+		total += t
+	}
+	return total
+}
+
+// cutIndex 返回保留 messages[cut:] 即可不超过 limit 的最小切分点（至少丢弃一条消息）
+func (e *contextEstimate) cutIndex(limit int) (int, bool) {
+	best := -1
+	tail := e.overhead
+	for i := len(e.messages) - 1; i >= 1; i-- {
+		tail += e.tokens[i]
+		if tail > limit {
+			break
+		}
+		if isContextCutPoint(e.messages[i]) {
+			best = i
+		}
+	}
+	return best, best > 0
+}
+
+// isContextCutPoint 只能从不含 tool_result 的 user 消息开始保留，
+// 否则其对应的 tool_use 会被丢弃导致上游校验失败
+func isContextCutPoint(msg gjson.Result) bool {
+	if msg.Get("role").String() != "user" {
+		return false
+	}
+	content := msg.Get("content")
+	if !content.IsArray() {
+		return true
+	}
+	for _, block := range content.Array() {
+		if block.Get("type").String() == "tool_result" {
+			return false
+		}
+	}
+	return true
+}
+
+func estimateContextValueTokens(value gjson.Result) int {
+	if !value.Exists() {
+		return 0
+	}
+	var sb strings.Builder
+	media := 0
+	collectContextText(value, "", &sb, &media)
+	return estimateTokensForText(sb.String()) + media*contextPolicyMediaTokens
+}
+
+func collectContextText(value gjson.Result, key string, sb *strings.Builder, media *int) {
+	switch {
+	case value.IsObject():
+		if key == "source" {
+			switch value.Get("type").String() {
+			case "base64", "url", "file":
+				*media++
+				return
+			}
+		}
+		value.ForEach(func(k, v gjson.Result) bool {
+			switch k.String() {
+			case "type", "signature", "cache_control", "id", "tool_use_id", "media_type":
+				return true
+			}
+			sb.WriteString(k.String())
+			sb.WriteByte(' ')
+			collectContextText(v, k.String(), sb, media)
+			return true
+		})
+	case value.IsArray():
+		value.ForEach(func(_, v gjson.Result) bool {
+			collectContextText(v, key, sb, media)
+			return true
+		})
+	case value.Type == gjson.String:
+		sb.WriteString(value.String())
+		sb.WriteByte('\n')
+	case value.Type == gjson.Number, value.Type == gjson.True, value.Type == gjson.False:
+		sb.WriteString(value.Raw)
+		sb.WriteByte(' ')
+	}
+}
+
+// rebuildContextMessages 用 kept 替换 messages；summary 非空时作为文本块插入首条保留消息开头
+func rebuildContextMessages(body []byte, kept []gjson.Result, summary string) ([]byte, error) {
+	raws := make([]string, 0, len(kept))
+	for i, msg := range kept {
+		raw := msg.Raw
+		if i == 0 && summary != "" {
+			var err error
+			if raw, err = prependContextSummary(msg, summary); err != nil {
+				return nil, err
+			}
+		}
+		raws = append(raws, raw)
+	}
+	return sjson.SetRawBytes(body, "messages", []byte("["+strings.Join(raws, ",")+"]"))
+}
+
+func prependContextSummary(msg gjson.Result, summary string) (string, error) {
+	summaryBlock, err := json.Marshal(map[string]string{"type": "text", "text": summary})
+	if err != nil {
+		return "", err
+	}
+	blocks := []string{string(summaryBlock)}
+	content := msg.Get("content")
+	if content.IsArray() {
+		for _, block := range content.Array() {
+			blocks = append(blocks, block.Raw)
+		}
+	} else if text := content.String(); text != "" {
+		textBlock, err := json.Marshal(map[string]string{"type": "text", "text": text})
+		if err != nil {
+			return "", err
+		}
+		blocks = append(blocks, string(textBlock))
+	}
+	return sjson.SetRaw(msg.Raw, "content", "["+strings.Join(blocks, ",")+"]")
+}
+
+// renderContextTranscript 把待摘要的消息渲染为纯文本对话记录；超过 maxTokens 时优先保留较新的消息
+func renderContextTranscript(messages []gjson.Result, maxTokens int) string {
+	parts := make([]string, 0, len(messages))
+	tokens := make([]int, 0, len(messages))
+	total := 0
+	for _, msg := range messages {
+		part := renderContextMessage(msg)
+		if part == "" {
+			continue
+		}
+		t := estimateTokensForText(part)
+		parts = append(parts, part)
+		tokens = append(tokens, t)
+		total += t
+	}
+	start := 0
+	for start < len(parts)-1 && total > maxTokens {
+		total -= tokens[start]
+		start++
+	}
+	transcript := strings.Join(parts[start:], "\n\n")
+	if start > 0 {
+		transcript = "[earlier messages omitted]\n\n" + transcript
+	}
+	return transcript
+}
+
+func renderContextMessage(msg gjson.Result) string {
+	role := "User"
+	if msg.Get("role").String() == "assistant" {
+		role = "Assistant"
+	}
+	content := msg.Get("content")
+	if !content.IsArray() {
+		if text := strings.TrimSpace(content.String()); text != "" {
+			return role + ": " + text
+		}
+		return ""
+	}
+	var lines []string
+	for _, block := range content.Array() {
+		switch block.Get("type").String() {
+		case "text":
+			if text := strings.TrimSpace(block.Get("text").String()); text != "" {
+				lines = append(lines, text)
+			}
+		case "tool_use":
+			lines = append(lines, fmt.Sprintf("[tool call %s: %s]", block.Get("name").String(), block.Get("input").Raw))
+		case "tool_result":
+			lines = append(lines, "[tool result: "+renderContextToolResult(block.Get("content"))+"]")
+		case "image":
+			lines = append(lines, "[image]")
+		case "document":
+			lines = append(lines, "[document]")
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return role + ": " + strings.Join(lines, "\n")
+}
+
+func renderContextToolResult(content gjson.Result) string {
+	if !content.IsArray() {
+		return strings.TrimSpace(content.String())
+	}
+	var texts []string
+	for _, block := range content.Array() {
+		if block.Get("type").String() == "text" {
+			texts = append(texts, strings.TrimSpace(block.Get("text").String()))
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// isContextTooLongMessage 判断上游错误消息是否为上下文超限（已小写）
+func isContextTooLongMessage(msg string) bool {
+	return strings.Contains(msg, "prompt is too long") ||
+		strings.Contains(msg, "request is too long") ||
+		strings.Contains(msg, "context length exceeded") ||
+		strings.Contains(msg, "exceed context limit") ||
+		strings.Contains(msg, "context window")
+}
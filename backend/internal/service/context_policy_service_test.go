@@ -0,0 +1,180 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// contextPolicyTestBody 构造一段多轮工具调用对话，每轮约 filler 个 token
+func contextPolicyTestBody(filler int) []byte {
+	text := strings.Repeat("word ", filler)
+	return []byte(`{"model":"claude-sonnet-4-5","max_tokens":1024,"system":"sys","messages":[
+		{"role":"user","content":"q1 ` + text + `"},
+		{"role":"assistant","content":[{"type":"text","text":"a1"},{"type":"tool_use","id":"t1","name":"read","input":{"path":"a.go"}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"` + text + `"}]},
+		{"role":"assistant","content":"a2 ` + text + `"},
+		{"role":"user","content":[{"type":"text","text":"q2"}]},
+		{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"read","input":{"path":"b.go"}}]},
+		{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}
+	]}`)
+}
+
+func contextPolicyTestGroup(cfg ContextPolicyConfig) *Group {
+	cfg.Enabled = true
+	normalized, err := NormalizeContextPolicyConfig(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	return &Group{ID: 5, ContextPolicy: normalized}
+}
+
+func TestContextPolicyService_UnderLimitUntouched(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	group := contextPolicyTestGroup(ContextPolicyConfig{Action: ContextPolicyActionReject, MaxInputTokens: 4096})
+
+	outcome, err := svc.Apply(context.Background(), group, contextPolicyTestBody(10))
+	require.NoError(t, err)
+	require.Nil(t, outcome)
+
+	outcome, err = svc.Apply(context.Background(), &Group{ID: 5}, contextPolicyTestBody(5000))
+	require.NoError(t, err)
+	require.Nil(t, outcome, "group without policy is untouched")
+}
+
+func TestContextPolicyService_RejectReportsEstimate(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	group := contextPolicyTestGroup(ContextPolicyConfig{Action: ContextPolicyActionReject, MaxInputTokens: 2048})
+
+	_, err := svc.Apply(context.Background(), group, contextPolicyTestBody(2000))
+	var tooLong *ContextTooLongError
+	require.True(t, errors.As(err, &tooLong))
+	require.Equal(t, 2048, tooLong.MaxTokens)
+	require.Greater(t, tooLong.EstimatedTokens, 2048)
+	require.Contains(t, err.Error(), "prompt is too long")
+}
+
+func TestContextPolicyService_TruncateKeepsToolPairing(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	group := contextPolicyTestGroup(ContextPolicyConfig{Action: ContextPolicyActionTruncate, MaxInputTokens: 2048})
+
+	outcome, err := svc.Apply(context.Background(), group, contextPolicyTestBody(2000))
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	require.Equal(t, UsageContextActionTruncated, outcome.Action)
+	// 索引 2 的 tool_result 不能作为起点，只能从 q2 开始保留
+	require.Equal(t, 4, outcome.DroppedMessages)
+	require.LessOrEqual(t, outcome.FinalTokens, 2048)
+
+	messages := gjson.GetBytes(outcome.Body, "messages").Array()
+	require.Len(t, messages, 3)
+	require.Equal(t, "q2", messages[0].Get("content.0.text").String())
+	require.Equal(t, "t2", messages[2].Get("content.0.tool_use_id").String())
+	require.Equal(t, "sys", gjson.GetBytes(outcome.Body, "system").String())
+
+	log := &UsageLog{}
+	outcome.tagUsageLog(log)
+	require.Equal(t, UsageContextActionTruncated, *log.ContextAction)
+	require.Equal(t, 4, log.ContextDroppedMessages)
+}
+
+func TestContextPolicyService_TruncateRejectsWhenTailTooLarge(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	group := contextPolicyTestGroup(ContextPolicyConfig{Action: ContextPolicyActionTruncate, MaxInputTokens: 2048})
+	text := strings.Repeat("word ", 3000)
+	body := []byte(`{"model":"m","messages":[{"role":"user","content":"q1"},{"role":"assistant","content":"a1"},{"role":"user","content":"` + text + `"}]}`)
+
+	outcome, err := svc.Apply(context.Background(), group, body)
+	var tooLong *ContextTooLongError
+	require.True(t, errors.As(err, &tooLong))
+	require.Nil(t, outcome)
+}
+
+func TestContextPolicyService_SummarizePrependsSummary(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	var gotTranscript string
+	summaryResult := &ForwardResult{RequestID: "req-sum", Model: "claude-haiku-4-5"}
+	svc.summarize = func(_ context.Context, groupID int64, cfg *ContextPolicyConfig, transcript string) (*contextSummary, error) {
+		require.Equal(t, int64(5), groupID)
+		require.Equal(t, "claude-haiku-4-5", cfg.SummaryModel)
+		gotTranscript = transcript
+		return &contextSummary{text: "user asked to read a.go", result: summaryResult, account: &Account{ID: 8}}, nil
+	}
+	group := contextPolicyTestGroup(ContextPolicyConfig{
+		Action:           ContextPolicyActionSummarize,
+		MaxInputTokens:   4096,
+		SummaryModel:     "claude-haiku-4-5",
+		SummaryMaxTokens: 512,
+	})
+
+	outcome, err := svc.Apply(context.Background(), group, contextPolicyTestBody(2000))
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	require.Equal(t, UsageContextActionSummarized, outcome.Action)
+	require.Equal(t, 4, outcome.DroppedMessages)
+	// 待摘要部分超出摘要模型预算时优先保留较新的消息
+	require.True(t, strings.HasPrefix(gotTranscript, "[earlier messages omitted]"))
+	require.Contains(t, gotTranscript, "Assistant: a2")
+
+	first := gjson.GetBytes(outcome.Body, "messages.0")
+	require.Equal(t, "user", first.Get("role").String())
+	require.Contains(t, first.Get("content.0.text").String(), "user asked to read a.go")
+	require.Equal(t, "q2", first.Get("content.1.text").String())
+
+	summaryOutcome := outcome.SummaryRequestOutcome()
+	require.NotNil(t, summaryOutcome)
+	require.Equal(t, UsageContextActionSummaryRequest, summaryOutcome.Action)
+	require.Same(t, summaryResult, outcome.SummaryResult)
+}
+
+func TestContextPolicyService_SummarizeFailureFallsBackToTruncate(t *testing.T) {
+	svc := NewContextPolicyService(nil, nil)
+	summaryResult := &ForwardResult{RequestID: "req-sum"}
+	svc.summarize = func(context.Context, int64, *ContextPolicyConfig, string) (*contextSummary, error) {
+		return &contextSummary{result: summaryResult, account: &Account{ID: 8}}, errors.New("upstream 529")
+	}
+	group := contextPolicyTestGroup(ContextPolicyConfig{
+		Action:           ContextPolicyActionSummarize,
+		MaxInputTokens:   4096,
+		SummaryModel:     "claude-haiku-4-5",
+		SummaryMaxTokens: 512,
+	})
+
+	outcome, err := svc.Apply(context.Background(), group, contextPolicyTestBody(2000))
+	require.NoError(t, err)
+	require.Equal(t, UsageContextActionTruncated, outcome.Action)
+	require.Same(t, summaryResult, outcome.SummaryResult, "billed summary call is still recorded")
+}
+
+func TestRenderContextTranscript_RendersToolBlocks(t *testing.T) {
+	messages := gjson.GetBytes(contextPolicyTestBody(1), "messages").Array()
+	transcript := renderContextTranscript(messages[:4], 4096)
+	require.Contains(t, transcript, "User: q1 word")
+	require.Contains(t, transcript, `[tool call read: {"path":"a.go"}]`)
+	require.Contains(t, transcript, "[tool result: word]")
+	require.NotContains(t, transcript, "[earlier messages omitted]")
+}
+
+func TestEstimateContextPolicyRequest_CountsMediaAndSkipsSignatures(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[
+		{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + strings.Repeat("A", 100000) + `"}},
+		{"type":"thinking","thinking":"hm","signature":"` + strings.Repeat("S", 100000) + `"}
+	]}]}`)
+	estimate := estimateContextPolicyRequest(body)
+	require.Len(t, estimate.messages, 1)
+	require.Greater(t, estimate.total(), contextPolicyMediaTokens)
+	require.Less(t, estimate.total(), contextPolicyMediaTokens+100)
+}
+
+func TestShouldFailoverOn400_PromptTooLongDoesNotFailover(t *testing.T) {
+	svc := &GatewayService{}
+	require.False(t, svc.shouldFailoverOn400([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`)))
+	require.False(t, svc.shouldFailoverOn400([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"input length and max_tokens exceed context limit; tool_result blocks included"}}`)))
+	require.True(t, svc.shouldFailoverOn400([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"unexpected tool_use_id found in tool_result blocks"}}`)))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	CostAnomalySubjectAPIKey = "api_key"
+	CostAnomalySubjectUser   = "user"
+
+	CostAnomalyMetricCost     = "cost"
+	CostAnomalyMetricRequests = "requests"
+
+	// costAnomalyAlertSource 写入告警事件 dimensions.source，用于区分规则告警与异常检测告警
+	costAnomalyAlertSource = "cost_anomaly"
+)
+
+// CostAnomalyBaseline 某个对象（Key 或用户）在基线窗口内的小时级用量统计
+type CostAnomalyBaseline struct {
+	SubjectID int64
+	UserID    int64
+	// FirstBucket 窗口内最早有用量的小时，用于计算有效小时数（新对象不被零值稀释）
+	FirstBucket   time.Time
+	SumCost       float64
+	SumCostSq     float64
+	SumRequests   float64
+	SumRequestsSq float64
+}
+
+// CostAnomalyUsage 最近一小时单个 API Key 的用量
+type CostAnomalyUsage struct {
+	APIKeyID int64
+	UserID   int64
+	Cost     float64
+	Requests int64
+}
+
+// CostAnomaly 检测到的一次异常
+type CostAnomaly struct {
+	Subject   string  `json:"subject"`
+	SubjectID int64   `json:"subject_id"`
+	UserID    int64   `json:"user_id"`
+	Metric    string  `json:"metric"`
+	Current   float64 `json:"current"`
+	Mean      float64 `json:"baseline_mean"`
+	StdDev    float64 `json:"baseline_stddev"`
+	ZScore    float64 `json:"z_score"`
+	Threshold float64 `json:"threshold"`
+	// HasBaseline 为 false 表示按无基线上限判定
+	HasBaseline bool `json:"has_baseline"`
+	Suspended   bool `json:"suspended"`
+}
+
+// CostAnomalyBaselineView 管理端查看的学习基线
+type CostAnomalyBaselineView struct {
+	Subject           string  `json:"subject"`
+	SubjectID         int64   `json:"subject_id"`
+	BaselineHours     float64 `json:"baseline_hours"`
+	HasBaseline       bool    `json:"has_baseline"`
+	CostMean          float64 `json:"cost_mean"`
+	CostStdDev        float64 `json:"cost_stddev"`
+	RequestsMean      float64 `json:"requests_mean"`
+	RequestsStdDev    float64 `json:"requests_stddev"`
+	CostThreshold     float64 `json:"cost_threshold"`
+	RequestsThreshold float64 `json:"requests_threshold"`
+}
+
+// CostAnomalyRepository 费用异常检测的数据访问接口
+type CostAnomalyRepository interface {
+	// RefreshHourly 按 usage_logs 重新计算 [from, to) 覆盖的小时聚合
+	RefreshHourly(ctx context.Context, from, to time.Time) error
+	PruneHourly(ctx context.Context, before time.Time) (int64, error)
+	// ListBaselines 返回指定对象在 [start, end) 内的小时统计；subject 为 api_key 或 user
+	ListBaselines(ctx context.Context, subject string, ids []int64, start, end time.Time) ([]CostAnomalyBaseline, error)
+	// ListRecentUsage 返回 since 之后各 API Key 的用量
+	ListRecentUsage(ctx context.Context, since time.Time) ([]CostAnomalyUsage, error)
+	// HasRecentAlert 判断 since 之后是否已有同一对象的异常告警
+	HasRecentAlert(ctx context.Context, subject string, subjectID int64, since time.Time) (bool, error)
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	costAnomalyWorkerName    = "cost_anomaly:detect"
+	costAnomalyLockKey       = "cost_anomaly:detect:leader"
+	costAnomalyRunTimeout    = 5 * time.Minute
+	costAnomalyActionTimeout = 10 * time.Second
+)
+
+// CostAnomalyService 费用异常检测：
+// 从小时级用量聚合学习每个 API Key / 用户的正常小时费用与请求数（均值、标准差），
+// 最近一小时超过 z-score 或倍数阈值时写入运维告警事件，可选自动停用 Key 并邮件通知用户。
+type CostAnomalyService struct {
+	repo          CostAnomalyRepository
+	opsRepo       OpsRepository
+	apiKeyService *APIKeyService
+	userRepo      UserRepository
+	emailService  *EmailService
+	timingWheel   *TimingWheelService
+	db            *sql.DB
+	cfg           config.CostAnomalyConfig
+
+	mu          sync.Mutex
+	refreshedTo time.Time
+}
+
+// NewCostAnomalyService 创建费用异常检测服务
+func NewCostAnomalyService(
+	repo CostAnomalyRepository,
+	opsRepo OpsRepository,
+	apiKeyService *APIKeyService,
+	userRepo UserRepository,
+	emailService *EmailService,
+	timingWheel *TimingWheelService,
+	db *sql.DB,
+	cfg *config.Config,
+) *CostAnomalyService {
+	var anomalyCfg config.CostAnomalyConfig
+	if cfg != nil {
+		anomalyCfg = cfg.CostAnomaly
+	}
+	return &CostAnomalyService{
+		repo:          repo,
+		opsRepo:       opsRepo,
+		apiKeyService: apiKeyService,
+		userRepo:      userRepo,
+		emailService:  emailService,
+		timingWheel:   timingWheel,
+		db:            db,
+		cfg:           anomalyCfg,
+	}
+}
+
+// Start 启动定时检测
+func (s *CostAnomalyService) Start() {
+	if s == nil || !s.cfg.Enabled || s.repo == nil || s.timingWheel == nil {
+		return
+	}
+	interval := time.Duration(s.cfg.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	s.timingWheel.ScheduleRecurring(costAnomalyWorkerName, interval, s.runOnce)
+	logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] started (interval=%s baseline_days=%d auto_suspend=%v)", interval, s.cfg.BaselineDays, s.cfg.AutoSuspend)
+}
+
+func (s *CostAnomalyService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), costAnomalyRunTimeout)
+	defer cancel()
+
+	if s.db != nil {
+		release, ok := tryAcquireDBAdvisoryLock(ctx, s.db, hashAdvisoryLockID(costAnomalyLockKey))
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
+	anomalies, err := s.Detect(ctx, time.Now().UTC())
+	if err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] detect failed: %v", err)
+		return
+	}
+	if len(anomalies) > 0 {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] detected anomalies: count=%d", len(anomalies))
+	}
+}
+
+// Detect 刷新小时聚合并检测最近一小时的异常，对新异常执行告警/停用/通知
+func (s *CostAnomalyService) Detect(ctx context.Context, now time.Time) ([]CostAnomaly, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil
+	}
+	if err := s.refresh(ctx, now); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.repo.ListRecentUsage(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("list recent usage: %w", err)
+	}
+	if len(usage) == 0 {
+		return nil, nil
+	}
+
+	baselineEnd, baselineStart := s.baselineWindow(now)
+	keyUsage := make(map[int64]CostAnomalyUsage, len(usage))
+	userUsage := make(map[int64]CostAnomalyUsage)
+	keyIDs := make([]int64, 0, len(usage))
+	for _, u := range usage {
+		keyUsage[u.APIKeyID] = u
+		keyIDs = append(keyIDs, u.APIKeyID)
+		agg := userUsage[u.UserID]
+		agg.UserID = u.UserID
+		agg.Cost += u.Cost
+		agg.Requests += u.Requests
+		userUsage[u.UserID] = agg
+	}
+	userIDs := make([]int64, 0, len(userUsage))
+	for id := range userUsage {
+		userIDs = append(userIDs, id)
+	}
+
+	keyBaselines, err := s.listBaselineMap(ctx, CostAnomalySubjectAPIKey, keyIDs, baselineStart, baselineEnd)
+	if err != nil {
+		return nil, err
+	}
+	userBaselines, err := s.listBaselineMap(ctx, CostAnomalySubjectUser, userIDs, baselineStart, baselineEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]CostAnomaly, 0)
+	for _, id := range keyIDs {
+		u := keyUsage[id]
+		baseline, ok := keyBaselines[id]
+		candidates = append(candidates, s.evaluate(CostAnomalySubjectAPIKey, id, u.UserID, u, baseline, ok, baselineStart, baselineEnd)...)
+	}
+	for _, id := range userIDs {
+		u := userUsage[id]
+		baseline, ok := userBaselines[id]
+		candidates = append(candidates, s.evaluate(CostAnomalySubjectUser, id, id, u, baseline, ok, baselineStart, baselineEnd)...)
+	}
+
+	fired := make([]CostAnomaly, 0, len(candidates))
+	handled := make(map[string]bool)
+	for _, anomaly := range candidates {
+		// 同一对象费用与请求数同时异常时只告警一次（优先费用）
+		key := anomaly.Subject + ":" + fmt.Sprint(anomaly.SubjectID)
+		if handled[key] {
+			continue
+		}
+		handled[key] = true
+		if s.inCooldown(ctx, anomaly, now) {
+			continue
+		}
+		s.handle(ctx, &anomaly, now)
+		fired = append(fired, anomaly)
+	}
+	return fired, nil
+}
+
+// GetBaseline 返回对象当前学习到的基线与阈值（管理端排查用）
+func (s *CostAnomalyService) GetBaseline(ctx context.Context, subject string, subjectID int64) (*CostAnomalyBaselineView, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("cost anomaly service not ready")
+	}
+	now := time.Now().UTC()
+	baselineEnd, baselineStart := s.baselineWindow(now)
+	baselines, err := s.listBaselineMap(ctx, subject, []int64{subjectID}, baselineStart, baselineEnd)
+	if err != nil {
+		return nil, err
+	}
+	view := &CostAnomalyBaselineView{Subject: subject, SubjectID: subjectID}
+	baseline, ok := baselines[subjectID]
+	if !ok {
+		return view, nil
+	}
+	hours := costAnomalyBaselineHours(baseline, baselineStart, baselineEnd)
+	view.BaselineHours = hours
+	view.HasBaseline = hours >= float64(s.cfg.MinBaselineHours)
+	view.CostMean, view.CostStdDev = costAnomalyStats(baseline.SumCost, baseline.SumCostSq, hours)
+	view.RequestsMean, view.RequestsStdDev = costAnomalyStats(baseline.SumRequests, baseline.SumRequestsSq, hours)
+	view.CostThreshold = s.threshold(view.CostMean, view.CostStdDev)
+	view.RequestsThreshold = s.threshold(view.RequestsMean, view.RequestsStdDev)
+	return view, nil
+}
+
+func (s *CostAnomalyService) refresh(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	from := s.refreshedTo
+	s.mu.Unlock()
+
+	currentHour := now.Truncate(time.Hour)
+	if from.IsZero() {
+		// 首次运行回填整个基线窗口
+		from = currentHour.AddDate(0, 0, -s.baselineDays())
+	} else {
+		// 重算上一小时，覆盖延迟写入的用量
+		from = from.Truncate(time.Hour).Add(-time.Hour)
+	}
+	if err := s.repo.RefreshHourly(ctx, from, now); err != nil {
+		return fmt.Errorf("refresh hourly aggregates: %w", err)
+	}
+	if _, err := s.repo.PruneHourly(ctx, currentHour.AddDate(0, 0, -s.baselineDays()-1)); err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] prune hourly aggregates failed: %v", err)
+	}
+
+	s.mu.Lock()
+	s.refreshedTo = now
+	s.mu.Unlock()
+	return nil
+}
+
+// baselineWindow 基线窗口截止到上一个完整小时之前，避免与最近一小时的检测窗口重叠
+func (s *CostAnomalyService) baselineWindow(now time.Time) (end, start time.Time) {
+	end = now.Add(-time.Hour).Truncate(time.Hour)
+	start = end.AddDate(0, 0, -s.baselineDays())
+	return end, start
+}
+
+func (s *CostAnomalyService) listBaselineMap(ctx context.Context, subject string, ids []int64, start, end time.Time) (map[int64]CostAnomalyBaseline, error) {
+	out := make(map[int64]CostAnomalyBaseline, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	baselines, err := s.repo.ListBaselines(ctx, subject, ids, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("list %s baselines: %w", subject, err)
+	}
+	for _, b := range baselines {
+		out[b.SubjectID] = b
+	}
+	return out, nil
+}
+
+// evaluate 按费用、请求数依次判定；返回的切片中费用异常在前
+func (s *CostAnomalyService) evaluate(subject string, subjectID, userID int64, usage CostAnomalyUsage, baseline CostAnomalyBaseline, found bool, start, end time.Time) []CostAnomaly {
+	var out []CostAnomaly
+	base := CostAnomaly{Subject: subject, SubjectID: subjectID, UserID: userID}
+
+	hours := 0.0
+	if found {
+		hours = costAnomalyBaselineHours(baseline, start, end)
+	}
+	if !found || hours < float64(s.cfg.MinBaselineHours) || hours <= 0 {
+		if s.cfg.NoBaselineHourlyCost > 0 && usage.Cost >= s.cfg.NoBaselineHourlyCost {
+			a := base
+			a.Metric = CostAnomalyMetricCost
+			a.Current = usage.Cost
+			a.Threshold = s.cfg.NoBaselineHourlyCost
+			out = append(out, a)
+		}
+		return out
+	}
+
+	costMean, costStd := costAnomalyStats(baseline.SumCost, baseline.SumCostSq, hours)
+	if usage.Cost >= s.cfg.MinHourlyCost {
+		if threshold, z, ok := s.exceeds(usage.Cost, costMean, costStd); ok {
+			a := base
+			a.Metric = CostAnomalyMetricCost
+			a.Current = usage.Cost
+			a.Mean, a.StdDev, a.ZScore, a.Threshold, a.HasBaseline = costMean, costStd, z, threshold, true
+			out = append(out, a)
+		}
+	}
+	reqMean, reqStd := costAnomalyStats(baseline.SumRequests, baseline.SumRequestsSq, hours)
+	if usage.Requests >= s.cfg.MinHourlyRequests {
+		if threshold, z, ok := s.exceeds(float64(usage.Requests), reqMean, reqStd); ok {
+			a := base
+			a.Metric = CostAnomalyMetricRequests
+			a.Current = float64(usage.Requests)
+			a.Mean, a.StdDev, a.ZScore, a.Threshold, a.HasBaseline = reqMean, reqStd, z, threshold, true
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// exceeds 判断当前值是否超过 z-score 或倍数阈值，返回实际越过的（较低）阈值
+func (s *CostAnomalyService) exceeds(current, mean, std float64) (float64, float64, bool) {
+	var z float64
+	if std > 0 {
+		z = (current - mean) / std
+	}
+	threshold := s.threshold(mean, std)
+	if threshold <= 0 || current < threshold {
+		return threshold, z, false
+	}
+	return threshold, z, true
+}
+
+// threshold 取 z-score 与倍数两个判据中较低的阈值；标准差为 0 时仅使用倍数判据
+func (s *CostAnomalyService) threshold(mean, std float64) float64 {
+	threshold := math.Inf(1)
+	if s.cfg.ZScoreThreshold > 0 && std > 0 {
+		threshold = mean + s.cfg.ZScoreThreshold*std
+	}
+	if s.cfg.Multiplier > 0 && mean > 0 {
+		threshold = math.Min(threshold, mean*s.cfg.Multiplier)
+	}
+	if math.IsInf(threshold, 1) {
+		return 0
+	}
+	return threshold
+}
+
+func (s *CostAnomalyService) inCooldown(ctx context.Context, anomaly CostAnomaly, now time.Time) bool {
+	if s.cfg.CooldownMinutes <= 0 {
+		return false
+	}
+	since := now.Add(-time.Duration(s.cfg.CooldownMinutes) * time.Minute)
+	exists, err := s.repo.HasRecentAlert(ctx, anomaly.Subject, anomaly.SubjectID, since)
+	if err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] cooldown check failed: subject=%s id=%d err=%v", anomaly.Subject, anomaly.SubjectID, err)
+		// 无法确认时宁可重复告警，也不漏报
+		return false
+	}
+	return exists
+}
+
+func (s *CostAnomalyService) handle(ctx context.Context, anomaly *CostAnomaly, now time.Time) {
+	if s.cfg.AutoSuspend && anomaly.Subject == CostAnomalySubjectAPIKey && s.apiKeyService != nil {
+		actionCtx, cancel := context.WithTimeout(ctx, costAnomalyActionTimeout)
+		_, changed, err := s.apiKeyService.Suspend(actionCtx, anomaly.SubjectID)
+		cancel()
+		if err != nil {
+			logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] suspend api key failed: key=%d err=%v", anomaly.SubjectID, err)
+		} else {
+			anomaly.Suspended = changed
+		}
+	}
+
+	logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] anomaly: subject=%s id=%d user=%d metric=%s current=%.4f mean=%.4f stddev=%.4f threshold=%.4f suspended=%v",
+		anomaly.Subject, anomaly.SubjectID, anomaly.UserID, anomaly.Metric, anomaly.Current, anomaly.Mean, anomaly.StdDev, anomaly.Threshold, anomaly.Suspended)
+
+	emailSent := false
+	if s.cfg.NotifyUser {
+		emailSent = s.notifyUser(ctx, anomaly)
+	}
+	s.recordAlert(ctx, anomaly, now, emailSent)
+}
+
+func (s *CostAnomalyService) recordAlert(ctx context.Context, anomaly *CostAnomaly, now time.Time, emailSent bool) {
+	if s.opsRepo == nil {
+		return
+	}
+	severity := "P2"
+	if anomaly.Suspended {
+		severity = "P1"
+	}
+	current := anomaly.Current
+	threshold := anomaly.Threshold
+	event := &OpsAlertEvent{
+		Severity:       severity,
+		Status:         OpsAlertStatusFiring,
+		Title:          costAnomalyTitle(anomaly),
+		Description:    costAnomalyDescription(anomaly),
+		MetricValue:    &current,
+		ThresholdValue: &threshold,
+		Dimensions: map[string]any{
+			"source":          costAnomalyAlertSource,
+			"subject":         anomaly.Subject,
+			"subject_id":      anomaly.SubjectID,
+			"user_id":         anomaly.UserID,
+			"metric":          anomaly.Metric,
+			"baseline_mean":   anomaly.Mean,
+			"baseline_stddev": anomaly.StdDev,
+			"z_score":         anomaly.ZScore,
+			"has_baseline":    anomaly.HasBaseline,
+			"auto_suspended":  anomaly.Suspended,
+		},
+		FiredAt:   now,
+		EmailSent: emailSent,
+		CreatedAt: now,
+	}
+	actionCtx, cancel := context.WithTimeout(ctx, costAnomalyActionTimeout)
+	defer cancel()
+	if _, err := s.opsRepo.CreateAlertEvent(actionCtx, event); err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] create alert event failed: subject=%s id=%d err=%v", anomaly.Subject, anomaly.SubjectID, err)
+	}
+}
+
+func (s *CostAnomalyService) notifyUser(ctx context.Context, anomaly *CostAnomaly) bool {
+	if s.emailService == nil || s.userRepo == nil || anomaly.UserID <= 0 {
+		return false
+	}
+	actionCtx, cancel := context.WithTimeout(ctx, costAnomalyActionTimeout)
+	defer cancel()
+	user, err := s.userRepo.GetByID(actionCtx, anomaly.UserID)
+	if err != nil || user == nil || strings.TrimSpace(user.Email) == "" {
+		return false
+	}
+	if err := s.emailService.SendEmail(actionCtx, user.Email, "[Usage Alert] "+costAnomalyTitle(anomaly), buildCostAnomalyEmailBody(anomaly)); err != nil {
+		logger.LegacyPrintf("service.cost_anomaly", "[CostAnomaly] notify user failed: user=%d err=%v", anomaly.UserID, err)
+		return false
+	}
+	return true
+}
+
+func (s *CostAnomalyService) baselineDays() int {
+	if s.cfg.BaselineDays <= 0 {
+		return 14
+	}
+	return s.cfg.BaselineDays
+}
+
+// costAnomalyBaselineHours 有效基线小时数：从窗口内首次出现用量开始计，未出现用量的小时按 0 计入
+func costAnomalyBaselineHours(b CostAnomalyBaseline, start, end time.Time) float64 {
+	from := start
+	if b.FirstBucket.After(from) {
+		from = b.FirstBucket
+	}
+	return end.Sub(from).Hours()
+}
+
+// costAnomalyStats 由和与平方和计算总体均值与标准差
+func costAnomalyStats(sum, sumSq, n float64) (float64, float64) {
+	if n <= 0 {
+		return 0, 0
+	}
+	mean := sum / n
+	variance := sumSq/n - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, math.Sqrt(variance)
+}
+
+func costAnomalyTitle(a *CostAnomaly) string {
+	subject := "API key"
+	if a.Subject == CostAnomalySubjectUser {
+		subject = "User"
+	}
+	metric := "spend"
+	if a.Metric == CostAnomalyMetricRequests {
+		metric = "request volume"
+	}
+	return fmt.Sprintf("Abnormal %s on %s #%d", metric, subject, a.SubjectID)
+}
+
+func costAnomalyDescription(a *CostAnomaly) string {
+	var b strings.Builder
+	if a.Metric == CostAnomalyMetricCost {
+		fmt.Fprintf(&b, "Last hour spend $%.4f exceeds threshold $%.4f", a.Current, a.Threshold)
+	} else {
+		fmt.Fprintf(&b, "Last hour requests %.0f exceed threshold %.0f", a.Current, a.Threshold)
+	}
+	if a.HasBaseline {
+		fmt.Fprintf(&b, " (baseline mean %.4f, stddev %.4f, z=%.2f)", a.Mean, a.StdDev, a.ZScore)
+	} else {
+		b.WriteString(" (no baseline yet)")
+	}
+	if a.Subject == CostAnomalySubjectAPIKey {
+		fmt.Fprintf(&b, "; user #%d", a.UserID)
+	}
+	if a.Suspended {
+		b.WriteString("; API key suspended automatically")
+	}
+	return b.String()
+}
+
+func buildCostAnomalyEmailBody(a *CostAnomaly) string {
+	action := "<p>If this usage is expected, no action is needed. Otherwise please rotate the affected API key immediately.</p>"
+	if a.Suspended {
+		action = "<p>The API key has been <b>suspended</b> to prevent further charges. Please contact the administrator to restore it, and rotate the key if it may have leaked.</p>"
+	}
+	return fmt.Sprintf(`
+<h2>Unusual usage detected</h2>
+<p>%s</p>
+%s
+`, html.EscapeString(costAnomalyDescription(a)), action)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type costAnomalyRepoStub struct {
+	usage      []CostAnomalyUsage
+	baselines  map[string][]CostAnomalyBaseline
+	alerted    map[string]bool
+	refreshed  int
+	lastPruned time.Time
+}
+
+func (r *costAnomalyRepoStub) RefreshHourly(ctx context.Context, from, to time.Time) error {
+	r.refreshed++
+	return nil
+}
+
+func (r *costAnomalyRepoStub) PruneHourly(ctx context.Context, before time.Time) (int64, error) {
+	r.lastPruned = before
+	return 0, nil
+}
+
+func (r *costAnomalyRepoStub) ListBaselines(ctx context.Context, subject string, ids []int64, start, end time.Time) ([]CostAnomalyBaseline, error) {
+	return r.baselines[subject], nil
+}
+
+func (r *costAnomalyRepoStub) ListRecentUsage(ctx context.Context, since time.Time) ([]CostAnomalyUsage, error) {
+	return r.usage, nil
+}
+
+func (r *costAnomalyRepoStub) HasRecentAlert(ctx context.Context, subject string, subjectID int64, since time.Time) (bool, error) {
+	return r.alerted[subject], nil
+}
+
+type costAnomalyOpsRepoStub struct {
+	OpsRepository
+	events []*OpsAlertEvent
+}
+
+func (r *costAnomalyOpsRepoStub) CreateAlertEvent(ctx context.Context, event *OpsAlertEvent) (*OpsAlertEvent, error) {
+	r.events = append(r.events, event)
+	return event, nil
+}
+
+func newCostAnomalyTestService(repo CostAnomalyRepository, opsRepo OpsRepository) *CostAnomalyService {
+	cfg := &config.Config{CostAnomaly: config.CostAnomalyConfig{
+		Enabled:              true,
+		BaselineDays:         14,
+		MinBaselineHours:     24,
+		ZScoreThreshold:      4,
+		Multiplier:           5,
+		MinHourlyCost:        5,
+		MinHourlyRequests:    200,
+		NoBaselineHourlyCost: 50,
+		CooldownMinutes:      60,
+	}}
+	return NewCostAnomalyService(repo, opsRepo, nil, nil, nil, nil, nil, cfg)
+}
+
+// costAnomalyFlatBaseline 构造每小时恒定用量的基线（标准差为 0）
+func costAnomalyFlatBaseline(id, userID int64, hours, cost, requests float64, end time.Time) CostAnomalyBaseline {
+	return CostAnomalyBaseline{
+		SubjectID:     id,
+		UserID:        userID,
+		FirstBucket:   end.Add(-time.Duration(hours) * time.Hour),
+		SumCost:       cost * hours,
+		SumCostSq:     cost * cost * hours,
+		SumRequests:   requests * hours,
+		SumRequestsSq: requests * requests * hours,
+	}
+}
+
+func TestCostAnomalyStats(t *testing.T) {
+	mean, std := costAnomalyStats(2+4+4+4+5+5+7+9, 4+16+16+16+25+25+49+81, 8)
+	require.InDelta(t, 5.0, mean, 1e-9)
+	require.InDelta(t, 2.0, std, 1e-9)
+
+	mean, std = costAnomalyStats(0, 0, 0)
+	require.Zero(t, mean)
+	require.Zero(t, std)
+}
+
+func TestCostAnomalyThresholdUsesLowerCriterion(t *testing.T) {
+	svc := newCostAnomalyTestService(nil, nil)
+
+	// mean+4σ = 18 < 5×mean = 20
+	require.InDelta(t, 18.0, svc.threshold(4, 3.5), 1e-9)
+	// 标准差为 0 时只用倍数判据
+	require.InDelta(t, 20.0, svc.threshold(4, 0), 1e-9)
+	require.Zero(t, svc.threshold(0, 0))
+}
+
+func TestCostAnomalyDetectFlagsSpikeAndRecordsAlert(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	svc := newCostAnomalyTestService(nil, nil)
+	end, _ := svc.baselineWindow(now)
+
+	repo := &costAnomalyRepoStub{
+		usage: []CostAnomalyUsage{
+			{APIKeyID: 1, UserID: 10, Cost: 30, Requests: 100},
+			{APIKeyID: 2, UserID: 20, Cost: 3, Requests: 50},
+		},
+		baselines: map[string][]CostAnomalyBaseline{
+			CostAnomalySubjectAPIKey: {
+				costAnomalyFlatBaseline(1, 10, 72, 2, 100, end),
+				costAnomalyFlatBaseline(2, 20, 72, 2, 50, end),
+			},
+			// 用户维度基线足够高，不应触发
+			CostAnomalySubjectUser: {
+				costAnomalyFlatBaseline(10, 10, 72, 20, 500, end),
+				costAnomalyFlatBaseline(20, 20, 72, 20, 500, end),
+			},
+		},
+	}
+	opsRepo := &costAnomalyOpsRepoStub{}
+	svc = newCostAnomalyTestService(repo, opsRepo)
+
+	anomalies, err := svc.Detect(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	require.Equal(t, CostAnomalySubjectAPIKey, anomalies[0].Subject)
+	require.Equal(t, int64(1), anomalies[0].SubjectID)
+	require.Equal(t, CostAnomalyMetricCost, anomalies[0].Metric)
+	require.True(t, anomalies[0].HasBaseline)
+	require.InDelta(t, 10.0, anomalies[0].Threshold, 1e-9)
+
+	require.Len(t, opsRepo.events, 1)
+	event := opsRepo.events[0]
+	require.Equal(t, "P2", event.Severity)
+	require.Equal(t, costAnomalyAlertSource, event.Dimensions["source"])
+	require.Equal(t, int64(1), event.Dimensions["subject_id"])
+	require.Equal(t, 1, repo.refreshed)
+}
+
+func TestCostAnomalyDetectWithoutBaselineUsesCap(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	svc := newCostAnomalyTestService(nil, nil)
+	end, _ := svc.baselineWindow(now)
+
+	repo := &costAnomalyRepoStub{
+		usage: []CostAnomalyUsage{
+			{APIKeyID: 1, UserID: 10, Cost: 40, Requests: 1000},
+			{APIKeyID: 2, UserID: 20, Cost: 60, Requests: 10},
+		},
+		baselines: map[string][]CostAnomalyBaseline{
+			// 仅 3 小时历史，不足 min_baseline_hours
+			CostAnomalySubjectAPIKey: {costAnomalyFlatBaseline(1, 10, 3, 0.1, 1, end)},
+		},
+	}
+	svc = newCostAnomalyTestService(repo, &costAnomalyOpsRepoStub{})
+
+	anomalies, err := svc.Detect(context.Background(), now)
+	require.NoError(t, err)
+	// Key 2 与用户 20 都超过无基线上限 50；Key 1 低于上限不告警
+	require.Len(t, anomalies, 2)
+	for _, a := range anomalies {
+		require.Equal(t, int64(20), a.UserID)
+		require.False(t, a.HasBaseline)
+		require.InDelta(t, 50.0, a.Threshold, 1e-9)
+	}
+}
+
+func TestCostAnomalyDetectSkipsWithinCooldown(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	repo := &costAnomalyRepoStub{
+		usage:   []CostAnomalyUsage{{APIKeyID: 1, UserID: 10, Cost: 80, Requests: 10}},
+		alerted: map[string]bool{CostAnomalySubjectAPIKey: true},
+	}
+	opsRepo := &costAnomalyOpsRepoStub{}
+	svc := newCostAnomalyTestService(repo, opsRepo)
+
+	anomalies, err := svc.Detect(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	require.Equal(t, CostAnomalySubjectUser, anomalies[0].Subject)
+	require.Len(t, opsRepo.events, 1)
+}
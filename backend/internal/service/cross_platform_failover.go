@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CrossPlatformFailoverPeer 返回跨平台故障转移的对端平台。
+// anthropic 与 antigravity 账号都能承接 Claude Messages 协议请求（antigravity 侧复用
+// internal/pkg/antigravity 的请求转换），因此互为后备；其他平台返回空串。
+func CrossPlatformFailoverPeer(platform string) string {
+	switch platform {
+	case PlatformAnthropic:
+		return PlatformAntigravity
+	case PlatformAntigravity:
+		return PlatformAnthropic
+	default:
+		return ""
+	}
+}
+
+// CrossPlatformFailoverStat 单个分组、单个方向的跨平台故障转移统计
+type CrossPlatformFailoverStat struct {
+	GroupID         int64     `json:"group_id"`
+	FromPlatform    string    `json:"from_platform"`
+	ToPlatform      string    `json:"to_platform"`
+	TriggeredTotal  int64     `json:"triggered_total"`
+	SucceededTotal  int64     `json:"succeeded_total"`
+	LastTriggeredAt time.Time `json:"last_triggered_at"`
+}
+
+type crossPlatformFailoverKey struct {
+	groupID  int64
+	from, to string
+}
+
+// crossPlatformFailoverStats 进程内跨平台故障转移计数，零值可用
+type crossPlatformFailoverStats struct {
+	mu    sync.Mutex
+	stats map[crossPlatformFailoverKey]*CrossPlatformFailoverStat
+}
+
+func (s *crossPlatformFailoverStats) entryLocked(groupID int64, from, to string) *CrossPlatformFailoverStat {
+	if s.stats == nil {
+		s.stats = make(map[crossPlatformFailoverKey]*CrossPlatformFailoverStat)
+	}
+	key := crossPlatformFailoverKey{groupID: groupID, from: from, to: to}
+	stat := s.stats[key]
+	if stat == nil {
+		stat = &CrossPlatformFailoverStat{GroupID: groupID, FromPlatform: from, ToPlatform: to}
+		s.stats[key] = stat
+	}
+	return stat
+}
+
+func (s *crossPlatformFailoverStats) recordTriggered(groupID int64, from, to string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat := s.entryLocked(groupID, from, to)
+	stat.TriggeredTotal++
+	stat.LastTriggeredAt = at
+}
+
+func (s *crossPlatformFailoverStats) recordSucceeded(groupID int64, from, to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(groupID, from, to).SucceededTotal++
+}
+
+func (s *crossPlatformFailoverStats) snapshot() []CrossPlatformFailoverStat {
+	s.mu.Lock()
+	out := make([]CrossPlatformFailoverStat, 0, len(s.stats))
+	for _, stat := range s.stats {
+		out = append(out, *stat)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].GroupID != out[j].GroupID {
+			return out[i].GroupID < out[j].GroupID
+		}
+		return out[i].FromPlatform < out[j].FromPlatform
+	})
+	return out
+}
+
+// RecordCrossPlatformFailover 记录一次跨平台故障转移触发
+func (s *GatewayService) RecordCrossPlatformFailover(groupID int64, from, to string) {
+	if s == nil {
+		return
+	}
+	s.crossPlatformFailovers.recordTriggered(groupID, from, to, time.Now())
+}
+
+// RecordCrossPlatformFailoverSuccess 记录跨平台故障转移后请求成功
+func (s *GatewayService) RecordCrossPlatformFailoverSuccess(groupID int64, from, to string) {
+	if s == nil {
+		return
+	}
+	s.crossPlatformFailovers.recordSucceeded(groupID, from, to)
+}
+
+// SnapshotCrossPlatformFailoverStats 返回本实例的跨平台故障转移统计
+func (s *GatewayService) SnapshotCrossPlatformFailoverStats() []CrossPlatformFailoverStat {
+	if s == nil {
+		return []CrossPlatformFailoverStat{}
+	}
+	return s.crossPlatformFailovers.snapshot()
+}
+
+// GetCrossPlatformFailoverStats 返回本实例按分组统计的跨平台故障转移次数
+func (s *OpsService) GetCrossPlatformFailoverStats(ctx context.Context) ([]CrossPlatformFailoverStat, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	return s.gatewayService.SnapshotCrossPlatformFailoverStats(), nil
+}
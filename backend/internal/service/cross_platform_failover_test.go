@@ -0,0 +1,36 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossPlatformFailoverPeer(t *testing.T) {
+	require.Equal(t, PlatformAntigravity, CrossPlatformFailoverPeer(PlatformAnthropic))
+	require.Equal(t, PlatformAnthropic, CrossPlatformFailoverPeer(PlatformAntigravity))
+	require.Empty(t, CrossPlatformFailoverPeer(PlatformOpenAI))
+	require.Empty(t, CrossPlatformFailoverPeer(PlatformGemini))
+}
+
+func TestCrossPlatformFailoverStats(t *testing.T) {
+	svc := &GatewayService{}
+	svc.RecordCrossPlatformFailover(2, PlatformAntigravity, PlatformAnthropic)
+	svc.RecordCrossPlatformFailover(1, PlatformAnthropic, PlatformAntigravity)
+	svc.RecordCrossPlatformFailover(1, PlatformAnthropic, PlatformAntigravity)
+	svc.RecordCrossPlatformFailoverSuccess(1, PlatformAnthropic, PlatformAntigravity)
+
+	stats := svc.SnapshotCrossPlatformFailoverStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, int64(1), stats[0].GroupID)
+	require.Equal(t, int64(2), stats[0].TriggeredTotal)
+	require.Equal(t, int64(1), stats[0].SucceededTotal)
+	require.False(t, stats[0].LastTriggeredAt.IsZero())
+	require.Equal(t, int64(2), stats[1].GroupID)
+	require.Equal(t, PlatformAnthropic, stats[1].ToPlatform)
+
+	var nilSvc *GatewayService
+	require.Empty(t, nilSvc.SnapshotCrossPlatformFailoverStats())
+}
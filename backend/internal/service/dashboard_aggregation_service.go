@@ -43,6 +43,7 @@ type DashboardAggregationService struct {
 	repo                 DashboardAggregationRepository
 	timingWheel          *TimingWheelService
 	cfg                  config.DashboardAggregationConfig
+	archiver             UsageLogArchiver
 	running              int32
 	lastRetentionCleanup atomic.Value // time.Time
 }
@@ -60,6 +61,14 @@ func NewDashboardAggregationService(repo DashboardAggregationRepository, timingW
 	}
 }
 
+// SetArchiver 设置 usage_logs 保留清理前的归档器。
+func (s *DashboardAggregationService) SetArchiver(archiver UsageLogArchiver) {
+	if s == nil {
+		return
+	}
+	s.archiver = archiver
+}
+
 // Start 启动定时聚合作业（重启生效配置）。
 func (s *DashboardAggregationService) Start() {
 	if s == nil || s.repo == nil || s.timingWheel == nil {
@@ -301,7 +310,17 @@ func (s *DashboardAggregationService) maybeCleanupRetention(ctx context.Context,
 	if aggErr != nil {
 		logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] 聚合保留清理失败: %v", aggErr)
 	}
-	usageErr := s.repo.CleanupUsageLogs(ctx, usageCutoff)
+	var usageErr error
+	if s.archiver != nil {
+		// 仅删除已成功归档的时间段；归档失败时本轮保留剩余数据，下次重试
+		usageCutoff, usageErr = s.archiver.ArchiveBefore(ctx, usageCutoff)
+		if usageErr != nil {
+			logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] usage_logs 归档失败，仅清理 %s 之前的数据: %v", usageCutoff.UTC().Format(time.RFC3339), usageErr)
+		}
+	}
+	if err := s.repo.CleanupUsageLogs(ctx, usageCutoff); err != nil {
+		usageErr = err
+	}
 	if usageErr != nil {
 		logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] usage_logs 保留清理失败: %v", usageErr)
 	}
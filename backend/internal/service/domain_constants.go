@@ -98,12 +98,19 @@ const (
 	// TOTP 双因素认证设置
 	SettingKeyTotpEnabled = "totp_enabled" // 是否启用 TOTP 2FA 功能
 
+	// WebAuthn / Passkey 设置
+	SettingKeyPasskeyEnabled  = "passkey_enabled"   // 是否启用 Passkey 登录与 2FA
+	SettingKeyAdminRequire2FA = "admin_require_2fa" // 管理员账号是否必须启用第二因素
+
 	// LinuxDo Connect OAuth 登录设置
 	SettingKeyLinuxDoConnectEnabled      = "linuxdo_connect_enabled"
 	SettingKeyLinuxDoConnectClientID     = "linuxdo_connect_client_id"
 	SettingKeyLinuxDoConnectClientSecret = "linuxdo_connect_client_secret"
 	SettingKeyLinuxDoConnectRedirectURL  = "linuxdo_connect_redirect_url"
 
+	// 通用 OIDC/OAuth2 登录提供方（JSON 数组）
+	SettingKeyOIDCProviders = "oidc_providers"
+
 	// OEM设置
 	SettingKeySoraClientEnabled           = "sora_client_enabled"           // 是否启用 Sora 客户端（管理员手动控制）
 	SettingKeySiteName                    = "site_name"                     // 网站名称
@@ -11,16 +11,18 @@ import (
 
 // Task type constants
 const (
-	TaskTypeVerifyCode    = "verify_code"
-	TaskTypePasswordReset = "password_reset"
+	TaskTypeVerifyCode     = "verify_code"
+	TaskTypePasswordReset  = "password_reset"
+	TaskTypeNewDeviceLogin = "new_device_login"
 )
 
 // EmailTask 邮件发送任务
 type EmailTask struct {
 	Email    string
 	SiteName string
-	TaskType string // "verify_code" or "password_reset"
-	ResetURL string // Only used for password_reset task type
+	TaskType string       // "verify_code", "password_reset" or "new_device_login"
+	ResetURL string       // Only used for password_reset task type
+	Session  *UserSession // Only used for new_device_login task type
 }
 
 // EmailQueueService 异步邮件队列服务
@@ -93,6 +95,12 @@ func (s *EmailQueueService) processTask(workerID int, task EmailTask) {
 		} else {
 			logger.LegacyPrintf("service.email_queue", "[EmailQueue] Worker %d sent password reset to %s", workerID, task.Email)
 		}
+	case TaskTypeNewDeviceLogin:
+		if err := s.emailService.SendNewDeviceLoginEmail(ctx, task.Email, task.SiteName, task.Session); err != nil {
+			logger.LegacyPrintf("service.email_queue", "[EmailQueue] Worker %d failed to send new device login notice to %s: %v", workerID, task.Email, err)
+		} else {
+			logger.LegacyPrintf("service.email_queue", "[EmailQueue] Worker %d sent new device login notice to %s", workerID, task.Email)
+		}
 	default:
 		logger.LegacyPrintf("service.email_queue", "[EmailQueue] Worker %d unknown task type: %s", workerID, task.TaskType)
 	}
@@ -133,6 +141,24 @@ func (s *EmailQueueService) EnqueuePasswordReset(email, siteName, resetURL strin
 	}
 }
 
+// EnqueueNewDeviceLogin 将新设备登录提醒邮件任务加入队列
+func (s *EmailQueueService) EnqueueNewDeviceLogin(email, siteName string, session *UserSession) error {
+	task := EmailTask{
+		Email:    email,
+		SiteName: siteName,
+		TaskType: TaskTypeNewDeviceLogin,
+		Session:  session,
+	}
+
+	select {
+	case s.taskChan <- task:
+		logger.LegacyPrintf("service.email_queue", "[EmailQueue] Enqueued new device login task for %s", email)
+		return nil
+	default:
+		return fmt.Errorf("email queue is full")
+	}
+}
+
 // Stop 停止队列服务
 func (s *EmailQueueService) Stop() {
 	close(s.stopChan)
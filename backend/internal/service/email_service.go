@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	"encoding/hex"
 	"fmt"
+	"html"
 	"log"
 	"math/big"
 	"net/smtp"
@@ -539,3 +540,69 @@ func (s *EmailService) buildPasswordResetEmailBody(resetURL, siteName string) st
 </html>
 `, siteName, resetURL, resetURL)
 }
+
+// SendNewDeviceLoginEmail sends a notice when the user signs in from a device not seen before
+func (s *EmailService) SendNewDeviceLoginEmail(ctx context.Context, email, siteName string, session *UserSession) error {
+	if session == nil {
+		return nil
+	}
+	subject := fmt.Sprintf("[%s] 新设备登录提醒", siteName)
+	body := s.buildNewDeviceLoginEmailBody(session, siteName)
+	if err := s.SendEmail(ctx, email, subject, body); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// buildNewDeviceLoginEmailBody builds the HTML content for new device login notice
+func (s *EmailService) buildNewDeviceLoginEmailBody(session *UserSession, siteName string) string {
+	ipAddr := session.IP
+	if ipAddr == "" {
+		ipAddr = "-"
+	}
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, sans-serif; background-color: #f5f5f5; margin: 0; padding: 20px; }
+        .container { max-width: 600px; margin: 0 auto; background-color: #ffffff; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 8px rgba(0,0,0,0.1); }
+        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; }
+        .header h1 { margin: 0; font-size: 24px; }
+        .content { padding: 40px 30px; }
+        .details { background-color: #f8f9fa; border-radius: 8px; padding: 15px 20px; color: #333; font-size: 14px; line-height: 1.8; }
+        .ua { color: #999; font-size: 12px; word-break: break-all; }
+        .info { color: #666; font-size: 14px; line-height: 1.6; margin-top: 20px; }
+        .footer { background-color: #f8f9fa; padding: 20px; text-align: center; color: #999; font-size: 12px; }
+        .warning { color: #e74c3c; font-weight: 500; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <p style="font-size: 18px; color: #333;">新设备登录提醒</p>
+            <p style="color: #666;">您的账号刚刚在一台新设备上登录：</p>
+            <div class="details">
+                <p><strong>设备：</strong>%s</p>
+                <p><strong>IP 地址：</strong>%s</p>
+                <p><strong>时间：</strong>%s</p>
+                <p class="ua">%s</p>
+            </div>
+            <div class="info">
+                <p>如果这是您本人的操作，请忽略此邮件。</p>
+                <p class="warning">如果不是您本人登录，请立即修改密码，并在个人资料页的「登录设备」中移除该会话。</p>
+            </div>
+        </div>
+        <div class="footer">
+            <p>这是一封自动发送的邮件，请勿回复。</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(siteName), html.EscapeString(session.Device), html.EscapeString(ipAddr),
+		session.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC"), html.EscapeString(session.UserAgent))
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+type Experiment = domain.Experiment
+type ExperimentVariant = domain.ExperimentVariant
+
+const (
+	ExperimentStatusDraft   = domain.ExperimentStatusDraft
+	ExperimentStatusRunning = domain.ExperimentStatusRunning
+	ExperimentStatusStopped = domain.ExperimentStatusStopped
+
+	ExperimentStickyUser   = domain.ExperimentStickyUser
+	ExperimentStickyAPIKey = domain.ExperimentStickyAPIKey
+)
+
+var (
+	ErrExperimentInvalid  = domain.ErrExperimentInvalid
+	ErrExperimentNotFound = infraerrors.NotFound("EXPERIMENT_NOT_FOUND", "experiment not found")
+)
+
+// experimentCacheTTL 运行中实验的本地缓存有效期；本实例修改后立即失效，其他实例最迟在 TTL 后生效
+const experimentCacheTTL = 30 * time.Second
+
+type ExperimentRepository interface {
+	List(ctx context.Context) ([]Experiment, error)
+	ListRunning(ctx context.Context) ([]Experiment, error)
+	GetByID(ctx context.Context, id int64) (*Experiment, error)
+	Create(ctx context.Context, experiment *Experiment) error
+	Update(ctx context.Context, experiment *Experiment) error
+	Delete(ctx context.Context, id int64) error
+	// VariantStats 按变体聚合实验期间的使用日志与错误日志
+	VariantStats(ctx context.Context, experimentID int64, startTime, endTime time.Time) ([]ExperimentVariantStats, error)
+}
+
+// ExperimentVariantStats 单个变体在统计窗口内的对比指标
+type ExperimentVariantStats struct {
+	Variant         string  `json:"variant"`
+	Requests        int64   `json:"requests"`
+	Errors          int64   `json:"errors"`
+	ErrorRate       float64 `json:"error_rate"`
+	UniqueUsers     int64   `json:"unique_users"`
+	ReturningUsers  int64   `json:"returning_users"`
+	AvgDurationMs   float64 `json:"avg_duration_ms"`
+	AvgFirstTokenMs float64 `json:"avg_first_token_ms"`
+	InputTokens     int64   `json:"input_tokens"`
+	OutputTokens    int64   `json:"output_tokens"`
+	AvgOutputTokens float64 `json:"avg_output_tokens"`
+	TotalCost       float64 `json:"total_cost"`
+	AvgCost         float64 `json:"avg_cost"`
+}
+
+// ExperimentResults 实验各变体的对比结果
+type ExperimentResults struct {
+	Experiment *Experiment              `json:"experiment"`
+	StartTime  time.Time                `json:"start_time"`
+	EndTime    time.Time                `json:"end_time"`
+	Variants   []ExperimentVariantStats `json:"variants"`
+}
+
+// ExperimentAssignment 单个请求命中的实验与变体
+type ExperimentAssignment struct {
+	ExperimentID int64
+	Variant      ExperimentVariant
+}
+
+// tagUsageLog 在使用日志上记录实验与变体
+func (a *ExperimentAssignment) tagUsageLog(log *UsageLog) {
+	if a == nil || log == nil {
+		return
+	}
+	id := a.ExperimentID
+	variant := a.Variant.Name
+	log.ExperimentID = &id
+	log.ExperimentVariant = &variant
+}
+
+// ExperimentService A/B 模型实验：管理实验配置，并为网关请求做粘性分流
+type ExperimentService struct {
+	repo      ExperimentRepository
+	groupRepo GroupRepository
+
+	mu       sync.RWMutex
+	running  []Experiment
+	loadedAt time.Time
+	reloadMu sync.Mutex
+}
+
+func NewExperimentService(repo ExperimentRepository, groupRepo GroupRepository) *ExperimentService {
+	return &ExperimentService{repo: repo, groupRepo: groupRepo}
+}
+
+func (s *ExperimentService) List(ctx context.Context) ([]Experiment, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *ExperimentService) GetByID(ctx context.Context, id int64) (*Experiment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ExperimentService) Create(ctx context.Context, experiment *Experiment) (*Experiment, error) {
+	if err := s.validate(ctx, experiment); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, experiment); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return experiment, nil
+}
+
+func (s *ExperimentService) Update(ctx context.Context, id int64, experiment *Experiment) (*Experiment, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	experiment.ID = id
+	if err := s.validate(ctx, experiment); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, experiment); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return experiment, nil
+}
+
+func (s *ExperimentService) Delete(ctx context.Context, id int64) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// validate 规范化实验配置并校验引用的分组：变体改写的目标分组须为 anthropic / antigravity 标准计费分组
+func (s *ExperimentService) validate(ctx context.Context, experiment *Experiment) error {
+	if err := domain.NormalizeExperiment(experiment); err != nil {
+		return err
+	}
+	for _, groupID := range experiment.GroupIDs {
+		if _, err := s.groupRepo.GetByIDLite(ctx, groupID); err != nil {
+			return fmt.Errorf("%w: group %d not found", ErrExperimentInvalid, groupID)
+		}
+	}
+	for _, v := range experiment.Variants {
+		if v.GroupID == 0 {
+			continue
+		}
+		group, err := s.groupRepo.GetByIDLite(ctx, v.GroupID)
+		if err != nil {
+			return fmt.Errorf("%w: variant %q group %d not found", ErrExperimentInvalid, v.Name, v.GroupID)
+		}
+		if group.Platform != PlatformAnthropic && group.Platform != PlatformAntigravity {
+			return fmt.Errorf("%w: variant %q group must be anthropic or antigravity platform", ErrExperimentInvalid, v.Name)
+		}
+		if group.IsSubscriptionType() {
+			return fmt.Errorf("%w: variant %q group cannot be subscription type", ErrExperimentInvalid, v.Name)
+		}
+	}
+	return nil
+}
+
+// GetResults 汇总实验各变体在时间窗口内的错误率、延迟、Token 与费用；无数据的变体以零值返回
+func (s *ExperimentService) GetResults(ctx context.Context, id int64, startTime, endTime time.Time) (*ExperimentResults, error) {
+	experiment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.VariantStats(ctx, id, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]ExperimentVariantStats, len(stats))
+	for _, st := range stats {
+		byName[st.Variant] = st
+	}
+
+	variants := make([]ExperimentVariantStats, 0, len(experiment.Variants)+len(stats))
+	for _, v := range experiment.Variants {
+		st, ok := byName[v.Name]
+		if !ok {
+			st = ExperimentVariantStats{Variant: v.Name}
+		}
+		delete(byName, v.Name)
+		variants = append(variants, finalizeExperimentVariantStats(st))
+	}
+	// 实验中途删除的变体仍保留历史数据
+	for _, st := range stats {
+		if _, ok := byName[st.Variant]; ok {
+			variants = append(variants, finalizeExperimentVariantStats(st))
+		}
+	}
+	return &ExperimentResults{Experiment: experiment, StartTime: startTime, EndTime: endTime, Variants: variants}, nil
+}
+
+func finalizeExperimentVariantStats(st ExperimentVariantStats) ExperimentVariantStats {
+	if attempts := st.Requests + st.Errors; attempts > 0 {
+		st.ErrorRate = float64(st.Errors) / float64(attempts)
+	}
+	if st.Requests > 0 {
+		st.AvgOutputTokens = float64(st.OutputTokens) / float64(st.Requests)
+		st.AvgCost = st.TotalCost / float64(st.Requests)
+	}
+	return st
+}
+
+// Assign 为请求选择第一个命中的运行中实验并按粘性维度分配变体，未命中返回 nil。
+// 改写分组的实验不对订阅分组的 Key 生效，避免订阅用户被转为余额计费。
+func (s *ExperimentService) Assign(ctx context.Context, apiKey *APIKey, model string) *ExperimentAssignment {
+	if s == nil || apiKey == nil {
+		return nil
+	}
+	experiments := s.runningExperiments(ctx)
+	for i := range experiments {
+		exp := &experiments[i]
+		if !exp.MatchesModel(model) || !exp.MatchesGroup(apiKey.GroupID) {
+			continue
+		}
+		if exp.OverridesGroup() && apiKey.Group != nil && apiKey.Group.IsSubscriptionType() {
+			continue
+		}
+		stickyKey := apiKey.UserID
+		if exp.StickyBy == ExperimentStickyAPIKey {
+			stickyKey = apiKey.ID
+		}
+		if variant := exp.Assign(stickyKey); variant != nil {
+			return &ExperimentAssignment{ExperimentID: exp.ID, Variant: *variant}
+		}
+	}
+	return nil
+}
+
+func (s *ExperimentService) runningExperiments(ctx context.Context) []Experiment {
+	s.mu.RLock()
+	running, fresh := s.running, !s.loadedAt.IsZero() && time.Since(s.loadedAt) < experimentCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return running
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.mu.RLock()
+	running, fresh = s.running, !s.loadedAt.IsZero() && time.Since(s.loadedAt) < experimentCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return running
+	}
+
+	loaded, err := s.repo.ListRunning(ctx)
+	if err != nil {
+		// 加载失败沿用旧数据，并推迟下次重试，避免每个请求都打到数据库
+		logger.L().Warn("experiment.load_running_failed", zap.Error(err))
+		loaded = running
+	}
+	s.mu.Lock()
+	s.running = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return loaded
+}
+
+func (s *ExperimentService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// ApplyExperimentVariant 按变体改写请求体：替换模型，并在 system 末尾追加提示词补丁。
+// 补丁追加在末尾，保留客户端原有 system 前缀（Claude Code 识别与提示词缓存均依赖前缀）。
+func (s *GatewayService) ApplyExperimentVariant(body []byte, system any, variant ExperimentVariant) []byte {
+	if variant.Model != "" {
+		body = s.replaceModelInBody(body, variant.Model)
+	}
+	if variant.SystemPromptPatch != "" {
+		body = appendSystemPromptPatch(body, system, variant.SystemPromptPatch)
+	}
+	return body
+}
+
+// appendSystemPromptPatch 在 system 末尾追加文本块，处理 null、字符串、数组三种格式
+func appendSystemPromptPatch(body []byte, system any, patch string) []byte {
+	patchBlock := map[string]any{"type": "text", "text": patch}
+
+	var newSystem []any
+	switch v := system.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			newSystem = []any{patchBlock}
+		} else {
+			newSystem = []any{map[string]any{"type": "text", "text": v}, patchBlock}
+		}
+	case []any:
+		newSystem = make([]any, 0, len(v)+1)
+		newSystem = append(newSystem, v...)
+		newSystem = append(newSystem, patchBlock)
+	default:
+		newSystem = []any{patchBlock}
+	}
+
+	result, err := sjson.SetBytes(body, "system", newSystem)
+	if err != nil {
+		logger.L().Warn("experiment.append_system_patch_failed", zap.Error(err))
+		return body
+	}
+	return result
+}
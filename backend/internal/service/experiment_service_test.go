@@ -0,0 +1,189 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type experimentRepoStub struct {
+	running      []Experiment
+	byID         map[int64]*Experiment
+	stats        []ExperimentVariantStats
+	listErr      error
+	listRunCalls int
+	created      *Experiment
+}
+
+func (s *experimentRepoStub) List(context.Context) ([]Experiment, error) { return nil, nil }
+func (s *experimentRepoStub) ListRunning(context.Context) ([]Experiment, error) {
+	s.listRunCalls++
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return s.running, nil
+}
+func (s *experimentRepoStub) GetByID(_ context.Context, id int64) (*Experiment, error) {
+	if e, ok := s.byID[id]; ok {
+		return e, nil
+	}
+	return nil, ErrExperimentNotFound
+}
+func (s *experimentRepoStub) Create(_ context.Context, e *Experiment) error {
+	e.ID = 1
+	s.created = e
+	return nil
+}
+func (s *experimentRepoStub) Update(context.Context, *Experiment) error { return nil }
+func (s *experimentRepoStub) Delete(context.Context, int64) error       { return nil }
+func (s *experimentRepoStub) VariantStats(context.Context, int64, time.Time, time.Time) ([]ExperimentVariantStats, error) {
+	return s.stats, nil
+}
+
+func TestExperimentService_Assign(t *testing.T) {
+	repo := &experimentRepoStub{running: []Experiment{
+		{
+			ID: 1, ModelPattern: "claude-opus-*", StickyBy: ExperimentStickyUser,
+			Variants: []ExperimentVariant{{Name: "control", Percent: 0}, {Name: "cheaper", Percent: 100, Model: "claude-sonnet-4-5"}},
+		},
+		{
+			ID: 2, ModelPattern: "claude-sonnet-*", GroupIDs: []int64{10}, StickyBy: ExperimentStickyAPIKey,
+			Variants: []ExperimentVariant{{Name: "control", Percent: 0}, {Name: "other-group", Percent: 100, GroupID: 20}},
+		},
+	}}
+	svc := NewExperimentService(repo, nil)
+	ctx := context.Background()
+
+	key := &APIKey{ID: 5, UserID: 7, GroupID: int64Ptr(10), Group: &Group{ID: 10}}
+	a := svc.Assign(ctx, key, "Claude-Opus-4-1")
+	require.NotNil(t, a)
+	require.Equal(t, int64(1), a.ExperimentID)
+	require.Equal(t, "cheaper", a.Variant.Name)
+
+	a = svc.Assign(ctx, key, "claude-sonnet-4-5")
+	require.NotNil(t, a)
+	require.Equal(t, int64(2), a.ExperimentID)
+
+	// 分组不在实验范围内
+	require.Nil(t, svc.Assign(ctx, &APIKey{ID: 5, UserID: 7, GroupID: int64Ptr(11), Group: &Group{ID: 11}}, "claude-sonnet-4-5"))
+	// 改写分组的实验不作用于订阅分组
+	subKey := &APIKey{ID: 5, UserID: 7, GroupID: int64Ptr(10), Group: &Group{ID: 10, SubscriptionType: SubscriptionTypeSubscription}}
+	require.Nil(t, svc.Assign(ctx, subKey, "claude-sonnet-4-5"))
+	require.NotNil(t, svc.Assign(ctx, subKey, "claude-opus-4-1"))
+
+	require.Nil(t, svc.Assign(ctx, key, "gpt-5"))
+	require.Equal(t, 1, repo.listRunCalls, "running experiments should be cached")
+
+	var nilSvc *ExperimentService
+	require.Nil(t, nilSvc.Assign(ctx, key, "claude-opus-4-1"))
+}
+
+func TestExperimentService_RunningCacheKeepsStaleOnError(t *testing.T) {
+	repo := &experimentRepoStub{running: []Experiment{{
+		ID: 1, ModelPattern: "*", StickyBy: ExperimentStickyUser,
+		Variants: []ExperimentVariant{{Name: "a", Percent: 100}, {Name: "b", Percent: 0}},
+	}}}
+	svc := NewExperimentService(repo, nil)
+	ctx := context.Background()
+	key := &APIKey{ID: 1, UserID: 1}
+	require.NotNil(t, svc.Assign(ctx, key, "claude-x"))
+
+	svc.invalidate()
+	repo.listErr = errors.New("db down")
+	require.NotNil(t, svc.Assign(ctx, key, "claude-x"))
+	require.Equal(t, 2, repo.listRunCalls)
+}
+
+func TestExperimentService_CreateValidatesVariantGroups(t *testing.T) {
+	groupRepo := &mockGroupRepoForGateway{groups: map[int64]*Group{
+		1: {ID: 1, Platform: PlatformAnthropic},
+		2: {ID: 2, Platform: PlatformOpenAI},
+		3: {ID: 3, Platform: PlatformAntigravity, SubscriptionType: SubscriptionTypeSubscription},
+	}}
+	repo := &experimentRepoStub{}
+	svc := NewExperimentService(repo, groupRepo)
+	ctx := context.Background()
+
+	build := func(groupID int64) *Experiment {
+		return &Experiment{
+			Name: "exp", ModelPattern: "claude-*",
+			Variants: []ExperimentVariant{{Name: "control", Percent: 50}, {Name: "b", Percent: 50, GroupID: groupID}},
+		}
+	}
+
+	_, err := svc.Create(ctx, build(1))
+	require.NoError(t, err)
+	require.NotNil(t, repo.created)
+	require.Equal(t, ExperimentStatusDraft, repo.created.Status)
+
+	for _, groupID := range []int64{2, 3, 99} {
+		_, err = svc.Create(ctx, build(groupID))
+		require.ErrorIs(t, err, ErrExperimentInvalid, "group %d", groupID)
+	}
+
+	exp := build(0)
+	exp.GroupIDs = []int64{99}
+	_, err = svc.Create(ctx, exp)
+	require.ErrorIs(t, err, ErrExperimentInvalid)
+}
+
+func TestExperimentService_GetResultsFillsMissingVariants(t *testing.T) {
+	repo := &experimentRepoStub{
+		byID: map[int64]*Experiment{1: {ID: 1, Variants: []ExperimentVariant{{Name: "control"}, {Name: "treatment"}}}},
+		stats: []ExperimentVariantStats{
+			{Variant: "removed", Requests: 2, TotalCost: 1},
+			{Variant: "treatment", Requests: 4, Errors: 1, OutputTokens: 400, TotalCost: 2},
+		},
+	}
+	svc := NewExperimentService(repo, nil)
+
+	res, err := svc.GetResults(context.Background(), 1, time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	require.Len(t, res.Variants, 3)
+	require.Equal(t, "control", res.Variants[0].Variant)
+	require.Zero(t, res.Variants[0].Requests)
+	require.Equal(t, "treatment", res.Variants[1].Variant)
+	require.InDelta(t, 0.2, res.Variants[1].ErrorRate, 1e-9)
+	require.InDelta(t, 100, res.Variants[1].AvgOutputTokens, 1e-9)
+	require.InDelta(t, 0.5, res.Variants[1].AvgCost, 1e-9)
+	require.Equal(t, "removed", res.Variants[2].Variant)
+
+	_, err = svc.GetResults(context.Background(), 2, time.Now(), time.Now())
+	require.ErrorIs(t, err, ErrExperimentNotFound)
+}
+
+func TestAppendSystemPromptPatch(t *testing.T) {
+	body := []byte(`{"model":"m","messages":[]}`)
+	out := appendSystemPromptPatch(body, nil, "be brief")
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system.0.text").String())
+
+	body = []byte(`{"model":"m","system":"you are helpful","messages":[]}`)
+	out = appendSystemPromptPatch(body, "you are helpful", "be brief")
+	require.Equal(t, "you are helpful", gjson.GetBytes(out, "system.0.text").String())
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system.1.text").String())
+
+	system := []any{map[string]any{"type": "text", "text": "prefix", "cache_control": map[string]any{"type": "ephemeral"}}}
+	body = []byte(`{"model":"m","system":[{"type":"text","text":"prefix","cache_control":{"type":"ephemeral"}}],"messages":[]}`)
+	out = appendSystemPromptPatch(body, system, "be brief")
+	require.Equal(t, "ephemeral", gjson.GetBytes(out, "system.0.cache_control.type").String())
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system.1.text").String())
+	require.Equal(t, int64(2), gjson.GetBytes(out, "system.#").Int())
+}
+
+func TestExperimentAssignmentTagUsageLog(t *testing.T) {
+	log := &UsageLog{}
+	(&ExperimentAssignment{ExperimentID: 3, Variant: ExperimentVariant{Name: "b"}}).tagUsageLog(log)
+	require.Equal(t, int64(3), *log.ExperimentID)
+	require.Equal(t, "b", *log.ExperimentVariant)
+
+	var nilAssignment *ExperimentAssignment
+	other := &UsageLog{}
+	nilAssignment.tagUsageLog(other)
+	require.Nil(t, other.ExperimentID)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// detachedClaudeResponse 后台转发的 Claude 请求结果
+type detachedClaudeResponse struct {
+	result *ForwardResult
+	// status 上游写回的 HTTP 状态码
+	status int
+	// body 截断到捕获上限的响应体
+	body []byte
+}
+
+// forwardDetachedClaudeRequest 在没有客户端连接的后台任务（影子镜像、上下文摘要等）中，
+// 以 POST /v1/messages 将 Claude 请求体转发到指定账号，响应写入有上限的缓冲区。
+// 请求体无法解析时返回 nil 响应与错误；转发失败时同时返回已捕获的响应与错误。
+func forwardDetachedClaudeRequest(
+	ctx context.Context,
+	gatewayService *GatewayService,
+	antigravityGatewayService *AntigravityGatewayService,
+	account *Account,
+	body []byte,
+	header http.Header,
+	captureBytes int,
+) (*detachedClaudeResponse, error) {
+	var parsed *ParsedRequest
+	if account.Platform != PlatformAntigravity || account.Type == AccountTypeAPIKey {
+		var err error
+		if parsed, err = ParseGatewayRequest(body, domain.PlatformAnthropic); err != nil {
+			return nil, err
+		}
+	}
+
+	w := newLimitedResponseWriter(captureBytes)
+	c, _ := gin.CreateTestContext(w)
+	httpReq, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/v1/messages", bytes.NewReader(nil))
+	for key, values := range header {
+		for _, v := range values {
+			httpReq.Header.Add(key, v)
+		}
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	c.Request = httpReq
+
+	var result *ForwardResult
+	var err error
+	if parsed == nil {
+		result, err = antigravityGatewayService.Forward(ctx, c, account, body, false)
+	} else {
+		result, err = gatewayService.Forward(ctx, c, account, parsed)
+	}
+	return &detachedClaudeResponse{result: result, status: c.Writer.Status(), body: w.bodyBytes()}, err
+}
@@ -63,6 +63,10 @@ type ParsedRequest struct {
 	MaxTokens       int             // max_tokens 值（用于探测请求拦截）
 	SessionContext  *SessionContext // 可选：请求上下文区分因子（nil 时行为不变）
 
+	// AutoCacheSeenMessages 自动缓存断点：同一对话前序请求已发送的消息数（0 表示新对话）。
+	// 由 ResolveAutoCacheConversation 在每个客户端请求上计算一次，换号重试时保持不变
+	AutoCacheSeenMessages int
+
 	// OnUpstreamAccepted 上游接受请求后立即调用（用于提前释放串行锁）
 	// 流式请求在收到 2xx 响应头后调用，避免持锁等流完成
 	OnUpstreamAccepted func()
@@ -462,6 +462,9 @@ type ForwardResult struct {
 	FirstTokenMs     *int // 首字时间（流式请求）
 	ClientDisconnect bool // 客户端是否在流式传输过程中断开
 
+	// AutoCacheBreakpoints 自动注入的 cache_control 断点数量（分组开启自动缓存断点时）
+	AutoCacheBreakpoints int
+
 	// 图片生成计费字段（图片生成模型使用）
 	ImageCount int    // 生成的图片数量
 	ImageSize  string // 图片尺寸 "1K", "2K", "4K"
@@ -530,6 +533,14 @@ type GatewayService struct {
 	responseHeaderFilter  *responseheaders.CompiledHeaderFilter
 	debugModelRouting     atomic.Bool
 	debugClaudeMimic      atomic.Bool
+
+	// 账号调度：分组策略实例、决策指标与账号运行时统计（错误率 / 首 token 耗时）
+	schedulingPolicies accountSchedulingPolicyRegistry
+	schedulerMetrics   accountSchedulerMetrics
+	accountStats       *accountRuntimeStats
+
+	// 跨平台故障转移触发统计（进程内累计）
+	crossPlatformFailovers crossPlatformFailoverStats
 }
 
 // NewGatewayService creates a new GatewayService
@@ -585,6 +596,7 @@ func NewGatewayService(
 		modelsListCache:      gocache.New(modelsListTTL, time.Minute),
 		modelsListCacheTTL:   modelsListTTL,
 		responseHeaderFilter: compileResponseHeaderFilter(cfg),
+		accountStats:         newAccountRuntimeStats(),
 	}
 	svc.userGroupRateResolver = newUserGroupRateResolver(
 		userGroupRateRepo,
@@ -1053,6 +1065,25 @@ func (s *GatewayService) SelectAccountForModelWithExclusions(ctx context.Context
 // SelectAccountWithLoadAwareness selects account with load-awareness and wait plan.
 // metadataUserID: 已废弃参数，会话限制现在统一使用 sessionHash
 func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string) (*AccountSelectionResult, error) {
+	selection, _, err := s.SelectAccountWithScheduleDecision(ctx, groupID, sessionHash, requestedModel, excludedIDs, metadataUserID)
+	return selection, err
+}
+
+// SelectAccountWithScheduleDecision 与 SelectAccountWithLoadAwareness 相同，额外返回调度决策（命中层级、策略、候选数等）。
+func (s *GatewayService) SelectAccountWithScheduleDecision(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string) (*AccountSelectionResult, AccountScheduleDecision, error) {
+	decision := AccountScheduleDecision{}
+	start := time.Now()
+	selection, err := s.selectAccountWithLoadAwareness(ctx, groupID, sessionHash, requestedModel, excludedIDs, metadataUserID, &decision)
+	decision.LatencyMs = time.Since(start).Milliseconds()
+	if selection != nil && selection.Account != nil {
+		decision.SelectedAccountID = selection.Account.ID
+		decision.SelectedAccountType = selection.Account.Type
+	}
+	s.schedulerMetrics.recordSelect(decision)
+	return selection, decision, err
+}
+
+func (s *GatewayService) selectAccountWithLoadAwareness(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, metadataUserID string, decision *AccountScheduleDecision) (*AccountSelectionResult, error) {
 	// 调试日志：记录调度入口参数
 	excludedIDsList := make([]int64, 0, len(excludedIDs))
 	for id := range excludedIDs {
@@ -1072,6 +1103,15 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		return nil, err
 	}
 	ctx = s.withGroupContext(ctx, group)
+	strategy := AccountSchedulingStrategyDefault
+	if group != nil {
+		strategy = AccountSchedulingStrategy(group.SchedulingStrategy)
+	}
+	policy := s.schedulingPolicies.resolve(groupID, strategy)
+	decision.Strategy = string(AccountSchedulingStrategyPriorityLRU)
+	if policy != nil {
+		decision.Strategy = string(policy.Strategy())
+	}
 
 	var stickyAccountID int64
 	if prefetch := prefetchedStickyAccountIDFromContext(ctx, groupID); prefetch > 0 {
@@ -1103,6 +1143,11 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			if err != nil {
 				return nil, err
 			}
+			decision.Layer = accountScheduleLayerLoadBalance
+			decision.StickySessionHit = stickyAccountID > 0 && stickyAccountID == account.ID
+			if decision.StickySessionHit {
+				decision.Layer = accountScheduleLayerSessionSticky
+			}
 
 			result, err := s.tryAcquireAccountSlot(ctx, account.ID, account.Concurrency)
 			if err == nil && result.Acquired {
@@ -1239,19 +1284,11 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 				modelScopeSkippedIDs = append(modelScopeSkippedIDs, account.ID)
 				continue
 			}
-			// 配额检查
-			if !s.isAccountSchedulableForQuota(account) {
-				continue
-			}
-			// 窗口费用检查（非粘性会话路径）
-			if !s.isAccountSchedulableForWindowCost(ctx, account, false) {
+			// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+			if !s.isAccountSchedulableForLimits(ctx, account, false) {
 				filteredWindowCost++
 				continue
 			}
-			// RPM 检查（非粘性会话路径）
-			if !s.isAccountSchedulableForRPM(ctx, account, false) {
-				continue
-			}
 			routingCandidates = append(routingCandidates, account)
 		}
 
@@ -1275,10 +1312,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							s.isAccountAllowedForPlatform(stickyAccount, platform, useMixed) &&
 							(requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, stickyAccount, requestedModel)) &&
 							s.isAccountSchedulableForModelSelection(ctx, stickyAccount, requestedModel) &&
-							s.isAccountSchedulableForQuota(stickyAccount) &&
-							s.isAccountSchedulableForWindowCost(ctx, stickyAccount, true) &&
-
-							s.isAccountSchedulableForRPM(ctx, stickyAccount, true) { // 粘性会话窗口费用+RPM 检查
+							s.isAccountSchedulableForLimits(ctx, stickyAccount, true) { // 粘性会话配额+窗口费用+RPM 检查
 							result, err := s.tryAcquireAccountSlot(ctx, stickyAccountID, stickyAccount.Concurrency)
 							if err == nil && result.Acquired {
 								// 会话数量限制检查
@@ -1289,6 +1323,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 									if s.debugModelRoutingEnabled() {
 										logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] routed sticky hit: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), stickyAccountID)
 									}
+									decision.Layer = accountScheduleLayerSessionSticky
+									decision.StickySessionHit = true
 									return &AccountSelectionResult{
 										Account:     stickyAccount,
 										Acquired:    true,
@@ -1303,6 +1339,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 								if !s.checkAndRegisterSession(ctx, stickyAccount, sessionHash) {
 									// 会话限制已满，继续到负载感知选择
 								} else {
+									decision.Layer = accountScheduleLayerSessionSticky
+									decision.StickySessionHit = true
 									return &AccountSelectionResult{
 										Account: stickyAccount,
 										WaitPlan: &AccountWaitPlan{
@@ -1345,27 +1383,33 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 
 			if len(routingAvailable) > 0 {
-				// 排序：优先级 > 负载率 > 最后使用时间
-				sort.SliceStable(routingAvailable, func(i, j int) bool {
-					a, b := routingAvailable[i], routingAvailable[j]
-					if a.account.Priority != b.account.Priority {
-						return a.account.Priority < b.account.Priority
-					}
-					if a.loadInfo.LoadRate != b.loadInfo.LoadRate {
-						return a.loadInfo.LoadRate < b.loadInfo.LoadRate
-					}
-					switch {
-					case a.account.LastUsedAt == nil && b.account.LastUsedAt != nil:
-						return true
-					case a.account.LastUsedAt != nil && b.account.LastUsedAt == nil:
-						return false
-					case a.account.LastUsedAt == nil && b.account.LastUsedAt == nil:
-						return false
-					default:
-						return a.account.LastUsedAt.Before(*b.account.LastUsedAt)
-					}
-				})
-				shuffleWithinSortGroups(routingAvailable)
+				decision.Layer = accountScheduleLayerModelRouting
+				decision.CandidateCount = len(routingAvailable)
+				if policy != nil {
+					routingAvailable = s.orderAccountsWithLoadByPolicy(policy, routingAvailable, groupID, sessionHash, requestedModel)
+				} else {
+					// 排序：优先级 > 负载率 > 最后使用时间
+					sort.SliceStable(routingAvailable, func(i, j int) bool {
+						a, b := routingAvailable[i], routingAvailable[j]
+						if a.account.Priority != b.account.Priority {
+							return a.account.Priority < b.account.Priority
+						}
+						if a.loadInfo.LoadRate != b.loadInfo.LoadRate {
+							return a.loadInfo.LoadRate < b.loadInfo.LoadRate
+						}
+						switch {
+						case a.account.LastUsedAt == nil && b.account.LastUsedAt != nil:
+							return true
+						case a.account.LastUsedAt != nil && b.account.LastUsedAt == nil:
+							return false
+						case a.account.LastUsedAt == nil && b.account.LastUsedAt == nil:
+							return false
+						default:
+							return a.account.LastUsedAt.Before(*b.account.LastUsedAt)
+						}
+					})
+					shuffleWithinSortGroups(routingAvailable)
+				}
 
 				// 4. 尝试获取槽位
 				for _, item := range routingAvailable {
@@ -1432,10 +1476,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 					s.isAccountAllowedForPlatform(account, platform, useMixed) &&
 					(requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) &&
 					s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) &&
-					s.isAccountSchedulableForQuota(account) &&
-					s.isAccountSchedulableForWindowCost(ctx, account, true) &&
-
-					s.isAccountSchedulableForRPM(ctx, account, true) { // 粘性会话窗口费用+RPM 检查
+					s.isAccountSchedulableForLimits(ctx, account, true) { // 粘性会话配额+窗口费用+RPM 检查
 					result, err := s.tryAcquireAccountSlot(ctx, accountID, account.Concurrency)
 					if err == nil && result.Acquired {
 						// 会话数量限制检查
@@ -1443,6 +1484,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 						if !s.checkAndRegisterSession(ctx, account, sessionHash) {
 							result.ReleaseFunc() // 释放槽位，继续到 Layer 2
 						} else {
+							decision.Layer = accountScheduleLayerSessionSticky
+							decision.StickySessionHit = true
 							return &AccountSelectionResult{
 								Account:     account,
 								Acquired:    true,
@@ -1459,6 +1502,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 							// 会话限制已满，继续到 Layer 2
 							// Session limit full, continue to Layer 2
 						} else {
+							decision.Layer = accountScheduleLayerSessionSticky
+							decision.StickySessionHit = true
 							return &AccountSelectionResult{
 								Account: account,
 								WaitPlan: &AccountWaitPlan{
@@ -1476,6 +1521,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	}
 
 	// ============ Layer 2: 负载感知选择 ============
+	decision.Layer = accountScheduleLayerLoadBalance
 	candidates := make([]*Account, 0, len(accounts))
 	for i := range accounts {
 		acc := &accounts[i]
@@ -1497,21 +1543,14 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		if !s.isAccountSchedulableForModelSelection(ctx, acc, requestedModel) {
 			continue
 		}
-		// 配额检查
-		if !s.isAccountSchedulableForQuota(acc) {
-			continue
-		}
-		// 窗口费用检查（非粘性会话路径）
-		if !s.isAccountSchedulableForWindowCost(ctx, acc, false) {
-			continue
-		}
-		// RPM 检查（非粘性会话路径）
-		if !s.isAccountSchedulableForRPM(ctx, acc, false) {
+		// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+		if !s.isAccountSchedulableForLimits(ctx, acc, false) {
 			continue
 		}
 		candidates = append(candidates, acc)
 	}
 
+	decision.CandidateCount = len(candidates)
 	if len(candidates) == 0 {
 		return nil, errors.New("no available accounts")
 	}
@@ -1531,11 +1570,15 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		}
 	} else {
 		var available []accountWithLoad
+		loadRateSum, loadRateSumSquares := 0.0, 0.0
 		for _, acc := range candidates {
 			loadInfo := loadMap[acc.ID]
 			if loadInfo == nil {
 				loadInfo = &AccountLoadInfo{AccountID: acc.ID}
 			}
+			loadRate := float64(loadInfo.LoadRate)
+			loadRateSum += loadRate
+			loadRateSumSquares += loadRate * loadRate
 			if loadInfo.LoadRate < 100 {
 				available = append(available, accountWithLoad{
 					account:  acc,
@@ -1543,6 +1586,15 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 				})
 			}
 		}
+		decision.LoadSkew = calcLoadSkewByMoments(loadRateSum, loadRateSumSquares, len(candidates))
+
+		// 分组显式配置了调度策略：按策略顺序尝试获取槽位
+		if policy != nil && len(available) > 0 {
+			if result, ok := s.tryAcquireByPolicyOrder(ctx, policy, available, groupID, sessionHash, requestedModel); ok {
+				return result, nil
+			}
+			available = nil
+		}
 
 		// 分层过滤选择：优先级 → 负载率 → LRU
 		for len(available) > 0 {
@@ -1631,6 +1683,70 @@ func (s *GatewayService) tryAcquireByLegacyOrder(ctx context.Context, candidates
 	return nil, false
 }
 
+// orderAccountsWithLoadByPolicy 按分组调度策略对候选账号重新排序。
+func (s *GatewayService) orderAccountsWithLoadByPolicy(policy AccountSchedulingPolicy, available []accountWithLoad, groupID *int64, sessionHash string, requestedModel string) []accountWithLoad {
+	accounts := make([]*Account, 0, len(available))
+	loadMap := make(map[int64]*AccountLoadInfo, len(available))
+	for _, item := range available {
+		accounts = append(accounts, item.account)
+		loadMap[item.account.ID] = item.loadInfo
+	}
+	ordered := policy.Order(buildAccountScheduleCandidates(accounts, loadMap, s.accountStats), deriveAccountSelectionSeed(groupID, sessionHash, requestedModel))
+
+	result := make([]accountWithLoad, 0, len(ordered))
+	for _, candidate := range ordered {
+		result = append(result, accountWithLoad{account: candidate.Account, loadInfo: candidate.LoadInfo})
+	}
+	return result
+}
+
+func (s *GatewayService) tryAcquireByPolicyOrder(ctx context.Context, policy AccountSchedulingPolicy, available []accountWithLoad, groupID *int64, sessionHash string, requestedModel string) (*AccountSelectionResult, bool) {
+	for _, item := range s.orderAccountsWithLoadByPolicy(policy, available, groupID, sessionHash, requestedModel) {
+		acc := item.account
+		result, err := s.tryAcquireAccountSlot(ctx, acc.ID, acc.Concurrency)
+		if err == nil && result.Acquired {
+			// 会话数量限制检查
+			if !s.checkAndRegisterSession(ctx, acc, sessionHash) {
+				result.ReleaseFunc() // 释放槽位，继续尝试下一个账号
+				continue
+			}
+			if sessionHash != "" && s.cache != nil {
+				_ = s.cache.SetSessionAccountID(ctx, derefGroupID(groupID), sessionHash, acc.ID, stickySessionTTL)
+			}
+			return &AccountSelectionResult{
+				Account:     acc,
+				Acquired:    true,
+				ReleaseFunc: result.ReleaseFunc,
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// ReportAccountScheduleResult 上报一次转发结果，更新账号错误率与首 token 耗时统计（供 lowest_latency 策略使用）。
+func (s *GatewayService) ReportAccountScheduleResult(accountID int64, success bool, firstTokenMs *int) {
+	if s == nil {
+		return
+	}
+	s.accountStats.report(accountID, success, firstTokenMs)
+}
+
+// RecordAccountSwitch 记录一次故障切换导致的账号切换。
+func (s *GatewayService) RecordAccountSwitch() {
+	if s == nil {
+		return
+	}
+	s.schedulerMetrics.recordSwitch()
+}
+
+// SnapshotAccountSchedulerMetrics 返回调度指标快照，口径与 OpenAI 调度器一致。
+func (s *GatewayService) SnapshotAccountSchedulerMetrics() AccountSchedulerMetricsSnapshot {
+	if s == nil {
+		return AccountSchedulerMetricsSnapshot{}
+	}
+	return s.schedulerMetrics.snapshot(s.accountStats.size())
+}
+
 func (s *GatewayService) schedulingConfig() config.GatewaySchedulingConfig {
 	if s.cfg != nil {
 		return s.cfg.Gateway.Scheduling
@@ -2137,10 +2253,15 @@ func (s *GatewayService) withWindowCostPrefetch(ctx context.Context, accounts []
 // isAccountSchedulableForQuota 检查 API Key 账号是否在配额限制内
 // 仅适用于配置了 quota_limit 的 apikey 类型账号
 func (s *GatewayService) isAccountSchedulableForQuota(account *Account) bool {
-	if account.Type != AccountTypeAPIKey {
-		return true
-	}
-	return !account.IsQuotaExceeded()
+	return isAccountWithinQuota(account)
+}
+
+// isAccountSchedulableForLimits 依次检查配额、窗口费用与 RPM。
+// isSticky 为 true 时允许 StickyOnly 状态的账号继续服务已绑定的会话。
+func (s *GatewayService) isAccountSchedulableForLimits(ctx context.Context, account *Account, isSticky bool) bool {
+	return s.isAccountSchedulableForQuota(account) &&
+		s.isAccountSchedulableForWindowCost(ctx, account, isSticky) &&
+		s.isAccountSchedulableForRPM(ctx, account, isSticky)
 }
 
 // isAccountSchedulableForWindowCost 检查账号是否可根据窗口费用进行调度
@@ -2620,7 +2741,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 						if clearSticky {
 							_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 						}
-						if !clearSticky && s.isAccountInGroup(account, groupID) && account.Platform == platform && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForQuota(account) && s.isAccountSchedulableForWindowCost(ctx, account, true) && s.isAccountSchedulableForRPM(ctx, account, true) {
+						if !clearSticky && s.isAccountInGroup(account, groupID) && account.Platform == platform && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForLimits(ctx, account, true) {
 							if s.debugModelRoutingEnabled() {
 								logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] legacy routed sticky hit: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), accountID)
 							}
@@ -2674,13 +2795,8 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 			if !s.isAccountSchedulableForModelSelection(ctx, acc, requestedModel) {
 				continue
 			}
-			if !s.isAccountSchedulableForQuota(acc) {
-				continue
-			}
-			if !s.isAccountSchedulableForWindowCost(ctx, acc, false) {
-				continue
-			}
-			if !s.isAccountSchedulableForRPM(ctx, acc, false) {
+			// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+			if !s.isAccountSchedulableForLimits(ctx, acc, false) {
 				continue
 			}
 			if selected == nil {
@@ -2733,7 +2849,7 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
-					if !clearSticky && s.isAccountInGroup(account, groupID) && account.Platform == platform && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForQuota(account) && s.isAccountSchedulableForWindowCost(ctx, account, true) && s.isAccountSchedulableForRPM(ctx, account, true) {
+					if !clearSticky && s.isAccountInGroup(account, groupID) && account.Platform == platform && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForLimits(ctx, account, true) {
 						return account, nil
 					}
 				}
@@ -2776,13 +2892,8 @@ func (s *GatewayService) selectAccountForModelWithPlatform(ctx context.Context,
 		if !s.isAccountSchedulableForModelSelection(ctx, acc, requestedModel) {
 			continue
 		}
-		if !s.isAccountSchedulableForQuota(acc) {
-			continue
-		}
-		if !s.isAccountSchedulableForWindowCost(ctx, acc, false) {
-			continue
-		}
-		if !s.isAccountSchedulableForRPM(ctx, acc, false) {
+		// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+		if !s.isAccountSchedulableForLimits(ctx, acc, false) {
 			continue
 		}
 		if selected == nil {
@@ -2854,7 +2965,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 						if clearSticky {
 							_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 						}
-						if !clearSticky && s.isAccountInGroup(account, groupID) && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForQuota(account) && s.isAccountSchedulableForWindowCost(ctx, account, true) && s.isAccountSchedulableForRPM(ctx, account, true) {
+						if !clearSticky && s.isAccountInGroup(account, groupID) && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForLimits(ctx, account, true) {
 							if account.Platform == nativePlatform || (account.Platform == PlatformAntigravity && account.IsMixedSchedulingEnabled()) {
 								if s.debugModelRoutingEnabled() {
 									logger.LegacyPrintf("service.gateway", "[ModelRoutingDebug] legacy mixed routed sticky hit: group_id=%v model=%s session=%s account=%d", derefGroupID(groupID), requestedModel, shortSessionHash(sessionHash), accountID)
@@ -2910,13 +3021,8 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 			if !s.isAccountSchedulableForModelSelection(ctx, acc, requestedModel) {
 				continue
 			}
-			if !s.isAccountSchedulableForQuota(acc) {
-				continue
-			}
-			if !s.isAccountSchedulableForWindowCost(ctx, acc, false) {
-				continue
-			}
-			if !s.isAccountSchedulableForRPM(ctx, acc, false) {
+			// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+			if !s.isAccountSchedulableForLimits(ctx, acc, false) {
 				continue
 			}
 			if selected == nil {
@@ -2969,7 +3075,7 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 					if clearSticky {
 						_ = s.cache.DeleteSessionAccountID(ctx, derefGroupID(groupID), sessionHash)
 					}
-					if !clearSticky && s.isAccountInGroup(account, groupID) && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForQuota(account) && s.isAccountSchedulableForWindowCost(ctx, account, true) && s.isAccountSchedulableForRPM(ctx, account, true) {
+					if !clearSticky && s.isAccountInGroup(account, groupID) && (requestedModel == "" || s.isModelSupportedByAccountWithContext(ctx, account, requestedModel)) && s.isAccountSchedulableForModelSelection(ctx, account, requestedModel) && s.isAccountSchedulableForLimits(ctx, account, true) {
 						if account.Platform == nativePlatform || (account.Platform == PlatformAntigravity && account.IsMixedSchedulingEnabled()) {
 							return account, nil
 						}
@@ -3014,13 +3120,8 @@ func (s *GatewayService) selectAccountWithMixedScheduling(ctx context.Context, g
 		if !s.isAccountSchedulableForModelSelection(ctx, acc, requestedModel) {
 			continue
 		}
-		if !s.isAccountSchedulableForQuota(acc) {
-			continue
-		}
-		if !s.isAccountSchedulableForWindowCost(ctx, acc, false) {
-			continue
-		}
-		if !s.isAccountSchedulableForRPM(ctx, acc, false) {
+		// 配额 / 窗口费用 / RPM 检查（非粘性会话路径）
+		if !s.isAccountSchedulableForLimits(ctx, acc, false) {
 			continue
 		}
 		if selected == nil {
@@ -3986,6 +4087,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	// 强制执行 cache_control 块数量限制（最多 4 个）
 	body = enforceCacheControlLimit(body)
 
+	// 分组开启自动缓存断点时，为未设置 cache_control 的稳定前缀注入断点
+	body, autoCacheBreakpoints := s.applyAutoCacheBreakpoints(ctx, account, parsed, body)
+
 	// 应用模型映射：
 	// - APIKey 账号：使用账号级别的显式映射（如果配置），否则透传原始模型名
 	// - OAuth/SetupToken 账号：使用 Anthropic 标准映射（短ID → 长ID）
@@ -4351,6 +4455,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		})
 		return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
 	}
+	if tooLong := s.promptTooLongForModelFallback(ctx, c, account, resp); tooLong != nil {
+		return nil, tooLong
+	}
 	if resp.StatusCode >= 400 {
 		// 可选：对部分 400 触发 failover（默认关闭以保持语义）
 		if resp.StatusCode == 400 && s.cfg != nil && s.cfg.Gateway.FailoverOn400 {
@@ -4431,13 +4538,14 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	}
 
 	return &ForwardResult{
-		RequestID:        resp.Header.Get("x-request-id"),
-		Usage:            *usage,
-		Model:            originalModel, // 使用原始模型用于计费和日志
-		Stream:           reqStream,
-		Duration:         time.Since(startTime),
-		FirstTokenMs:     firstTokenMs,
-		ClientDisconnect: clientDisconnect,
+		RequestID:            resp.Header.Get("x-request-id"),
+		Usage:                *usage,
+		Model:                originalModel, // 使用原始模型用于计费和日志
+		Stream:               reqStream,
+		Duration:             time.Since(startTime),
+		FirstTokenMs:         firstTokenMs,
+		ClientDisconnect:     clientDisconnect,
+		AutoCacheBreakpoints: autoCacheBreakpoints,
 	}, nil
 }
 
@@ -4616,6 +4724,9 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthrough(
 		})
 		return nil, &UpstreamFailoverError{StatusCode: resp.StatusCode, ResponseBody: respBody}
 	}
+	if tooLong := s.promptTooLongForModelFallback(ctx, c, account, resp); tooLong != nil {
+		return nil, tooLong
+	}
 
 	if resp.StatusCode >= 400 {
 		return s.handleErrorResponse(ctx, resp, c, account)
@@ -5432,6 +5543,11 @@ func (s *GatewayService) shouldFailoverOn400(respBody []byte) bool {
 		return false
 	}
 
+	// 上下文超限与账号无关，换账号只会重复失败（消息中可能带 tool_result 等字样，需优先排除）
+	if isContextTooLongMessage(msg) {
+		return false
+	}
+
 	// 缺少/错误的 beta header：换账号/链路可能成功（尤其是混合调度时）。
 	// 更精确匹配 beta 相关的兼容性问题，避免误触发切换。
 	if strings.Contains(msg, "anthropic-beta") ||
@@ -5785,6 +5901,12 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 		c.Header("x-request-id", v)
 	}
 
+	// 流式断线续传：事件带 ID 并写入缓冲，客户端断开后仍继续记录
+	resumeRecorder := StreamResumeRecorderFromContext(ctx)
+	if resumeRecorder != nil {
+		c.Header(StreamResumeIDHeader, resumeRecorder.ID())
+	}
+
 	w := c.Writer
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -5856,7 +5978,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 			return
 		}
 		errorEventSent = true
-		_, _ = fmt.Fprintf(w, "event: error\ndata: {\"error\":\"%s\"}\n\n", reason)
+		_, _ = fmt.Fprint(w, resumeRecorder.Append(fmt.Sprintf("event: error\ndata: {\"error\":\"%s\"}\n\n", reason)))
 		flusher.Flush()
 	}
 
@@ -6031,6 +6153,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 				}
 
 				for _, block := range outputBlocks {
+					block = resumeRecorder.Append(block)
 					if !clientDisconnected {
 						if _, werr := fmt.Fprint(w, block); werr != nil {
 							clientDisconnected = true
@@ -6412,11 +6535,14 @@ type RecordUsageInput struct {
 	APIKey            *APIKey
 	User              *User
 	Account           *Account
-	Subscription      *UserSubscription  // 可选：订阅信息
-	UserAgent         string             // 请求的 User-Agent
-	IPAddress         string             // 请求的客户端 IP 地址
-	ForceCacheBilling bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
-	APIKeyService     APIKeyQuotaUpdater // 可选：用于更新API Key配额
+	Subscription      *UserSubscription     // 可选：订阅信息
+	UserAgent         string                // 请求的 User-Agent
+	IPAddress         string                // 请求的客户端 IP 地址
+	ForceCacheBilling bool                  // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
+	APIKeyService     APIKeyQuotaUpdater    // 可选：用于更新API Key配额
+	Experiment        *ExperimentAssignment // 可选：A/B 实验分配，用于标记使用日志
+	ResponseCacheHit  *ResponseCacheHit     // 可选：网关响应缓存命中，按命中价格计费且不计入账号用量
+	ContextPolicy     *ContextPolicyOutcome // 可选：上下文策略处理结果，用于标记使用日志
 }
 
 // APIKeyQuotaUpdater defines the interface for updating API Key quota and rate limit usage
@@ -6435,6 +6561,8 @@ type postUsageBillingParams struct {
 	IsSubscriptionBill    bool
 	AccountRateMultiplier float64
 	APIKeyService         APIKeyQuotaUpdater
+	// SkipAccountUsage 未实际占用上游账号（如响应缓存命中），跳过账号配额与最近使用时间更新
+	SkipAccountUsage bool
 }
 
 // postUsageBilling 统一处理使用量记录后的扣费逻辑：
@@ -6477,6 +6605,10 @@ func postUsageBilling(ctx context.Context, p *postUsageBillingParams, deps *bill
 		deps.billingCacheService.QueueUpdateAPIKeyRateLimitUsage(p.APIKey.ID, cost.ActualCost)
 	}
 
+	if p.SkipAccountUsage {
+		return
+	}
+
 	// 4. 账号配额用量（账号口径：TotalCost × 账号计费倍率）
 	if cost.TotalCost > 0 && p.Account.Type == AccountTypeAPIKey && p.Account.HasAnyQuotaLimit() {
 		accountCost := cost.TotalCost * p.AccountRateMultiplier
@@ -6591,6 +6723,12 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		}
 	}
 
+	// 自动缓存断点节省：与缓存 Token 全部按普通输入计费的差额
+	promptCacheSavings := 0.0
+	if result.AutoCacheBreakpoints > 0 && result.MediaType == "" && result.ImageCount == 0 {
+		promptCacheSavings = s.calculatePromptCacheSavings(result, multiplier, cost)
+	}
+
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
 	billingType := BillingTypeBalance
@@ -6609,6 +6747,13 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		mediaType = &result.MediaType
 	}
 	accountRateMultiplier := account.BillingRateMultiplier()
+	// 响应缓存命中：按命中价格折算费用，单独的计费类型，账号口径费用为 0
+	skipAccountUsage := input.ResponseCacheHit != nil
+	if skipAccountUsage {
+		cost = input.ResponseCacheHit.apply(cost)
+		billingType = BillingTypeResponseCache
+		accountRateMultiplier = 0
+	}
 	usageLog := &UsageLog{
 		UserID:                user.ID,
 		APIKeyID:              apiKey.ID,
@@ -6657,15 +6802,23 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 	if subscription != nil {
 		usageLog.SubscriptionID = &subscription.ID
 	}
+	input.Experiment.tagUsageLog(usageLog)
+	input.ContextPolicy.tagUsageLog(usageLog)
+	usageLog.AutoCacheBreakpoints = result.AutoCacheBreakpoints
+	usageLog.PromptCacheSavings = promptCacheSavings
 
 	inserted, err := s.usageLogRepo.Create(ctx, usageLog)
 	if err != nil {
 		logger.LegacyPrintf("service.gateway", "Create usage log failed: %v", err)
 	}
 
+	recordRequestRateLimitUsage(ctx, input.APIKeyService, apiKey, usageLog.TotalTokens())
+
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
-		s.deferredService.ScheduleLastUsedUpdate(account.ID)
+		if !skipAccountUsage {
+			s.deferredService.ScheduleLastUsedUpdate(account.ID)
+		}
 		return nil
 	}
 
@@ -6681,8 +6834,9 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 			IsSubscriptionBill:    isSubscriptionBilling,
 			AccountRateMultiplier: accountRateMultiplier,
 			APIKeyService:         input.APIKeyService,
+			SkipAccountUsage:      skipAccountUsage,
 		}, s.billingDeps())
-	} else {
+	} else if !skipAccountUsage {
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
 	}
 
@@ -6837,6 +6991,8 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 		logger.LegacyPrintf("service.gateway", "Create usage log failed: %v", err)
 	}
 
+	recordRequestRateLimitUsage(ctx, input.APIKeyService, apiKey, usageLog.TotalTokens())
+
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
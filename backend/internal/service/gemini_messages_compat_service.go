@@ -54,6 +54,12 @@ type GeminiMessagesCompatService struct {
 	antigravityGatewayService *AntigravityGatewayService
 	cfg                       *config.Config
 	responseHeaderFilter      *responseheaders.CompiledHeaderFilter
+
+	// 账号调度：分组策略实例；负载、运行时统计与决策指标与 GatewayService 共用（见 ShareAccountScheduling）
+	schedulingPolicies accountSchedulingPolicyRegistry
+	concurrencyService *ConcurrencyService
+	accountStats       *accountRuntimeStats
+	schedulerMetrics   *accountSchedulerMetrics
 }
 
 func NewGeminiMessagesCompatService(
@@ -81,6 +87,17 @@ func NewGeminiMessagesCompatService(
 	}
 }
 
+// ShareAccountScheduling 复用 GatewayService 的并发负载、账号运行时统计与调度指标。
+// Gemini 分组的转发结果由网关统一上报，共用后分组调度策略才能拿到真实的负载与错误率/TTFT。
+func (s *GeminiMessagesCompatService) ShareAccountScheduling(gatewayService *GatewayService) {
+	if s == nil || gatewayService == nil {
+		return
+	}
+	s.concurrencyService = gatewayService.concurrencyService
+	s.accountStats = gatewayService.accountStats
+	s.schedulerMetrics = &gatewayService.schedulerMetrics
+}
+
 // GetTokenProvider returns the token provider for OAuth accounts
 func (s *GeminiMessagesCompatService) GetTokenProvider() *GeminiTokenProvider {
 	return s.tokenProvider
@@ -91,6 +108,19 @@ func (s *GeminiMessagesCompatService) SelectAccountForModel(ctx context.Context,
 }
 
 func (s *GeminiMessagesCompatService) SelectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}) (*Account, error) {
+	decision := AccountScheduleDecision{}
+	start := time.Now()
+	account, err := s.selectAccountForModelWithExclusions(ctx, groupID, sessionHash, requestedModel, excludedIDs, &decision)
+	decision.LatencyMs = time.Since(start).Milliseconds()
+	if account != nil {
+		decision.SelectedAccountID = account.ID
+		decision.SelectedAccountType = account.Type
+	}
+	s.schedulerMetrics.recordSelect(decision)
+	return account, err
+}
+
+func (s *GeminiMessagesCompatService) selectAccountForModelWithExclusions(ctx context.Context, groupID *int64, sessionHash string, requestedModel string, excludedIDs map[int64]struct{}, decision *AccountScheduleDecision) (*Account, error) {
 	// 1. 确定目标平台和调度模式
 	// Determine target platform and scheduling mode
 	platform, useMixedScheduling, hasForcePlatform, err := s.resolvePlatformAndSchedulingMode(ctx, groupID)
@@ -103,6 +133,8 @@ func (s *GeminiMessagesCompatService) SelectAccountForModelWithExclusions(ctx co
 	// 2. 尝试粘性会话命中
 	// Try sticky session hit
 	if account := s.tryStickySessionHit(ctx, groupID, sessionHash, cacheKey, requestedModel, excludedIDs, platform, useMixedScheduling); account != nil {
+		decision.Layer = accountScheduleLayerSessionSticky
+		decision.StickySessionHit = true
 		return account, nil
 	}
 
@@ -120,9 +152,9 @@ func (s *GeminiMessagesCompatService) SelectAccountForModelWithExclusions(ctx co
 		}
 	}
 
-	// 4. 按优先级 + LRU 选择最佳账号
-	// Select best account by priority + LRU
-	selected := s.selectBestGeminiAccount(ctx, accounts, requestedModel, excludedIDs, platform, useMixedScheduling)
+	// 4. 按分组调度策略选择最佳账号（默认优先级 + LRU）
+	// Select best account by group scheduling strategy (priority + LRU by default)
+	selected := s.selectBestGeminiAccount(ctx, groupID, accounts, requestedModel, excludedIDs, platform, useMixedScheduling, decision)
 
 	if selected == nil {
 		if requestedModel != "" {
@@ -260,6 +292,12 @@ func (s *GeminiMessagesCompatService) isAccountUsableForRequestWithPrecheck(
 		return false
 	}
 
+	// 配额检查
+	// Quota check
+	if !isAccountWithinQuota(account) {
+		return false
+	}
+
 	// 速率限制预检
 	// Rate limit precheck
 	if !s.passesRateLimitPreCheckWithCache(ctx, account, requestedModel, precheckResult) {
@@ -302,22 +340,32 @@ func (s *GeminiMessagesCompatService) passesRateLimitPreCheckWithCache(ctx conte
 	return ok
 }
 
-// selectBestGeminiAccount 从候选账号中选择最佳账号（优先级 + LRU + OAuth 优先）。
+// selectBestGeminiAccount 从候选账号中选择最佳账号，并将候选数、策略与负载偏斜写入 decision。
+// 分组未配置调度策略时使用优先级 + LRU + OAuth 优先；否则交由分组策略按实时负载与运行时统计排序。
 // 返回 nil 表示无可用账号。
 //
-// selectBestGeminiAccount selects best account from candidates (priority + LRU + OAuth preferred).
+// selectBestGeminiAccount selects best account from candidates.
+// Uses priority + LRU + OAuth preferred unless the group configures a scheduling strategy.
 // Returns nil if no available account.
 func (s *GeminiMessagesCompatService) selectBestGeminiAccount(
 	ctx context.Context,
+	groupID *int64,
 	accounts []Account,
 	requestedModel string,
 	excludedIDs map[int64]struct{},
 	platform string,
 	useMixedScheduling bool,
+	decision *AccountScheduleDecision,
 ) *Account {
-	var selected *Account
 	precheckResult := s.buildPreCheckUsageResultMap(ctx, accounts, requestedModel)
+	policy := s.schedulingPolicies.resolve(groupID, schedulingStrategyFromContext(ctx, groupID))
+	decision.Layer = accountScheduleLayerLoadBalance
+	decision.Strategy = string(AccountSchedulingStrategyPriorityLRU)
+	if policy != nil {
+		decision.Strategy = string(policy.Strategy())
+	}
 
+	var usable []*Account
 	for i := range accounts {
 		acc := &accounts[i]
 
@@ -330,19 +378,58 @@ func (s *GeminiMessagesCompatService) selectBestGeminiAccount(
 		if !s.isAccountUsableForRequestWithPrecheck(ctx, acc, requestedModel, platform, useMixedScheduling, precheckResult) {
 			continue
 		}
+		usable = append(usable, acc)
+	}
+	decision.CandidateCount = len(usable)
+	if len(usable) == 0 {
+		return nil
+	}
 
-		// 选择最佳账号
-		if selected == nil {
-			selected = acc
-			continue
+	if policy == nil {
+		selected := usable[0]
+		for _, acc := range usable[1:] {
+			if s.isBetterGeminiAccount(acc, selected) {
+				selected = acc
+			}
 		}
+		return selected
+	}
 
-		if s.isBetterGeminiAccount(acc, selected) {
-			selected = acc
+	// 兼容路径不做并发槽位管理，取策略排序的首位即可
+	loadMap := s.loadGeminiCandidates(ctx, usable)
+	if len(loadMap) > 0 {
+		loadRateSum, loadRateSumSquares := 0.0, 0.0
+		for _, acc := range usable {
+			if loadInfo := loadMap[acc.ID]; loadInfo != nil {
+				loadRate := float64(loadInfo.LoadRate)
+				loadRateSum += loadRate
+				loadRateSumSquares += loadRate * loadRate
+			}
 		}
+		decision.LoadSkew = calcLoadSkewByMoments(loadRateSum, loadRateSumSquares, len(usable))
 	}
+	ordered := policy.Order(buildAccountScheduleCandidates(usable, loadMap, s.accountStats), deriveAccountSelectionSeed(groupID, "", requestedModel))
+	return ordered[0].Account
+}
 
-	return selected
+// loadGeminiCandidates 批量查询候选账号的并发负载；未注入并发服务或查询失败时返回 nil，策略按零负载排序。
+func (s *GeminiMessagesCompatService) loadGeminiCandidates(ctx context.Context, accounts []*Account) map[int64]*AccountLoadInfo {
+	if s.concurrencyService == nil {
+		return nil
+	}
+	accountLoads := make([]AccountWithConcurrency, 0, len(accounts))
+	for _, acc := range accounts {
+		accountLoads = append(accountLoads, AccountWithConcurrency{
+			ID:             acc.ID,
+			MaxConcurrency: acc.EffectiveLoadFactor(),
+		})
+	}
+	loadMap, err := s.concurrencyService.GetAccountsLoadBatch(ctx, accountLoads)
+	if err != nil {
+		logger.LegacyPrintf("service.gemini_messages_compat", "[Gemini Schedule] load batch failed: %v", err)
+		return nil
+	}
+	return loadMap
 }
 
 func (s *GeminiMessagesCompatService) buildPreCheckUsageResultMap(ctx context.Context, accounts []Account, requestedModel string) map[int64]bool {
@@ -358,6 +358,51 @@ func TestGeminiMessagesCompatService_GroupResolution_ReusesContextGroup(t *testi
 	require.Equal(t, 0, groupRepo.getByIDLiteCalls)
 }
 
+func TestGeminiMessagesCompatService_GroupStrategyUsesSharedStatsAndRecordsDecision(t *testing.T) {
+	groupID := int64(7)
+	group := &Group{
+		ID:                 groupID,
+		Platform:           PlatformGemini,
+		Status:             StatusActive,
+		Hydrated:           true,
+		SchedulingStrategy: string(AccountSchedulingStrategyLowestLatency),
+	}
+	ctx := context.WithValue(context.Background(), ctxkey.Group, group)
+
+	repo := &mockAccountRepoForGemini{
+		accounts: []Account{
+			{ID: 1, Platform: PlatformGemini, Priority: 1, Status: StatusActive, Schedulable: true},
+			{ID: 2, Platform: PlatformGemini, Priority: 2, Status: StatusActive, Schedulable: true},
+		},
+		accountsByID: map[int64]*Account{},
+	}
+	for i := range repo.accounts {
+		repo.accountsByID[repo.accounts[i].ID] = &repo.accounts[i]
+	}
+
+	gatewaySvc := &GatewayService{accountStats: newAccountRuntimeStats()}
+	slow, fast := 1500, 200
+	gatewaySvc.ReportAccountScheduleResult(1, true, &slow)
+	gatewaySvc.ReportAccountScheduleResult(2, true, &fast)
+
+	svc := &GeminiMessagesCompatService{
+		accountRepo: repo,
+		groupRepo:   &mockGroupRepoForGemini{groups: map[int64]*Group{}},
+		cache:       &mockGatewayCacheForGemini{},
+	}
+	svc.ShareAccountScheduling(gatewaySvc)
+
+	acc, err := svc.SelectAccountForModelWithExclusions(ctx, &groupID, "", "gemini-2.5-flash", nil)
+	require.NoError(t, err)
+	require.NotNil(t, acc)
+	require.Equal(t, int64(2), acc.ID, "lowest_latency 应使用网关上报的首 token 耗时")
+
+	snapshot := gatewaySvc.SnapshotAccountSchedulerMetrics()
+	require.Equal(t, int64(1), snapshot.SelectTotal)
+	require.Equal(t, int64(1), snapshot.LoadBalanceSelectTotal)
+	require.Equal(t, 2, snapshot.RuntimeStatsAccountCount)
+}
+
 func TestGeminiMessagesCompatService_GroupResolution_UsesLiteFetch(t *testing.T) {
 	ctx := context.Background()
 	groupID := int64(7)
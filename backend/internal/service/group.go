@@ -61,6 +61,40 @@ type Group struct {
 	AllowMessagesDispatch bool
 	DefaultMappedModel    string
 
+	// Key 共享防护：每个 API Key 每小时允许的不同来源 IP 数，0 表示不限制
+	MaxIPsPerKeyPerHour int
+
+	// 请求速率限制（分组内所有请求合计，滑动窗口），0 表示不限制
+	RPMLimit int
+	TPMLimit int
+
+	// 账号满载排队优先级（0-9，越大越优先），与用户级优先级取较大值
+	QueuePriority int
+
+	// 账号调度策略，空字符串表示使用平台默认策略
+	SchedulingStrategy string
+
+	// 模型降级链：源模型匹配且命中触发条件时依次尝试降级模型
+	ModelFallbackChains []ModelFallbackChain
+
+	// 跨平台故障转移：anthropic 与 antigravity 账号互为后备，本平台账号耗尽后转移到对端平台
+	CrossPlatformFailover bool
+
+	// 影子流量镜像配置，nil 表示未启用
+	ShadowMirror *ShadowMirrorConfig
+
+	// 网关响应缓存配置，nil 表示未启用
+	ResponseCache *ResponseCacheConfig
+
+	// 自动 prompt 缓存断点：为客户端未设置 cache_control 的稳定前缀注入缓存断点
+	AutoCacheBreakpoints bool
+
+	// 流式断线续传：服务端短暂缓冲 SSE 事件，客户端断线后可凭 Last-Event-ID 续传
+	StreamResume bool
+
+	// 上下文策略配置，nil 表示未启用
+	ContextPolicy *ContextPolicyConfig
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
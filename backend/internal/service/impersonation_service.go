@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ImpersonationScopeRead 只读模拟：仅允许查询类请求（默认）
+	ImpersonationScopeRead = "read"
+	// ImpersonationScopeWrite 可写模拟：允许普通写操作，敏感操作仍被拦截
+	ImpersonationScopeWrite = "write"
+
+	ImpersonationEventStart   = "start"
+	ImpersonationEventAction  = "action"
+	ImpersonationEventBlocked = "blocked"
+	ImpersonationEventEnd     = "end"
+
+	defaultImpersonationDuration = 30 * time.Minute
+	maxImpersonationDuration     = 2 * time.Hour
+	impersonationReasonMaxLen    = 500
+	impersonationAuditTimeout    = 3 * time.Second
+)
+
+var (
+	ErrImpersonationActorRequired  = infraerrors.Forbidden("IMPERSONATION_ACTOR_REQUIRED", "impersonation must be started by a signed-in admin")
+	ErrImpersonationReasonRequired = infraerrors.BadRequest("IMPERSONATION_REASON_REQUIRED", "a reason is required to impersonate a user")
+	ErrImpersonationSelf           = infraerrors.BadRequest("IMPERSONATION_SELF", "you cannot impersonate yourself")
+	ErrImpersonationTargetAdmin    = infraerrors.Forbidden("IMPERSONATION_TARGET_ADMIN", "admin accounts cannot be impersonated")
+	ErrImpersonationTargetInactive = infraerrors.BadRequest("IMPERSONATION_TARGET_INACTIVE", "user account is not active")
+	ErrImpersonationEnded          = infraerrors.Unauthorized("IMPERSONATION_ENDED", "impersonation session has ended")
+	ErrImpersonationReadOnly       = infraerrors.Forbidden("IMPERSONATION_READ_ONLY", "this impersonation session is read-only")
+	ErrImpersonationActionBlocked  = infraerrors.Forbidden("IMPERSONATION_ACTION_BLOCKED", "this action is not allowed while impersonating a user")
+	ErrNotImpersonating            = infraerrors.BadRequest("NOT_IMPERSONATING", "current session is not an impersonation session")
+)
+
+// impersonationSensitiveRoutes 模拟期间始终禁止的路由（相对 /api/v1，格式见 matchRouteTemplate）：
+// 修改密码、2FA/Passkey、外部身份绑定、会话撤销，以及会返回 Key 明文的创建/轮换和兑换
+var impersonationSensitiveRoutes = []string{
+	"PUT user/password",
+	"POST user/totp/*",
+	"POST user/passkeys/*",
+	"PUT user/passkeys/*",
+	"DELETE user/identities/*",
+	"POST user/oidc/*",
+	"DELETE user/sessions/*",
+	"POST auth/revoke-all-sessions",
+	"POST keys",
+	"POST keys/:id/rotate",
+	"POST redeem",
+}
+
+// impersonationReadOnlyAllowedRoutes 只读模拟下仍允许的非 GET 请求（只查询数据或结束模拟）
+var impersonationReadOnlyAllowedRoutes = []string{
+	"POST usage/dashboard/api-keys-usage",
+	"POST auth/impersonation/end",
+}
+
+// ImpersonationClaim 写入模拟 Token 的声明，前端据此显示模拟横幅
+type ImpersonationClaim struct {
+	ID      string `json:"id"`
+	AdminID int64  `json:"admin_id"`
+	Scope   string `json:"scope"`
+}
+
+// ImpersonationSession 进行中的模拟会话（保存在缓存中，删除即结束）
+type ImpersonationSession struct {
+	ID           string    `json:"id"`
+	AdminID      int64     `json:"admin_id"`
+	AdminEmail   string    `json:"admin_email"`
+	TargetUserID int64     `json:"target_user_id"`
+	Scope        string    `json:"scope"`
+	Reason       string    `json:"reason"`
+	StartedAt    time.Time `json:"started_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// ReadOnly 是否为只读模拟
+func (s *ImpersonationSession) ReadOnly() bool {
+	return s.Scope != ImpersonationScopeWrite
+}
+
+// ImpersonationAuditLog 模拟审计日志
+type ImpersonationAuditLog struct {
+	ID              int64     `json:"id"`
+	ImpersonationID string    `json:"impersonation_id"`
+	AdminID         int64     `json:"admin_id"`
+	TargetUserID    int64     `json:"target_user_id"`
+	Event           string    `json:"event"`
+	Scope           string    `json:"scope"`
+	Method          string    `json:"method"`
+	Path            string    `json:"path"`
+	StatusCode      int       `json:"status_code"`
+	Detail          string    `json:"detail"`
+	IP              string    `json:"ip"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ImpersonationAuditFilter 审计日志查询条件（零值表示不过滤）
+type ImpersonationAuditFilter struct {
+	ImpersonationID string
+	AdminID         int64
+	TargetUserID    int64
+}
+
+// ImpersonationInput 开始模拟的参数
+type ImpersonationInput struct {
+	Reason          string
+	WriteAccess     bool
+	DurationMinutes int
+	ClientIP        string
+}
+
+// ImpersonationStartResult 开始模拟的结果（不签发 Refresh Token，到期即需重新发起）
+type ImpersonationStartResult struct {
+	AccessToken string                `json:"access_token"`
+	ExpiresIn   int                   `json:"expires_in"`
+	TokenType   string                `json:"token_type"`
+	Session     *ImpersonationSession `json:"session"`
+}
+
+// ImpersonationCache 进行中的模拟会话存储
+type ImpersonationCache interface {
+	SaveImpersonation(ctx context.Context, session *ImpersonationSession, ttl time.Duration) error
+	// GetImpersonation 不存在（已结束或过期）时返回 (nil, nil)
+	GetImpersonation(ctx context.Context, id string) (*ImpersonationSession, error)
+	DeleteImpersonation(ctx context.Context, id string) error
+}
+
+// ImpersonationAuditRepository 模拟审计日志存储
+type ImpersonationAuditRepository interface {
+	Create(ctx context.Context, log *ImpersonationAuditLog) error
+	List(ctx context.Context, filter ImpersonationAuditFilter, params pagination.PaginationParams) ([]ImpersonationAuditLog, *pagination.PaginationResult, error)
+}
+
+// ImpersonationService 管理员模拟用户登录：签发短期、带明确标记的用户 Token，
+// 默认只读，始终拦截敏感操作，并将开始、结束与每个请求写入审计日志。
+type ImpersonationService struct {
+	authService *AuthService
+	userRepo    UserRepository
+	cache       ImpersonationCache
+	auditRepo   ImpersonationAuditRepository
+	now         func() time.Time
+}
+
+// NewImpersonationService 创建模拟登录服务
+func NewImpersonationService(authService *AuthService, userRepo UserRepository, cache ImpersonationCache, auditRepo ImpersonationAuditRepository) *ImpersonationService {
+	return &ImpersonationService{
+		authService: authService,
+		userRepo:    userRepo,
+		cache:       cache,
+		auditRepo:   auditRepo,
+		now:         time.Now,
+	}
+}
+
+// Start 以 adminID 身份开始模拟 targetUserID
+func (s *ImpersonationService) Start(ctx context.Context, adminID, targetUserID int64, input ImpersonationInput) (*ImpersonationStartResult, error) {
+	// 管理员 API Key 没有对应的用户身份，无法追溯到具体操作人
+	if adminID <= 0 {
+		return nil, ErrImpersonationActorRequired
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrImpersonationReasonRequired
+	}
+	if len([]rune(reason)) > impersonationReasonMaxLen {
+		reason = string([]rune(reason)[:impersonationReasonMaxLen])
+	}
+	if adminID == targetUserID {
+		return nil, ErrImpersonationSelf
+	}
+
+	admin, err := s.userRepo.GetByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsAdmin() {
+		return nil, ErrImpersonationTargetAdmin
+	}
+	if !target.IsActive() {
+		return nil, ErrImpersonationTargetInactive
+	}
+
+	duration := defaultImpersonationDuration
+	if input.DurationMinutes > 0 {
+		duration = time.Duration(input.DurationMinutes) * time.Minute
+	}
+	duration = min(duration, maxImpersonationDuration)
+
+	id, err := randomHexString(16)
+	if err != nil {
+		return nil, fmt.Errorf("generate impersonation id: %w", err)
+	}
+	scope := ImpersonationScopeRead
+	if input.WriteAccess {
+		scope = ImpersonationScopeWrite
+	}
+	now := s.now()
+	session := &ImpersonationSession{
+		ID:           id,
+		AdminID:      admin.ID,
+		AdminEmail:   admin.Email,
+		TargetUserID: target.ID,
+		Scope:        scope,
+		Reason:       reason,
+		StartedAt:    now,
+		ExpiresAt:    now.Add(duration),
+	}
+
+	token, err := s.authService.generateImpersonationToken(target, session)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.SaveImpersonation(ctx, session, duration); err != nil {
+		logger.LegacyPrintf("service.impersonation", "[Impersonation] save session failed: admin=%d target=%d err=%v", admin.ID, target.ID, err)
+		return nil, ErrServiceUnavailable
+	}
+	// 开始事件必须落库，否则撤销会话并拒绝本次模拟
+	if err := s.auditRepo.Create(ctx, &ImpersonationAuditLog{
+		ImpersonationID: id,
+		AdminID:         admin.ID,
+		TargetUserID:    target.ID,
+		Event:           ImpersonationEventStart,
+		Scope:           scope,
+		Detail:          reason,
+		IP:              input.ClientIP,
+	}); err != nil {
+		_ = s.cache.DeleteImpersonation(ctx, id)
+		logger.LegacyPrintf("service.impersonation", "[Impersonation] write audit log failed: admin=%d target=%d err=%v", admin.ID, target.ID, err)
+		return nil, ErrServiceUnavailable
+	}
+
+	logger.LegacyPrintf("service.impersonation", "[Impersonation] started: id=%s admin=%d target=%d scope=%s duration=%s", id, admin.ID, target.ID, scope, duration)
+	return &ImpersonationStartResult{
+		AccessToken: token,
+		ExpiresIn:   int(duration.Seconds()),
+		TokenType:   "Bearer",
+		Session:     session,
+	}, nil
+}
+
+// Validate 校验模拟 Token 对应的会话仍然有效，返回会话详情
+func (s *ImpersonationService) Validate(ctx context.Context, claim *ImpersonationClaim, userID int64) (*ImpersonationSession, error) {
+	session, err := s.cache.GetImpersonation(ctx, claim.ID)
+	if err != nil {
+		logger.LegacyPrintf("service.impersonation", "[Impersonation] load session failed: id=%s err=%v", claim.ID, err)
+		return nil, ErrServiceUnavailable
+	}
+	if session == nil || session.TargetUserID != userID || session.AdminID != claim.AdminID {
+		return nil, ErrImpersonationEnded
+	}
+	return session, nil
+}
+
+// End 结束模拟（Token 随即失效）
+func (s *ImpersonationService) End(ctx context.Context, session *ImpersonationSession, clientIP string) error {
+	if session == nil {
+		return ErrNotImpersonating
+	}
+	if err := s.cache.DeleteImpersonation(ctx, session.ID); err != nil {
+		logger.LegacyPrintf("service.impersonation", "[Impersonation] delete session failed: id=%s err=%v", session.ID, err)
+		return ErrServiceUnavailable
+	}
+	s.record(ctx, &ImpersonationAuditLog{
+		ImpersonationID: session.ID,
+		AdminID:         session.AdminID,
+		TargetUserID:    session.TargetUserID,
+		Event:           ImpersonationEventEnd,
+		Scope:           session.Scope,
+		IP:              clientIP,
+	})
+	logger.LegacyPrintf("service.impersonation", "[Impersonation] ended: id=%s admin=%d target=%d", session.ID, session.AdminID, session.TargetUserID)
+	return nil
+}
+
+// RecordRequest 记录模拟期间的一次请求；blockedReason 非空表示该请求被拦截
+func (s *ImpersonationService) RecordRequest(ctx context.Context, session *ImpersonationSession, method, path string, statusCode int, blockedReason, clientIP string) {
+	event := ImpersonationEventAction
+	if blockedReason != "" {
+		event = ImpersonationEventBlocked
+	}
+	s.record(ctx, &ImpersonationAuditLog{
+		ImpersonationID: session.ID,
+		AdminID:         session.AdminID,
+		TargetUserID:    session.TargetUserID,
+		Event:           event,
+		Scope:           session.Scope,
+		Method:          method,
+		Path:            path,
+		StatusCode:      statusCode,
+		Detail:          blockedReason,
+		IP:              clientIP,
+	})
+}
+
+// record 写入审计日志；请求可能已结束，使用独立的超时上下文
+func (s *ImpersonationService) record(ctx context.Context, entry *ImpersonationAuditLog) {
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), impersonationAuditTimeout)
+	defer cancel()
+	if err := s.auditRepo.Create(writeCtx, entry); err != nil {
+		logger.LegacyPrintf("service.impersonation", "[Impersonation] write audit log failed: id=%s event=%s path=%s err=%v", entry.ImpersonationID, entry.Event, entry.Path, err)
+	}
+}
+
+// ListAuditLogs 分页查询审计日志（按时间倒序）
+func (s *ImpersonationService) ListAuditLogs(ctx context.Context, filter ImpersonationAuditFilter, params pagination.PaginationParams) ([]ImpersonationAuditLog, *pagination.PaginationResult, error) {
+	return s.auditRepo.List(ctx, filter, params)
+}
+
+// CheckImpersonationAccess 判断模拟会话能否访问指定路由。
+// fullPath 为 gin 路由模板（如 /api/v1/keys/:id/rotate）。
+func CheckImpersonationAccess(session *ImpersonationSession, method, fullPath string) error {
+	route := impersonationRelativeRoute(fullPath)
+	for _, scope := range impersonationSensitiveRoutes {
+		if matchRouteTemplate(scope, method, route) {
+			return ErrImpersonationActionBlocked
+		}
+	}
+	if !session.ReadOnly() {
+		return nil
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	for _, scope := range impersonationReadOnlyAllowedRoutes {
+		if matchRouteTemplate(scope, method, route) {
+			return nil
+		}
+	}
+	return ErrImpersonationReadOnly
+}
+
+// impersonationRelativeRoute 将路由模板转换为相对 /api/v1 的形式（如 "keys/:id"）
+func impersonationRelativeRoute(fullPath string) string {
+	const marker = "/api/v1/"
+	if idx := strings.Index(fullPath, marker); idx >= 0 {
+		return fullPath[idx+len(marker):]
+	}
+	return strings.Trim(fullPath, "/")
+}
+
+// generateImpersonationToken 为目标用户签发模拟 Token：有效期与模拟会话一致，携带 imp 声明
+func (s *AuthService) generateImpersonationToken(user *User, session *ImpersonationSession) (string, error) {
+	claims := &JWTClaims{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		Impersonation: &ImpersonationClaim{
+			ID:      session.ID,
+			AdminID: session.AdminID,
+			Scope:   session.Scope,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(session.StartedAt),
+			NotBefore: jwt.NewNumericDate(session.StartedAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(s.cfg.JWT.Secret))
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return tokenString, nil
+}
@@ -0,0 +1,189 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type impersonationCacheStub struct {
+	sessions map[string]*ImpersonationSession
+	ttl      time.Duration
+}
+
+func (c *impersonationCacheStub) SaveImpersonation(ctx context.Context, session *ImpersonationSession, ttl time.Duration) error {
+	cp := *session
+	c.sessions[session.ID] = &cp
+	c.ttl = ttl
+	return nil
+}
+
+func (c *impersonationCacheStub) GetImpersonation(ctx context.Context, id string) (*ImpersonationSession, error) {
+	if s, ok := c.sessions[id]; ok {
+		cp := *s
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (c *impersonationCacheStub) DeleteImpersonation(ctx context.Context, id string) error {
+	delete(c.sessions, id)
+	return nil
+}
+
+type impersonationAuditRepoStub struct {
+	logs []ImpersonationAuditLog
+}
+
+func (r *impersonationAuditRepoStub) Create(ctx context.Context, log *ImpersonationAuditLog) error {
+	log.ID = int64(len(r.logs) + 1)
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *impersonationAuditRepoStub) List(ctx context.Context, filter ImpersonationAuditFilter, params pagination.PaginationParams) ([]ImpersonationAuditLog, *pagination.PaginationResult, error) {
+	return r.logs, &pagination.PaginationResult{Total: int64(len(r.logs))}, nil
+}
+
+func newImpersonationTestService(users ...*User) (*ImpersonationService, *AuthService, *impersonationCacheStub, *impersonationAuditRepoStub) {
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", ExpireHour: 1}}
+	repo := &sessionUserRepoStub{users: map[int64]*User{}}
+	for _, u := range users {
+		repo.users[u.ID] = u
+	}
+	authSvc := NewAuthService(repo, nil, nil, cfg, nil, nil, nil, nil, nil, nil)
+	cache := &impersonationCacheStub{sessions: map[string]*ImpersonationSession{}}
+	audit := &impersonationAuditRepoStub{}
+	return NewImpersonationService(authSvc, repo, cache, audit), authSvc, cache, audit
+}
+
+func TestImpersonationStartMintsMarkedShortLivedToken(t *testing.T) {
+	admin := &User{ID: 1, Email: "admin@example.com", Role: RoleAdmin, Status: StatusActive}
+	user := &User{ID: 2, Email: "user@example.com", Role: RoleUser, Status: StatusActive, TokenVersion: 3}
+	svc, authSvc, cache, audit := newImpersonationTestService(admin, user)
+
+	result, err := svc.Start(context.Background(), admin.ID, user.ID, ImpersonationInput{Reason: " ticket #42 ", DurationMinutes: 600, ClientIP: "203.0.113.1"})
+	require.NoError(t, err)
+	require.Equal(t, int(maxImpersonationDuration.Seconds()), result.ExpiresIn)
+	require.Equal(t, maxImpersonationDuration, cache.ttl)
+	require.Equal(t, ImpersonationScopeRead, result.Session.Scope)
+	require.Equal(t, "ticket #42", result.Session.Reason)
+
+	claims, err := authSvc.ValidateToken(result.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, claims.UserID)
+	require.Equal(t, user.TokenVersion, claims.TokenVersion)
+	require.Empty(t, claims.SessionID)
+	require.NotNil(t, claims.Impersonation)
+	require.Equal(t, result.Session.ID, claims.Impersonation.ID)
+	require.Equal(t, admin.ID, claims.Impersonation.AdminID)
+
+	// 模拟 Token 不能换取普通 Token
+	_, err = authSvc.RefreshToken(context.Background(), result.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	require.Len(t, audit.logs, 1)
+	require.Equal(t, ImpersonationEventStart, audit.logs[0].Event)
+	require.Equal(t, "ticket #42", audit.logs[0].Detail)
+	require.Equal(t, "203.0.113.1", audit.logs[0].IP)
+}
+
+func TestImpersonationStartRejectsInvalidTargets(t *testing.T) {
+	admin := &User{ID: 1, Email: "admin@example.com", Role: RoleAdmin, Status: StatusActive}
+	otherAdmin := &User{ID: 2, Email: "other@example.com", Role: RoleAdmin, Status: StatusActive}
+	disabled := &User{ID: 3, Email: "disabled@example.com", Role: RoleUser, Status: StatusDisabled}
+	svc, _, _, audit := newImpersonationTestService(admin, otherAdmin, disabled)
+	ctx := context.Background()
+
+	_, err := svc.Start(ctx, admin.ID, disabled.ID, ImpersonationInput{Reason: "  "})
+	require.ErrorIs(t, err, ErrImpersonationReasonRequired)
+	_, err = svc.Start(ctx, 0, disabled.ID, ImpersonationInput{Reason: "api key"})
+	require.ErrorIs(t, err, ErrImpersonationActorRequired)
+	_, err = svc.Start(ctx, admin.ID, admin.ID, ImpersonationInput{Reason: "self"})
+	require.ErrorIs(t, err, ErrImpersonationSelf)
+	_, err = svc.Start(ctx, admin.ID, otherAdmin.ID, ImpersonationInput{Reason: "admin"})
+	require.ErrorIs(t, err, ErrImpersonationTargetAdmin)
+	_, err = svc.Start(ctx, admin.ID, disabled.ID, ImpersonationInput{Reason: "disabled"})
+	require.ErrorIs(t, err, ErrImpersonationTargetInactive)
+	require.Empty(t, audit.logs)
+}
+
+func TestImpersonationValidateAndEnd(t *testing.T) {
+	admin := &User{ID: 1, Email: "admin@example.com", Role: RoleAdmin, Status: StatusActive}
+	user := &User{ID: 2, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, authSvc, _, audit := newImpersonationTestService(admin, user)
+	ctx := context.Background()
+
+	result, err := svc.Start(ctx, admin.ID, user.ID, ImpersonationInput{Reason: "support", WriteAccess: true})
+	require.NoError(t, err)
+	claims, err := authSvc.ValidateToken(result.AccessToken)
+	require.NoError(t, err)
+
+	session, err := svc.Validate(ctx, claims.Impersonation, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, ImpersonationScopeWrite, session.Scope)
+	require.Equal(t, admin.Email, session.AdminEmail)
+
+	// Token 与会话的目标用户不一致视为无效
+	_, err = svc.Validate(ctx, claims.Impersonation, admin.ID)
+	require.ErrorIs(t, err, ErrImpersonationEnded)
+
+	svc.RecordRequest(ctx, session, http.MethodGet, "/api/v1/keys", http.StatusOK, "", "203.0.113.1")
+	require.NoError(t, svc.End(ctx, session, "203.0.113.1"))
+	_, err = svc.Validate(ctx, claims.Impersonation, user.ID)
+	require.ErrorIs(t, err, ErrImpersonationEnded)
+	require.ErrorIs(t, svc.End(ctx, nil, ""), ErrNotImpersonating)
+
+	events := make([]string, 0, len(audit.logs))
+	for _, l := range audit.logs {
+		require.Equal(t, session.ID, l.ImpersonationID)
+		events = append(events, l.Event)
+	}
+	require.Equal(t, []string{ImpersonationEventStart, ImpersonationEventAction, ImpersonationEventEnd}, events)
+	require.Equal(t, "/api/v1/keys", audit.logs[1].Path)
+}
+
+func TestCheckImpersonationAccess(t *testing.T) {
+	readOnly := &ImpersonationSession{Scope: ImpersonationScopeRead}
+	writable := &ImpersonationSession{Scope: ImpersonationScopeWrite}
+
+	cases := []struct {
+		session *ImpersonationSession
+		method  string
+		path    string
+		want    error
+	}{
+		{readOnly, http.MethodGet, "/api/v1/keys", nil},
+		{readOnly, http.MethodGet, "/api/v1/subscriptions/progress", nil},
+		{readOnly, http.MethodPost, "/api/v1/usage/dashboard/api-keys-usage", nil},
+		{readOnly, http.MethodPost, "/api/v1/auth/impersonation/end", nil},
+		{readOnly, http.MethodPut, "/api/v1/keys/:id", ErrImpersonationReadOnly},
+		{readOnly, http.MethodPost, "/api/v1/announcements/:id/read", ErrImpersonationReadOnly},
+		{writable, http.MethodPut, "/api/v1/keys/:id", nil},
+		{writable, http.MethodPut, "/api/v1/user/password", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/user/totp/setup", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/user/passkeys/:id/delete", ErrImpersonationActionBlocked},
+		{writable, http.MethodDelete, "/api/v1/user/sessions/:id", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/keys", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/keys/:id/rotate", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/redeem", ErrImpersonationActionBlocked},
+		{writable, http.MethodPost, "/api/v1/auth/revoke-all-sessions", ErrImpersonationActionBlocked},
+		// 只读接口不受敏感路由影响
+		{readOnly, http.MethodGet, "/api/v1/user/totp/status", nil},
+	}
+	for _, tc := range cases {
+		err := CheckImpersonationAccess(tc.session, tc.method, tc.path)
+		if tc.want == nil {
+			require.NoError(t, err, "%s %s (%s)", tc.method, tc.path, tc.session.Scope)
+		} else {
+			require.ErrorIs(t, err, tc.want, "%s %s (%s)", tc.method, tc.path, tc.session.Scope)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	KeySharingReviewPending   = "pending"
+	KeySharingReviewConfirmed = "confirmed"
+	KeySharingReviewDismissed = "dismissed"
+)
+
+// KeySharingSignals 单个 API Key 在某个窗口内的共享信号
+type KeySharingSignals struct {
+	APIKeyID           int64 `json:"api_key_id"`
+	UserID             int64 `json:"user_id"`
+	Requests           int64 `json:"requests"`
+	DistinctIPs        int   `json:"distinct_ips"`
+	DistinctNetworks   int   `json:"distinct_networks"`
+	DistinctUserAgents int   `json:"distinct_user_agents"`
+	// ConcurrentIPs 同一分钟内同时活跃的最大不同 IP 数
+	ConcurrentIPs int `json:"concurrent_ips"`
+}
+
+// KeySharingSourceCount 证据中的来源计数（IP 或 User-Agent）
+type KeySharingSourceCount struct {
+	Value    string `json:"value"`
+	Requests int64  `json:"requests"`
+}
+
+// KeySharingWindowEvidence 单个窗口的信号与得分
+type KeySharingWindowEvidence struct {
+	WindowMinutes int     `json:"window_minutes"`
+	Score         float64 `json:"score"`
+	KeySharingSignals
+}
+
+// KeySharingEvidence 审核证据
+type KeySharingEvidence struct {
+	Windows       []KeySharingWindowEvidence `json:"windows"`
+	TopIPs        []KeySharingSourceCount    `json:"top_ips,omitempty"`
+	TopUserAgents []KeySharingSourceCount    `json:"top_user_agents,omitempty"`
+}
+
+// KeySharingReview 审核队列中的一条记录
+type KeySharingReview struct {
+	ID         int64              `json:"id"`
+	APIKeyID   int64              `json:"api_key_id"`
+	UserID     int64              `json:"user_id"`
+	Score      float64            `json:"score"`
+	Evidence   KeySharingEvidence `json:"evidence"`
+	Status     string             `json:"status"`
+	Note       string             `json:"note"`
+	ReviewedBy *int64             `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"reviewed_at,omitempty"`
+	DetectedAt time.Time          `json:"detected_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// KeySharingRepository 共享检测的数据访问接口
+type KeySharingRepository interface {
+	// ListSignals 统计 since 之后请求数不少于 minRequests 的 Key 的共享信号
+	ListSignals(ctx context.Context, since time.Time, minRequests int64) ([]KeySharingSignals, error)
+	// ListTopSources 返回 since 之后某个 Key 请求最多的 IP 与 User-Agent
+	ListTopSources(ctx context.Context, apiKeyID int64, since time.Time, limit int) (ips, userAgents []KeySharingSourceCount, err error)
+	// UpsertPendingReview 写入待审核记录；已有待审核记录时更新分数与证据
+	UpsertPendingReview(ctx context.Context, review *KeySharingReview) error
+	// HasRecentDismissal 判断 since 之后是否有被忽略的审核记录
+	HasRecentDismissal(ctx context.Context, apiKeyID int64, since time.Time) (bool, error)
+	ListReviews(ctx context.Context, status string, params pagination.PaginationParams) ([]KeySharingReview, *pagination.PaginationResult, error)
+	GetReview(ctx context.Context, id int64) (*KeySharingReview, error)
+	// ResolveReview 将待审核记录置为 confirmed/dismissed；记录不存在或已处理时返回 false
+	ResolveReview(ctx context.Context, id int64, status, note string, reviewedBy int64, reviewedAt time.Time) (bool, error)
+}
+
+// KeySharingCache 分组级每 Key 每小时来源 IP 上限的计数缓存
+type KeySharingCache interface {
+	// TrackKeyIP 记录 Key 在 bucket 小时内的来源 IP；新 IP 会使不同 IP 数超过 limit 时返回 false 且不记录
+	TrackKeyIP(ctx context.Context, apiKeyID int64, ip string, bucket time.Time, limit int) (bool, error)
+}
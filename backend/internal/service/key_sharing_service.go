@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	keySharingWorkerName  = "key_sharing:detect"
+	keySharingLockKey     = "key_sharing:detect:leader"
+	keySharingRunTimeout  = 5 * time.Minute
+	keySharingIPLimitWait = 200 * time.Millisecond
+
+	// 各信号在总分（0-100）中的权重
+	keySharingWeightIPs         = 35.0
+	keySharingWeightNetworks    = 25.0
+	keySharingWeightUserAgents  = 15.0
+	keySharingWeightConcurrency = 25.0
+)
+
+var (
+	ErrAPIKeyIPLimitExceeded    = infraerrors.TooManyRequests("API_KEY_IP_LIMIT_EXCEEDED", "too many distinct client IPs for this api key, please try again later")
+	ErrKeySharingReviewNotFound = infraerrors.NotFound("KEY_SHARING_REVIEW_NOT_FOUND", "key sharing review not found")
+	ErrKeySharingReviewResolved = infraerrors.Conflict("KEY_SHARING_REVIEW_RESOLVED", "key sharing review has already been resolved")
+	ErrKeySharingInvalidAction  = infraerrors.BadRequest("KEY_SHARING_INVALID_ACTION", "action must be confirm or dismiss")
+)
+
+// KeySharingResolveInput 审核处理参数
+type KeySharingResolveInput struct {
+	// Action: confirm 确认共享 / dismiss 忽略
+	Action string
+	// Suspend: 确认时是否同时停用 Key
+	Suspend bool
+	Note    string
+}
+
+// KeySharingService API Key 共享/转售检测：
+// 按滑动窗口统计每个 Key 的不同 IP、网段、并发 IP 与客户端指纹并评分，可疑 Key 进入审核队列；
+// 同时负责分组级"每 Key 每小时不同 IP 上限"的实时校验。
+type KeySharingService struct {
+	repo          KeySharingRepository
+	cache         KeySharingCache
+	apiKeyService *APIKeyService
+	timingWheel   *TimingWheelService
+	db            *sql.DB
+	cfg           config.KeySharingConfig
+}
+
+// NewKeySharingService 创建共享检测服务
+func NewKeySharingService(
+	repo KeySharingRepository,
+	cache KeySharingCache,
+	apiKeyService *APIKeyService,
+	timingWheel *TimingWheelService,
+	db *sql.DB,
+	cfg *config.Config,
+) *KeySharingService {
+	var sharingCfg config.KeySharingConfig
+	if cfg != nil {
+		sharingCfg = cfg.KeySharing
+	}
+	return &KeySharingService{
+		repo:          repo,
+		cache:         cache,
+		apiKeyService: apiKeyService,
+		timingWheel:   timingWheel,
+		db:            db,
+		cfg:           sharingCfg,
+	}
+}
+
+// Start 启动定时检测
+func (s *KeySharingService) Start() {
+	if s == nil || !s.cfg.Enabled || s.repo == nil || s.timingWheel == nil {
+		return
+	}
+	interval := time.Duration(s.cfg.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	s.timingWheel.ScheduleRecurring(keySharingWorkerName, interval, s.runOnce)
+	logger.LegacyPrintf("service.key_sharing", "[KeySharing] started (interval=%s windows=%d threshold=%.1f)", interval, len(s.cfg.Windows), s.cfg.ScoreThreshold)
+}
+
+func (s *KeySharingService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), keySharingRunTimeout)
+	defer cancel()
+
+	if s.db != nil {
+		release, ok := tryAcquireDBAdvisoryLock(ctx, s.db, hashAdvisoryLockID(keySharingLockKey))
+		if !ok {
+			return
+		}
+		defer release()
+	}
+
+	reviews, err := s.Scan(ctx, time.Now().UTC())
+	if err != nil {
+		logger.LegacyPrintf("service.key_sharing", "[KeySharing] scan failed: %v", err)
+		return
+	}
+	if len(reviews) > 0 {
+		logger.LegacyPrintf("service.key_sharing", "[KeySharing] suspicious keys queued for review: count=%d", len(reviews))
+	}
+}
+
+// CheckIPLimit 校验分组级每 Key 每小时不同来源 IP 上限。
+// Redis 异常时放行（fail-open），避免缓存故障影响正常请求。
+func (s *KeySharingService) CheckIPLimit(ctx context.Context, apiKey *APIKey, clientIP string) error {
+	if s == nil || s.cache == nil || apiKey == nil || apiKey.Group == nil {
+		return nil
+	}
+	limit := apiKey.Group.MaxIPsPerKeyPerHour
+	clientIP = strings.TrimSpace(clientIP)
+	if limit <= 0 || clientIP == "" {
+		return nil
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, keySharingIPLimitWait)
+	defer cancel()
+	allowed, err := s.cache.TrackKeyIP(checkCtx, apiKey.ID, clientIP, time.Now().UTC().Truncate(time.Hour), limit)
+	if err != nil {
+		logger.LegacyPrintf("service.key_sharing", "[KeySharing] ip limit check failed (fail-open): key=%d err=%v", apiKey.ID, err)
+		return nil
+	}
+	if !allowed {
+		return ErrAPIKeyIPLimitExceeded
+	}
+	return nil
+}
+
+// Scan 按各滑动窗口评分，达到阈值的 Key 写入（或更新）待审核记录
+func (s *KeySharingService) Scan(ctx context.Context, now time.Time) ([]KeySharingReview, error) {
+	if s == nil || s.repo == nil || len(s.cfg.Windows) == 0 {
+		return nil, nil
+	}
+
+	evidence := make(map[int64]*KeySharingReview)
+	var longest time.Duration
+	for _, window := range s.cfg.Windows {
+		length := time.Duration(window.Minutes) * time.Minute
+		if length <= 0 {
+			continue
+		}
+		if length > longest {
+			longest = length
+		}
+		signals, err := s.repo.ListSignals(ctx, now.Add(-length), s.cfg.MinRequests)
+		if err != nil {
+			return nil, fmt.Errorf("list key sharing signals (%dm): %w", window.Minutes, err)
+		}
+		for _, sig := range signals {
+			score := keySharingScore(sig, window)
+			review := evidence[sig.APIKeyID]
+			if review == nil {
+				review = &KeySharingReview{APIKeyID: sig.APIKeyID, UserID: sig.UserID}
+				evidence[sig.APIKeyID] = review
+			}
+			review.Evidence.Windows = append(review.Evidence.Windows, KeySharingWindowEvidence{
+				WindowMinutes:     window.Minutes,
+				Score:             score,
+				KeySharingSignals: sig,
+			})
+			review.Score = math.Max(review.Score, score)
+		}
+	}
+
+	ids := make([]int64, 0, len(evidence))
+	for id, review := range evidence {
+		if review.Score >= s.cfg.ScoreThreshold {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	queued := make([]KeySharingReview, 0, len(ids))
+	for _, id := range ids {
+		review := evidence[id]
+		if s.cfg.DismissCooldownHours > 0 {
+			dismissed, err := s.repo.HasRecentDismissal(ctx, id, now.Add(-time.Duration(s.cfg.DismissCooldownHours)*time.Hour))
+			if err != nil {
+				logger.LegacyPrintf("service.key_sharing", "[KeySharing] dismissal check failed: key=%d err=%v", id, err)
+			} else if dismissed {
+				continue
+			}
+		}
+		if s.cfg.EvidenceTopN > 0 {
+			ips, uas, err := s.repo.ListTopSources(ctx, id, now.Add(-longest), s.cfg.EvidenceTopN)
+			if err != nil {
+				logger.LegacyPrintf("service.key_sharing", "[KeySharing] list top sources failed: key=%d err=%v", id, err)
+			} else {
+				review.Evidence.TopIPs = ips
+				review.Evidence.TopUserAgents = uas
+			}
+		}
+		review.Status = KeySharingReviewPending
+		review.DetectedAt = now
+		if err := s.repo.UpsertPendingReview(ctx, review); err != nil {
+			logger.LegacyPrintf("service.key_sharing", "[KeySharing] upsert review failed: key=%d err=%v", id, err)
+			continue
+		}
+		queued = append(queued, *review)
+	}
+	return queued, nil
+}
+
+// ListReviews 分页列出审核记录，status 为空时返回全部
+func (s *KeySharingService) ListReviews(ctx context.Context, status string, params pagination.PaginationParams) ([]KeySharingReview, *pagination.PaginationResult, error) {
+	return s.repo.ListReviews(ctx, status, params)
+}
+
+// GetReview 获取单条审核记录
+func (s *KeySharingService) GetReview(ctx context.Context, id int64) (*KeySharingReview, error) {
+	review, err := s.repo.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrKeySharingReviewNotFound
+	}
+	return review, nil
+}
+
+// ResolveReview 处理待审核记录；确认并选择停用时将 Key 置为 suspended
+func (s *KeySharingService) ResolveReview(ctx context.Context, id int64, input KeySharingResolveInput, adminID int64) (*KeySharingReview, error) {
+	var status string
+	switch strings.ToLower(strings.TrimSpace(input.Action)) {
+	case "confirm":
+		status = KeySharingReviewConfirmed
+	case "dismiss":
+		status = KeySharingReviewDismissed
+	default:
+		return nil, ErrKeySharingInvalidAction
+	}
+
+	review, err := s.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != KeySharingReviewPending {
+		return nil, ErrKeySharingReviewResolved
+	}
+
+	if status == KeySharingReviewConfirmed && input.Suspend && s.apiKeyService != nil {
+		if _, _, err := s.apiKeyService.Suspend(ctx, review.APIKeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := s.repo.ResolveReview(ctx, id, status, strings.TrimSpace(input.Note), adminID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrKeySharingReviewResolved
+	}
+	return s.GetReview(ctx, id)
+}
+
+// keySharingScore 计算单个窗口的可疑分数（0-100）：各信号按阈值线性计分并封顶
+func keySharingScore(sig KeySharingSignals, window config.KeySharingWindowConfig) float64 {
+	score := keySharingSignalScore(sig.DistinctIPs, window.DistinctIPs, keySharingWeightIPs) +
+		keySharingSignalScore(sig.DistinctNetworks, window.DistinctNetworks, keySharingWeightNetworks) +
+		keySharingSignalScore(sig.DistinctUserAgents, window.DistinctUserAgents, keySharingWeightUserAgents) +
+		keySharingSignalScore(sig.ConcurrentIPs, window.ConcurrentIPs, keySharingWeightConcurrency)
+	return math.Round(score*10) / 10
+}
+
+func keySharingSignalScore(value, threshold int, weight float64) float64 {
+	// 单个来源视为正常使用，不计分
+	if threshold <= 0 || value <= 1 {
+		return 0
+	}
+	return weight * math.Min(float64(value)/float64(threshold), 1)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type keySharingRepoStub struct {
+	KeySharingRepository
+	signals   map[time.Duration][]KeySharingSignals
+	now       time.Time
+	dismissed map[int64]bool
+	upserted  []KeySharingReview
+}
+
+func (r *keySharingRepoStub) ListSignals(ctx context.Context, since time.Time, minRequests int64) ([]KeySharingSignals, error) {
+	return r.signals[r.now.Sub(since)], nil
+}
+
+func (r *keySharingRepoStub) ListTopSources(ctx context.Context, apiKeyID int64, since time.Time, limit int) ([]KeySharingSourceCount, []KeySharingSourceCount, error) {
+	return []KeySharingSourceCount{{Value: "1.1.1.1", Requests: 9}}, []KeySharingSourceCount{{Value: "curl/8", Requests: 5}}, nil
+}
+
+func (r *keySharingRepoStub) HasRecentDismissal(ctx context.Context, apiKeyID int64, since time.Time) (bool, error) {
+	return r.dismissed[apiKeyID], nil
+}
+
+func (r *keySharingRepoStub) UpsertPendingReview(ctx context.Context, review *KeySharingReview) error {
+	r.upserted = append(r.upserted, *review)
+	return nil
+}
+
+type keySharingCacheStub struct {
+	err   error
+	sets  map[int64]map[string]bool
+	calls int
+}
+
+func (c *keySharingCacheStub) TrackKeyIP(ctx context.Context, apiKeyID int64, ip string, bucket time.Time, limit int) (bool, error) {
+	c.calls++
+	if c.err != nil {
+		return false, c.err
+	}
+	set := c.sets[apiKeyID]
+	if set == nil {
+		set = map[string]bool{}
+		c.sets[apiKeyID] = set
+	}
+	if set[ip] {
+		return true, nil
+	}
+	if len(set) >= limit {
+		return false, nil
+	}
+	set[ip] = true
+	return true, nil
+}
+
+func newKeySharingTestConfig() *config.Config {
+	return &config.Config{KeySharing: config.KeySharingConfig{
+		Enabled: true,
+		Windows: []config.KeySharingWindowConfig{
+			{Minutes: 60, DistinctIPs: 10, DistinctNetworks: 5, DistinctUserAgents: 4, ConcurrentIPs: 4},
+			{Minutes: 1440, DistinctIPs: 40, DistinctNetworks: 15, DistinctUserAgents: 8, ConcurrentIPs: 6},
+		},
+		ScoreThreshold:       70,
+		DismissCooldownHours: 24,
+		EvidenceTopN:         5,
+	}}
+}
+
+func TestKeySharingScore(t *testing.T) {
+	window := config.KeySharingWindowConfig{DistinctIPs: 10, DistinctNetworks: 5, DistinctUserAgents: 4, ConcurrentIPs: 4}
+
+	// 单一来源不计分
+	require.Zero(t, keySharingScore(KeySharingSignals{DistinctIPs: 1, DistinctNetworks: 1, DistinctUserAgents: 1, ConcurrentIPs: 1}, window))
+	// 各信号封顶后满分 100
+	require.InDelta(t, 100.0, keySharingScore(KeySharingSignals{DistinctIPs: 50, DistinctNetworks: 20, DistinctUserAgents: 9, ConcurrentIPs: 8}, window), 1e-9)
+	// 35*5/10 + 25*2/5 + 0 + 25*2/4 = 17.5 + 10 + 12.5
+	require.InDelta(t, 40.0, keySharingScore(KeySharingSignals{DistinctIPs: 5, DistinctNetworks: 2, DistinctUserAgents: 1, ConcurrentIPs: 2}, window), 1e-9)
+	// 阈值为 0 的信号不参与
+	require.Zero(t, keySharingScore(KeySharingSignals{DistinctIPs: 50}, config.KeySharingWindowConfig{}))
+}
+
+func TestKeySharingScanQueuesSuspiciousKeys(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	repo := &keySharingRepoStub{
+		now: now,
+		signals: map[time.Duration][]KeySharingSignals{
+			time.Hour: {
+				// 1 小时内大量不同 IP 与网段 → 可疑
+				{APIKeyID: 1, UserID: 10, Requests: 300, DistinctIPs: 25, DistinctNetworks: 9, DistinctUserAgents: 2, ConcurrentIPs: 6},
+				// 家庭/办公网络切换 → 正常
+				{APIKeyID: 2, UserID: 20, Requests: 80, DistinctIPs: 2, DistinctNetworks: 1, DistinctUserAgents: 2, ConcurrentIPs: 1},
+			},
+			24 * time.Hour: {
+				{APIKeyID: 1, UserID: 10, Requests: 2000, DistinctIPs: 90, DistinctNetworks: 30, DistinctUserAgents: 3, ConcurrentIPs: 8},
+				{APIKeyID: 2, UserID: 20, Requests: 900, DistinctIPs: 4, DistinctNetworks: 2, DistinctUserAgents: 2, ConcurrentIPs: 1},
+				// 已被忽略的 Key 在冷却期内不再入队
+				{APIKeyID: 3, UserID: 30, Requests: 900, DistinctIPs: 60, DistinctNetworks: 20, DistinctUserAgents: 9, ConcurrentIPs: 7},
+			},
+		},
+		dismissed: map[int64]bool{3: true},
+	}
+	svc := NewKeySharingService(repo, nil, nil, nil, nil, newKeySharingTestConfig())
+
+	queued, err := svc.Scan(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, queued, 1)
+	require.Len(t, repo.upserted, 1)
+
+	review := repo.upserted[0]
+	require.Equal(t, int64(1), review.APIKeyID)
+	require.Equal(t, int64(10), review.UserID)
+	require.Equal(t, KeySharingReviewPending, review.Status)
+	require.Equal(t, now, review.DetectedAt)
+	require.GreaterOrEqual(t, review.Score, 70.0)
+	require.Len(t, review.Evidence.Windows, 2)
+	require.Equal(t, 60, review.Evidence.Windows[0].WindowMinutes)
+	require.Equal(t, 25, review.Evidence.Windows[0].DistinctIPs)
+	require.Len(t, review.Evidence.TopIPs, 1)
+	require.Equal(t, "curl/8", review.Evidence.TopUserAgents[0].Value)
+}
+
+func TestKeySharingCheckIPLimit(t *testing.T) {
+	cache := &keySharingCacheStub{sets: map[int64]map[string]bool{}}
+	svc := NewKeySharingService(nil, cache, nil, nil, nil, &config.Config{})
+	apiKey := &APIKey{ID: 1, Group: &Group{ID: 9, MaxIPsPerKeyPerHour: 2}}
+	ctx := context.Background()
+
+	require.NoError(t, svc.CheckIPLimit(ctx, apiKey, "1.1.1.1"))
+	require.NoError(t, svc.CheckIPLimit(ctx, apiKey, "2.2.2.2"))
+	require.NoError(t, svc.CheckIPLimit(ctx, apiKey, "1.1.1.1"))
+	require.ErrorIs(t, svc.CheckIPLimit(ctx, apiKey, "3.3.3.3"), ErrAPIKeyIPLimitExceeded)
+
+	// 未设置上限或无分组时不访问缓存
+	calls := cache.calls
+	require.NoError(t, svc.CheckIPLimit(ctx, &APIKey{ID: 2, Group: &Group{ID: 9}}, "4.4.4.4"))
+	require.NoError(t, svc.CheckIPLimit(ctx, &APIKey{ID: 3}, "4.4.4.4"))
+	require.Equal(t, calls, cache.calls)
+
+	// Redis 异常时放行
+	cache.err = errors.New("redis down")
+	require.NoError(t, svc.CheckIPLimit(ctx, apiKey, "5.5.5.5"))
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/util/logredact"
+)
+
+const (
+	defaultLogSinkQueueSize     = 10000
+	defaultLogSinkBatchSize     = 200
+	defaultLogSinkFlushInterval = time.Second
+	defaultLogSinkTimeout       = 10 * time.Second
+)
+
+// LogSinkExporter 外部日志平台的投递实现（syslog / Loki / HTTP NDJSON）。
+// Export 在 sink 的后台协程中串行调用，实现无需并发安全。
+type LogSinkExporter interface {
+	Type() string
+	Export(ctx context.Context, events []*logger.LogEvent) error
+	Close() error
+}
+
+// LogSinkHealth 外部 sink 健康状态，计数口径与 OpsSystemLogSinkHealth 一致
+type LogSinkHealth struct {
+	Type string `json:"type"`
+	OpsSystemLogSinkHealth
+}
+
+// AsyncLogSink 带有界队列的异步批量 sink：队列满时直接丢弃并计数，不阻塞业务日志。
+type AsyncLogSink struct {
+	exporter LogSinkExporter
+
+	minLevel     int
+	accessEvents bool
+
+	queue         chan *logger.LogEvent
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	droppedCount uint64
+	writeFailed  uint64
+	writtenCount uint64
+	totalDelayNs uint64
+
+	lastError atomic.Value
+}
+
+// NewAsyncLogSink 创建异步 sink
+func NewAsyncLogSink(exporter LogSinkExporter, filter config.LogSinkFilterConfig, cfg config.LogSinksConfig) *AsyncLogSink {
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultLogSinkQueueSize
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultLogSinkBatchSize
+	}
+	flushInterval := time.Duration(cfg.FlushIntervalMs) * time.Millisecond
+	if flushInterval <= 0 {
+		flushInterval = defaultLogSinkFlushInterval
+	}
+	timeout := logSinkTimeout(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &AsyncLogSink{
+		exporter:      exporter,
+		minLevel:      logSinkLevelRank(filter.MinLevel),
+		accessEvents:  filter.AccessEvents,
+		queue:         make(chan *logger.LogEvent, queueSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		timeout:       timeout,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	s.lastError.Store("")
+	return s
+}
+
+func (s *AsyncLogSink) Start() {
+	if s == nil || s.exporter == nil {
+		return
+	}
+	s.wg.Add(1)
+	go s.run()
+}
+
+func (s *AsyncLogSink) Stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	if s.exporter != nil {
+		_ = s.exporter.Close()
+	}
+}
+
+// AccessEventsEnabled 是否投递访问事件
+func (s *AsyncLogSink) AccessEventsEnabled() bool {
+	return s != nil && s.accessEvents
+}
+
+func (s *AsyncLogSink) WriteLogEvent(event *logger.LogEvent) {
+	if s == nil || event == nil || !s.accepts(event) {
+		return
+	}
+	select {
+	case <-s.ctx.Done():
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		atomic.AddUint64(&s.droppedCount, 1)
+	}
+}
+
+func (s *AsyncLogSink) accepts(event *logger.LogEvent) bool {
+	if logger.IsAccessEvent(event) {
+		return s.accessEvents
+	}
+	return logSinkLevelRank(event.Level) >= s.minLevel
+}
+
+func (s *AsyncLogSink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*logger.LogEvent, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		started := time.Now()
+		err := s.exporter.Export(ctx, batch)
+		delay := time.Since(started)
+		cancel()
+		if err != nil {
+			atomic.AddUint64(&s.writeFailed, uint64(len(batch)))
+			s.lastError.Store(err.Error())
+			// 不能写回 logger，否则失败日志会再次进入 sink 形成循环
+			_, _ = fmt.Fprintf(os.Stderr, "time=%s level=WARN msg=\"log sink export failed\" sink=%s err=%v batch=%d\n",
+				time.Now().Format(time.RFC3339Nano), s.exporter.Type(), err, len(batch),
+			)
+		} else {
+			atomic.AddUint64(&s.writtenCount, uint64(len(batch)))
+			atomic.AddUint64(&s.totalDelayNs, uint64(delay.Nanoseconds()))
+			s.lastError.Store("")
+		}
+		batch = batch[:0]
+	}
+	drainAndFlush := func() {
+		for {
+			select {
+			case item := <-s.queue:
+				if item == nil {
+					continue
+				}
+				batch = append(batch, item)
+				if len(batch) >= s.batchSize {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			drainAndFlush()
+			return
+		case item := <-s.queue:
+			if item == nil {
+				continue
+			}
+			batch = append(batch, item)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *AsyncLogSink) Health() LogSinkHealth {
+	if s == nil {
+		return LogSinkHealth{}
+	}
+	written := atomic.LoadUint64(&s.writtenCount)
+	totalDelay := atomic.LoadUint64(&s.totalDelayNs)
+	var avgDelay uint64
+	if written > 0 {
+		avgDelay = (totalDelay / written) / uint64(time.Millisecond)
+	}
+	lastErr, _ := s.lastError.Load().(string)
+	var sinkType string
+	if s.exporter != nil {
+		sinkType = s.exporter.Type()
+	}
+	return LogSinkHealth{
+		Type: sinkType,
+		OpsSystemLogSinkHealth: OpsSystemLogSinkHealth{
+			QueueDepth:      int64(len(s.queue)),
+			QueueCapacity:   int64(cap(s.queue)),
+			DroppedCount:    atomic.LoadUint64(&s.droppedCount),
+			WriteFailed:     atomic.LoadUint64(&s.writeFailed),
+			WrittenCount:    written,
+			AvgWriteDelayMs: avgDelay,
+			LastError:       strings.TrimSpace(lastErr),
+		},
+	}
+}
+
+// LogSinkManager 管理所有已启用的外部 sink
+type LogSinkManager struct {
+	sinks []*AsyncLogSink
+}
+
+// NewLogSinkManager 按配置创建已启用的外部 sink（配置已在启动时校验）
+func NewLogSinkManager(cfg *config.Config) *LogSinkManager {
+	m := &LogSinkManager{}
+	if cfg == nil {
+		return m
+	}
+	sinksCfg := cfg.Log.Sinks
+	base := logSinkBase{service: cfg.Log.ServiceName, env: cfg.Log.Environment}
+	if sinksCfg.Syslog.Enabled {
+		m.add(NewSyslogLogExporter(sinksCfg.Syslog, base), sinksCfg.Syslog.LogSinkFilterConfig, sinksCfg)
+	}
+	if sinksCfg.Loki.Enabled {
+		m.add(NewLokiLogExporter(sinksCfg.Loki, base, sinksCfg), sinksCfg.Loki.LogSinkFilterConfig, sinksCfg)
+	}
+	if sinksCfg.HTTP.Enabled {
+		m.add(NewHTTPNDJSONLogExporter(sinksCfg.HTTP, base, sinksCfg), sinksCfg.HTTP.LogSinkFilterConfig, sinksCfg)
+	}
+	return m
+}
+
+func (m *LogSinkManager) add(exporter LogSinkExporter, filter config.LogSinkFilterConfig, cfg config.LogSinksConfig) {
+	m.sinks = append(m.sinks, NewAsyncLogSink(exporter, filter, cfg))
+}
+
+// Start 启动所有 sink，并按需开启访问事件输出
+func (m *LogSinkManager) Start() {
+	if m == nil {
+		return
+	}
+	accessEvents := false
+	for _, sink := range m.sinks {
+		sink.Start()
+		if sink.AccessEventsEnabled() {
+			accessEvents = true
+		}
+	}
+	logger.SetAccessEventsEnabled(accessEvents)
+}
+
+func (m *LogSinkManager) Stop() {
+	if m == nil {
+		return
+	}
+	logger.SetAccessEventsEnabled(false)
+	for _, sink := range m.sinks {
+		sink.Stop()
+	}
+}
+
+func (m *LogSinkManager) WriteLogEvent(event *logger.LogEvent) {
+	if m == nil {
+		return
+	}
+	for _, sink := range m.sinks {
+		sink.WriteLogEvent(event)
+	}
+}
+
+func (m *LogSinkManager) Health() []LogSinkHealth {
+	if m == nil || len(m.sinks) == 0 {
+		return nil
+	}
+	out := make([]LogSinkHealth, 0, len(m.sinks))
+	for _, sink := range m.sinks {
+		out = append(out, sink.Health())
+	}
+	return out
+}
+
+// Empty 是否没有任何外部 sink
+func (m *LogSinkManager) Empty() bool {
+	return m == nil || len(m.sinks) == 0
+}
+
+// logSinkFanout 将日志事件同时写入多个 sink
+type logSinkFanout []logger.Sink
+
+func (f logSinkFanout) WriteLogEvent(event *logger.LogEvent) {
+	for _, sink := range f {
+		sink.WriteLogEvent(event)
+	}
+}
+
+type logSinkBase struct {
+	service string
+	env     string
+}
+
+// record 将事件编码为外部平台使用的统一 JSON 结构。
+// 访问事件直接输出稳定字段集合；普通日志脱敏后将附加字段放在 fields 下。
+func (b logSinkBase) record(event *logger.LogEvent) map[string]any {
+	ts := event.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	if logger.IsAccessEvent(event) {
+		out := make(map[string]any, len(event.Fields)+3)
+		for k, v := range event.Fields {
+			out[k] = v
+		}
+		out["time"] = ts.UTC().Format(time.RFC3339Nano)
+		out["service"] = b.service
+		out["env"] = b.env
+		return out
+	}
+
+	fields := copyMap(event.Fields)
+	component := strings.TrimSpace(event.Component)
+	if fieldComponent := asString(fields["component"]); fieldComponent != "" {
+		component = fieldComponent
+	}
+	delete(fields, "component")
+	if component == "" {
+		component = "app"
+	}
+	return map[string]any{
+		"time":      ts.UTC().Format(time.RFC3339Nano),
+		"level":     strings.ToLower(strings.TrimSpace(event.Level)),
+		"service":   b.service,
+		"env":       b.env,
+		"component": component,
+		"message":   logredact.RedactText(strings.TrimSpace(event.Message)),
+		"fields":    logredact.RedactMap(fields),
+	}
+}
+
+// logSinkLevelRank 日志级别排序，未知或空级别视为 info
+func logSinkLevelRank(level string) int {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return 0
+	case "warn", "warning":
+		return 2
+	case "error":
+		return 3
+	case "dpanic", "panic", "fatal":
+		return 4
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// HTTPNDJSONLogExporter 以 NDJSON（每行一条 JSON）批量 POST 到任意 HTTP 端点
+type HTTPNDJSONLogExporter struct {
+	url         string
+	bearerToken string
+	headers     map[string]string
+	gzip        bool
+	base        logSinkBase
+	client      *http.Client
+}
+
+// NewHTTPNDJSONLogExporter 创建 HTTP NDJSON 投递器
+func NewHTTPNDJSONLogExporter(cfg config.LogHTTPSinkConfig, base logSinkBase, sinksCfg config.LogSinksConfig) *HTTPNDJSONLogExporter {
+	client, err := httpclient.GetClient(httpclient.Options{Timeout: logSinkTimeout(sinksCfg)})
+	if err != nil {
+		client = &http.Client{Timeout: logSinkTimeout(sinksCfg)}
+	}
+	return &HTTPNDJSONLogExporter{
+		url:         strings.TrimSpace(cfg.URL),
+		bearerToken: strings.TrimSpace(cfg.BearerToken),
+		headers:     cfg.Headers,
+		gzip:        cfg.Gzip,
+		base:        base,
+		client:      client,
+	}
+}
+
+func (e *HTTPNDJSONLogExporter) Type() string { return "http" }
+
+func (e *HTTPNDJSONLogExporter) Export(ctx context.Context, events []*logger.LogEvent) error {
+	body, err := e.encode(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if e.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if e.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+e.bearerToken)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http sink post: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("http sink post: status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *HTTPNDJSONLogExporter) Close() error { return nil }
+
+func (e *HTTPNDJSONLogExporter) encode(events []*logger.LogEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if e.gzip {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, event := range events {
+		// Encode 自带换行，恰好构成 NDJSON
+		if err := enc.Encode(e.base.record(event)); err != nil {
+			continue
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, fmt.Errorf("gzip ndjson: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+// LokiLogExporter 通过 Loki push API 投递，按 service/env/level/component 及静态标签分流
+type LokiLogExporter struct {
+	url      string
+	tenantID string
+	username string
+	password string
+	labels   map[string]string
+	base     logSinkBase
+	client   *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// NewLokiLogExporter 创建 Loki 投递器
+func NewLokiLogExporter(cfg config.LogLokiSinkConfig, base logSinkBase, sinksCfg config.LogSinksConfig) *LokiLogExporter {
+	pushURL := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if !strings.HasSuffix(pushURL, lokiPushPath) {
+		pushURL += lokiPushPath
+	}
+	client, err := httpclient.GetClient(httpclient.Options{Timeout: logSinkTimeout(sinksCfg)})
+	if err != nil {
+		client = &http.Client{Timeout: logSinkTimeout(sinksCfg)}
+	}
+	return &LokiLogExporter{
+		url:      pushURL,
+		tenantID: strings.TrimSpace(cfg.TenantID),
+		username: cfg.Username,
+		password: cfg.Password,
+		labels:   cfg.Labels,
+		base:     base,
+		client:   client,
+	}
+}
+
+func (e *LokiLogExporter) Type() string { return "loki" }
+
+func (e *LokiLogExporter) Export(ctx context.Context, events []*logger.LogEvent) error {
+	body, err := json.Marshal(e.buildPush(events))
+	if err != nil {
+		return fmt.Errorf("encode loki push: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", e.tenantID)
+	}
+	if e.username != "" || e.password != "" {
+		req.SetBasicAuth(e.username, e.password)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("loki push: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("loki push: status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *LokiLogExporter) Close() error { return nil }
+
+func (e *LokiLogExporter) buildPush(events []*logger.LogEvent) *lokiPushRequest {
+	streams := make(map[string]*lokiStream)
+	order := make([]string, 0)
+	for _, event := range events {
+		record := e.base.record(event)
+		line, err := json.Marshal(record)
+		if err != nil {
+			continue
+		}
+		labels := e.streamLabels(event, record)
+		key := lokiLabelKey(labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		ts := event.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(line)})
+	}
+	out := &lokiPushRequest{Streams: make([]*lokiStream, 0, len(order))}
+	for _, key := range order {
+		out.Streams = append(out.Streams, streams[key])
+	}
+	return out
+}
+
+func (e *LokiLogExporter) streamLabels(event *logger.LogEvent, record map[string]any) map[string]string {
+	labels := make(map[string]string, len(e.labels)+4)
+	for k, v := range e.labels {
+		labels[k] = v
+	}
+	labels["service"] = e.base.service
+	labels["env"] = e.base.env
+	labels["component"], _ = record["component"].(string)
+	level := strings.ToLower(strings.TrimSpace(event.Level))
+	if level == "" {
+		level = "info"
+	}
+	labels["level"] = level
+	return labels
+}
+
+func lokiLabelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+func logSinkTimeout(cfg config.LogSinksConfig) time.Duration {
+	if cfg.TimeoutSeconds <= 0 {
+		return defaultLogSinkTimeout
+	}
+	return time.Duration(cfg.TimeoutSeconds) * time.Second
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const syslogTimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogLogExporter 以 RFC5424 格式投递到 syslog；MSG 部分为 JSON 记录。
+// udp 每条消息一个数据报；tcp/tls 使用 RFC6587 octet-counting 分帧。
+type SyslogLogExporter struct {
+	network            string
+	address            string
+	insecureSkipVerify bool
+	facility           int
+	hostname           string
+	appName            string
+	procID             string
+	base               logSinkBase
+
+	conn net.Conn
+}
+
+// NewSyslogLogExporter 创建 syslog 投递器（连接在首次投递时建立）
+func NewSyslogLogExporter(cfg config.LogSyslogSinkConfig, base logSinkBase) *SyslogLogExporter {
+	facility, ok := syslogFacilities[strings.ToLower(strings.TrimSpace(cfg.Facility))]
+	if !ok {
+		facility = syslogFacilities["local0"]
+	}
+	hostname := strings.TrimSpace(cfg.Hostname)
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	appName := strings.TrimSpace(cfg.AppName)
+	if appName == "" {
+		appName = base.service
+	}
+	return &SyslogLogExporter{
+		network:            strings.ToLower(strings.TrimSpace(cfg.Network)),
+		address:            strings.TrimSpace(cfg.Address),
+		insecureSkipVerify: cfg.InsecureSkipVerify,
+		facility:           facility,
+		hostname:           syslogHeaderField(hostname, 255),
+		appName:            syslogHeaderField(appName, 48),
+		procID:             strconv.Itoa(os.Getpid()),
+		base:               base,
+	}
+}
+
+func (e *SyslogLogExporter) Type() string { return "syslog" }
+
+func (e *SyslogLogExporter) Export(ctx context.Context, events []*logger.LogEvent) error {
+	if err := e.ensureConn(ctx); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = e.conn.SetWriteDeadline(deadline)
+	}
+
+	var buf bytes.Buffer
+	for _, event := range events {
+		msg, err := e.format(event)
+		if err != nil {
+			continue
+		}
+		if e.network == "udp" {
+			if _, err := e.conn.Write(msg); err != nil {
+				e.resetConn()
+				return fmt.Errorf("syslog write: %w", err)
+			}
+			continue
+		}
+		buf.WriteString(strconv.Itoa(len(msg)))
+		buf.WriteByte(' ')
+		buf.Write(msg)
+	}
+	if buf.Len() > 0 {
+		if _, err := e.conn.Write(buf.Bytes()); err != nil {
+			e.resetConn()
+			return fmt.Errorf("syslog write: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *SyslogLogExporter) Close() error {
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+func (e *SyslogLogExporter) ensureConn(ctx context.Context) error {
+	if e.conn != nil {
+		return nil
+	}
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch e.network {
+	case "tls":
+		dialer := &tls.Dialer{Config: &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: e.insecureSkipVerify, //nolint:gosec // 由配置显式开启，仅用于测试环境
+		}}
+		conn, err = dialer.DialContext(ctx, "tcp", e.address)
+	default:
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, e.network, e.address)
+	}
+	if err != nil {
+		return fmt.Errorf("syslog dial %s %s: %w", e.network, e.address, err)
+	}
+	e.conn = conn
+	return nil
+}
+
+func (e *SyslogLogExporter) resetConn() {
+	_ = e.Close()
+}
+
+// format 生成 RFC5424 消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID - MSG
+func (e *SyslogLogExporter) format(event *logger.LogEvent) ([]byte, error) {
+	payload, err := json.Marshal(e.base.record(event))
+	if err != nil {
+		return nil, err
+	}
+	ts := event.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	pri := e.facility*8 + syslogSeverity(event.Level)
+	msgID := syslogHeaderField(event.Component, 32)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s - ", pri, ts.UTC().Format(syslogTimestampLayout), e.hostname, e.appName, e.procID, msgID)
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+func syslogSeverity(level string) int {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return 7
+	case "warn", "warning":
+		return 4
+	case "error":
+		return 3
+	case "dpanic", "panic", "fatal":
+		return 2
+	default:
+		return 6
+	}
+}
+
+// syslogHeaderField 头部字段仅允许可打印 ASCII（33-126），空值使用 NILVALUE "-"
+func syslogHeaderField(value string, maxLen int) string {
+	var b strings.Builder
+	for i := 0; i < len(value) && b.Len() < maxLen; i++ {
+		c := value[i]
+		if c >= 33 && c <= 126 {
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
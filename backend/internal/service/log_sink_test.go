@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type captureLogExporter struct {
+	batches [][]*logger.LogEvent
+}
+
+func (e *captureLogExporter) Type() string { return "capture" }
+
+func (e *captureLogExporter) Export(ctx context.Context, events []*logger.LogEvent) error {
+	e.batches = append(e.batches, append([]*logger.LogEvent(nil), events...))
+	return nil
+}
+
+func (e *captureLogExporter) Close() error { return nil }
+
+func TestAsyncLogSinkFiltersAndDropAccounting(t *testing.T) {
+	sink := NewAsyncLogSink(&captureLogExporter{}, config.LogSinkFilterConfig{MinLevel: "warn"}, config.LogSinksConfig{QueueSize: 2})
+
+	sink.WriteLogEvent(&logger.LogEvent{Level: "info", Message: "skipped"})
+	sink.WriteLogEvent(&logger.LogEvent{Level: "warn", Message: "a"})
+	sink.WriteLogEvent(&logger.LogEvent{Level: "error", Message: "b"})
+	sink.WriteLogEvent(&logger.LogEvent{Level: "error", Message: "c"})
+	// 未开启访问事件时忽略
+	sink.WriteLogEvent(&logger.LogEvent{Level: "info", Component: logger.AccessEventComponent})
+
+	health := sink.Health()
+	require.Equal(t, "capture", health.Type)
+	require.Equal(t, int64(2), health.QueueDepth)
+	require.Equal(t, int64(2), health.QueueCapacity)
+	require.Equal(t, uint64(1), health.DroppedCount)
+}
+
+func TestAsyncLogSinkFlushesOnStop(t *testing.T) {
+	exporter := &captureLogExporter{}
+	sink := NewAsyncLogSink(exporter, config.LogSinkFilterConfig{AccessEvents: true}, config.LogSinksConfig{FlushIntervalMs: 60000})
+	sink.Start()
+	sink.WriteLogEvent(&logger.LogEvent{Level: "info", Message: "hello"})
+	sink.WriteLogEvent(&logger.LogEvent{Level: "info", Component: logger.AccessEventComponent})
+	sink.Stop()
+
+	require.Len(t, exporter.batches, 1)
+	require.Len(t, exporter.batches[0], 2)
+	require.Equal(t, uint64(2), sink.Health().WrittenCount)
+}
+
+func TestSyslogLogExporterFormatRFC5424(t *testing.T) {
+	exporter := NewSyslogLogExporter(config.LogSyslogSinkConfig{
+		Network:  "udp",
+		Facility: "local3",
+		AppName:  "sub2api gateway",
+		Hostname: "host-1",
+	}, logSinkBase{service: "sub2api", env: "test"})
+	exporter.procID = "42"
+
+	msg, err := exporter.format(&logger.LogEvent{
+		Time:      time.Date(2026, 10, 18, 8, 30, 1, 123456789, time.UTC),
+		Level:     "warn",
+		Component: "service.gateway",
+		Message:   "upstream slow",
+		Fields:    map[string]any{"account_id": 7},
+	})
+	require.NoError(t, err)
+
+	line := string(msg)
+	// local3(19)*8 + warning(4) = 156；APP-NAME 中的空格被移除
+	prefix := "<156>1 2026-10-18T08:30:01.123456Z host-1 sub2apigateway 42 service.gateway - "
+	require.True(t, strings.HasPrefix(line, prefix), line)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, prefix)), &record))
+	require.Equal(t, "upstream slow", record["message"])
+	require.Equal(t, "warn", record["level"])
+	require.Equal(t, "service.gateway", record["component"])
+}
+
+func TestLokiLogExporterGroupsStreams(t *testing.T) {
+	var got lokiPushRequest
+	var tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, lokiPushPath, r.URL.Path)
+		tenant = r.Header.Get("X-Scope-OrgID")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exporter := NewLokiLogExporter(config.LogLokiSinkConfig{
+		URL:      server.URL,
+		TenantID: "team-a",
+		Labels:   map[string]string{"cluster": "c1"},
+	}, logSinkBase{service: "sub2api", env: "test"}, config.LogSinksConfig{})
+
+	access := &logger.AccessEvent{Method: "POST", Path: "/v1/messages", StatusCode: 200}
+	events := []*logger.LogEvent{
+		{Level: "error", Component: "service.gateway", Message: "e1"},
+		{Level: "info", Component: logger.AccessEventComponent, Fields: access.Fields()},
+		{Level: "error", Component: "service.gateway", Message: "e2"},
+	}
+	require.NoError(t, exporter.Export(context.Background(), events))
+
+	require.Equal(t, "team-a", tenant)
+	require.Len(t, got.Streams, 2)
+	require.Equal(t, "c1", got.Streams[0].Stream["cluster"])
+	require.Equal(t, "error", got.Streams[0].Stream["level"])
+	require.Len(t, got.Streams[0].Values, 2)
+	require.Equal(t, logger.AccessEventComponent, got.Streams[1].Stream["component"])
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(got.Streams[1].Values[0][1]), &record))
+	require.Equal(t, logger.AccessEventSchema, record["schema"])
+	require.Equal(t, "/v1/messages", record["path"])
+}
+
+func TestHTTPNDJSONLogExporterPostsLines(t *testing.T) {
+	var lines []string
+	var auth, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	exporter := NewHTTPNDJSONLogExporter(config.LogHTTPSinkConfig{
+		URL:         server.URL,
+		BearerToken: "tok",
+	}, logSinkBase{service: "sub2api", env: "test"}, config.LogSinksConfig{})
+
+	require.NoError(t, exporter.Export(context.Background(), []*logger.LogEvent{
+		{Level: "info", Message: "one"},
+		{Level: "warn", Message: "two", Fields: map[string]any{"component": "audit"}},
+	}))
+	require.Equal(t, "Bearer tok", auth)
+	require.Equal(t, "application/x-ndjson", contentType)
+	require.Len(t, lines, 2)
+
+	var second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	require.Equal(t, "audit", second["component"])
+	require.Equal(t, "two", second["message"])
+}
+
+func TestHTTPNDJSONLogExporterReportsStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	exporter := NewHTTPNDJSONLogExporter(config.LogHTTPSinkConfig{URL: server.URL}, logSinkBase{}, config.LogSinksConfig{})
+	err := exporter.Export(context.Background(), []*logger.LogEvent{{Level: "info", Message: "x"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "502")
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/gin-gonic/gin"
+)
+
+type ModelFallbackChain = domain.ModelFallbackChain
+type ModelFallbackStep = domain.ModelFallbackStep
+
+const (
+	ModelFallbackTriggerNoAccount      = domain.ModelFallbackTriggerNoAccount
+	ModelFallbackTriggerRateLimited    = domain.ModelFallbackTriggerRateLimited
+	ModelFallbackTriggerOverloaded     = domain.ModelFallbackTriggerOverloaded
+	ModelFallbackTriggerContextTooLong = domain.ModelFallbackTriggerContextTooLong
+)
+
+// 模型降级响应头：发生降级时告知客户端原始请求模型与触发原因
+const (
+	ModelFallbackFromHeader   = "X-Model-Fallback-From"
+	ModelFallbackReasonHeader = "X-Model-Fallback-Reason"
+)
+
+func NormalizeModelFallbackChains(chains []ModelFallbackChain) ([]ModelFallbackChain, error) {
+	return domain.NormalizeModelFallbackChains(chains)
+}
+
+// validateGroupModelFallbackChains 降级链仅接入 Messages 接口，拒绝在 OpenAI / Gemini 分组上配置
+func validateGroupModelFallbackChains(platform string, chains []ModelFallbackChain) error {
+	if len(chains) > 0 && !domain.ModelFallbackSupportsGroupPlatform(platform) {
+		return fmt.Errorf("%w: model fallback chains only apply to anthropic and antigravity groups", domain.ErrModelFallbackInvalid)
+	}
+	return nil
+}
+
+// ModelFallbackPlan 单个请求的模型降级进度。
+// 降级链按请求的原始模型匹配一次，之后每次触发依次推进到下一步，直到链耗尽。
+type ModelFallbackPlan struct {
+	OriginalModel string
+	// BaseForcePlatform 降级前的强制平台（空表示按分组平台调度），每个降级步骤都从该值重新设置
+	BaseForcePlatform string
+	chain             *ModelFallbackChain
+	next              int
+}
+
+// NewModelFallbackPlan 为请求模型匹配分组降级链，无匹配时返回 nil
+func NewModelFallbackPlan(group *Group, model string) *ModelFallbackPlan {
+	if group == nil || len(group.ModelFallbackChains) == 0 {
+		return nil
+	}
+	chain := domain.FindModelFallbackChain(group.ModelFallbackChains, model)
+	if chain == nil {
+		return nil
+	}
+	return &ModelFallbackPlan{OriginalModel: model, chain: chain}
+}
+
+// CanFallback 判断触发条件下是否还有可用的降级步骤（不推进进度）
+func (p *ModelFallbackPlan) CanFallback(trigger string) bool {
+	if p == nil || trigger == "" || !p.chain.TriggeredBy(trigger) {
+		return false
+	}
+	return p.next < len(p.chain.Fallbacks)
+}
+
+// Next 按触发条件推进到下一个降级步骤
+func (p *ModelFallbackPlan) Next(trigger string) (ModelFallbackStep, bool) {
+	if !p.CanFallback(trigger) {
+		return ModelFallbackStep{}, false
+	}
+	step := p.chain.Fallbacks[p.next]
+	p.next++
+	return step, true
+}
+
+// ModelFallbackTriggerForStatus 将上游状态码映射为降级触发条件，不可降级时返回空串
+func ModelFallbackTriggerForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ModelFallbackTriggerRateLimited
+	case 529, http.StatusServiceUnavailable:
+		return ModelFallbackTriggerOverloaded
+	default:
+		return ""
+	}
+}
+
+// ReplaceModelInBody 替换请求体中的 model 字段（供模型降级重写请求使用）
+func (s *GatewayService) ReplaceModelInBody(body []byte, newModel string) []byte {
+	return s.replaceModelInBody(body, newModel)
+}
+
+// WithModelFallbackOnPromptTooLong 标记请求可由 context_too_long 触发模型降级：
+// Anthropic 账号返回 prompt too long 时以 *PromptTooLongError 交给 handler，而不是直接写回客户端
+func WithModelFallbackOnPromptTooLong(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxkey.ModelFallbackOnPromptTooLong, true)
+}
+
+// promptTooLongForModelFallback 请求已标记 context_too_long 降级且上游 400 为上下文超限时返回 *PromptTooLongError；
+// 其余情况返回 nil，resp.Body 保持可读
+func (s *GatewayService) promptTooLongForModelFallback(ctx context.Context, c *gin.Context, account *Account, resp *http.Response) *PromptTooLongError {
+	if resp.StatusCode != http.StatusBadRequest {
+		return nil
+	}
+	if enabled, _ := ctx.Value(ctxkey.ModelFallbackOnPromptTooLong).(bool); !enabled {
+		return nil
+	}
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil
+	}
+	upstreamMsg := strings.TrimSpace(extractUpstreamErrorMessage(respBody))
+	if !isContextTooLongMessage(strings.ToLower(upstreamMsg)) {
+		return nil
+	}
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+		Platform:           account.Platform,
+		AccountID:          account.ID,
+		AccountName:        account.Name,
+		UpstreamStatusCode: resp.StatusCode,
+		UpstreamRequestID:  resp.Header.Get("x-request-id"),
+		Kind:               "prompt_too_long",
+		Message:            sanitizeUpstreamErrorMessage(upstreamMsg),
+	})
+	return &PromptTooLongError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("x-request-id"),
+		Body:       respBody,
+	}
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelFallbackPlan_Next(t *testing.T) {
+	group := &Group{ModelFallbackChains: []ModelFallbackChain{{
+		Source: "claude-opus-4*",
+		Fallbacks: []ModelFallbackStep{
+			{Model: "claude-sonnet-4"},
+			{Model: "gemini-2.5-pro", Platform: PlatformAntigravity},
+		},
+		Triggers: []string{ModelFallbackTriggerRateLimited, ModelFallbackTriggerOverloaded},
+	}}}
+
+	require.Nil(t, NewModelFallbackPlan(nil, "claude-opus-4"))
+	require.Nil(t, NewModelFallbackPlan(group, "claude-haiku-4"))
+
+	plan := NewModelFallbackPlan(group, "claude-opus-4-1")
+	require.NotNil(t, plan)
+	require.Equal(t, "claude-opus-4-1", plan.OriginalModel)
+
+	_, ok := plan.Next(ModelFallbackTriggerContextTooLong)
+	require.False(t, ok, "未启用的触发条件不降级")
+
+	step, ok := plan.Next(ModelFallbackTriggerRateLimited)
+	require.True(t, ok)
+	require.Equal(t, "claude-sonnet-4", step.Model)
+
+	step, ok = plan.Next(ModelFallbackTriggerOverloaded)
+	require.True(t, ok)
+	require.Equal(t, "gemini-2.5-pro", step.Model)
+	require.Equal(t, PlatformAntigravity, step.Platform)
+
+	_, ok = plan.Next(ModelFallbackTriggerRateLimited)
+	require.False(t, ok, "降级链耗尽")
+
+	var nilPlan *ModelFallbackPlan
+	_, ok = nilPlan.Next(ModelFallbackTriggerRateLimited)
+	require.False(t, ok)
+}
+
+func TestModelFallbackTriggerForStatus(t *testing.T) {
+	require.Equal(t, ModelFallbackTriggerRateLimited, ModelFallbackTriggerForStatus(429))
+	require.Equal(t, ModelFallbackTriggerOverloaded, ModelFallbackTriggerForStatus(529))
+	require.Equal(t, ModelFallbackTriggerOverloaded, ModelFallbackTriggerForStatus(503))
+	require.Empty(t, ModelFallbackTriggerForStatus(500))
+}
+
+func TestValidateGroupModelFallbackChains_MessagesPlatformsOnly(t *testing.T) {
+	chains := []ModelFallbackChain{{Source: "*", Fallbacks: []ModelFallbackStep{{Model: "claude-haiku-4"}}}}
+	require.NoError(t, validateGroupModelFallbackChains(PlatformAnthropic, chains))
+	require.NoError(t, validateGroupModelFallbackChains(PlatformAntigravity, chains))
+	require.NoError(t, validateGroupModelFallbackChains(PlatformOpenAI, nil))
+	require.ErrorIs(t, validateGroupModelFallbackChains(PlatformOpenAI, chains), domain.ErrModelFallbackInvalid)
+	require.ErrorIs(t, validateGroupModelFallbackChains(PlatformGemini, chains), domain.ErrModelFallbackInvalid)
+}
+
+func TestPromptTooLongForModelFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	svc := &GatewayService{}
+	account := &Account{ID: 3, Platform: PlatformAnthropic}
+	newResp := func(status int, body string) *http.Response {
+		return &http.Response{StatusCode: status, Header: http.Header{"X-Request-Id": []string{"req-1"}}, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	tooLongBody := `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`
+	enabled := WithModelFallbackOnPromptTooLong(context.Background())
+
+	resp := newResp(http.StatusBadRequest, tooLongBody)
+	require.Nil(t, svc.promptTooLongForModelFallback(context.Background(), c, account, resp), "未启用 context_too_long 降级时不拦截")
+
+	resp = newResp(http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"messages: field required"}}`)
+	require.Nil(t, svc.promptTooLongForModelFallback(enabled, c, account, resp))
+	remaining, _ := io.ReadAll(resp.Body)
+	require.Contains(t, string(remaining), "field required", "未命中时响应体仍可读取")
+
+	resp = newResp(http.StatusBadRequest, tooLongBody)
+	tooLong := svc.promptTooLongForModelFallback(enabled, c, account, resp)
+	require.NotNil(t, tooLong)
+	require.Equal(t, http.StatusBadRequest, tooLong.StatusCode)
+	require.Equal(t, "req-1", tooLong.RequestID)
+	require.JSONEq(t, tooLongBody, string(tooLong.Body))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// OIDCSyntheticEmailDomain 是通用 OIDC/OAuth2 用户的合成邮箱后缀（RFC 保留域名）。
+const OIDCSyntheticEmailDomain = "@oidc-connect.invalid"
+
+const (
+	OIDCTokenAuthClientSecretPost  = "client_secret_post"
+	OIDCTokenAuthClientSecretBasic = "client_secret_basic"
+	OIDCTokenAuthNone              = "none"
+)
+
+// OIDCGroupMapping IdP 分组到 sub2api 权限的映射
+type OIDCGroupMapping struct {
+	// IdPGroup IdP 返回的分组名（大小写敏感）
+	IdPGroup string `json:"idp_group"`
+	// AllowedGroupIDs 每次登录时增量加入用户的可用分组（专属分组授权）
+	AllowedGroupIDs []int64 `json:"allowed_group_ids"`
+	// Subscriptions 首次注册时额外发放的订阅
+	Subscriptions []DefaultSubscriptionSetting `json:"subscriptions"`
+}
+
+// OIDCProvider 一个 OIDC/OAuth2 登录提供方（保存在 settings 中）。
+// 配置 DiscoveryURL 时端点从 .well-known/openid-configuration 读取，显式填写的端点优先；
+// 未提供 id_token 的纯 OAuth2 提供方（如 GitHub）依赖 UserInfoURL 获取身份。
+type OIDCProvider struct {
+	Key                    string `json:"key"`
+	Name                   string `json:"name"`
+	Enabled                bool   `json:"enabled"`
+	DiscoveryURL           string `json:"discovery_url"`
+	Issuer                 string `json:"issuer"`
+	AuthorizeURL           string `json:"authorize_url"`
+	TokenURL               string `json:"token_url"`
+	UserInfoURL            string `json:"userinfo_url"`
+	JWKSURL                string `json:"jwks_url"`
+	ClientID               string `json:"client_id"`
+	ClientSecret           string `json:"client_secret"`
+	ClientSecretConfigured bool   `json:"-"`
+	TokenAuthMethod        string `json:"token_auth_method"`
+	Scopes                 string `json:"scopes"`
+	// RedirectURL 后端回调地址（需在 IdP 登记），形如 https://host/api/v1/auth/oauth/oidc/{key}/callback
+	RedirectURL string `json:"redirect_url"`
+	// FrontendRedirectURL 前端接收 token 的路由（默认：/auth/oidc/callback）
+	FrontendRedirectURL string `json:"frontend_redirect_url"`
+
+	// 声明映射：gjson 路径，作用于 id_token 声明与 userinfo 合并后的 JSON
+	SubjectClaim       string `json:"subject_claim"`
+	EmailClaim         string `json:"email_claim"`
+	EmailVerifiedClaim string `json:"email_verified_claim"`
+	UsernameClaim      string `json:"username_claim"`
+	GroupsClaim        string `json:"groups_claim"`
+	// TrustEmail 信任 IdP 返回的已验证邮箱，直接与同邮箱的本地账号关联；
+	// 关闭时使用基于 subject 的合成邮箱，本地账号需主动绑定。
+	TrustEmail bool `json:"trust_email"`
+	// AssumeEmailVerified 声明中缺少 email_verified 时视为已验证。
+	// 仅用于确认只返回已验证邮箱、却不提供该声明的提供方（如 GitHub），默认关闭。
+	AssumeEmailVerified bool `json:"assume_email_verified"`
+
+	GroupMappings []OIDCGroupMapping `json:"group_mappings"`
+	UpdatedAt     string             `json:"updated_at"`
+}
+
+// OIDCPublicProvider 登录页展示的提供方信息
+type OIDCPublicProvider struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// OAuthGrants 第三方登录附带的授权（由 IdP 分组映射得出）
+type OAuthGrants struct {
+	AllowedGroupIDs []int64
+	Subscriptions   []DefaultSubscriptionSetting
+}
+
+// UserIdentity 本地用户与外部身份的绑定关系
+type UserIdentity struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	Username    string     `json:"username"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// UserIdentityRepository 外部身份绑定存储
+type UserIdentityRepository interface {
+	// GetByProviderSubject 不存在时返回 nil, nil
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*UserIdentity, error)
+	ListByUser(ctx context.Context, userID int64) ([]UserIdentity, error)
+	// Upsert 写入或刷新绑定；同一外部身份已绑定到其他用户时返回 ErrOIDCIdentityLinkedElsewhere
+	Upsert(ctx context.Context, identity *UserIdentity) error
+	Delete(ctx context.Context, userID int64, provider string) (bool, error)
+}
+
+// OIDCAuthState 授权请求发起时保存的一次性状态
+type OIDCAuthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	RedirectTo   string `json:"redirect_to"`
+	// LinkUserID 大于 0 表示已登录用户发起的账号绑定
+	LinkUserID int64 `json:"link_user_id,omitempty"`
+}
+
+// OIDCStateStore 授权状态存储（一次性读取）
+type OIDCStateStore interface {
+	SaveState(ctx context.Context, state string, data *OIDCAuthState, ttl time.Duration) error
+	// ConsumeState 读取并删除；不存在时返回 nil, nil
+	ConsumeState(ctx context.Context, state string) (*OIDCAuthState, error)
+}
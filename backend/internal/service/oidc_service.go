@@ -0,0 +1,768 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httpclient"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/oauth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	oidcStateTTL          = 10 * time.Minute
+	oidcHTTPTimeout       = 15 * time.Second
+	oidcMetadataCacheTTL  = time.Hour
+	oidcJWKSMinRefresh    = time.Minute
+	oidcMaxResponseBytes  = 1 << 20
+	oidcDefaultScopes     = "openid email profile"
+	oidcMaxSubjectLen     = 255
+	oidcMaxUsernameLength = 100
+)
+
+var (
+	ErrOIDCProviderDisabled        = infraerrors.NotFound("OAUTH_DISABLED", "oauth login is disabled")
+	ErrOIDCInvalidState            = infraerrors.BadRequest("OIDC_INVALID_STATE", "invalid or expired oauth state")
+	ErrOIDCIDTokenInvalid          = infraerrors.Unauthorized("OIDC_ID_TOKEN_INVALID", "invalid id token")
+	ErrOIDCSubjectMissing          = infraerrors.BadRequest("OIDC_SUBJECT_MISSING", "identity provider did not return a subject")
+	ErrOIDCIdentityLinkedElsewhere = infraerrors.Conflict("OIDC_IDENTITY_LINKED", "this external account is already linked to another user")
+	ErrOIDCProviderAlreadyLinked   = infraerrors.Conflict("OIDC_PROVIDER_ALREADY_LINKED", "another account of this provider is already linked")
+	ErrOIDCIdentityNotFound        = infraerrors.NotFound("OIDC_IDENTITY_NOT_FOUND", "linked identity not found")
+	ErrOIDCUnlinkLastLogin         = infraerrors.BadRequest("OIDC_UNLINK_LAST_LOGIN", "cannot unlink the only login method of this account")
+)
+
+// OIDCAuthResult 回调处理结果：登录时携带 TokenPair，绑定时 Linked 为 true
+type OIDCAuthResult struct {
+	Provider   string
+	RedirectTo string
+	Linked     bool
+	// User 登录的用户；Token 由 handler 在第二因素校验后签发
+	User *User
+}
+
+// oidcIdentity 从 id_token/userinfo 中提取的外部身份
+type oidcIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Groups        []string
+}
+
+type oidcEndpoints struct {
+	Issuer       string
+	AuthorizeURL string
+	TokenURL     string
+	UserInfoURL  string
+	JWKSURL      string
+}
+
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type oidcCachedDiscovery struct {
+	doc       oidcDiscoveryDocument
+	fetchedAt time.Time
+}
+
+type oidcCachedJWKS struct {
+	keys      map[string]any
+	fetchedAt time.Time
+}
+
+// OIDCService 通用 OIDC/OAuth2 单点登录：授权码 + PKCE + nonce，
+// 支持任意数量的提供方、已登录用户绑定外部身份，以及 IdP 分组到可用分组/订阅的映射。
+type OIDCService struct {
+	settingService *SettingService
+	authService    *AuthService
+	userRepo       UserRepository
+	identityRepo   UserIdentityRepository
+	stateStore     OIDCStateStore
+	httpClient     *http.Client
+
+	mu        sync.Mutex
+	discovery map[string]oidcCachedDiscovery
+	jwks      map[string]oidcCachedJWKS
+}
+
+// NewOIDCService 创建 OIDC 登录服务
+func NewOIDCService(
+	settingService *SettingService,
+	authService *AuthService,
+	userRepo UserRepository,
+	identityRepo UserIdentityRepository,
+	stateStore OIDCStateStore,
+) *OIDCService {
+	client, err := httpclient.GetClient(httpclient.Options{Timeout: oidcHTTPTimeout})
+	if err != nil {
+		client = &http.Client{Timeout: oidcHTTPTimeout}
+	}
+	return &OIDCService{
+		settingService: settingService,
+		authService:    authService,
+		userRepo:       userRepo,
+		identityRepo:   identityRepo,
+		stateStore:     stateStore,
+		httpClient:     client,
+		discovery:      make(map[string]oidcCachedDiscovery),
+		jwks:           make(map[string]oidcCachedJWKS),
+	}
+}
+
+// GetProvider 获取已启用的提供方配置
+func (s *OIDCService) GetProvider(ctx context.Context, key string) (*OIDCProvider, error) {
+	provider, err := s.settingService.GetOIDCProvider(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Enabled {
+		return nil, ErrOIDCProviderDisabled
+	}
+	return provider, nil
+}
+
+// BeginAuth 生成授权地址并保存一次性状态（state/nonce/PKCE verifier）。
+// linkUserID 大于 0 时回调将把外部身份绑定到该用户，而不是登录。
+func (s *OIDCService) BeginAuth(ctx context.Context, providerKey, redirectTo string, linkUserID int64) (authURL string, state string, err error) {
+	provider, err := s.GetProvider(ctx, providerKey)
+	if err != nil {
+		return "", "", err
+	}
+	endpoints, err := s.resolveEndpoints(ctx, provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err = oauth.GenerateState()
+	if err != nil {
+		return "", "", infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oauth state").WithCause(err)
+	}
+	nonce, err := oauth.GenerateState()
+	if err != nil {
+		return "", "", infraerrors.InternalServer("OAUTH_STATE_GEN_FAILED", "failed to generate oauth nonce").WithCause(err)
+	}
+	verifier, err := oauth.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", infraerrors.InternalServer("OAUTH_PKCE_GEN_FAILED", "failed to generate pkce verifier").WithCause(err)
+	}
+
+	if err := s.stateStore.SaveState(ctx, state, &OIDCAuthState{
+		Provider:     provider.Key,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectTo:   redirectTo,
+		LinkUserID:   linkUserID,
+	}, oidcStateTTL); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(endpoints.AuthorizeURL)
+	if err != nil {
+		return "", "", infraerrors.InternalServer("OAUTH_CONFIG_INVALID", "oauth authorize url invalid").WithCause(err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", provider.ClientID)
+	q.Set("redirect_uri", provider.RedirectURL)
+	q.Set("scope", oidcScopes(provider))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", oauth.GenerateCodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), state, nil
+}
+
+// CompleteAuth 处理回调：校验 state，交换授权码，验证 id_token 与 nonce，然后登录/注册或绑定。
+// 登录时只解析用户，不签发 Token（启用第二因素的用户须先完成 2FA）。
+func (s *OIDCService) CompleteAuth(ctx context.Context, providerKey, state, code string) (*OIDCAuthResult, error) {
+	pending, err := s.stateStore.ConsumeState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil || pending.Provider != providerKey {
+		return nil, ErrOIDCInvalidState
+	}
+	provider, err := s.GetProvider(ctx, providerKey)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := s.resolveEndpoints(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResp, err := s.exchangeCode(ctx, provider, endpoints, code, pending.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := s.fetchIdentity(ctx, provider, endpoints, tokenResp, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OIDCAuthResult{Provider: provider.Key, RedirectTo: pending.RedirectTo}
+	grants := oidcGrantsForGroups(provider, identity.Groups)
+	now := time.Now().UTC()
+
+	existing, err := s.identityRepo.GetByProviderSubject(ctx, provider.Key, identity.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("get linked identity: %w", err)
+	}
+
+	if pending.LinkUserID > 0 {
+		if existing != nil && existing.UserID != pending.LinkUserID {
+			return nil, ErrOIDCIdentityLinkedElsewhere
+		}
+		if err := s.identityRepo.Upsert(ctx, &UserIdentity{
+			UserID:   pending.LinkUserID,
+			Provider: provider.Key,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			Username: identity.Username,
+		}); err != nil {
+			return nil, err
+		}
+		result.Linked = true
+		return result, nil
+	}
+
+	var user *User
+	if existing != nil {
+		user, err = s.authService.LoginOAuthUser(ctx, existing.UserID, grants)
+	} else {
+		user, err = s.authService.LoginOrRegisterOAuthUser(ctx, oidcLoginEmail(provider, identity), identity.Username, grants)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.Upsert(ctx, &UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider.Key,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		Username:    identity.Username,
+		LastLoginAt: &now,
+	}); err != nil {
+		// 可信邮箱匹配到已绑定同一提供方其他身份的账号时无法记录绑定，不影响本次登录
+		logger.LegacyPrintf("service.oidc", "[OIDC] record identity failed: provider=%s user=%d err=%v", provider.Key, user.ID, err)
+	}
+
+	result.User = user
+	return result, nil
+}
+
+// ListIdentities 列出用户已绑定的外部身份
+func (s *OIDCService) ListIdentities(ctx context.Context, userID int64) ([]UserIdentity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+// Unlink 解除绑定；合成邮箱账号不能解除最后一个外部身份（否则无法再登录）
+func (s *OIDCService) Unlink(ctx context.Context, userID int64, providerKey string) error {
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == providerKey {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrOIDCIdentityNotFound
+	}
+	if len(identities) == 1 && s.userRepo != nil {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(user.Email, OIDCSyntheticEmailDomain) {
+			return ErrOIDCUnlinkLastLogin
+		}
+	}
+	deleted, err := s.identityRepo.Delete(ctx, userID, providerKey)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOIDCIdentityNotFound
+	}
+	return nil
+}
+
+func (s *OIDCService) resolveEndpoints(ctx context.Context, provider *OIDCProvider) (*oidcEndpoints, error) {
+	endpoints := &oidcEndpoints{
+		Issuer:       provider.Issuer,
+		AuthorizeURL: provider.AuthorizeURL,
+		TokenURL:     provider.TokenURL,
+		UserInfoURL:  provider.UserInfoURL,
+		JWKSURL:      provider.JWKSURL,
+	}
+	if provider.DiscoveryURL != "" {
+		doc, err := s.getDiscovery(ctx, provider.DiscoveryURL)
+		if err != nil {
+			return nil, infraerrors.ServiceUnavailable("OIDC_DISCOVERY_FAILED", "failed to load oidc discovery document").WithCause(err)
+		}
+		// 显式配置的端点优先
+		endpoints.Issuer = firstNonEmptyString(endpoints.Issuer, doc.Issuer)
+		endpoints.AuthorizeURL = firstNonEmptyString(endpoints.AuthorizeURL, doc.AuthorizationEndpoint)
+		endpoints.TokenURL = firstNonEmptyString(endpoints.TokenURL, doc.TokenEndpoint)
+		endpoints.UserInfoURL = firstNonEmptyString(endpoints.UserInfoURL, doc.UserinfoEndpoint)
+		endpoints.JWKSURL = firstNonEmptyString(endpoints.JWKSURL, doc.JWKSURI)
+	}
+	if endpoints.AuthorizeURL == "" || endpoints.TokenURL == "" {
+		return nil, infraerrors.InternalServer("OAUTH_CONFIG_INVALID", "oauth authorize/token url not configured")
+	}
+	return endpoints, nil
+}
+
+func (s *OIDCService) getDiscovery(ctx context.Context, discoveryURL string) (*oidcDiscoveryDocument, error) {
+	s.mu.Lock()
+	cached, ok := s.discovery[discoveryURL]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < oidcMetadataCacheTTL {
+		return &cached.doc, nil
+	}
+
+	var doc oidcDiscoveryDocument
+	if err := s.getJSON(ctx, discoveryURL, "", &doc); err != nil {
+		if ok {
+			// 刷新失败时沿用旧文档，避免 IdP 短暂故障导致无法登录
+			logger.LegacyPrintf("service.oidc", "[OIDC] discovery refresh failed, using cached document: url=%s err=%v", discoveryURL, err)
+			return &cached.doc, nil
+		}
+		return nil, err
+	}
+	s.mu.Lock()
+	s.discovery[discoveryURL] = oidcCachedDiscovery{doc: doc, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return &doc, nil
+}
+
+func (s *OIDCService) exchangeCode(ctx context.Context, provider *OIDCProvider, endpoints *oidcEndpoints, code, verifier string) (*oidcTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", provider.ClientID)
+	if provider.TokenAuthMethod == OIDCTokenAuthClientSecretPost {
+		form.Set("client_secret", provider.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if provider.TokenAuthMethod == OIDCTokenAuthClientSecretBasic {
+		req.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+	}
+
+	body, status, err := s.do(req)
+	if err != nil {
+		return nil, infraerrors.ServiceUnavailable("OIDC_TOKEN_EXCHANGE_FAILED", "failed to exchange oauth code").WithCause(err)
+	}
+	if status < 200 || status >= 300 {
+		logger.LegacyPrintf("service.oidc", "[OIDC] token exchange failed: provider=%s status=%d body=%s", provider.Key, status, truncateString(string(body), 512))
+		return nil, infraerrors.BadRequest("OIDC_TOKEN_EXCHANGE_FAILED", "failed to exchange oauth code")
+	}
+
+	var tokenResp oidcTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		// 部分 OAuth2 提供方默认返回 form 编码
+		values, qErr := url.ParseQuery(string(body))
+		if qErr != nil {
+			return nil, infraerrors.BadRequest("OIDC_TOKEN_EXCHANGE_FAILED", "invalid token response").WithCause(err)
+		}
+		tokenResp = oidcTokenResponse{
+			AccessToken: values.Get("access_token"),
+			TokenType:   values.Get("token_type"),
+			IDToken:     values.Get("id_token"),
+		}
+	}
+	if tokenResp.AccessToken == "" && tokenResp.IDToken == "" {
+		return nil, infraerrors.BadRequest("OIDC_TOKEN_EXCHANGE_FAILED", "token response missing access_token")
+	}
+	return &tokenResp, nil
+}
+
+// fetchIdentity 合并 id_token 声明与 userinfo（id_token 优先），再按提供方的声明映射提取身份
+func (s *OIDCService) fetchIdentity(ctx context.Context, provider *OIDCProvider, endpoints *oidcEndpoints, tokenResp *oidcTokenResponse, nonce string) (*oidcIdentity, error) {
+	claims := map[string]any{}
+	if tokenResp.IDToken != "" {
+		idClaims, err := s.verifyIDToken(ctx, provider, endpoints, tokenResp.IDToken, nonce)
+		if err != nil {
+			return nil, err
+		}
+		claims = idClaims
+	} else if oidcHasScope(oidcScopes(provider), "openid") {
+		return nil, ErrOIDCIDTokenInvalid.WithCause(errors.New("token response missing id_token"))
+	}
+
+	if endpoints.UserInfoURL != "" && tokenResp.AccessToken != "" {
+		var userInfo map[string]any
+		tokenType := tokenResp.TokenType
+		if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+			tokenType = "Bearer"
+		}
+		if err := s.getJSON(ctx, endpoints.UserInfoURL, tokenType+" "+tokenResp.AccessToken, &userInfo); err != nil {
+			if tokenResp.IDToken == "" {
+				return nil, infraerrors.ServiceUnavailable("OIDC_USERINFO_FAILED", "failed to fetch user info").WithCause(err)
+			}
+			logger.LegacyPrintf("service.oidc", "[OIDC] userinfo fetch failed, using id_token claims: provider=%s err=%v", provider.Key, err)
+		}
+		if sub, ok := claims["sub"]; ok && userInfo["sub"] != nil && fmt.Sprint(userInfo["sub"]) != fmt.Sprint(sub) {
+			return nil, ErrOIDCIDTokenInvalid.WithCause(errors.New("userinfo subject does not match id_token"))
+		}
+		for k, v := range userInfo {
+			if _, exists := claims[k]; !exists {
+				claims[k] = v
+			}
+		}
+	}
+
+	raw, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	return extractOIDCIdentity(provider, string(raw))
+}
+
+func (s *OIDCService) verifyIDToken(ctx context.Context, provider *OIDCProvider, endpoints *oidcEndpoints, idToken, nonce string) (map[string]any, error) {
+	if endpoints.JWKSURL == "" {
+		return nil, infraerrors.InternalServer("OAUTH_CONFIG_INVALID", "oidc jwks url not configured")
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	}
+	if endpoints.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(endpoints.Issuer))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.NewParser(opts...).ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.lookupJWK(ctx, endpoints.JWKSURL, kid)
+	})
+	if err != nil {
+		return nil, ErrOIDCIDTokenInvalid.WithCause(err)
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, ErrOIDCIDTokenInvalid.WithCause(errors.New("nonce mismatch"))
+	}
+	return claims, nil
+}
+
+// lookupJWK 按 kid 查找签名公钥；未命中时强制刷新一次（处理 IdP 轮换密钥）
+func (s *OIDCService) lookupJWK(ctx context.Context, jwksURL, kid string) (any, error) {
+	s.mu.Lock()
+	cached, ok := s.jwks[jwksURL]
+	s.mu.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < oidcMetadataCacheTTL {
+		if key := pickJWK(cached.keys, kid); key != nil {
+			return key, nil
+		}
+		if time.Since(cached.fetchedAt) < oidcJWKSMinRefresh {
+			return nil, fmt.Errorf("signing key %q not found", kid)
+		}
+	}
+
+	var doc struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := s.getJSON(ctx, jwksURL, "", &doc); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]any, len(doc.Keys))
+	for i, rawKey := range doc.Keys {
+		keyID, key, err := parseJWK(rawKey)
+		if err != nil {
+			continue
+		}
+		if keyID == "" {
+			keyID = fmt.Sprintf("#%d", i)
+		}
+		keys[keyID] = key
+	}
+	s.mu.Lock()
+	s.jwks[jwksURL] = oidcCachedJWKS{keys: keys, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	if key := pickJWK(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %q not found", kid)
+}
+
+func pickJWK(keys map[string]any, kid string) any {
+	if kid != "" {
+		return keys[kid]
+	}
+	// 未指定 kid 时仅在只有一把密钥时使用
+	if len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func parseJWK(raw json.RawMessage) (string, any, error) {
+	var jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, errors.New("not a signing key")
+	}
+	decode := func(v string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(v, "="))
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func (s *OIDCService) getJSON(ctx context.Context, target, authorization string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	body, status, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("GET %s: status %d", target, status)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (s *OIDCService) do(req *http.Request) ([]byte, int, error) {
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxResponseBytes))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+// extractOIDCIdentity 按声明映射（gjson 路径）提取身份；未配置时尝试常见字段名
+func extractOIDCIdentity(provider *OIDCProvider, claimsJSON string) (*oidcIdentity, error) {
+	pick := func(configured string, fallbacks ...string) gjson.Result {
+		paths := fallbacks
+		if configured != "" {
+			paths = []string{configured}
+		}
+		for _, path := range paths {
+			if res := gjson.Get(claimsJSON, path); res.Exists() && res.Type != gjson.Null {
+				return res
+			}
+		}
+		return gjson.Result{}
+	}
+
+	subject := strings.TrimSpace(pick(provider.SubjectClaim, "sub", "id", "user_id").String())
+	if subject == "" || len(subject) > oidcMaxSubjectLen {
+		return nil, ErrOIDCSubjectMissing
+	}
+
+	identity := &oidcIdentity{
+		Subject:  subject,
+		Email:    strings.TrimSpace(pick(provider.EmailClaim, "email").String()),
+		Username: strings.TrimSpace(pick(provider.UsernameClaim, "preferred_username", "login", "nickname", "name").String()),
+	}
+	// 仅显式返回 email_verified=true 时视为已验证；缺少该声明时由提供方的 AssumeEmailVerified 决定
+	verified := pick(provider.EmailVerifiedClaim, "email_verified")
+	if verified.Exists() {
+		identity.EmailVerified = verified.Bool()
+	} else {
+		identity.EmailVerified = provider.AssumeEmailVerified
+	}
+	if runes := []rune(identity.Username); len(runes) > oidcMaxUsernameLength {
+		identity.Username = string(runes[:oidcMaxUsernameLength])
+	}
+
+	groups := pick(provider.GroupsClaim, "groups")
+	if groups.IsArray() {
+		for _, g := range groups.Array() {
+			if v := strings.TrimSpace(g.String()); v != "" {
+				identity.Groups = append(identity.Groups, v)
+			}
+		}
+	} else if v := strings.TrimSpace(groups.String()); v != "" {
+		identity.Groups = []string{v}
+	}
+	return identity, nil
+}
+
+// oidcGrantsForGroups 汇总用户所属 IdP 分组命中的映射
+func oidcGrantsForGroups(provider *OIDCProvider, groups []string) *OAuthGrants {
+	if len(provider.GroupMappings) == 0 || len(groups) == 0 {
+		return nil
+	}
+	member := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		member[g] = struct{}{}
+	}
+
+	grants := &OAuthGrants{}
+	seenGroups := map[int64]struct{}{}
+	seenSubs := map[int64]struct{}{}
+	for _, m := range provider.GroupMappings {
+		if _, ok := member[m.IdPGroup]; !ok {
+			continue
+		}
+		for _, id := range m.AllowedGroupIDs {
+			if _, ok := seenGroups[id]; !ok {
+				seenGroups[id] = struct{}{}
+				grants.AllowedGroupIDs = append(grants.AllowedGroupIDs, id)
+			}
+		}
+		for _, sub := range m.Subscriptions {
+			if _, ok := seenSubs[sub.GroupID]; !ok {
+				seenSubs[sub.GroupID] = struct{}{}
+				grants.Subscriptions = append(grants.Subscriptions, sub)
+			}
+		}
+	}
+	if len(grants.AllowedGroupIDs) == 0 && len(grants.Subscriptions) == 0 {
+		return nil
+	}
+	return grants
+}
+
+// oidcLoginEmail 未绑定身份首次登录时使用的本地邮箱：
+// 仅在提供方开启 TrustEmail 且邮箱已验证时使用真实邮箱（可关联同邮箱本地账号），否则使用基于 subject 的合成邮箱。
+func oidcLoginEmail(provider *OIDCProvider, identity *oidcIdentity) string {
+	if provider.TrustEmail && identity.Email != "" && identity.EmailVerified {
+		return identity.Email
+	}
+	return OIDCSyntheticEmail(provider.Key, identity.Subject)
+}
+
+// OIDCSyntheticEmail 基于提供方与 subject 生成稳定的合成邮箱
+func OIDCSyntheticEmail(providerKey, subject string) string {
+	sum := sha256.Sum256([]byte(providerKey + "\x00" + subject))
+	return providerKey + "-" + hex.EncodeToString(sum[:12]) + OIDCSyntheticEmailDomain
+}
+
+func oidcScopes(provider *OIDCProvider) string {
+	if provider.Scopes != "" {
+		return provider.Scopes
+	}
+	if provider.DiscoveryURL != "" {
+		return oidcDefaultScopes
+	}
+	return ""
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func oidcHasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
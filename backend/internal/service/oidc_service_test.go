@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type oidcSettingRepoStub struct {
+	SettingRepository
+	values map[string]string
+}
+
+func (s *oidcSettingRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+	return "", ErrSettingNotFound
+}
+
+func (s *oidcSettingRepoStub) Set(ctx context.Context, key, value string) error {
+	s.values[key] = value
+	return nil
+}
+
+type oidcUserRepoStub struct {
+	UserRepository
+	users  map[int64]*User
+	nextID int64
+	grants []int64
+}
+
+func (r *oidcUserRepoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *oidcUserRepoStub) GetByEmail(ctx context.Context, email string) (*User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *oidcUserRepoStub) Create(ctx context.Context, user *User) error {
+	r.nextID++
+	user.ID = r.nextID
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *oidcUserRepoStub) AddGroupToAllowedGroups(ctx context.Context, userID int64, groupID int64) error {
+	r.grants = append(r.grants, groupID)
+	return nil
+}
+
+type oidcIdentityRepoStub struct {
+	identities []UserIdentity
+}
+
+func (r *oidcIdentityRepoStub) GetByProviderSubject(ctx context.Context, provider, subject string) (*UserIdentity, error) {
+	for i := range r.identities {
+		if r.identities[i].Provider == provider && r.identities[i].Subject == subject {
+			identity := r.identities[i]
+			return &identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *oidcIdentityRepoStub) ListByUser(ctx context.Context, userID int64) ([]UserIdentity, error) {
+	out := []UserIdentity{}
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			out = append(out, identity)
+		}
+	}
+	return out, nil
+}
+
+func (r *oidcIdentityRepoStub) Upsert(ctx context.Context, identity *UserIdentity) error {
+	for i := range r.identities {
+		if r.identities[i].Provider == identity.Provider && r.identities[i].Subject == identity.Subject {
+			if r.identities[i].UserID != identity.UserID {
+				return ErrOIDCIdentityLinkedElsewhere
+			}
+			r.identities[i] = *identity
+			return nil
+		}
+	}
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *oidcIdentityRepoStub) Delete(ctx context.Context, userID int64, provider string) (bool, error) {
+	for i := range r.identities {
+		if r.identities[i].UserID == userID && r.identities[i].Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type oidcStateStoreStub struct {
+	states map[string]*OIDCAuthState
+}
+
+func (s *oidcStateStoreStub) SaveState(ctx context.Context, state string, data *OIDCAuthState, ttl time.Duration) error {
+	s.states[state] = data
+	return nil
+}
+
+func (s *oidcStateStoreStub) ConsumeState(ctx context.Context, state string) (*OIDCAuthState, error) {
+	data := s.states[state]
+	delete(s.states, state)
+	return data, nil
+}
+
+type oidcRefreshTokenCacheStub struct {
+	RefreshTokenCache
+}
+
+func (oidcRefreshTokenCacheStub) StoreRefreshToken(ctx context.Context, tokenHash string, data *RefreshTokenData, ttl time.Duration) error {
+	return nil
+}
+
+func (oidcRefreshTokenCacheStub) AddToUserTokenSet(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+func (oidcRefreshTokenCacheStub) AddToFamilyTokenSet(ctx context.Context, familyID string, tokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+type oidcSubAssignerStub struct {
+	calls []AssignSubscriptionInput
+}
+
+func (s *oidcSubAssignerStub) AssignOrExtendSubscription(ctx context.Context, input *AssignSubscriptionInput) (*UserSubscription, bool, error) {
+	s.calls = append(s.calls, *input)
+	return &UserSubscription{UserID: input.UserID, GroupID: input.GroupID}, false, nil
+}
+
+// fakeIdP 最小化的 OIDC 提供方：discovery + jwks + token + userinfo
+type fakeIdP struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	mu      sync.Mutex
+	subject string
+	groups  []string
+	nonce   string
+	// badNonce 为 true 时签发 nonce 不匹配的 id_token
+	badNonce bool
+	lastForm url.Values
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &fakeIdP{key: key, subject: "user-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"userinfo_endpoint":      idp.server.URL + "/userinfo",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		idp.mu.Lock()
+		idp.lastForm = r.PostForm
+		nonce := idp.nonce
+		if idp.badNonce {
+			nonce = "forged"
+		}
+		idp.mu.Unlock()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   "client-1",
+			"sub":   idp.subject,
+			"nonce": nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"email": "alice@example.com",
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": signed})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sub":                idp.subject,
+			"preferred_username": "alice",
+			"groups":             idp.groups,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+type oidcTestEnv struct {
+	svc        *OIDCService
+	users      *oidcUserRepoStub
+	identities *oidcIdentityRepoStub
+	assigner   *oidcSubAssignerStub
+	idp        *fakeIdP
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+	idp := newFakeIdP(t)
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", ExpireHour: 1}}
+	settingService := NewSettingService(&oidcSettingRepoStub{values: map[string]string{
+		SettingKeyRegistrationEnabled: "true",
+	}}, cfg)
+	_, err := settingService.UpsertOIDCProvider(context.Background(), &OIDCProvider{
+		Key:          "keycloak",
+		Name:         "Keycloak",
+		Enabled:      true,
+		DiscoveryURL: idp.server.URL + "/.well-known/openid-configuration",
+		ClientID:     "client-1",
+		ClientSecret: "secret-1",
+		RedirectURL:  "https://sub2api.example.com/api/v1/auth/oauth/oidc/keycloak/callback",
+		GroupMappings: []OIDCGroupMapping{
+			{IdPGroup: "engineering", AllowedGroupIDs: []int64{7}, Subscriptions: []DefaultSubscriptionSetting{{GroupID: 9, ValidityDays: 30}}},
+			{IdPGroup: "finance", AllowedGroupIDs: []int64{8}},
+		},
+	})
+	require.NoError(t, err)
+
+	users := &oidcUserRepoStub{users: map[int64]*User{}, nextID: 100}
+	identities := &oidcIdentityRepoStub{}
+	assigner := &oidcSubAssignerStub{}
+	authService := NewAuthService(users, nil, oidcRefreshTokenCacheStub{}, cfg, settingService, nil, nil, nil, nil, assigner)
+	svc := NewOIDCService(settingService, authService, users, identities, &oidcStateStoreStub{states: map[string]*OIDCAuthState{}})
+	return &oidcTestEnv{svc: svc, users: users, identities: identities, assigner: assigner, idp: idp}
+}
+
+// begin 发起授权并模拟 IdP 记录 nonce，返回 state
+func (e *oidcTestEnv) begin(t *testing.T, linkUserID int64) string {
+	t.Helper()
+	authURL, state, err := e.svc.BeginAuth(context.Background(), "keycloak", "/dashboard", linkUserID)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, e.idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	require.Equal(t, state, q.Get("state"))
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.NotEmpty(t, q.Get("code_challenge"))
+	require.Equal(t, "openid email profile", q.Get("scope"))
+	e.idp.mu.Lock()
+	e.idp.nonce = q.Get("nonce")
+	e.idp.mu.Unlock()
+	return state
+}
+
+func TestOIDCLoginRegistersUserAndAppliesGroupMappings(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.idp.groups = []string{"engineering", "unmapped"}
+	ctx := context.Background()
+
+	result, err := env.svc.CompleteAuth(ctx, "keycloak", env.begin(t, 0), "code-1")
+	require.NoError(t, err)
+	require.False(t, result.Linked)
+	require.NotNil(t, result.User)
+	require.Equal(t, "/dashboard", result.RedirectTo)
+
+	// PKCE verifier 与 client secret 随授权码一同提交
+	require.NotEmpty(t, env.idp.lastForm.Get("code_verifier"))
+	require.Equal(t, "secret-1", env.idp.lastForm.Get("client_secret"))
+
+	// 未开启 TrustEmail 时使用合成邮箱，不与同邮箱本地账号关联
+	require.Equal(t, OIDCSyntheticEmail("keycloak", "user-1"), result.User.Email)
+	require.Equal(t, "alice", result.User.Username)
+	require.Equal(t, []int64{7}, env.users.grants)
+	require.Len(t, env.assigner.calls, 1)
+	require.Equal(t, int64(9), env.assigner.calls[0].GroupID)
+	require.Len(t, env.identities.identities, 1)
+	require.Equal(t, result.User.ID, env.identities.identities[0].UserID)
+
+	// 再次登录走身份绑定，不重复注册或发放订阅；可用分组按当前 IdP 分组增量同步
+	env.idp.groups = []string{"engineering", "finance"}
+	again, err := env.svc.CompleteAuth(ctx, "keycloak", env.begin(t, 0), "code-2")
+	require.NoError(t, err)
+	require.Equal(t, result.User.ID, again.User.ID)
+	require.Len(t, env.users.users, 1)
+	require.Len(t, env.assigner.calls, 1)
+	require.Equal(t, []int64{7, 8}, env.users.grants)
+}
+
+func TestOIDCRejectsNonceMismatchAndStateReuse(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	ctx := context.Background()
+
+	env.idp.badNonce = true
+	state := env.begin(t, 0)
+	_, err := env.svc.CompleteAuth(ctx, "keycloak", state, "code-1")
+	require.ErrorIs(t, err, ErrOIDCIDTokenInvalid)
+	require.Empty(t, env.users.users)
+
+	// state 一次性使用
+	env.idp.badNonce = false
+	_, err = env.svc.CompleteAuth(ctx, "keycloak", state, "code-1")
+	require.ErrorIs(t, err, ErrOIDCInvalidState)
+}
+
+func TestOIDCLinkExistingUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	ctx := context.Background()
+	env.users.users[1] = &User{ID: 1, Email: "alice@corp.example", Status: StatusActive}
+	env.users.users[2] = &User{ID: 2, Email: "bob@corp.example", Status: StatusActive}
+
+	result, err := env.svc.CompleteAuth(ctx, "keycloak", env.begin(t, 1), "code-1")
+	require.NoError(t, err)
+	require.True(t, result.Linked)
+	require.Nil(t, result.User)
+
+	// 绑定后通过 SSO 登录到已有账号
+	login, err := env.svc.CompleteAuth(ctx, "keycloak", env.begin(t, 0), "code-2")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), login.User.ID)
+
+	// 同一外部身份不能再绑定到其他用户
+	_, err = env.svc.CompleteAuth(ctx, "keycloak", env.begin(t, 2), "code-3")
+	require.ErrorIs(t, err, ErrOIDCIdentityLinkedElsewhere)
+
+	identities, err := env.svc.ListIdentities(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	require.NoError(t, env.svc.Unlink(ctx, 1, "keycloak"))
+	require.ErrorIs(t, env.svc.Unlink(ctx, 1, "keycloak"), ErrOIDCIdentityNotFound)
+}
+
+func TestExtractOIDCIdentityClaimMapping(t *testing.T) {
+	// GitHub 风格：无 id_token，数字 id 与 login
+	identity, err := extractOIDCIdentity(&OIDCProvider{}, `{"id":12345,"login":"octocat","email":null}`)
+	require.NoError(t, err)
+	require.Equal(t, "12345", identity.Subject)
+	require.Equal(t, "octocat", identity.Username)
+	require.Empty(t, identity.Email)
+
+	// 缺少 email_verified 声明时默认未验证，避免开启 TrustEmail 后被未验证邮箱关联到本地账号
+	identity, err = extractOIDCIdentity(&OIDCProvider{}, `{"sub":"u1","email":"admin@example.com"}`)
+	require.NoError(t, err)
+	require.False(t, identity.EmailVerified)
+	require.Equal(t, OIDCSyntheticEmail("corp", "u1"), oidcLoginEmail(&OIDCProvider{Key: "corp", TrustEmail: true}, identity))
+	// 提供方显式开启后才视为已验证；显式返回 false 时仍以声明为准
+	identity, err = extractOIDCIdentity(&OIDCProvider{AssumeEmailVerified: true}, `{"sub":"u1","email":"admin@example.com"}`)
+	require.NoError(t, err)
+	require.True(t, identity.EmailVerified)
+	identity, err = extractOIDCIdentity(&OIDCProvider{AssumeEmailVerified: true}, `{"sub":"u1","email":"admin@example.com","email_verified":false}`)
+	require.NoError(t, err)
+	require.False(t, identity.EmailVerified)
+
+	// 自定义声明路径：Keycloak realm 角色作为分组
+	identity, err = extractOIDCIdentity(&OIDCProvider{
+		EmailClaim:  "mail",
+		GroupsClaim: "realm_access.roles",
+	}, `{"sub":"abc","mail":"a@corp.example","email_verified":false,"realm_access":{"roles":["admin","dev"]}}`)
+	require.NoError(t, err)
+	require.Equal(t, "a@corp.example", identity.Email)
+	require.False(t, identity.EmailVerified)
+	require.Equal(t, []string{"admin", "dev"}, identity.Groups)
+
+	// 未验证邮箱即使开启 TrustEmail 也使用合成邮箱
+	provider := &OIDCProvider{Key: "corp", TrustEmail: true}
+	require.Equal(t, OIDCSyntheticEmail("corp", "abc"), oidcLoginEmail(provider, identity))
+	identity.EmailVerified = true
+	require.Equal(t, "a@corp.example", oidcLoginEmail(provider, identity))
+
+	_, err = extractOIDCIdentity(&OIDCProvider{}, `{"email":"x@example.com"}`)
+	require.ErrorIs(t, err, ErrOIDCSubjectMissing)
+}
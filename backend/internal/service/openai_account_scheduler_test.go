@@ -536,7 +536,7 @@ func intPtrForTest(v int) *int {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := newAccountRuntimeStats()
 	stats.report(1001, true, nil)
 	firstTTFT := 100
 	stats.report(1001, false, &firstTTFT)
@@ -551,7 +551,7 @@ func TestOpenAIAccountRuntimeStats_ReportAndSnapshot(t *testing.T) {
 }
 
 func TestOpenAIAccountRuntimeStats_ReportConcurrent(t *testing.T) {
-	stats := newOpenAIAccountRuntimeStats()
+	stats := newAccountRuntimeStats()
 
 	const (
 		accountCount = 4
@@ -778,7 +778,7 @@ func TestBuildOpenAIWeightedSelectionOrder_HandlesInvalidScores(t *testing.T) {
 }
 
 func TestOpenAISelectionRNG_SeedZeroStillWorks(t *testing.T) {
-	rng := newOpenAISelectionRNG(0)
+	rng := newAccountSelectionRNG(0)
 	v1 := rng.nextUint64()
 	v2 := rng.nextUint64()
 	require.NotEqual(t, v1, v2)
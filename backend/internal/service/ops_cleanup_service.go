@@ -41,6 +41,7 @@ type OpsCleanupService struct {
 	db          *sql.DB
 	redisClient *redis.Client
 	cfg         *config.Config
+	partitions  *TimePartitionService
 
 	instanceID string
 
@@ -67,6 +68,15 @@ func NewOpsCleanupService(
 	}
 }
 
+// SetPartitionService sets the partition service; partitioned tables drop whole
+// expired monthly partitions before falling back to batched deletes.
+func (s *OpsCleanupService) SetPartitionService(partitions *TimePartitionService) {
+	if s == nil {
+		return
+	}
+	s.partitions = partitions
+}
+
 func (s *OpsCleanupService) Start() {
 	if s == nil {
 		return
@@ -191,11 +201,15 @@ func (s *OpsCleanupService) runCleanupOnce(ctx context.Context) (opsCleanupDelet
 	// Error-like tables: error logs / retry attempts / alert events.
 	if days := s.cfg.Ops.Cleanup.ErrorLogRetentionDays; days > 0 {
 		cutoff := now.AddDate(0, 0, -days)
+		dropped, err := s.dropExpiredPartitions(ctx, "ops_error_logs", cutoff)
+		if err != nil {
+			return out, err
+		}
 		n, err := deleteOldRowsByID(ctx, s.db, "ops_error_logs", "created_at", cutoff, batchSize, false)
 		if err != nil {
 			return out, err
 		}
-		out.errorLogs = n
+		out.errorLogs = dropped + n
 
 		n, err = deleteOldRowsByID(ctx, s.db, "ops_retry_attempts", "created_at", cutoff, batchSize, false)
 		if err != nil {
@@ -225,11 +239,15 @@ func (s *OpsCleanupService) runCleanupOnce(ctx context.Context) (opsCleanupDelet
 	// Minute-level metrics snapshots.
 	if days := s.cfg.Ops.Cleanup.MinuteMetricsRetentionDays; days > 0 {
 		cutoff := now.AddDate(0, 0, -days)
+		dropped, err := s.dropExpiredPartitions(ctx, "ops_system_metrics", cutoff)
+		if err != nil {
+			return out, err
+		}
 		n, err := deleteOldRowsByID(ctx, s.db, "ops_system_metrics", "created_at", cutoff, batchSize, false)
 		if err != nil {
 			return out, err
 		}
-		out.systemMetrics = n
+		out.systemMetrics = dropped + n
 	}
 
 	// Pre-aggregation tables (hourly/daily).
@@ -251,6 +269,20 @@ func (s *OpsCleanupService) runCleanupOnce(ctx context.Context) (opsCleanupDelet
 	return out, nil
 }
 
+// dropExpiredPartitions drops monthly partitions entirely older than cutoff and
+// returns the estimated number of rows removed (0 when the table is not partitioned).
+func (s *OpsCleanupService) dropExpiredPartitions(ctx context.Context, table string, cutoff time.Time) (int64, error) {
+	if s.partitions == nil {
+		return 0, nil
+	}
+	dropped, err := s.partitions.DropPartitionsInRange(ctx, table, time.Time{}, cutoff)
+	var total int64
+	for _, p := range dropped {
+		total += p.EstimatedRows
+	}
+	return total, err
+}
+
 func deleteOldRowsByID(
 	ctx context.Context,
 	db *sql.DB,
@@ -280,8 +312,9 @@ WITH batch AS (
   LIMIT $2
 )
 DELETE FROM %s
-WHERE id IN (SELECT id FROM batch)
-`, table, where, table)
+WHERE %s
+  AND id IN (SELECT id FROM batch)
+`, table, where, table, where)
 
 	var total int64
 	for {
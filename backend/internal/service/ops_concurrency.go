@@ -122,6 +122,10 @@ func (s *OpsService) GetConcurrencyStats(
 
 	collectedAt := time.Now()
 	loadMap := s.getAccountsLoadMapBestEffort(ctx, accounts)
+	var queueDepths map[int64]map[int]int
+	if s.concurrencyService != nil {
+		queueDepths = s.concurrencyService.GetAccountWaitQueueDepths()
+	}
 
 	platform := make(map[string]*PlatformConcurrencyInfo)
 	group := make(map[int64]*GroupConcurrencyInfo)
@@ -150,6 +154,7 @@ func (s *OpsService) GetConcurrencyStats(
 		}
 
 		load := loadMap[acc.ID]
+		depth := queueDepths[acc.ID]
 		currentInUse := int64(0)
 		waiting := int64(0)
 		if load != nil {
@@ -182,6 +187,7 @@ func (s *OpsService) GetConcurrencyStats(
 			if info.MaxCapacity > 0 {
 				info.LoadPercentage = float64(info.CurrentInUse) / float64(info.MaxCapacity) * 100
 			}
+			addWaitingByPriority(&info.WaitingByPriority, depth)
 			account[acc.ID] = info
 		}
 
@@ -196,6 +202,7 @@ func (s *OpsService) GetConcurrencyStats(
 			p.MaxCapacity += int64(acc.Concurrency)
 			p.CurrentInUse += currentInUse
 			p.WaitingInQueue += waiting
+			addWaitingByPriority(&p.WaitingByPriority, depth)
 		}
 
 		// Group aggregation (one account may contribute to multiple groups).
@@ -219,6 +226,7 @@ func (s *OpsService) GetConcurrencyStats(
 			g.MaxCapacity += int64(acc.Concurrency)
 			g.CurrentInUse += currentInUse
 			g.WaitingInQueue += waiting
+			addWaitingByPriority(&g.WaitingByPriority, depth)
 		} else {
 			for _, grp := range acc.Groups {
 				if grp == nil || grp.ID <= 0 {
@@ -242,6 +250,7 @@ func (s *OpsService) GetConcurrencyStats(
 				g.MaxCapacity += int64(acc.Concurrency)
 				g.CurrentInUse += currentInUse
 				g.WaitingInQueue += waiting
+				addWaitingByPriority(&g.WaitingByPriority, depth)
 			}
 		}
 	}
@@ -260,6 +269,19 @@ func (s *OpsService) GetConcurrencyStats(
 	return platform, group, account, &collectedAt, nil
 }
 
+// addWaitingByPriority 累加按优先级统计的排队数
+func addWaitingByPriority(dst *map[int]int64, depth map[int]int) {
+	if len(depth) == 0 {
+		return
+	}
+	if *dst == nil {
+		*dst = make(map[int]int64, len(depth))
+	}
+	for priority, count := range depth {
+		(*dst)[priority] += int64(count)
+	}
+}
+
 // listAllActiveUsersForOps returns all active users with their concurrency settings.
 func (s *OpsService) listAllActiveUsersForOps(ctx context.Context) ([]User, error) {
 	if s == nil || s.userRepo == nil {
@@ -117,6 +117,10 @@ type OpsInsertErrorLogInput struct {
 	IsRetryable bool
 	RetryCount  int
 
+	// A/B 实验标记（未参与实验时为空）
+	ExperimentID      *int64
+	ExperimentVariant *string
+
 	CreatedAt time.Time
 }
 
@@ -9,6 +9,8 @@ type PlatformConcurrencyInfo struct {
 	MaxCapacity    int64   `json:"max_capacity"`
 	LoadPercentage float64 `json:"load_percentage"`
 	WaitingInQueue int64   `json:"waiting_in_queue"`
+	// WaitingByPriority 本实例优先级等待队列中按优先级统计的排队数
+	WaitingByPriority map[int]int64 `json:"waiting_by_priority,omitempty"`
 }
 
 // GroupConcurrencyInfo aggregates concurrency usage by group.
@@ -22,6 +24,8 @@ type GroupConcurrencyInfo struct {
 	MaxCapacity    int64   `json:"max_capacity"`
 	LoadPercentage float64 `json:"load_percentage"`
 	WaitingInQueue int64   `json:"waiting_in_queue"`
+	// WaitingByPriority 本实例优先级等待队列中按优先级统计的排队数
+	WaitingByPriority map[int]int64 `json:"waiting_by_priority,omitempty"`
 }
 
 // AccountConcurrencyInfo represents real-time concurrency usage for a single account.
@@ -35,6 +39,8 @@ type AccountConcurrencyInfo struct {
 	MaxCapacity    int64   `json:"max_capacity"`
 	LoadPercentage float64 `json:"load_percentage"`
 	WaitingInQueue int64   `json:"waiting_in_queue"`
+	// WaitingByPriority 本实例优先级等待队列中按优先级统计的排队数
+	WaitingByPriority map[int]int64 `json:"waiting_by_priority,omitempty"`
 }
 
 // UserConcurrencyInfo represents real-time concurrency usage for a single user.
@@ -54,6 +54,7 @@ type OpsService struct {
 	geminiCompatService       *GeminiMessagesCompatService
 	antigravityGatewayService *AntigravityGatewayService
 	systemLogSink             *OpsSystemLogSink
+	streamResumeService       *StreamResumeService
 }
 
 func NewOpsService(
@@ -88,6 +89,11 @@ func NewOpsService(
 	return svc
 }
 
+// SetStreamResumeService 注入流式断线续传缓冲，用于展示续传指标
+func (s *OpsService) SetStreamResumeService(streamResumeService *StreamResumeService) {
+	s.streamResumeService = streamResumeService
+}
+
 func (s *OpsService) RequireMonitoringEnabled(ctx context.Context) error {
 	if s.IsMonitoringEnabled(ctx) {
 		return nil
@@ -23,6 +23,9 @@ type OpsSystemLogSinkHealth struct {
 	WrittenCount    uint64 `json:"written_count"`
 	AvgWriteDelayMs uint64 `json:"avg_write_delay_ms"`
 	LastError       string `json:"last_error"`
+
+	// ExternalSinks 外部日志平台 sink 的状态（未配置时为空）
+	ExternalSinks []LogSinkHealth `json:"external_sinks,omitempty"`
 }
 
 type OpsSystemLogSink struct {
@@ -43,6 +46,8 @@ type OpsSystemLogSink struct {
 	totalDelayNs uint64
 
 	lastError atomic.Value
+
+	external *LogSinkManager
 }
 
 func NewOpsSystemLogSink(opsRepo OpsRepository) *OpsSystemLogSink {
@@ -59,6 +64,14 @@ func NewOpsSystemLogSink(opsRepo OpsRepository) *OpsSystemLogSink {
 	return s
 }
 
+// SetExternalSinks 关联外部 sink，用于在健康状态中一并展示
+func (s *OpsSystemLogSink) SetExternalSinks(m *LogSinkManager) {
+	if s == nil {
+		return
+	}
+	s.external = m
+}
+
 func (s *OpsSystemLogSink) Start() {
 	if s == nil || s.opsRepo == nil {
 		return
@@ -267,6 +280,7 @@ func (s *OpsSystemLogSink) Health() OpsSystemLogSinkHealth {
 		WrittenCount:    written,
 		AvgWriteDelayMs: avgDelay,
 		LastError:       strings.TrimSpace(lastErr),
+		ExternalSinks:   s.external.Health(),
 	}
 }
 
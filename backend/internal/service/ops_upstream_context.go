@@ -36,6 +36,14 @@ const (
 	// OpsSkipPassthroughKey 由 applyErrorPassthroughRule 在命中 skip_monitoring=true 的规则时设置。
 	// ops_error_logger 中间件检查此 key，为 true 时跳过错误记录。
 	OpsSkipPassthroughKey = "ops_skip_passthrough"
+
+	// OpsRequestRateLimitKey 由 API Key 认证中间件在 Key/用户/分组 RPM/TPM 超限时设置（值如 "user:rpm"）。
+	// ops_error_logger 据此将错误归类为本地限流（request 阶段、业务限制）。
+	OpsRequestRateLimitKey = "ops_request_rate_limit"
+
+	// OpsExperimentKey 由网关在请求命中 A/B 实验时设置（值为 *ExperimentAssignment），
+	// ops_error_logger 据此为错误日志打上实验与变体标记。
+	OpsExperimentKey = "ops_experiment"
 )
 
 func setOpsUpstreamRequestBody(c *gin.Context, body []byte) {
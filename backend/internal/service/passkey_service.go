@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+var (
+	ErrPasskeyNotEnabled          = infraerrors.BadRequest("PASSKEY_NOT_ENABLED", "passkey feature is not enabled")
+	ErrPasskeyNotConfigured       = infraerrors.ServiceUnavailable("PASSKEY_NOT_CONFIGURED", "webauthn relying party is not configured")
+	ErrPasskeyCeremonyExpired     = infraerrors.BadRequest("PASSKEY_CEREMONY_EXPIRED", "passkey ceremony expired, please try again")
+	ErrPasskeyVerificationFailed  = infraerrors.Unauthorized("PASSKEY_VERIFICATION_FAILED", "passkey verification failed")
+	ErrPasskeyNotFound            = infraerrors.NotFound("PASSKEY_NOT_FOUND", "passkey not found")
+	ErrPasskeyAlreadyRegistered   = infraerrors.Conflict("PASSKEY_ALREADY_REGISTERED", "this passkey is already registered")
+	ErrPasskeyLimitReached        = infraerrors.BadRequest("PASSKEY_LIMIT_REACHED", "maximum number of passkeys reached")
+	ErrPasskeyNoneRegistered      = infraerrors.BadRequest("PASSKEY_NONE_REGISTERED", "no passkey is registered for this account")
+	ErrPasskeyCredentialCloned    = infraerrors.Unauthorized("PASSKEY_CREDENTIAL_CLONED", "passkey sign counter regressed, the authenticator may be cloned")
+	ErrAdminSecondFactorRequired  = infraerrors.Forbidden("ADMIN_2FA_REQUIRED", "admin accounts must enable two-factor authentication (TOTP or passkey)")
+	errPasskeyCeremonyKindInvalid = errors.New("passkey ceremony kind mismatch")
+)
+
+const (
+	passkeyCeremonyTTL    = 5 * time.Minute
+	maxPasskeysPerUser    = 10
+	passkeyDefaultName    = "Passkey"
+	passkeyMaxNameRunes   = 64
+	passkeyCeremonyRegist = "register"
+	passkeyCeremonyLogin  = "login"
+	passkeyCeremony2FA    = "2fa"
+)
+
+// UserPasskey 用户注册的 WebAuthn 凭证
+type UserPasskey struct {
+	ID           int64               `json:"id"`
+	UserID       int64               `json:"-"`
+	Name         string              `json:"name"`
+	CredentialID string              `json:"credential_id"` // base64url
+	Credential   webauthn.Credential `json:"-"`
+	LastUsedAt   *time.Time          `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// UserPasskeyRepository 持久化用户 Passkey
+type UserPasskeyRepository interface {
+	ListByUser(ctx context.Context, userID int64) ([]UserPasskey, error)
+	// GetByCredentialID 不存在时返回 nil, nil
+	GetByCredentialID(ctx context.Context, credentialID string) (*UserPasskey, error)
+	CountByUser(ctx context.Context, userID int64) (int, error)
+	// Create 凭证 ID 冲突时返回 ErrPasskeyAlreadyRegistered
+	Create(ctx context.Context, passkey *UserPasskey) error
+	UpdateCredential(ctx context.Context, id int64, credential *webauthn.Credential, usedAt time.Time) error
+	Rename(ctx context.Context, userID, id int64, name string) (bool, error)
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+}
+
+// PasskeyCeremony 一次 WebAuthn 注册/认证仪式的服务端状态
+type PasskeyCeremony struct {
+	Kind    string               `json:"kind"`
+	UserID  int64                `json:"user_id,omitempty"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+// PasskeyCeremonyStore 保存仪式状态（一次性消费）
+type PasskeyCeremonyStore interface {
+	SaveCeremony(ctx context.Context, token string, ceremony *PasskeyCeremony, ttl time.Duration) error
+	// ConsumeCeremony 读取并删除；不存在时返回 nil, nil
+	ConsumeCeremony(ctx context.Context, token string) (*PasskeyCeremony, error)
+}
+
+// PasskeyCredentialCreation 注册仪式选项，原样传给 navigator.credentials.create()
+type PasskeyCredentialCreation struct {
+	CeremonyToken string                       `json:"ceremony_token"`
+	Options       *protocol.CredentialCreation `json:"options"`
+}
+
+// PasskeyCredentialAssertion 认证仪式选项，原样传给 navigator.credentials.get()
+type PasskeyCredentialAssertion struct {
+	CeremonyToken string                        `json:"ceremony_token"`
+	Options       *protocol.CredentialAssertion `json:"options"`
+}
+
+// PasskeyStatus 当前用户的 Passkey 状态
+type PasskeyStatus struct {
+	FeatureEnabled bool          `json:"feature_enabled"`
+	Passkeys       []UserPasskey `json:"passkeys"`
+}
+
+// PasskeyService 处理 WebAuthn Passkey 的注册、无密码登录与第二因素验证
+type PasskeyService struct {
+	cfg            *config.Config
+	settingService *SettingService
+	totpService    *TotpService
+	userRepo       UserRepository
+	passkeyRepo    UserPasskeyRepository
+	ceremonies     PasskeyCeremonyStore
+}
+
+// NewPasskeyService creates a new PasskeyService
+func NewPasskeyService(
+	cfg *config.Config,
+	settingService *SettingService,
+	totpService *TotpService,
+	userRepo UserRepository,
+	passkeyRepo UserPasskeyRepository,
+	ceremonies PasskeyCeremonyStore,
+) *PasskeyService {
+	return &PasskeyService{
+		cfg:            cfg,
+		settingService: settingService,
+		totpService:    totpService,
+		userRepo:       userRepo,
+		passkeyRepo:    passkeyRepo,
+		ceremonies:     ceremonies,
+	}
+}
+
+// GetStatus returns the passkey feature status and the user's registered passkeys
+func (s *PasskeyService) GetStatus(ctx context.Context, userID int64) (*PasskeyStatus, error) {
+	passkeys, err := s.passkeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list passkeys: %w", err)
+	}
+	return &PasskeyStatus{
+		FeatureEnabled: s.settingService.IsPasskeyEnabled(ctx),
+		Passkeys:       passkeys,
+	}, nil
+}
+
+// BeginRegistration 发起 Passkey 注册（需再次验证身份，与 TOTP 设置一致）
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID int64, emailCode, password string) (*PasskeyCredentialCreation, error) {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if err := s.totpService.VerifyIdentity(ctx, user, emailCode, password); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.loadOwner(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(owner.passkeys) >= maxPasskeysPerUser {
+		return nil, ErrPasskeyLimitReached
+	}
+
+	options, session, err := wa.BeginRegistration(owner,
+		webauthn.WithExclusions(webauthn.Credentials(owner.WebAuthnCredentials()).CredentialDescriptors()),
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementPreferred,
+			UserVerification: protocol.VerificationPreferred,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("begin webauthn registration: %w", err)
+	}
+	token, err := s.saveCeremony(ctx, passkeyCeremonyRegist, userID, session)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyCredentialCreation{CeremonyToken: token, Options: options}, nil
+}
+
+// FinishRegistration 校验认证器返回的注册结果并保存凭证
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID int64, ceremonyToken, name string, response json.RawMessage) (*UserPasskey, error) {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ceremony, err := s.consumeCeremony(ctx, ceremonyToken, passkeyCeremonyRegist)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID != userID {
+		return nil, ErrPasskeyCeremonyExpired
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	owner, err := s.loadOwner(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, ErrPasskeyVerificationFailed.WithCause(err)
+	}
+	credential, err := wa.CreateCredential(owner, ceremony.Session, parsed)
+	if err != nil {
+		return nil, ErrPasskeyVerificationFailed.WithCause(err)
+	}
+
+	passkey := &UserPasskey{
+		UserID:       userID,
+		Name:         normalizePasskeyName(name),
+		CredentialID: encodePasskeyCredentialID(credential.ID),
+		Credential:   *credential,
+	}
+	if err := s.passkeyRepo.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// Rename 修改 Passkey 显示名称
+func (s *PasskeyService) Rename(ctx context.Context, userID, passkeyID int64, name string) error {
+	ok, err := s.passkeyRepo.Rename(ctx, userID, passkeyID, normalizePasskeyName(name))
+	if err != nil {
+		return fmt.Errorf("rename passkey: %w", err)
+	}
+	if !ok {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// Delete 删除 Passkey（需再次验证身份，与关闭 TOTP 一致）
+func (s *PasskeyService) Delete(ctx context.Context, userID, passkeyID int64, emailCode, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if err := s.totpService.VerifyIdentity(ctx, user, emailCode, password); err != nil {
+		return err
+	}
+	ok, err := s.passkeyRepo.Delete(ctx, userID, passkeyID)
+	if err != nil {
+		return fmt.Errorf("delete passkey: %w", err)
+	}
+	if !ok {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// BeginLogin 发起无密码登录（可发现凭证，由认证器选择账号）
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*PasskeyCredentialAssertion, error) {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// 无密码登录要求用户验证（生物识别/PIN），使 Passkey 本身即构成多因素
+	options, session, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, fmt.Errorf("begin webauthn login: %w", err)
+	}
+	token, err := s.saveCeremony(ctx, passkeyCeremonyLogin, 0, session)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyCredentialAssertion{CeremonyToken: token, Options: options}, nil
+}
+
+// FinishLogin 校验无密码登录断言，返回对应用户
+func (s *PasskeyService) FinishLogin(ctx context.Context, ceremonyToken string, response json.RawMessage) (*User, error) {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ceremony, err := s.consumeCeremony(ctx, ceremonyToken, passkeyCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, ErrPasskeyVerificationFailed.WithCause(err)
+	}
+
+	var owner *passkeyOwner
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		passkey, err := s.passkeyRepo.GetByCredentialID(ctx, encodePasskeyCredentialID(rawID))
+		if err != nil {
+			return nil, err
+		}
+		if passkey == nil {
+			return nil, ErrPasskeyNotFound
+		}
+		user, err := s.userRepo.GetByID(ctx, passkey.UserID)
+		if err != nil {
+			return nil, err
+		}
+		owner, err = s.loadOwner(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}
+	_, credential, err := wa.ValidatePasskeyLogin(handler, ceremony.Session, parsed)
+	if err != nil {
+		return nil, ErrPasskeyVerificationFailed.WithCause(err)
+	}
+	if err := s.recordUse(ctx, owner, credential); err != nil {
+		return nil, err
+	}
+	if !owner.user.IsActive() {
+		return nil, ErrUserNotActive
+	}
+	return owner.user, nil
+}
+
+// BeginSecondFactor 为密码登录后的 2FA 步骤发起认证（仅允许该用户已注册的凭证）
+func (s *PasskeyService) BeginSecondFactor(ctx context.Context, userID int64) (*PasskeyCredentialAssertion, error) {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	owner, err := s.loadOwner(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(owner.passkeys) == 0 {
+		return nil, ErrPasskeyNoneRegistered
+	}
+	options, session, err := wa.BeginLogin(owner, webauthn.WithUserVerification(protocol.VerificationPreferred))
+	if err != nil {
+		return nil, fmt.Errorf("begin webauthn login: %w", err)
+	}
+	token, err := s.saveCeremony(ctx, passkeyCeremony2FA, userID, session)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyCredentialAssertion{CeremonyToken: token, Options: options}, nil
+}
+
+// VerifySecondFactor 校验 2FA 步骤的 Passkey 断言
+func (s *PasskeyService) VerifySecondFactor(ctx context.Context, userID int64, ceremonyToken string, response json.RawMessage) error {
+	wa, err := s.relyingParty(ctx)
+	if err != nil {
+		return err
+	}
+	ceremony, err := s.consumeCeremony(ctx, ceremonyToken, passkeyCeremony2FA)
+	if err != nil {
+		return err
+	}
+	if ceremony.UserID != userID {
+		return ErrPasskeyCeremonyExpired
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	owner, err := s.loadOwner(ctx, user)
+	if err != nil {
+		return err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return ErrPasskeyVerificationFailed.WithCause(err)
+	}
+	credential, err := wa.ValidateLogin(owner, ceremony.Session, parsed)
+	if err != nil {
+		return ErrPasskeyVerificationFailed.WithCause(err)
+	}
+	return s.recordUse(ctx, owner, credential)
+}
+
+// HasUsablePasskey 判断用户是否可以使用 Passkey 作为第二因素
+func (s *PasskeyService) HasUsablePasskey(ctx context.Context, userID int64) (bool, error) {
+	if !s.settingService.IsPasskeyEnabled(ctx) {
+		return false, nil
+	}
+	count, err := s.passkeyRepo.CountByUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("count passkeys: %w", err)
+	}
+	return count > 0, nil
+}
+
+// CheckAdminSecondFactor 在启用“管理员必须启用第二因素”策略时校验管理员账号
+func (s *PasskeyService) CheckAdminSecondFactor(ctx context.Context, user *User) error {
+	if user == nil || !user.IsAdmin() {
+		return nil
+	}
+	return s.CheckStaffSecondFactor(ctx, user)
+}
+
+// CheckStaffSecondFactor 与 CheckAdminSecondFactor 相同，但适用于任何已获得后台访问权限的用户
+// （包括通过 RBAC 角色分配获得权限的普通用户）。
+func (s *PasskeyService) CheckStaffSecondFactor(ctx context.Context, user *User) error {
+	if user == nil || !s.settingService.IsAdminRequire2FA(ctx) {
+		return nil
+	}
+	if user.TotpEnabled && s.settingService.IsTotpEnabled(ctx) {
+		return nil
+	}
+	ok, err := s.HasUsablePasskey(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAdminSecondFactorRequired
+	}
+	return nil
+}
+
+// relyingParty 构造 WebAuthn 依赖方；功能未开启或未配置时返回错误
+func (s *PasskeyService) relyingParty(ctx context.Context) (*webauthn.WebAuthn, error) {
+	if !s.settingService.IsPasskeyEnabled(ctx) {
+		return nil, ErrPasskeyNotEnabled
+	}
+	if !s.settingService.IsWebAuthnConfigured() {
+		return nil, ErrPasskeyNotConfigured
+	}
+	displayName := strings.TrimSpace(s.cfg.WebAuthn.RPDisplayName)
+	if displayName == "" {
+		displayName = s.settingService.GetSiteName(ctx)
+	}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          s.cfg.WebAuthn.RPID,
+		RPDisplayName: displayName,
+		RPOrigins:     s.cfg.WebAuthn.RPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL, TimeoutUVD: passkeyCeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL, TimeoutUVD: passkeyCeremonyTTL},
+		},
+	})
+	if err != nil {
+		return nil, ErrPasskeyNotConfigured.WithCause(err)
+	}
+	return wa, nil
+}
+
+func (s *PasskeyService) saveCeremony(ctx context.Context, kind string, userID int64, session *webauthn.SessionData) (string, error) {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("generate ceremony token: %w", err)
+	}
+	if err := s.ceremonies.SaveCeremony(ctx, token, &PasskeyCeremony{Kind: kind, UserID: userID, Session: *session}, passkeyCeremonyTTL); err != nil {
+		return "", fmt.Errorf("store passkey ceremony: %w", err)
+	}
+	return token, nil
+}
+
+func (s *PasskeyService) consumeCeremony(ctx context.Context, token, kind string) (*PasskeyCeremony, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrPasskeyCeremonyExpired
+	}
+	ceremony, err := s.ceremonies.ConsumeCeremony(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("load passkey ceremony: %w", err)
+	}
+	if ceremony == nil {
+		return nil, ErrPasskeyCeremonyExpired
+	}
+	if ceremony.Kind != kind {
+		return nil, ErrPasskeyCeremonyExpired.WithCause(errPasskeyCeremonyKindInvalid)
+	}
+	return ceremony, nil
+}
+
+// recordUse 回写签名计数与标志位；计数回退视为凭证被克隆并拒绝
+func (s *PasskeyService) recordUse(ctx context.Context, owner *passkeyOwner, credential *webauthn.Credential) error {
+	passkey := owner.find(credential.ID)
+	if passkey == nil {
+		return ErrPasskeyNotFound
+	}
+	if credential.Authenticator.CloneWarning {
+		logger.LegacyPrintf("service.passkey", "[Passkey] clone warning: user=%d passkey=%d", owner.user.ID, passkey.ID)
+		return ErrPasskeyCredentialCloned
+	}
+	if err := s.passkeyRepo.UpdateCredential(ctx, passkey.ID, credential, time.Now()); err != nil {
+		return fmt.Errorf("update passkey: %w", err)
+	}
+	return nil
+}
+
+func (s *PasskeyService) loadOwner(ctx context.Context, user *User) (*passkeyOwner, error) {
+	passkeys, err := s.passkeyRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list passkeys: %w", err)
+	}
+	return &passkeyOwner{user: user, passkeys: passkeys}, nil
+}
+
+// passkeyOwner 适配 webauthn.User
+type passkeyOwner struct {
+	user     *User
+	passkeys []UserPasskey
+}
+
+// WebAuthnID 用户句柄：8 字节大端用户 ID（不含邮箱等个人信息）
+func (o *passkeyOwner) WebAuthnID() []byte {
+	return passkeyUserHandle(o.user.ID)
+}
+
+func (o *passkeyOwner) WebAuthnName() string {
+	return o.user.Email
+}
+
+func (o *passkeyOwner) WebAuthnDisplayName() string {
+	if o.user.Username != "" {
+		return o.user.Username
+	}
+	return o.user.Email
+}
+
+func (o *passkeyOwner) WebAuthnCredentials() []webauthn.Credential {
+	out := make([]webauthn.Credential, 0, len(o.passkeys))
+	for _, p := range o.passkeys {
+		out = append(out, p.Credential)
+	}
+	return out
+}
+
+func (o *passkeyOwner) find(credentialID []byte) *UserPasskey {
+	encoded := encodePasskeyCredentialID(credentialID)
+	for i := range o.passkeys {
+		if o.passkeys[i].CredentialID == encoded {
+			return &o.passkeys[i]
+		}
+	}
+	return nil
+}
+
+func passkeyUserHandle(userID int64) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+func encodePasskeyCredentialID(id []byte) string {
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+func normalizePasskeyName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return passkeyDefaultName
+	}
+	if runes := []rune(name); len(runes) > passkeyMaxNameRunes {
+		name = string(runes[:passkeyMaxNameRunes])
+	}
+	return name
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	passkeyTestRPID   = "sub2api.example.com"
+	passkeyTestOrigin = "https://sub2api.example.com"
+)
+
+type passkeySettingRepoStub struct {
+	SettingRepository
+	values map[string]string
+}
+
+func (s *passkeySettingRepoStub) GetValue(ctx context.Context, key string) (string, error) {
+	if v, ok := s.values[key]; ok {
+		return v, nil
+	}
+	return "", ErrSettingNotFound
+}
+
+type passkeyUserRepoStub struct {
+	UserRepository
+	users map[int64]*User
+}
+
+func (r *passkeyUserRepoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, ErrUserNotFound
+}
+
+type passkeyRepoStub struct {
+	passkeys []UserPasskey
+	nextID   int64
+}
+
+func (r *passkeyRepoStub) ListByUser(ctx context.Context, userID int64) ([]UserPasskey, error) {
+	out := []UserPasskey{}
+	for _, p := range r.passkeys {
+		if p.UserID == userID {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (r *passkeyRepoStub) GetByCredentialID(ctx context.Context, credentialID string) (*UserPasskey, error) {
+	for i := range r.passkeys {
+		if r.passkeys[i].CredentialID == credentialID {
+			p := r.passkeys[i]
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *passkeyRepoStub) CountByUser(ctx context.Context, userID int64) (int, error) {
+	list, _ := r.ListByUser(ctx, userID)
+	return len(list), nil
+}
+
+func (r *passkeyRepoStub) Create(ctx context.Context, passkey *UserPasskey) error {
+	if p, _ := r.GetByCredentialID(ctx, passkey.CredentialID); p != nil {
+		return ErrPasskeyAlreadyRegistered
+	}
+	r.nextID++
+	passkey.ID = r.nextID
+	passkey.CreatedAt = time.Now()
+	r.passkeys = append(r.passkeys, *passkey)
+	return nil
+}
+
+func (r *passkeyRepoStub) UpdateCredential(ctx context.Context, id int64, credential *webauthn.Credential, usedAt time.Time) error {
+	for i := range r.passkeys {
+		if r.passkeys[i].ID == id {
+			r.passkeys[i].Credential = *credential
+			r.passkeys[i].LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (r *passkeyRepoStub) Rename(ctx context.Context, userID, id int64, name string) (bool, error) {
+	for i := range r.passkeys {
+		if r.passkeys[i].ID == id && r.passkeys[i].UserID == userID {
+			r.passkeys[i].Name = name
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *passkeyRepoStub) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	for i := range r.passkeys {
+		if r.passkeys[i].ID == id && r.passkeys[i].UserID == userID {
+			r.passkeys = append(r.passkeys[:i], r.passkeys[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type passkeyCeremonyStoreStub struct {
+	ceremonies map[string]*PasskeyCeremony
+}
+
+func (s *passkeyCeremonyStoreStub) SaveCeremony(ctx context.Context, token string, ceremony *PasskeyCeremony, ttl time.Duration) error {
+	// 经过 JSON 往返，与 Redis 实现保持一致
+	raw, err := json.Marshal(ceremony)
+	if err != nil {
+		return err
+	}
+	var copied PasskeyCeremony
+	if err := json.Unmarshal(raw, &copied); err != nil {
+		return err
+	}
+	s.ceremonies[token] = &copied
+	return nil
+}
+
+func (s *passkeyCeremonyStoreStub) ConsumeCeremony(ctx context.Context, token string) (*PasskeyCeremony, error) {
+	ceremony := s.ceremonies[token]
+	delete(s.ceremonies, token)
+	return ceremony, nil
+}
+
+// softAuthenticator 最小化的 ES256 软件认证器（attestation: none）
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	counter      uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: id}
+}
+
+func (a *softAuthenticator) authData(t *testing.T, withCredential bool) []byte {
+	t.Helper()
+	rpHash := sha256.Sum256([]byte(passkeyTestRPID))
+	flags := byte(0x01 | 0x04) // UP | UV
+	if withCredential {
+		flags |= 0x40 // AT
+	}
+	data := append([]byte{}, rpHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	if withCredential {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		pub, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{KeyType: int64(webauthncose.EllipticKey), Algorithm: int64(webauthncose.AlgES256)},
+			Curve:         1, // P-256
+			XCoord:        a.key.X.FillBytes(make([]byte, 32)),
+			YCoord:        a.key.Y.FillBytes(make([]byte, 32)),
+		})
+		require.NoError(t, err)
+		data = append(data, pub...)
+	}
+	return data
+}
+
+func clientDataJSON(t *testing.T, typ, challenge string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"type": typ, "challenge": challenge, "origin": passkeyTestOrigin})
+	require.NoError(t, err)
+	return raw
+}
+
+func (a *softAuthenticator) create(t *testing.T, creation *PasskeyCredentialCreation) json.RawMessage {
+	t.Helper()
+	a.userHandle = creation.Options.Response.User.ID.(protocol.URLEncodedBase64)
+	attObj, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(t, true),
+	})
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	raw, err := json.Marshal(map[string]any{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(clientDataJSON(t, "webauthn.create", creation.Options.Response.Challenge.String())),
+			"attestationObject": b64(attObj),
+		},
+	})
+	require.NoError(t, err)
+	return raw
+}
+
+func (a *softAuthenticator) get(t *testing.T, assertion *PasskeyCredentialAssertion, counter uint32) json.RawMessage {
+	t.Helper()
+	a.counter = counter
+	authData := a.authData(t, false)
+	cdj := clientDataJSON(t, "webauthn.get", assertion.Options.Response.Challenge.String())
+	cdjHash := sha256.Sum256(cdj)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), cdjHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	raw, err := json.Marshal(map[string]any{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(cdj),
+			"authenticatorData": b64(authData),
+			"signature":         b64(sig),
+			"userHandle":        b64(a.userHandle),
+		},
+	})
+	require.NoError(t, err)
+	return raw
+}
+
+type passkeyTestEnv struct {
+	svc      *PasskeyService
+	settings map[string]string
+	passkeys *passkeyRepoStub
+	user     *User
+}
+
+func newPasskeyTestEnv(t *testing.T) *passkeyTestEnv {
+	t.Helper()
+	cfg := &config.Config{WebAuthn: config.WebAuthnConfig{
+		RPID:          passkeyTestRPID,
+		RPDisplayName: "Sub2API",
+		RPOrigins:     []string{passkeyTestOrigin},
+	}}
+	values := map[string]string{SettingKeyPasskeyEnabled: "true"}
+	settingService := NewSettingService(&passkeySettingRepoStub{values: values}, cfg)
+	user := &User{ID: 7, Email: "alice@example.com", Role: RoleUser, Status: StatusActive}
+	require.NoError(t, user.SetPassword("correct-horse"))
+	users := &passkeyUserRepoStub{users: map[int64]*User{user.ID: user}}
+	totpService := NewTotpService(users, nil, nil, settingService, nil, nil)
+	passkeys := &passkeyRepoStub{}
+	svc := NewPasskeyService(cfg, settingService, totpService, users, passkeys, &passkeyCeremonyStoreStub{ceremonies: map[string]*PasskeyCeremony{}})
+	return &passkeyTestEnv{svc: svc, settings: values, passkeys: passkeys, user: user}
+}
+
+func (e *passkeyTestEnv) register(t *testing.T, authenticator *softAuthenticator) *UserPasskey {
+	t.Helper()
+	ctx := context.Background()
+	creation, err := e.svc.BeginRegistration(ctx, e.user.ID, "", "correct-horse")
+	require.NoError(t, err)
+	passkey, err := e.svc.FinishRegistration(ctx, e.user.ID, creation.CeremonyToken, "  Laptop  ", authenticator.create(t, creation))
+	require.NoError(t, err)
+	return passkey
+}
+
+func TestPasskeyRegistrationAndPasswordlessLogin(t *testing.T) {
+	env := newPasskeyTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.svc.BeginRegistration(ctx, env.user.ID, "", "wrong")
+	require.ErrorIs(t, err, ErrPasswordIncorrect)
+
+	authenticator := newSoftAuthenticator(t)
+	passkey := env.register(t, authenticator)
+	require.Equal(t, "Laptop", passkey.Name)
+	require.Equal(t, base64.RawURLEncoding.EncodeToString(authenticator.credentialID), passkey.CredentialID)
+	require.Equal(t, passkeyUserHandle(env.user.ID), authenticator.userHandle)
+
+	assertion, err := env.svc.BeginLogin(ctx)
+	require.NoError(t, err)
+	user, err := env.svc.FinishLogin(ctx, assertion.CeremonyToken, authenticator.get(t, assertion, 5))
+	require.NoError(t, err)
+	require.Equal(t, env.user.ID, user.ID)
+	require.Equal(t, uint32(5), env.passkeys.passkeys[0].Credential.Authenticator.SignCount)
+	require.NotNil(t, env.passkeys.passkeys[0].LastUsedAt)
+
+	// 挑战一次性使用
+	_, err = env.svc.FinishLogin(ctx, assertion.CeremonyToken, authenticator.get(t, assertion, 6))
+	require.ErrorIs(t, err, ErrPasskeyCeremonyExpired)
+
+	// 签名计数回退视为克隆
+	assertion, err = env.svc.BeginLogin(ctx)
+	require.NoError(t, err)
+	_, err = env.svc.FinishLogin(ctx, assertion.CeremonyToken, authenticator.get(t, assertion, 3))
+	require.ErrorIs(t, err, ErrPasskeyCredentialCloned)
+}
+
+func TestPasskeySecondFactor(t *testing.T) {
+	env := newPasskeyTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.svc.BeginSecondFactor(ctx, env.user.ID)
+	require.ErrorIs(t, err, ErrPasskeyNoneRegistered)
+
+	authenticator := newSoftAuthenticator(t)
+	env.register(t, authenticator)
+	ok, err := env.svc.HasUsablePasskey(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assertion, err := env.svc.BeginSecondFactor(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, assertion.Options.Response.AllowedCredentials, 1)
+
+	// 2FA 仪式不能用于其他用户
+	require.ErrorIs(t, env.svc.VerifySecondFactor(ctx, env.user.ID+1, assertion.CeremonyToken, authenticator.get(t, assertion, 1)), ErrPasskeyCeremonyExpired)
+
+	assertion, err = env.svc.BeginSecondFactor(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.NoError(t, env.svc.VerifySecondFactor(ctx, env.user.ID, assertion.CeremonyToken, authenticator.get(t, assertion, 2)))
+
+	// 2FA 仪式不能当作无密码登录使用
+	assertion, err = env.svc.BeginSecondFactor(ctx, env.user.ID)
+	require.NoError(t, err)
+	_, err = env.svc.FinishLogin(ctx, assertion.CeremonyToken, authenticator.get(t, assertion, 3))
+	require.ErrorIs(t, err, ErrPasskeyCeremonyExpired)
+
+	// 功能关闭后 Passkey 不再作为可用第二因素
+	env.settings[SettingKeyPasskeyEnabled] = "false"
+	ok, err = env.svc.HasUsablePasskey(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = env.svc.BeginLogin(ctx)
+	require.ErrorIs(t, err, ErrPasskeyNotEnabled)
+}
+
+func TestPasskeyAdminSecondFactorPolicy(t *testing.T) {
+	env := newPasskeyTestEnv(t)
+	ctx := context.Background()
+	env.user.Role = RoleAdmin
+
+	require.NoError(t, env.svc.CheckAdminSecondFactor(ctx, env.user))
+
+	env.settings[SettingKeyAdminRequire2FA] = "true"
+	require.ErrorIs(t, env.svc.CheckAdminSecondFactor(ctx, env.user), ErrAdminSecondFactorRequired)
+
+	// 已启用 TOTP 即满足策略
+	env.settings[SettingKeyTotpEnabled] = "true"
+	env.user.TotpEnabled = true
+	require.NoError(t, env.svc.CheckAdminSecondFactor(ctx, env.user))
+
+	// 或者注册了 Passkey
+	env.user.TotpEnabled = false
+	env.register(t, newSoftAuthenticator(t))
+	require.NoError(t, env.svc.CheckAdminSecondFactor(ctx, env.user))
+
+	// 普通用户不受影响
+	env.user.Role = RoleUser
+	env.passkeys.passkeys = nil
+	require.NoError(t, env.svc.CheckAdminSecondFactor(ctx, env.user))
+}
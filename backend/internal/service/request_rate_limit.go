@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// RequestRateLimitWindowSeconds RPM/TPM 滑动窗口长度（秒）
+const RequestRateLimitWindowSeconds = 60
+
+// RequestRateLimitScope 限流维度
+type RequestRateLimitScope string
+
+const (
+	RequestRateLimitScopeAPIKey RequestRateLimitScope = "api_key"
+	RequestRateLimitScopeUser   RequestRateLimitScope = "user"
+	RequestRateLimitScopeGroup  RequestRateLimitScope = "group"
+)
+
+var (
+	ErrUserRPMExceeded  = infraerrors.TooManyRequests("USER_RPM_EXCEEDED", "requests per minute limit of this user exceeded")
+	ErrUserTPMExceeded  = infraerrors.TooManyRequests("USER_TPM_EXCEEDED", "tokens per minute limit of this user exceeded")
+	ErrGroupRPMExceeded = infraerrors.TooManyRequests("GROUP_RPM_EXCEEDED", "requests per minute limit of this group exceeded")
+	ErrGroupTPMExceeded = infraerrors.TooManyRequests("GROUP_TPM_EXCEEDED", "tokens per minute limit of this group exceeded")
+)
+
+// RequestRateLimitRule 单个维度的 RPM/TPM 限制，0 表示该项不限制
+type RequestRateLimitRule struct {
+	Scope     RequestRateLimitScope
+	SubjectID int64
+	RPM       int
+	TPM       int
+}
+
+// RequestRateLimitWindow 单个维度在滑动窗口内的状态
+type RequestRateLimitWindow struct {
+	Allowed bool
+	// Requests / Tokens 窗口内已计入的请求数与 token 数（允许时包含本次请求）
+	Requests int
+	Tokens   int
+	// ResetRequests / ResetTokens 窗口内最早的计数移出窗口（额度开始恢复）所需时间
+	ResetRequests time.Duration
+	ResetTokens   time.Duration
+	// RetryAfter 被拒绝时，用量回落到上限以下所需时间
+	RetryAfter time.Duration
+	// RequestsExceeded 被拒绝的原因是 RPM（否则为 TPM）
+	RequestsExceeded bool
+	// Bucket 本次请求计入的秒级时间桶，用于回滚
+	Bucket int64
+}
+
+// RequestRateLimitCache 基于 Redis 的 RPM/TPM 滑动窗口计数器
+type RequestRateLimitCache interface {
+	// Acquire 原子检查窗口内的请求数与 token 数，未超限时计入本次请求
+	Acquire(ctx context.Context, rule RequestRateLimitRule) (*RequestRateLimitWindow, error)
+	// Release 回滚 Acquire 计入的请求（后续维度拒绝时调用）
+	Release(ctx context.Context, rule RequestRateLimitRule, bucket int64) error
+	// AddTokens 将请求实际消耗的 token 数计入窗口
+	AddTokens(ctx context.Context, rule RequestRateLimitRule, tokens int) error
+}
+
+// RequestRateLimitStatus 本次请求在所有维度中最紧的额度，用于 x-ratelimit-* 响应头
+type RequestRateLimitStatus struct {
+	HasRequests       bool
+	LimitRequests     int
+	RemainingRequests int
+	ResetRequests     time.Duration
+
+	HasTokens       bool
+	LimitTokens     int
+	RemainingTokens int
+	ResetTokens     time.Duration
+
+	// 超限时的维度、指标与建议重试时间
+	ExceededScope  RequestRateLimitScope
+	ExceededMetric string
+	RetryAfter     time.Duration
+}
+
+// RequestRateLimitService Key / 用户 / 分组三级 RPM/TPM 限流
+type RequestRateLimitService struct {
+	cache RequestRateLimitCache
+}
+
+// NewRequestRateLimitService 创建请求速率限流服务
+func NewRequestRateLimitService(cache RequestRateLimitCache) *RequestRateLimitService {
+	return &RequestRateLimitService{cache: cache}
+}
+
+// RequestRateLimitRules 汇总 API Key 生效的限流规则（顺序：Key → 用户 → 分组）
+func RequestRateLimitRules(apiKey *APIKey) []RequestRateLimitRule {
+	if apiKey == nil {
+		return nil
+	}
+	var rules []RequestRateLimitRule
+	if apiKey.Policy != nil && (apiKey.Policy.RPM > 0 || apiKey.Policy.TPM > 0) {
+		rules = append(rules, RequestRateLimitRule{Scope: RequestRateLimitScopeAPIKey, SubjectID: apiKey.ID, RPM: apiKey.Policy.RPM, TPM: apiKey.Policy.TPM})
+	}
+	if apiKey.User != nil && (apiKey.User.RPMLimit > 0 || apiKey.User.TPMLimit > 0) {
+		rules = append(rules, RequestRateLimitRule{Scope: RequestRateLimitScopeUser, SubjectID: apiKey.User.ID, RPM: apiKey.User.RPMLimit, TPM: apiKey.User.TPMLimit})
+	}
+	if apiKey.Group != nil && (apiKey.Group.RPMLimit > 0 || apiKey.Group.TPMLimit > 0) {
+		rules = append(rules, RequestRateLimitRule{Scope: RequestRateLimitScopeGroup, SubjectID: apiKey.Group.ID, RPM: apiKey.Group.RPMLimit, TPM: apiKey.Group.TPMLimit})
+	}
+	return rules
+}
+
+// Acquire 依次检查各维度并计入本次请求；任一维度超限时回滚已计入的维度并返回 429 错误。
+// Redis 故障时放行（fail-open），对应维度不参与响应头计算。
+// 未配置任何限制时返回 nil 状态。
+func (s *RequestRateLimitService) Acquire(ctx context.Context, apiKey *APIKey) (*RequestRateLimitStatus, error) {
+	rules := RequestRateLimitRules(apiKey)
+	if len(rules) == 0 || s == nil || s.cache == nil {
+		return nil, nil
+	}
+
+	status := &RequestRateLimitStatus{}
+	type acquired struct {
+		rule   RequestRateLimitRule
+		bucket int64
+	}
+	granted := make([]acquired, 0, len(rules))
+
+	for _, rule := range rules {
+		window, err := s.cache.Acquire(ctx, rule)
+		if err != nil {
+			logger.LegacyPrintf("service.request_rate_limit", "[RequestRateLimit] acquire failed (fail-open): scope=%s id=%d err=%v", rule.Scope, rule.SubjectID, err)
+			continue
+		}
+		status.merge(rule, window)
+		if !window.Allowed {
+			for _, g := range granted {
+				if err := s.cache.Release(ctx, g.rule, g.bucket); err != nil {
+					logger.LegacyPrintf("service.request_rate_limit", "[RequestRateLimit] release failed: scope=%s id=%d err=%v", g.rule.Scope, g.rule.SubjectID, err)
+				}
+			}
+			metric := "tpm"
+			limit := rule.TPM
+			if window.RequestsExceeded {
+				metric = "rpm"
+				limit = rule.RPM
+			}
+			status.ExceededScope = rule.Scope
+			status.ExceededMetric = metric
+			status.RetryAfter = window.RetryAfter
+			return status, requestRateLimitError(rule.Scope, metric, limit)
+		}
+		granted = append(granted, acquired{rule: rule, bucket: window.Bucket})
+	}
+	return status, nil
+}
+
+// RecordTokens 请求完成后将实际 token 用量计入配置了 TPM 的维度
+func (s *RequestRateLimitService) RecordTokens(ctx context.Context, apiKey *APIKey, tokens int) {
+	if s == nil || s.cache == nil || tokens <= 0 {
+		return
+	}
+	for _, rule := range RequestRateLimitRules(apiKey) {
+		if rule.TPM <= 0 {
+			continue
+		}
+		if err := s.cache.AddTokens(ctx, rule, tokens); err != nil {
+			logger.LegacyPrintf("service.request_rate_limit", "[RequestRateLimit] record tokens failed: scope=%s id=%d err=%v", rule.Scope, rule.SubjectID, err)
+		}
+	}
+}
+
+// merge 以剩余额度最少的维度作为对外展示的额度
+func (st *RequestRateLimitStatus) merge(rule RequestRateLimitRule, window *RequestRateLimitWindow) {
+	if rule.RPM > 0 {
+		remaining := max(rule.RPM-window.Requests, 0)
+		if !st.HasRequests || remaining < st.RemainingRequests {
+			st.HasRequests = true
+			st.LimitRequests = rule.RPM
+			st.RemainingRequests = remaining
+			st.ResetRequests = window.ResetRequests
+		}
+	}
+	if rule.TPM > 0 {
+		remaining := max(rule.TPM-window.Tokens, 0)
+		if !st.HasTokens || remaining < st.RemainingTokens {
+			st.HasTokens = true
+			st.LimitTokens = rule.TPM
+			st.RemainingTokens = remaining
+			st.ResetTokens = window.ResetTokens
+		}
+	}
+}
+
+func requestRateLimitError(scope RequestRateLimitScope, metric string, limit int) error {
+	var base *infraerrors.ApplicationError
+	var subject string
+	switch scope {
+	case RequestRateLimitScopeUser:
+		base, subject = ErrUserRPMExceeded, "user"
+		if metric == "tpm" {
+			base = ErrUserTPMExceeded
+		}
+	case RequestRateLimitScopeGroup:
+		base, subject = ErrGroupRPMExceeded, "group"
+		if metric == "tpm" {
+			base = ErrGroupTPMExceeded
+		}
+	default:
+		base, subject = ErrAPIKeyRPMExceeded, "API key"
+		if metric == "tpm" {
+			base = ErrAPIKeyTPMExceeded
+		}
+	}
+	unit := "requests"
+	if metric == "tpm" {
+		unit = "tokens"
+	}
+	return policyError(base, "%s per minute limit of this %s exceeded (%d)", unit, subject, limit)
+}
+
+// SetRequestRateLimiter sets the optional key/user/group RPM/TPM limiter.
+func (s *APIKeyService) SetRequestRateLimiter(limiter *RequestRateLimitService) {
+	s.rateLimiter = limiter
+}
+
+// AcquireRequestRateLimit 在认证阶段按 Key → 用户 → 分组检查 RPM/TPM 并计入本次请求
+func (s *APIKeyService) AcquireRequestRateLimit(ctx context.Context, apiKey *APIKey) (*RequestRateLimitStatus, error) {
+	return s.rateLimiter.Acquire(ctx, apiKey)
+}
+
+// RecordRequestTokenUsage 记录请求实际消耗的 token 数，供 TPM 校验使用
+func (s *APIKeyService) RecordRequestTokenUsage(ctx context.Context, apiKey *APIKey, tokens int) {
+	s.rateLimiter.RecordTokens(ctx, apiKey, tokens)
+}
+
+// RequestRateLimitUsageRecorder 记录 TPM 用量（由 APIKeyService 实现）
+type RequestRateLimitUsageRecorder interface {
+	RecordRequestTokenUsage(ctx context.Context, apiKey *APIKey, tokens int)
+}
+
+// recordRequestRateLimitUsage 使用量入库后累加各维度 TPM 计数（简易模式同样生效）
+func recordRequestRateLimitUsage(ctx context.Context, updater APIKeyQuotaUpdater, apiKey *APIKey, tokens int) {
+	if recorder, ok := updater.(RequestRateLimitUsageRecorder); ok {
+		recorder.RecordRequestTokenUsage(ctx, apiKey, tokens)
+	}
+}
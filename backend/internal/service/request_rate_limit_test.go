@@ -0,0 +1,118 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type requestRateLimitCacheStub struct {
+	windows  map[RequestRateLimitScope]*RequestRateLimitWindow
+	errs     map[RequestRateLimitScope]error
+	released []RequestRateLimitScope
+	tokens   map[RequestRateLimitScope]int
+}
+
+func (s *requestRateLimitCacheStub) Acquire(ctx context.Context, rule RequestRateLimitRule) (*RequestRateLimitWindow, error) {
+	if err := s.errs[rule.Scope]; err != nil {
+		return nil, err
+	}
+	if w, ok := s.windows[rule.Scope]; ok {
+		return w, nil
+	}
+	return &RequestRateLimitWindow{Allowed: true, Requests: 1, Bucket: 1}, nil
+}
+
+func (s *requestRateLimitCacheStub) Release(ctx context.Context, rule RequestRateLimitRule, bucket int64) error {
+	s.released = append(s.released, rule.Scope)
+	return nil
+}
+
+func (s *requestRateLimitCacheStub) AddTokens(ctx context.Context, rule RequestRateLimitRule, tokens int) error {
+	if s.tokens == nil {
+		s.tokens = map[RequestRateLimitScope]int{}
+	}
+	s.tokens[rule.Scope] += tokens
+	return nil
+}
+
+func newRateLimitedAPIKey() *APIKey {
+	return &APIKey{
+		ID:     1,
+		Policy: &APIKeyPolicy{RPM: 100},
+		User:   &User{ID: 2, RPMLimit: 10, TPMLimit: 5000},
+		Group:  &Group{ID: 3, TPMLimit: 100000},
+	}
+}
+
+func TestRequestRateLimitRules(t *testing.T) {
+	rules := RequestRateLimitRules(newRateLimitedAPIKey())
+	require.Len(t, rules, 3)
+	require.Equal(t, RequestRateLimitScopeAPIKey, rules[0].Scope)
+	require.Equal(t, RequestRateLimitRule{Scope: RequestRateLimitScopeUser, SubjectID: 2, RPM: 10, TPM: 5000}, rules[1])
+	require.Equal(t, RequestRateLimitScopeGroup, rules[2].Scope)
+
+	require.Empty(t, RequestRateLimitRules(&APIKey{ID: 1, User: &User{ID: 2}, Policy: &APIKeyPolicy{DenyTools: true}}))
+}
+
+func TestRequestRateLimitService_AcquireReportsTightestScope(t *testing.T) {
+	cache := &requestRateLimitCacheStub{windows: map[RequestRateLimitScope]*RequestRateLimitWindow{
+		RequestRateLimitScopeAPIKey: {Allowed: true, Requests: 20, ResetRequests: 40 * time.Second},
+		RequestRateLimitScopeUser:   {Allowed: true, Requests: 8, Tokens: 4000, ResetRequests: 10 * time.Second, ResetTokens: 5 * time.Second},
+		RequestRateLimitScopeGroup:  {Allowed: true, Requests: 50, Tokens: 1000},
+	}}
+	svc := NewRequestRateLimitService(cache)
+
+	status, err := svc.Acquire(context.Background(), newRateLimitedAPIKey())
+	require.NoError(t, err)
+	require.Equal(t, 10, status.LimitRequests)
+	require.Equal(t, 2, status.RemainingRequests)
+	require.Equal(t, 10*time.Second, status.ResetRequests)
+	require.Equal(t, 5000, status.LimitTokens)
+	require.Equal(t, 1000, status.RemainingTokens)
+	require.Empty(t, cache.released)
+}
+
+func TestRequestRateLimitService_AcquireRejectsAndReleases(t *testing.T) {
+	cache := &requestRateLimitCacheStub{windows: map[RequestRateLimitScope]*RequestRateLimitWindow{
+		RequestRateLimitScopeGroup: {Allowed: false, Tokens: 100000, RetryAfter: 12 * time.Second},
+	}}
+	svc := NewRequestRateLimitService(cache)
+
+	status, err := svc.Acquire(context.Background(), newRateLimitedAPIKey())
+	require.ErrorIs(t, err, ErrGroupTPMExceeded)
+	require.Equal(t, 429, infraerrors.Code(err))
+	require.Equal(t, RequestRateLimitScopeGroup, status.ExceededScope)
+	require.Equal(t, "tpm", status.ExceededMetric)
+	require.Equal(t, 12*time.Second, status.RetryAfter)
+	require.Equal(t, []RequestRateLimitScope{RequestRateLimitScopeAPIKey, RequestRateLimitScopeUser}, cache.released)
+}
+
+func TestRequestRateLimitService_FailOpenAndRecordTokens(t *testing.T) {
+	cache := &requestRateLimitCacheStub{errs: map[RequestRateLimitScope]error{
+		RequestRateLimitScopeUser: errors.New("redis down"),
+	}}
+	svc := NewRequestRateLimitService(cache)
+	apiKey := newRateLimitedAPIKey()
+
+	status, err := svc.Acquire(context.Background(), apiKey)
+	require.NoError(t, err)
+	require.Equal(t, 100, status.LimitRequests)
+
+	// 仅配置了 TPM 的维度记录 token 用量
+	apiKeySvc := &APIKeyService{}
+	apiKeySvc.SetRequestRateLimiter(svc)
+	recordRequestRateLimitUsage(context.Background(), apiKeySvc, apiKey, 300)
+	require.Equal(t, map[RequestRateLimitScope]int{RequestRateLimitScopeUser: 300, RequestRateLimitScopeGroup: 300}, cache.tokens)
+
+	// 未注入限流器时直接放行
+	status, err = (&APIKeyService{}).AcquireRequestRateLimit(context.Background(), apiKey)
+	require.NoError(t, err)
+	require.Nil(t, status)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+type ResponseCacheConfig = domain.ResponseCacheConfig
+
+const (
+	ResponseCacheScopeAPIKey = domain.ResponseCacheScopeAPIKey
+	ResponseCacheScopeGroup  = domain.ResponseCacheScopeGroup
+)
+
+var ErrResponseCacheInvalid = domain.ErrResponseCacheInvalid
+
+func NormalizeResponseCacheConfig(cfg *ResponseCacheConfig) (*ResponseCacheConfig, error) {
+	return domain.NormalizeResponseCacheConfig(cfg)
+}
+
+const (
+	// ResponseCacheHeader 请求头取值 bypass 时跳过缓存；响应头返回 HIT / MISS / BYPASS
+	ResponseCacheHeader = "X-Response-Cache"
+	ResponseCacheBypass = "bypass"
+
+	ResponseCacheStatusHit    = "HIT"
+	ResponseCacheStatusMiss   = "MISS"
+	ResponseCacheStatusBypass = "BYPASS"
+)
+
+// responseCacheKeyIgnoredFields 不参与缓存键计算的请求字段（不影响模型输出）
+var responseCacheKeyIgnoredFields = []string{"metadata"}
+
+// ResponseCacheEntry 缓存的上游响应：原样回放的响应体与计费所需的用量
+type ResponseCacheEntry struct {
+	Body        []byte      `json:"body"`
+	ContentType string      `json:"content_type"`
+	Stream      bool        `json:"stream"`
+	Model       string      `json:"model"`
+	Usage       ClaudeUsage `json:"usage"`
+	// AccountID 生成该响应的上游账号（使用日志关联用，命中不计入账号用量）
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResponseCacheStore 响应缓存存储（Redis，按 TTL 过期）
+type ResponseCacheStore interface {
+	// Get 未命中时返回 nil, nil
+	Get(ctx context.Context, key string) (*ResponseCacheEntry, error)
+	// Set 写入条目并计入 scope 的总量，超过 maxTotalBytes 时淘汰该 scope 最早写入的条目
+	Set(ctx context.Context, scope, key string, entry *ResponseCacheEntry, ttl time.Duration, maxTotalBytes int) error
+}
+
+// ResponseCacheLookup 单个请求的缓存上下文
+type ResponseCacheLookup struct {
+	// Scope 共享范围标识（k{apiKeyID} 或 g{groupID}），总量上限按此统计
+	Scope  string
+	Key    string
+	Config ResponseCacheConfig
+}
+
+// ResponseCacheHit 响应缓存命中的计费参数
+type ResponseCacheHit struct {
+	// PriceRatio 命中费用相对原费用的比例
+	PriceRatio float64
+}
+
+// apply 按命中比例折算费用
+func (h *ResponseCacheHit) apply(cost *CostBreakdown) *CostBreakdown {
+	ratio := h.PriceRatio
+	return &CostBreakdown{
+		InputCost:         cost.InputCost * ratio,
+		OutputCost:        cost.OutputCost * ratio,
+		CacheCreationCost: cost.CacheCreationCost * ratio,
+		CacheReadCost:     cost.CacheReadCost * ratio,
+		TotalCost:         cost.TotalCost * ratio,
+		ActualCost:        cost.ActualCost * ratio,
+	}
+}
+
+// ResponseCacheService 网关响应缓存：对确定性请求做精确匹配回放
+type ResponseCacheService struct {
+	store ResponseCacheStore
+}
+
+func NewResponseCacheService(store ResponseCacheStore) *ResponseCacheService {
+	return &ResponseCacheService{store: store}
+}
+
+// ResolveResponseCacheConfig 解析请求生效的缓存配置：分组配置优先，其次 API Key 策略开启时的默认配置
+func ResolveResponseCacheConfig(apiKey *APIKey) *ResponseCacheConfig {
+	if apiKey == nil {
+		return nil
+	}
+	if apiKey.Group != nil && apiKey.Group.ResponseCache != nil && apiKey.Group.ResponseCache.Enabled {
+		return apiKey.Group.ResponseCache
+	}
+	if apiKey.Policy != nil && apiKey.Policy.ResponseCache {
+		return domain.DefaultResponseCacheConfig()
+	}
+	return nil
+}
+
+// Prepare 判断请求是否可缓存并计算缓存键；不可缓存时返回 nil。
+// anthropicBeta 为请求的 anthropic-beta 头，会改变上游输出，参与缓存键计算
+func (s *ResponseCacheService) Prepare(apiKey *APIKey, body []byte, anthropicBeta string) *ResponseCacheLookup {
+	if s == nil || s.store == nil {
+		return nil
+	}
+	cfg := ResolveResponseCacheConfig(apiKey)
+	if cfg == nil || !IsDeterministicRequest(body) {
+		return nil
+	}
+	scope := "k" + strconv.FormatInt(apiKey.ID, 10)
+	if cfg.Scope == ResponseCacheScopeGroup && apiKey.GroupID != nil {
+		scope = "g" + strconv.FormatInt(*apiKey.GroupID, 10)
+	}
+	digest, ok := ResponseCacheDigest(body, anthropicBeta)
+	if !ok {
+		return nil
+	}
+	return &ResponseCacheLookup{Scope: scope, Key: scope + ":" + digest, Config: *cfg}
+}
+
+// Get 查询缓存，存储异常视为未命中
+func (s *ResponseCacheService) Get(ctx context.Context, lookup *ResponseCacheLookup) *ResponseCacheEntry {
+	if s == nil || lookup == nil {
+		return nil
+	}
+	entry, err := s.store.Get(ctx, lookup.Key)
+	if err != nil {
+		logger.L().Warn("response_cache.get_failed", zap.Error(err))
+		return nil
+	}
+	return entry
+}
+
+// Store 写入缓存；超过单条大小上限的响应不缓存，范围内总量超限时由存储淘汰最早的条目
+func (s *ResponseCacheService) Store(ctx context.Context, lookup *ResponseCacheLookup, entry *ResponseCacheEntry) {
+	if s == nil || lookup == nil || entry == nil || len(entry.Body) == 0 {
+		return
+	}
+	if len(entry.Body) > lookup.Config.MaxEntryBytes {
+		return
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	ttl := time.Duration(lookup.Config.TTLSeconds) * time.Second
+	if err := s.store.Set(ctx, lookup.Scope, lookup.Key, entry, ttl, lookup.Config.MaxTotalBytes); err != nil {
+		logger.L().Warn("response_cache.set_failed", zap.Error(err))
+	}
+}
+
+// IsDeterministicRequest 仅 temperature 显式为 0 的请求视为确定性请求
+func IsDeterministicRequest(body []byte) bool {
+	temperature := gjson.GetBytes(body, "temperature")
+	return temperature.Type == gjson.Number && temperature.Float() == 0
+}
+
+// ResponseCacheDigest 计算规范化请求体与 anthropic-beta 头的摘要：去除无关字段后按键排序重新序列化，
+// 使字段顺序与空白不同但语义相同的请求命中同一条缓存；beta 特性按排序后的集合参与计算
+func ResponseCacheDigest(body []byte, anthropicBeta string) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil || fields == nil {
+		return "", false
+	}
+	for _, name := range responseCacheKeyIgnoredFields {
+		delete(fields, name)
+	}
+	if model, ok := fields["model"].(string); ok {
+		fields["model"] = strings.ToLower(strings.TrimSpace(model))
+	}
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", false
+	}
+	hasher := sha256.New()
+	hasher.Write(canonical)
+	if betas := normalizeResponseCacheBetas(anthropicBeta); betas != "" {
+		hasher.Write([]byte("\nanthropic-beta:" + betas))
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), true
+}
+
+// normalizeResponseCacheBetas 去重并排序 anthropic-beta 特性列表，使顺序不同的同一组特性命中同一条缓存
+func normalizeResponseCacheBetas(header string) string {
+	seen := make(map[string]struct{})
+	betas := make([]string, 0)
+	for _, part := range strings.Split(header, ",") {
+		beta := strings.ToLower(strings.TrimSpace(part))
+		if beta == "" {
+			continue
+		}
+		if _, ok := seen[beta]; ok {
+			continue
+		}
+		seen[beta] = struct{}{}
+		betas = append(betas, beta)
+	}
+	sort.Strings(betas)
+	return strings.Join(betas, ",")
+}
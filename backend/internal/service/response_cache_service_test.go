@@ -0,0 +1,139 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+type responseCacheStoreStub struct {
+	entries   map[string]*ResponseCacheEntry
+	ttls      map[string]time.Duration
+	scopes    map[string]string
+	maxTotals map[string]int
+}
+
+func newResponseCacheStoreStub() *responseCacheStoreStub {
+	return &responseCacheStoreStub{
+		entries:   map[string]*ResponseCacheEntry{},
+		ttls:      map[string]time.Duration{},
+		scopes:    map[string]string{},
+		maxTotals: map[string]int{},
+	}
+}
+
+func (s *responseCacheStoreStub) Get(_ context.Context, key string) (*ResponseCacheEntry, error) {
+	return s.entries[key], nil
+}
+
+func (s *responseCacheStoreStub) Set(_ context.Context, scope, key string, entry *ResponseCacheEntry, ttl time.Duration, maxTotalBytes int) error {
+	s.entries[key] = entry
+	s.ttls[key] = ttl
+	s.scopes[key] = scope
+	s.maxTotals[key] = maxTotalBytes
+	return nil
+}
+
+func TestResponseCacheDigest_IgnoresOrderWhitespaceAndMetadata(t *testing.T) {
+	a, ok := ResponseCacheDigest([]byte(`{"model":"claude-sonnet-4-5","temperature":0,"messages":[{"role":"user","content":"hi"}],"metadata":{"user_id":"a"}}`), "")
+	require.True(t, ok)
+	b, ok := ResponseCacheDigest([]byte(`{ "messages": [ {"content":"hi","role":"user"} ], "temperature": 0, "model": "Claude-Sonnet-4-5", "metadata": {"user_id":"b"} }`), "")
+	require.True(t, ok)
+	require.Equal(t, a, b)
+
+	c, ok := ResponseCacheDigest([]byte(`{"model":"claude-sonnet-4-5","temperature":0,"messages":[{"role":"user","content":"hello"}]}`), "")
+	require.True(t, ok)
+	require.NotEqual(t, a, c)
+
+	streamed, ok := ResponseCacheDigest([]byte(`{"model":"claude-sonnet-4-5","temperature":0,"stream":true,"messages":[{"role":"user","content":"hi"}]}`), "")
+	require.True(t, ok)
+	require.NotEqual(t, a, streamed)
+
+	_, ok = ResponseCacheDigest([]byte(`not json`), "")
+	require.False(t, ok)
+}
+
+func TestResponseCacheDigest_IncludesAnthropicBeta(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-5","temperature":0,"messages":[{"role":"user","content":"hi"}]}`)
+	plain, ok := ResponseCacheDigest(body, "")
+	require.True(t, ok)
+	beta, ok := ResponseCacheDigest(body, "interleaved-thinking-2025-05-14,context-1m-2025-08-07")
+	require.True(t, ok)
+	require.NotEqual(t, plain, beta)
+
+	reordered, ok := ResponseCacheDigest(body, " context-1m-2025-08-07 , Interleaved-Thinking-2025-05-14")
+	require.True(t, ok)
+	require.Equal(t, beta, reordered, "beta 特性顺序与大小写不影响缓存键")
+	blank, ok := ResponseCacheDigest(body, " , ")
+	require.True(t, ok)
+	require.Equal(t, plain, blank)
+}
+
+func TestIsDeterministicRequest(t *testing.T) {
+	require.True(t, IsDeterministicRequest([]byte(`{"temperature":0}`)))
+	require.True(t, IsDeterministicRequest([]byte(`{"temperature":0.0}`)))
+	require.False(t, IsDeterministicRequest([]byte(`{"temperature":0.7}`)))
+	require.False(t, IsDeterministicRequest([]byte(`{"temperature":"0"}`)))
+	require.False(t, IsDeterministicRequest([]byte(`{"model":"claude"}`)))
+}
+
+func TestResponseCacheService_PrepareScope(t *testing.T) {
+	svc := NewResponseCacheService(newResponseCacheStoreStub())
+	body := []byte(`{"model":"claude-sonnet-4-5","temperature":0,"messages":[]}`)
+	groupID := int64(7)
+
+	require.Nil(t, svc.Prepare(&APIKey{ID: 1}, body, ""), "disabled cache should not prepare a lookup")
+
+	policyKey := &APIKey{ID: 1, Policy: &APIKeyPolicy{ResponseCache: true}}
+	lookup := svc.Prepare(policyKey, body, "")
+	require.NotNil(t, lookup)
+	require.Contains(t, lookup.Key, "k1:")
+	require.Equal(t, "k1", lookup.Scope)
+	require.Equal(t, domain.DefaultResponseCacheMaxTotalBytes, lookup.Config.MaxTotalBytes)
+	require.Equal(t, domain.DefaultResponseCacheHitPriceRatio, lookup.Config.HitPriceRatio)
+	require.Nil(t, svc.Prepare(policyKey, []byte(`{"model":"claude-sonnet-4-5","temperature":1}`), ""))
+
+	groupKey := &APIKey{ID: 2, GroupID: &groupID, Group: &Group{ID: groupID, ResponseCache: &ResponseCacheConfig{
+		Enabled: true, TTLSeconds: 60, MaxEntryBytes: 1024, HitPriceRatio: 0.5, Scope: ResponseCacheScopeGroup,
+	}}}
+	lookup = svc.Prepare(groupKey, body, "")
+	require.NotNil(t, lookup)
+	require.Contains(t, lookup.Key, "g7:")
+	require.Equal(t, "g7", lookup.Scope)
+	require.Equal(t, 0.5, lookup.Config.HitPriceRatio)
+
+	require.Nil(t, (*ResponseCacheService)(nil).Prepare(groupKey, body, ""))
+}
+
+func TestResponseCacheService_StoreRespectsSizeCapAndTTL(t *testing.T) {
+	store := newResponseCacheStoreStub()
+	svc := NewResponseCacheService(store)
+	lookup := &ResponseCacheLookup{Scope: "k1", Key: "k1:abc", Config: ResponseCacheConfig{Enabled: true, TTLSeconds: 60, MaxEntryBytes: 8, MaxTotalBytes: 64}}
+
+	svc.Store(context.Background(), lookup, &ResponseCacheEntry{Body: []byte("0123456789")})
+	require.Nil(t, svc.Get(context.Background(), lookup))
+
+	svc.Store(context.Background(), lookup, &ResponseCacheEntry{Body: []byte("{}"), Model: "claude"})
+	entry := svc.Get(context.Background(), lookup)
+	require.NotNil(t, entry)
+	require.Equal(t, "claude", entry.Model)
+	require.False(t, entry.CreatedAt.IsZero())
+	require.Equal(t, time.Minute, store.ttls[lookup.Key])
+	require.Equal(t, "k1", store.scopes[lookup.Key])
+	require.Equal(t, 64, store.maxTotals[lookup.Key])
+}
+
+func TestResponseCacheHit_ApplyScalesCost(t *testing.T) {
+	hit := &ResponseCacheHit{PriceRatio: 0.1}
+	cost := hit.apply(&CostBreakdown{InputCost: 1, OutputCost: 2, CacheReadCost: 0.5, TotalCost: 3.5, ActualCost: 7})
+	require.InDelta(t, 0.1, cost.InputCost, 1e-9)
+	require.InDelta(t, 0.2, cost.OutputCost, 1e-9)
+	require.InDelta(t, 0.05, cost.CacheReadCost, 1e-9)
+	require.InDelta(t, 0.35, cost.TotalCost, 1e-9)
+	require.InDelta(t, 0.7, cost.ActualCost, 1e-9)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// routeTemplateRulePattern 路由规则：可选 HTTP 方法 + 相对路由模板，末尾 "/*" 表示前缀匹配。
+// 例如 "POST keys/:id/rotate"、"users/:id"、"ops/*"。
+var routeTemplateRulePattern = regexp.MustCompile(`^(?:(GET|POST|PUT|PATCH|DELETE) )?(:?[a-z0-9_\-]+(?:/:?[a-z0-9_\-]+)*(?:/\*)?)$`)
+
+// matchRouteTemplate 判断请求方法与 gin 路由模板（如 "keys/:id"）是否命中路由规则
+func matchRouteTemplate(rule, method, route string) bool {
+	m := routeTemplateRulePattern.FindStringSubmatch(rule)
+	if m == nil {
+		return false
+	}
+	if m[1] != "" && !strings.EqualFold(m[1], method) {
+		return false
+	}
+	pattern := m[2]
+	route = strings.Trim(route, "/")
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return route == prefix || strings.HasPrefix(route, prefix+"/")
+	}
+	return route == pattern
+}
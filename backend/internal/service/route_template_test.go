@@ -0,0 +1,21 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRouteTemplate(t *testing.T) {
+	require.True(t, matchRouteTemplate("keys", "GET", "keys"))
+	require.True(t, matchRouteTemplate("POST keys/:id/rotate", "post", "/keys/:id/rotate/"))
+	require.False(t, matchRouteTemplate("POST keys/:id/rotate", "PUT", "keys/:id/rotate"))
+	require.True(t, matchRouteTemplate("user/passkeys/*", "POST", "user/passkeys"))
+	require.True(t, matchRouteTemplate("user/passkeys/*", "POST", "user/passkeys/:id/delete"))
+	require.False(t, matchRouteTemplate("user/passkeys/*", "POST", "user/passkeysx"))
+	require.False(t, matchRouteTemplate("FETCH keys", "GET", "keys"), "非法规则不匹配")
+	// 通用匹配不含 Admin API Key 的受保护路由排除
+	require.True(t, matchRouteTemplate("settings/*", "POST", "settings/admin-api-keys"))
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var (
+	ErrOIDCProviderNotFound = infraerrors.NotFound("OIDC_PROVIDER_NOT_FOUND", "oidc provider not found")
+	ErrOIDCProviderInvalid  = infraerrors.BadRequest("OIDC_PROVIDER_INVALID", "invalid oidc provider config")
+)
+
+// oidcProviderKeyPattern 提供方标识会出现在回调路径与合成邮箱中，限制为小写字母数字及 -_
+var oidcProviderKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ListOIDCProviders 获取全部 OIDC 提供方（不含 client secret）
+func (s *SettingService) ListOIDCProviders(ctx context.Context) ([]OIDCProvider, error) {
+	providers, err := s.loadOIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range providers {
+		providers[i].ClientSecretConfigured = providers[i].ClientSecret != ""
+		providers[i].ClientSecret = ""
+	}
+	return providers, nil
+}
+
+// GetOIDCProvider 获取指定提供方的完整配置（含 client secret，仅供登录流程使用）
+func (s *SettingService) GetOIDCProvider(ctx context.Context, key string) (*OIDCProvider, error) {
+	providers, err := s.loadOIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key = strings.TrimSpace(key)
+	for i := range providers {
+		if providers[i].Key == key {
+			provider := providers[i]
+			provider.ClientSecretConfigured = provider.ClientSecret != ""
+			return &provider, nil
+		}
+	}
+	return nil, ErrOIDCProviderNotFound
+}
+
+// parsePublicOIDCProviders 从原始配置中提取登录页展示的已启用提供方
+func parsePublicOIDCProviders(raw string) []OIDCPublicProvider {
+	out := []OIDCPublicProvider{}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return out
+	}
+	var providers []OIDCProvider
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return out
+	}
+	for _, p := range providers {
+		if p.Enabled {
+			out = append(out, OIDCPublicProvider{Key: p.Key, Name: p.Name})
+		}
+	}
+	return out
+}
+
+// UpsertOIDCProvider 创建或更新提供方；ClientSecret 为空时保留原值
+func (s *SettingService) UpsertOIDCProvider(ctx context.Context, provider *OIDCProvider) (*OIDCProvider, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("provider cannot be nil")
+	}
+	providers, err := s.loadOIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item := normalizeOIDCProvider(*provider)
+	idx := -1
+	for i := range providers {
+		if providers[i].Key == item.Key {
+			idx = i
+			break
+		}
+	}
+	if item.ClientSecret == "" && idx >= 0 {
+		item.ClientSecret = providers[idx].ClientSecret
+	}
+	if err := s.validateOIDCProvider(ctx, &item); err != nil {
+		return nil, err
+	}
+	item.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	if idx >= 0 {
+		providers[idx] = item
+	} else {
+		providers = append(providers, item)
+	}
+	if err := s.persistOIDCProviders(ctx, providers); err != nil {
+		return nil, err
+	}
+
+	item.ClientSecretConfigured = item.ClientSecret != ""
+	item.ClientSecret = ""
+	return &item, nil
+}
+
+// DeleteOIDCProvider 删除提供方（已有的身份绑定保留，重新添加同名提供方后继续生效）
+func (s *SettingService) DeleteOIDCProvider(ctx context.Context, key string) error {
+	providers, err := s.loadOIDCProviders(ctx)
+	if err != nil {
+		return err
+	}
+	key = strings.TrimSpace(key)
+	kept := make([]OIDCProvider, 0, len(providers))
+	for _, p := range providers {
+		if p.Key != key {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(providers) {
+		return ErrOIDCProviderNotFound
+	}
+	return s.persistOIDCProviders(ctx, kept)
+}
+
+func (s *SettingService) loadOIDCProviders(ctx context.Context) ([]OIDCProvider, error) {
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyOIDCProviders)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return []OIDCProvider{}, nil
+		}
+		return nil, fmt.Errorf("get oidc providers: %w", err)
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return []OIDCProvider{}, nil
+	}
+	var providers []OIDCProvider
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return nil, fmt.Errorf("unmarshal oidc providers: %w", err)
+	}
+	return providers, nil
+}
+
+func (s *SettingService) persistOIDCProviders(ctx context.Context, providers []OIDCProvider) error {
+	data, err := json.Marshal(providers)
+	if err != nil {
+		return fmt.Errorf("marshal oidc providers: %w", err)
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyOIDCProviders, string(data)); err != nil {
+		return err
+	}
+	if s.onUpdate != nil {
+		s.onUpdate()
+	}
+	return nil
+}
+
+func normalizeOIDCProvider(p OIDCProvider) OIDCProvider {
+	p.Key = strings.ToLower(strings.TrimSpace(p.Key))
+	p.Name = strings.TrimSpace(p.Name)
+	p.DiscoveryURL = strings.TrimSpace(p.DiscoveryURL)
+	p.Issuer = strings.TrimSpace(p.Issuer)
+	p.AuthorizeURL = strings.TrimSpace(p.AuthorizeURL)
+	p.TokenURL = strings.TrimSpace(p.TokenURL)
+	p.UserInfoURL = strings.TrimSpace(p.UserInfoURL)
+	p.JWKSURL = strings.TrimSpace(p.JWKSURL)
+	p.ClientID = strings.TrimSpace(p.ClientID)
+	p.ClientSecret = strings.TrimSpace(p.ClientSecret)
+	p.TokenAuthMethod = strings.ToLower(strings.TrimSpace(p.TokenAuthMethod))
+	if p.TokenAuthMethod == "" {
+		p.TokenAuthMethod = OIDCTokenAuthClientSecretPost
+	}
+	p.Scopes = strings.Join(strings.Fields(p.Scopes), " ")
+	p.RedirectURL = strings.TrimSpace(p.RedirectURL)
+	p.FrontendRedirectURL = strings.TrimSpace(p.FrontendRedirectURL)
+	p.SubjectClaim = strings.TrimSpace(p.SubjectClaim)
+	p.EmailClaim = strings.TrimSpace(p.EmailClaim)
+	p.EmailVerifiedClaim = strings.TrimSpace(p.EmailVerifiedClaim)
+	p.UsernameClaim = strings.TrimSpace(p.UsernameClaim)
+	p.GroupsClaim = strings.TrimSpace(p.GroupsClaim)
+
+	mappings := make([]OIDCGroupMapping, 0, len(p.GroupMappings))
+	for _, m := range p.GroupMappings {
+		m.IdPGroup = strings.TrimSpace(m.IdPGroup)
+		if m.IdPGroup == "" {
+			continue
+		}
+		mappings = append(mappings, m)
+	}
+	p.GroupMappings = mappings
+	return p
+}
+
+func (s *SettingService) validateOIDCProvider(ctx context.Context, p *OIDCProvider) error {
+	invalid := func(reason string) error {
+		return ErrOIDCProviderInvalid.WithMetadata(map[string]string{"reason": reason})
+	}
+	if !oidcProviderKeyPattern.MatchString(p.Key) {
+		return invalid("key must match ^[a-z0-9][a-z0-9_-]{0,31}$")
+	}
+	if p.Name == "" {
+		return invalid("name is required")
+	}
+	if p.ClientID == "" {
+		return invalid("client_id is required")
+	}
+	if p.DiscoveryURL == "" && (p.AuthorizeURL == "" || p.TokenURL == "") {
+		return invalid("discovery_url or authorize_url/token_url is required")
+	}
+	for name, raw := range map[string]string{
+		"discovery_url": p.DiscoveryURL,
+		"authorize_url": p.AuthorizeURL,
+		"token_url":     p.TokenURL,
+		"userinfo_url":  p.UserInfoURL,
+		"jwks_url":      p.JWKSURL,
+		"redirect_url":  p.RedirectURL,
+	} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return invalid(name + " must be an absolute http(s) url")
+		}
+	}
+	if p.RedirectURL == "" {
+		return invalid("redirect_url is required")
+	}
+	switch p.TokenAuthMethod {
+	case OIDCTokenAuthClientSecretPost, OIDCTokenAuthClientSecretBasic:
+		if p.ClientSecret == "" {
+			return invalid("client_secret is required for " + p.TokenAuthMethod)
+		}
+	case OIDCTokenAuthNone:
+	default:
+		return invalid("token_auth_method must be client_secret_post, client_secret_basic or none")
+	}
+
+	seen := make(map[string]struct{}, len(p.GroupMappings))
+	for _, m := range p.GroupMappings {
+		if _, ok := seen[m.IdPGroup]; ok {
+			return invalid("duplicate group mapping: " + m.IdPGroup)
+		}
+		seen[m.IdPGroup] = struct{}{}
+		if err := s.validateDefaultSubscriptionGroups(ctx, m.Subscriptions); err != nil {
+			return err
+		}
+		if s.defaultSubGroupReader == nil {
+			continue
+		}
+		for _, groupID := range m.AllowedGroupIDs {
+			if _, err := s.defaultSubGroupReader.GetByID(ctx, groupID); err != nil {
+				if errors.Is(err, ErrGroupNotFound) {
+					return invalid("allowed group not found: " + strconv.FormatInt(groupID, 10))
+				}
+				return fmt.Errorf("get mapped group %d: %w", groupID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -113,6 +113,7 @@ func (s *SettingService) GetPublicSettings(ctx context.Context) (*PublicSettings
 		SettingKeyPasswordResetEnabled,
 		SettingKeyInvitationCodeEnabled,
 		SettingKeyTotpEnabled,
+		SettingKeyPasskeyEnabled,
 		SettingKeyTurnstileEnabled,
 		SettingKeyTurnstileSiteKey,
 		SettingKeySiteName,
@@ -128,6 +129,7 @@ func (s *SettingService) GetPublicSettings(ctx context.Context) (*PublicSettings
 		SettingKeySoraClientEnabled,
 		SettingKeyCustomMenuItems,
 		SettingKeyLinuxDoConnectEnabled,
+		SettingKeyOIDCProviders,
 	}
 
 	settings, err := s.settingRepo.GetMultiple(ctx, keys)
@@ -157,6 +159,7 @@ func (s *SettingService) GetPublicSettings(ctx context.Context) (*PublicSettings
 		PasswordResetEnabled:             passwordResetEnabled,
 		InvitationCodeEnabled:            settings[SettingKeyInvitationCodeEnabled] == "true",
 		TotpEnabled:                      settings[SettingKeyTotpEnabled] == "true",
+		PasskeyEnabled:                   settings[SettingKeyPasskeyEnabled] == "true",
 		TurnstileEnabled:                 settings[SettingKeyTurnstileEnabled] == "true",
 		TurnstileSiteKey:                 settings[SettingKeyTurnstileSiteKey],
 		SiteName:                         s.getStringOrDefault(settings, SettingKeySiteName, "Sub2API"),
@@ -172,6 +175,7 @@ func (s *SettingService) GetPublicSettings(ctx context.Context) (*PublicSettings
 		SoraClientEnabled:                settings[SettingKeySoraClientEnabled] == "true",
 		CustomMenuItems:                  settings[SettingKeyCustomMenuItems],
 		LinuxDoOAuthEnabled:              linuxDoEnabled,
+		OIDCProviders:                    parsePublicOIDCProviders(settings[SettingKeyOIDCProviders]),
 	}, nil
 }
 
@@ -201,29 +205,31 @@ func (s *SettingService) GetPublicSettingsForInjection(ctx context.Context) (any
 
 	// Return a struct that matches the frontend's expected format
 	return &struct {
-		RegistrationEnabled              bool            `json:"registration_enabled"`
-		EmailVerifyEnabled               bool            `json:"email_verify_enabled"`
-		RegistrationEmailSuffixWhitelist []string        `json:"registration_email_suffix_whitelist"`
-		PromoCodeEnabled                 bool            `json:"promo_code_enabled"`
-		PasswordResetEnabled             bool            `json:"password_reset_enabled"`
-		InvitationCodeEnabled            bool            `json:"invitation_code_enabled"`
-		TotpEnabled                      bool            `json:"totp_enabled"`
-		TurnstileEnabled                 bool            `json:"turnstile_enabled"`
-		TurnstileSiteKey                 string          `json:"turnstile_site_key,omitempty"`
-		SiteName                         string          `json:"site_name"`
-		SiteLogo                         string          `json:"site_logo,omitempty"`
-		SiteSubtitle                     string          `json:"site_subtitle,omitempty"`
-		APIBaseURL                       string          `json:"api_base_url,omitempty"`
-		ContactInfo                      string          `json:"contact_info,omitempty"`
-		DocURL                           string          `json:"doc_url,omitempty"`
-		HomeContent                      string          `json:"home_content,omitempty"`
-		HideCcsImportButton              bool            `json:"hide_ccs_import_button"`
-		PurchaseSubscriptionEnabled      bool            `json:"purchase_subscription_enabled"`
-		PurchaseSubscriptionURL          string          `json:"purchase_subscription_url,omitempty"`
-		SoraClientEnabled                bool            `json:"sora_client_enabled"`
-		CustomMenuItems                  json.RawMessage `json:"custom_menu_items"`
-		LinuxDoOAuthEnabled              bool            `json:"linuxdo_oauth_enabled"`
-		Version                          string          `json:"version,omitempty"`
+		RegistrationEnabled              bool                 `json:"registration_enabled"`
+		EmailVerifyEnabled               bool                 `json:"email_verify_enabled"`
+		RegistrationEmailSuffixWhitelist []string             `json:"registration_email_suffix_whitelist"`
+		PromoCodeEnabled                 bool                 `json:"promo_code_enabled"`
+		PasswordResetEnabled             bool                 `json:"password_reset_enabled"`
+		InvitationCodeEnabled            bool                 `json:"invitation_code_enabled"`
+		TotpEnabled                      bool                 `json:"totp_enabled"`
+		PasskeyEnabled                   bool                 `json:"passkey_enabled"`
+		TurnstileEnabled                 bool                 `json:"turnstile_enabled"`
+		TurnstileSiteKey                 string               `json:"turnstile_site_key,omitempty"`
+		SiteName                         string               `json:"site_name"`
+		SiteLogo                         string               `json:"site_logo,omitempty"`
+		SiteSubtitle                     string               `json:"site_subtitle,omitempty"`
+		APIBaseURL                       string               `json:"api_base_url,omitempty"`
+		ContactInfo                      string               `json:"contact_info,omitempty"`
+		DocURL                           string               `json:"doc_url,omitempty"`
+		HomeContent                      string               `json:"home_content,omitempty"`
+		HideCcsImportButton              bool                 `json:"hide_ccs_import_button"`
+		PurchaseSubscriptionEnabled      bool                 `json:"purchase_subscription_enabled"`
+		PurchaseSubscriptionURL          string               `json:"purchase_subscription_url,omitempty"`
+		SoraClientEnabled                bool                 `json:"sora_client_enabled"`
+		CustomMenuItems                  json.RawMessage      `json:"custom_menu_items"`
+		LinuxDoOAuthEnabled              bool                 `json:"linuxdo_oauth_enabled"`
+		OIDCProviders                    []OIDCPublicProvider `json:"oidc_providers"`
+		Version                          string               `json:"version,omitempty"`
 	}{
 		RegistrationEnabled:              settings.RegistrationEnabled,
 		EmailVerifyEnabled:               settings.EmailVerifyEnabled,
@@ -232,6 +238,7 @@ func (s *SettingService) GetPublicSettingsForInjection(ctx context.Context) (any
 		PasswordResetEnabled:             settings.PasswordResetEnabled,
 		InvitationCodeEnabled:            settings.InvitationCodeEnabled,
 		TotpEnabled:                      settings.TotpEnabled,
+		PasskeyEnabled:                   settings.PasskeyEnabled,
 		TurnstileEnabled:                 settings.TurnstileEnabled,
 		TurnstileSiteKey:                 settings.TurnstileSiteKey,
 		SiteName:                         settings.SiteName,
@@ -247,6 +254,7 @@ func (s *SettingService) GetPublicSettingsForInjection(ctx context.Context) (any
 		SoraClientEnabled:                settings.SoraClientEnabled,
 		CustomMenuItems:                  filterUserVisibleMenuItems(settings.CustomMenuItems),
 		LinuxDoOAuthEnabled:              settings.LinuxDoOAuthEnabled,
+		OIDCProviders:                    settings.OIDCProviders,
 		Version:                          s.version,
 	}, nil
 }
@@ -386,6 +394,8 @@ func (s *SettingService) UpdateSettings(ctx context.Context, settings *SystemSet
 	updates[SettingKeyPasswordResetEnabled] = strconv.FormatBool(settings.PasswordResetEnabled)
 	updates[SettingKeyInvitationCodeEnabled] = strconv.FormatBool(settings.InvitationCodeEnabled)
 	updates[SettingKeyTotpEnabled] = strconv.FormatBool(settings.TotpEnabled)
+	updates[SettingKeyPasskeyEnabled] = strconv.FormatBool(settings.PasskeyEnabled)
+	updates[SettingKeyAdminRequire2FA] = strconv.FormatBool(settings.AdminRequire2FA)
 
 	// 邮件服务设置（只有非空才更新密码）
 	updates[SettingKeySMTPHost] = settings.SMTPHost
@@ -584,12 +594,36 @@ func (s *SettingService) IsTotpEnabled(ctx context.Context) bool {
 	return value == "true"
 }
 
+// IsPasskeyEnabled 检查是否启用 WebAuthn Passkey 功能
+func (s *SettingService) IsPasskeyEnabled(ctx context.Context) bool {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyPasskeyEnabled)
+	if err != nil {
+		return false // 默认关闭
+	}
+	return value == "true"
+}
+
+// IsAdminRequire2FA 检查是否要求管理员账号启用第二因素（TOTP 或 Passkey）
+func (s *SettingService) IsAdminRequire2FA(ctx context.Context) bool {
+	value, err := s.settingRepo.GetValue(ctx, SettingKeyAdminRequire2FA)
+	if err != nil {
+		return false // 默认关闭
+	}
+	return value == "true"
+}
+
 // IsTotpEncryptionKeyConfigured 检查 TOTP 加密密钥是否已手动配置
 // 只有手动配置了密钥才允许在管理后台启用 TOTP 功能
 func (s *SettingService) IsTotpEncryptionKeyConfigured() bool {
 	return s.cfg.Totp.EncryptionKeyConfigured
 }
 
+// IsWebAuthnConfigured 检查 WebAuthn 依赖方（RP ID 与来源）是否已配置
+// 只有配置了依赖方才允许在管理后台启用 Passkey 功能
+func (s *SettingService) IsWebAuthnConfigured() bool {
+	return s.cfg != nil && s.cfg.WebAuthn.RPID != "" && len(s.cfg.WebAuthn.RPOrigins) > 0
+}
+
 // GetSiteName 获取网站名称
 func (s *SettingService) GetSiteName(ctx context.Context) string {
 	value, err := s.settingRepo.GetValue(ctx, SettingKeySiteName)
@@ -698,6 +732,8 @@ func (s *SettingService) parseSettings(settings map[string]string) *SystemSettin
 		PasswordResetEnabled:             emailVerifyEnabled && settings[SettingKeyPasswordResetEnabled] == "true",
 		InvitationCodeEnabled:            settings[SettingKeyInvitationCodeEnabled] == "true",
 		TotpEnabled:                      settings[SettingKeyTotpEnabled] == "true",
+		PasskeyEnabled:                   settings[SettingKeyPasskeyEnabled] == "true",
+		AdminRequire2FA:                  settings[SettingKeyAdminRequire2FA] == "true",
 		SMTPHost:                         settings[SettingKeySMTPHost],
 		SMTPUsername:                     settings[SettingKeySMTPUsername],
 		SMTPFrom:                         settings[SettingKeySMTPFrom],
@@ -1327,6 +1363,36 @@ func (s *SettingService) GetSoraS3Settings(ctx context.Context) (*SoraS3Settings
 	}, nil
 }
 
+// GetS3ProfileSettings 获取指定 S3 配置（profileID 为空时返回当前激活配置），供归档等非 Sora 场景复用
+func (s *SettingService) GetS3ProfileSettings(ctx context.Context, profileID string) (*SoraS3Settings, string, error) {
+	store, err := s.loadSoraS3ProfilesStore(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	var item *soraS3ProfileStoreItem
+	if profileID = strings.TrimSpace(profileID); profileID == "" {
+		item = pickActiveSoraS3ProfileFromStore(store.Items, store.ActiveProfileID)
+	} else if idx := findSoraS3ProfileIndex(store.Items, profileID); idx >= 0 {
+		item = &store.Items[idx]
+	}
+	if item == nil {
+		return nil, "", ErrSoraS3ProfileNotFound
+	}
+	return &SoraS3Settings{
+		Enabled:                   item.Enabled,
+		Endpoint:                  item.Endpoint,
+		Region:                    item.Region,
+		Bucket:                    item.Bucket,
+		AccessKeyID:               item.AccessKeyID,
+		SecretAccessKey:           item.SecretAccessKey,
+		SecretAccessKeyConfigured: item.SecretAccessKey != "",
+		Prefix:                    item.Prefix,
+		ForcePathStyle:            item.ForcePathStyle,
+		CDNURL:                    item.CDNURL,
+		DefaultStorageQuotaBytes:  item.DefaultStorageQuotaBytes,
+	}, item.ProfileID, nil
+}
+
 // SetSoraS3Settings 更新 Sora S3 存储配置（兼容旧单配置语义：写入当前激活配置）
 func (s *SettingService) SetSoraS3Settings(ctx context.Context, settings *SoraS3Settings) error {
 	if settings == nil {
@@ -8,6 +8,8 @@ type SystemSettings struct {
 	PasswordResetEnabled             bool
 	InvitationCodeEnabled            bool
 	TotpEnabled                      bool // TOTP 双因素认证
+	PasskeyEnabled                   bool // WebAuthn Passkey 登录与 2FA
+	AdminRequire2FA                  bool // 管理员必须启用第二因素
 
 	SMTPHost               string
 	SMTPPort               int
@@ -84,6 +86,7 @@ type PublicSettings struct {
 	PasswordResetEnabled             bool
 	InvitationCodeEnabled            bool
 	TotpEnabled                      bool // TOTP 双因素认证
+	PasskeyEnabled                   bool // WebAuthn Passkey 登录与 2FA
 	TurnstileEnabled                 bool
 	TurnstileSiteKey                 string
 	SiteName                         string
@@ -101,6 +104,7 @@ type PublicSettings struct {
 	CustomMenuItems             string // JSON array of custom menu items
 
 	LinuxDoOAuthEnabled bool
+	OIDCProviders       []OIDCPublicProvider
 	Version             string
 }
 
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"go.uber.org/zap"
+)
+
+type ShadowMirrorConfig = domain.ShadowMirrorConfig
+
+var ErrShadowMirrorInvalid = domain.ErrShadowMirrorInvalid
+
+func NormalizeShadowMirrorConfig(cfg *ShadowMirrorConfig) (*ShadowMirrorConfig, error) {
+	return domain.NormalizeShadowMirrorConfig(cfg)
+}
+
+const (
+	// shadowMirrorMaxInFlight 单实例同时进行的影子请求上限，超出时直接丢弃采样
+	shadowMirrorMaxInFlight = 16
+	shadowMirrorTimeout     = 5 * time.Minute
+	// shadowMirrorCaptureBytes 主/影子响应用于相似度计算的最大捕获字节数
+	shadowMirrorCaptureBytes = 256 * 1024
+)
+
+var shadowMirrorRequestHeaderAllowlist = []string{"anthropic-beta", "anthropic-version", "user-agent"}
+
+// ShadowMirrorResult 一次影子镜像的对比记录
+type ShadowMirrorResult struct {
+	ID                  int64     `json:"id"`
+	GroupID             int64     `json:"group_id"`
+	APIKeyID            int64     `json:"api_key_id"`
+	Model               string    `json:"model"`
+	Stream              bool      `json:"stream"`
+	PrimaryAccountID    int64     `json:"primary_account_id"`
+	ShadowAccountID     *int64    `json:"shadow_account_id"`
+	PrimaryStatus       int       `json:"primary_status"`
+	ShadowStatus        int       `json:"shadow_status"`
+	PrimaryLatencyMs    int64     `json:"primary_latency_ms"`
+	ShadowLatencyMs     int64     `json:"shadow_latency_ms"`
+	PrimaryInputTokens  int       `json:"primary_input_tokens"`
+	PrimaryOutputTokens int       `json:"primary_output_tokens"`
+	ShadowInputTokens   int       `json:"shadow_input_tokens"`
+	ShadowOutputTokens  int       `json:"shadow_output_tokens"`
+	Similarity          *float64  `json:"similarity"`
+	ErrorMessage        string    `json:"error_message"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// ShadowMirrorFilter 影子镜像记录查询条件
+type ShadowMirrorFilter struct {
+	GroupID   int64
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// ShadowMirrorSummary 分组影子镜像汇总对比
+type ShadowMirrorSummary struct {
+	Total                  int64    `json:"total"`
+	ShadowSucceeded        int64    `json:"shadow_succeeded"`
+	ShadowFailed           int64    `json:"shadow_failed"`
+	Skipped                int64    `json:"skipped"`
+	AvgPrimaryLatencyMs    float64  `json:"avg_primary_latency_ms"`
+	AvgShadowLatencyMs     float64  `json:"avg_shadow_latency_ms"`
+	AvgPrimaryOutputTokens float64  `json:"avg_primary_output_tokens"`
+	AvgShadowOutputTokens  float64  `json:"avg_shadow_output_tokens"`
+	AvgSimilarity          *float64 `json:"avg_similarity"`
+}
+
+type ShadowMirrorRepository interface {
+	Create(ctx context.Context, result *ShadowMirrorResult) error
+	List(ctx context.Context, filter ShadowMirrorFilter, params pagination.PaginationParams) ([]ShadowMirrorResult, *pagination.PaginationResult, error)
+	Summary(ctx context.Context, filter ShadowMirrorFilter) (*ShadowMirrorSummary, error)
+}
+
+// ShadowMirrorRequest 主请求完成后提交的镜像任务
+type ShadowMirrorRequest struct {
+	Config           ShadowMirrorConfig
+	GroupID          int64
+	APIKeyID         int64
+	Body             []byte
+	Model            string
+	Stream           bool
+	Headers          http.Header
+	PrimaryAccountID int64
+	PrimaryStatus    int
+	PrimaryLatency   time.Duration
+	PrimaryResult    *ForwardResult
+	// PrimaryOutput 主响应捕获内容（JSON 或 SSE），用于相似度计算
+	PrimaryOutput []byte
+}
+
+// ShadowMirrorService 影子流量镜像：异步复制采样请求到影子账号并记录对比结果。
+//
+// 影子请求复用网关 Forward 路径但写入丢弃型 ResponseWriter，不调用 RecordUsage，
+// 因此不会计费；账号并发槽位按影子账号自身上限非阻塞获取，满载时跳过。
+type ShadowMirrorService struct {
+	repo                      ShadowMirrorRepository
+	accountRepo               AccountRepository
+	gatewayService            *GatewayService
+	antigravityGatewayService *AntigravityGatewayService
+	concurrencyService        *ConcurrencyService
+
+	inFlight chan struct{}
+	sample   func() float64 // 测试注入
+}
+
+func NewShadowMirrorService(
+	repo ShadowMirrorRepository,
+	accountRepo AccountRepository,
+	gatewayService *GatewayService,
+	antigravityGatewayService *AntigravityGatewayService,
+	concurrencyService *ConcurrencyService,
+) *ShadowMirrorService {
+	return &ShadowMirrorService{
+		repo:                      repo,
+		accountRepo:               accountRepo,
+		gatewayService:            gatewayService,
+		antigravityGatewayService: antigravityGatewayService,
+		concurrencyService:        concurrencyService,
+		inFlight:                  make(chan struct{}, shadowMirrorMaxInFlight),
+		sample:                    rand.Float64,
+	}
+}
+
+// ShouldMirror 按分组配置采样决定是否镜像当前请求
+func (s *ShadowMirrorService) ShouldMirror(group *Group) bool {
+	if s == nil || group == nil || group.ShadowMirror == nil || !group.ShadowMirror.Enabled {
+		return false
+	}
+	return s.sample() < group.ShadowMirror.SampleRate
+}
+
+// Submit 异步执行镜像；在途影子请求已满时丢弃本次采样
+func (s *ShadowMirrorService) Submit(req *ShadowMirrorRequest) {
+	if s == nil || req == nil {
+		return
+	}
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		logger.L().Debug("shadow_mirror.dropped_in_flight_full", zap.Int64("group_id", req.GroupID))
+		return
+	}
+	go func() {
+		defer func() { <-s.inFlight }()
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.L().Error("shadow_mirror.panic_recovered", zap.Any("panic", recovered))
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), shadowMirrorTimeout)
+		defer cancel()
+		s.run(ctx, req)
+	}()
+}
+
+func (s *ShadowMirrorService) run(ctx context.Context, req *ShadowMirrorRequest) {
+	result := &ShadowMirrorResult{
+		GroupID:          req.GroupID,
+		APIKeyID:         req.APIKeyID,
+		Model:            req.Model,
+		Stream:           req.Stream,
+		PrimaryAccountID: req.PrimaryAccountID,
+		PrimaryStatus:    req.PrimaryStatus,
+		PrimaryLatencyMs: req.PrimaryLatency.Milliseconds(),
+	}
+	if req.PrimaryResult != nil {
+		result.PrimaryInputTokens = req.PrimaryResult.Usage.InputTokens
+		result.PrimaryOutputTokens = req.PrimaryResult.Usage.OutputTokens
+	}
+
+	account, release, err := s.acquireShadowAccount(ctx, req)
+	if err != nil {
+		result.ErrorMessage = err.Error()
+	} else {
+		accountID := account.ID
+		result.ShadowAccountID = &accountID
+		s.forwardShadow(ctx, req, account, result)
+		release()
+	}
+
+	if err := s.repo.Create(ctx, result); err != nil {
+		logger.L().Warn("shadow_mirror.record_failed", zap.Int64("group_id", req.GroupID), zap.Error(err))
+	}
+}
+
+// acquireShadowAccount 获取影子账号及其并发槽位（非阻塞，满载时返回错误并跳过本次镜像）
+func (s *ShadowMirrorService) acquireShadowAccount(ctx context.Context, req *ShadowMirrorRequest) (*Account, func(), error) {
+	if req.Config.AccountID > 0 {
+		account, err := s.accountRepo.GetByID(ctx, req.Config.AccountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load shadow account: %w", err)
+		}
+		if !account.IsSchedulable() {
+			return nil, nil, fmt.Errorf("shadow account %d is not schedulable", account.ID)
+		}
+		slot, err := s.concurrencyService.AcquireAccountSlot(ctx, account.ID, account.Concurrency)
+		if err != nil {
+			return nil, nil, fmt.Errorf("acquire shadow account slot: %w", err)
+		}
+		if !slot.Acquired {
+			return nil, nil, fmt.Errorf("shadow account %d is at concurrency limit", account.ID)
+		}
+		return account, releaseOrNoop(slot.ReleaseFunc), nil
+	}
+
+	groupID := req.Config.GroupID
+	selection, err := s.gatewayService.SelectAccountWithLoadAwareness(ctx, &groupID, "", req.Model, nil, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("select shadow account: %w", err)
+	}
+	if !selection.Acquired {
+		return nil, nil, fmt.Errorf("shadow group %d has no idle account", groupID)
+	}
+	return selection.Account, releaseOrNoop(selection.ReleaseFunc), nil
+}
+
+func releaseOrNoop(release func()) func() {
+	if release == nil {
+		return func() {}
+	}
+	return release
+}
+
+func (s *ShadowMirrorService) forwardShadow(ctx context.Context, req *ShadowMirrorRequest, account *Account, result *ShadowMirrorResult) {
+	header := http.Header{}
+	for _, key := range shadowMirrorRequestHeaderAllowlist {
+		if v := req.Headers.Get(key); v != "" {
+			header.Set(key, v)
+		}
+	}
+
+	start := time.Now()
+	resp, err := forwardDetachedClaudeRequest(ctx, s.gatewayService, s.antigravityGatewayService, account, req.Body, header, shadowMirrorCaptureBytes)
+	if resp == nil {
+		result.ErrorMessage = "failed to parse request body"
+		return
+	}
+	result.ShadowLatencyMs = time.Since(start).Milliseconds()
+	result.ShadowStatus = resp.status
+	if err != nil {
+		result.ErrorMessage = err.Error()
+		if result.ShadowStatus < 400 {
+			result.ShadowStatus = http.StatusBadGateway
+		}
+	}
+	if resp.result != nil {
+		result.ShadowInputTokens = resp.result.Usage.InputTokens
+		result.ShadowOutputTokens = resp.result.Usage.OutputTokens
+	}
+	if err == nil && result.ShadowStatus < 400 {
+		primaryText := ExtractClaudeResponseText(req.PrimaryOutput)
+		shadowText := ExtractClaudeResponseText(resp.body)
+		if primaryText != "" || shadowText != "" {
+			similarity := TextSimilarity(primaryText, shadowText)
+			result.Similarity = &similarity
+		}
+	}
+}
+
+// ListResults 分页查询影子镜像记录
+func (s *ShadowMirrorService) ListResults(ctx context.Context, filter ShadowMirrorFilter, params pagination.PaginationParams) ([]ShadowMirrorResult, *pagination.PaginationResult, error) {
+	return s.repo.List(ctx, filter, params)
+}
+
+// GetSummary 汇总分组影子镜像的对比指标
+func (s *ShadowMirrorService) GetSummary(ctx context.Context, filter ShadowMirrorFilter) (*ShadowMirrorSummary, error) {
+	return s.repo.Summary(ctx, filter)
+}
+
+// ExtractClaudeResponseText 从 Claude Messages 响应（非流式 JSON 或 SSE 流）中提取文本输出
+func ExtractClaudeResponseText(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ""
+	}
+	if body[0] == '{' {
+		var resp struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return ""
+		}
+		var sb strings.Builder
+		for _, block := range resp.Content {
+			if block.Type == "text" {
+				sb.WriteString(block.Text)
+			}
+		}
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue
+		}
+		var event struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+		}
+		if err := json.Unmarshal(bytes.TrimSpace(line[len("data:"):]), &event); err != nil {
+			continue
+		}
+		if event.Type == "content_block_delta" && event.Delta.Type == "text_delta" {
+			sb.WriteString(event.Delta.Text)
+		}
+	}
+	return sb.String()
+}
+
+// TextSimilarity 基于词频向量的余弦相似度（0-1）；CJK 字符逐字切分
+func TextSimilarity(a, b string) float64 {
+	va, vb := termFrequencies(a), termFrequencies(b)
+	if len(va) == 0 && len(vb) == 0 {
+		return 1
+	}
+	if len(va) == 0 || len(vb) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for term, ca := range va {
+		na += ca * ca
+		if cb, ok := vb[term]; ok {
+			dot += ca * cb
+		}
+	}
+	for _, cb := range vb {
+		nb += cb * cb
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func termFrequencies(text string) map[string]float64 {
+	freq := make(map[string]float64)
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			freq[word.String()]++
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			freq[string(r)]++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return freq
+}
@@ -0,0 +1,76 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractClaudeResponseText(t *testing.T) {
+	jsonBody := []byte(`{"id":"msg_1","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Hello "},{"type":"text","text":"world"}]}`)
+	require.Equal(t, "Hello world", ExtractClaudeResponseText(jsonBody))
+
+	sseBody := []byte("event: message_start\n" +
+		`data: {"type":"message_start","message":{"id":"msg_1"}}` + "\n\n" +
+		"event: content_block_delta\n" +
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello "}}` + "\n\n" +
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{}"}}` + "\n\n" +
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"world"}}` + "\n\n" +
+		`data: {"type":"message_stop"}` + "\n\n")
+	require.Equal(t, "Hello world", ExtractClaudeResponseText(sseBody))
+
+	require.Empty(t, ExtractClaudeResponseText(nil))
+	require.Empty(t, ExtractClaudeResponseText([]byte(`{"content":`)))
+}
+
+func TestTextSimilarity(t *testing.T) {
+	require.InDelta(t, 1.0, TextSimilarity("The quick brown fox", "the QUICK brown fox!"), 1e-9)
+	require.InDelta(t, 0.0, TextSimilarity("alpha beta", "gamma delta"), 1e-9)
+	require.InDelta(t, 1.0, TextSimilarity("", ""), 1e-9)
+	require.InDelta(t, 0.0, TextSimilarity("alpha", ""), 1e-9)
+
+	partial := TextSimilarity("alpha beta gamma", "alpha beta delta")
+	require.Greater(t, partial, 0.5)
+	require.Less(t, partial, 1.0)
+
+	// CJK 按字切分
+	require.Greater(t, TextSimilarity("你好世界", "你好，世界！"), 0.99)
+}
+
+func TestShadowMirrorService_ShouldMirror(t *testing.T) {
+	svc := &ShadowMirrorService{sample: func() float64 { return 0.3 }}
+	group := &Group{ShadowMirror: &ShadowMirrorConfig{Enabled: true, SampleRate: 0.5, AccountID: 1}}
+	require.True(t, svc.ShouldMirror(group))
+
+	group.ShadowMirror.SampleRate = 0.2
+	require.False(t, svc.ShouldMirror(group))
+
+	group.ShadowMirror.Enabled = false
+	group.ShadowMirror.SampleRate = 1
+	require.False(t, svc.ShouldMirror(group))
+
+	require.False(t, svc.ShouldMirror(&Group{}))
+	require.False(t, svc.ShouldMirror(nil))
+
+	var nilSvc *ShadowMirrorService
+	require.False(t, nilSvc.ShouldMirror(&Group{ShadowMirror: &ShadowMirrorConfig{Enabled: true, SampleRate: 1, AccountID: 1}}))
+}
+
+func TestShadowMirrorService_SubmitDropsWhenFull(t *testing.T) {
+	svc := &ShadowMirrorService{inFlight: make(chan struct{}, 1)}
+	svc.inFlight <- struct{}{}
+
+	// 在途已满时直接丢弃，不阻塞也不访问依赖
+	svc.Submit(&ShadowMirrorRequest{GroupID: 1})
+	require.Len(t, svc.inFlight, 1)
+}
+
+func TestForwardDetachedClaudeRequest_RejectsUnparsableBody(t *testing.T) {
+	account := &Account{ID: 1, Platform: PlatformAnthropic, Type: AccountTypeOAuth}
+	resp, err := forwardDetachedClaudeRequest(context.Background(), nil, nil, account, []byte(`not json`), nil, 1024)
+	require.Error(t, err)
+	require.Nil(t, resp, "无法解析的请求体不应转发")
+}
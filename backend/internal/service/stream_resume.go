@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// 流式断线续传：分组开启后，网关为每个 SSE 流在本实例内存中短暂缓冲已发送的事件，
+// 每个事件带 "id: <流ID>:<序号>"。客户端断线后携带 Last-Event-ID 重连即可收到剩余事件，
+// 上游在客户端断开后继续读取完毕（计费不变），续传不会产生新的上游请求。
+//
+// 内存按单流与单实例两级上限约束：超出单流上限的流停止缓冲并不可续传；
+// 总量超限时优先淘汰最早结束的流，仍不足时新事件不再缓冲。
+const (
+	// StreamResumeIDHeader 响应头：返回本次流的续传 ID
+	StreamResumeIDHeader = "X-Stream-Resume-ID"
+	// StreamResumeLastEventIDHeader 请求头：客户端已收到的最后一个事件 ID
+	StreamResumeLastEventIDHeader = "Last-Event-ID"
+
+	defaultStreamResumeTTL           = 2 * time.Minute
+	defaultStreamResumeMaxStreamSize = 4 * 1024 * 1024
+	defaultStreamResumeMaxTotalSize  = 256 * 1024 * 1024
+)
+
+// StreamResumeStats 续传缓冲的实例级指标
+type StreamResumeStats struct {
+	// StreamsStarted 开始缓冲的流数量
+	StreamsStarted int64 `json:"streams_started"`
+	// ResumeHits 续传成功次数
+	ResumeHits int64 `json:"resume_hits"`
+	// ResumeMisses 续传失败次数（流不存在、已过期或已淘汰）
+	ResumeMisses int64 `json:"resume_misses"`
+	// StreamsEvicted 因总量上限被提前淘汰的流数量
+	StreamsEvicted int64 `json:"streams_evicted"`
+	// StreamsOverflowed 因缓冲上限停止缓冲的流数量
+	StreamsOverflowed int64 `json:"streams_overflowed"`
+	// ActiveStreams 当前缓冲中的流数量（含已结束、等待过期的流）
+	ActiveStreams int `json:"active_streams"`
+	// BufferedBytes 当前缓冲占用字节数
+	BufferedBytes int64 `json:"buffered_bytes"`
+	// MaxTotalBytes 单实例缓冲上限
+	MaxTotalBytes int64 `json:"max_total_bytes"`
+}
+
+// resumableStream 单个流的事件缓冲
+type resumableStream struct {
+	id         string
+	apiKeyID   int64
+	events     []string
+	bytes      int
+	done       bool
+	removed    bool
+	finishedAt time.Time
+	// notify 每次追加事件或结束时关闭并替换，用于续传方等待新事件
+	notify chan struct{}
+}
+
+// StreamResumeService 流式断线续传缓冲（单实例内存，有界）
+type StreamResumeService struct {
+	ttl            time.Duration
+	maxStreamBytes int
+	maxTotalBytes  int64
+
+	mu      sync.Mutex
+	streams map[string]*resumableStream
+	// finished 按结束先后排列的已结束流，过期清理与淘汰均从头部开始
+	finished   []*resumableStream
+	totalBytes int64
+
+	started    atomic.Int64
+	hits       atomic.Int64
+	misses     atomic.Int64
+	evicted    atomic.Int64
+	overflowed atomic.Int64
+}
+
+func NewStreamResumeService(cfg *config.Config) *StreamResumeService {
+	svc := &StreamResumeService{
+		ttl:            defaultStreamResumeTTL,
+		maxStreamBytes: defaultStreamResumeMaxStreamSize,
+		maxTotalBytes:  defaultStreamResumeMaxTotalSize,
+		streams:        make(map[string]*resumableStream),
+	}
+	if cfg != nil {
+		resumeCfg := cfg.Gateway.StreamResume
+		if resumeCfg.BufferTTLSeconds > 0 {
+			svc.ttl = time.Duration(resumeCfg.BufferTTLSeconds) * time.Second
+		}
+		if resumeCfg.MaxStreamBytes > 0 {
+			svc.maxStreamBytes = resumeCfg.MaxStreamBytes
+		}
+		if resumeCfg.MaxTotalBytes > 0 {
+			svc.maxTotalBytes = resumeCfg.MaxTotalBytes
+		}
+	}
+	return svc
+}
+
+// StreamResumeRecorder 单个请求的事件记录器；首个事件写入时才登记到缓冲，
+// 未产生 SSE 事件的请求（非流式、失败或其它平台转发）不会占用缓冲
+type StreamResumeRecorder struct {
+	svc      *StreamResumeService
+	id       string
+	apiKeyID int64
+	stream   *resumableStream
+	dropped  bool
+}
+
+// Begin 为请求创建记录器，id 为请求 ID
+func (s *StreamResumeService) Begin(id string, apiKeyID int64) *StreamResumeRecorder {
+	id = strings.TrimSpace(id)
+	if s == nil || id == "" || strings.Contains(id, ":") {
+		return nil
+	}
+	return &StreamResumeRecorder{svc: s, id: id, apiKeyID: apiKeyID}
+}
+
+// WithStreamResumeRecorder 将记录器注入上下文，供流式响应处理时写入事件
+func WithStreamResumeRecorder(ctx context.Context, recorder *StreamResumeRecorder) context.Context {
+	if recorder == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.StreamResumeRecorder, recorder)
+}
+
+// StreamResumeRecorderFromContext 读取上下文中的记录器，未开启续传时返回 nil
+func StreamResumeRecorderFromContext(ctx context.Context) *StreamResumeRecorder {
+	recorder, _ := ctx.Value(ctxkey.StreamResumeRecorder).(*StreamResumeRecorder)
+	return recorder
+}
+
+// ID 返回续传 ID
+func (r *StreamResumeRecorder) ID() string {
+	if r == nil {
+		return ""
+	}
+	return r.id
+}
+
+// Append 为 SSE 事件块加上事件 ID 并写入缓冲，返回应发送给客户端的事件块。
+// 无论客户端是否已断开都应调用，以保证缓冲的事件完整。
+func (r *StreamResumeRecorder) Append(block string) string {
+	if r == nil || r.dropped {
+		return block
+	}
+	s := r.svc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.stream == nil {
+		s.sweepLocked(time.Now())
+		r.stream = &resumableStream{id: r.id, apiKeyID: r.apiKeyID, notify: make(chan struct{})}
+		if existing := s.streams[r.id]; existing != nil {
+			s.removeLocked(existing)
+		}
+		s.streams[r.id] = r.stream
+		s.started.Add(1)
+	}
+	if r.stream.removed {
+		r.dropped = true
+		return block
+	}
+
+	tagged := "id: " + r.id + ":" + strconv.Itoa(len(r.stream.events)+1) + "\n" + block
+	size := len(tagged)
+	if r.stream.bytes+size > s.maxStreamBytes || !s.reserveLocked(int64(size)) {
+		// 缓冲不完整的流无法续传，整体丢弃
+		s.overflowed.Add(1)
+		s.removeLocked(r.stream)
+		r.dropped = true
+		return block
+	}
+	r.stream.events = append(r.stream.events, tagged)
+	r.stream.bytes += size
+	s.totalBytes += int64(size)
+	r.stream.signalLocked()
+	return tagged
+}
+
+// Finish 标记流结束，此后在 TTL 内仍可续传
+func (r *StreamResumeRecorder) Finish() {
+	if r == nil || r.stream == nil {
+		return
+	}
+	s := r.svc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.stream.done || r.stream.removed {
+		return
+	}
+	r.stream.done = true
+	r.stream.finishedAt = time.Now()
+	r.stream.signalLocked()
+	s.finished = append(s.finished, r.stream)
+}
+
+// reserveLocked 确保总量上限内有 size 字节可用，必要时淘汰最早结束的流
+func (s *StreamResumeService) reserveLocked(size int64) bool {
+	for s.totalBytes+size > s.maxTotalBytes {
+		if len(s.finished) == 0 {
+			return false
+		}
+		oldest := s.finished[0]
+		s.finished = s.finished[1:]
+		if !oldest.removed {
+			s.evicted.Add(1)
+			s.removeLocked(oldest)
+		}
+	}
+	return true
+}
+
+// sweepLocked 清理已过期的结束流
+func (s *StreamResumeService) sweepLocked(now time.Time) {
+	for len(s.finished) > 0 {
+		oldest := s.finished[0]
+		if !oldest.removed && now.Sub(oldest.finishedAt) < s.ttl {
+			return
+		}
+		s.finished = s.finished[1:]
+		s.removeLocked(oldest)
+	}
+}
+
+func (s *StreamResumeService) removeLocked(stream *resumableStream) {
+	if stream.removed {
+		return
+	}
+	stream.removed = true
+	if s.streams[stream.id] == stream {
+		delete(s.streams, stream.id)
+	}
+	s.totalBytes -= int64(stream.bytes)
+	stream.events = nil
+	stream.bytes = 0
+	stream.signalLocked()
+}
+
+func (stream *resumableStream) signalLocked() {
+	close(stream.notify)
+	stream.notify = make(chan struct{})
+}
+
+// ParseStreamResumeEventID 解析 "<流ID>:<序号>" 格式的事件 ID
+func ParseStreamResumeEventID(eventID string) (string, int, bool) {
+	eventID = strings.TrimSpace(eventID)
+	idx := strings.LastIndex(eventID, ":")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(eventID[idx+1:])
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+	return eventID[:idx], seq, true
+}
+
+// StreamResumeCursor 续传读取游标
+type StreamResumeCursor struct {
+	svc    *StreamResumeService
+	stream *resumableStream
+	next   int
+}
+
+// Resume 按事件 ID 定位缓冲的流；流不存在、已过期或不属于该 API Key 时返回 nil
+func (s *StreamResumeService) Resume(apiKeyID int64, lastEventID string) *StreamResumeCursor {
+	if s == nil {
+		return nil
+	}
+	id, seq, ok := ParseStreamResumeEventID(lastEventID)
+	if !ok {
+		s.misses.Add(1)
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(time.Now())
+	stream := s.streams[id]
+	if stream == nil || stream.apiKeyID != apiKeyID || seq > len(stream.events) {
+		s.misses.Add(1)
+		return nil
+	}
+	s.hits.Add(1)
+	return &StreamResumeCursor{svc: s, stream: stream, next: seq}
+}
+
+// ID 返回续传 ID
+func (c *StreamResumeCursor) ID() string {
+	return c.stream.id
+}
+
+// Next 返回游标之后已缓冲的事件；流已结束且无剩余事件时 done=true。
+// 暂无新事件时返回 wait 通道，新事件到达或流结束时关闭。
+func (c *StreamResumeCursor) Next() (events []string, wait <-chan struct{}, done bool) {
+	s := c.svc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.stream.removed {
+		return nil, nil, true
+	}
+	if c.next < len(c.stream.events) {
+		events = append(events, c.stream.events[c.next:]...)
+		c.next = len(c.stream.events)
+		return events, nil, false
+	}
+	if c.stream.done {
+		return nil, nil, true
+	}
+	return nil, c.stream.notify, false
+}
+
+// Stats 返回实例级续传指标
+func (s *StreamResumeService) Stats() StreamResumeStats {
+	if s == nil {
+		return StreamResumeStats{}
+	}
+	s.mu.Lock()
+	s.sweepLocked(time.Now())
+	active := len(s.streams)
+	buffered := s.totalBytes
+	s.mu.Unlock()
+	return StreamResumeStats{
+		StreamsStarted:    s.started.Load(),
+		ResumeHits:        s.hits.Load(),
+		ResumeMisses:      s.misses.Load(),
+		StreamsEvicted:    s.evicted.Load(),
+		StreamsOverflowed: s.overflowed.Load(),
+		ActiveStreams:     active,
+		BufferedBytes:     buffered,
+		MaxTotalBytes:     s.maxTotalBytes,
+	}
+}
+
+// GetStreamResumeStats 返回本实例的流式断线续传指标
+func (s *OpsService) GetStreamResumeStats(ctx context.Context) (StreamResumeStats, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return StreamResumeStats{}, err
+	}
+	return s.streamResumeService.Stats(), nil
+}
@@ -0,0 +1,147 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamResumeTestService(ttlSeconds, maxStream int, maxTotal int64) *StreamResumeService {
+	cfg := &config.Config{}
+	cfg.Gateway.StreamResume = config.GatewayStreamResumeConfig{
+		BufferTTLSeconds: ttlSeconds,
+		MaxStreamBytes:   maxStream,
+		MaxTotalBytes:    maxTotal,
+	}
+	return NewStreamResumeService(cfg)
+}
+
+func TestStreamResume_TagsEventsAndReplaysRemainder(t *testing.T) {
+	svc := newStreamResumeTestService(60, 1024, 4096)
+	recorder := svc.Begin("req-1", 7)
+
+	first := recorder.Append("event: message_start\ndata: {}\n\n")
+	require.Equal(t, "id: req-1:1\nevent: message_start\ndata: {}\n\n", first)
+	recorder.Append("event: content_block_delta\ndata: {\"a\":1}\n\n")
+	recorder.Append("event: message_stop\ndata: {}\n\n")
+
+	cursor := svc.Resume(7, "req-1:1")
+	require.NotNil(t, cursor)
+	require.Equal(t, "req-1", cursor.ID())
+
+	events, wait, done := cursor.Next()
+	require.Len(t, events, 2)
+	require.True(t, strings.HasPrefix(events[0], "id: req-1:2\n"))
+	require.False(t, done)
+	require.Nil(t, wait)
+
+	// 原始流尚未结束：等待新事件
+	_, wait, done = cursor.Next()
+	require.False(t, done)
+	require.NotNil(t, wait)
+	recorder.Finish()
+	select {
+	case <-wait:
+	case <-time.After(time.Second):
+		t.Fatal("finish should wake up waiting resumers")
+	}
+	_, _, done = cursor.Next()
+	require.True(t, done)
+
+	stats := svc.Stats()
+	require.Equal(t, int64(1), stats.StreamsStarted)
+	require.Equal(t, int64(1), stats.ResumeHits)
+	require.Equal(t, 1, stats.ActiveStreams)
+}
+
+func TestStreamResume_MissesForOtherKeysAndUnknownStreams(t *testing.T) {
+	svc := newStreamResumeTestService(60, 1024, 4096)
+	recorder := svc.Begin("req-1", 7)
+	recorder.Append("data: {}\n\n")
+	recorder.Finish()
+
+	require.Nil(t, svc.Resume(8, "req-1:1"), "other API keys cannot resume the stream")
+	require.Nil(t, svc.Resume(7, "req-2:1"))
+	require.Nil(t, svc.Resume(7, "req-1:5"), "event IDs beyond the buffer are rejected")
+	require.Nil(t, svc.Resume(7, "garbage"))
+	require.Equal(t, int64(4), svc.Stats().ResumeMisses)
+
+	// 未产生事件的记录器不占用缓冲
+	svc.Begin("req-3", 7).Finish()
+	require.Nil(t, svc.Resume(7, "req-3:0"))
+}
+
+func TestStreamResume_PerStreamLimitDropsStream(t *testing.T) {
+	svc := newStreamResumeTestService(60, 64, 4096)
+	recorder := svc.Begin("req-1", 7)
+	recorder.Append("data: short\n\n")
+	block := "data: " + strings.Repeat("x", 80) + "\n\n"
+	require.Equal(t, block, recorder.Append(block), "overflowing events are sent without an ID")
+	require.Equal(t, "data: tail\n\n", recorder.Append("data: tail\n\n"))
+	recorder.Finish()
+
+	require.Nil(t, svc.Resume(7, "req-1:1"))
+	stats := svc.Stats()
+	require.Equal(t, int64(1), stats.StreamsOverflowed)
+	require.Zero(t, stats.BufferedBytes)
+	require.Zero(t, stats.ActiveStreams)
+}
+
+func TestStreamResume_TotalLimitEvictsOldestFinishedStream(t *testing.T) {
+	svc := newStreamResumeTestService(60, 64, 100)
+	payload := "data: " + strings.Repeat("x", 30) + "\n\n"
+
+	oldest := svc.Begin("req-1", 7)
+	oldest.Append(payload)
+	oldest.Finish()
+	newer := svc.Begin("req-2", 7)
+	newer.Append(payload)
+	newer.Finish()
+
+	live := svc.Begin("req-3", 7)
+	live.Append(payload)
+
+	require.Nil(t, svc.Resume(7, "req-1:0"), "oldest finished stream is evicted first")
+	require.NotNil(t, svc.Resume(7, "req-2:0"))
+	require.NotNil(t, svc.Resume(7, "req-3:0"))
+	stats := svc.Stats()
+	require.Equal(t, int64(1), stats.StreamsEvicted)
+	require.LessOrEqual(t, stats.BufferedBytes, int64(100))
+
+	// 无已结束流可淘汰时，正在进行的流停止缓冲
+	second := svc.Begin("req-4", 7)
+	second.Append(payload)
+	second.Append(payload)
+	require.Equal(t, int64(1), svc.Stats().StreamsOverflowed)
+}
+
+func TestStreamResume_FinishedStreamsExpire(t *testing.T) {
+	svc := newStreamResumeTestService(60, 1024, 4096)
+	recorder := svc.Begin("req-1", 7)
+	recorder.Append("data: {}\n\n")
+	recorder.Finish()
+
+	svc.mu.Lock()
+	recorder.stream.finishedAt = time.Now().Add(-2 * time.Minute)
+	svc.mu.Unlock()
+
+	require.Nil(t, svc.Resume(7, "req-1:1"))
+	require.Zero(t, svc.Stats().BufferedBytes)
+}
+
+func TestParseStreamResumeEventID(t *testing.T) {
+	id, seq, ok := ParseStreamResumeEventID(" 6f1c-uuid:12 ")
+	require.True(t, ok)
+	require.Equal(t, "6f1c-uuid", id)
+	require.Equal(t, 12, seq)
+
+	for _, invalid := range []string{"", "12", ":3", "abc:", "abc:-1", "abc:x"} {
+		_, _, ok := ParseStreamResumeEventID(invalid)
+		require.False(t, ok, invalid)
+	}
+}
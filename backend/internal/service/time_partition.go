@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	PartitionConversionCopying   = "copying"
+	PartitionConversionSwapping  = "swapping"
+	PartitionConversionCompleted = "completed"
+	PartitionConversionFailed    = "failed"
+)
+
+// partitionedTables 支持按月分区（created_at RANGE）的表
+var partitionedTables = []string{"usage_logs", "ops_error_logs", "ops_system_metrics"}
+
+// IsPartitionableTable 判断表是否在按月分区的管理范围内
+func IsPartitionableTable(table string) bool {
+	for _, t := range partitionedTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// TimePartition 一个按月分区，范围为左闭右开 [RangeStart, RangeEnd)
+type TimePartition struct {
+	Name          string    `json:"name"`
+	RangeStart    time.Time `json:"range_start"`
+	RangeEnd      time.Time `json:"range_end"`
+	EstimatedRows int64     `json:"estimated_rows"`
+}
+
+// TablePartitionConversion 在线分区转换进度
+type TablePartitionConversion struct {
+	TableName    string     `json:"table_name"`
+	Status       string     `json:"status"`
+	LastCopiedID int64      `json:"last_copied_id"`
+	CopiedRows   int64      `json:"copied_rows"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TablePartitionStatus 单个表的分区状态
+type TablePartitionStatus struct {
+	TableName   string                    `json:"table_name"`
+	Partitioned bool                      `json:"partitioned"`
+	Partitions  []TimePartition           `json:"partitions"`
+	Conversion  *TablePartitionConversion `json:"conversion,omitempty"`
+}
+
+// TimePartitionRepository 按月分区的 DDL 与在线转换持久层接口
+type TimePartitionRepository interface {
+	IsPartitioned(ctx context.Context, table string) (bool, error)
+	// ListPartitions 按起始时间升序返回子分区；不符合 {table}_YYYYMM 命名的分区会被忽略
+	ListPartitions(ctx context.Context, table string) ([]TimePartition, error)
+	CreateMonthlyPartition(ctx context.Context, table string, month time.Time) error
+	DropPartition(ctx context.Context, table string, name string) error
+	// OldestRowTime 返回表中最早的 created_at；空表返回 nil
+	OldestRowTime(ctx context.Context, table string) (*time.Time, error)
+	// PruneUsageLogDedup 删除早于 before 的 usage_logs 去重记录
+	PruneUsageLogDedup(ctx context.Context, before time.Time, batchSize int) (int64, error)
+
+	GetConversion(ctx context.Context, table string) (*TablePartitionConversion, error)
+	// ClaimConversion 标记转换开始；已有进行中的转换时返回 false
+	ClaimConversion(ctx context.Context, table string) (bool, error)
+	UpdateConversion(ctx context.Context, table string, status string, lastCopiedID, copiedRows int64, errMsg *string) error
+	// PrepareConversion 创建影子分区表（含主键、索引、外键）、[from, to) 的月分区以及同步触发器；
+	// 影子表为本次新建时返回 true，调用方需从头复制
+	PrepareConversion(ctx context.Context, table string, from, to time.Time) (bool, error)
+	// CopyConversionBatch 复制 id > afterID 的一批数据，返回本批最大 id 与扫描行数
+	CopyConversionBatch(ctx context.Context, table string, afterID int64, limit int) (int64, int64, error)
+	// SwapConversion 在一个事务内补齐增量并用影子表替换原表，原表保留为 {table}_legacy
+	SwapConversion(ctx context.Context, table string, afterID int64) (int64, error)
+}
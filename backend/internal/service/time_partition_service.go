@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+const (
+	defaultPartitionPremakeMonths      = 3
+	defaultPartitionMaintenanceMinutes = 60
+	defaultPartitionConvertBatchSize   = 10000
+	defaultPartitionDedupRetentionDays = 7
+	partitionDedupPruneBatchSize       = 5000
+	partitionMaintenanceTimeout        = 5 * time.Minute
+)
+
+var (
+	ErrPartitionTableUnsupported = infraerrors.BadRequest("PARTITION_TABLE_UNSUPPORTED", "table does not support partitioning")
+	ErrPartitionAlreadyConverted = infraerrors.Conflict("PARTITION_ALREADY_CONVERTED", "table is already partitioned")
+	ErrPartitionConversionBusy   = infraerrors.Conflict("PARTITION_CONVERSION_RUNNING", "partition conversion is already running")
+)
+
+// TimePartitionService 维护按月分区表：预建未来分区、按分区删除过期数据、在线转换未分区的表。
+//
+// 分区后保留清理直接删除整月分区（DROP TABLE），避免大批量 DELETE 产生的锁与膨胀。
+type TimePartitionService struct {
+	repo        TimePartitionRepository
+	timingWheel *TimingWheelService
+	cfg         config.PartitioningConfig
+}
+
+// NewTimePartitionService 创建分区维护服务
+func NewTimePartitionService(repo TimePartitionRepository, timingWheel *TimingWheelService, cfg *config.Config) *TimePartitionService {
+	var partCfg config.PartitioningConfig
+	if cfg != nil {
+		partCfg = cfg.Partitioning
+	}
+	return &TimePartitionService{repo: repo, timingWheel: timingWheel, cfg: partCfg}
+}
+
+// Start 启动定时分区维护
+func (s *TimePartitionService) Start() {
+	if s == nil || s.repo == nil || s.timingWheel == nil {
+		return
+	}
+	interval := time.Duration(s.cfg.MaintenanceIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = defaultPartitionMaintenanceMinutes * time.Minute
+	}
+	if !s.cfg.Enabled {
+		// 用量日志写入始终占用 usage_log_dedup 去重键，分区维护关闭时仍需单独清理
+		go s.runDedupPrune()
+		s.timingWheel.ScheduleRecurring("partition:dedup_prune", interval, s.runDedupPrune)
+		logger.LegacyPrintf("service.time_partition", "[Partition] maintenance disabled, dedup prune only (interval=%v)", interval)
+		return
+	}
+	go s.runMaintenance()
+	s.timingWheel.ScheduleRecurring("partition:maintenance", interval, s.runMaintenance)
+	logger.LegacyPrintf("service.time_partition", "[Partition] maintenance started (interval=%v premake_months=%d)", interval, s.premakeMonths())
+}
+
+func (s *TimePartitionService) runMaintenance() {
+	ctx, cancel := context.WithTimeout(context.Background(), partitionMaintenanceTimeout)
+	defer cancel()
+	if err := s.Maintain(ctx, time.Now().UTC()); err != nil {
+		logger.LegacyPrintf("service.time_partition", "[Partition] maintenance failed: %v", err)
+	}
+}
+
+func (s *TimePartitionService) runDedupPrune() {
+	ctx, cancel := context.WithTimeout(context.Background(), partitionMaintenanceTimeout)
+	defer cancel()
+	if err := s.PruneDedup(ctx, time.Now().UTC()); err != nil {
+		logger.LegacyPrintf("service.time_partition", "[Partition] dedup prune failed: %v", err)
+	}
+}
+
+// Maintain 为所有已分区的表预建分区，并清理过期的去重记录
+func (s *TimePartitionService) Maintain(ctx context.Context, now time.Time) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	var firstErr error
+	for _, table := range partitionedTables {
+		partitioned, err := s.repo.IsPartitioned(ctx, table)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("check %s: %w", table, err)
+			}
+			continue
+		}
+		if !partitioned {
+			continue
+		}
+		if err := s.ensureFuturePartitions(ctx, table, now); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("ensure %s partitions: %w", table, err)
+		}
+	}
+
+	if err := s.PruneDedup(ctx, now); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// PruneDedup 清理超过保留期的 usage_log_dedup 记录，与 usage_logs 是否分区无关
+func (s *TimePartitionService) PruneDedup(ctx context.Context, now time.Time) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	cutoff := now.AddDate(0, 0, -s.dedupRetentionDays())
+	pruned, err := s.repo.PruneUsageLogDedup(ctx, cutoff, partitionDedupPruneBatchSize)
+	if err != nil {
+		return fmt.Errorf("prune usage_log_dedup: %w", err)
+	}
+	if pruned > 0 {
+		logger.LegacyPrintf("service.time_partition", "[Partition] pruned usage_log_dedup: rows=%d before=%s", pruned, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (s *TimePartitionService) ensureFuturePartitions(ctx context.Context, table string, now time.Time) error {
+	month := truncateToMonthUTC(now)
+	for i := 0; i <= s.premakeMonths(); i++ {
+		if err := s.repo.CreateMonthlyPartition(ctx, table, month.AddDate(0, i, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropPartitionsInRange 删除完全落在 [start, end) 内的分区；start 为零值表示不限下界。
+// 表未分区时返回空结果，调用方应继续按行删除剩余数据。
+func (s *TimePartitionService) DropPartitionsInRange(ctx context.Context, table string, start, end time.Time) ([]TimePartition, error) {
+	if s == nil || s.repo == nil || !IsPartitionableTable(table) {
+		return nil, nil
+	}
+	partitioned, err := s.repo.IsPartitioned(ctx, table)
+	if err != nil || !partitioned {
+		return nil, err
+	}
+	partitions, err := s.repo.ListPartitions(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	current := truncateToMonthUTC(time.Now())
+	dropped := make([]TimePartition, 0)
+	for _, p := range partitions {
+		if !start.IsZero() && p.RangeStart.Before(start) {
+			continue
+		}
+		if p.RangeEnd.After(end) {
+			continue
+		}
+		// 当前及未来月份的分区仍在写入，删除后新记录将无处落盘，始终保留
+		if p.RangeEnd.After(current) {
+			continue
+		}
+		if err := s.repo.DropPartition(ctx, table, p.Name); err != nil {
+			return dropped, fmt.Errorf("drop partition %s: %w", p.Name, err)
+		}
+		dropped = append(dropped, p)
+		logger.LegacyPrintf("service.time_partition", "[Partition] dropped partition: table=%s name=%s range=[%s,%s) est_rows=%d",
+			table, p.Name, p.RangeStart.Format("2006-01-02"), p.RangeEnd.Format("2006-01-02"), p.EstimatedRows)
+	}
+	return dropped, nil
+}
+
+// ListStatus 返回所有受管表的分区状态
+func (s *TimePartitionService) ListStatus(ctx context.Context) ([]TablePartitionStatus, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("partition service not ready")
+	}
+	out := make([]TablePartitionStatus, 0, len(partitionedTables))
+	for _, table := range partitionedTables {
+		status := TablePartitionStatus{TableName: table, Partitions: []TimePartition{}}
+		partitioned, err := s.repo.IsPartitioned(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		status.Partitioned = partitioned
+		if partitioned {
+			partitions, err := s.repo.ListPartitions(ctx, table)
+			if err != nil {
+				return nil, err
+			}
+			status.Partitions = partitions
+		}
+		conversion, err := s.repo.GetConversion(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		status.Conversion = conversion
+		out = append(out, status)
+	}
+	return out, nil
+}
+
+// Convert 异步将未分区的表在线转换为按月分区表。
+//
+// 流程：创建影子分区表与同步触发器 → 按 id 分批复制（可中断续跑）→ 在一个事务内补齐增量并改名替换。
+// 原表保留为 {table}_legacy，确认无误后可手动删除。
+func (s *TimePartitionService) Convert(ctx context.Context, table string) (*TablePartitionConversion, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("partition service not ready")
+	}
+	table = strings.TrimSpace(table)
+	if !IsPartitionableTable(table) {
+		return nil, ErrPartitionTableUnsupported
+	}
+	partitioned, err := s.repo.IsPartitioned(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if partitioned {
+		return nil, ErrPartitionAlreadyConverted
+	}
+	claimed, err := s.repo.ClaimConversion(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrPartitionConversionBusy
+	}
+	conversion, err := s.repo.GetConversion(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	logger.LegacyPrintf("service.time_partition", "[Partition] conversion requested: table=%s resume_from_id=%d", table, conversion.LastCopiedID)
+	go s.runConversion(table, conversion.LastCopiedID, conversion.CopiedRows)
+	return conversion, nil
+}
+
+func (s *TimePartitionService) runConversion(table string, lastID, copied int64) {
+	ctx := context.Background()
+	startedAt := time.Now()
+	if err := s.convert(ctx, table, lastID, copied); err != nil {
+		msg := strings.TrimSpace(err.Error())
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		logger.LegacyPrintf("service.time_partition", "[Partition] conversion failed: table=%s err=%s", table, msg)
+		updateCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conversion, getErr := s.repo.GetConversion(updateCtx, table)
+		if getErr == nil && conversion != nil {
+			lastID, copied = conversion.LastCopiedID, conversion.CopiedRows
+		}
+		if updateErr := s.repo.UpdateConversion(updateCtx, table, PartitionConversionFailed, lastID, copied, &msg); updateErr != nil {
+			logger.LegacyPrintf("service.time_partition", "[Partition] update conversion status failed: table=%s err=%v", table, updateErr)
+		}
+		return
+	}
+	logger.LegacyPrintf("service.time_partition", "[Partition] conversion completed: table=%s duration=%s", table, time.Since(startedAt))
+}
+
+func (s *TimePartitionService) convert(ctx context.Context, table string, lastID, copied int64) error {
+	now := time.Now().UTC()
+	from := truncateToMonthUTC(now)
+	oldest, err := s.repo.OldestRowTime(ctx, table)
+	if err != nil {
+		return fmt.Errorf("query oldest row: %w", err)
+	}
+	if oldest != nil && oldest.Before(from) {
+		from = truncateToMonthUTC(*oldest)
+	}
+	to := truncateToMonthUTC(now).AddDate(0, s.premakeMonths()+1, 0)
+	created, err := s.repo.PrepareConversion(ctx, table, from, to)
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	if created {
+		lastID, copied = 0, 0
+	}
+
+	batchSize := s.convertBatchSize()
+	for batch := 1; ; batch++ {
+		nextID, scanned, err := s.repo.CopyConversionBatch(ctx, table, lastID, batchSize)
+		if err != nil {
+			return fmt.Errorf("copy after id %d: %w", lastID, err)
+		}
+		if scanned == 0 {
+			break
+		}
+		lastID = nextID
+		copied += scanned
+		if err := s.repo.UpdateConversion(ctx, table, PartitionConversionCopying, lastID, copied, nil); err != nil {
+			return fmt.Errorf("update progress: %w", err)
+		}
+		if batch%50 == 0 {
+			logger.LegacyPrintf("service.time_partition", "[Partition] conversion progress: table=%s copied=%d last_id=%d", table, copied, lastID)
+		}
+	}
+
+	if err := s.repo.UpdateConversion(ctx, table, PartitionConversionSwapping, lastID, copied, nil); err != nil {
+		return fmt.Errorf("update progress: %w", err)
+	}
+	delta, err := s.repo.SwapConversion(ctx, table, lastID)
+	if err != nil {
+		return fmt.Errorf("swap: %w", err)
+	}
+	copied += delta
+	return s.repo.UpdateConversion(ctx, table, PartitionConversionCompleted, lastID, copied, nil)
+}
+
+func (s *TimePartitionService) premakeMonths() int {
+	if s.cfg.PremakeMonths <= 0 {
+		return defaultPartitionPremakeMonths
+	}
+	return s.cfg.PremakeMonths
+}
+
+func (s *TimePartitionService) convertBatchSize() int {
+	if s.cfg.ConvertBatchSize <= 0 {
+		return defaultPartitionConvertBatchSize
+	}
+	return s.cfg.ConvertBatchSize
+}
+
+func (s *TimePartitionService) dedupRetentionDays() int {
+	if s.cfg.DedupRetentionDays <= 0 {
+		return defaultPartitionDedupRetentionDays
+	}
+	return s.cfg.DedupRetentionDays
+}
+
+func truncateToMonthUTC(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type timePartitionRepoStub struct {
+	TimePartitionRepository
+
+	mu          sync.Mutex
+	partitioned map[string]bool
+	partitions  []TimePartition
+	created     []time.Time
+	droppedName []string
+	pruneBefore time.Time
+}
+
+func (s *timePartitionRepoStub) IsPartitioned(ctx context.Context, table string) (bool, error) {
+	return s.partitioned[table], nil
+}
+
+func (s *timePartitionRepoStub) ListPartitions(ctx context.Context, table string) ([]TimePartition, error) {
+	return s.partitions, nil
+}
+
+func (s *timePartitionRepoStub) CreateMonthlyPartition(ctx context.Context, table string, month time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, month)
+	return nil
+}
+
+func (s *timePartitionRepoStub) DropPartition(ctx context.Context, table string, name string) error {
+	s.droppedName = append(s.droppedName, name)
+	return nil
+}
+
+func (s *timePartitionRepoStub) PruneUsageLogDedup(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneBefore = before
+	return 0, nil
+}
+
+func monthPartition(name string, start time.Time, rows int64) TimePartition {
+	return TimePartition{Name: name, RangeStart: start, RangeEnd: start.AddDate(0, 1, 0), EstimatedRows: rows}
+}
+
+func TestTimePartitionServiceMaintainPremakesPartitions(t *testing.T) {
+	repo := &timePartitionRepoStub{partitioned: map[string]bool{"usage_logs": true}}
+	svc := NewTimePartitionService(repo, nil, &config.Config{Partitioning: config.PartitioningConfig{
+		Enabled:            true,
+		PremakeMonths:      2,
+		DedupRetentionDays: 3,
+	}})
+
+	now := time.Date(2026, 11, 20, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.Maintain(context.Background(), now))
+	require.Equal(t, []time.Time{
+		time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, repo.created)
+	require.Equal(t, now.AddDate(0, 0, -3), repo.pruneBefore)
+}
+
+func TestTimePartitionServiceDropPartitionsInRange(t *testing.T) {
+	current := truncateToMonthUTC(time.Now())
+	m3 := current.AddDate(0, -3, 0)
+	m2 := current.AddDate(0, -2, 0)
+	m1 := current.AddDate(0, -1, 0)
+	repo := &timePartitionRepoStub{
+		partitioned: map[string]bool{"usage_logs": true},
+		partitions: []TimePartition{
+			monthPartition("p3", m3, 10),
+			monthPartition("p2", m2, 20),
+			monthPartition("p1", m1, 30),
+			monthPartition("p0", current, 40),
+		},
+	}
+	svc := NewTimePartitionService(repo, nil, &config.Config{})
+
+	// 起点落在 m3 月中：m3 不完整保留；当前月即使在范围内也不删除
+	dropped, err := svc.DropPartitionsInRange(context.Background(), "usage_logs", m3.Add(time.Hour), current.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Len(t, dropped, 2)
+	require.Equal(t, []string{"p2", "p1"}, repo.droppedName)
+
+	// 终点落在 m1 月中：仅删除之前的完整月份
+	repo.droppedName = nil
+	dropped, err = svc.DropPartitionsInRange(context.Background(), "usage_logs", time.Time{}, m1.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, dropped, 2)
+	require.Equal(t, []string{"p3", "p2"}, repo.droppedName)
+}
+
+func TestTimePartitionServiceDropPartitionsSkipsUnpartitioned(t *testing.T) {
+	repo := &timePartitionRepoStub{partitioned: map[string]bool{}}
+	svc := NewTimePartitionService(repo, nil, &config.Config{})
+
+	dropped, err := svc.DropPartitionsInRange(context.Background(), "usage_logs", time.Time{}, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, dropped)
+
+	dropped, err = svc.DropPartitionsInRange(context.Background(), "users", time.Time{}, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, dropped)
+	require.Empty(t, repo.droppedName)
+}
+
+func TestProvideTimePartitionServicePrunesDedupWhenDisabled(t *testing.T) {
+	repo := &timePartitionRepoStub{partitioned: map[string]bool{"usage_logs": true}}
+	tw, err := NewTimingWheelService()
+	require.NoError(t, err)
+	defer tw.Stop()
+	ProvideTimePartitionService(repo, tw, &config.Config{Partitioning: config.PartitioningConfig{
+		Enabled:            false,
+		DedupRetentionDays: 3,
+	}})
+
+	require.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return !repo.pruneBefore.IsZero()
+	}, time.Second, 10*time.Millisecond)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Empty(t, repo.created)
+	require.WithinDuration(t, time.Now().UTC().AddDate(0, 0, -3), repo.pruneBefore, time.Minute)
+}
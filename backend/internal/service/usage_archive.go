@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	// UsageArchiveSourceRetention 保留期清理前的整段归档
+	UsageArchiveSourceRetention = "retention"
+	// UsageArchiveSourceCleanupTask 清理任务删除前的按条件归档
+	UsageArchiveSourceCleanupTask = "cleanup_task"
+
+	UsageArchiveGranularityDay   = "day"
+	UsageArchiveGranularityMonth = "month"
+
+	// UsageArchiveFormatNDJSONGzip 每行一条 usage_logs 记录（row_to_json），整体 gzip 压缩
+	UsageArchiveFormatNDJSONGzip = "ndjson.gz"
+
+	UsageArchiveRehydrateRunning = "running"
+	UsageArchiveRehydrateReady   = "ready"
+	UsageArchiveRehydrateFailed  = "failed"
+)
+
+// UsageLogArchive 归档索引记录：一条记录对应对象存储中的一个归档文件
+type UsageLogArchive struct {
+	ID              int64
+	Source          string
+	TaskID          *int64
+	Granularity     string
+	RangeStart      time.Time
+	RangeEnd        time.Time
+	Filters         UsageCleanupFilters
+	ProfileID       string
+	Bucket          string
+	ObjectKey       string
+	Format          string
+	RowCount        int64
+	SizeBytes       int64
+	SHA256          string
+	MinLogID        *int64
+	MaxLogID        *int64
+	RehydrateStatus string
+	RehydratedRows  int64
+	RehydrateError  *string
+	RehydratedAt    *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// UsageArchiveExportStats 导出统计
+type UsageArchiveExportStats struct {
+	Rows     int64
+	MinLogID *int64
+	MaxLogID *int64
+}
+
+// UsageArchiveLogFilters 回灌数据查询条件（时间范围为左闭右开）
+type UsageArchiveLogFilters struct {
+	UserID    *int64
+	APIKeyID  *int64
+	ArchiveID *int64
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// UsageArchiveLogRow 回灌后的单条使用记录
+type UsageArchiveLogRow struct {
+	ArchiveID  int64           `json:"archive_id"`
+	UsageLogID int64           `json:"usage_log_id"`
+	UserID     int64           `json:"user_id"`
+	APIKeyID   int64           `json:"api_key_id"`
+	AccountID  int64           `json:"account_id"`
+	GroupID    *int64          `json:"group_id,omitempty"`
+	Model      string          `json:"model"`
+	TotalCost  float64         `json:"total_cost"`
+	ActualCost float64         `json:"actual_cost"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// UsageStatementLine 对账单按模型汇总的一行
+type UsageStatementLine struct {
+	Model               string  `json:"model"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalCost           float64 `json:"total_cost"`
+	ActualCost          float64 `json:"actual_cost"`
+}
+
+// UsageStatement 用户对账单（合并在线 usage_logs 与已回灌的归档数据）
+type UsageStatement struct {
+	UserID     int64                `json:"user_id"`
+	StartTime  time.Time            `json:"start_time"`
+	EndTime    time.Time            `json:"end_time"`
+	Lines      []UsageStatementLine `json:"lines"`
+	Requests   int64                `json:"requests"`
+	TotalCost  float64              `json:"total_cost"`
+	ActualCost float64              `json:"actual_cost"`
+	// UncoveredArchives 时间范围内尚未回灌的归档数量；非 0 表示对账单可能不完整
+	UncoveredArchives int `json:"uncovered_archives"`
+}
+
+// UsageArchiveRepository 归档索引与回灌数据持久层接口
+type UsageArchiveRepository interface {
+	// ExportUsageLogs 将命中过滤条件的 usage_logs 按 id 升序逐行写入 w（NDJSON）
+	ExportUsageLogs(ctx context.Context, filters UsageCleanupFilters, w io.Writer) (*UsageArchiveExportStats, error)
+	// OldestUsageLogTime 返回早于 before 的最早一条 usage_logs 时间；不存在时返回 nil
+	OldestUsageLogTime(ctx context.Context, before time.Time) (*time.Time, error)
+	// FindArchive 按来源与起始时间查找归档；不存在时返回 nil
+	FindArchive(ctx context.Context, source string, granularity string, taskID *int64, rangeStart time.Time) (*UsageLogArchive, error)
+	CreateArchive(ctx context.Context, archive *UsageLogArchive) error
+	GetArchive(ctx context.Context, id int64) (*UsageLogArchive, error)
+	ListArchives(ctx context.Context, params pagination.PaginationParams) ([]UsageLogArchive, *pagination.PaginationResult, error)
+	// CountArchivesWithoutRehydration 统计与时间范围重叠且未回灌完成的归档数量
+	CountArchivesWithoutRehydration(ctx context.Context, start, end time.Time) (int, error)
+	// ClaimRehydration 将归档标记为回灌中；已在回灌中时返回 false
+	ClaimRehydration(ctx context.Context, id int64) (bool, error)
+	UpdateRehydration(ctx context.Context, id int64, status string, rows int64, errMsg *string) error
+	InsertRehydratedRows(ctx context.Context, archiveID int64, rows []json.RawMessage) error
+	DeleteRehydratedRows(ctx context.Context, archiveID int64) (int64, error)
+	ListRehydratedLogs(ctx context.Context, filters UsageArchiveLogFilters, params pagination.PaginationParams) ([]UsageArchiveLogRow, *pagination.PaginationResult, error)
+	GetUserStatement(ctx context.Context, userID int64, start, end time.Time) ([]UsageStatementLine, error)
+}
+
+// UsageArchiveObjectRef 已上传归档对象的位置
+type UsageArchiveObjectRef struct {
+	ProfileID string
+	Bucket    string
+	Key       string
+}
+
+// UsageArchiveObjectStore 归档对象存储接口
+type UsageArchiveObjectStore interface {
+	// PutObject 上传对象；key 为相对路径，实现方负责拼接 profile 级前缀
+	PutObject(ctx context.Context, profileID, key string, body io.ReadSeeker, size int64, contentType string) (*UsageArchiveObjectRef, error)
+	GetObject(ctx context.Context, ref UsageArchiveObjectRef) (io.ReadCloser, error)
+}
+
+// UsageLogArchiver 在删除 usage_logs 之前负责归档
+type UsageLogArchiver interface {
+	// ArchiveBefore 归档 cutoff 之前所有完整时间段，返回可以安全删除的截止时间（不晚于 cutoff）
+	ArchiveBefore(ctx context.Context, cutoff time.Time) (time.Time, error)
+	// ArchiveCleanupTask 归档清理任务即将删除的数据
+	ArchiveCleanupTask(ctx context.Context, taskID int64, filters UsageCleanupFilters) error
+}
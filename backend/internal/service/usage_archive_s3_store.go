@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// UsageArchiveS3Store 基于系统设置中 S3 多配置的归档对象存储。
+// 每次调用按 profile 重新解析配置，保证配置变更后立即生效。
+type UsageArchiveS3Store struct {
+	settingService *SettingService
+}
+
+// NewUsageArchiveS3Store 创建归档对象存储
+func NewUsageArchiveS3Store(settingService *SettingService) *UsageArchiveS3Store {
+	return &UsageArchiveS3Store{settingService: settingService}
+}
+
+func (s *UsageArchiveS3Store) resolve(ctx context.Context, profileID string) (*s3.Client, *SoraS3Settings, string, error) {
+	if s == nil || s.settingService == nil {
+		return nil, nil, "", fmt.Errorf("setting service not available")
+	}
+	cfg, resolvedID, err := s.settingService.GetS3ProfileSettings(ctx, profileID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("load s3 profile: %w", err)
+	}
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, nil, "", fmt.Errorf("s3 profile %q incomplete: bucket, access_key_id, secret_access_key are required", resolvedID)
+	}
+	client, _, err := buildSoraS3Client(ctx, cfg)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return client, cfg, resolvedID, nil
+}
+
+// PutObject 上传归档文件
+func (s *UsageArchiveS3Store) PutObject(ctx context.Context, profileID, key string, body io.ReadSeeker, size int64, contentType string) (*UsageArchiveObjectRef, error) {
+	client, cfg, resolvedID, err := s.resolve(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	objectKey := strings.TrimLeft(key, "/")
+	if prefix := strings.Trim(cfg.Prefix, "/"); prefix != "" {
+		objectKey = prefix + "/" + objectKey
+	}
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        &cfg.Bucket,
+		Key:           &objectKey,
+		Body:          body,
+		ContentLength: &size,
+		ContentType:   &contentType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 upload: %w", err)
+	}
+	return &UsageArchiveObjectRef{ProfileID: resolvedID, Bucket: cfg.Bucket, Key: objectKey}, nil
+}
+
+// GetObject 下载归档文件（调用方负责关闭）
+func (s *UsageArchiveS3Store) GetObject(ctx context.Context, ref UsageArchiveObjectRef) (io.ReadCloser, error) {
+	client, cfg, _, err := s.resolve(ctx, ref.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	bucket := ref.Bucket
+	if bucket == "" {
+		bucket = cfg.Bucket
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &ref.Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 download: %w", err)
+	}
+	return out.Body, nil
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+const (
+	defaultUsageArchiveTimeout            = time.Hour
+	defaultUsageArchiveRehydrateBatchSize = 1000
+	defaultUsageArchiveRehydrateTimeout   = 2 * time.Hour
+	usageArchiveMaxLineBytes              = 4 << 20
+)
+
+var (
+	ErrUsageArchiveNotFound          = infraerrors.NotFound("USAGE_ARCHIVE_NOT_FOUND", "usage archive not found")
+	ErrUsageArchiveDisabled          = infraerrors.New(http.StatusServiceUnavailable, "USAGE_ARCHIVE_DISABLED", "usage archive is disabled")
+	ErrUsageArchiveRehydrateConflict = infraerrors.Conflict("USAGE_ARCHIVE_REHYDRATE_RUNNING", "usage archive rehydration is already running")
+)
+
+// UsageArchiveService 负责在删除 usage_logs 前导出归档，并支持按需回灌。
+//
+// 归档文件为 gzip 压缩的 NDJSON（每行一条 row_to_json 结果），
+// 上传到系统设置中的 S3 配置；归档索引记录在 usage_log_archives 表中。
+type UsageArchiveService struct {
+	repo  UsageArchiveRepository
+	store UsageArchiveObjectStore
+	cfg   *config.Config
+
+	// archiveMu 串行化归档导出，避免保留清理与清理任务并发导出同一时间段
+	archiveMu sync.Mutex
+}
+
+// NewUsageArchiveService 创建归档服务
+func NewUsageArchiveService(repo UsageArchiveRepository, store UsageArchiveObjectStore, cfg *config.Config) *UsageArchiveService {
+	return &UsageArchiveService{repo: repo, store: store, cfg: cfg}
+}
+
+// Enabled 返回归档是否启用
+func (s *UsageArchiveService) Enabled() bool {
+	return s != nil && s.repo != nil && s.store != nil && s.cfg != nil && s.cfg.UsageArchive.Enabled
+}
+
+// ArchiveBefore 归档 cutoff 之前的所有完整时间段，返回可安全删除的截止时间。
+// 未启用时原样返回 cutoff；遇到错误时返回已完成归档的边界，调用方只应删除该边界之前的数据。
+func (s *UsageArchiveService) ArchiveBefore(ctx context.Context, cutoff time.Time) (time.Time, error) {
+	if !s.Enabled() {
+		return cutoff, nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.archiveTimeout())
+	defer cancel()
+
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	cutoff = cutoff.UTC()
+	oldest, err := s.repo.OldestUsageLogTime(ctx, cutoff)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query oldest usage log: %w", err)
+	}
+	if oldest == nil {
+		return cutoff, nil
+	}
+
+	granularity := s.granularity()
+	safeCutoff := usageArchivePeriodStart(*oldest, granularity)
+	for start := safeCutoff; ; {
+		end := usageArchivePeriodEnd(start, granularity)
+		if end.After(cutoff) {
+			break
+		}
+		if err := s.archiveRetentionPeriod(ctx, start, end, granularity); err != nil {
+			return safeCutoff, err
+		}
+		safeCutoff = end
+		start = end
+	}
+	return safeCutoff, nil
+}
+
+func (s *UsageArchiveService) archiveRetentionPeriod(ctx context.Context, start, end time.Time, granularity string) error {
+	existing, err := s.repo.FindArchive(ctx, UsageArchiveSourceRetention, granularity, nil, start)
+	if err != nil {
+		return fmt.Errorf("find archive: %w", err)
+	}
+	if existing != nil {
+		return nil
+	}
+	archive := &UsageLogArchive{
+		Source:      UsageArchiveSourceRetention,
+		Granularity: granularity,
+		RangeStart:  start,
+		RangeEnd:    end,
+		Filters:     UsageCleanupFilters{StartTime: start, EndTime: end.Add(-time.Microsecond)},
+	}
+	key := fmt.Sprintf("usage_logs/retention/%s/usage_logs_%s_%s.ndjson.gz", start.Format("2006/01"), start.Format("20060102"), granularity)
+	return s.exportArchive(ctx, archive, key)
+}
+
+// ArchiveCleanupTask 归档清理任务即将删除的数据（按粒度切分，已归档的时间段会跳过，支持任务续跑）
+func (s *UsageArchiveService) ArchiveCleanupTask(ctx context.Context, taskID int64, filters UsageCleanupFilters) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if filters.StartTime.IsZero() || filters.EndTime.IsZero() {
+		return fmt.Errorf("cleanup filters missing time range")
+	}
+
+	s.archiveMu.Lock()
+	defer s.archiveMu.Unlock()
+
+	granularity := s.granularity()
+	taskStart := filters.StartTime.UTC()
+	// 清理任务的结束时间为闭区间，归档范围统一转为左闭右开（PostgreSQL 时间精度为微秒）
+	taskEnd := filters.EndTime.UTC().Truncate(time.Microsecond).Add(time.Microsecond)
+	for periodStart := usageArchivePeriodStart(taskStart, granularity); periodStart.Before(taskEnd); periodStart = usageArchivePeriodEnd(periodStart, granularity) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		start := maxTime(periodStart, taskStart)
+		end := minTime(usageArchivePeriodEnd(periodStart, granularity), taskEnd)
+
+		existing, err := s.repo.FindArchive(ctx, UsageArchiveSourceCleanupTask, granularity, &taskID, start)
+		if err != nil {
+			return fmt.Errorf("find archive: %w", err)
+		}
+		if existing != nil {
+			continue
+		}
+		sliceFilters := filters
+		sliceFilters.StartTime = start
+		sliceFilters.EndTime = end.Add(-time.Microsecond)
+		id := taskID
+		archive := &UsageLogArchive{
+			Source:      UsageArchiveSourceCleanupTask,
+			TaskID:      &id,
+			Granularity: granularity,
+			RangeStart:  start,
+			RangeEnd:    end,
+			Filters:     sliceFilters,
+		}
+		key := fmt.Sprintf("usage_logs/cleanup_tasks/%d/usage_logs_%s_%s.ndjson.gz", taskID, start.Format("20060102T150405"), granularity)
+		if err := s.exportArchive(ctx, archive, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportArchive 导出到临时文件后上传，成功后写入归档索引。
+// 没有数据的时间段只写索引（object_key 为空），用于标记该时间段已处理。
+func (s *UsageArchiveService) exportArchive(ctx context.Context, archive *UsageLogArchive, key string) error {
+	startedAt := time.Now()
+	tmp, err := os.CreateTemp("", "usage-archive-*.ndjson.gz")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	hasher := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(tmp, hasher))
+	stats, err := s.repo.ExportUsageLogs(ctx, archive.Filters, gz)
+	if err != nil {
+		_ = gz.Close()
+		return fmt.Errorf("export usage logs: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("finalize archive: %w", err)
+	}
+
+	archive.Format = UsageArchiveFormatNDJSONGzip
+	archive.RowCount = stats.Rows
+	archive.MinLogID = stats.MinLogID
+	archive.MaxLogID = stats.MaxLogID
+	if stats.Rows > 0 {
+		size, err := tmp.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("stat archive: %w", err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("rewind archive: %w", err)
+		}
+		ref, err := s.store.PutObject(ctx, s.cfg.UsageArchive.S3ProfileID, s.objectKey(key), tmp, size, "application/gzip")
+		if err != nil {
+			return err
+		}
+		archive.ProfileID = ref.ProfileID
+		archive.Bucket = ref.Bucket
+		archive.ObjectKey = ref.Key
+		archive.SizeBytes = size
+		archive.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	}
+	if err := s.repo.CreateArchive(ctx, archive); err != nil {
+		return fmt.Errorf("create archive index: %w", err)
+	}
+	logger.LegacyPrintf("service.usage_archive", "[UsageArchive] archived: id=%d source=%s range=[%s,%s) rows=%d size=%d key=%s duration=%s",
+		archive.ID, archive.Source, archive.RangeStart.Format(time.RFC3339), archive.RangeEnd.Format(time.RFC3339), archive.RowCount, archive.SizeBytes, archive.ObjectKey, time.Since(startedAt))
+	return nil
+}
+
+// ListArchives 分页查询归档索引
+func (s *UsageArchiveService) ListArchives(ctx context.Context, params pagination.PaginationParams) ([]UsageLogArchive, *pagination.PaginationResult, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil, fmt.Errorf("usage archive service not ready")
+	}
+	return s.repo.ListArchives(ctx, params)
+}
+
+// Rehydrate 异步将归档回灌到 usage_log_archive_rows，供临时查询与对账单使用
+func (s *UsageArchiveService) Rehydrate(ctx context.Context, archiveID int64) (*UsageLogArchive, error) {
+	if s == nil || s.repo == nil || s.store == nil {
+		return nil, ErrUsageArchiveDisabled
+	}
+	archive, err := s.repo.GetArchive(ctx, archiveID)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.repo.ClaimRehydration(ctx, archiveID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrUsageArchiveRehydrateConflict
+	}
+	archive.RehydrateStatus = UsageArchiveRehydrateRunning
+	archive.RehydrateError = nil
+	logger.LegacyPrintf("service.usage_archive", "[UsageArchive] rehydrate requested: id=%d key=%s rows=%d", archive.ID, archive.ObjectKey, archive.RowCount)
+	go s.runRehydration(*archive)
+	return archive, nil
+}
+
+func (s *UsageArchiveService) runRehydration(archive UsageLogArchive) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultUsageArchiveRehydrateTimeout)
+	defer cancel()
+
+	rows, err := s.rehydrate(ctx, archive)
+	status := UsageArchiveRehydrateReady
+	var errMsg *string
+	if err != nil {
+		status = UsageArchiveRehydrateFailed
+		msg := strings.TrimSpace(err.Error())
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		errMsg = &msg
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] rehydrate failed: id=%d rows=%d err=%s", archive.ID, rows, msg)
+	} else {
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] rehydrate done: id=%d rows=%d", archive.ID, rows)
+	}
+	updateCtx, updateCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer updateCancel()
+	if updateErr := s.repo.UpdateRehydration(updateCtx, archive.ID, status, rows, errMsg); updateErr != nil {
+		logger.LegacyPrintf("service.usage_archive", "[UsageArchive] update rehydrate status failed: id=%d err=%v", archive.ID, updateErr)
+	}
+}
+
+func (s *UsageArchiveService) rehydrate(ctx context.Context, archive UsageLogArchive) (int64, error) {
+	// 先清掉上一次（可能中断的）回灌结果，保证幂等
+	if _, err := s.repo.DeleteRehydratedRows(ctx, archive.ID); err != nil {
+		return 0, fmt.Errorf("reset rehydrated rows: %w", err)
+	}
+	if archive.RowCount == 0 || archive.ObjectKey == "" {
+		return 0, nil
+	}
+
+	body, err := s.store.GetObject(ctx, UsageArchiveObjectRef{ProfileID: archive.ProfileID, Bucket: archive.Bucket, Key: archive.ObjectKey})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = body.Close() }()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return 0, fmt.Errorf("open archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	batchSize := s.rehydrateBatchSize()
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), usageArchiveMaxLineBytes)
+	batch := make([]json.RawMessage, 0, batchSize)
+	var total int64
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.repo.InsertRehydratedRows(ctx, archive.ID, batch); err != nil {
+			return fmt.Errorf("insert rehydrated rows: %w", err)
+		}
+		total += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return total, fmt.Errorf("invalid archive line at row %d", total+int64(len(batch))+1)
+		}
+		batch = append(batch, json.RawMessage(append([]byte(nil), line...)))
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return total, fmt.Errorf("read archive: %w", err)
+	}
+	if err := flush(); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// EvictRehydration 删除某个归档的回灌数据
+func (s *UsageArchiveService) EvictRehydration(ctx context.Context, archiveID int64) error {
+	if s == nil || s.repo == nil {
+		return fmt.Errorf("usage archive service not ready")
+	}
+	archive, err := s.repo.GetArchive(ctx, archiveID)
+	if err != nil {
+		return err
+	}
+	if archive.RehydrateStatus == UsageArchiveRehydrateRunning {
+		return ErrUsageArchiveRehydrateConflict
+	}
+	deleted, err := s.repo.DeleteRehydratedRows(ctx, archiveID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateRehydration(ctx, archiveID, "", 0, nil); err != nil {
+		return err
+	}
+	logger.LegacyPrintf("service.usage_archive", "[UsageArchive] rehydration evicted: id=%d rows=%d", archiveID, deleted)
+	return nil
+}
+
+// ListRehydratedLogs 查询已回灌的使用记录
+func (s *UsageArchiveService) ListRehydratedLogs(ctx context.Context, filters UsageArchiveLogFilters, params pagination.PaginationParams) ([]UsageArchiveLogRow, *pagination.PaginationResult, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil, fmt.Errorf("usage archive service not ready")
+	}
+	if !filters.StartTime.IsZero() && !filters.EndTime.IsZero() && !filters.EndTime.After(filters.StartTime) {
+		return nil, nil, infraerrors.BadRequest("USAGE_ARCHIVE_INVALID_RANGE", "end_date must be after start_date")
+	}
+	return s.repo.ListRehydratedLogs(ctx, filters, params)
+}
+
+// GetUserStatement 生成用户对账单：合并在线 usage_logs 与已回灌的归档数据（按 usage_log_id 去重）
+func (s *UsageArchiveService) GetUserStatement(ctx context.Context, userID int64, start, end time.Time) (*UsageStatement, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("usage archive service not ready")
+	}
+	if userID <= 0 {
+		return nil, infraerrors.BadRequest("USAGE_ARCHIVE_INVALID_USER", "user_id is required")
+	}
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return nil, infraerrors.BadRequest("USAGE_ARCHIVE_INVALID_RANGE", "end_date must be after start_date")
+	}
+	lines, err := s.repo.GetUserStatement(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	uncovered, err := s.repo.CountArchivesWithoutRehydration(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	statement := &UsageStatement{
+		UserID:            userID,
+		StartTime:         start,
+		EndTime:           end,
+		Lines:             lines,
+		UncoveredArchives: uncovered,
+	}
+	for _, line := range lines {
+		statement.Requests += line.Requests
+		statement.TotalCost += line.TotalCost
+		statement.ActualCost += line.ActualCost
+	}
+	return statement, nil
+}
+
+func (s *UsageArchiveService) objectKey(key string) string {
+	prefix := strings.Trim(strings.TrimSpace(s.cfg.UsageArchive.Prefix), "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
+
+func (s *UsageArchiveService) granularity() string {
+	if s == nil || s.cfg == nil {
+		return UsageArchiveGranularityMonth
+	}
+	if strings.EqualFold(strings.TrimSpace(s.cfg.UsageArchive.Granularity), UsageArchiveGranularityDay) {
+		return UsageArchiveGranularityDay
+	}
+	return UsageArchiveGranularityMonth
+}
+
+func (s *UsageArchiveService) archiveTimeout() time.Duration {
+	if s == nil || s.cfg == nil || s.cfg.UsageArchive.ArchiveTimeoutSeconds <= 0 {
+		return defaultUsageArchiveTimeout
+	}
+	return time.Duration(s.cfg.UsageArchive.ArchiveTimeoutSeconds) * time.Second
+}
+
+func (s *UsageArchiveService) rehydrateBatchSize() int {
+	if s == nil || s.cfg == nil || s.cfg.UsageArchive.RehydrateBatchSize <= 0 {
+		return defaultUsageArchiveRehydrateBatchSize
+	}
+	return s.cfg.UsageArchive.RehydrateBatchSize
+}
+
+// usageArchivePeriodStart 返回 t 所在归档时间段的起点（UTC）
+func usageArchivePeriodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == UsageArchiveGranularityDay {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// usageArchivePeriodEnd 返回 start 所在归档时间段的终点（不含）
+func usageArchivePeriodEnd(start time.Time, granularity string) time.Time {
+	start = usageArchivePeriodStart(start, granularity)
+	if granularity == UsageArchiveGranularityDay {
+		return start.AddDate(0, 0, 1)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveRepoStub struct {
+	UsageArchiveRepository
+
+	oldest      *time.Time
+	rowsPerCall int64
+	exportErrAt int
+	exportCalls []UsageCleanupFilters
+	existing    map[time.Time]bool
+	created     []*UsageLogArchive
+}
+
+func (s *archiveRepoStub) ExportUsageLogs(ctx context.Context, filters UsageCleanupFilters, w io.Writer) (*UsageArchiveExportStats, error) {
+	s.exportCalls = append(s.exportCalls, filters)
+	if s.exportErrAt > 0 && len(s.exportCalls) == s.exportErrAt {
+		return nil, errors.New("export failed")
+	}
+	for i := int64(0); i < s.rowsPerCall; i++ {
+		if _, err := io.WriteString(w, `{"id":1}`+"\n"); err != nil {
+			return nil, err
+		}
+	}
+	return &UsageArchiveExportStats{Rows: s.rowsPerCall}, nil
+}
+
+func (s *archiveRepoStub) OldestUsageLogTime(ctx context.Context, before time.Time) (*time.Time, error) {
+	return s.oldest, nil
+}
+
+func (s *archiveRepoStub) FindArchive(ctx context.Context, source string, granularity string, taskID *int64, rangeStart time.Time) (*UsageLogArchive, error) {
+	if s.existing[rangeStart] {
+		return &UsageLogArchive{Source: source, RangeStart: rangeStart}, nil
+	}
+	return nil, nil
+}
+
+func (s *archiveRepoStub) CreateArchive(ctx context.Context, archive *UsageLogArchive) error {
+	archive.ID = int64(len(s.created) + 1)
+	s.created = append(s.created, archive)
+	return nil
+}
+
+type archiveStoreStub struct {
+	keys     []string
+	payloads [][]byte
+}
+
+func (s *archiveStoreStub) PutObject(ctx context.Context, profileID, key string, body io.ReadSeeker, size int64, contentType string) (*UsageArchiveObjectRef, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	s.keys = append(s.keys, key)
+	s.payloads = append(s.payloads, data)
+	return &UsageArchiveObjectRef{ProfileID: "default", Bucket: "bucket", Key: key}, nil
+}
+
+func (s *archiveStoreStub) GetObject(ctx context.Context, ref UsageArchiveObjectRef) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newArchiveServiceForTest(repo *archiveRepoStub, store *archiveStoreStub, granularity string) *UsageArchiveService {
+	cfg := &config.Config{UsageArchive: config.UsageArchiveConfig{
+		Enabled:     true,
+		Prefix:      "archive",
+		Granularity: granularity,
+	}}
+	return NewUsageArchiveService(repo, store, cfg)
+}
+
+func TestUsageArchivePeriodBoundaries(t *testing.T) {
+	ts := time.Date(2026, 2, 15, 13, 4, 5, 0, time.FixedZone("UTC+8", 8*3600))
+
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), usageArchivePeriodStart(ts, UsageArchiveGranularityMonth))
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), usageArchivePeriodEnd(ts, UsageArchiveGranularityMonth))
+	require.Equal(t, time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), usageArchivePeriodStart(ts, UsageArchiveGranularityDay))
+	require.Equal(t, time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC), usageArchivePeriodEnd(ts, UsageArchiveGranularityDay))
+}
+
+func TestUsageArchiveServiceDisabledPassesCutoffThrough(t *testing.T) {
+	svc := NewUsageArchiveService(&archiveRepoStub{}, &archiveStoreStub{}, &config.Config{})
+	cutoff := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	got, err := svc.ArchiveBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	require.Equal(t, cutoff, got)
+
+	var nilSvc *UsageArchiveService
+	got, err = nilSvc.ArchiveBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	require.Equal(t, cutoff, got)
+}
+
+func TestUsageArchiveServiceArchiveBeforeCompletePeriodsOnly(t *testing.T) {
+	oldest := time.Date(2026, 1, 20, 8, 0, 0, 0, time.UTC)
+	repo := &archiveRepoStub{
+		oldest:      &oldest,
+		rowsPerCall: 2,
+		existing:    map[time.Time]bool{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC): true},
+	}
+	store := &archiveStoreStub{}
+	svc := newArchiveServiceForTest(repo, store, UsageArchiveGranularityMonth)
+
+	cutoff := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	safe, err := svc.ArchiveBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	// 三月未结束，只能删除到 3 月 1 日
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), safe)
+
+	// 一月已归档跳过，仅导出二月
+	require.Len(t, repo.exportCalls, 1)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), repo.exportCalls[0].StartTime)
+	require.Len(t, repo.created, 1)
+	require.Equal(t, UsageArchiveSourceRetention, repo.created[0].Source)
+	require.Equal(t, int64(2), repo.created[0].RowCount)
+	require.NotEmpty(t, repo.created[0].SHA256)
+	require.Equal(t, []string{"archive/usage_logs/retention/2026/02/usage_logs_20260201_month.ndjson.gz"}, store.keys)
+
+	gz, err := gzip.NewReader(bytes.NewReader(store.payloads[0]))
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":1}\n{\"id\":1}\n", string(content))
+}
+
+func TestUsageArchiveServiceArchiveBeforeStopsAtFailure(t *testing.T) {
+	oldest := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	repo := &archiveRepoStub{oldest: &oldest, rowsPerCall: 1, exportErrAt: 2}
+	svc := newArchiveServiceForTest(repo, &archiveStoreStub{}, UsageArchiveGranularityMonth)
+
+	safe, err := svc.ArchiveBefore(context.Background(), time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC))
+	require.Error(t, err)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), safe)
+	require.Len(t, repo.created, 1)
+}
+
+func TestUsageArchiveServiceArchiveCleanupTaskSlices(t *testing.T) {
+	repo := &archiveRepoStub{
+		existing: map[time.Time]bool{time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC): true},
+	}
+	store := &archiveStoreStub{}
+	svc := newArchiveServiceForTest(repo, store, UsageArchiveGranularityDay)
+
+	userID := int64(7)
+	filters := UsageCleanupFilters{
+		StartTime: time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2026, 1, 12, 23, 59, 59, 999999999, time.UTC),
+		UserID:    &userID,
+	}
+	require.NoError(t, svc.ArchiveCleanupTask(context.Background(), 42, filters))
+
+	// 1/10 从任务起点开始，1/11 已归档跳过，1/12 截止到任务终点
+	require.Len(t, repo.exportCalls, 2)
+	require.Equal(t, filters.StartTime, repo.exportCalls[0].StartTime)
+	require.Equal(t, time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC).Add(-time.Microsecond), repo.exportCalls[0].EndTime)
+	require.Equal(t, time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC), repo.exportCalls[1].StartTime)
+	require.Equal(t, &userID, repo.exportCalls[1].UserID)
+
+	// 空时间段只写索引不上传
+	require.Empty(t, store.keys)
+	require.Len(t, repo.created, 2)
+	for _, archive := range repo.created {
+		require.Equal(t, UsageArchiveSourceCleanupTask, archive.Source)
+		require.Equal(t, int64(42), *archive.TaskID)
+		require.Empty(t, archive.ObjectKey)
+	}
+}
@@ -27,6 +27,8 @@ type UsageCleanupService struct {
 	repo        UsageCleanupRepository
 	timingWheel *TimingWheelService
 	dashboard   *DashboardAggregationService
+	archiver    UsageLogArchiver
+	partitions  *TimePartitionService
 	cfg         *config.Config
 
 	running   int32
@@ -49,6 +51,22 @@ func NewUsageCleanupService(repo UsageCleanupRepository, timingWheel *TimingWhee
 	}
 }
 
+// SetArchiver 设置删除前的归档器；为 nil 时直接删除
+func (s *UsageCleanupService) SetArchiver(archiver UsageLogArchiver) {
+	if s == nil {
+		return
+	}
+	s.archiver = archiver
+}
+
+// SetPartitionService 设置分区服务；usage_logs 已分区时，仅按时间清理的任务直接删除整月分区
+func (s *UsageCleanupService) SetPartitionService(partitions *TimePartitionService) {
+	if s == nil {
+		return
+	}
+	s.partitions = partitions
+}
+
 func describeUsageCleanupFilters(filters UsageCleanupFilters) string {
 	var parts []string
 	parts = append(parts, "start="+filters.StartTime.UTC().Format(time.RFC3339))
@@ -197,6 +215,44 @@ func (s *UsageCleanupService) executeTask(ctx context.Context, task *UsageCleanu
 	logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task started: task=%d batch_size=%d deleted_rows=%d %s", task.ID, batchSize, deletedTotal, describeUsageCleanupFilters(task.Filters))
 	var batchNum int
 
+	if s.archiver != nil {
+		if err := s.archiver.ArchiveCleanupTask(ctx, task.ID, task.Filters); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task interrupted during archive: task=%d err=%v", task.ID, err)
+				return
+			}
+			// 归档失败时不删除数据，避免不可恢复的数据丢失
+			s.markTaskFailed(task.ID, deletedTotal, fmt.Errorf("archive before delete: %w", err))
+			return
+		}
+	}
+
+	if s.partitions != nil && isTimeOnlyUsageCleanupFilters(task.Filters) {
+		// 结束时间为闭区间，转为左闭右开后再匹配整月分区
+		end := task.Filters.EndTime.Truncate(time.Microsecond).Add(time.Microsecond)
+		dropped, err := s.partitions.DropPartitionsInRange(ctx, "usage_logs", task.Filters.StartTime, end)
+		for _, p := range dropped {
+			// 分区删除无法得到精确行数，使用统计信息中的估算值
+			deletedTotal += p.EstimatedRows
+		}
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task interrupted during partition drop: task=%d err=%v", task.ID, err)
+				return
+			}
+			s.markTaskFailed(task.ID, deletedTotal, fmt.Errorf("drop partitions: %w", err))
+			return
+		}
+		if len(dropped) > 0 {
+			logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task dropped partitions: task=%d partitions=%d deleted_rows=%d", task.ID, len(dropped), deletedTotal)
+			updateCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			if err := s.repo.UpdateTaskProgress(updateCtx, task.ID, deletedTotal); err != nil {
+				logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task progress update failed: task=%d deleted_rows=%d err=%v", task.ID, deletedTotal, err)
+			}
+			cancel()
+		}
+	}
+
 	for {
 		if ctx != nil && ctx.Err() != nil {
 			logger.LegacyPrintf("service.usage_cleanup", "[UsageCleanup] task interrupted: task=%d err=%v", task.ID, ctx.Err())
@@ -256,6 +312,18 @@ func (s *UsageCleanupService) executeTask(ctx context.Context, task *UsageCleanu
 	}
 }
 
+// isTimeOnlyUsageCleanupFilters 判断清理条件是否只包含时间范围（可直接按分区删除）
+func isTimeOnlyUsageCleanupFilters(filters UsageCleanupFilters) bool {
+	return filters.UserID == nil &&
+		filters.APIKeyID == nil &&
+		filters.AccountID == nil &&
+		filters.GroupID == nil &&
+		filters.Model == nil &&
+		filters.RequestType == nil &&
+		filters.Stream == nil &&
+		filters.BillingType == nil
+}
+
 func (s *UsageCleanupService) markTaskFailed(taskID int64, deletedRows int64, err error) {
 	msg := strings.TrimSpace(err.Error())
 	if len(msg) > 500 {
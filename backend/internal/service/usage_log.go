@@ -7,8 +7,9 @@ import (
 )
 
 const (
-	BillingTypeBalance      int8 = 0 // 钱包余额
-	BillingTypeSubscription int8 = 1 // 订阅套餐
+	BillingTypeBalance       int8 = 0 // 钱包余额
+	BillingTypeSubscription  int8 = 1 // 订阅套餐
+	BillingTypeResponseCache int8 = 2 // 网关响应缓存命中（按命中价格从余额或订阅扣费）
 )
 
 type RequestType int16
@@ -135,6 +136,18 @@ type UsageLog struct {
 	// Cache TTL Override 标记（管理员强制替换了缓存 TTL 计费）
 	CacheTTLOverridden bool
 
+	// A/B 实验标记（未参与实验时为空）
+	ExperimentID      *int64
+	ExperimentVariant *string
+
+	// 自动缓存断点：注入数量，及相对缓存 Token 全部按普通输入计费的节省金额（写入缓存的轮次可能为负）
+	AutoCacheBreakpoints int
+	PromptCacheSavings   float64
+
+	// 上下文策略：处理方式（truncated / summarized / summary_request，未处理时为空）及丢弃或摘要的消息数
+	ContextAction          *string
+	ContextDroppedMessages int
+
 	// 图片生成字段
 	ImageCount int
 	ImageSize  *string
@@ -29,6 +29,13 @@ type User struct {
 	SoraStorageQuotaBytes int64 // 用户级 Sora 存储配额（0 表示使用分组或系统默认值）
 	SoraStorageUsedBytes  int64 // Sora 存储已用量
 
+	// 请求速率限制（用户所有 Key 合计，滑动窗口），0 表示不限制
+	RPMLimit int
+	TPMLimit int
+
+	// 账号满载排队优先级（0-9，越大越优先）
+	QueuePriority int
+
 	// TOTP 双因素认证字段
 	TotpSecretEncrypted *string    // AES-256-GCM 加密的 TOTP 密钥
 	TotpEnabled         bool       // 是否启用 TOTP
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// ErrUserSessionNotFound 会话不存在或不属于该用户
+var ErrUserSessionNotFound = infraerrors.NotFound("SESSION_NOT_FOUND", "session not found")
+
+// knownDeviceTTL 已知设备的记忆时长，超过该时长未登录的设备再次登录会重新提醒
+const knownDeviceTTL = 180 * 24 * time.Hour
+
+// UserSession 用户登录会话，对应一个 Refresh Token 家族（ID 即家族 ID）
+type UserSession struct {
+	ID           string    `json:"id"`
+	UserID       int64     `json:"user_id"`
+	TokenVersion int64     `json:"token_version"` // 签发时的 TokenVersion，密码修改后会话失效
+	Device       string    `json:"device"`        // 由 User-Agent 解析出的设备描述，如 "Chrome on macOS"
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Current 是否为发起查询的当前会话（仅查询时填充，不持久化）
+	Current bool `json:"-"`
+}
+
+// UserSessionCache 管理用户会话元数据的 Redis 缓存
+//
+// Key 格式:
+//   - user_session:{family_id}         -> UserSession (JSON)
+//   - user_sessions:{user_id}          -> Set<family_id>
+//   - user_known_devices:{user_id}     -> Set<device>
+type UserSessionCache interface {
+	// SaveSession 保存会话，ttl 与 Refresh Token 有效期一致
+	SaveSession(ctx context.Context, session *UserSession, ttl time.Duration) error
+	// GetSession 获取会话，不存在时返回 (nil, nil)
+	GetSession(ctx context.Context, sessionID string) (*UserSession, error)
+	// ListUserSessions 获取用户的所有会话（顺带清理已过期的索引）
+	ListUserSessions(ctx context.Context, userID int64) ([]*UserSession, error)
+	// DeleteSession 删除单个会话
+	DeleteSession(ctx context.Context, userID int64, sessionID string) error
+	// DeleteUserSessions 删除用户的所有会话
+	DeleteUserSessions(ctx context.Context, userID int64) error
+	// RememberDevice 记录用户登录过的设备；仅当用户已有设备记录且该设备首次出现时返回 true
+	RememberDevice(ctx context.Context, userID int64, device string, ttl time.Duration) (bool, error)
+}
+
+// SessionClientInfo 签发 Token 时的客户端信息
+type SessionClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// WithSessionClientInfo 将客户端 IP 与 User-Agent 写入 context，供会话记录使用
+func WithSessionClientInfo(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, ctxkey.SessionClient, SessionClientInfo{
+		IP:        strings.TrimSpace(ip),
+		UserAgent: strings.TrimSpace(userAgent),
+	})
+}
+
+func sessionClientInfoFromContext(ctx context.Context) SessionClientInfo {
+	if ctx == nil {
+		return SessionClientInfo{}
+	}
+	info, _ := ctx.Value(ctxkey.SessionClient).(SessionClientInfo)
+	return info
+}
+
+// SetUserSessionCache sets the optional session metadata store.
+func (s *AuthService) SetUserSessionCache(cache UserSessionCache) {
+	s.sessionCache = cache
+}
+
+// trackSession 签发或轮转 Refresh Token 时更新会话的设备、IP 与最近活跃时间。
+// 新会话会检查是否为新设备登录并发送邮件提醒。会话记录失败不影响登录，
+// 返回 false 表示会话未记录，调用方签发的 Access Token 不应携带会话 ID。
+func (s *AuthService) trackSession(ctx context.Context, user *User, familyID string, isNew bool, ttl time.Duration) bool {
+	if s.sessionCache == nil {
+		return false
+	}
+
+	var session *UserSession
+	if !isNew {
+		existing, err := s.sessionCache.GetSession(ctx, familyID)
+		if err != nil {
+			logger.LegacyPrintf("service.auth", "[Auth] Failed to load session %s: %v", familyID, err)
+		}
+		session = existing
+	}
+
+	now := time.Now()
+	if session == nil {
+		session = &UserSession{ID: familyID, UserID: user.ID, CreatedAt: now}
+	}
+	info := sessionClientInfoFromContext(ctx)
+	if info.IP != "" {
+		session.IP = info.IP
+	}
+	if info.UserAgent != "" {
+		session.UserAgent = info.UserAgent
+		session.Device = describeUserAgent(info.UserAgent)
+	}
+	session.TokenVersion = user.TokenVersion
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(ttl)
+
+	if err := s.sessionCache.SaveSession(ctx, session, ttl); err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to save session for user %d: %v", user.ID, err)
+		return false
+	}
+
+	if isNew {
+		s.notifyNewDeviceLogin(ctx, user, session)
+	}
+	return true
+}
+
+// notifyNewDeviceLogin 用户首次在某设备上登录时异步发送邮件提醒
+func (s *AuthService) notifyNewDeviceLogin(ctx context.Context, user *User, session *UserSession) {
+	if session.Device == "" {
+		return
+	}
+	isNewDevice, err := s.sessionCache.RememberDevice(ctx, user.ID, strings.ToLower(session.Device), knownDeviceTTL)
+	if err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to remember device for user %d: %v", user.ID, err)
+		return
+	}
+	if !isNewDevice || s.emailQueueService == nil || isReservedEmail(user.Email) {
+		return
+	}
+
+	siteName := "Sub2API"
+	if s.settingService != nil {
+		siteName = s.settingService.GetSiteName(ctx)
+	}
+	if err := s.emailQueueService.EnqueueNewDeviceLogin(user.Email, siteName, session); err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to enqueue new device login email for user %d: %v", user.ID, err)
+	}
+}
+
+// ListUserSessions 列出用户的活跃会话（按最近活跃时间倒序），currentSessionID 对应的会话标记为当前会话。
+// 已被撤销或因密码修改失效的会话会被清理。
+func (s *AuthService) ListUserSessions(ctx context.Context, userID int64, currentSessionID string) ([]*UserSession, error) {
+	if s.sessionCache == nil || s.refreshTokenCache == nil {
+		return []*UserSession{}, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.sessionCache.ListUserSessions(ctx, userID)
+	if err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to list sessions for user %d: %v", userID, err)
+		return nil, ErrServiceUnavailable
+	}
+
+	active := make([]*UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.TokenVersion != user.TokenVersion || !s.sessionHasRefreshTokens(ctx, session.ID) {
+			_ = s.sessionCache.DeleteSession(ctx, userID, session.ID)
+			continue
+		}
+		session.Current = currentSessionID != "" && session.ID == currentSessionID
+		active = append(active, session)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].LastSeenAt.After(active[j].LastSeenAt)
+	})
+	return active, nil
+}
+
+// sessionHasRefreshTokens 会话对应的 Token 家族是否仍存在（登出、重放检测等会删除整个家族）
+func (s *AuthService) sessionHasRefreshTokens(ctx context.Context, familyID string) bool {
+	hashes, err := s.refreshTokenCache.GetFamilyTokenHashes(ctx, familyID)
+	if err != nil {
+		// 查询失败时保守地保留会话
+		return true
+	}
+	return len(hashes) > 0
+}
+
+// RevokeUserSession 撤销用户的单个会话（删除整个 Refresh Token 家族与会话记录）。
+// 会话记录删除后，该会话已签发的 Access Token 由 IsSessionActive 拒绝。
+func (s *AuthService) RevokeUserSession(ctx context.Context, userID int64, sessionID string) error {
+	if s.sessionCache == nil || s.refreshTokenCache == nil {
+		return ErrUserSessionNotFound
+	}
+	session, err := s.sessionCache.GetSession(ctx, sessionID)
+	if err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to load session %s: %v", sessionID, err)
+		return ErrServiceUnavailable
+	}
+	if session == nil || session.UserID != userID {
+		return ErrUserSessionNotFound
+	}
+	if err := s.refreshTokenCache.DeleteTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("delete token family: %w", err)
+	}
+	return s.sessionCache.DeleteSession(ctx, userID, sessionID)
+}
+
+// IsSessionActive 判断 Access Token 所属会话是否仍存在，会话被撤销、登出或因改密清理后返回 false。
+// 未启用会话记录或 Token 不含会话 ID 时视为有效；查询失败时保守放行，避免缓存故障导致全员登出。
+func (s *AuthService) IsSessionActive(ctx context.Context, userID int64, sessionID string) bool {
+	if s.sessionCache == nil || sessionID == "" {
+		return true
+	}
+	session, err := s.sessionCache.GetSession(ctx, sessionID)
+	if err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to load session %s: %v", sessionID, err)
+		return true
+	}
+	return session != nil && session.UserID == userID
+}
+
+// endSession 删除单个 Token 家族对应的会话记录
+func (s *AuthService) endSession(ctx context.Context, data *RefreshTokenData) {
+	if s.sessionCache == nil || data == nil {
+		return
+	}
+	if err := s.sessionCache.DeleteSession(ctx, data.UserID, data.FamilyID); err != nil {
+		logger.LegacyPrintf("service.auth", "[Auth] Failed to delete session %s: %v", data.FamilyID, err)
+	}
+}
+
+// describeUserAgent 从 User-Agent 中提取浏览器与操作系统，如 "Chrome on macOS"
+func describeUserAgent(ua string) string {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return ""
+	}
+
+	var client string
+	switch {
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "Edge/"):
+		client = "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		client = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		client = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		client = "Safari"
+	default:
+		// 非浏览器客户端（curl、SDK 等）取产品名
+		client = strings.SplitN(strings.Fields(ua)[0], "/", 2)[0]
+	}
+
+	var platform string
+	switch {
+	case strings.Contains(ua, "Windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		platform = "iOS"
+	case strings.Contains(ua, "Android"):
+		platform = "Android"
+	case strings.Contains(ua, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "Linux"):
+		platform = "Linux"
+	}
+
+	if platform == "" {
+		return client
+	}
+	return client + " on " + platform
+}
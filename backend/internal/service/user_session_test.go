@@ -0,0 +1,304 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionUserRepoStub struct {
+	UserRepository
+	users map[int64]*User
+}
+
+func (r *sessionUserRepoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, ErrUserNotFound
+}
+
+// sessionRefreshTokenCacheStub 内存版 Refresh Token 缓存，仅实现会话相关方法
+type sessionRefreshTokenCacheStub struct {
+	RefreshTokenCache
+	tokens   map[string]*RefreshTokenData
+	families map[string][]string
+}
+
+func newSessionRefreshTokenCacheStub() *sessionRefreshTokenCacheStub {
+	return &sessionRefreshTokenCacheStub{tokens: map[string]*RefreshTokenData{}, families: map[string][]string{}}
+}
+
+func (c *sessionRefreshTokenCacheStub) StoreRefreshToken(ctx context.Context, tokenHash string, data *RefreshTokenData, ttl time.Duration) error {
+	c.tokens[tokenHash] = data
+	return nil
+}
+
+func (c *sessionRefreshTokenCacheStub) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshTokenData, error) {
+	if data, ok := c.tokens[tokenHash]; ok {
+		return data, nil
+	}
+	return nil, ErrRefreshTokenNotFound
+}
+
+func (c *sessionRefreshTokenCacheStub) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	delete(c.tokens, tokenHash)
+	return nil
+}
+
+func (c *sessionRefreshTokenCacheStub) DeleteTokenFamily(ctx context.Context, familyID string) error {
+	for _, hash := range c.families[familyID] {
+		delete(c.tokens, hash)
+	}
+	delete(c.families, familyID)
+	return nil
+}
+
+func (c *sessionRefreshTokenCacheStub) AddToUserTokenSet(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+func (c *sessionRefreshTokenCacheStub) AddToFamilyTokenSet(ctx context.Context, familyID string, tokenHash string, ttl time.Duration) error {
+	c.families[familyID] = append(c.families[familyID], tokenHash)
+	return nil
+}
+
+func (c *sessionRefreshTokenCacheStub) GetFamilyTokenHashes(ctx context.Context, familyID string) ([]string, error) {
+	return c.families[familyID], nil
+}
+
+type userSessionCacheStub struct {
+	sessions map[string]*UserSession
+	devices  map[int64]map[string]bool
+	saveErr  error
+}
+
+func newUserSessionCacheStub() *userSessionCacheStub {
+	return &userSessionCacheStub{sessions: map[string]*UserSession{}, devices: map[int64]map[string]bool{}}
+}
+
+func (c *userSessionCacheStub) SaveSession(ctx context.Context, session *UserSession, ttl time.Duration) error {
+	if c.saveErr != nil {
+		return c.saveErr
+	}
+	cp := *session
+	c.sessions[session.ID] = &cp
+	return nil
+}
+
+func (c *userSessionCacheStub) GetSession(ctx context.Context, sessionID string) (*UserSession, error) {
+	if s, ok := c.sessions[sessionID]; ok {
+		cp := *s
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (c *userSessionCacheStub) ListUserSessions(ctx context.Context, userID int64) ([]*UserSession, error) {
+	var out []*UserSession
+	for _, s := range c.sessions {
+		if s.UserID == userID {
+			cp := *s
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (c *userSessionCacheStub) DeleteSession(ctx context.Context, userID int64, sessionID string) error {
+	delete(c.sessions, sessionID)
+	return nil
+}
+
+func (c *userSessionCacheStub) DeleteUserSessions(ctx context.Context, userID int64) error {
+	for id, s := range c.sessions {
+		if s.UserID == userID {
+			delete(c.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (c *userSessionCacheStub) RememberDevice(ctx context.Context, userID int64, device string, ttl time.Duration) (bool, error) {
+	known, existed := c.devices[userID]
+	if !existed {
+		c.devices[userID] = map[string]bool{device: true}
+		return false, nil
+	}
+	if known[device] {
+		return false, nil
+	}
+	known[device] = true
+	return true, nil
+}
+
+const (
+	testChromeMacUA    = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36"
+	testFirefoxLinuxUA = "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+)
+
+func newSessionTestAuthService(user *User) (*AuthService, *userSessionCacheStub) {
+	cfg := &config.Config{JWT: config.JWTConfig{Secret: "test-secret", ExpireHour: 1, RefreshTokenExpireDays: 7}}
+	users := &sessionUserRepoStub{users: map[int64]*User{user.ID: user}}
+	svc := NewAuthService(users, nil, newSessionRefreshTokenCacheStub(), cfg, nil, nil, nil, nil, nil, nil)
+	sessions := newUserSessionCacheStub()
+	svc.SetUserSessionCache(sessions)
+	return svc, sessions
+}
+
+func TestDescribeUserAgent(t *testing.T) {
+	require.Equal(t, "Chrome on macOS", describeUserAgent(testChromeMacUA))
+	require.Equal(t, "Firefox on Linux", describeUserAgent(testFirefoxLinuxUA))
+	require.Equal(t, "Edge on Windows", describeUserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36 Edg/130.0.0.0"))
+	require.Equal(t, "Safari on iOS", describeUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"))
+	require.Equal(t, "curl", describeUserAgent("curl/8.4.0"))
+	require.Equal(t, "", describeUserAgent(""))
+}
+
+func TestGenerateTokenPairTracksSessionAndRefreshUpdatesLastSeen(t *testing.T) {
+	user := &User{ID: 1, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, sessions := newSessionTestAuthService(user)
+
+	ctx := WithSessionClientInfo(context.Background(), "203.0.113.7", testChromeMacUA)
+	pair, err := svc.GenerateTokenPair(ctx, user, "")
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.SessionID)
+
+	session := sessions.sessions[claims.SessionID]
+	require.NotNil(t, session)
+	require.Equal(t, "Chrome on macOS", session.Device)
+	require.Equal(t, "203.0.113.7", session.IP)
+	createdAt := session.CreatedAt
+
+	// 刷新 Token 保持同一会话，更新 IP 与最近活跃时间
+	refreshCtx := WithSessionClientInfo(context.Background(), "198.51.100.9", testChromeMacUA)
+	refreshed, err := svc.RefreshTokenPair(refreshCtx, pair.RefreshToken)
+	require.NoError(t, err)
+	refreshedClaims, err := svc.ValidateToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, claims.SessionID, refreshedClaims.SessionID)
+	require.Len(t, sessions.sessions, 1)
+	require.Equal(t, "198.51.100.9", sessions.sessions[claims.SessionID].IP)
+	require.Equal(t, createdAt, sessions.sessions[claims.SessionID].CreatedAt)
+}
+
+func TestGenerateTokenPairOmitsSessionIDWhenSaveFails(t *testing.T) {
+	user := &User{ID: 1, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, sessions := newSessionTestAuthService(user)
+	sessions.saveErr = errors.New("redis down")
+
+	pair, err := svc.GenerateTokenPair(WithSessionClientInfo(context.Background(), "203.0.113.7", testChromeMacUA), user, "")
+	require.NoError(t, err, "会话记录失败不影响登录")
+
+	claims, err := svc.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.Empty(t, claims.SessionID, "未记录的会话不写入 sid")
+	require.True(t, svc.IsSessionActive(context.Background(), user.ID, claims.SessionID))
+
+	// 缓存恢复后刷新 Token 重新记录会话
+	sessions.saveErr = nil
+	refreshed, err := svc.RefreshTokenPair(context.Background(), pair.RefreshToken)
+	require.NoError(t, err)
+	refreshedClaims, err := svc.ValidateToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, refreshedClaims.SessionID)
+	require.True(t, svc.IsSessionActive(context.Background(), user.ID, refreshedClaims.SessionID))
+}
+
+func TestListAndRevokeUserSessions(t *testing.T) {
+	user := &User{ID: 1, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, sessions := newSessionTestAuthService(user)
+
+	macPair, err := svc.GenerateTokenPair(WithSessionClientInfo(context.Background(), "203.0.113.7", testChromeMacUA), user, "")
+	require.NoError(t, err)
+	_, err = svc.GenerateTokenPair(WithSessionClientInfo(context.Background(), "203.0.113.8", testFirefoxLinuxUA), user, "")
+	require.NoError(t, err)
+	macClaims, err := svc.ValidateToken(macPair.AccessToken)
+	require.NoError(t, err)
+
+	list, err := svc.ListUserSessions(context.Background(), user.ID, macClaims.SessionID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	var current int
+	for _, s := range list {
+		if s.Current {
+			current++
+			require.Equal(t, macClaims.SessionID, s.ID)
+		}
+	}
+	require.Equal(t, 1, current)
+
+	// 其他用户无法撤销
+	require.ErrorIs(t, svc.RevokeUserSession(context.Background(), 2, macClaims.SessionID), ErrUserSessionNotFound)
+
+	require.True(t, svc.IsSessionActive(context.Background(), user.ID, macClaims.SessionID))
+	require.False(t, svc.IsSessionActive(context.Background(), 2, macClaims.SessionID), "会话不属于该用户")
+
+	require.NoError(t, svc.RevokeUserSession(context.Background(), user.ID, macClaims.SessionID))
+	_, err = svc.RefreshTokenPair(context.Background(), macPair.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	require.False(t, svc.IsSessionActive(context.Background(), user.ID, macClaims.SessionID), "撤销后 Access Token 立即失效")
+	require.True(t, svc.IsSessionActive(context.Background(), user.ID, ""), "不含会话 ID 的 Token 不受影响")
+
+	list, err = svc.ListUserSessions(context.Background(), user.ID, "")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "Firefox on Linux", list[0].Device)
+
+	// 密码修改后（TokenVersion 变化）旧会话不再列出
+	user.TokenVersion++
+	list, err = svc.ListUserSessions(context.Background(), user.ID, "")
+	require.NoError(t, err)
+	require.Empty(t, list)
+	require.Empty(t, sessions.sessions)
+}
+
+func TestLogoutEndsSession(t *testing.T) {
+	user := &User{ID: 1, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, sessions := newSessionTestAuthService(user)
+
+	pair, err := svc.GenerateTokenPair(WithSessionClientInfo(context.Background(), "203.0.113.7", testChromeMacUA), user, "")
+	require.NoError(t, err)
+	require.Len(t, sessions.sessions, 1)
+
+	require.NoError(t, svc.RevokeRefreshToken(context.Background(), pair.RefreshToken))
+	require.Empty(t, sessions.sessions)
+}
+
+func TestNewDeviceLoginEnqueuesNotice(t *testing.T) {
+	user := &User{ID: 1, Email: "user@example.com", Role: RoleUser, Status: StatusActive}
+	svc, _ := newSessionTestAuthService(user)
+	// 不启动 worker，直接检查入队的任务
+	queue := &EmailQueueService{taskChan: make(chan EmailTask, 10)}
+	svc.emailQueueService = queue
+	ctx := context.Background()
+
+	// 首次登录仅记录设备，不提醒
+	_, err := svc.GenerateTokenPair(WithSessionClientInfo(ctx, "203.0.113.7", testChromeMacUA), user, "")
+	require.NoError(t, err)
+	require.Len(t, queue.taskChan, 0)
+
+	// 同一设备再次登录不提醒
+	_, err = svc.GenerateTokenPair(WithSessionClientInfo(ctx, "198.51.100.1", testChromeMacUA), user, "")
+	require.NoError(t, err)
+	require.Len(t, queue.taskChan, 0)
+
+	// 新设备登录提醒
+	_, err = svc.GenerateTokenPair(WithSessionClientInfo(ctx, "203.0.113.8", testFirefoxLinuxUA), user, "")
+	require.NoError(t, err)
+	require.Len(t, queue.taskChan, 1)
+	task := <-queue.taskChan
+	require.Equal(t, TaskTypeNewDeviceLogin, task.TaskType)
+	require.Equal(t, user.Email, task.Email)
+	require.Equal(t, "Firefox on Linux", task.Session.Device)
+	require.Equal(t, "203.0.113.8", task.Session.IP)
+}
@@ -32,6 +32,44 @@ func ProvideUpdateService(cache UpdateCache, githubClient GitHubReleaseClient, b
 	return NewUpdateService(cache, githubClient, buildInfo.Version, buildInfo.BuildType)
 }
 
+// ProvideOpsService creates OpsService with the stream resume buffer attached for metrics
+func ProvideOpsService(
+	opsRepo OpsRepository,
+	settingRepo SettingRepository,
+	cfg *config.Config,
+	accountRepo AccountRepository,
+	userRepo UserRepository,
+	concurrencyService *ConcurrencyService,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	geminiCompatService *GeminiMessagesCompatService,
+	antigravityGatewayService *AntigravityGatewayService,
+	systemLogSink *OpsSystemLogSink,
+	streamResumeService *StreamResumeService,
+) *OpsService {
+	svc := NewOpsService(opsRepo, settingRepo, cfg, accountRepo, userRepo, concurrencyService, gatewayService, openAIGatewayService, geminiCompatService, antigravityGatewayService, systemLogSink)
+	svc.SetStreamResumeService(streamResumeService)
+	return svc
+}
+
+// ProvideGeminiMessagesCompatService creates GeminiMessagesCompatService sharing the gateway's scheduling state
+func ProvideGeminiMessagesCompatService(
+	accountRepo AccountRepository,
+	groupRepo GroupRepository,
+	cache GatewayCache,
+	schedulerSnapshot *SchedulerSnapshotService,
+	tokenProvider *GeminiTokenProvider,
+	rateLimitService *RateLimitService,
+	httpUpstream HTTPUpstream,
+	antigravityGatewayService *AntigravityGatewayService,
+	cfg *config.Config,
+	gatewayService *GatewayService,
+) *GeminiMessagesCompatService {
+	svc := NewGeminiMessagesCompatService(accountRepo, groupRepo, cache, schedulerSnapshot, tokenProvider, rateLimitService, httpUpstream, antigravityGatewayService, cfg)
+	svc.ShareAccountScheduling(gatewayService)
+	return svc
+}
+
 // ProvideEmailQueueService creates EmailQueueService with default worker count
 func ProvideEmailQueueService(emailService *EmailService) *EmailQueueService {
 	return NewEmailQueueService(emailService, 3)
@@ -58,15 +96,95 @@ func ProvideTokenRefreshService(
 }
 
 // ProvideDashboardAggregationService 创建并启动仪表盘聚合服务
-func ProvideDashboardAggregationService(repo DashboardAggregationRepository, timingWheel *TimingWheelService, cfg *config.Config) *DashboardAggregationService {
+func ProvideDashboardAggregationService(repo DashboardAggregationRepository, timingWheel *TimingWheelService, archiveService *UsageArchiveService, cfg *config.Config) *DashboardAggregationService {
 	svc := NewDashboardAggregationService(repo, timingWheel, cfg)
+	if archiveService.Enabled() {
+		svc.SetArchiver(archiveService)
+	}
 	svc.Start()
 	return svc
 }
 
 // ProvideUsageCleanupService 创建并启动使用记录清理任务服务
-func ProvideUsageCleanupService(repo UsageCleanupRepository, timingWheel *TimingWheelService, dashboardAgg *DashboardAggregationService, cfg *config.Config) *UsageCleanupService {
+func ProvideUsageCleanupService(repo UsageCleanupRepository, timingWheel *TimingWheelService, dashboardAgg *DashboardAggregationService, archiveService *UsageArchiveService, partitionService *TimePartitionService, cfg *config.Config) *UsageCleanupService {
 	svc := NewUsageCleanupService(repo, timingWheel, dashboardAgg, cfg)
+	if archiveService.Enabled() {
+		svc.SetArchiver(archiveService)
+	}
+	svc.SetPartitionService(partitionService)
+	svc.Start()
+	return svc
+}
+
+// ProvideTimePartitionService 创建并启动分区维护服务（未开启分区维护时仅定期清理过期去重记录）
+func ProvideTimePartitionService(repo TimePartitionRepository, timingWheel *TimingWheelService, cfg *config.Config) *TimePartitionService {
+	svc := NewTimePartitionService(repo, timingWheel, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideCostAnomalyService 创建并启动费用异常检测服务
+func ProvideCostAnomalyService(
+	repo CostAnomalyRepository,
+	opsRepo OpsRepository,
+	apiKeyService *APIKeyService,
+	userRepo UserRepository,
+	emailService *EmailService,
+	timingWheel *TimingWheelService,
+	db *sql.DB,
+	cfg *config.Config,
+) *CostAnomalyService {
+	svc := NewCostAnomalyService(repo, opsRepo, apiKeyService, userRepo, emailService, timingWheel, db, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideAPIKeyService 创建 APIKeyService 并注入 Key/用户/分组 RPM/TPM 限流
+func ProvideAPIKeyService(
+	apiKeyRepo APIKeyRepository,
+	userRepo UserRepository,
+	groupRepo GroupRepository,
+	userSubRepo UserSubscriptionRepository,
+	userGroupRateRepo UserGroupRateRepository,
+	cache APIKeyCache,
+	cfg *config.Config,
+	rateLimiter *RequestRateLimitService,
+) *APIKeyService {
+	svc := NewAPIKeyService(apiKeyRepo, userRepo, groupRepo, userSubRepo, userGroupRateRepo, cache, cfg)
+	svc.SetRequestRateLimiter(rateLimiter)
+	return svc
+}
+
+// ProvideAuthService 创建认证服务并注入会话元数据存储
+func ProvideAuthService(
+	userRepo UserRepository,
+	redeemRepo RedeemCodeRepository,
+	refreshTokenCache RefreshTokenCache,
+	cfg *config.Config,
+	settingService *SettingService,
+	emailService *EmailService,
+	turnstileService *TurnstileService,
+	emailQueueService *EmailQueueService,
+	promoService *PromoService,
+	defaultSubAssigner DefaultSubscriptionAssigner,
+	sessionCache UserSessionCache,
+) *AuthService {
+	svc := NewAuthService(userRepo, redeemRepo, refreshTokenCache, cfg, settingService, emailService, turnstileService, emailQueueService, promoService, defaultSubAssigner)
+	svc.SetUserSessionCache(sessionCache)
+	return svc
+}
+
+// ProvideKeySharingService 创建共享检测服务，注册为 APIKeyService 的 IP 上限校验器并启动定时检测
+func ProvideKeySharingService(
+	repo KeySharingRepository,
+	cache KeySharingCache,
+	apiKeyService *APIKeyService,
+	timingWheel *TimingWheelService,
+	db *sql.DB,
+	cfg *config.Config,
+) *KeySharingService {
+	svc := NewKeySharingService(repo, cache, apiKeyService, timingWheel, db, cfg)
+	apiKeyService.SetIPLimiter(svc)
 	svc.Start()
 	return svc
 }
@@ -85,6 +203,14 @@ func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository) *S
 	return svc
 }
 
+// ProvideAPIKeyRotationCleanupService creates and starts APIKeyRotationCleanupService.
+func ProvideAPIKeyRotationCleanupService(apiKeyRepo APIKeyRepository, apiKeyService *APIKeyService, cfg *config.Config) *APIKeyRotationCleanupService {
+	interval := time.Duration(cfg.APIKeyRotation.CleanupIntervalMinutes) * time.Minute
+	svc := NewAPIKeyRotationCleanupService(apiKeyRepo, apiKeyService, interval)
+	svc.Start()
+	return svc
+}
+
 // ProvideTimingWheelService creates and starts TimingWheelService
 func ProvideTimingWheelService() (*TimingWheelService, error) {
 	svc, err := NewTimingWheelService()
@@ -197,20 +323,34 @@ func ProvideOpsCleanupService(
 	opsRepo OpsRepository,
 	db *sql.DB,
 	redisClient *redis.Client,
+	partitionService *TimePartitionService,
 	cfg *config.Config,
 ) *OpsCleanupService {
 	svc := NewOpsCleanupService(opsRepo, db, redisClient, cfg)
+	svc.SetPartitionService(partitionService)
 	svc.Start()
 	return svc
 }
 
-func ProvideOpsSystemLogSink(opsRepo OpsRepository) *OpsSystemLogSink {
+func ProvideOpsSystemLogSink(opsRepo OpsRepository, logSinks *LogSinkManager) *OpsSystemLogSink {
 	sink := NewOpsSystemLogSink(opsRepo)
 	sink.Start()
-	logger.SetSink(sink)
+	if logSinks.Empty() {
+		logger.SetSink(sink)
+	} else {
+		sink.SetExternalSinks(logSinks)
+		logger.SetSink(logSinkFanout{sink, logSinks})
+	}
 	return sink
 }
 
+// ProvideLogSinkManager 创建并启动外部日志 sink（syslog / Loki / HTTP NDJSON）
+func ProvideLogSinkManager(cfg *config.Config) *LogSinkManager {
+	m := NewLogSinkManager(cfg)
+	m.Start()
+	return m
+}
+
 // ProvideSoraMediaStorage 初始化 Sora 媒体存储
 func ProvideSoraMediaStorage(cfg *config.Config) *SoraMediaStorage {
 	return NewSoraMediaStorage(cfg)
@@ -324,9 +464,10 @@ func ProvideSettingService(settingRepo SettingRepository, groupRepo GroupReposit
 // ProviderSet is the Wire provider set for all services
 var ProviderSet = wire.NewSet(
 	// Core services
-	NewAuthService,
+	ProvideAuthService,
 	NewUserService,
-	NewAPIKeyService,
+	NewRequestRateLimitService,
+	ProvideAPIKeyService,
 	ProvideAPIKeyAuthCacheInvalidator,
 	NewGroupService,
 	NewAccountService,
@@ -355,7 +496,7 @@ var ProviderSet = wire.NewSet(
 	wire.Bind(new(TokenCacheInvalidator), new(*CompositeTokenCacheInvalidator)),
 	NewAntigravityOAuthService,
 	NewGeminiTokenProvider,
-	NewGeminiMessagesCompatService,
+	ProvideGeminiMessagesCompatService,
 	NewAntigravityTokenProvider,
 	NewOpenAITokenProvider,
 	NewClaudeTokenProvider,
@@ -366,7 +507,8 @@ var ProviderSet = wire.NewSet(
 	ProvideSettingService,
 	NewDataManagementService,
 	ProvideOpsSystemLogSink,
-	NewOpsService,
+	ProvideLogSinkManager,
+	ProvideOpsService,
 	ProvideOpsMetricsCollector,
 	ProvideOpsAggregationService,
 	ProvideOpsAlertEvaluatorService,
@@ -387,14 +529,31 @@ var ProviderSet = wire.NewSet(
 	ProvideTokenRefreshService,
 	ProvideAccountExpiryService,
 	ProvideSubscriptionExpiryService,
+	ProvideAPIKeyRotationCleanupService,
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
 	ProvideUsageCleanupService,
+	ProvideTimePartitionService,
+	ProvideCostAnomalyService,
+	ProvideKeySharingService,
+	NewUsageArchiveService,
+	NewUsageArchiveS3Store,
+	wire.Bind(new(UsageArchiveObjectStore), new(*UsageArchiveS3Store)),
 	ProvideDeferredService,
 	NewAntigravityQuotaFetcher,
 	NewUserAttributeService,
 	NewUsageCache,
 	NewTotpService,
+	NewOIDCService,
+	NewPasskeyService,
+	NewAdminRBACService,
+	NewAdminKeyService,
+	NewImpersonationService,
+	NewShadowMirrorService,
+	NewExperimentService,
+	NewResponseCacheService,
+	NewStreamResumeService,
+	NewContextPolicyService,
 	NewErrorPassthroughService,
 	NewDigestSessionStore,
 	ProvideIdempotencyCoordinator,
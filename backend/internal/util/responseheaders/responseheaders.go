@@ -107,9 +107,23 @@ func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
 	return filtered
 }
 
+// rateLimitHeaders 网关本地 RPM/TPM 限流写入的响应头
+var rateLimitHeaders = map[string]struct{}{
+	"x-ratelimit-limit-requests":     {},
+	"x-ratelimit-limit-tokens":       {},
+	"x-ratelimit-remaining-requests": {},
+	"x-ratelimit-remaining-tokens":   {},
+	"x-ratelimit-reset-requests":     {},
+	"x-ratelimit-reset-tokens":       {},
+}
+
 func WriteFilteredHeaders(dst http.Header, src http.Header, filter *CompiledHeaderFilter) {
 	filtered := FilterHeaders(src, filter)
 	for key, values := range filtered {
+		// 本地限流已写入额度头时以本地为准：上游值反映的是账号额度，而非调用方自身的额度
+		if _, ok := rateLimitHeaders[strings.ToLower(key)]; ok && dst.Get(key) != "" {
+			continue
+		}
 		for _, value := range values {
 			dst.Add(key, value)
 		}
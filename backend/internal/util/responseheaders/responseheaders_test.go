@@ -65,3 +65,25 @@ func TestFilterHeadersEnabledUsesAllowlist(t *testing.T) {
 		t.Fatalf("expected X-Blocked removed, got %q", filtered.Get("X-Blocked"))
 	}
 }
+
+func TestWriteFilteredHeadersKeepsLocalRateLimitHeaders(t *testing.T) {
+	src := http.Header{}
+	src.Add("X-Ratelimit-Remaining-Requests", "4999")
+	src.Add("X-Ratelimit-Remaining-Tokens", "800000")
+	src.Add("Content-Type", "application/json")
+
+	dst := http.Header{}
+	dst.Set("x-ratelimit-remaining-requests", "3")
+
+	WriteFilteredHeaders(dst, src, nil)
+
+	if got := dst.Values("X-Ratelimit-Remaining-Requests"); len(got) != 1 || got[0] != "3" {
+		t.Fatalf("expected local rate limit header to win, got %v", got)
+	}
+	if dst.Get("X-Ratelimit-Remaining-Tokens") != "800000" {
+		t.Fatalf("expected upstream header to pass through when not set locally")
+	}
+	if dst.Get("Content-Type") != "application/json" {
+		t.Fatalf("expected content-type to pass through")
+	}
+}
@@ -0,0 +1,59 @@
+-- 070_add_usage_log_archives.sql
+-- usage_logs 归档索引与回灌表：删除前导出到对象存储，按需回灌用于临时查询与对账单
+
+CREATE TABLE IF NOT EXISTS usage_log_archives (
+    id                  BIGSERIAL PRIMARY KEY,
+    source              VARCHAR(20) NOT NULL,
+    task_id             BIGINT,
+    granularity         VARCHAR(10) NOT NULL,
+    range_start         TIMESTAMPTZ NOT NULL,
+    range_end           TIMESTAMPTZ NOT NULL,
+    filters             JSONB NOT NULL DEFAULT '{}'::jsonb,
+    profile_id          VARCHAR(64) NOT NULL DEFAULT '',
+    bucket              VARCHAR(255) NOT NULL DEFAULT '',
+    object_key          TEXT NOT NULL,
+    format              VARCHAR(20) NOT NULL DEFAULT 'ndjson.gz',
+    row_count           BIGINT NOT NULL DEFAULT 0,
+    size_bytes          BIGINT NOT NULL DEFAULT 0,
+    sha256              VARCHAR(64) NOT NULL DEFAULT '',
+    min_log_id          BIGINT,
+    max_log_id          BIGINT,
+    rehydrate_status    VARCHAR(20) NOT NULL DEFAULT '',
+    rehydrated_rows     BIGINT NOT NULL DEFAULT 0,
+    rehydrate_error     TEXT,
+    rehydrated_at       TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 保留清理按时间段去重；清理任务按 (task_id, range_start) 去重，便于任务中断后续跑
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_log_archives_retention_range
+    ON usage_log_archives (granularity, range_start)
+    WHERE source = 'retention';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_log_archives_task_range
+    ON usage_log_archives (task_id, range_start)
+    WHERE source = 'cleanup_task';
+CREATE INDEX IF NOT EXISTS idx_usage_log_archives_range
+    ON usage_log_archives (range_start, range_end);
+
+CREATE TABLE IF NOT EXISTS usage_log_archive_rows (
+    archive_id              BIGINT NOT NULL REFERENCES usage_log_archives(id) ON DELETE CASCADE,
+    usage_log_id            BIGINT NOT NULL,
+    user_id                 BIGINT NOT NULL,
+    api_key_id              BIGINT NOT NULL,
+    account_id              BIGINT NOT NULL,
+    group_id                BIGINT,
+    model                   VARCHAR(100) NOT NULL,
+    input_tokens            BIGINT NOT NULL DEFAULT 0,
+    output_tokens           BIGINT NOT NULL DEFAULT 0,
+    cache_creation_tokens   BIGINT NOT NULL DEFAULT 0,
+    cache_read_tokens       BIGINT NOT NULL DEFAULT 0,
+    total_cost              DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    actual_cost             DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    created_at              TIMESTAMPTZ NOT NULL,
+    data                    JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_log_archive_rows_archive ON usage_log_archive_rows (archive_id);
+CREATE INDEX IF NOT EXISTS idx_usage_log_archive_rows_user_created ON usage_log_archive_rows (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_usage_log_archive_rows_created ON usage_log_archive_rows (created_at);
@@ -0,0 +1,25 @@
+-- 071_add_time_partitioning.sql
+-- 按月分区支持：
+--   1) usage_log_dedup：分区表的唯一约束必须包含分区键，无法继续用 (request_id, api_key_id)
+--      唯一索引做幂等，改由该表承担（未分区时与原唯一索引共同生效）。
+--   2) table_partition_conversions：在线转换进度，支持中断后续跑。
+
+CREATE TABLE IF NOT EXISTS usage_log_dedup (
+    request_id  VARCHAR(64) NOT NULL,
+    api_key_id  BIGINT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (request_id, api_key_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_log_dedup_created_at ON usage_log_dedup (created_at);
+
+CREATE TABLE IF NOT EXISTS table_partition_conversions (
+    table_name      VARCHAR(64) PRIMARY KEY,
+    status          VARCHAR(20) NOT NULL,
+    last_copied_id  BIGINT NOT NULL DEFAULT 0,
+    copied_rows     BIGINT NOT NULL DEFAULT 0,
+    error_message   TEXT,
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at     TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
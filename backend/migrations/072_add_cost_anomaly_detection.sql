@@ -0,0 +1,20 @@
+-- 072_add_cost_anomaly_detection.sql
+-- 费用异常检测：按 API Key 的小时级用量聚合，用于学习每个 Key/用户的正常消费基线。
+
+CREATE TABLE IF NOT EXISTS usage_api_key_hourly (
+    bucket_start  TIMESTAMPTZ NOT NULL,
+    api_key_id    BIGINT NOT NULL,
+    user_id       BIGINT NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    actual_cost   DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    computed_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bucket_start, api_key_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_api_key_hourly_key_bucket
+    ON usage_api_key_hourly (api_key_id, bucket_start);
+
+CREATE INDEX IF NOT EXISTS idx_usage_api_key_hourly_user_bucket
+    ON usage_api_key_hourly (user_id, bucket_start);
+
+COMMENT ON TABLE usage_api_key_hourly IS 'Hourly per-API-key usage aggregates for cost anomaly baselines (UTC buckets).';
@@ -0,0 +1,34 @@
+-- 073_add_api_key_sharing_detection.sql
+-- API Key 共享/转售检测：分组级来源 IP 上限 + 可疑 Key 人工审核队列。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS max_ips_per_key_per_hour INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN groups.max_ips_per_key_per_hour IS 'Max distinct client IPs per API key per hour; 0 means unlimited.';
+
+CREATE TABLE IF NOT EXISTS api_key_sharing_reviews (
+    id          BIGSERIAL PRIMARY KEY,
+    api_key_id  BIGINT NOT NULL,
+    user_id     BIGINT NOT NULL,
+    score       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    evidence    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status      VARCHAR(20) NOT NULL DEFAULT 'pending',
+    note        TEXT NOT NULL DEFAULT '',
+    reviewed_by BIGINT,
+    reviewed_at TIMESTAMPTZ,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 每个 Key 同时最多一条待审核记录，重复检测时原地更新
+CREATE UNIQUE INDEX IF NOT EXISTS uq_api_key_sharing_reviews_pending
+    ON api_key_sharing_reviews (api_key_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_api_key_sharing_reviews_status_detected
+    ON api_key_sharing_reviews (status, detected_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_sharing_reviews_key_reviewed
+    ON api_key_sharing_reviews (api_key_id, reviewed_at DESC);
+
+COMMENT ON TABLE api_key_sharing_reviews IS 'Admin review queue for API keys suspected of being shared or resold.';
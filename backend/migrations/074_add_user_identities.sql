@@ -0,0 +1,24 @@
+-- 074_add_user_identities.sql
+-- 通用 OIDC/OAuth2 登录：本地用户与外部身份（provider + subject）的绑定关系。
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider      VARCHAR(32) NOT NULL,
+    subject       VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL DEFAULT '',
+    username      VARCHAR(100) NOT NULL DEFAULT '',
+    last_login_at TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 同一外部身份只能绑定一个本地用户
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_identities_provider_subject
+    ON user_identities (provider, subject);
+
+-- 每个用户在同一提供方下最多绑定一个身份
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_identities_user_provider
+    ON user_identities (user_id, provider);
+
+COMMENT ON TABLE user_identities IS 'External OIDC/OAuth2 identities linked to local users.';
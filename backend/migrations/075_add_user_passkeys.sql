@@ -0,0 +1,23 @@
+-- 075_add_user_passkeys.sql
+-- WebAuthn Passkey：用户注册的公钥凭证，可用于无密码登录或作为第二因素。
+
+CREATE TABLE IF NOT EXISTS user_passkeys (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name          VARCHAR(64) NOT NULL DEFAULT '',
+    -- base64url 编码的凭证 ID（全局唯一）
+    credential_id VARCHAR(1400) NOT NULL,
+    -- 完整的 WebAuthn 凭证记录（公钥、签名计数、标志位、认证器信息）
+    credential    JSONB NOT NULL,
+    last_used_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_passkeys_credential_id
+    ON user_passkeys (credential_id);
+
+CREATE INDEX IF NOT EXISTS idx_user_passkeys_user_id
+    ON user_passkeys (user_id);
+
+COMMENT ON TABLE user_passkeys IS 'WebAuthn passkey credentials registered by users.';
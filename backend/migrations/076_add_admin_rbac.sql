@@ -0,0 +1,33 @@
+-- 076_add_admin_rbac.sql
+-- 管理后台 RBAC：自定义角色与用户角色分配。
+-- 内置角色（viewer/support/finance/operator/superadmin）定义在代码中，不入库；
+-- role=admin 且未分配角色的用户视为 superadmin，保持升级前行为。
+
+CREATE TABLE IF NOT EXISTS admin_roles (
+    id          BIGSERIAL PRIMARY KEY,
+    key         VARCHAR(64) NOT NULL,
+    name        VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    -- 权限点列表，例如 ["users:read", "users:write"]
+    permissions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_admin_roles_key
+    ON admin_roles (key);
+
+CREATE TABLE IF NOT EXISTS admin_role_assignments (
+    user_id     BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    -- 内置角色 key 或 admin_roles.key
+    role_key    VARCHAR(64) NOT NULL,
+    assigned_by BIGINT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_role_assignments_role_key
+    ON admin_role_assignments (role_key);
+
+COMMENT ON TABLE admin_roles IS 'Custom admin roles with fine-grained permissions.';
+COMMENT ON TABLE admin_role_assignments IS 'Admin role assigned to each staff user.';
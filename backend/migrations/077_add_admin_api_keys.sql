@@ -0,0 +1,27 @@
+-- 077_add_admin_api_keys.sql
+-- 多个命名的管理员 API Key：每个 Key 独立的 scope、IP 白名单、过期时间与最近使用记录。
+-- 仅保存 SHA-256 摘要，明文只在创建/轮换时返回一次。
+-- settings.admin_api_key 中的全局 Key 继续有效（全部权限），便于平滑迁移。
+
+CREATE TABLE IF NOT EXISTS admin_api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL,
+    -- 展示用前缀，例如 admin-1a2b3c
+    key_prefix   VARCHAR(32) NOT NULL,
+    key_hash     CHAR(64) NOT NULL,
+    -- 权限点（users:read）、路由（POST redeem-codes/create-and-redeem）或 "*"
+    scopes       JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ip_allowlist JSONB NOT NULL DEFAULT '[]'::jsonb,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(64) NOT NULL DEFAULT '',
+    revoked_at   TIMESTAMPTZ,
+    created_by   BIGINT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_admin_api_keys_key_hash
+    ON admin_api_keys (key_hash);
+
+COMMENT ON TABLE admin_api_keys IS 'Named, scoped admin API keys (hashed at rest).';
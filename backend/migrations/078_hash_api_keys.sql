@@ -0,0 +1,10 @@
+-- 078_hash_api_keys.sql
+-- API Key 不再明文落库：api_keys.key 改存 "hmac:" + HMAC-SHA256(server secret, key) 的十六进制摘要，
+-- 明文只在创建时返回一次，列表中仅展示 key_prefix。
+-- 摘要所需的密钥保存在 security_secrets（api_key_hash_secret），SQL 中无法计算，
+-- 因此存量明文 Key 由服务启动时的回填任务（repository.backfillAPIKeyHashes）批量转换。
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_prefix VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN api_keys.key IS 'HMAC-SHA256 digest of the API key (hmac:<hex>); legacy rows may hold plaintext until backfilled';
+COMMENT ON COLUMN api_keys.key_prefix IS 'Display prefix of the plaintext key';
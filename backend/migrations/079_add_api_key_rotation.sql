@@ -0,0 +1,11 @@
+-- 079_add_api_key_rotation.sql
+-- API Key 轮换：同一条记录（ID、分组、额度、限流窗口与用量历史不变）换发新 Key，
+-- 旧 Key 摘要移入 previous_key，在宽限期内仍可认证，到期后由后台任务清除。
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key VARCHAR(128);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key_prefix VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS previous_key_expires_at TIMESTAMPTZ;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_key ON api_keys (previous_key);
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_key_expires_at ON api_keys (previous_key_expires_at);
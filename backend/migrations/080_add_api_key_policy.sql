@@ -0,0 +1,6 @@
+-- 080_add_api_key_policy.sql
+-- API Key 访问策略：模型允许/拒绝列表（glob）、允许的端点与平台、
+-- 单次请求输入/输出 token 上限、thinking/工具开关以及每分钟请求数与 token 数上限。
+-- NULL 表示不做限制。
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS policy JSONB;
@@ -0,0 +1,13 @@
+-- 081_add_request_rate_limits.sql
+-- 用户级与分组级 RPM/TPM 滑动窗口限流（Key 级限制位于 api_keys.policy 中）。
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS rpm_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tpm_limit INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS rpm_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS tpm_limit INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN users.rpm_limit IS 'Sliding-window requests per minute across all API keys of the user; 0 means unlimited.';
+COMMENT ON COLUMN users.tpm_limit IS 'Sliding-window tokens per minute across all API keys of the user; 0 means unlimited.';
+COMMENT ON COLUMN groups.rpm_limit IS 'Sliding-window requests per minute across all requests routed to the group; 0 means unlimited.';
+COMMENT ON COLUMN groups.tpm_limit IS 'Sliding-window tokens per minute across all requests routed to the group; 0 means unlimited.';
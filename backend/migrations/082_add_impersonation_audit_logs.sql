@@ -0,0 +1,30 @@
+-- 082_add_impersonation_audit_logs.sql
+-- 管理员模拟用户登录（impersonation）的审计日志：记录开始、结束、每个请求以及被拦截的敏感操作。
+-- 进行中的模拟会话保存在 Redis（impersonation:{id}），提前结束时删除即可让 Token 立即失效。
+
+CREATE TABLE IF NOT EXISTS impersonation_audit_logs (
+    id               BIGSERIAL PRIMARY KEY,
+    impersonation_id VARCHAR(64) NOT NULL,
+    admin_id         BIGINT NOT NULL,
+    target_user_id   BIGINT NOT NULL,
+    -- start / action / blocked / end
+    event            VARCHAR(16) NOT NULL,
+    -- read（只读，默认）/ write
+    scope            VARCHAR(10) NOT NULL DEFAULT 'read',
+    method           VARCHAR(10) NOT NULL DEFAULT '',
+    path             VARCHAR(255) NOT NULL DEFAULT '',
+    status_code      INTEGER NOT NULL DEFAULT 0,
+    -- start 事件为模拟原因，blocked 事件为拦截原因
+    detail           TEXT NOT NULL DEFAULT '',
+    ip               VARCHAR(64) NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_logs_impersonation_id
+    ON impersonation_audit_logs (impersonation_id, id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_logs_admin_id
+    ON impersonation_audit_logs (admin_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_logs_target_user_id
+    ON impersonation_audit_logs (target_user_id, created_at DESC);
+
+COMMENT ON TABLE impersonation_audit_logs IS 'Audit trail of admin impersonation sessions: start, end and every request made with the impersonation token.';
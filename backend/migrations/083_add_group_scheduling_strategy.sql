@@ -0,0 +1,7 @@
+-- 083_add_group_scheduling_strategy.sql
+-- 分组级账号调度策略。空字符串表示使用网关默认策略
+-- （Anthropic/Gemini 为优先级 + LRU，OpenAI 为综合评分 top-K）。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS scheduling_strategy VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.scheduling_strategy IS 'Account selection strategy: priority_lru, weighted_round_robin, least_loaded, lowest_latency, cheapest or power_of_two; empty uses the platform default.';
@@ -0,0 +1,8 @@
+-- 084_add_queue_priority.sql
+-- 账号满载排队优先级（Key 级设置位于 api_keys.policy 中，只能下调）。
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS queue_priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS queue_priority INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN users.queue_priority IS 'Wait-queue priority (0-9, higher first) when all accounts are busy.';
+COMMENT ON COLUMN groups.queue_priority IS 'Wait-queue priority (0-9, higher first) for requests routed to the group.';
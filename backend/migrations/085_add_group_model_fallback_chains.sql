@@ -0,0 +1,6 @@
+-- 085_add_group_model_fallback_chains.sql
+-- 分组级模型降级链：无可用账号、429/529、上下文超长时按顺序降级到其他模型（可指定平台）。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS model_fallback_chains JSONB;
+
+COMMENT ON COLUMN groups.model_fallback_chains IS 'Ordered model fallback chains: [{source, fallbacks: [{model, platform}], triggers}].';
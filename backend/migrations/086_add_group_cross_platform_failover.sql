@@ -0,0 +1,6 @@
+-- 086_add_group_cross_platform_failover.sql
+-- 分组级跨平台故障转移：anthropic 账号全部不可用时转移到分组内 antigravity 账号，反之亦然。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS cross_platform_failover BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN groups.cross_platform_failover IS 'Fail over between anthropic and antigravity accounts in this group once the native platform is exhausted.';
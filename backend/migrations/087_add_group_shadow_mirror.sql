@@ -0,0 +1,36 @@
+-- 087_add_group_shadow_mirror.sql
+-- 分组级影子流量镜像：按采样率将请求异步复制到影子账号/分组，丢弃影子响应，仅记录状态、延迟、Token 用量与输出相似度用于对比。
+-- 影子请求不计费，并受影子账号自身并发上限约束（满载时跳过）。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS shadow_mirror JSONB;
+
+COMMENT ON COLUMN groups.shadow_mirror IS 'Shadow mirroring config: {"enabled", "sample_rate", "account_id" | "group_id"}. NULL disables mirroring.';
+
+CREATE TABLE IF NOT EXISTS shadow_mirror_results (
+    id                    BIGSERIAL PRIMARY KEY,
+    group_id              BIGINT NOT NULL,
+    api_key_id            BIGINT NOT NULL DEFAULT 0,
+    model                 VARCHAR(128) NOT NULL DEFAULT '',
+    stream                BOOLEAN NOT NULL DEFAULT FALSE,
+    primary_account_id    BIGINT NOT NULL DEFAULT 0,
+    -- 未获取到影子账号（不存在/不可调度/并发已满）时为 NULL
+    shadow_account_id     BIGINT,
+    primary_status        INTEGER NOT NULL DEFAULT 0,
+    -- 0 表示影子请求未发出
+    shadow_status         INTEGER NOT NULL DEFAULT 0,
+    primary_latency_ms    BIGINT NOT NULL DEFAULT 0,
+    shadow_latency_ms     BIGINT NOT NULL DEFAULT 0,
+    primary_input_tokens  INTEGER NOT NULL DEFAULT 0,
+    primary_output_tokens INTEGER NOT NULL DEFAULT 0,
+    shadow_input_tokens   INTEGER NOT NULL DEFAULT 0,
+    shadow_output_tokens  INTEGER NOT NULL DEFAULT 0,
+    -- 主/影子文本输出的余弦相似度（0-1），无法比较时为 NULL
+    similarity            DOUBLE PRECISION,
+    error_message         TEXT NOT NULL DEFAULT '',
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shadow_mirror_results_group_id
+    ON shadow_mirror_results (group_id, created_at DESC);
+
+COMMENT ON TABLE shadow_mirror_results IS 'Primary vs shadow comparison records produced by per-group traffic mirroring. Shadow traffic is never billed.';
@@ -0,0 +1,41 @@
+-- 088_add_experiments.sql
+-- A/B 模型实验：匹配模型的请求按用户或 API Key 哈希粘性分配到变体（模型映射 / 账号分组 / system 提示词补丁）。
+-- usage_logs 与 ops_error_logs 记录实验与变体，用于按变体对比错误率、延迟、Token 与费用。
+
+CREATE TABLE IF NOT EXISTS experiments (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(100) NOT NULL,
+    description   TEXT NOT NULL DEFAULT '',
+    -- draft / running / stopped
+    status        VARCHAR(16) NOT NULL DEFAULT 'draft',
+    -- 请求模型匹配模式（glob：* / ?）
+    model_pattern VARCHAR(128) NOT NULL,
+    -- 限定 API Key 所属分组，空数组表示不限
+    group_ids     JSONB NOT NULL DEFAULT '[]'::jsonb,
+    -- user / api_key
+    sticky_by     VARCHAR(16) NOT NULL DEFAULT 'user',
+    -- [{"name","percent","model","group_id","system_prompt_patch"}]
+    variants      JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiments_status
+    ON experiments (status) WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE experiments IS 'A/B model experiments with sticky per-user or per-key variant assignment.';
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS experiment_id BIGINT;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS experiment_variant VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_usage_logs_experiment
+    ON usage_logs (experiment_id, experiment_variant, created_at)
+    WHERE experiment_id IS NOT NULL;
+
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS experiment_id BIGINT;
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS experiment_variant VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_ops_error_logs_experiment
+    ON ops_error_logs (experiment_id, experiment_variant, created_at)
+    WHERE experiment_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- 089_add_group_response_cache.sql
+-- 网关响应缓存：确定性请求（temperature=0）按规范化请求体精确匹配，命中时回放缓存的 JSON / SSE 响应。
+-- 缓存条目存放于 Redis（带 TTL）；命中按原费用 × hit_price_ratio 计费，使用日志 billing_type = 2。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS response_cache JSONB;
+
+COMMENT ON COLUMN groups.response_cache IS 'Response cache config: {"enabled", "ttl_seconds", "max_entry_bytes", "hit_price_ratio", "scope"}. NULL disables caching.';
+COMMENT ON COLUMN usage_logs.billing_type IS '0 = balance, 1 = subscription, 2 = response cache hit';
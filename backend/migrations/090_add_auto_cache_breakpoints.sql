@@ -0,0 +1,12 @@
+-- 090_add_auto_cache_breakpoints.sql
+-- 自动 prompt 缓存断点：分组开启后为未设置 cache_control 的 Anthropic 请求在 tools / system / 历史轮次上注入断点。
+-- usage_logs 记录注入数量与相对无缓存计费的节省金额，用于按 API Key 汇总节省。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS auto_cache_breakpoints BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN groups.auto_cache_breakpoints IS 'Inject cache_control breakpoints on stable prompt prefixes for Anthropic requests that do not set them.';
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS auto_cache_breakpoints SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS prompt_cache_savings DECIMAL(20, 10) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN usage_logs.prompt_cache_savings IS 'Cost difference versus billing all cached tokens as regular input, for requests with injected breakpoints (negative on cache-write turns).';
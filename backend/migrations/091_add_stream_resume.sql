@@ -0,0 +1,7 @@
+-- 091_add_stream_resume.sql
+-- 流式断线续传：分组开启后网关在服务端短暂缓冲 SSE 事件（带事件 ID），
+-- 客户端断线重连时携带 Last-Event-ID 即可收到剩余事件，无需重新请求上游。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS stream_resume BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN groups.stream_resume IS 'Buffer streaming responses briefly so clients can resume with Last-Event-ID after a dropped connection.';
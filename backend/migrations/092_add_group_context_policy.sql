@@ -0,0 +1,13 @@
+-- 092_add_group_context_policy.sql
+-- 分组上下文策略：转发前本地估算输入 token，超出上限时直接拒绝、丢弃最早的对话轮次或用低价模型摘要，
+-- 避免上游 "prompt is too long" 触发反复切换账号。usage_logs 记录实际采取的处理方式。
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS context_policy JSONB;
+
+COMMENT ON COLUMN groups.context_policy IS 'Context policy config: {"enabled", "action", "max_input_tokens", "summary_model", "summary_max_tokens"}. NULL disables the policy.';
+
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS context_action VARCHAR(20);
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS context_dropped_messages INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN usage_logs.context_action IS 'Context policy action applied to the request: truncated, summarized, or summary_request for the summarization call itself. NULL when untouched.';
+COMMENT ON COLUMN usage_logs.context_dropped_messages IS 'Number of leading messages dropped or summarized by the context policy.';
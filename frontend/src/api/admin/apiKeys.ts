@@ -26,8 +26,22 @@ export async function updateApiKeyGroup(id: number, groupId: number | null): Pro
   return data
 }
 
+/**
+ * Rotate an API key on behalf of its owner
+ * @param id - API Key ID
+ * @param graceHours - Grace period for the old secret (omit for server default, 0 = revoke now)
+ * @returns Rotated API key including the new plaintext key
+ */
+export async function rotateApiKey(id: number, graceHours?: number): Promise<ApiKey> {
+  const { data } = await apiClient.post<ApiKey>(`/admin/api-keys/${id}/rotate`, {
+    grace_hours: graceHours
+  })
+  return data
+}
+
 export const apiKeysAPI = {
-  updateApiKeyGroup
+  updateApiKeyGroup,
+  rotateApiKey
 }
 
 export default apiKeysAPI
@@ -0,0 +1,82 @@
+/**
+ * Admin Experiments API endpoints
+ * Handles A/B model experiments and per-variant result comparison
+ */
+
+import { apiClient } from '../client'
+import type { Experiment, ExperimentResults, SaveExperimentRequest } from '@/types'
+
+/**
+ * List all experiments
+ * @returns List of experiments
+ */
+export async function list(): Promise<Experiment[]> {
+  const { data } = await apiClient.get<Experiment[]>('/admin/experiments')
+  return data ?? []
+}
+
+/**
+ * Get experiment by ID
+ * @param id - Experiment ID
+ * @returns Experiment details
+ */
+export async function getById(id: number): Promise<Experiment> {
+  const { data } = await apiClient.get<Experiment>(`/admin/experiments/${id}`)
+  return data
+}
+
+/**
+ * Create a new experiment
+ * @param req - Experiment configuration
+ * @returns Created experiment
+ */
+export async function create(req: SaveExperimentRequest): Promise<Experiment> {
+  const { data } = await apiClient.post<Experiment>('/admin/experiments', req)
+  return data
+}
+
+/**
+ * Replace an experiment's configuration
+ * @param id - Experiment ID
+ * @param req - Experiment configuration
+ * @returns Updated experiment
+ */
+export async function update(id: number, req: SaveExperimentRequest): Promise<Experiment> {
+  const { data } = await apiClient.put<Experiment>(`/admin/experiments/${id}`, req)
+  return data
+}
+
+/**
+ * Delete an experiment (tagged usage history is kept)
+ * @param id - Experiment ID
+ */
+export async function deleteExperiment(id: number): Promise<void> {
+  await apiClient.delete(`/admin/experiments/${id}`)
+}
+
+/**
+ * Compare errors, latency, tokens and cost per variant
+ * @param id - Experiment ID
+ * @param params - Optional time range (defaults to the last 7 days)
+ * @returns Per-variant results
+ */
+export async function getResults(
+  id: number,
+  params?: { start_date?: string; end_date?: string; timezone?: string }
+): Promise<ExperimentResults> {
+  const { data } = await apiClient.get<ExperimentResults>(`/admin/experiments/${id}/results`, {
+    params
+  })
+  return data
+}
+
+export const experimentsAPI = {
+  list,
+  getById,
+  create,
+  update,
+  delete: deleteExperiment,
+  getResults
+}
+
+export default experimentsAPI
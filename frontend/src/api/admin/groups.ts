@@ -9,7 +9,9 @@ import type {
   GroupPlatform,
   CreateGroupRequest,
   UpdateGroupRequest,
-  PaginatedResponse
+  PaginatedResponse,
+  ShadowMirrorResult,
+  ShadowMirrorSummary
 } from '@/types'
 
 /**
@@ -167,6 +169,42 @@ export async function updateSortOrder(
   return data
 }
 
+/**
+ * Get shadow mirroring summary of a group
+ * @param id - Group ID
+ * @param params - Optional date range (YYYY-MM-DD, defaults to last 7 days)
+ * @returns Aggregated primary vs shadow metrics
+ */
+export async function getShadowMirrorSummary(
+  id: number,
+  params?: { start_date?: string; end_date?: string; timezone?: string }
+): Promise<ShadowMirrorSummary> {
+  const { data } = await apiClient.get<ShadowMirrorSummary>(
+    `/admin/groups/${id}/shadow-mirror/summary`,
+    { params }
+  )
+  return data
+}
+
+/**
+ * List shadow mirroring comparison records of a group
+ * @param id - Group ID
+ * @param page - Page number
+ * @param pageSize - Items per page
+ * @returns Paginated list of comparison records
+ */
+export async function getShadowMirrorResults(
+  id: number,
+  page: number = 1,
+  pageSize: number = 20
+): Promise<PaginatedResponse<ShadowMirrorResult>> {
+  const { data } = await apiClient.get<PaginatedResponse<ShadowMirrorResult>>(
+    `/admin/groups/${id}/shadow-mirror/results`,
+    { params: { page, page_size: pageSize } }
+  )
+  return data
+}
+
 export const groupsAPI = {
   list,
   getAll,
@@ -178,6 +216,8 @@ export const groupsAPI = {
   toggleStatus,
   getStats,
   getGroupApiKeys,
+  getShadowMirrorSummary,
+  getShadowMirrorResults,
   updateSortOrder
 }
 
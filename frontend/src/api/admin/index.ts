@@ -23,6 +23,8 @@ import errorPassthroughAPI from './errorPassthrough'
 import dataManagementAPI from './dataManagement'
 import apiKeysAPI from './apiKeys'
 import scheduledTestsAPI from './scheduledTests'
+import experimentsAPI from './experiments'
+import rbacAPI from './rbac'
 
 /**
  * Unified admin API object for convenient access
@@ -47,7 +49,9 @@ export const adminAPI = {
   errorPassthrough: errorPassthroughAPI,
   dataManagement: dataManagementAPI,
   apiKeys: apiKeysAPI,
-  scheduledTests: scheduledTestsAPI
+  scheduledTests: scheduledTestsAPI,
+  experiments: experimentsAPI,
+  rbac: rbacAPI
 }
 
 export {
@@ -70,7 +74,9 @@ export {
   errorPassthroughAPI,
   dataManagementAPI,
   apiKeysAPI,
-  scheduledTestsAPI
+  scheduledTestsAPI,
+  experimentsAPI,
+  rbacAPI
 }
 
 export default adminAPI
@@ -346,6 +346,8 @@ export interface PlatformConcurrencyInfo {
   max_capacity: number
   load_percentage: number
   waiting_in_queue: number
+  // 本实例优先级等待队列中按优先级统计的排队数（key 为优先级 0-9）
+  waiting_by_priority?: Record<string, number>
 }
 
 export interface GroupConcurrencyInfo {
@@ -356,6 +358,8 @@ export interface GroupConcurrencyInfo {
   max_capacity: number
   load_percentage: number
   waiting_in_queue: number
+  // 本实例优先级等待队列中按优先级统计的排队数（key 为优先级 0-9）
+  waiting_by_priority?: Record<string, number>
 }
 
 export interface AccountConcurrencyInfo {
@@ -368,6 +372,8 @@ export interface AccountConcurrencyInfo {
   max_capacity: number
   load_percentage: number
   waiting_in_queue: number
+  // 本实例优先级等待队列中按优先级统计的排队数（key 为优先级 0-9）
+  waiting_by_priority?: Record<string, number>
 }
 
 export interface OpsConcurrencyStatsResponse {
@@ -507,6 +513,71 @@ export async function getRealtimeTrafficSummary(
   return data
 }
 
+export interface OpsCrossPlatformFailoverStat {
+  group_id: number
+  from_platform: string
+  to_platform: string
+  triggered_total: number
+  succeeded_total: number
+  last_triggered_at: string
+}
+
+export interface OpsCrossPlatformFailoverStatsResponse {
+  items: OpsCrossPlatformFailoverStat[]
+  timestamp?: string
+}
+
+export async function getCrossPlatformFailoverStats(): Promise<OpsCrossPlatformFailoverStatsResponse> {
+  const { data } = await apiClient.get<OpsCrossPlatformFailoverStatsResponse>('/admin/ops/cross-platform-failover')
+  return data
+}
+
+export interface OpsStreamResumeStats {
+  streams_started: number
+  resume_hits: number
+  resume_misses: number
+  streams_evicted: number
+  streams_overflowed: number
+  active_streams: number
+  buffered_bytes: number
+  max_total_bytes: number
+}
+
+export interface OpsStreamResumeStatsResponse {
+  stats: OpsStreamResumeStats
+  timestamp?: string
+}
+
+export async function getStreamResumeStats(): Promise<OpsStreamResumeStatsResponse> {
+  const { data } = await apiClient.get<OpsStreamResumeStatsResponse>('/admin/ops/stream-resume')
+  return data
+}
+
+export interface OpsAccountSchedulerMetrics {
+  select_total: number
+  sticky_previous_hit_total: number
+  sticky_session_hit_total: number
+  load_balance_select_total: number
+  account_switch_total: number
+  scheduler_latency_ms_total: number
+  scheduler_latency_ms_avg: number
+  sticky_hit_ratio: number
+  account_switch_rate: number
+  load_skew_avg: number
+  runtime_stats_account_count: number
+}
+
+export interface OpsAccountSchedulerMetricsResponse {
+  gateway: OpsAccountSchedulerMetrics
+  openai: OpsAccountSchedulerMetrics
+  timestamp?: string
+}
+
+export async function getAccountSchedulerMetrics(): Promise<OpsAccountSchedulerMetricsResponse> {
+  const { data } = await apiClient.get<OpsAccountSchedulerMetricsResponse>('/admin/ops/account-scheduler')
+  return data
+}
+
 /**
  * Subscribe to realtime QPS updates via WebSocket.
  *
@@ -1365,6 +1436,9 @@ export const opsAPI = {
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
   getRealtimeTrafficSummary,
+  getCrossPlatformFailoverStats,
+  getStreamResumeStats,
+  getAccountSchedulerMetrics,
   subscribeQPS,
 
   // Legacy unified endpoints
@@ -0,0 +1,22 @@
+/**
+ * Admin RBAC API endpoints
+ * Exposes the effective admin permissions of the current user
+ */
+
+import { apiClient } from '../client'
+import type { AdminAccess } from '@/types'
+
+/**
+ * Get the effective admin role and permissions of the current user
+ * @returns Admin access (403 when the user has no admin access)
+ */
+export async function getMyAccess(): Promise<AdminAccess> {
+  const { data } = await apiClient.get<AdminAccess>('/admin/rbac/me')
+  return data
+}
+
+export const rbacAPI = {
+  getMyAccess
+}
+
+export default rbacAPI
@@ -24,6 +24,9 @@ export interface SystemSettings {
   invitation_code_enabled: boolean
   totp_enabled: boolean // TOTP 双因素认证
   totp_encryption_key_configured: boolean // TOTP 加密密钥是否已配置
+  passkey_enabled: boolean // WebAuthn Passkey 登录与 2FA
+  webauthn_configured: boolean // WebAuthn 依赖方是否已配置
+  admin_require_2fa: boolean // 管理员必须启用第二因素
   // Default settings
   default_balance: number
   default_concurrency: number
@@ -92,6 +95,8 @@ export interface UpdateSettingsRequest {
   password_reset_enabled?: boolean
   invitation_code_enabled?: boolean
   totp_enabled?: boolean // TOTP 双因素认证
+  passkey_enabled?: boolean // WebAuthn Passkey 登录与 2FA
+  admin_require_2fa?: boolean // 管理员必须启用第二因素
   default_balance?: number
   default_concurrency?: number
   default_subscriptions?: DefaultSubscriptionSetting[]
@@ -4,7 +4,15 @@
  */
 
 import { apiClient } from '../client'
-import type { AdminUser, UpdateUserRequest, PaginatedResponse, ApiKey } from '@/types'
+import type {
+  AdminUser,
+  UpdateUserRequest,
+  PaginatedResponse,
+  ApiKey,
+  UserSession,
+  ImpersonationStartResult,
+  ImpersonationAuditLog
+} from '@/types'
 
 /**
  * List all users with pagination
@@ -152,6 +160,68 @@ export async function getUserApiKeys(id: number): Promise<PaginatedResponse<ApiK
   return data
 }
 
+/**
+ * Get user's login sessions
+ * @param id - User ID
+ * @returns Active sessions of the user
+ */
+export async function getUserSessions(id: number): Promise<UserSession[]> {
+  const { data } = await apiClient.get<UserSession[]>(`/admin/users/${id}/sessions`)
+  return data
+}
+
+/**
+ * Revoke a single login session of a user
+ * @param id - User ID
+ * @param sessionId - Session ID
+ */
+export async function revokeUserSession(id: number, sessionId: string): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(
+    `/admin/users/${id}/sessions/${encodeURIComponent(sessionId)}`
+  )
+  return data
+}
+
+/**
+ * Revoke all login sessions of a user
+ * @param id - User ID
+ */
+export async function revokeAllUserSessions(id: number): Promise<{ message: string }> {
+  const { data } = await apiClient.post<{ message: string }>(`/admin/users/${id}/sessions/revoke-all`)
+  return data
+}
+
+/**
+ * Start impersonating a user (read-only unless write access is requested)
+ * @param id - User ID
+ * @param payload - Reason, write access and duration in minutes (max 120)
+ * @returns Short-lived impersonation token and session info
+ */
+export async function impersonate(
+  id: number,
+  payload: { reason: string; write_access?: boolean; duration_minutes?: number }
+): Promise<ImpersonationStartResult> {
+  const { data } = await apiClient.post<ImpersonationStartResult>(`/admin/users/${id}/impersonate`, payload)
+  return data
+}
+
+/**
+ * List impersonation audit log entries (newest first)
+ * @param params - Filters and pagination
+ */
+export async function getImpersonationLogs(params: {
+  user_id?: number
+  admin_id?: number
+  impersonation_id?: string
+  page?: number
+  page_size?: number
+}): Promise<PaginatedResponse<ImpersonationAuditLog>> {
+  const { data } = await apiClient.get<PaginatedResponse<ImpersonationAuditLog>>('/admin/users/impersonation-logs', {
+    params
+  })
+  return data
+}
+
 /**
  * Get user's usage statistics
  * @param id - User ID
@@ -233,6 +303,11 @@ export const usersAPI = {
   updateConcurrency,
   toggleStatus,
   getUserApiKeys,
+  getUserSessions,
+  revokeUserSession,
+  revokeAllUserSessions,
+  impersonate,
+  getImpersonationLogs,
   getUserUsageStats,
   getUserBalanceHistory
 }
@@ -13,7 +13,8 @@ import type {
   SendVerifyCodeResponse,
   PublicSettings,
   TotpLoginResponse,
-  TotpLogin2FARequest
+  TotpLogin2FARequest,
+  PasskeyCredentialAssertion
 } from '@/types'
 
 /**
@@ -108,7 +109,7 @@ export async function login(credentials: LoginRequest): Promise<LoginResponse> {
 
 /**
  * Complete login with 2FA code
- * @param request - Temp token and TOTP code
+ * @param request - Temp token and TOTP code or passkey assertion
  * @returns Authentication response with token and user data
  */
 export async function login2FA(request: TotpLogin2FARequest): Promise<AuthResponse> {
@@ -127,6 +128,55 @@ export async function login2FA(request: TotpLogin2FARequest): Promise<AuthRespon
   return data
 }
 
+/**
+ * Start passwordless passkey login
+ * @returns Ceremony token and assertion options for navigator.credentials.get()
+ */
+export async function passkeyLoginOptions(): Promise<PasskeyCredentialAssertion> {
+  const { data } = await apiClient.post<PasskeyCredentialAssertion>('/auth/passkey/login/options')
+  return data
+}
+
+/**
+ * Complete passwordless passkey login
+ * @param ceremonyToken - Token from passkeyLoginOptions
+ * @param credential - Serialized assertion
+ * @returns Authentication response with token and user data
+ */
+export async function passkeyLogin(
+  ceremonyToken: string,
+  credential: Record<string, any>
+): Promise<AuthResponse> {
+  const { data } = await apiClient.post<AuthResponse>('/auth/passkey/login', {
+    ceremony_token: ceremonyToken,
+    credential
+  })
+
+  // Store token and user data
+  setAuthToken(data.access_token)
+  if (data.refresh_token) {
+    setRefreshToken(data.refresh_token)
+  }
+  if (data.expires_in) {
+    setTokenExpiresAt(data.expires_in)
+  }
+  localStorage.setItem('auth_user', JSON.stringify(data.user))
+
+  return data
+}
+
+/**
+ * Start passkey verification for the 2FA step of a login
+ * @param tempToken - Temp token from the initial login
+ * @returns Ceremony token and assertion options; submit the result via login2FA
+ */
+export async function login2FAPasskeyOptions(tempToken: string): Promise<PasskeyCredentialAssertion> {
+  const { data } = await apiClient.post<PasskeyCredentialAssertion>('/auth/login/2fa/passkey/options', {
+    temp_token: tempToken
+  })
+  return data
+}
+
 /**
  * User registration
  * @param userData - Registration data (username, email, password)
@@ -217,6 +267,15 @@ export async function revokeAllSessions(): Promise<{ message: string }> {
   return data
 }
 
+/**
+ * End the current admin impersonation; the impersonation token stops working immediately
+ * @returns Response with message
+ */
+export async function endImpersonation(): Promise<{ message: string }> {
+  const { data } = await apiClient.post<{ message: string }>('/auth/impersonation/end')
+  return data
+}
+
 /**
  * Check if user is authenticated
  * @returns True if user has valid token
@@ -338,6 +397,9 @@ export async function resetPassword(request: ResetPasswordRequest): Promise<Rese
 export const authAPI = {
   login,
   login2FA,
+  passkeyLoginOptions,
+  passkeyLogin,
+  login2FAPasskeyOptions,
   isTotp2FARequired,
   register,
   getCurrentUser,
@@ -357,7 +419,8 @@ export const authAPI = {
   forgotPassword,
   resetPassword,
   refreshToken,
-  revokeAllSessions
+  revokeAllSessions,
+  endImpersonation
 }
 
 export default authAPI
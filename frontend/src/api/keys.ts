@@ -4,7 +4,13 @@
  */
 
 import { apiClient } from './client'
-import type { ApiKey, CreateApiKeyRequest, UpdateApiKeyRequest, PaginatedResponse } from '@/types'
+import type {
+  ApiKey,
+  ApiKeyPolicy,
+  CreateApiKeyRequest,
+  UpdateApiKeyRequest,
+  PaginatedResponse
+} from '@/types'
 
 /**
  * List all API keys for current user
@@ -49,6 +55,7 @@ export async function getById(id: number): Promise<ApiKey> {
  * @param quota - Optional quota limit in USD (0 = unlimited)
  * @param expiresInDays - Optional days until expiry (undefined = never expires)
  * @param rateLimitData - Optional rate limit fields
+ * @param policy - Optional access policy
  * @returns Created API key
  */
 export async function create(
@@ -59,7 +66,8 @@ export async function create(
   ipBlacklist?: string[],
   quota?: number,
   expiresInDays?: number,
-  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number }
+  rateLimitData?: { rate_limit_5h?: number; rate_limit_1d?: number; rate_limit_7d?: number },
+  policy?: ApiKeyPolicy
 ): Promise<ApiKey> {
   const payload: CreateApiKeyRequest = { name }
   if (groupId !== undefined) {
@@ -89,6 +97,9 @@ export async function create(
   if (rateLimitData?.rate_limit_7d && rateLimitData.rate_limit_7d > 0) {
     payload.rate_limit_7d = rateLimitData.rate_limit_7d
   }
+  if (policy && Object.keys(policy).length > 0) {
+    payload.policy = policy
+  }
 
   const { data } = await apiClient.post<ApiKey>('/keys', payload)
   return data
@@ -115,6 +126,19 @@ export async function deleteKey(id: number): Promise<{ message: string }> {
   return data
 }
 
+/**
+ * Rotate API key: issues a new secret, the old one stays valid during the grace period
+ * @param id - API key ID
+ * @param graceHours - Grace period for the old secret (omit for server default, 0 = revoke now)
+ * @returns Rotated API key including the new plaintext key
+ */
+export async function rotate(id: number, graceHours?: number): Promise<ApiKey> {
+  const { data } = await apiClient.post<ApiKey>(`/keys/${id}/rotate`, {
+    grace_hours: graceHours
+  })
+  return data
+}
+
 /**
  * Toggle API key status (active/inactive)
  * @param id - API key ID
@@ -131,6 +155,7 @@ export const keysAPI = {
   create,
   update,
   delete: deleteKey,
+  rotate,
   toggleStatus
 }
 
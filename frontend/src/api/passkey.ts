@@ -0,0 +1,83 @@
+/**
+ * Passkey (WebAuthn) API endpoints
+ * Handles passkey registration and management for the current user
+ */
+
+import { apiClient } from './client'
+import type {
+  PasskeyStatus,
+  PasskeyCredentialCreation,
+  PasskeyVerifyRequest,
+  UserPasskey
+} from '@/types'
+
+/**
+ * Get passkey feature status and registered passkeys
+ * @returns Feature status and passkey list
+ */
+export async function getStatus(): Promise<PasskeyStatus> {
+  const { data } = await apiClient.get<PasskeyStatus>('/user/passkeys')
+  return data
+}
+
+/**
+ * Start passkey registration
+ * @param request - Email code or password depending on verification method
+ * @returns Ceremony token and creation options for navigator.credentials.create()
+ */
+export async function beginRegistration(request: PasskeyVerifyRequest): Promise<PasskeyCredentialCreation> {
+  const { data } = await apiClient.post<PasskeyCredentialCreation>('/user/passkeys/register/begin', request)
+  return data
+}
+
+/**
+ * Finish passkey registration with the authenticator attestation
+ * @param ceremonyToken - Token from beginRegistration
+ * @param credential - Serialized attestation
+ * @param name - Display name of the passkey
+ * @returns Registered passkey
+ */
+export async function finishRegistration(
+  ceremonyToken: string,
+  credential: Record<string, any>,
+  name: string
+): Promise<UserPasskey> {
+  const { data } = await apiClient.post<UserPasskey>('/user/passkeys/register/finish', {
+    ceremony_token: ceremonyToken,
+    credential,
+    name
+  })
+  return data
+}
+
+/**
+ * Rename a passkey
+ * @param id - Passkey ID
+ * @param name - New display name
+ * @returns Success response
+ */
+export async function rename(id: number, name: string): Promise<{ success: boolean }> {
+  const { data } = await apiClient.put<{ success: boolean }>(`/user/passkeys/${id}`, { name })
+  return data
+}
+
+/**
+ * Remove a passkey
+ * @param id - Passkey ID
+ * @param request - Email code or password depending on verification method
+ * @returns Success response
+ */
+export async function remove(id: number, request: PasskeyVerifyRequest): Promise<{ success: boolean }> {
+  const { data } = await apiClient.post<{ success: boolean }>(`/user/passkeys/${id}/delete`, request)
+  return data
+}
+
+export const passkeyAPI = {
+  getStatus,
+  beginRegistration,
+  finishRegistration,
+  rename,
+  remove
+}
+
+export default passkeyAPI
@@ -227,6 +227,7 @@ export interface BatchApiKeyUsageStats {
   api_key_id: number
   today_actual_cost: number
   total_actual_cost: number
+  prompt_cache_savings?: number // savings from auto cache breakpoints over the same window
 }
 
 export interface BatchApiKeysUsageResponse {
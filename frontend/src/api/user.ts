@@ -4,7 +4,7 @@
  */
 
 import { apiClient } from './client'
-import type { User, ChangePasswordRequest } from '@/types'
+import type { User, ChangePasswordRequest, UserSession, UserIdentity } from '@/types'
 
 /**
  * Get current user profile
@@ -45,10 +45,72 @@ export async function changePassword(
   return data
 }
 
+/**
+ * List login sessions (devices) of current user
+ * @returns Active sessions, most recently used first
+ */
+export async function listSessions(): Promise<UserSession[]> {
+  const { data } = await apiClient.get<UserSession[]>('/user/sessions')
+  return data
+}
+
+/**
+ * Revoke a single login session of current user
+ * @param id - Session ID
+ * @returns Success message
+ */
+export async function revokeSession(id: string): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(`/user/sessions/${encodeURIComponent(id)}`)
+  return data
+}
+
+/**
+ * List external identities (OIDC) linked to current user
+ * @returns Linked identities
+ */
+export async function listIdentities(): Promise<UserIdentity[]> {
+  const { data } = await apiClient.get<UserIdentity[]>('/user/identities')
+  return data
+}
+
+/**
+ * Start linking an external identity
+ * @param provider - OIDC provider key
+ * @param redirect - Frontend path to return to after linking
+ * @returns Authorization URL to navigate to
+ */
+export async function linkIdentity(
+  provider: string,
+  redirect = '/profile'
+): Promise<{ authorize_url: string }> {
+  const { data } = await apiClient.post<{ authorize_url: string }>(
+    `/user/oidc/${encodeURIComponent(provider)}/link`,
+    { redirect }
+  )
+  return data
+}
+
+/**
+ * Unlink an external identity from current user
+ * @param provider - OIDC provider key
+ * @returns Success message
+ */
+export async function unlinkIdentity(provider: string): Promise<{ message: string }> {
+  const { data } = await apiClient.delete<{ message: string }>(
+    `/user/identities/${encodeURIComponent(provider)}`
+  )
+  return data
+}
+
 export const userAPI = {
   getProfile,
   updateProfile,
-  changePassword
+  changePassword,
+  listSessions,
+  revokeSession,
+  listIdentities,
+  linkIdentity,
+  unlinkIdentity
 }
 
 export default userAPI
@@ -338,6 +338,7 @@ export default {
     proxies: 'Proxies',
     redeemCodes: 'Redeem Codes',
     ops: 'Ops',
+    experiments: 'Experiments',
     promoCodes: 'Promo Codes',
     dataManagement: 'Data Management',
     settings: 'Settings',
@@ -433,6 +434,21 @@ export default {
       callbackProcessing: 'Completing login, please wait...',
       callbackHint: 'If you are not redirected automatically, go back to the login page and try again.',
       callbackMissingToken: 'Missing login token, please try again.',
+      secondFactorUnsupported: 'This account requires a passkey for two-factor verification, which this browser does not support.',
+      backToLogin: 'Back to Login'
+    },
+    passkey: {
+      signIn: 'Sign in with a passkey',
+      loginFailed: 'Passkey sign-in failed'
+    },
+    oidc: {
+      signIn: 'Continue with {name}',
+      callbackTitle: 'Signing you in',
+      callbackProcessing: 'Completing login, please wait...',
+      callbackHint: 'If you are not redirected automatically, go back to the login page and try again.',
+      callbackMissingToken: 'Missing login token, please try again.',
+      secondFactorUnsupported: 'This account requires a passkey for two-factor verification, which this browser does not support.',
+      linkSuccess: 'Account linked',
       backToLogin: 'Back to Login'
     },
     oauth: {
@@ -556,6 +572,13 @@ export default {
     keyCreatedSuccess: 'API key created successfully',
     keyUpdatedSuccess: 'API key updated successfully',
     keyDeletedSuccess: 'API key deleted successfully',
+    rotateKey: 'Rotate',
+    rotateKeyTitle: 'Rotate API Key',
+    rotateConfirmMessage: "Issue a new secret for '{name}'? The current secret keeps working for a grace period and is then revoked automatically.",
+    keyRotatedSuccess: 'API key rotated, copy the new secret now',
+    failedToRotate: 'Failed to rotate API key',
+    previousKeyValidUntil: 'Old key {prefix}... valid until {time}',
+    secretUnavailable: 'The full key is only shown at creation or rotation. Rotate the key to get a new secret.',
     keyEnabledSuccess: 'API key enabled successfully',
     keyDisabledSuccess: 'API key disabled successfully',
     failedToLoad: 'Failed to load API keys',
@@ -569,6 +592,7 @@ export default {
     usage: 'Usage',
     today: 'Today',
     total: 'Total',
+    cacheSavings: 'Cache savings',
     quota: 'Quota',
     lastUsedAt: 'Last Used',
     useKey: 'Use Key',
@@ -626,6 +650,26 @@ export default {
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: 'One IP or CIDR per line. These IPs will be blocked from using this key.',
     ipRestrictionEnabled: 'IP restriction enabled',
+    policy: {
+      title: 'Access Policy',
+      allowedModels: 'Allowed Models',
+      deniedModels: 'Denied Models',
+      modelsHint: 'One pattern per line, * and ? wildcards supported. Denied models take precedence; leave empty for no restriction.',
+      allowedEndpoints: 'Allowed Endpoints',
+      endpointsHint: 'One request path per line (e.g. /v1/messages, /v1beta/models/*). Leave empty to allow all endpoints.',
+      allowedPlatforms: 'Allowed Platforms',
+      platformsHint: 'Leave all unchecked to allow every platform.',
+      maxInputTokens: 'Max Input Tokens',
+      maxOutputTokens: 'Max Output Tokens',
+      rpm: 'Requests per Minute',
+      tpm: 'Tokens per Minute',
+      limitsHint: 'Leave empty or 0 for no limit. Input tokens are estimated from the request body.',
+      queuePriority: 'Queue Priority',
+      queuePriorityHint: '1-9, used when all accounts are busy. Can only lower the priority granted by your account or group; leave empty to inherit.',
+      denyThinking: 'Deny thinking / reasoning',
+      denyTools: 'Deny tool definitions',
+      responseCache: 'Cache deterministic responses (temperature 0)'
+    },
     ccSwitchNotInstalled: 'CC-Switch is not installed or the protocol handler is not registered. Please install CC-Switch first or manually copy the API key.',
     ccsClientSelect: {
       title: 'Select Client',
@@ -782,6 +826,15 @@ export default {
     pleaseEnterCode: 'Please enter a redeem code'
   },
 
+  // Admin impersonation banner
+  impersonation: {
+    banner: 'You are viewing as {email} (impersonated by {admin})',
+    scopeRead: 'Read-only',
+    scopeWrite: 'Changes allowed',
+    expiresAt: 'Ends at {time}',
+    end: 'End impersonation'
+  },
+
   // Profile
   profile: {
     title: 'Profile Settings',
@@ -810,6 +863,60 @@ export default {
     passwordTooShort: 'Password must be at least 8 characters long',
     passwordChangeSuccess: 'Password changed successfully',
     passwordChangeFailed: 'Failed to change password',
+    // Login sessions
+    sessions: {
+      title: 'Login Sessions',
+      description: 'Devices currently signed in to your account. Revoke any session you do not recognize.',
+      empty: 'No active sessions',
+      current: 'This device',
+      unknownDevice: 'Unknown device',
+      lastSeen: 'Last active',
+      signedIn: 'Signed in',
+      revoke: 'Sign out',
+      revokeConfirm: 'Sign out the session on {device}?',
+      revokeSuccess: 'Session signed out',
+      revokeFailed: 'Failed to sign out session'
+    },
+    passkeys: {
+      title: 'Passkeys',
+      description: 'Sign in without a password, or use a passkey as your second factor.',
+      add: 'Add passkey',
+      empty: 'No passkeys registered',
+      unsupported: 'This browser does not support passkeys.',
+      unnamed: 'Passkey',
+      createdAt: 'Added',
+      lastUsed: 'Last used',
+      neverUsed: 'Never',
+      name: 'Name',
+      namePlaceholder: 'e.g. MacBook Touch ID',
+      registerTitle: 'Add Passkey',
+      registerHint: 'Verify your identity, then follow your browser prompt to create the passkey.',
+      register: 'Continue',
+      registerSuccess: 'Passkey added',
+      registerFailed: 'Failed to add passkey',
+      rename: 'Rename',
+      renamePrompt: 'New passkey name',
+      renameSuccess: 'Passkey renamed',
+      renameFailed: 'Failed to rename passkey',
+      deleteTitle: 'Remove Passkey',
+      deleteWarning: 'You will no longer be able to sign in with "{name}".',
+      confirmDelete: 'Remove',
+      deleteSuccess: 'Passkey removed',
+      deleteFailed: 'Failed to remove passkey',
+      useForLogin: 'Verify with a passkey'
+    },
+    identities: {
+      title: 'Linked Accounts',
+      description: 'Sign in with an external identity provider linked to this account.',
+      notLinked: 'Not linked',
+      linkedAt: 'Linked',
+      link: 'Link',
+      linkFailed: 'Failed to start linking',
+      unlink: 'Unlink',
+      unlinkConfirm: 'Unlink your {name} account?',
+      unlinkSuccess: 'Account unlinked',
+      unlinkFailed: 'Failed to unlink account'
+    },
     // TOTP 2FA
     totp: {
       title: 'Two-Factor Authentication (2FA)',
@@ -1203,6 +1310,41 @@ export default {
       concurrencyMin: 'Concurrency must be at least 1',
       soraStorageQuota: 'Sora Storage Quota',
       soraStorageQuotaHint: 'In GB, 0 means use group or system default quota',
+      rpmLimit: 'Requests per Minute',
+      tpmLimit: 'Tokens per Minute',
+      rateLimitHint: 'Sliding one-minute window across all API keys of the user. 0 means unlimited.',
+      queuePriority: 'Queue Priority',
+      queuePriorityHint: '0-9, higher is served first when all accounts are busy. The higher of user and group priority applies.',
+      sessions: {
+        menu: 'Login Sessions',
+        title: 'Login Sessions',
+        empty: 'This user has no active sessions',
+        revokeAll: 'Sign out all',
+        revokeAllConfirm: 'Sign out all sessions of {email}?',
+        revokeAllSuccess: 'All sessions signed out'
+      },
+      impersonate: {
+        menu: 'Impersonate',
+        title: 'Impersonate User',
+        description: 'Sign in as this user to see exactly what they see. Every request is recorded in the audit log.',
+        reason: 'Reason',
+        reasonPlaceholder: 'e.g. ticket #1234 – usage chart looks empty',
+        duration: 'Duration',
+        minutes: '{n} minutes',
+        writeAccess: 'Allow changes',
+        hint: 'Read-only by default. Password, 2FA, passkey, session and key reveal actions are always blocked.',
+        start: 'Start impersonation',
+        failed: 'Failed to start impersonation',
+        recentLogs: 'Recent impersonation activity',
+        noLogs: 'No impersonation activity for this user',
+        byAdmin: 'by admin #{id}',
+        events: {
+          start: 'Start',
+          action: 'Request',
+          blocked: 'Blocked',
+          end: 'End'
+        }
+      },
       amountRequired: 'Please enter a valid amount',
       insufficientBalance: 'Insufficient balance',
       deleteConfirm: "Are you sure you want to delete '{email}'? This action cannot be undone.",
@@ -1373,6 +1515,85 @@ export default {
       platformHint: 'Select the platform this group is associated with',
       platformNotEditable: 'Platform cannot be changed after creation',
       rateMultiplierHint: 'Cost multiplier for this group (e.g., 1.5 = 150% of base cost)',
+      schedulingStrategy: {
+        title: 'Account Scheduling Strategy',
+        hint: 'How accounts in this group are picked after sticky sessions. Default uses priority + LRU (OpenAI: composite score).',
+        default: 'Platform default',
+        priorityLru: 'Priority + LRU',
+        weightedRoundRobin: 'Weighted round robin',
+        leastLoaded: 'Least loaded',
+        lowestLatency: 'Lowest latency (TTFT EWMA)',
+        cheapest: 'Cheapest rate multiplier',
+        powerOfTwo: 'Power of two choices'
+      },
+      rateLimit: {
+        title: 'Rate Limits',
+        rpm: 'Requests per Minute',
+        tpm: 'Tokens per Minute',
+        hint: 'Sliding one-minute window across all requests routed to this group. 0 means unlimited.'
+      },
+      crossPlatformFailover: {
+        title: 'Cross-Platform Failover',
+        hint: 'When every account on this platform is unavailable, continue the request on this group\'s accounts of the peer platform (anthropic ↔ antigravity). Sticky sessions move to the new account.'
+      },
+      streamResume: {
+        title: 'Stream Resumption',
+        hint: 'Buffer streaming responses on the gateway for a short window. Every event carries an ID; a client whose connection drops can reconnect with the Last-Event-ID header (or GET /v1/messages/resume) and receive the rest without a new upstream call. The upstream keeps streaming after the client disconnects.'
+      },
+      contextPolicy: {
+        title: 'Context Policy',
+        hint: 'Estimate input tokens on the gateway before forwarding. Requests above the limit are rejected with the estimate, have their oldest turns dropped, or have their oldest turns summarized, instead of failing upstream with "prompt is too long". Tool calls and their results are always kept together. The action taken is recorded on the usage log.',
+        action: 'When Over Limit',
+        actionReject: 'Reject with error',
+        actionTruncate: 'Drop oldest turns',
+        actionSummarize: 'Summarize oldest turns',
+        maxInputTokens: 'Max Input Tokens (estimated)',
+        summaryModel: 'Summary Model',
+        summaryMaxTokens: 'Summary Max Tokens',
+        summaryHint: 'The summary is generated by an account in this group and billed to the caller as a separate usage record. If summarization fails, the oldest turns are dropped instead.',
+        invalid: 'Invalid context policy: max input tokens must be 1024-2000000; summarize requires a summary model and summary max tokens below half of max input tokens'
+      },
+      autoCacheBreakpoints: {
+        title: 'Auto Prompt Cache Breakpoints',
+        hint: 'For clients that never set cache_control, insert up to four cache breakpoints on stable prefixes: the tool definitions, the system prompt, and earlier turns of recognised multi-turn conversations. Breakpoint TTL follows each account\'s cache TTL override. Savings are reported per API key.'
+      },
+      responseCache: {
+        title: 'Response Cache',
+        hint: 'Replay identical deterministic requests (temperature 0) from the gateway cache, for both streaming and JSON responses. Cache hits do not reach upstream accounts and are billed at the hit price ratio. Clients can skip the cache with the header X-Response-Cache: bypass.',
+        ttlSeconds: 'TTL (seconds)',
+        maxEntryKb: 'Max Entry Size (KB)',
+        maxTotalMb: 'Max Total Size per Scope (MB)',
+        hitPricePercent: 'Hit Price (% of original)',
+        scope: 'Sharing Scope',
+        scopeApiKey: 'Per API key',
+        scopeGroup: 'Whole group',
+        invalid: 'Response cache requires a TTL of 1-604800 seconds, an entry size of 1-8192 KB, a total size of 1-1024 MB (not below the entry size) and a hit price between 0 and 100%'
+      },
+      shadowMirror: {
+        title: 'Shadow Mirroring',
+        hint: 'Asynchronously duplicate a sample of successful requests to a shadow account or group. The shadow response is discarded; only status, latency, token usage and output similarity are recorded. Shadow traffic is never billed and is skipped when the shadow account is at its concurrency limit.',
+        sampleRate: 'Sample Rate (%)',
+        targetType: 'Shadow Target',
+        targetAccount: 'Account',
+        targetGroup: 'Group',
+        targetId: 'Target ID',
+        invalid: 'Shadow mirroring requires a target ID and a sample rate between 0 and 100%',
+        summaryTitle: 'Shadow comparison (last 7 days)',
+        total: 'Mirrored',
+        shadowResult: 'Succeeded / Failed / Skipped',
+        latency: 'Avg latency (primary → shadow)',
+        outputTokens: 'Avg output tokens (primary → shadow)',
+        similarity: 'Avg output similarity'
+      },
+      modelFallback: {
+        title: 'Model Fallback Chains',
+        hint: 'JSON array. When the requested model matches source and a trigger fires (no_account, rate_limited, overloaded, context_too_long; empty = all), the fallbacks are tried in order. Platform may be anthropic or antigravity. Only applies to the Claude Messages API (/v1/messages); fallback steps not allowed by an API key's access policy are skipped. The response model and billing follow the fallback model.',
+        invalidJson: 'Model fallback chains must be a valid JSON array'
+      },
+      queuePriority: {
+        title: 'Queue Priority',
+        hint: '0-9, higher is served first when all accounts are busy. Long waits are gradually promoted to prevent starvation.'
+      },
       exclusiveHint: 'Exclusive group, manually assign to specific users',
       exclusiveTooltip: {
         title: 'What is an exclusive group?',
@@ -2783,6 +3004,80 @@ export default {
     },
 
     // Promo Codes
+    experiments: {
+      title: 'Model Experiments',
+      description: 'A/B test models, groups and system prompts with sticky traffic splits',
+      hint: 'Matching requests are split by user or API key hash; the same user always lands in the same variant. Usage and error logs are tagged with the variant for comparison.',
+      create: 'New Experiment',
+      edit: 'Edit Experiment',
+      delete: 'Delete Experiment',
+      deleteConfirm: 'Delete this experiment? Tagged usage history is kept.',
+      name: 'Name',
+      description: 'Description',
+      statusLabel: 'Status',
+      status: {
+        draft: 'Draft',
+        running: 'Running',
+        stopped: 'Stopped'
+      },
+      modelPattern: 'Model Pattern',
+      modelPatternHint: 'Requested model to match, supports * and ? wildcards',
+      stickyByLabel: 'Sticky By',
+      stickyByHint: 'Hash key used to keep assignment stable',
+      stickyBy: {
+        user: 'User',
+        api_key: 'API Key'
+      },
+      scopeGroups: 'Limit to Groups',
+      scopeGroupsHint: 'Only keys in the selected groups join the experiment; leave empty for all groups',
+      variants: 'Variants',
+      percentTotal: 'Total: {total}%',
+      percentMustSum: 'Variant percentages must sum to 100',
+      variantName: 'Variant Name',
+      percent: 'Traffic %',
+      variantModel: 'Model',
+      variantGroup: 'Account Group',
+      unchanged: 'Unchanged',
+      control: 'Control (no changes)',
+      systemPromptPatch: 'System Prompt Patch',
+      systemPromptPatchHint: 'Appended to the end of the system prompt; leave empty for no change',
+      addVariant: 'Add Variant',
+      removeVariant: 'Remove',
+      viewResults: 'Results',
+      resultsTitle: 'Results: {name}',
+      startDate: 'Start Date',
+      endDate: 'End Date',
+      created: 'Experiment created',
+      updated: 'Experiment updated',
+      deleted: 'Experiment deleted',
+      failedToLoad: 'Failed to load experiments',
+      failedToSave: 'Failed to save experiment',
+      failedToDelete: 'Failed to delete experiment',
+      failedToLoadResults: 'Failed to load experiment results',
+      columns: {
+        name: 'Name',
+        status: 'Status',
+        modelPattern: 'Model Pattern',
+        stickyBy: 'Sticky By',
+        variants: 'Variants',
+        updatedAt: 'Updated',
+        actions: 'Actions'
+      },
+      results: {
+        variant: 'Variant',
+        requests: 'Requests (+Errors)',
+        errorRate: 'Error Rate',
+        users: 'Users / Returning',
+        returningHint: 'Users active on 2 or more days in the range',
+        avgDuration: 'Avg Latency',
+        avgFirstToken: 'Avg First Token',
+        avgOutputTokens: 'Avg Output Tokens',
+        totalCost: 'Total Cost',
+        avgCost: 'Avg Cost',
+        footnote: 'Group overrides do not apply to subscription keys. Error rate counts upstream and gateway errors, excluding local rate limits.'
+      }
+    },
+
     promo: {
       title: 'Promo Code Management',
       description: 'Create and manage registration promo codes',
@@ -2874,6 +3169,7 @@ export default {
       allBillingTypes: 'All Billing Types',
       billingTypeBalance: 'Balance',
       billingTypeSubscription: 'Subscription',
+      billingTypeResponseCache: 'Response Cache Hit',
       ipAddress: 'IP',
       clickToViewBalance: 'Click to view balance history',
       failedToLoadUser: 'Failed to load user info',
@@ -3012,6 +3308,33 @@ export default {
         '7d': 'Last 7 days',
         '30d': 'Last 30 days'
       },
+      crossPlatformFailover: {
+        title: 'Cross-Platform Failover',
+        description: 'Requests that exhausted anthropic accounts and continued on antigravity (or vice versa). Counted per instance since startup.',
+        failedToLoad: 'Failed to load cross-platform failover stats',
+        empty: 'No cross-platform failover has been triggered',
+        table: {
+          group: 'Group',
+          direction: 'Direction',
+          triggered: 'Triggered',
+          succeeded: 'Succeeded',
+          successRate: 'Success Rate',
+          lastTriggeredAt: 'Last Triggered'
+        }
+      },
+      streamResume: {
+        title: 'Stream Resumption',
+        description: 'Streams buffered for groups with stream resumption enabled, and how often clients resumed after a dropped connection. Counted per instance since startup.',
+        failedToLoad: 'Failed to load stream resumption stats',
+        streamsStarted: 'Buffered Streams',
+        resumeHits: 'Resume Hits',
+        resumeMisses: 'Resume Misses',
+        hitRate: 'Hit Rate',
+        activeStreams: 'Streams in Buffer',
+        bufferUsage: 'Buffer Usage',
+        evicted: 'Evicted Early',
+        overflowed: 'Over Buffer Limit'
+      },
       openaiTokenStats: {
         title: 'OpenAI Token Request Stats',
         viewModeTopN: 'TopN',
@@ -3615,6 +3938,7 @@ export default {
         disabledHint: 'Realtime monitoring is disabled in settings.',
         empty: 'No data',
         queued: 'Queue {count}',
+        queuedByPriority: 'P{priority} × {count}',
         rateLimited: 'Rate-limited {count}',
         errorAccounts: 'Errors {count}',
         loadFailed: 'Failed to load concurrency data'
@@ -3705,7 +4029,12 @@ export default {
         totp: 'Two-Factor Authentication (2FA)',
         totpHint: 'Allow users to use authenticator apps like Google Authenticator',
         totpKeyNotConfigured:
-          'Please configure TOTP_ENCRYPTION_KEY in environment variables first. Generate a key with: openssl rand -hex 32'
+          'Please configure TOTP_ENCRYPTION_KEY in environment variables first. Generate a key with: openssl rand -hex 32',
+        passkey: 'Passkeys',
+        passkeyHint: 'Allow users to sign in with passkeys and use them as a second factor',
+        passkeyNotConfigured: 'Please configure webauthn.rp_id and webauthn.rp_origins (or server.frontend_url) first.',
+        adminRequire2fa: 'Require 2FA for Admins',
+        adminRequire2faHint: 'Admins must enable TOTP or a passkey before using the admin console'
       },
       turnstile: {
         title: 'Cloudflare Turnstile',
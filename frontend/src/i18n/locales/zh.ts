@@ -338,6 +338,7 @@ export default {
     proxies: 'IP管理',
     redeemCodes: '兑换码',
     ops: '运维监控',
+    experiments: '模型实验',
     promoCodes: '优惠码',
     dataManagement: '数据管理',
     settings: '系统设置',
@@ -432,6 +433,21 @@ export default {
       callbackProcessing: '正在验证登录信息，请稍候...',
       callbackHint: '如果页面未自动跳转，请返回登录页重试。',
       callbackMissingToken: '登录信息缺失，请返回重试。',
+      secondFactorUnsupported: '该账号需要使用 Passkey 完成两步验证，当前浏览器不支持 Passkey。',
+      backToLogin: '返回登录'
+    },
+    passkey: {
+      signIn: '使用 Passkey 登录',
+      loginFailed: 'Passkey 登录失败'
+    },
+    oidc: {
+      signIn: '使用 {name} 登录',
+      callbackTitle: '正在完成登录',
+      callbackProcessing: '正在验证登录信息，请稍候...',
+      callbackHint: '如果页面未自动跳转，请返回登录页重试。',
+      callbackMissingToken: '登录信息缺失，请返回重试。',
+      secondFactorUnsupported: '该账号需要使用 Passkey 完成两步验证，当前浏览器不支持 Passkey。',
+      linkSuccess: '账号绑定成功',
       backToLogin: '返回登录'
     },
     oauth: {
@@ -556,6 +572,13 @@ export default {
     keyCreatedSuccess: 'API 密钥创建成功',
     keyUpdatedSuccess: 'API 密钥更新成功',
     keyDeletedSuccess: 'API 密钥删除成功',
+    rotateKey: '轮换',
+    rotateKeyTitle: '轮换 API 密钥',
+    rotateConfirmMessage: "确定为 '{name}' 签发新密钥吗？当前密钥在宽限期内仍可使用，到期后自动失效。",
+    keyRotatedSuccess: 'API 密钥已轮换，请立即复制新密钥',
+    failedToRotate: '轮换 API 密钥失败',
+    previousKeyValidUntil: '旧密钥 {prefix}... 有效期至 {time}',
+    secretUnavailable: '完整密钥仅在创建或轮换时显示，如需使用请轮换密钥获取新密钥。',
     keyEnabledSuccess: 'API 密钥已启用',
     keyDisabledSuccess: 'API 密钥已禁用',
     failedToLoad: '加载 API 密钥失败',
@@ -569,6 +592,7 @@ export default {
     usage: '用量',
     today: '今日',
     total: '累计',
+    cacheSavings: '缓存节省',
     quota: '额度',
     lastUsedAt: '上次使用时间',
     useKey: '使用密钥',
@@ -630,6 +654,26 @@ export default {
     ipBlacklistPlaceholder: '1.2.3.4\n5.6.0.0/16',
     ipBlacklistHint: '每行一个 IP 或 CIDR，这些 IP 将被禁止使用此密钥',
     ipRestrictionEnabled: '已配置 IP 限制',
+    policy: {
+      title: '访问策略',
+      allowedModels: '允许的模型',
+      deniedModels: '禁止的模型',
+      modelsHint: '每行一个规则，支持 * 和 ? 通配符。禁止列表优先，留空表示不限制。',
+      allowedEndpoints: '允许的端点',
+      endpointsHint: '每行一个请求路径（如 /v1/messages、/v1beta/models/*），留空表示允许所有端点。',
+      allowedPlatforms: '允许的平台',
+      platformsHint: '全部不勾选表示允许所有平台。',
+      maxInputTokens: '单次输入 Token 上限',
+      maxOutputTokens: '单次输出 Token 上限',
+      rpm: '每分钟请求数',
+      tpm: '每分钟 Token 数',
+      limitsHint: '留空或填 0 表示不限制，输入 Token 数根据请求体估算。',
+      queuePriority: '排队优先级',
+      queuePriorityHint: '1-9，账号全部满载排队时使用；只能低于账号或分组赋予的优先级，留空表示沿用。',
+      denyThinking: '禁止 thinking / reasoning',
+      denyTools: '禁止携带工具定义',
+      responseCache: '缓存确定性请求的响应（temperature 为 0）'
+    },
     ccSwitchNotInstalled:
       'CC-Switch 未安装或协议处理程序未注册。请先安装 CC-Switch 或手动复制 API 密钥。',
     ccsClientSelect: {
@@ -787,6 +831,15 @@ export default {
     pleaseEnterCode: '请输入兑换码'
   },
 
+  // Admin impersonation banner
+  impersonation: {
+    banner: '当前正以 {email} 的身份浏览（由 {admin} 模拟登录）',
+    scopeRead: '只读',
+    scopeWrite: '允许修改',
+    expiresAt: '{time} 结束',
+    end: '结束模拟'
+  },
+
   // Profile
   profile: {
     title: '个人设置',
@@ -815,6 +868,60 @@ export default {
     passwordTooShort: '密码至少需要 8 个字符',
     passwordChangeSuccess: '密码修改成功',
     passwordChangeFailed: '密码修改失败',
+    // 登录会话
+    sessions: {
+      title: '登录设备',
+      description: '当前登录您账号的设备，如有不认识的会话请立即移除。',
+      empty: '暂无活跃会话',
+      current: '当前设备',
+      unknownDevice: '未知设备',
+      lastSeen: '最近活跃',
+      signedIn: '登录于',
+      revoke: '移除',
+      revokeConfirm: '确定移除 {device} 上的登录会话吗？',
+      revokeSuccess: '会话已移除',
+      revokeFailed: '移除会话失败'
+    },
+    passkeys: {
+      title: 'Passkey',
+      description: '使用 Passkey 免密码登录，或将其作为两步验证的第二因素。',
+      add: '添加 Passkey',
+      empty: '尚未注册 Passkey',
+      unsupported: '当前浏览器不支持 Passkey。',
+      unnamed: 'Passkey',
+      createdAt: '添加于',
+      lastUsed: '最近使用',
+      neverUsed: '从未',
+      name: '名称',
+      namePlaceholder: '例如 MacBook Touch ID',
+      registerTitle: '添加 Passkey',
+      registerHint: '验证身份后，按浏览器提示创建 Passkey。',
+      register: '继续',
+      registerSuccess: 'Passkey 已添加',
+      registerFailed: '添加 Passkey 失败',
+      rename: '重命名',
+      renamePrompt: '新的 Passkey 名称',
+      renameSuccess: 'Passkey 已重命名',
+      renameFailed: '重命名 Passkey 失败',
+      deleteTitle: '移除 Passkey',
+      deleteWarning: '移除后将无法再使用「{name}」登录。',
+      confirmDelete: '移除',
+      deleteSuccess: 'Passkey 已移除',
+      deleteFailed: '移除 Passkey 失败',
+      useForLogin: '使用 Passkey 验证'
+    },
+    identities: {
+      title: '第三方账号',
+      description: '绑定外部身份提供方后，可直接使用其登录本账号。',
+      notLinked: '未绑定',
+      linkedAt: '绑定于',
+      link: '绑定',
+      linkFailed: '发起绑定失败',
+      unlink: '解除绑定',
+      unlinkConfirm: '确定解除与 {name} 的绑定吗？',
+      unlinkSuccess: '已解除绑定',
+      unlinkFailed: '解除绑定失败'
+    },
     // TOTP 2FA
     totp: {
       title: '双因素认证 (2FA)',
@@ -1268,6 +1375,41 @@ export default {
       concurrencyMin: '并发数不能小于1',
       soraStorageQuota: 'Sora 存储配额',
       soraStorageQuotaHint: '单位 GB，0 表示使用分组或系统默认配额',
+      rpmLimit: '每分钟请求数',
+      tpmLimit: '每分钟 Token 数',
+      rateLimitHint: '按一分钟滑动窗口统计该用户所有 API 密钥的合计用量，0 表示不限制。',
+      queuePriority: '排队优先级',
+      queuePriorityHint: '0-9，账号全部满载时数值越大越先获得槽位；与分组优先级取较大值。',
+      sessions: {
+        menu: '登录设备',
+        title: '登录设备',
+        empty: '该用户暂无活跃会话',
+        revokeAll: '全部移除',
+        revokeAllConfirm: '确定移除 {email} 的所有登录会话吗？',
+        revokeAllSuccess: '已移除所有会话'
+      },
+      impersonate: {
+        menu: '模拟登录',
+        title: '模拟用户登录',
+        description: '以该用户身份登录，查看与用户完全一致的页面。所有请求都会写入审计日志。',
+        reason: '原因',
+        reasonPlaceholder: '例如：工单 #1234，用量图表为空',
+        duration: '时长',
+        minutes: '{n} 分钟',
+        writeAccess: '允许修改',
+        hint: '默认只读。修改密码、2FA、Passkey、会话管理及查看 Key 明文等操作始终被禁止。',
+        start: '开始模拟',
+        failed: '开始模拟失败',
+        recentLogs: '最近的模拟记录',
+        noLogs: '该用户暂无模拟记录',
+        byAdmin: '管理员 #{id}',
+        events: {
+          start: '开始',
+          action: '请求',
+          blocked: '已拦截',
+          end: '结束'
+        }
+      },
       amountRequired: '请输入有效金额',
       insufficientBalance: '余额不足',
       setAllowedGroups: '设置允许分组',
@@ -1452,6 +1594,85 @@ export default {
           '公开分组费率 0.8，您可以创建一个费率 0.7 的专属分组，手动分配给 VIP 用户，让他们享受更优惠的价格。'
       },
       rateMultiplierHint: '1.0 = 标准费率，0.5 = 半价，2.0 = 双倍',
+      schedulingStrategy: {
+        title: '账号调度策略',
+        hint: '粘性会话未命中时如何从分组内挑选账号。默认为优先级 + LRU（OpenAI 为综合评分）。',
+        default: '平台默认',
+        priorityLru: '优先级 + LRU',
+        weightedRoundRobin: '加权轮询',
+        leastLoaded: '最小负载',
+        lowestLatency: '最低延迟（首字耗时 EWMA）',
+        cheapest: '最低账号倍率',
+        powerOfTwo: '随机二选一'
+      },
+      rateLimit: {
+        title: '速率限制',
+        rpm: '每分钟请求数',
+        tpm: '每分钟 Token 数',
+        hint: '按一分钟滑动窗口统计路由到该分组的所有请求，0 表示不限制。'
+      },
+      crossPlatformFailover: {
+        title: '跨平台故障转移',
+        hint: '本平台账号全部不可用时，转到分组内对端平台（anthropic ↔ antigravity）的账号继续处理请求，粘性会话随之迁移到新账号。'
+      },
+      streamResume: {
+        title: '流式断线续传',
+        hint: '在网关短暂缓冲流式响应，每个事件带有 ID；客户端断线后携带 Last-Event-ID 请求头重连（或请求 GET /v1/messages/resume）即可收到剩余内容，不会重新请求上游。客户端断开后上游继续输出。'
+      },
+      contextPolicy: {
+        title: '上下文策略',
+        hint: '转发前在网关本地估算输入 token。超出上限的请求按配置直接拒绝（返回估算值）、丢弃最早的对话轮次或摘要最早的对话轮次，避免上游返回 "prompt is too long"。工具调用与其结果始终成对保留，处理方式记录在使用日志中。',
+        action: '超限处理',
+        actionReject: '拒绝并返回错误',
+        actionTruncate: '丢弃最早轮次',
+        actionSummarize: '摘要最早轮次',
+        maxInputTokens: '输入 Token 上限（估算）',
+        summaryModel: '摘要模型',
+        summaryMaxTokens: '摘要最大 Token',
+        summaryHint: '摘要由本分组中的账号生成，作为单独的使用记录计入调用方。摘要失败时改为丢弃最早轮次。',
+        invalid: '上下文策略无效：输入 Token 上限须在 1024-2000000 之间；摘要模式需填写摘要模型，且摘要最大 Token 须小于输入上限的一半'
+      },
+      autoCacheBreakpoints: {
+        title: '自动 Prompt 缓存断点',
+        hint: '为从不设置 cache_control 的客户端，在稳定前缀上自动注入最多 4 个缓存断点：工具定义、system 提示词，以及识别为多轮对话的历史轮次。断点 TTL 跟随账号的缓存 TTL 强制替换设置，节省金额按 API Key 统计。'
+      },
+      responseCache: {
+        title: '响应缓存',
+        hint: '对 temperature 为 0 的相同请求直接回放网关缓存的响应（支持流式与 JSON）。命中不经过上游账号，按命中计费比例计费；客户端可通过请求头 X-Response-Cache: bypass 跳过缓存。',
+        ttlSeconds: '有效期（秒）',
+        maxEntryKb: '单条上限（KB）',
+        maxTotalMb: '每个范围总量上限（MB）',
+        hitPricePercent: '命中计费比例（%）',
+        scope: '共享范围',
+        scopeApiKey: '按 API Key',
+        scopeGroup: '整个分组',
+        invalid: '响应缓存要求有效期 1-604800 秒、单条上限 1-8192 KB、总量上限 1-1024 MB 且不小于单条上限、命中计费比例 0-100%'
+      },
+      shadowMirror: {
+        title: '影子流量镜像',
+        hint: '按采样率将成功请求异步复制到影子账号或分组，丢弃影子响应，仅记录状态、延迟、Token 用量与输出相似度。影子流量不计费，影子账号并发已满时跳过。',
+        sampleRate: '采样率（%）',
+        targetType: '影子目标',
+        targetAccount: '账号',
+        targetGroup: '分组',
+        targetId: '目标 ID',
+        invalid: '影子镜像需要填写目标 ID，且采样率在 0 到 100% 之间',
+        summaryTitle: '影子对比（最近 7 天）',
+        total: '镜像数',
+        shadowResult: '成功 / 失败 / 跳过',
+        latency: '平均延迟（主 → 影子）',
+        outputTokens: '平均输出 Token（主 → 影子）',
+        similarity: '平均输出相似度'
+      },
+      modelFallback: {
+        title: '模型降级链',
+        hint: 'JSON 数组。请求模型匹配 source 且命中触发条件（no_account、rate_limited、overloaded、context_too_long，留空表示全部）时，按顺序尝试降级模型；platform 可选 anthropic 或 antigravity。仅作用于 Claude Messages 接口（/v1/messages），API Key 访问策略不允许的降级步骤会被跳过。响应中的 model 与计费均按降级后的模型。',
+        invalidJson: '模型降级链必须是合法的 JSON 数组'
+      },
+      queuePriority: {
+        title: '排队优先级',
+        hint: '0-9，账号全部满载时数值越大越先获得槽位；等待较久的请求会逐步提升优先级以防饿死。'
+      },
       platforms: {
         all: '全部平台',
         anthropic: 'Anthropic',
@@ -2951,6 +3172,80 @@ export default {
     },
 
     // Promo Codes
+    experiments: {
+      title: '模型实验',
+      description: '以粘性分流对模型、分组与系统提示词进行 A/B 测试',
+      hint: '命中的请求按用户或 API Key 哈希分流，同一用户始终落在同一变体；使用记录与错误日志会标记变体以便对比。',
+      create: '新建实验',
+      edit: '编辑实验',
+      delete: '删除实验',
+      deleteConfirm: '确定删除该实验吗？已标记的使用记录会保留。',
+      name: '名称',
+      description: '描述',
+      statusLabel: '状态',
+      status: {
+        draft: '草稿',
+        running: '运行中',
+        stopped: '已停止'
+      },
+      modelPattern: '模型匹配',
+      modelPatternHint: '匹配请求模型，支持 * 与 ? 通配',
+      stickyByLabel: '粘性维度',
+      stickyByHint: '用于保持分流稳定的哈希键',
+      stickyBy: {
+        user: '用户',
+        api_key: 'API Key'
+      },
+      scopeGroups: '限定分组',
+      scopeGroupsHint: '仅所选分组的 Key 参与实验，留空表示全部分组',
+      variants: '变体',
+      percentTotal: '合计：{total}%',
+      percentMustSum: '变体流量占比合计必须为 100',
+      variantName: '变体名称',
+      percent: '流量占比 %',
+      variantModel: '模型',
+      variantGroup: '账号分组',
+      unchanged: '不修改',
+      control: '对照组（不做修改）',
+      systemPromptPatch: '系统提示词补丁',
+      systemPromptPatchHint: '追加到系统提示词末尾，留空表示不修改',
+      addVariant: '添加变体',
+      removeVariant: '移除',
+      viewResults: '结果',
+      resultsTitle: '实验结果：{name}',
+      startDate: '开始日期',
+      endDate: '结束日期',
+      created: '实验已创建',
+      updated: '实验已更新',
+      deleted: '实验已删除',
+      failedToLoad: '加载实验失败',
+      failedToSave: '保存实验失败',
+      failedToDelete: '删除实验失败',
+      failedToLoadResults: '加载实验结果失败',
+      columns: {
+        name: '名称',
+        status: '状态',
+        modelPattern: '模型匹配',
+        stickyBy: '粘性维度',
+        variants: '变体',
+        updatedAt: '更新时间',
+        actions: '操作'
+      },
+      results: {
+        variant: '变体',
+        requests: '请求数（+错误）',
+        errorRate: '错误率',
+        users: '用户 / 回访',
+        returningHint: '时间范围内 2 天及以上有请求的用户数',
+        avgDuration: '平均耗时',
+        avgFirstToken: '平均首 Token',
+        avgOutputTokens: '平均输出 Token',
+        totalCost: '总费用',
+        avgCost: '平均费用',
+        footnote: '分组改写不作用于订阅分组的 Key。错误率统计上游与网关错误，不含本地限流。'
+      }
+    },
+
     promo: {
       title: '优惠码管理',
       description: '创建和管理注册优惠码',
@@ -3042,6 +3337,7 @@ export default {
       allBillingTypes: '全部计费类型',
       billingTypeBalance: '钱包余额',
       billingTypeSubscription: '订阅套餐',
+      billingTypeResponseCache: '响应缓存命中',
       ipAddress: 'IP',
       clickToViewBalance: '点击查看充值记录',
       failedToLoadUser: '加载用户信息失败',
@@ -3182,6 +3478,33 @@ export default {
         '30d': '近30天',
         custom: '自定义'
       },
+      crossPlatformFailover: {
+        title: '跨平台故障转移',
+        description: 'anthropic 账号耗尽后转到 antigravity 继续处理（或反向）的请求次数，按实例自启动以来累计。',
+        failedToLoad: '加载跨平台故障转移统计失败',
+        empty: '暂未触发跨平台故障转移',
+        table: {
+          group: '分组',
+          direction: '方向',
+          triggered: '触发次数',
+          succeeded: '成功次数',
+          successRate: '成功率',
+          lastTriggeredAt: '最近触发'
+        }
+      },
+      streamResume: {
+        title: '流式断线续传',
+        description: '开启续传的分组缓冲的流数量，以及客户端断线后续传的次数，按实例自启动以来累计。',
+        failedToLoad: '加载流式断线续传统计失败',
+        streamsStarted: '缓冲流数',
+        resumeHits: '续传成功',
+        resumeMisses: '续传失败',
+        hitRate: '成功率',
+        activeStreams: '缓冲中的流',
+        bufferUsage: '缓冲占用',
+        evicted: '提前淘汰',
+        overflowed: '超出缓冲上限'
+      },
       openaiTokenStats: {
         title: 'OpenAI Token 请求统计',
         viewModeTopN: 'TopN',
@@ -3790,6 +4113,7 @@ export default {
         disabledHint: '已在设置中关闭实时监控。',
         empty: '暂无数据',
         queued: '队列 {count}',
+        queuedByPriority: '优先级 {priority} × {count}',
         rateLimited: '限流 {count}',
         errorAccounts: '异常 {count}',
         loadFailed: '加载并发数据失败'
@@ -3880,7 +4204,12 @@ export default {
         totp: '双因素认证 (2FA)',
         totpHint: '允许用户使用 Google Authenticator 等应用进行二次验证',
         totpKeyNotConfigured:
-          '请先在环境变量中配置 TOTP_ENCRYPTION_KEY。使用命令 openssl rand -hex 32 生成密钥。'
+          '请先在环境变量中配置 TOTP_ENCRYPTION_KEY。使用命令 openssl rand -hex 32 生成密钥。',
+        passkey: 'Passkey',
+        passkeyHint: '允许用户使用 Passkey 无密码登录，或将其作为第二因素',
+        passkeyNotConfigured: '请先配置 webauthn.rp_id 与 webauthn.rp_origins（或 server.frontend_url）。',
+        adminRequire2fa: '管理员强制两步验证',
+        adminRequire2faHint: '管理员须启用 TOTP 或 Passkey 后才能使用管理后台'
       },
       turnstile: {
         title: 'Cloudflare Turnstile',
@@ -45,12 +45,32 @@ vi.mock('@/api/auth', () => ({
   getPublicSettings: vi.fn(),
 }))
 
+vi.mock('@/api/admin/rbac', () => ({
+  rbacAPI: {
+    getMyAccess: vi.fn(),
+  },
+}))
+
 
 // 用于测试的 auth 状态
 interface MockAuthState {
   isAuthenticated: boolean
   isAdmin: boolean
   isSimpleMode: boolean
+  // 已加载的后台权限，未提供时视为拥有全部权限
+  permissions?: string[]
+}
+
+// 与 router/index.ts 中管理路由的定义顺序一致
+const adminRoutePermissions: Array<[string, string]> = [
+  ['/admin/dashboard', 'dashboard:read'],
+  ['/admin/ops', 'ops:read'],
+  ['/admin/users', 'users:read'],
+  ['/admin/groups', 'groups:read'],
+]
+
+function hasPermission(authState: MockAuthState, permission: string): boolean {
+  return !authState.permissions || authState.permissions.includes(permission)
 }
 
 /**
@@ -85,6 +105,12 @@ function simulateGuard(
     return '/dashboard'
   }
 
+  // 缺少页面所需权限时跳到第一个有权限的管理页面
+  if (requiresAdmin && toMeta.permission && !hasPermission(authState, toMeta.permission)) {
+    const fallback = adminRoutePermissions.find(([, permission]) => hasPermission(authState, permission))
+    return fallback?.[0] ?? '/dashboard'
+  }
+
   // 简易模式限制
   if (authState.isSimpleMode) {
     const restrictedPaths = [
@@ -264,4 +290,45 @@ describe('路由守卫逻辑', () => {
       expect(redirect).toBeNull()
     })
   })
+
+  // --- 后台权限 ---
+
+  describe('后台权限', () => {
+    it('拥有页面权限时允许访问', () => {
+      const authState: MockAuthState = {
+        isAuthenticated: true,
+        isAdmin: true,
+        isSimpleMode: false,
+        permissions: ['users:read'],
+      }
+      const redirect = simulateGuard('/admin/users', { requiresAdmin: true, permission: 'users:read' }, authState)
+      expect(redirect).toBeNull()
+    })
+
+    it('缺少页面权限时重定向到第一个有权限的管理页面', () => {
+      const authState: MockAuthState = {
+        isAuthenticated: true,
+        isAdmin: true,
+        isSimpleMode: false,
+        permissions: ['users:read'],
+      }
+      const redirect = simulateGuard(
+        '/admin/dashboard',
+        { requiresAdmin: true, permission: 'dashboard:read' },
+        authState
+      )
+      expect(redirect).toBe('/admin/users')
+    })
+
+    it('没有任何页面权限时重定向到 /dashboard', () => {
+      const authState: MockAuthState = {
+        isAuthenticated: true,
+        isAdmin: true,
+        isSimpleMode: false,
+        permissions: [],
+      }
+      const redirect = simulateGuard('/admin/groups', { requiresAdmin: true, permission: 'groups:read' }, authState)
+      expect(redirect).toBe('/dashboard')
+    })
+  })
 })
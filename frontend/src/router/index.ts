@@ -83,6 +83,15 @@ const routes: RouteRecordRaw[] = [
       title: 'LinuxDo OAuth Callback'
     }
   },
+  {
+    path: '/auth/oidc/callback',
+    name: 'OIDCCallback',
+    component: () => import('@/views/auth/OIDCCallbackView.vue'),
+    meta: {
+      requiresAuth: false,
+      title: 'OIDC Callback'
+    }
+  },
   {
     path: '/forgot-password',
     name: 'ForgotPassword',
@@ -237,6 +246,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'dashboard:read',
       title: 'Admin Dashboard',
       titleKey: 'admin.dashboard.title',
       descriptionKey: 'admin.dashboard.description'
@@ -249,6 +259,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'ops:read',
       title: 'Ops Monitoring',
       titleKey: 'admin.ops.title',
       descriptionKey: 'admin.ops.description'
@@ -261,6 +272,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'users:read',
       title: 'User Management',
       titleKey: 'admin.users.title',
       descriptionKey: 'admin.users.description'
@@ -273,6 +285,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'groups:read',
       title: 'Group Management',
       titleKey: 'admin.groups.title',
       descriptionKey: 'admin.groups.description'
@@ -285,6 +298,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'subscriptions:read',
       title: 'Subscription Management',
       titleKey: 'admin.subscriptions.title',
       descriptionKey: 'admin.subscriptions.description'
@@ -297,6 +311,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'accounts:read',
       title: 'Account Management',
       titleKey: 'admin.accounts.title',
       descriptionKey: 'admin.accounts.description'
@@ -309,6 +324,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'announcements:read',
       title: 'Announcements',
       titleKey: 'admin.announcements.title',
       descriptionKey: 'admin.announcements.description'
@@ -321,6 +337,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'proxies:read',
       title: 'Proxy Management',
       titleKey: 'admin.proxies.title',
       descriptionKey: 'admin.proxies.description'
@@ -333,6 +350,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'redeem:read',
       title: 'Redeem Code Management',
       titleKey: 'admin.redeem.title',
       descriptionKey: 'admin.redeem.description'
@@ -345,11 +363,25 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'promo:read',
       title: 'Promo Code Management',
       titleKey: 'admin.promo.title',
       descriptionKey: 'admin.promo.description'
     }
   },
+  {
+    path: '/admin/experiments',
+    name: 'AdminExperiments',
+    component: () => import('@/views/admin/ExperimentsView.vue'),
+    meta: {
+      requiresAuth: true,
+      requiresAdmin: true,
+      permission: 'groups:read',
+      title: 'Model Experiments',
+      titleKey: 'admin.experiments.title',
+      descriptionKey: 'admin.experiments.description'
+    }
+  },
   {
     path: '/admin/data-management',
     name: 'AdminDataManagement',
@@ -357,6 +389,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'data:manage',
       title: 'Data Management',
       titleKey: 'admin.dataManagement.title',
       descriptionKey: 'admin.dataManagement.description'
@@ -369,6 +402,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'settings:read',
       title: 'System Settings',
       titleKey: 'admin.settings.title',
       descriptionKey: 'admin.settings.description'
@@ -381,6 +415,7 @@ const routes: RouteRecordRaw[] = [
     meta: {
       requiresAuth: true,
       requiresAdmin: true,
+      permission: 'usage:read',
       title: 'Usage Records',
       titleKey: 'admin.usage.title',
       descriptionKey: 'admin.usage.description'
@@ -414,6 +449,18 @@ const router = createRouter({
   }
 })
 
+/**
+ * 返回当前用户有权限访问的第一个管理页面（按路由定义顺序），没有时返回 null
+ */
+function firstPermittedAdminPath(authStore: ReturnType<typeof useAuthStore>): string | null {
+  const route = routes.find(
+    (r) =>
+      r.meta?.requiresAdmin === true &&
+      (!r.meta.permission || authStore.hasPermission(r.meta.permission))
+  )
+  return route?.path ?? null
+}
+
 /**
  * Navigation guard: Authentication check
  */
@@ -424,7 +471,7 @@ const navigationLoading = useNavigationLoadingState()
 // 延迟初始化预加载，传入 router 实例
 let routePrefetch: ReturnType<typeof useRoutePrefetch> | null = null
 
-router.beforeEach((to, _from, next) => {
+router.beforeEach(async (to, _from, next) => {
   // 开始导航加载状态
   navigationLoading.startNavigation()
 
@@ -482,10 +529,18 @@ router.beforeEach((to, _from, next) => {
   }
 
   // Check admin requirement
-  if (requiresAdmin && !authStore.isAdmin) {
-    // User is authenticated but not admin, redirect to user dashboard
-    next('/dashboard')
-    return
+  if (requiresAdmin) {
+    // 等待后台权限（/admin/rbac/me）加载完成，再按权限点放行
+    await authStore.loadAdminAccess()
+    if (!authStore.isAdmin) {
+      // User is authenticated but not admin, redirect to user dashboard
+      next('/dashboard')
+      return
+    }
+    if (to.meta.permission && !authStore.hasPermission(to.meta.permission)) {
+      next(firstPermittedAdminPath(authStore) ?? '/dashboard')
+      return
+    }
   }
 
   // 简易模式下限制访问某些页面
@@ -4,6 +4,7 @@
  */
 
 import 'vue-router'
+import type { AdminPermission } from '@/types'
 
 declare module 'vue-router' {
   interface RouteMeta {
@@ -19,6 +20,11 @@ declare module 'vue-router' {
      */
     requiresAdmin?: boolean
 
+    /**
+     * Admin permission required to open this route (admin routes only)
+     */
+    permission?: AdminPermission
+
     /**
      * Page title for this route
      */
@@ -9,6 +9,7 @@ const mockLogout = vi.fn()
 const mockGetCurrentUser = vi.fn()
 const mockRegister = vi.fn()
 const mockRefreshToken = vi.fn()
+const mockEndImpersonation = vi.fn()
 
 vi.mock('@/api', () => ({
   authAPI: {
@@ -18,10 +19,19 @@ vi.mock('@/api', () => ({
     getCurrentUser: (...args: any[]) => mockGetCurrentUser(...args),
     register: (...args: any[]) => mockRegister(...args),
     refreshToken: (...args: any[]) => mockRefreshToken(...args),
+    endImpersonation: (...args: any[]) => mockEndImpersonation(...args),
   },
   isTotp2FARequired: (response: any) => response?.requires_2fa === true,
 }))
 
+const mockGetMyAccess = vi.fn()
+
+vi.mock('@/api/admin/rbac', () => ({
+  rbacAPI: {
+    getMyAccess: (...args: any[]) => mockGetMyAccess(...args),
+  },
+}))
+
 const fakeUser = {
   id: 1,
   username: 'testuser',
@@ -57,6 +67,8 @@ describe('useAuthStore', () => {
     localStorage.clear()
     vi.useFakeTimers()
     vi.clearAllMocks()
+    // 默认保持权限请求未完成，isAdmin/hasPermission 回退到按 role 判断
+    mockGetMyAccess.mockReturnValue(new Promise(() => {}))
   })
 
   afterEach(() => {
@@ -239,6 +251,102 @@ describe('useAuthStore', () => {
       const store = useAuthStore()
       expect(store.isAdmin).toBe(false)
     })
+
+    it('普通用户被分配管理角色后返回 true', async () => {
+      mockLogin.mockResolvedValue(fakeAuthResponse)
+      mockGetMyAccess.mockResolvedValue({
+        role_key: 'support',
+        role_name: 'Support',
+        permissions: ['users:read'],
+      })
+      const store = useAuthStore()
+
+      await store.login({ email: 'test@example.com', password: '123456' })
+      await store.loadAdminAccess()
+
+      expect(store.isAdmin).toBe(true)
+    })
+
+    it('权限接口返回 403 时管理员用户返回 false', async () => {
+      const adminResponse = { ...fakeAuthResponse, user: { ...fakeAdminUser } }
+      mockLogin.mockResolvedValue(adminResponse)
+      mockGetMyAccess.mockRejectedValue({ status: 403, message: 'Admin access required' })
+      const store = useAuthStore()
+
+      await store.login({ email: 'admin@example.com', password: '123456' })
+      await store.loadAdminAccess()
+
+      expect(store.isAdmin).toBe(false)
+    })
+
+    it('管理员未启用第二因素时仍按 role 判断', async () => {
+      const adminResponse = { ...fakeAuthResponse, user: { ...fakeAdminUser } }
+      mockLogin.mockResolvedValue(adminResponse)
+      mockGetMyAccess.mockRejectedValue({ status: 403, code: 'ADMIN_2FA_REQUIRED' })
+      const store = useAuthStore()
+
+      await store.login({ email: 'admin@example.com', password: '123456' })
+      await store.loadAdminAccess()
+
+      expect(store.isAdmin).toBe(true)
+      expect(store.hasPermission('settings:write')).toBe(true)
+    })
+  })
+
+  // --- hasPermission ---
+
+  describe('hasPermission', () => {
+    it('权限加载前管理员拥有全部权限', async () => {
+      const adminResponse = { ...fakeAuthResponse, user: { ...fakeAdminUser } }
+      mockLogin.mockResolvedValue(adminResponse)
+      const store = useAuthStore()
+
+      await store.login({ email: 'admin@example.com', password: '123456' })
+
+      expect(store.hasPermission('accounts:write')).toBe(true)
+    })
+
+    it('权限加载后按角色权限列表判断', async () => {
+      const adminResponse = { ...fakeAuthResponse, user: { ...fakeAdminUser } }
+      mockLogin.mockResolvedValue(adminResponse)
+      mockGetMyAccess.mockResolvedValue({
+        role_key: 'viewer',
+        role_name: 'Viewer',
+        permissions: ['accounts:read', 'usage:read'],
+      })
+      const store = useAuthStore()
+
+      await store.login({ email: 'admin@example.com', password: '123456' })
+      await store.loadAdminAccess()
+
+      expect(store.hasPermission('accounts:read')).toBe(true)
+      expect(store.hasPermission('accounts:write')).toBe(false)
+    })
+
+    it('并发调用只请求一次', async () => {
+      mockLogin.mockResolvedValue(fakeAuthResponse)
+      mockGetMyAccess.mockResolvedValue({ role_key: 'viewer', role_name: 'Viewer', permissions: [] })
+      const store = useAuthStore()
+
+      await store.login({ email: 'test@example.com', password: '123456' })
+      await Promise.all([store.loadAdminAccess(), store.loadAdminAccess()])
+
+      expect(mockGetMyAccess).toHaveBeenCalledTimes(1)
+    })
+
+    it('注销后清除已加载的权限', async () => {
+      mockLogin.mockResolvedValue(fakeAuthResponse)
+      mockLogout.mockResolvedValue(undefined)
+      mockGetMyAccess.mockResolvedValue({ role_key: 'viewer', role_name: 'Viewer', permissions: ['users:read'] })
+      const store = useAuthStore()
+
+      await store.login({ email: 'test@example.com', password: '123456' })
+      await store.loadAdminAccess()
+      await store.logout()
+
+      expect(store.adminAccess).toBeNull()
+      expect(store.hasPermission('users:read')).toBe(false)
+    })
   })
 
   // --- refreshUser ---
@@ -286,4 +394,81 @@ describe('useAuthStore', () => {
       expect(store.isSimpleMode).toBe(false)
     })
   })
+
+  // --- impersonation ---
+
+  describe('impersonation', () => {
+    const impersonationSession = {
+      id: 'imp-1',
+      admin_id: 2,
+      admin_email: 'admin@example.com',
+      target_user_id: 1,
+      scope: 'read' as const,
+      reason: 'ticket',
+      started_at: '2024-01-01T00:00:00Z',
+      expires_at: '2024-01-01T00:30:00Z',
+    }
+    const startResult = {
+      access_token: 'imp-token',
+      expires_in: 1800,
+      token_type: 'Bearer',
+      session: impersonationSession,
+    }
+
+    async function loginAsAdmin() {
+      mockLogin.mockResolvedValue({ ...fakeAuthResponse, user: { ...fakeAdminUser } })
+      const store = useAuthStore()
+      await store.login({ email: 'admin@example.com', password: '123456' })
+      return store
+    }
+
+    it('开始模拟后切换到模拟 Token，且不保留 refresh token', async () => {
+      const store = await loginAsAdmin()
+      mockGetCurrentUser.mockResolvedValue({ data: { ...fakeUser, impersonation: impersonationSession } })
+
+      await store.startImpersonation(startResult)
+
+      expect(store.token).toBe('imp-token')
+      expect(store.user?.id).toBe(fakeUser.id)
+      expect(store.impersonation).toEqual(impersonationSession)
+      expect(localStorage.getItem('auth_token')).toBe('imp-token')
+      expect(localStorage.getItem('refresh_token')).toBeNull()
+      expect(localStorage.getItem('impersonation_admin_auth')).not.toBeNull()
+    })
+
+    it('结束模拟后恢复管理员登录态', async () => {
+      const store = await loginAsAdmin()
+      mockGetCurrentUser.mockResolvedValue({ data: { ...fakeUser, impersonation: impersonationSession } })
+      await store.startImpersonation(startResult)
+
+      mockEndImpersonation.mockResolvedValue({ message: 'ok' })
+      mockGetCurrentUser.mockResolvedValue({ data: { ...fakeAdminUser } })
+      await store.endImpersonation()
+
+      expect(mockEndImpersonation).toHaveBeenCalled()
+      expect(store.token).toBe('test-token-123')
+      expect(store.user?.id).toBe(fakeAdminUser.id)
+      expect(store.impersonation).toBeNull()
+      expect(localStorage.getItem('refresh_token')).toBe('refresh-token-456')
+      expect(localStorage.getItem('impersonation_admin_auth')).toBeNull()
+    })
+
+    it('模拟 Token 失效后启动时恢复管理员登录态', async () => {
+      const store = await loginAsAdmin()
+      mockGetCurrentUser.mockResolvedValue({ data: { ...fakeUser, impersonation: impersonationSession } })
+      await store.startImpersonation(startResult)
+
+      // 模拟 401 后由请求拦截器清除登录态
+      localStorage.removeItem('auth_token')
+      localStorage.removeItem('auth_user')
+      setActivePinia(createPinia())
+      mockGetCurrentUser.mockResolvedValue({ data: { ...fakeAdminUser } })
+      const restored = useAuthStore()
+      restored.checkAuth()
+
+      expect(restored.token).toBe('test-token-123')
+      expect(restored.isAdmin).toBe(true)
+      expect(localStorage.getItem('impersonation_admin_auth')).toBeNull()
+    })
+  })
 })
@@ -330,6 +330,8 @@ export const useAppStore = defineStore('app', () => {
         purchase_subscription_url: '',
         custom_menu_items: [],
         linuxdo_oauth_enabled: false,
+        oidc_providers: [],
+        passkey_enabled: false,
         sora_client_enabled: false,
         version: siteVersion.value
       }
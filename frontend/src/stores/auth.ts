@@ -6,12 +6,23 @@
 import { defineStore } from 'pinia'
 import { ref, computed, readonly } from 'vue'
 import { authAPI, isTotp2FARequired, type LoginResponse } from '@/api'
-import type { User, LoginRequest, RegisterRequest, AuthResponse } from '@/types'
+import { rbacAPI } from '@/api/admin/rbac'
+import { getPasskeyAssertion } from '@/utils/webauthn'
+import type {
+  User,
+  LoginRequest,
+  RegisterRequest,
+  AuthResponse,
+  ImpersonationStartResult,
+  AdminAccess,
+  AdminPermission
+} from '@/types'
 
 const AUTH_TOKEN_KEY = 'auth_token'
 const AUTH_USER_KEY = 'auth_user'
 const REFRESH_TOKEN_KEY = 'refresh_token'
 const TOKEN_EXPIRES_AT_KEY = 'token_expires_at' // 存储过期时间戳而非有效期
+const IMPERSONATION_ADMIN_AUTH_KEY = 'impersonation_admin_auth' // 模拟登录期间保存的管理员登录态
 const AUTO_REFRESH_INTERVAL = 60 * 1000 // 60 seconds for user data refresh
 const TOKEN_REFRESH_BUFFER = 120 * 1000 // 120 seconds before expiry to refresh token
 
@@ -23,6 +34,10 @@ export const useAuthStore = defineStore('auth', () => {
   const refreshTokenValue = ref<string | null>(null)
   const tokenExpiresAt = ref<number | null>(null) // 过期时间戳（毫秒）
   const runMode = ref<'standard' | 'simple'>('standard')
+  // 管理后台有效权限（GET /admin/rbac/me），null 表示无后台访问权限
+  const adminAccess = ref<AdminAccess | null>(null)
+  const adminAccessLoaded = ref(false)
+  let adminAccessRequest: Promise<AdminAccess | null> | null = null
   let refreshIntervalId: ReturnType<typeof setInterval> | null = null
   let tokenRefreshTimeoutId: ReturnType<typeof setTimeout> | null = null
 
@@ -32,12 +47,32 @@ export const useAuthStore = defineStore('auth', () => {
     return !!token.value && !!user.value
   })
 
+  // 拥有任意后台权限即可进入管理后台（含分配了管理角色的普通用户）；
+  // 权限尚未加载时按 role 判断，最终以服务端校验为准
   const isAdmin = computed(() => {
+    if (adminAccessLoaded.value) {
+      return adminAccess.value !== null
+    }
     return user.value?.role === 'admin'
   })
 
   const isSimpleMode = computed(() => runMode.value === 'simple')
 
+  // 非空表示管理员正在模拟该用户登录
+  const impersonation = computed(() => user.value?.impersonation ?? null)
+
+  /**
+   * Check whether the current user holds an admin permission
+   * Falls back to the single admin role until /admin/rbac/me has loaded
+   * @param permission - Permission key, e.g. 'users:write'
+   */
+  function hasPermission(permission: AdminPermission): boolean {
+    if (adminAccessLoaded.value) {
+      return adminAccess.value?.permissions.includes(permission) ?? false
+    }
+    return user.value?.role === 'admin'
+  }
+
   // ==================== Actions ====================
 
   /**
@@ -51,6 +86,12 @@ export const useAuthStore = defineStore('auth', () => {
     const savedRefreshToken = localStorage.getItem(REFRESH_TOKEN_KEY)
     const savedExpiresAt = localStorage.getItem(TOKEN_EXPIRES_AT_KEY)
 
+    // 模拟 Token 过期被清除后，恢复模拟前的管理员登录态
+    if (!savedToken && localStorage.getItem(IMPERSONATION_ADMIN_AUTH_KEY)) {
+      restoreAdminAuth()
+      return
+    }
+
     if (savedToken && savedUser) {
       try {
         token.value = savedToken
@@ -62,6 +103,7 @@ export const useAuthStore = defineStore('auth', () => {
         refreshUser().catch((error) => {
           console.error('Failed to refresh user on init:', error)
         })
+        void loadAdminAccess()
 
         // Start auto-refresh interval for user data
         startAutoRefresh()
@@ -219,6 +261,47 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
+  /**
+   * Sign in with a passkey (passwordless, no 2FA step)
+   * @returns Promise resolving to the authenticated user
+   * @throws Error if the browser ceremony or verification fails
+   */
+  async function loginWithPasskey(): Promise<User> {
+    try {
+      const { ceremony_token, options } = await authAPI.passkeyLoginOptions()
+      const credential = await getPasskeyAssertion(options)
+      const response = await authAPI.passkeyLogin(ceremony_token, credential)
+      setAuthFromResponse(response)
+      return user.value!
+    } catch (error) {
+      clearAuth()
+      throw error
+    }
+  }
+
+  /**
+   * Complete login with a passkey as the second factor
+   * @param tempToken - Temporary token from initial login
+   * @returns Promise resolving to the authenticated user
+   * @throws Error if the browser ceremony or verification fails
+   */
+  async function login2FAPasskey(tempToken: string): Promise<User> {
+    try {
+      const { ceremony_token, options } = await authAPI.login2FAPasskeyOptions(tempToken)
+      const credential = await getPasskeyAssertion(options)
+      const response = await authAPI.login2FA({
+        temp_token: tempToken,
+        passkey_ceremony_token: ceremony_token,
+        passkey_credential: credential
+      })
+      setAuthFromResponse(response)
+      return user.value!
+    } catch (error) {
+      clearAuth()
+      throw error
+    }
+  }
+
   /**
    * Set auth state from an AuthResponse
    * Internal helper function
@@ -244,6 +327,9 @@ export const useAuthStore = defineStore('auth', () => {
     localStorage.setItem(AUTH_TOKEN_KEY, response.access_token)
     localStorage.setItem(AUTH_USER_KEY, JSON.stringify(userData))
 
+    resetAdminAccess()
+    void loadAdminAccess()
+
     // Start auto-refresh interval for user data
     startAutoRefresh()
 
@@ -287,6 +373,7 @@ export const useAuthStore = defineStore('auth', () => {
     stopTokenRefresh()
     token.value = null
     user.value = null
+    resetAdminAccess()
 
     token.value = newToken
     localStorage.setItem(AUTH_TOKEN_KEY, newToken)
@@ -304,6 +391,7 @@ export const useAuthStore = defineStore('auth', () => {
 
     try {
       const userData = await refreshUser()
+      await loadAdminAccess()
       startAutoRefresh()
 
       // Start proactive token refresh if we have refresh token and expiry info
@@ -331,6 +419,82 @@ export const useAuthStore = defineStore('auth', () => {
     clearAuth()
   }
 
+  /**
+   * Switch to an impersonation token minted by the admin API
+   * The admin's own session is saved and restored by endImpersonation()
+   * @param result - Impersonation token and session info
+   * @returns Promise resolving to the impersonated user
+   */
+  async function startImpersonation(result: ImpersonationStartResult): Promise<User> {
+    const snapshot = {
+      [AUTH_TOKEN_KEY]: localStorage.getItem(AUTH_TOKEN_KEY),
+      [AUTH_USER_KEY]: localStorage.getItem(AUTH_USER_KEY),
+      [REFRESH_TOKEN_KEY]: localStorage.getItem(REFRESH_TOKEN_KEY),
+      [TOKEN_EXPIRES_AT_KEY]: localStorage.getItem(TOKEN_EXPIRES_AT_KEY)
+    }
+
+    // 模拟 Token 不可刷新，到期后需重新发起
+    stopAutoRefresh()
+    stopTokenRefresh()
+    refreshTokenValue.value = null
+    tokenExpiresAt.value = null
+    localStorage.removeItem(REFRESH_TOKEN_KEY)
+    localStorage.removeItem(TOKEN_EXPIRES_AT_KEY)
+
+    token.value = result.access_token
+    localStorage.setItem(AUTH_TOKEN_KEY, result.access_token)
+    localStorage.setItem(IMPERSONATION_ADMIN_AUTH_KEY, JSON.stringify(snapshot))
+    // 模拟 Token 不能访问管理后台
+    adminAccess.value = null
+    adminAccessLoaded.value = true
+
+    try {
+      const userData = await refreshUser()
+      startAutoRefresh()
+      return userData
+    } catch (error) {
+      // refreshUser 失败时可能已清空登录态，使用本地快照恢复
+      restoreAdminAuth(JSON.stringify(snapshot))
+      throw error
+    }
+  }
+
+  /**
+   * End the current impersonation and restore the admin's own session
+   */
+  async function endImpersonation(): Promise<void> {
+    try {
+      await authAPI.endImpersonation()
+    } catch (error) {
+      // Token 可能已过期，仍然恢复管理员登录态
+      console.error('Failed to end impersonation:', error)
+    }
+    restoreAdminAuth()
+  }
+
+  /**
+   * Restore the admin session saved by startImpersonation()
+   * Internal helper function
+   */
+  function restoreAdminAuth(saved = localStorage.getItem(IMPERSONATION_ADMIN_AUTH_KEY)): void {
+    clearAuth()
+    if (!saved) {
+      return
+    }
+    try {
+      const snapshot = JSON.parse(saved) as Record<string, string | null>
+      for (const [key, value] of Object.entries(snapshot)) {
+        if (value !== null) {
+          localStorage.setItem(key, value)
+        }
+      }
+    } catch (error) {
+      console.error('Failed to restore admin session:', error)
+      return
+    }
+    checkAuth()
+  }
+
   /**
    * Refresh current user data
    * Fetches latest user info from the server
@@ -363,6 +527,64 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
+  /**
+   * Load the admin permissions of the current user from /admin/rbac/me
+   * Concurrent callers share one request; 403 means no admin access
+   * @param force - Reload even if permissions are already loaded
+   * @returns Admin access, or null when the user has none
+   */
+  async function loadAdminAccess(force = false): Promise<AdminAccess | null> {
+    if (!token.value) {
+      return null
+    }
+    // 模拟 Token 不能访问管理后台
+    if (impersonation.value) {
+      adminAccess.value = null
+      adminAccessLoaded.value = true
+      return null
+    }
+    if (adminAccessLoaded.value && !force) {
+      return adminAccess.value
+    }
+    if (!adminAccessRequest) {
+      const requestToken = token.value
+      const request: Promise<AdminAccess | null> = rbacAPI
+        .getMyAccess()
+        .then((access) => {
+          if (token.value === requestToken) {
+            adminAccess.value = access
+            adminAccessLoaded.value = true
+          }
+          return adminAccess.value
+        })
+        .catch((error: { status?: number; code?: string }) => {
+          // 管理员未启用第二因素时保持按 role 判断，由后台页面提示启用
+          if (token.value === requestToken && error.status === 403 && error.code !== 'ADMIN_2FA_REQUIRED') {
+            adminAccess.value = null
+            adminAccessLoaded.value = true
+          }
+          return adminAccess.value
+        })
+        .finally(() => {
+          if (adminAccessRequest === request) {
+            adminAccessRequest = null
+          }
+        })
+      adminAccessRequest = request
+    }
+    return adminAccessRequest
+  }
+
+  /**
+   * Forget loaded admin permissions (on session changes)
+   * Internal helper function
+   */
+  function resetAdminAccess(): void {
+    adminAccess.value = null
+    adminAccessLoaded.value = false
+    adminAccessRequest = null
+  }
+
   /**
    * Clear all authentication state
    * Internal helper function
@@ -377,10 +599,13 @@ export const useAuthStore = defineStore('auth', () => {
     refreshTokenValue.value = null
     tokenExpiresAt.value = null
     user.value = null
+    resetAdminAccess()
     localStorage.removeItem(AUTH_TOKEN_KEY)
     localStorage.removeItem(AUTH_USER_KEY)
     localStorage.removeItem(REFRESH_TOKEN_KEY)
     localStorage.removeItem(TOKEN_EXPIRES_AT_KEY)
+    // 登出或登录态失效时一并丢弃模拟前保存的管理员登录态
+    localStorage.removeItem(IMPERSONATION_ADMIN_AUTH_KEY)
   }
 
   // ==================== Return Store API ====================
@@ -390,19 +615,27 @@ export const useAuthStore = defineStore('auth', () => {
     user,
     token,
     runMode: readonly(runMode),
+    adminAccess: readonly(adminAccess),
 
     // Computed
     isAuthenticated,
     isAdmin,
     isSimpleMode,
+    impersonation,
 
     // Actions
+    hasPermission,
+    loadAdminAccess,
     login,
     login2FA,
+    loginWithPasskey,
+    login2FAPasskey,
     register,
     setToken,
     logout,
     checkAuth,
-    refreshUser
+    refreshUser,
+    startImpersonation,
+    endImpersonation
   }
 })
@@ -34,6 +34,7 @@ export interface User {
   status: 'active' | 'disabled' // Account status
   allowed_groups: number[] | null // Allowed group IDs (null = all non-exclusive groups)
   subscriptions?: UserSubscription[] // User's active subscriptions
+  impersonation?: ImpersonationSession // Set when an admin is impersonating this user
   created_at: string
   updated_at: string
 }
@@ -48,6 +49,156 @@ export interface AdminUser extends User {
   // Sora 存储配额（字节）
   sora_storage_quota_bytes: number
   sora_storage_used_bytes: number
+  // 用户所有 Key 合计的 RPM/TPM 上限（0 不限制）
+  rpm_limit?: number
+  tpm_limit?: number
+  // 账号满载排队优先级（0-9，越大越优先）
+  queue_priority?: number
+  // 模型降级链
+  model_fallback_chains?: ModelFallbackChain[] | null
+  // 跨平台故障转移（anthropic ↔ antigravity）
+  cross_platform_failover?: boolean
+  // 影子流量镜像
+  shadow_mirror?: ShadowMirrorConfig | null
+  // 网关响应缓存
+  response_cache?: ResponseCacheConfig | null
+  // 自动 prompt 缓存断点
+  auto_cache_breakpoints?: boolean
+  // 流式断线续传
+  stream_resume?: boolean
+  // 上下文策略
+  context_policy?: ContextPolicyConfig | null
+}
+
+export type ModelFallbackTrigger = 'no_account' | 'rate_limited' | 'overloaded' | 'context_too_long'
+
+export interface ModelFallbackStep {
+  model: string
+  platform?: '' | 'anthropic' | 'antigravity'
+}
+
+export interface ModelFallbackChain {
+  source: string // 请求模型，支持 * / ? 通配
+  fallbacks: ModelFallbackStep[]
+  triggers?: ModelFallbackTrigger[] // 为空表示全部触发条件
+}
+
+// 影子流量镜像：按采样率异步复制请求到影子账号或分组（account_id 与 group_id 二选一）
+export interface ShadowMirrorConfig {
+  enabled: boolean
+  sample_rate?: number // (0, 1]
+  account_id?: number
+  group_id?: number
+}
+
+// 网关响应缓存：仅缓存 temperature 为 0 的请求，命中按原费用 × hit_price_ratio 计费
+export interface ResponseCacheConfig {
+  enabled: boolean
+  ttl_seconds?: number
+  max_entry_bytes?: number
+  max_total_bytes?: number // 每个共享范围的缓存总量上限
+  hit_price_ratio?: number // [0, 1]
+  scope?: 'api_key' | 'group'
+}
+
+// 上下文策略：转发前估算输入 token，超出 max_input_tokens 时拒绝、截断最早轮次或用 summary_model 摘要
+export interface ContextPolicyConfig {
+  enabled: boolean
+  action?: 'reject' | 'truncate' | 'summarize'
+  max_input_tokens?: number
+  summary_model?: string
+  summary_max_tokens?: number
+}
+
+export interface ShadowMirrorSummary {
+  total: number
+  shadow_succeeded: number
+  shadow_failed: number
+  skipped: number
+  avg_primary_latency_ms: number
+  avg_shadow_latency_ms: number
+  avg_primary_output_tokens: number
+  avg_shadow_output_tokens: number
+  avg_similarity: number | null
+}
+
+export interface ShadowMirrorResult {
+  id: number
+  group_id: number
+  api_key_id: number
+  model: string
+  stream: boolean
+  primary_account_id: number
+  shadow_account_id: number | null
+  primary_status: number
+  shadow_status: number
+  primary_latency_ms: number
+  shadow_latency_ms: number
+  primary_input_tokens: number
+  primary_output_tokens: number
+  shadow_input_tokens: number
+  shadow_output_tokens: number
+  similarity: number | null
+  error_message: string
+  created_at: string
+}
+
+// A/B 模型实验：匹配模型的请求按用户或 API Key 粘性分配到变体
+export type ExperimentStatus = 'draft' | 'running' | 'stopped'
+export type ExperimentStickyBy = 'user' | 'api_key'
+
+export interface ExperimentVariant {
+  name: string
+  percent: number // 所有变体合计 100
+  model?: string
+  group_id?: number
+  system_prompt_patch?: string
+}
+
+export interface Experiment {
+  id: number
+  name: string
+  description: string
+  status: ExperimentStatus
+  model_pattern: string
+  group_ids: number[]
+  sticky_by: ExperimentStickyBy
+  variants: ExperimentVariant[]
+  created_at: string
+  updated_at: string
+}
+
+export interface SaveExperimentRequest {
+  name: string
+  description?: string
+  status?: ExperimentStatus
+  model_pattern: string
+  group_ids?: number[]
+  sticky_by?: ExperimentStickyBy
+  variants: ExperimentVariant[]
+}
+
+export interface ExperimentVariantStats {
+  variant: string
+  requests: number
+  errors: number
+  error_rate: number
+  unique_users: number
+  returning_users: number
+  avg_duration_ms: number
+  avg_first_token_ms: number
+  input_tokens: number
+  output_tokens: number
+  avg_output_tokens: number
+  total_cost: number
+  avg_cost: number
+}
+
+export interface ExperimentResults {
+  experiment: Experiment
+  start_time: string
+  end_time: string
+  variants: ExperimentVariantStats[]
 }
 
 export interface LoginRequest {
@@ -105,10 +256,18 @@ export interface PublicSettings {
   purchase_subscription_url: string
   custom_menu_items: CustomMenuItem[]
   linuxdo_oauth_enabled: boolean
+  oidc_providers: OIDCPublicProvider[]
+  passkey_enabled: boolean
   sora_client_enabled: boolean
   version: string
 }
 
+/** 登录页展示的 OIDC 提供方 */
+export interface OIDCPublicProvider {
+  key: string
+  name: string
+}
+
 export interface AuthResponse {
   access_token: string
   refresh_token?: string  // New: Refresh Token for token renewal
@@ -414,12 +573,21 @@ export interface AdminGroup extends Group {
 
   // 分组排序
   sort_order: number
+
+  // 分组内所有请求合计的 RPM/TPM 上限（0 不限制）
+  rpm_limit?: number
+  tpm_limit?: number
+  // 账号调度策略（空为平台默认）
+  scheduling_strategy?: string
+  // 账号满载排队优先级（0-9，越大越优先）
+  queue_priority?: number
 }
 
 export interface ApiKey {
   id: number
   user_id: number
-  key: string
+  key: string // plaintext, only returned on creation
+  key_prefix: string
   name: string
   group_id: number | null
   status: 'active' | 'inactive' | 'quota_exhausted' | 'expired'
@@ -441,6 +609,26 @@ export interface ApiKey {
   window_5h_start: string | null
   window_1d_start: string | null
   window_7d_start: string | null
+  previous_key_prefix: string // old secret still valid during the rotation grace period
+  previous_key_expires_at: string | null
+  rotated_at: string | null
+  policy: ApiKeyPolicy | null // access policy (null = unrestricted)
+}
+
+// Per-key access policy; model/endpoint patterns support * and ? wildcards
+export interface ApiKeyPolicy {
+  allowed_models?: string[]
+  denied_models?: string[] // takes precedence over allowed_models
+  allowed_endpoints?: string[] // e.g. /v1/messages, /v1beta/models/*
+  allowed_platforms?: string[]
+  max_input_tokens?: number // 0 = unlimited
+  max_output_tokens?: number // 0 = unlimited
+  deny_thinking?: boolean
+  deny_tools?: boolean
+  response_cache?: boolean // opt in to the gateway response cache for deterministic requests
+  rpm?: number // requests per minute, 0 = unlimited
+  tpm?: number // tokens per minute, 0 = unlimited
+  queue_priority?: number // wait-queue priority 1-9, 0 = inherit; can only lower
 }
 
 export interface CreateApiKeyRequest {
@@ -454,6 +642,7 @@ export interface CreateApiKeyRequest {
   rate_limit_5h?: number
   rate_limit_1d?: number
   rate_limit_7d?: number
+  policy?: ApiKeyPolicy
 }
 
 export interface UpdateApiKeyRequest {
@@ -469,6 +658,7 @@ export interface UpdateApiKeyRequest {
   rate_limit_1d?: number
   rate_limit_7d?: number
   reset_rate_limit_usage?: boolean
+  policy?: ApiKeyPolicy // omit = no change, {} = clear
 }
 
 export interface CreateGroupRequest {
@@ -494,6 +684,17 @@ export interface CreateGroupRequest {
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
   supported_model_scopes?: string[]
+  rpm_limit?: number
+  tpm_limit?: number
+  scheduling_strategy?: string
+  queue_priority?: number
+  model_fallback_chains?: ModelFallbackChain[]
+  cross_platform_failover?: boolean
+  shadow_mirror?: ShadowMirrorConfig
+  response_cache?: ResponseCacheConfig
+  auto_cache_breakpoints?: boolean
+  stream_resume?: boolean
+  context_policy?: ContextPolicyConfig
   // 从指定分组复制账号
   copy_accounts_from_group_ids?: number[]
 }
@@ -522,6 +723,17 @@ export interface UpdateGroupRequest {
   fallback_group_id_on_invalid_request?: number | null
   mcp_xml_inject?: boolean
   supported_model_scopes?: string[]
+  rpm_limit?: number
+  tpm_limit?: number
+  scheduling_strategy?: string
+  queue_priority?: number
+  model_fallback_chains?: ModelFallbackChain[]
+  cross_platform_failover?: boolean
+  shadow_mirror?: ShadowMirrorConfig
+  response_cache?: ResponseCacheConfig
+  auto_cache_breakpoints?: boolean
+  stream_resume?: boolean
+  context_policy?: ContextPolicyConfig
   copy_accounts_from_group_ids?: number[]
 }
 
@@ -1187,6 +1399,109 @@ export interface ChangePasswordRequest {
   new_password: string
 }
 
+/** 登录会话（一个 Refresh Token 家族） */
+export interface UserSession {
+  id: string
+  device: string
+  ip: string
+  user_agent: string
+  created_at: string
+  last_seen_at: string
+  expires_at: string
+  current: boolean
+}
+
+/** 已绑定的外部身份（OIDC） */
+export interface UserIdentity {
+  id: number
+  user_id: number
+  provider: string
+  subject: string
+  email: string
+  username: string
+  last_login_at?: string
+  created_at: string
+  updated_at: string
+}
+
+// ==================== Admin RBAC Types ====================
+
+// 管理后台权限点（资源:动作），与 service/admin_rbac_service.go 保持一致
+export type AdminPermission =
+  | 'dashboard:read'
+  | 'users:read'
+  | 'users:write'
+  | 'users:balance'
+  | 'users:impersonate'
+  | 'groups:read'
+  | 'groups:write'
+  | 'accounts:read'
+  | 'accounts:write'
+  | 'proxies:read'
+  | 'proxies:write'
+  | 'announcements:read'
+  | 'announcements:write'
+  | 'redeem:read'
+  | 'redeem:write'
+  | 'promo:read'
+  | 'promo:write'
+  | 'subscriptions:read'
+  | 'subscriptions:write'
+  | 'usage:read'
+  | 'usage:write'
+  | 'ops:read'
+  | 'ops:write'
+  | 'settings:read'
+  | 'settings:write'
+  | 'data:manage'
+  | 'system:read'
+  | 'system:manage'
+  | 'roles:manage'
+
+// 当前用户在管理后台的有效权限（GET /admin/rbac/me）
+export interface AdminAccess {
+  role_key: string
+  role_name: string
+  permissions: AdminPermission[]
+}
+
+// ==================== Impersonation Types ====================
+
+export type ImpersonationScope = 'read' | 'write'
+
+export interface ImpersonationSession {
+  id: string
+  admin_id: number
+  admin_email: string
+  target_user_id: number
+  scope: ImpersonationScope
+  reason: string
+  started_at: string
+  expires_at: string
+}
+
+export interface ImpersonationStartResult {
+  access_token: string
+  expires_in: number
+  token_type: string
+  session: ImpersonationSession
+}
+
+export interface ImpersonationAuditLog {
+  id: number
+  impersonation_id: string
+  admin_id: number
+  target_user_id: number
+  event: 'start' | 'action' | 'blocked' | 'end'
+  scope: ImpersonationScope
+  method: string
+  path: string
+  status_code: number
+  detail: string
+  ip: string
+  created_at: string
+}
+
 // ==================== User Subscription Types ====================
 
 export interface UserSubscription {
@@ -1475,11 +1790,48 @@ export interface TotpLoginResponse {
   requires_2fa: boolean
   temp_token?: string
   user_email_masked?: string
+  totp_available?: boolean
+  passkey_available?: boolean
 }
 
+// totp_code 与 passkey_ceremony_token + passkey_credential 二选一
 export interface TotpLogin2FARequest {
   temp_token: string
-  totp_code: string
+  totp_code?: string
+  passkey_ceremony_token?: string
+  passkey_credential?: Record<string, any>
+}
+
+// ==================== Passkey Types ====================
+
+export interface UserPasskey {
+  id: number
+  name: string
+  credential_id: string
+  last_used_at?: string
+  created_at: string
+}
+
+export interface PasskeyStatus {
+  feature_enabled: boolean
+  passkeys: UserPasskey[]
+}
+
+/** 注册仪式选项，options 原样传给 navigator.credentials.create() */
+export interface PasskeyCredentialCreation {
+  ceremony_token: string
+  options: Record<string, any>
+}
+
+/** 认证仪式选项，options 原样传给 navigator.credentials.get() */
+export interface PasskeyCredentialAssertion {
+  ceremony_token: string
+  options: Record<string, any>
+}
+
+export interface PasskeyVerifyRequest {
+  email_code?: string
+  password?: string
 }
 
 // ==================== Scheduled Test Types ====================
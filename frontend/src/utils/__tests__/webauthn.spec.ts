@@ -0,0 +1,15 @@
+import { describe, expect, it } from 'vitest'
+import { base64urlToBuffer, bufferToBase64url } from '@/utils/webauthn'
+
+describe('base64url', () => {
+  it('编码结果不含填充与 +/ 字符', () => {
+    const bytes = new Uint8Array([0xfb, 0xff, 0xfe, 0x01])
+    expect(bufferToBase64url(bytes.buffer)).toBe('-__-AQ')
+  })
+
+  it('解码后与原始字节一致', () => {
+    const bytes = new Uint8Array([0, 1, 2, 250, 251, 252, 253, 254, 255])
+    const decoded = new Uint8Array(base64urlToBuffer(bufferToBase64url(bytes.buffer)))
+    expect(Array.from(decoded)).toEqual(Array.from(bytes))
+  })
+})
@@ -0,0 +1,111 @@
+/**
+ * WebAuthn 辅助函数
+ * 服务端（go-webauthn）以 base64url 编码二进制字段，浏览器 API 需要 ArrayBuffer，
+ * 这里负责在两者之间转换，返回可直接 JSON 提交给服务端的凭据。
+ */
+
+type JsonObject = Record<string, any>
+
+/**
+ * 当前浏览器是否支持 Passkey
+ */
+export function isPasskeySupported(): boolean {
+  return (
+    typeof window !== 'undefined' &&
+    typeof window.PublicKeyCredential !== 'undefined' &&
+    typeof navigator !== 'undefined' &&
+    !!navigator.credentials
+  )
+}
+
+export function base64urlToBuffer(value: string): ArrayBuffer {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/')
+  const padded = base64 + '='.repeat((4 - (base64.length % 4)) % 4)
+  const binary = atob(padded)
+  const bytes = new Uint8Array(binary.length)
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i)
+  }
+  return bytes.buffer
+}
+
+export function bufferToBase64url(buffer: ArrayBuffer): string {
+  const bytes = new Uint8Array(buffer)
+  let binary = ''
+  for (let i = 0; i < bytes.length; i++) {
+    binary += String.fromCharCode(bytes[i])
+  }
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '')
+}
+
+function decodeCredentialDescriptors(list: JsonObject[] | undefined) {
+  return list?.map((item) => ({ ...item, id: base64urlToBuffer(item.id) }))
+}
+
+/**
+ * 调用 navigator.credentials.create() 注册 Passkey
+ * @param options - 服务端返回的 CredentialCreation（{ publicKey: ... }）
+ * @returns 序列化后的 attestation，原样提交到 register/finish
+ */
+export async function createPasskeyCredential(options: JsonObject): Promise<JsonObject> {
+  const publicKey = options.publicKey
+  const credential = (await navigator.credentials.create({
+    publicKey: {
+      ...publicKey,
+      challenge: base64urlToBuffer(publicKey.challenge),
+      user: { ...publicKey.user, id: base64urlToBuffer(publicKey.user.id) },
+      excludeCredentials: decodeCredentialDescriptors(publicKey.excludeCredentials)
+    }
+  })) as PublicKeyCredential | null
+  if (!credential) {
+    throw new Error('Passkey registration was cancelled')
+  }
+
+  const response = credential.response as AuthenticatorAttestationResponse
+  return {
+    id: credential.id,
+    rawId: bufferToBase64url(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment ?? undefined,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response: {
+      clientDataJSON: bufferToBase64url(response.clientDataJSON),
+      attestationObject: bufferToBase64url(response.attestationObject),
+      transports: typeof response.getTransports === 'function' ? response.getTransports() : undefined
+    }
+  }
+}
+
+/**
+ * 调用 navigator.credentials.get() 获取 Passkey 断言
+ * @param options - 服务端返回的 CredentialAssertion（{ publicKey: ... }）
+ * @returns 序列化后的 assertion，原样提交给登录或 2FA 接口
+ */
+export async function getPasskeyAssertion(options: JsonObject): Promise<JsonObject> {
+  const publicKey = options.publicKey
+  const credential = (await navigator.credentials.get({
+    publicKey: {
+      ...publicKey,
+      challenge: base64urlToBuffer(publicKey.challenge),
+      allowCredentials: decodeCredentialDescriptors(publicKey.allowCredentials)
+    }
+  })) as PublicKeyCredential | null
+  if (!credential) {
+    throw new Error('Passkey verification was cancelled')
+  }
+
+  const response = credential.response as AuthenticatorAssertionResponse
+  return {
+    id: credential.id,
+    rawId: bufferToBase64url(credential.rawId),
+    type: credential.type,
+    authenticatorAttachment: credential.authenticatorAttachment ?? undefined,
+    clientExtensionResults: credential.getClientExtensionResults(),
+    response: {
+      clientDataJSON: bufferToBase64url(response.clientDataJSON),
+      authenticatorData: bufferToBase64url(response.authenticatorData),
+      signature: bufferToBase64url(response.signature),
+      userHandle: response.userHandle ? bufferToBase64url(response.userHandle) : undefined
+    }
+  }
+}